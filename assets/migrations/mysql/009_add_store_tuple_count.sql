@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE store_tuple_count (
+    store CHAR(26) NOT NULL,
+    shard SMALLINT NOT NULL,
+    tuple_count BIGINT NOT NULL,
+    PRIMARY KEY (store, shard)
+);
+
+INSERT INTO store_tuple_count (store, shard, tuple_count)
+SELECT store, 0, COUNT(*) FROM tuple GROUP BY store;
+
+-- +goose Down
+DROP TABLE store_tuple_count;
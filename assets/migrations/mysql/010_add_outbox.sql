@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE outbox (
+    id CHAR(26) NOT NULL,
+    store CHAR(26) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload LONGBLOB NOT NULL,
+    inserted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE outbox;
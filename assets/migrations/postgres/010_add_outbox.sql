@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE outbox (
+	id TEXT PRIMARY KEY,
+	store TEXT NOT NULL,
+	event_type TEXT NOT NULL,
+	payload BYTEA NOT NULL,
+	inserted_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE outbox;
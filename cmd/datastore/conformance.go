@@ -0,0 +1,118 @@
+package datastore
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/bolt"
+	"github.com/openfga/openfga/pkg/storage/conformance"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/mysql"
+	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
+)
+
+// runConformance runs the scenarios, replaced by the tests which can't drive the testing package themselves.
+var runConformance = conformance.Run
+
+func NewConformanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conformance",
+		Short: "Run the conformance scenarios of the datastores against a datastore",
+		Long: "Run every scenario of the storage test suite, which the datastores must all pass, against a live " +
+			"datastore, then print whether each passed and how long it took. The failures are printed as they " +
+			"happen. The scenarios write stores of their own to the datastore, so it shouldn't be one in use. " +
+			"The command fails if any scenario does.\n" +
+			"The datastores implemented outside of this repository can run the same scenarios with the " +
+			"'pkg/storage/conformance' package.",
+		RunE: runConformanceCmd,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(engineFlag, "", "the datastore engine ('memory', 'mysql', 'postgres', 'sqlite' or 'bolt')")
+	flags.String(uriFlag, "", "the connection uri to the datastore, migrated to the latest schema")
+	flags.String(runFlag, "", "a regular expression selecting the scenarios to run by name. If empty, they all run")
+	flags.Bool(verboseFlag, false, "print every scenario and subtest as it runs, rather than only the failures")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindConformanceFlagsFunc(flags)
+
+	return cmd
+}
+
+func runConformanceCmd(cmd *cobra.Command, _ []string) error {
+	opts := []conformance.Option{conformance.WithVerbose(viper.GetBool(verboseFlag))}
+	if pattern := viper.GetString(runFlag); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid '--%s' pattern: %w", runFlag, err)
+		}
+		opts = append(opts, conformance.WithPattern(re))
+	}
+
+	engine := viper.GetString(engineFlag)
+	ds, tokenSerializer, err := openDatastore(engine, viper.GetString(uriFlag))
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+	opts = append(opts, conformance.WithContinuationTokenSerializer(tokenSerializer))
+
+	report, err := runConformance(ds, opts...)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if _, err := fmt.Fprintf(out, "\nconformance of the '%s' datastore:\n\n", engine); err != nil {
+		return err
+	}
+	if err := report.Print(out); err != nil {
+		return err
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("the datastore failed the conformance scenarios")
+	}
+	return nil
+}
+
+// openDatastore opens the datastore, and returns it along with the serializer of its continuation tokens.
+func openDatastore(engine, uri string) (storage.OpenFGADatastore, encoder.ContinuationTokenSerializer, error) {
+	var (
+		db  storage.OpenFGADatastore
+		err error
+	)
+	tokenSerializer := encoder.ContinuationTokenSerializer(sqlcommon.NewSQLContinuationTokenSerializer())
+	switch engine {
+	case "memory":
+		tokenSerializer = encoder.NewStringContinuationTokenSerializer()
+		db = memory.New(memory.WithContinuationTokenSerializer(tokenSerializer))
+	case "mysql":
+		db, err = mysql.New(uri, sqlcommon.NewConfig(sqlcommon.WithContinuationTokenSerializer(tokenSerializer)))
+	case "postgres":
+		db, err = postgres.New(uri, sqlcommon.NewConfig(sqlcommon.WithContinuationTokenSerializer(tokenSerializer)))
+	case "sqlite":
+		db, err = sqlite.New(uri, sqlcommon.NewConfig(sqlcommon.WithContinuationTokenSerializer(tokenSerializer)))
+	case "bolt":
+		tokenSerializer = encoder.NewStringContinuationTokenSerializer()
+		db, err = bolt.New(uri, bolt.WithContinuationTokenSerializer(tokenSerializer))
+	case "":
+		return nil, nil, fmt.Errorf("missing datastore engine type")
+	default:
+		return nil, nil, fmt.Errorf("storage engine '%s' is unsupported", engine)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open a connection to the datastore: %v", err)
+	}
+	return db, tokenSerializer, nil
+}
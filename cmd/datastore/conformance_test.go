@@ -0,0 +1,47 @@
+package datastore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/conformance"
+)
+
+func TestConformanceCommand(t *testing.T) {
+	// the test binary drives the testing package already, so the scenarios run as subtests
+	runConformance = func(ds storage.OpenFGADatastore, opts ...conformance.Option) (*conformance.Report, error) {
+		return conformance.RunT(t, ds, opts...), nil
+	}
+	t.Cleanup(func() {
+		runConformance = conformance.Run
+	})
+
+	t.Run("prints_the_report", func(t *testing.T) {
+		_, _, uri := util.MustBootstrapDatastore(t, "sqlite")
+
+		var output bytes.Buffer
+		conformanceCmd := NewConformanceCommand()
+		conformanceCmd.SetOut(&output)
+		conformanceCmd.SetArgs([]string{"--engine", "sqlite", "--uri", uri, "--run", "^(TestDatastoreIsReady|TestStore)$"})
+		require.NoError(t, conformanceCmd.Execute())
+
+		require.Contains(t, output.String(), "conformance of the 'sqlite' datastore")
+		require.Regexp(t, `PASS\s+TestDatastoreIsReady`, output.String())
+		require.Regexp(t, `PASS\s+TestStore\s`, output.String())
+		require.Contains(t, output.String(), "2 passed, 0 failed, 0 skipped")
+	})
+
+	t.Run("invalid_flags", func(t *testing.T) {
+		conformanceCmd := NewConformanceCommand()
+		conformanceCmd.SetArgs([]string{"--engine", "sqlite", "--run", "("})
+		require.ErrorContains(t, conformanceCmd.Execute(), "invalid '--run' pattern")
+
+		conformanceCmd = NewConformanceCommand()
+		conformanceCmd.SetArgs([]string{"--engine", "cassandra", "--run", ""})
+		require.ErrorContains(t, conformanceCmd.Execute(), "storage engine 'cassandra' is unsupported")
+	})
+}
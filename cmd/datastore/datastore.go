@@ -0,0 +1,25 @@
+// Package datastore contains the commands to work with the datastore itself.
+package datastore
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	engineFlag  = "engine"
+	uriFlag     = "uri"
+	runFlag     = "run"
+	verboseFlag = "verbose"
+)
+
+func NewDatastoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datastore",
+		Short: "Work with the datastore",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewConformanceCommand())
+
+	return cmd
+}
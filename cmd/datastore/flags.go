@@ -0,0 +1,19 @@
+package datastore
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindConformanceFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindConformanceFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(engineFlag, flags.Lookup(engineFlag))
+		util.MustBindPFlag(uriFlag, flags.Lookup(uriFlag))
+		util.MustBindPFlag(runFlag, flags.Lookup(runFlag))
+		util.MustBindPFlag(verboseFlag, flags.Lookup(verboseFlag))
+	}
+}
@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const downToFlag = "to"
+
+func NewDownCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back database schema migrations",
+		Long: "Roll back the applied migrations down to, but not including, the version given with --to. " +
+			"The migrations are rolled back while holding the lock of the migrate command.",
+		RunE: runDown,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+
+	flags.Uint(downToFlag, 0, "(required) the version to roll back to, which stays applied")
+	flags.Bool(dryRunFlag, false, "print the SQL rolling back the migrations instead of running it")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindDownFlagsFunc(flags)
+
+	return cmd
+}
+
+func runDown(cmd *cobra.Command, _ []string) error {
+	if !viper.IsSet(downToFlag) {
+		return fmt.Errorf("missing version to roll back to")
+	}
+	targetVersion := int64(viper.GetUint(downToFlag))
+
+	m, err := newMigrator(cmd.Context())
+	if errors.Is(err, errNoMigrations) {
+		log.Println("no migrations to roll back for `memory` datastore")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer m.close()
+
+	currentVersion, err := m.provider.GetDBVersion(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get db version: %w", err)
+	}
+
+	log.Printf("current version %d", currentVersion)
+
+	if targetVersion >= currentVersion {
+		log.Println("nothing to do")
+		return nil
+	}
+
+	if viper.GetBool(dryRunFlag) {
+		return m.printApplied(cmd.Context(), cmd.OutOrStdout(), targetVersion)
+	}
+
+	log.Printf("rolling back to %d", targetVersion)
+	if _, err := m.provider.DownTo(cmd.Context(), targetVersion); err != nil {
+		return fmt.Errorf("failed to run migrations down to %v: %w", targetVersion, err)
+	}
+
+	log.Println("migration done")
+	return nil
+}
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+const gooseAnnotation = "-- +goose "
+
+// printPending prints the SQL of the pending migrations up to, and including, the version, or all of them if it
+// is zero, in the order they would be applied.
+func (m *migrator) printPending(ctx context.Context, w io.Writer, version int64) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the migrations status: %w", err)
+	}
+
+	for _, status := range statuses {
+		if status.State != goose.StatePending || (version != 0 && status.Source.Version > version) {
+			continue
+		}
+		if err := m.printMigration(w, status.Source, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printApplied prints the SQL rolling back the applied migrations down to, but not including, the version, in the
+// order they would be rolled back.
+func (m *migrator) printApplied(ctx context.Context, w io.Writer, version int64) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the migrations status: %w", err)
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		status := statuses[i]
+		if status.State != goose.StateApplied || status.Source.Version <= version {
+			continue
+		}
+		if err := m.printMigration(w, status.Source, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMigration prints the SQL of the up or down section of the migration, after a comment naming it.
+func (m *migrator) printMigration(w io.Writer, source *goose.Source, up bool) error {
+	statements, err := migrationSQL(m.migrations, source.Path, up)
+	if err != nil {
+		return err
+	}
+
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	_, err = fmt.Fprintf(w, "-- %s (%s)\n%s\n\n", source.Path, direction, statements)
+	return err
+}
+
+// migrationSQL returns the statements of the up or down section of the migration file, without the goose
+// annotations.
+func migrationSQL(migrations fs.FS, path string, up bool) (string, error) {
+	data, err := fs.ReadFile(migrations, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the migration '%s': %w", path, err)
+	}
+
+	var sb strings.Builder
+	var inUp, inDown bool
+	for _, line := range strings.Split(string(data), "\n") {
+		if annotation, ok := strings.CutPrefix(strings.TrimSpace(line), gooseAnnotation); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				inUp, inDown = true, false
+			case "Down":
+				inUp, inDown = false, true
+			}
+			continue
+		}
+		if (up && inUp) || (!up && inDown) {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
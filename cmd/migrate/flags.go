@@ -11,24 +11,50 @@ import (
 // by viper. This bridges the config between cobra flags and viper flags.
 func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 	return func(cmd *cobra.Command, args []string) {
-		util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
-		util.MustBindEnv(datastoreEngineFlag, "OPENFGA_DATASTORE_ENGINE")
+		bindDatastoreFlags(flags)
 
-		util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
-		util.MustBindEnv(datastoreURIFlag, "OPENFGA_DATASTORE_URI")
+		util.MustBindPFlag(versionFlag, flags.Lookup(versionFlag))
 
-		util.MustBindPFlag(datastoreUsernameFlag, flags.Lookup(datastoreUsernameFlag))
-		util.MustBindEnv(datastoreUsernameFlag, "OPENFGA_DATASTORE_USERNAME")
+		util.MustBindPFlag(dryRunFlag, flags.Lookup(dryRunFlag))
+	}
+}
 
-		util.MustBindPFlag(datastorePasswordFlag, flags.Lookup(datastorePasswordFlag))
-		util.MustBindEnv(datastorePasswordFlag, "OPENFGA_DATASTORE_PASSWORD")
+// bindStatusFlagsFunc binds the flags of the status command, those of the datastore.
+func bindStatusFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		bindDatastoreFlags(flags)
+	}
+}
 
-		util.MustBindPFlag(versionFlag, flags.Lookup(versionFlag))
+// bindDownFlagsFunc binds the flags of the down command.
+func bindDownFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		bindDatastoreFlags(flags)
 
-		util.MustBindPFlag(timeoutFlag, flags.Lookup(timeoutFlag))
-		util.MustBindEnv(timeoutFlag, "OPENFGA_TIMEOUT")
+		util.MustBindPFlag(downToFlag, flags.Lookup(downToFlag))
 
-		util.MustBindPFlag(verboseMigrationFlag, flags.Lookup(verboseMigrationFlag))
-		util.MustBindEnv(verboseMigrationFlag, "OPENFGA_VERBOSE")
+		util.MustBindPFlag(dryRunFlag, flags.Lookup(dryRunFlag))
 	}
 }
+
+// bindDatastoreFlags binds the persistent flags of the migrate command, inherited by its subcommands, whose flags,
+// once parsed, include them.
+func bindDatastoreFlags(flags *pflag.FlagSet) {
+	util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
+	util.MustBindEnv(datastoreEngineFlag, "OPENFGA_DATASTORE_ENGINE")
+
+	util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
+	util.MustBindEnv(datastoreURIFlag, "OPENFGA_DATASTORE_URI")
+
+	util.MustBindPFlag(datastoreUsernameFlag, flags.Lookup(datastoreUsernameFlag))
+	util.MustBindEnv(datastoreUsernameFlag, "OPENFGA_DATASTORE_USERNAME")
+
+	util.MustBindPFlag(datastorePasswordFlag, flags.Lookup(datastorePasswordFlag))
+	util.MustBindEnv(datastorePasswordFlag, "OPENFGA_DATASTORE_PASSWORD")
+
+	util.MustBindPFlag(timeoutFlag, flags.Lookup(timeoutFlag))
+	util.MustBindEnv(timeoutFlag, "OPENFGA_TIMEOUT")
+
+	util.MustBindPFlag(verboseMigrationFlag, flags.Lookup(verboseMigrationFlag))
+	util.MustBindEnv(verboseMigrationFlag, "OPENFGA_VERBOSE")
+}
@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3/lock"
+)
+
+const (
+	// lockName is the name of the advisory lock of MySQL held while running the migrations.
+	lockName = "openfga_migrate"
+
+	// lockTimeout is how long to wait for the lock held by another instance running the migrations.
+	lockTimeout = 5 * time.Minute
+
+	// lockFileSuffix is appended to the path of a sqlite database to get the path of its lock file.
+	lockFileSuffix = ".migrate-lock"
+)
+
+// newSessionLocker returns the lock held while running the migrations against the database of the engine, so that
+// several instances starting at once don't run them concurrently: an advisory lock for postgres and mysql, and a
+// lock file next to the database for sqlite. It returns nil for an in-memory sqlite database.
+func newSessionLocker(engine, uri string) (lock.SessionLocker, error) {
+	switch engine {
+	case "postgres":
+		// the lock is tried every 5 seconds, 60 times
+		return lock.NewPostgresSessionLocker(lock.WithLockTimeout(5, uint64(lockTimeout/(5*time.Second))))
+	case "mysql":
+		return &mysqlSessionLocker{}, nil
+	case "sqlite":
+		path := sqliteDatabasePath(uri)
+		if path == "" || path == ":memory:" || strings.Contains(uri, "mode=memory") {
+			return nil, nil
+		}
+		return &sqliteSessionLocker{path: path + lockFileSuffix}, nil
+	default:
+		return nil, fmt.Errorf("unknown datastore engine type: %s", engine)
+	}
+}
+
+// mysqlSessionLocker holds a named lock of MySQL, see https://dev.mysql.com/doc/refman/8.0/en/locking-functions.html.
+type mysqlSessionLocker struct{}
+
+var _ lock.SessionLocker = (*mysqlSessionLocker)(nil)
+
+func (l *mysqlSessionLocker) SessionLock(ctx context.Context, conn *sql.Conn) error {
+	// GET_LOCK returns 1 once the lock is acquired, 0 if it timed out, and NULL on error
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to execute GET_LOCK: %w", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("failed to acquire the lock '%s' within %s", lockName, lockTimeout)
+	}
+	return nil
+}
+
+func (l *mysqlSessionLocker) SessionUnlock(ctx context.Context, conn *sql.Conn) error {
+	var released sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", lockName).Scan(&released); err != nil {
+		return fmt.Errorf("failed to execute RELEASE_LOCK: %w", err)
+	}
+	if !released.Valid || released.Int64 != 1 {
+		return fmt.Errorf("failed to release the lock '%s'", lockName)
+	}
+	return nil
+}
+
+// sqliteSessionLocker holds an exclusive lock of a file, itself a sqlite database, so that the lock is portable and
+// released by the operating system even if the process dies. The lock isn't taken on the database being migrated,
+// which the migrations write to from other connections.
+type sqliteSessionLocker struct {
+	path string
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+var _ lock.SessionLocker = (*sqliteSessionLocker)(nil)
+
+func (l *sqliteSessionLocker) SessionLock(ctx context.Context, _ *sql.Conn) error {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)", l.path, lockTimeout.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("failed to open the lock file '%s': %w", l.path, err)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to open the lock file '%s': %w", l.path, err), db.Close())
+	}
+	// the exclusive transaction waits for the one of another instance for up to the busy timeout
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return errors.Join(fmt.Errorf("failed to lock the file '%s': %w", l.path, err), conn.Close(), db.Close())
+	}
+	l.db, l.conn = db, conn
+	return nil
+}
+
+func (l *sqliteSessionLocker) SessionUnlock(ctx context.Context, _ *sql.Conn) error {
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(ctx, "ROLLBACK")
+	err = errors.Join(err, l.conn.Close(), l.db.Close())
+	l.db, l.conn = nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to unlock the file '%s': %w", l.path, err)
+	}
+	return nil
+}
+
+// sqliteDatabasePath returns the path of the database file of the sqlite uri.
+func sqliteDatabasePath(uri string) string {
+	path, _, _ := strings.Cut(uri, "?")
+	return strings.TrimPrefix(path, "file:")
+}
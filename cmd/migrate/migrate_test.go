@@ -1,16 +1,24 @@
 package migrate
 
 import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 
+	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/cmd"
 	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/build"
 )
 
 const defaultDuration = 1 * time.Minute
@@ -46,6 +54,115 @@ func TestMigrateCommandRollbacks(t *testing.T) {
 	}
 }
 
+func TestMigrateCommandStatusDownAndDryRun(t *testing.T) {
+	uri := filepath.Join(t.TempDir(), "openfga.db")
+
+	migrate := func(t *testing.T, args ...string) (string, error) {
+		var output bytes.Buffer
+		migrateCommand := NewMigrateCommand()
+		migrateCommand.SetOut(&output)
+		migrateCommand.SetArgs(append(args, "--datastore-engine", "sqlite", "--datastore-uri", uri))
+		err := migrateCommand.Execute()
+		return output.String(), err
+	}
+
+	requireStatus := func(t *testing.T, version int, pending ...string) {
+		output, err := migrate(t, "status")
+		require.NoError(t, err)
+		require.Contains(t, output, fmt.Sprintf("current version: %d\n", version))
+		require.Contains(t, output, fmt.Sprintf("minimum version required: %d\n", build.MinimumSupportedDatastoreSchemaRevision))
+		require.Equal(t, len(pending), strings.Count(output, "pending"))
+		for _, migration := range pending {
+			require.Regexp(t, migration+` +pending +-\n`, output)
+		}
+	}
+
+	_, err := migrate(t, "--version", "8")
+	require.NoError(t, err)
+	requireStatus(t, 8, "009_add_store_tuple_count.sql", "010_add_outbox.sql")
+
+	t.Run("dry_run_up", func(t *testing.T) {
+		output, err := migrate(t, "--dry-run")
+		require.NoError(t, err)
+		require.Contains(t, output, "-- 009_add_store_tuple_count.sql (up)\nCREATE TABLE store_tuple_count (")
+		require.Contains(t, output, "-- 010_add_outbox.sql (up)\nCREATE TABLE outbox (")
+		require.NotContains(t, output, "+goose")
+		require.NotContains(t, output, "DROP TABLE")
+		requireStatus(t, 8, "009_add_store_tuple_count.sql", "010_add_outbox.sql")
+	})
+
+	t.Run("dry_run_down", func(t *testing.T) {
+		output, err := migrate(t, "down", "--to", "6", "--dry-run")
+		require.NoError(t, err)
+		require.Equal(t, "-- 008_add_store_labels.sql (down)\nDROP TABLE store_label;\n\n"+
+			"-- 007_add_changelog_horizon.sql (down)\nDROP TABLE changelog_horizon;\n\n", output)
+		requireStatus(t, 8, "009_add_store_tuple_count.sql", "010_add_outbox.sql")
+	})
+
+	t.Run("down", func(t *testing.T) {
+		_, err := migrate(t, "down")
+		require.ErrorContains(t, err, "missing version to roll back to")
+
+		_, err = migrate(t, "down", "--to", "6")
+		require.NoError(t, err)
+		requireStatus(t, 6, "007_add_changelog_horizon.sql", "008_add_store_labels.sql",
+			"009_add_store_tuple_count.sql", "010_add_outbox.sql")
+
+		// the lock file is left for the next migrations
+		require.FileExists(t, uri+lockFileSuffix)
+	})
+
+	t.Run("up", func(t *testing.T) {
+		_, err := migrate(t)
+		require.NoError(t, err)
+		requireStatus(t, int(build.MinimumSupportedDatastoreSchemaRevision))
+	})
+}
+
+func TestMigrationSQL(t *testing.T) {
+	migrations := fstest.MapFS{
+		"001_test.sql": {Data: []byte(`-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE test (id TEXT);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE test;
+`)},
+	}
+
+	up, err := migrationSQL(migrations, "001_test.sql", true)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE test (id TEXT);", up)
+
+	down, err := migrationSQL(migrations, "001_test.sql", false)
+	require.NoError(t, err)
+	require.Equal(t, "DROP TABLE test;", down)
+
+	_, err = migrationSQL(migrations, "002_missing.sql", true)
+	require.ErrorContains(t, err, "failed to read the migration '002_missing.sql'")
+}
+
+// TestMinimumSupportedDatastoreSchemaRevision ensures that the schema required by the binary is bumped along with
+// every migration, since the datastores query the tables and columns of all of them.
+func TestMinimumSupportedDatastoreSchemaRevision(t *testing.T) {
+	for _, dir := range []string{assets.MySQLMigrationDir, assets.PostgresMigrationDir, assets.SqliteMigrationDir} {
+		t.Run(dir, func(t *testing.T) {
+			entries, err := fs.ReadDir(assets.EmbedMigrations, dir)
+			require.NoError(t, err)
+
+			var latest int64
+			for _, entry := range entries {
+				prefix, _, _ := strings.Cut(entry.Name(), "_")
+				version, err := strconv.ParseInt(prefix, 10, 64)
+				require.NoError(t, err)
+				latest = max(latest, version)
+			}
+			require.Equal(t, latest, build.MinimumSupportedDatastoreSchemaRevision)
+		})
+	}
+}
+
 func TestMigrateCommandNoConfigDefaultValues(t *testing.T) {
 	util.PrepareTempConfigDir(t)
 	migrateCmd := NewMigrateCommand()
@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openfga/openfga/internal/build"
+)
+
+func NewStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the applied and pending database schema migrations",
+		Long: "Show the migrations of the datastore engine, with whether, and when, each was applied, the current " +
+			"version of the database schema, and the minimum one this version of OpenFGA requires.",
+		RunE: runStatus,
+		Args: cobra.NoArgs,
+	}
+
+	// NOTE: the flags are those of the migrate command, if you add a new one here, update the function below, too
+
+	cmd.PreRun = bindStatusFlagsFunc(cmd.Flags())
+
+	return cmd
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	m, err := newMigrator(cmd.Context())
+	if errors.Is(err, errNoMigrations) {
+		fmt.Fprintln(cmd.OutOrStdout(), "no migrations for `memory` datastore")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer m.close()
+
+	currentVersion, err := m.provider.GetDBVersion(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get db version: %w", err)
+	}
+	statuses, err := m.provider.Status(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get the migrations status: %w", err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "current version: %d\n", currentVersion)
+	fmt.Fprintf(w, "minimum version required: %d\n\n", build.MinimumSupportedDatastoreSchemaRevision)
+	fmt.Fprintln(w, "VERSION\tMIGRATION\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.Source.Path, status.State, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,95 @@
+package migratedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// storeProgress is the progress of the copy of a store.
+type storeProgress struct {
+	// ChangesAfter is the ULID of the last change of the store copied so far.
+	ChangesAfter string `json:"changes_after,omitempty"`
+	// Done reports whether the store was copied entirely.
+	Done bool `json:"done,omitempty"`
+}
+
+// checkpoint is the progress of a copy, saved to its file, if any, so that a failed copy can resume from it.
+type checkpoint struct {
+	path string
+
+	FromEngine string                    `json:"from_engine"`
+	ToEngine   string                    `json:"to_engine"`
+	Stores     map[string]*storeProgress `json:"stores"`
+}
+
+// loadCheckpoint loads the checkpoint of the file, or returns an empty one if the file doesn't exist or the path is
+// empty. A checkpoint of a copy between other engines is rejected.
+func loadCheckpoint(path, fromEngine, toEngine string) (*checkpoint, error) {
+	c := &checkpoint{
+		path:       path,
+		FromEngine: fromEngine,
+		ToEngine:   toEngine,
+		Stores:     make(map[string]*storeProgress),
+	}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("decode checkpoint '%s': %w", path, err)
+	}
+	if c.FromEngine != fromEngine || c.ToEngine != toEngine {
+		return nil, fmt.Errorf("the checkpoint '%s' is of a copy from '%s' to '%s'", path, c.FromEngine, c.ToEngine)
+	}
+	if c.Stores == nil {
+		c.Stores = make(map[string]*storeProgress)
+	}
+	return c, nil
+}
+
+// store returns the progress of the copy of the store, which save persists.
+func (c *checkpoint) store(id string) *storeProgress {
+	progress, ok := c.Stores[id]
+	if !ok {
+		progress = &storeProgress{}
+		c.Stores[id] = progress
+	}
+	return progress
+}
+
+// save writes the checkpoint to its file, if any, atomically.
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
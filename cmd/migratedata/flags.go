@@ -0,0 +1,22 @@
+package migratedata
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindMigrateDataFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindMigrateDataFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(fromEngineFlag, flags.Lookup(fromEngineFlag))
+		util.MustBindPFlag(fromURIFlag, flags.Lookup(fromURIFlag))
+		util.MustBindPFlag(toEngineFlag, flags.Lookup(toEngineFlag))
+		util.MustBindPFlag(toURIFlag, flags.Lookup(toURIFlag))
+		util.MustBindPFlag(outboxFlag, flags.Lookup(outboxFlag))
+		util.MustBindPFlag(storeIDFlag, flags.Lookup(storeIDFlag))
+		util.MustBindPFlag(checkpointFileFlag, flags.Lookup(checkpointFileFlag))
+	}
+}
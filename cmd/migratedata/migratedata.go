@@ -0,0 +1,207 @@
+// Package migratedata contains the command to copy the data of the stores from a datastore to another one.
+package migratedata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storecopy"
+)
+
+const (
+	fromEngineFlag     = "from-engine"
+	fromURIFlag        = "from-uri"
+	toEngineFlag       = "to-engine"
+	toURIFlag          = "to-uri"
+	storeIDFlag        = "store-id"
+	checkpointFileFlag = "checkpoint-file"
+	outboxFlag         = "outbox"
+
+	// pageSize is the number of stores, tuples, changes and authorization models read at once.
+	pageSize = 100
+)
+
+func NewMigrateDataCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-data",
+		Short: "Copy the data of the stores from a datastore to another one, of any engine",
+		Long: "Copy the stores, with their labels, authorization models, assertions, tuples and changelog, from a " +
+			"datastore to another one, e.g. from sqlite to postgres. Both must be migrated to the latest schema, " +
+			"and the source shouldn't be written to during the copy.\n" +
+			"The authorization models keep their IDs, and so their order. The tuples and changelog of each store " +
+			"are copied as they are stored, with their ULIDs, timestamps and expiry, so the continuation tokens of " +
+			"ReadChanges stay valid. The tuples are then reconciled, in case the changelog was pruned.\n" +
+			"With --checkpoint-file, the progress is saved as it goes, and a failed copy resumes from it when run " +
+			"again with the same file. Once done, the tuples, authorization models, assertions and changes of each " +
+			"store are counted, and the tuples and changes checksummed, on both datastores, and the command fails " +
+			"if they don't match.",
+		RunE: runMigrateData,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(fromEngineFlag, "", "the engine of the datastore to copy the stores from "+util.DatastoreEnginesUsage())
+	flags.String(fromURIFlag, "", "the connection uri of the datastore to copy the stores from")
+	flags.String(toEngineFlag, "", "the engine of the datastore to copy the stores to "+util.DatastoreEnginesUsage())
+	flags.String(toURIFlag, "", "the connection uri of the datastore to copy the stores to")
+	flags.Bool(outboxFlag, false, "record the creations of the stores and the writes of their authorization models in the outbox of the datastore the stores are copied to, as the servers do with --outbox-enabled. The tuples and changes copied as they are stored aren't recorded. It requires a datastore with the outbox migration applied")
+	flags.StringSlice(storeIDFlag, nil, "the ids of the stores to copy. If empty, all the stores are copied")
+	flags.String(checkpointFileFlag, "", "the file the progress of the copy is saved to, and resumed from. If empty, the progress isn't saved")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindMigrateDataFlagsFunc(flags)
+
+	return cmd
+}
+
+// migrateDataResult is the outcome of a copy, printed once it is done.
+type migrateDataResult struct {
+	Stores   []storeCounts `json:"stores"`
+	Duration string        `json:"duration"`
+}
+
+func runMigrateData(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	fromEngine, fromURI := viper.GetString(fromEngineFlag), viper.GetString(fromURIFlag)
+	toEngine, toURI := viper.GetString(toEngineFlag), viper.GetString(toURIFlag)
+	if fromEngine == toEngine && fromURI == toURI {
+		return fmt.Errorf("the datastores to copy the stores from and to must be different")
+	}
+
+	// the progress of the copy is logged to stderr, its result being printed to stdout
+	log, err := logger.NewLogger(logger.WithOutputPaths("stderr"))
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := loadCheckpoint(viper.GetString(checkpointFileFlag), fromEngine, toEngine)
+	if err != nil {
+		return err
+	}
+
+	source, err := util.OpenDatastore(fromEngine, fromURI, false)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	defer source.Close()
+
+	target, err := util.OpenDatastore(toEngine, toURI, viper.GetBool(outboxFlag))
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+	defer target.Close()
+
+	start := time.Now()
+	stores, err := listStores(ctx, source, viper.GetStringSlice(storeIDFlag))
+	if err != nil {
+		return err
+	}
+
+	for _, store := range stores {
+		if err := copyStore(ctx, source, target, store, checkpoint, log); err != nil {
+			return fmt.Errorf("failed to copy the store '%s': %w", store.GetId(), err)
+		}
+	}
+
+	result := migrateDataResult{Stores: make([]storeCounts, 0, len(stores))}
+	var mismatched []string
+	for _, store := range stores {
+		counts, err := countStore(ctx, source, target, store.GetId())
+		if err != nil {
+			return fmt.Errorf("failed to verify the store '%s': %w", store.GetId(), err)
+		}
+		result.Stores = append(result.Stores, counts)
+		if !counts.Verified {
+			mismatched = append(mismatched, store.GetId())
+		}
+	}
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	marshalled, err := json.MarshalIndent(result, " ", "    ")
+	if err != nil {
+		return fmt.Errorf("error gathering copy results: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(marshalled))
+
+	if len(mismatched) > 0 {
+		return fmt.Errorf("the counts of the copies of the stores %v don't match those of the source", mismatched)
+	}
+	return nil
+}
+
+// listStores returns the stores of the datastore with the IDs, or all of them if there are none.
+func listStores(ctx context.Context, ds storage.OpenFGADatastore, ids []string) ([]*openfgav1.Store, error) {
+	var stores []*openfgav1.Store
+	var token string
+	for {
+		page, next, err := ds.ListStores(ctx, storage.ListStoresOptions{
+			IDs:        ids,
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list stores: %w", err)
+		}
+		stores = append(stores, page...)
+		if len(next) == 0 {
+			break
+		}
+		token = string(next)
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(stores, func(s *openfgav1.Store) bool { return s.GetId() == id }) {
+			return nil, fmt.Errorf("the store '%s' doesn't exist in the source", id)
+		}
+	}
+	return stores, nil
+}
+
+// copyStore copies the store from the source to the target, resuming from its checkpoint, unless it was already.
+func copyStore(ctx context.Context, source, target storage.OpenFGADatastore, store *openfgav1.Store, checkpoint *checkpoint, log logger.Logger) error {
+	progress := checkpoint.store(store.GetId())
+	if progress.Done {
+		log.Info("skipping store copied already", zap.String("store_id", store.GetId()))
+		return nil
+	}
+	log.Info("copying store", zap.String("store_id", store.GetId()), zap.String("changes_after", progress.ChangesAfter))
+
+	copier, err := storecopy.New(store.GetId(), source, target, storecopy.WithChangesCheckpoint(func(after string) error {
+		progress.ChangesAfter = after
+		return checkpoint.save()
+	}))
+	if err != nil {
+		return err
+	}
+
+	if err := copier.CreateStore(ctx, store); err != nil {
+		return err
+	}
+	if err := copier.CopyModels(ctx); err != nil {
+		return err
+	}
+	if _, err := copier.CopyChanges(ctx, progress.ChangesAfter); err != nil {
+		return err
+	}
+	if err := copier.ReconcileTuples(ctx); err != nil {
+		return err
+	}
+
+	progress.Done = true
+	if err := checkpoint.save(); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	log.Info("copied store", zap.String("store_id", store.GetId()))
+	return nil
+}
@@ -0,0 +1,186 @@
+package migratedata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/bolt"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// writeStore writes a store to the datastore, with two authorization models, assertions, and tuples with conditions
+// and expiry.
+func writeStore(t *testing.T, ds storage.OpenFGADatastore, name string) (string, []*openfgav1.AuthorizationModel) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: name})
+	require.NoError(t, err)
+
+	var models []*openfgav1.AuthorizationModel
+	for i := 0; i < 2; i++ {
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user, user with in_office]
+			condition in_office(ip: ipaddress) {
+				ip.in_cidr("10.0.0.0/8")
+			}`)
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+		require.NoError(t, ds.WriteAssertions(ctx, storeID, model.GetId(), []*openfgav1.Assertion{
+			{TupleKey: tuple.NewAssertionTupleKey("document:1", "viewer", "user:anne"), Expectation: true},
+		}))
+		models = append(models, model)
+	}
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	}, storage.WithExpiresAt(time.Now().Add(time.Hour))))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("document:2", "viewer", "user:bob", "in_office", nil),
+		tuple.NewTupleKey("document:3", "viewer", "user:carl"),
+	}))
+	require.NoError(t, ds.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+		tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:3", "viewer", "user:carl")),
+	}, nil))
+
+	return storeID, models
+}
+
+func TestMigrateDataCommand(t *testing.T) {
+	ctx := context.Background()
+	_, source, sourceURI := util.MustBootstrapDatastore(t, "sqlite")
+	targetURI := filepath.Join(t.TempDir(), "openfga.db")
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	store1, models := writeStore(t, source, "first")
+	store2, _ := writeStore(t, source, "second")
+
+	migrateData := func(t *testing.T, args ...string) (migrateDataResult, error) {
+		var output bytes.Buffer
+		migrateDataCmd := NewMigrateDataCommand()
+		migrateDataCmd.SetOut(&output)
+		migrateDataCmd.SetArgs(append([]string{
+			"--from-engine", "sqlite",
+			"--from-uri", sourceURI,
+			"--to-engine", "bolt",
+			"--to-uri", targetURI,
+		}, args...))
+		err := migrateDataCmd.ExecuteContext(ctx)
+
+		var result migrateDataResult
+		if err == nil {
+			require.NoError(t, json.Unmarshal(output.Bytes(), &result))
+		}
+		return result, err
+	}
+
+	t.Run("copies_the_stores_with_the_ids", func(t *testing.T) {
+		result, err := migrateData(t, "--store-id", store1, "--checkpoint-file", checkpointFile)
+		require.NoError(t, err)
+
+		require.Len(t, result.Stores, 1)
+		counts := result.Stores[0]
+		require.Equal(t, store1, counts.StoreID)
+		require.Equal(t, count{Source: 2, Target: 2}, counts.Tuples)
+		require.Equal(t, count{Source: 2, Target: 2}, counts.AuthorizationModels)
+		require.Equal(t, count{Source: 2, Target: 2}, counts.Assertions)
+		require.Equal(t, count{Source: 4, Target: 4}, counts.Changes)
+		require.NotEmpty(t, counts.TuplesChecksum.Source)
+		require.Equal(t, counts.TuplesChecksum.Source, counts.TuplesChecksum.Target)
+		require.NotEmpty(t, counts.ChangesChecksum.Source)
+		require.Equal(t, counts.ChangesChecksum.Source, counts.ChangesChecksum.Target)
+		require.True(t, counts.Verified)
+
+		target, err := bolt.New(targetURI)
+		require.NoError(t, err)
+		defer target.Close()
+
+		// the tuples keep their ULIDs and expiry
+		anne := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+		sourceRecord, err := source.(storage.RecordCopier).ReadTupleRecord(ctx, store1, anne)
+		require.NoError(t, err)
+		targetRecord, err := target.ReadTupleRecord(ctx, store1, anne)
+		require.NoError(t, err)
+		require.Equal(t, sourceRecord.Ulid, targetRecord.Ulid)
+		require.NotNil(t, targetRecord.ExpiresAt)
+		require.True(t, sourceRecord.ExpiresAt.Equal(*targetRecord.ExpiresAt))
+
+		store, err := target.GetStore(ctx, store1)
+		require.NoError(t, err)
+		require.Equal(t, "first", store.GetName())
+
+		latest, err := target.FindLatestAuthorizationModel(ctx, store1)
+		require.NoError(t, err)
+		require.Equal(t, models[1].GetId(), latest.GetId())
+
+		tk, err := target.ReadUserTuple(ctx, store1, tuple.NewTupleKey("document:2", "viewer", "user:bob"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "in_office", tk.GetKey().GetCondition().GetName())
+
+		_, err = target.GetStore(ctx, store2)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("resumes_from_the_checkpoint", func(t *testing.T) {
+		result, err := migrateData(t, "--store-id", "", "--checkpoint-file", checkpointFile)
+		require.NoError(t, err)
+		require.Len(t, result.Stores, 2)
+		for _, counts := range result.Stores {
+			require.True(t, counts.Verified)
+		}
+
+		var saved checkpoint
+		data, err := os.ReadFile(checkpointFile)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &saved))
+		require.True(t, saved.Stores[store1].Done)
+		require.True(t, saved.Stores[store2].Done)
+	})
+
+	t.Run("records_the_changes_in_the_outbox", func(t *testing.T) {
+		otherTargetURI := filepath.Join(t.TempDir(), "openfga.db")
+		_, err := migrateData(t, "--store-id", store2, "--checkpoint-file", "", "--to-uri", otherTargetURI, "--outbox")
+		require.NoError(t, err)
+
+		target, err := bolt.New(otherTargetURI)
+		require.NoError(t, err)
+		defer target.Close()
+
+		events, err := target.ReadOutbox(ctx, 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+		require.Equal(t, storage.EventStoreCreated, events[0].Type)
+		require.Equal(t, store2, events[0].StoreID)
+		for _, event := range events[1:] {
+			require.Equal(t, storage.EventAuthorizationModelWritten, event.Type)
+		}
+	})
+
+	t.Run("invalid_arguments", func(t *testing.T) {
+		_, err := migrateData(t, "--store-id", ulid.Make().String(), "--checkpoint-file", "")
+		require.ErrorContains(t, err, "doesn't exist in the source")
+
+		checkpointOfOtherEngines := filepath.Join(t.TempDir(), "checkpoint.json")
+		require.NoError(t, os.WriteFile(checkpointOfOtherEngines, []byte(`{"from_engine":"mysql","to_engine":"postgres"}`), 0o600))
+		_, err = migrateData(t, "--store-id", "", "--checkpoint-file", checkpointOfOtherEngines)
+		require.ErrorContains(t, err, "is of a copy from 'mysql' to 'postgres'")
+
+		_, err = migrateData(t, "--to-engine", "sqlite", "--to-uri", sourceURI)
+		require.ErrorContains(t, err, "must be different")
+	})
+}
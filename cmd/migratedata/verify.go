@@ -0,0 +1,213 @@
+package migratedata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// count is the number of items of a store on the source and the target.
+type count struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+}
+
+// checksum is the checksum of items of a store on the source and the target.
+type checksum struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// storeCounts are the counts of the data of a store on the source and the target.
+type storeCounts struct {
+	StoreID             string `json:"store_id"`
+	Tuples              count  `json:"tuples"`
+	AuthorizationModels count  `json:"authorization_models"`
+	Assertions          count  `json:"assertions"`
+	Changes             count  `json:"changes"`
+	// TuplesChecksum and ChangesChecksum are the checksums of the tuples and changes, along with their ULIDs,
+	// conditions, expiry and timestamps, to the second, as precise as every engine stores them.
+	TuplesChecksum  checksum `json:"tuples_checksum"`
+	ChangesChecksum checksum `json:"changes_checksum"`
+	// Verified reports whether the counts and checksums match.
+	Verified bool `json:"verified"`
+}
+
+// storeData are the counts and checksums of the data of a store on a datastore.
+type storeData struct {
+	tuples, models, assertions, changes int
+	tuplesChecksum, changesChecksum     string
+}
+
+// countStore counts the data of the store on the source and the target.
+func countStore(ctx context.Context, source, target storage.OpenFGADatastore, store string) (storeCounts, error) {
+	sourceData, err := countData(ctx, source, store)
+	if err != nil {
+		return storeCounts{}, fmt.Errorf("source: %w", err)
+	}
+	targetData, err := countData(ctx, target, store)
+	if err != nil {
+		return storeCounts{}, fmt.Errorf("target: %w", err)
+	}
+
+	counts := storeCounts{
+		StoreID:             store,
+		Tuples:              count{Source: sourceData.tuples, Target: targetData.tuples},
+		AuthorizationModels: count{Source: sourceData.models, Target: targetData.models},
+		Assertions:          count{Source: sourceData.assertions, Target: targetData.assertions},
+		Changes:             count{Source: sourceData.changes, Target: targetData.changes},
+		TuplesChecksum:      checksum{Source: sourceData.tuplesChecksum, Target: targetData.tuplesChecksum},
+		ChangesChecksum:     checksum{Source: sourceData.changesChecksum, Target: targetData.changesChecksum},
+	}
+	counts.Verified = counts.Tuples.Source == counts.Tuples.Target &&
+		counts.AuthorizationModels.Source == counts.AuthorizationModels.Target &&
+		counts.Assertions.Source == counts.Assertions.Target &&
+		counts.Changes.Source == counts.Changes.Target &&
+		counts.TuplesChecksum.Source == counts.TuplesChecksum.Target &&
+		counts.ChangesChecksum.Source == counts.ChangesChecksum.Target
+	return counts, nil
+}
+
+// countData returns the counts and checksums of the tuples, authorization models, assertions and changes of the
+// store.
+func countData(ctx context.Context, ds storage.OpenFGADatastore, store string) (storeData, error) {
+	copier, ok := storage.As[storage.RecordCopier](ds)
+	if !ok {
+		return storeData{}, fmt.Errorf("read the tuples and changes as they are stored: %w", errors.ErrUnsupported)
+	}
+
+	var data storeData
+	var err error
+	data.tuples, data.tuplesChecksum, err = countTuples(ctx, copier, store)
+	if err != nil {
+		return storeData{}, err
+	}
+	data.models, data.assertions, err = countModels(ctx, ds, store)
+	if err != nil {
+		return storeData{}, err
+	}
+	data.changes, data.changesChecksum, err = countChanges(ctx, copier, store)
+	if err != nil {
+		return storeData{}, err
+	}
+	return data, nil
+}
+
+// countTuples returns the number of tuples of the store, including those which expired but weren't deleted yet,
+// and their checksum.
+func countTuples(ctx context.Context, copier storage.RecordCopier, store string) (int, string, error) {
+	var n int
+	var sum uint64
+	var token string
+	for {
+		records, next, err := copier.ReadTupleRecords(ctx, store, storage.NewPaginationOptions(pageSize, token))
+		if err != nil {
+			return 0, "", fmt.Errorf("read tuples: %w", err)
+		}
+		for _, record := range records {
+			expiresAt := "-"
+			if record.ExpiresAt != nil {
+				expiresAt = strconv.FormatInt(record.ExpiresAt.Truncate(time.Microsecond).UnixMicro(), 10)
+			}
+			h, err := hashItem(record.Ulid, record.AsTuple().GetKey(), strconv.FormatInt(record.InsertedAt.Unix(), 10), expiresAt)
+			if err != nil {
+				return 0, "", err
+			}
+			sum += h
+		}
+		n += len(records)
+		if len(next) == 0 {
+			return n, formatChecksum(sum), nil
+		}
+		token = string(next)
+	}
+}
+
+// countModels returns the number of authorization models of the store, and of their assertions.
+func countModels(ctx context.Context, ds storage.OpenFGADatastore, store string) (int, int, error) {
+	var models, assertions int
+	var token string
+	for {
+		page, next, err := ds.ReadAuthorizationModels(ctx, store, storage.ReadAuthorizationModelsOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("read authorization models: %w", err)
+		}
+		for _, model := range page {
+			modelAssertions, err := ds.ReadAssertions(ctx, store, model.GetId())
+			if err != nil {
+				return 0, 0, fmt.Errorf("read assertions of authorization model '%s': %w", model.GetId(), err)
+			}
+			assertions += len(modelAssertions)
+		}
+		models += len(page)
+		if len(next) == 0 {
+			return models, assertions, nil
+		}
+		token = string(next)
+	}
+}
+
+// countChanges returns the number of changes of the store, and their checksum.
+func countChanges(ctx context.Context, copier storage.RecordCopier, store string) (int, string, error) {
+	var n int
+	var sum uint64
+	var after string
+	for {
+		changes, err := copier.ReadChangeRecords(ctx, store, after, pageSize)
+		if err != nil {
+			return 0, "", fmt.Errorf("read changes: %w", err)
+		}
+		for _, change := range changes {
+			h, err := hashItem(change.Ulid, change.TupleKey, change.Operation.String(), strconv.FormatInt(change.InsertedAt.Unix(), 10))
+			if err != nil {
+				return 0, "", err
+			}
+			sum += h
+		}
+		n += len(changes)
+		if len(changes) < pageSize {
+			return n, formatChecksum(sum), nil
+		}
+		after = changes[len(changes)-1].Ulid
+	}
+}
+
+// hashItem hashes a tuple or change identified by its ULID, with its key, condition and fields. The hashes of the
+// items are summed, so that the checksum doesn't depend on the order they are read in.
+func hashItem(id string, tk *openfgav1.TupleKey, fields ...string) (uint64, error) {
+	// an empty context is the same as none
+	var conditionContext []byte
+	if len(tk.GetCondition().GetContext().GetFields()) > 0 {
+		var err error
+		conditionContext, err = proto.MarshalOptions{Deterministic: true}.Marshal(tk.GetCondition().GetContext())
+		if err != nil {
+			return 0, fmt.Errorf("marshal condition context: %w", err)
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(strings.Join(append([]string{
+		id,
+		tuple.TupleKeyToString(tk),
+		tk.GetCondition().GetName(),
+		string(conditionContext),
+	}, fields...), "\x00")))
+	return binary.BigEndian.Uint64(h.Sum(nil)), nil
+}
+
+func formatChecksum(sum uint64) string {
+	return fmt.Sprintf("%016x", sum)
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	fromFlag             = "from"
+	toFlag               = "to"
+	maxInvalidTuplesFlag = "max-invalid-tuples"
+	failOnBreakingFlag   = "fail-on-breaking"
+
+	defaultMaxInvalidTuples = 100
+)
+
+// ErrBreakingChanges is returned by the diff command with --fail-on-breaking when the new model has breaking changes.
+var ErrBreakingChanges = errors.New("the new model has breaking changes")
+
+func NewDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare an authorization model with a previous one",
+		Long: "Compare an authorization model with a previous one, read from a file or from a store, and report the " +
+			"types, relations, rewrites, type restrictions and conditions that changed as JSON.\n" +
+			"If a store is given, its tuples are read directly from the datastore to count those that would become " +
+			"invalid under the new model. Model files are read in JSON if their extension is .json, and in the DSL otherwise.",
+		RunE: runDiff,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(toFlag, "", "the file of the new model")
+	flags.String(fromFlag, "", "the file of the previous model. If empty, the model of the store is used")
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.String(storeIDFlag, "", "the id of the store whose tuples are checked against the new model")
+	flags.String(modelIDFlag, "", "the id of the model of the store to compare with if --from is empty. If empty, the latest model is used")
+	flags.Int(maxInvalidTuplesFlag, defaultMaxInvalidTuples, "the maximum number of tuples that would become invalid reported")
+	flags.Bool(failOnBreakingFlag, false, "exit with an error if the new model has breaking changes or makes tuples invalid")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindDiffFlagsFunc(flags)
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, _ []string) error {
+	toFile := viper.GetString(toFlag)
+	if toFile == "" {
+		return fmt.Errorf("missing new model file")
+	}
+
+	to, err := readModelFile(toFile)
+	if err != nil {
+		return err
+	}
+
+	var from *openfgav1.AuthorizationModel
+	if fromFile := viper.GetString(fromFlag); fromFile != "" {
+		from, err = readModelFile(fromFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	storeID := viper.GetString(storeIDFlag)
+	if from == nil && storeID == "" {
+		return fmt.Errorf("missing previous model file or store id")
+	}
+
+	var db storage.OpenFGADatastore
+	if storeID != "" {
+		db, err = util.OpenDatastore(viper.GetString(datastoreEngineFlag), viper.GetString(datastoreURIFlag), false)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+	}
+
+	result, err := DiffModels(cmd.Context(), db, storeID, viper.GetString(modelIDFlag), from, to, viper.GetInt(maxInvalidTuplesFlag))
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+
+	if viper.GetBool(failOnBreakingFlag) && (result.IsBreaking() || result.InvalidTupleCount > 0) {
+		return ErrBreakingChanges
+	}
+	return nil
+}
+
+// DiffModels compares the new model with the previous one. If from is nil, the previous model is the one of the
+// store with the model id, or its latest model if the id is empty. If the datastore is not nil, the tuples of the
+// store that would become invalid under the new model are counted.
+func DiffModels(
+	ctx context.Context,
+	db storage.OpenFGADatastore,
+	storeID, modelID string,
+	from, to *openfgav1.AuthorizationModel,
+	maxInvalidTuples int,
+) (*commands.DiffAuthorizationModelsResult, error) {
+	if to.GetId() == "" {
+		to.Id = ulid.Make().String()
+	}
+	toTypesys, err := typesystem.NewAndValidate(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid new model: %w", err)
+	}
+
+	if from == nil {
+		if modelID == "" {
+			from, err = db.FindLatestAuthorizationModel(ctx, storeID)
+		} else {
+			from, err = db.ReadAuthorizationModel(ctx, storeID, modelID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the model of the store: %w", err)
+		}
+	}
+	fromTypesys, err := typesystem.New(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous model: %w", err)
+	}
+
+	if db == nil {
+		return &commands.DiffAuthorizationModelsResult{ModelDiff: typesystem.Diff(fromTypesys, toTypesys)}, nil
+	}
+
+	return commands.NewDiffAuthorizationModelsQuery(db,
+		commands.WithDiffAuthModelsQueryMaxInvalidTuples(maxInvalidTuples),
+	).Execute(ctx, storeID, fromTypesys, toTypesys)
+}
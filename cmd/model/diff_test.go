@@ -0,0 +1,115 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	previousModel = `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user, user:*]`
+
+	newModel = `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestDiffModels(t *testing.T) {
+	ctx := context.Background()
+	_, ds, _ := util.MustBootstrapDatastore(t, "sqlite")
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, testutils.MustTransformDSLToProtoWithID(previousModel)))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:*"),
+	}))
+
+	result, err := DiffModels(ctx, ds, storeID, "", nil, testutils.MustTransformDSLToProtoWithID(newModel), 10)
+	require.NoError(t, err)
+	require.True(t, result.IsBreaking())
+	require.Equal(t, int64(2), result.ScannedTupleCount)
+	require.Equal(t, int64(1), result.InvalidTupleCount)
+	require.Equal(t, "document:2#viewer@user:*", result.InvalidTuples[0].Tuple)
+}
+
+func TestDiffCommandWithFiles(t *testing.T) {
+	from := writeFile(t, "from.fga", previousModel)
+	to := writeFile(t, "to.fga", newModel)
+
+	var output bytes.Buffer
+	diffCmd := NewDiffCommand()
+	diffCmd.SetOut(&output)
+	diffCmd.SetArgs([]string{"--from", from, "--to", to, "--fail-on-breaking"})
+	require.ErrorIs(t, diffCmd.Execute(), ErrBreakingChanges)
+
+	// the diff is followed by the usage of the command
+	var result commands.DiffAuthorizationModelsResult
+	require.NoError(t, json.NewDecoder(&output).Decode(&result))
+	require.Len(t, result.NarrowedTypeRestrictions, 1)
+	require.Equal(t, []string{"user:*"}, result.NarrowedTypeRestrictions[0].RemovedTypes)
+}
+
+func TestDiffCommandWhenInvalidFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args          []string
+		errorExpected string
+	}{
+		{
+			args:          []string{},
+			errorExpected: "missing new model file",
+		},
+		{
+			args:          []string{"--to", writeFile(t, "to.fga", newModel)},
+			errorExpected: "missing previous model file or store id",
+		},
+		{
+			args:          []string{"--to", writeFile(t, "to.json", newModel), "--from", writeFile(t, "from.fga", previousModel)},
+			errorExpected: "invalid model",
+		},
+		{
+			args:          []string{"--to", writeFile(t, "to.fga", newModel), "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS", "--datastore-engine", "memory"},
+			errorExpected: "storage engine 'memory' is unsupported",
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			diffCmd := NewDiffCommand()
+			diffCmd.SetArgs(tc.args)
+			require.ErrorContains(t, diffCmd.Execute(), tc.errorExpected)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindDiffFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindDiffFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(toFlag, flags.Lookup(toFlag))
+		util.MustBindPFlag(fromFlag, flags.Lookup(fromFlag))
+		util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
+		util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
+		util.MustBindPFlag(storeIDFlag, flags.Lookup(storeIDFlag))
+		util.MustBindPFlag(modelIDFlag, flags.Lookup(modelIDFlag))
+		util.MustBindPFlag(maxInvalidTuplesFlag, flags.Lookup(maxInvalidTuplesFlag))
+		util.MustBindPFlag(failOnBreakingFlag, flags.Lookup(failOnBreakingFlag))
+	}
+}
@@ -0,0 +1,51 @@
+// Package model contains the commands to work with authorization models.
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/language/pkg/go/transformer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	datastoreEngineFlag = "datastore-engine"
+	datastoreURIFlag    = "datastore-uri"
+	storeIDFlag         = "store-id"
+	modelIDFlag         = "model-id"
+)
+
+func NewModelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "model",
+		Short: "Work with authorization models",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewDiffCommand())
+
+	return cmd
+}
+
+// readModelFile reads an authorization model from a file, in JSON if its extension is .json and in the DSL otherwise.
+func readModelFile(file string) (*openfgav1.AuthorizationModel, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var model *openfgav1.AuthorizationModel
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		model, err = transformer.LoadJSONStringToProto(string(data))
+	} else {
+		model, err = transformer.TransformDSLToProto(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid model in '%s': %w", file, err)
+	}
+	return model, nil
+}
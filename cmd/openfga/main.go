@@ -5,8 +5,13 @@ import (
 	"os"
 
 	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/datastore"
 	"github.com/openfga/openfga/cmd/migrate"
+	"github.com/openfga/openfga/cmd/migratedata"
+	"github.com/openfga/openfga/cmd/model"
 	"github.com/openfga/openfga/cmd/run"
+	"github.com/openfga/openfga/cmd/shards"
+	"github.com/openfga/openfga/cmd/tuples"
 	"github.com/openfga/openfga/cmd/validatemodels"
 )
 
@@ -19,9 +24,30 @@ func main() {
 	migrateCmd := migrate.NewMigrateCommand()
 	rootCmd.AddCommand(migrateCmd)
 
+	migrateDataCmd := migratedata.NewMigrateDataCommand()
+	rootCmd.AddCommand(migrateDataCmd)
+
 	validateModelsCmd := validatemodels.NewValidateCommand()
 	rootCmd.AddCommand(validateModelsCmd)
 
+	importCmd := tuples.NewImportCommand()
+	rootCmd.AddCommand(importCmd)
+
+	exportCmd := tuples.NewExportCommand()
+	rootCmd.AddCommand(exportCmd)
+
+	gcTuplesCmd := tuples.NewGCCommand()
+	rootCmd.AddCommand(gcTuplesCmd)
+
+	modelCmd := model.NewModelCommand()
+	rootCmd.AddCommand(modelCmd)
+
+	shardsCmd := shards.NewShardsCommand()
+	rootCmd.AddCommand(shardsCmd)
+
+	datastoreCmd := datastore.NewDatastoreCommand()
+	rootCmd.AddCommand(datastoreCmd)
+
 	versionCmd := cmd.NewVersionCommand()
 	rootCmd.AddCommand(versionCmd)
 
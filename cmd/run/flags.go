@@ -116,6 +116,36 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("datastore.metrics.enabled", flags.Lookup("datastore-metrics-enabled"))
 		util.MustBindEnv("datastore.metrics.enabled", "OPENFGA_DATASTORE_METRICS_ENABLED")
 
+		util.MustBindPFlag("datastore.metrics.storeLabelLimit", flags.Lookup("datastore-metrics-store-label-limit"))
+		util.MustBindEnv("datastore.metrics.storeLabelLimit", "OPENFGA_DATASTORE_METRICS_STORE_LABEL_LIMIT")
+
+		util.MustBindPFlag("datastore.readReplicaURIs", flags.Lookup("datastore-read-replica-uris"))
+		util.MustBindEnv("datastore.readReplicaURIs", "OPENFGA_DATASTORE_READ_REPLICA_URIS")
+
+		util.MustBindPFlag("datastore.replicaMaxLag", flags.Lookup("datastore-replica-max-lag"))
+		util.MustBindEnv("datastore.replicaMaxLag", "OPENFGA_DATASTORE_REPLICA_MAX_LAG")
+
+		util.MustBindPFlag("datastore.replicaCheckInterval", flags.Lookup("datastore-replica-check-interval"))
+		util.MustBindEnv("datastore.replicaCheckInterval", "OPENFGA_DATASTORE_REPLICA_CHECK_INTERVAL")
+
+		util.MustBindPFlag("datastore.memory.dir", flags.Lookup("datastore-memory-dir"))
+		util.MustBindEnv("datastore.memory.dir", "OPENFGA_DATASTORE_MEMORY_DIR")
+
+		util.MustBindPFlag("datastore.memory.fsync", flags.Lookup("datastore-memory-fsync"))
+		util.MustBindEnv("datastore.memory.fsync", "OPENFGA_DATASTORE_MEMORY_FSYNC")
+
+		util.MustBindPFlag("datastore.memory.snapshotInterval", flags.Lookup("datastore-memory-snapshot-interval"))
+		util.MustBindEnv("datastore.memory.snapshotInterval", "OPENFGA_DATASTORE_MEMORY_SNAPSHOT_INTERVAL")
+
+		util.MustBindPFlag("datastore.shards", flags.Lookup("datastore-shards"))
+		util.MustBindEnv("datastore.shards", "OPENFGA_DATASTORE_SHARDS")
+
+		util.MustBindPFlag("datastore.shardPlacement", flags.Lookup("datastore-shard-placement"))
+		util.MustBindEnv("datastore.shardPlacement", "OPENFGA_DATASTORE_SHARD_PLACEMENT")
+
+		util.MustBindPFlag("datastore.shardPlacementCacheTTL", flags.Lookup("datastore-shard-placement-cache-ttl"))
+		util.MustBindEnv("datastore.shardPlacementCacheTTL", "OPENFGA_DATASTORE_SHARD_PLACEMENT_CACHE_TTL")
+
 		util.MustBindPFlag("playground.enabled", flags.Lookup("playground-enabled"))
 		util.MustBindEnv("playground.enabled", "OPENFGA_PLAYGROUND_ENABLED")
 
@@ -179,6 +209,12 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("maxConcurrentReadsForCheck", flags.Lookup("max-concurrent-reads-for-check"))
 		util.MustBindEnv("maxConcurrentReadsForCheck", "OPENFGA_MAX_CONCURRENT_READS_FOR_CHECK", "OPENFGA_MAXCONCURRENTREADSFORCHECK")
 
+		util.MustBindPFlag("maxChecksPerBatchCheck", flags.Lookup("max-checks-per-batch-check"))
+		util.MustBindEnv("maxChecksPerBatchCheck", "OPENFGA_MAX_CHECKS_PER_BATCH_CHECK", "OPENFGA_MAXCHECKSPERBATCHCHECK")
+
+		util.MustBindPFlag("maxConcurrentChecksPerBatchCheck", flags.Lookup("max-concurrent-checks-per-batch-check"))
+		util.MustBindEnv("maxConcurrentChecksPerBatchCheck", "OPENFGA_MAX_CONCURRENT_CHECKS_PER_BATCH_CHECK", "OPENFGA_MAXCONCURRENTCHECKSPERBATCHCHECK")
+
 		util.MustBindPFlag("maxConditionEvaluationCost", flags.Lookup("max-condition-evaluation-cost"))
 		util.MustBindEnv("maxConditionEvaluationCost", "OPENFGA_MAX_CONDITION_EVALUATION_COST", "OPENFGA_MAXCONDITIONEVALUATIONCOST")
 
@@ -207,6 +243,27 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("cache.limit", flags.Lookup("check-query-cache-limit"))
 		util.MustBindEnv("cache.limit", "OPENFGA_CHECK_QUERY_CACHE_LIMIT")
 
+		util.MustBindPFlag("cache.shared.addr", flags.Lookup("shared-cache-addr"))
+		util.MustBindEnv("cache.shared.addr", "OPENFGA_SHARED_CACHE_ADDR")
+
+		util.MustBindPFlag("cache.shared.username", flags.Lookup("shared-cache-username"))
+		util.MustBindEnv("cache.shared.username", "OPENFGA_SHARED_CACHE_USERNAME")
+
+		util.MustBindPFlag("cache.shared.password", flags.Lookup("shared-cache-password"))
+		util.MustBindEnv("cache.shared.password", "OPENFGA_SHARED_CACHE_PASSWORD")
+
+		util.MustBindPFlag("cache.shared.db", flags.Lookup("shared-cache-db"))
+		util.MustBindEnv("cache.shared.db", "OPENFGA_SHARED_CACHE_DB")
+
+		util.MustBindPFlag("cache.shared.keyPrefix", flags.Lookup("shared-cache-key-prefix"))
+		util.MustBindEnv("cache.shared.keyPrefix", "OPENFGA_SHARED_CACHE_KEY_PREFIX")
+
+		util.MustBindPFlag("cache.shared.timeout", flags.Lookup("shared-cache-timeout"))
+		util.MustBindEnv("cache.shared.timeout", "OPENFGA_SHARED_CACHE_TIMEOUT")
+
+		util.MustBindPFlag("cache.shared.backoff", flags.Lookup("shared-cache-backoff"))
+		util.MustBindEnv("cache.shared.backoff", "OPENFGA_SHARED_CACHE_BACKOFF")
+
 		util.MustBindPFlag("checkIteratorCache.enabled", flags.Lookup("check-iterator-cache-enabled"))
 		util.MustBindEnv("checkIteratorCache.enabled", "OPENFGA_CHECK_ITERATOR_CACHE_ENABLED")
 
@@ -237,6 +294,135 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("checkDispatchThrottling.maxThreshold", flags.Lookup("check-dispatch-throttling-max-threshold"))
 		util.MustBindEnv("checkDispatchThrottling.maxThreshold", "OPENFGA_CHECK_DISPATCH_THROTTLING_MAX_THRESHOLD")
 
+		util.MustBindPFlag("remoteCheckDispatch.enabled", flags.Lookup("remote-check-dispatch-enabled"))
+		util.MustBindEnv("remoteCheckDispatch.enabled", "OPENFGA_REMOTE_CHECK_DISPATCH_ENABLED")
+
+		util.MustBindPFlag("remoteCheckDispatch.addr", flags.Lookup("remote-check-dispatch-addr"))
+		util.MustBindEnv("remoteCheckDispatch.addr", "OPENFGA_REMOTE_CHECK_DISPATCH_ADDR")
+
+		util.MustBindPFlag("remoteCheckDispatch.advertiseAddr", flags.Lookup("remote-check-dispatch-advertise-addr"))
+		util.MustBindEnv("remoteCheckDispatch.advertiseAddr", "OPENFGA_REMOTE_CHECK_DISPATCH_ADVERTISE_ADDR")
+
+		util.MustBindPFlag("remoteCheckDispatch.peers", flags.Lookup("remote-check-dispatch-peers"))
+		util.MustBindEnv("remoteCheckDispatch.peers", "OPENFGA_REMOTE_CHECK_DISPATCH_PEERS")
+
+		util.MustBindPFlag("remoteCheckDispatch.dnsName", flags.Lookup("remote-check-dispatch-dns-name"))
+		util.MustBindEnv("remoteCheckDispatch.dnsName", "OPENFGA_REMOTE_CHECK_DISPATCH_DNS_NAME")
+
+		util.MustBindPFlag("remoteCheckDispatch.peerRefreshInterval", flags.Lookup("remote-check-dispatch-peer-refresh-interval"))
+		util.MustBindEnv("remoteCheckDispatch.peerRefreshInterval", "OPENFGA_REMOTE_CHECK_DISPATCH_PEER_REFRESH_INTERVAL")
+
+		util.MustBindPFlag("remoteCheckDispatch.peerUnhealthyBackoff", flags.Lookup("remote-check-dispatch-peer-unhealthy-backoff"))
+		util.MustBindEnv("remoteCheckDispatch.peerUnhealthyBackoff", "OPENFGA_REMOTE_CHECK_DISPATCH_PEER_UNHEALTHY_BACKOFF")
+
+		util.MustBindPFlag("remoteCheckDispatch.presharedKey", flags.Lookup("remote-check-dispatch-preshared-key"))
+		util.MustBindEnv("remoteCheckDispatch.presharedKey", "OPENFGA_REMOTE_CHECK_DISPATCH_PRESHARED_KEY")
+
+		util.MustBindPFlag("remoteCheckDispatch.tls.enabled", flags.Lookup("remote-check-dispatch-tls-enabled"))
+		util.MustBindEnv("remoteCheckDispatch.tls.enabled", "OPENFGA_REMOTE_CHECK_DISPATCH_TLS_ENABLED")
+
+		util.MustBindPFlag("remoteCheckDispatch.tls.cert", flags.Lookup("remote-check-dispatch-tls-cert"))
+		util.MustBindEnv("remoteCheckDispatch.tls.cert", "OPENFGA_REMOTE_CHECK_DISPATCH_TLS_CERT")
+
+		util.MustBindPFlag("remoteCheckDispatch.tls.key", flags.Lookup("remote-check-dispatch-tls-key"))
+		util.MustBindEnv("remoteCheckDispatch.tls.key", "OPENFGA_REMOTE_CHECK_DISPATCH_TLS_KEY")
+
+		util.MustBindPFlag("remoteCheckDispatch.tls.ca", flags.Lookup("remote-check-dispatch-tls-ca"))
+		util.MustBindEnv("remoteCheckDispatch.tls.ca", "OPENFGA_REMOTE_CHECK_DISPATCH_TLS_CA")
+
+		util.MustBindPFlag("watchChanges.heartbeatInterval", flags.Lookup("watch-changes-heartbeat-interval"))
+		util.MustBindEnv("watchChanges.heartbeatInterval", "OPENFGA_WATCH_CHANGES_HEARTBEAT_INTERVAL")
+
+		util.MustBindPFlag("watchChanges.minPollInterval", flags.Lookup("watch-changes-min-poll-interval"))
+		util.MustBindEnv("watchChanges.minPollInterval", "OPENFGA_WATCH_CHANGES_MIN_POLL_INTERVAL")
+
+		util.MustBindPFlag("watchChanges.maxPollInterval", flags.Lookup("watch-changes-max-poll-interval"))
+		util.MustBindEnv("watchChanges.maxPollInterval", "OPENFGA_WATCH_CHANGES_MAX_POLL_INTERVAL")
+
+		util.MustBindPFlag("tupleExpiration.reaperInterval", flags.Lookup("tuple-expiration-reaper-interval"))
+		util.MustBindEnv("tupleExpiration.reaperInterval", "OPENFGA_TUPLE_EXPIRATION_REAPER_INTERVAL")
+
+		util.MustBindPFlag("tupleExpiration.reaperBatchSize", flags.Lookup("tuple-expiration-reaper-batch-size"))
+		util.MustBindEnv("tupleExpiration.reaperBatchSize", "OPENFGA_TUPLE_EXPIRATION_REAPER_BATCH_SIZE")
+
+		util.MustBindPFlag("changelogRetention.maxAge", flags.Lookup("changelog-retention-max-age"))
+		util.MustBindEnv("changelogRetention.maxAge", "OPENFGA_CHANGELOG_RETENTION_MAX_AGE")
+
+		util.MustBindPFlag("changelogRetention.maxCount", flags.Lookup("changelog-retention-max-count"))
+		util.MustBindEnv("changelogRetention.maxCount", "OPENFGA_CHANGELOG_RETENTION_MAX_COUNT")
+
+		util.MustBindPFlag("changelogRetention.storeOverrides", flags.Lookup("changelog-retention-store-overrides"))
+		util.MustBindEnv("changelogRetention.storeOverrides", "OPENFGA_CHANGELOG_RETENTION_STORE_OVERRIDES")
+
+		util.MustBindPFlag("changelogRetention.compactAfter", flags.Lookup("changelog-retention-compact-after"))
+		util.MustBindEnv("changelogRetention.compactAfter", "OPENFGA_CHANGELOG_RETENTION_COMPACT_AFTER")
+
+		util.MustBindPFlag("changelogRetention.pruneInterval", flags.Lookup("changelog-retention-prune-interval"))
+		util.MustBindEnv("changelogRetention.pruneInterval", "OPENFGA_CHANGELOG_RETENTION_PRUNE_INTERVAL")
+
+		util.MustBindPFlag("changelogRetention.pruneBatchSize", flags.Lookup("changelog-retention-prune-batch-size"))
+		util.MustBindEnv("changelogRetention.pruneBatchSize", "OPENFGA_CHANGELOG_RETENTION_PRUNE_BATCH_SIZE")
+
+		util.MustBindPFlag("storePurge.retention", flags.Lookup("store-purge-retention"))
+		util.MustBindEnv("storePurge.retention", "OPENFGA_STORE_PURGE_RETENTION")
+
+		util.MustBindPFlag("storePurge.interval", flags.Lookup("store-purge-interval"))
+		util.MustBindEnv("storePurge.interval", "OPENFGA_STORE_PURGE_INTERVAL")
+
+		util.MustBindPFlag("storePurge.batchSize", flags.Lookup("store-purge-batch-size"))
+		util.MustBindEnv("storePurge.batchSize", "OPENFGA_STORE_PURGE_BATCH_SIZE")
+
+		util.MustBindPFlag("storeQuota.maxTuples", flags.Lookup("store-quota-max-tuples"))
+		util.MustBindEnv("storeQuota.maxTuples", "OPENFGA_STORE_QUOTA_MAX_TUPLES")
+
+		util.MustBindPFlag("storeQuota.maxAuthorizationModels", flags.Lookup("store-quota-max-authorization-models"))
+		util.MustBindEnv("storeQuota.maxAuthorizationModels", "OPENFGA_STORE_QUOTA_MAX_AUTHORIZATION_MODELS")
+
+		util.MustBindPFlag("storeQuota.maxAssertions", flags.Lookup("store-quota-max-assertions"))
+		util.MustBindEnv("storeQuota.maxAssertions", "OPENFGA_STORE_QUOTA_MAX_ASSERTIONS")
+
+		util.MustBindPFlag("storeQuota.writeRate", flags.Lookup("store-quota-write-rate"))
+		util.MustBindEnv("storeQuota.writeRate", "OPENFGA_STORE_QUOTA_WRITE_RATE")
+
+		util.MustBindPFlag("storeQuota.storeOverrides", flags.Lookup("store-quota-store-overrides"))
+		util.MustBindEnv("storeQuota.storeOverrides", "OPENFGA_STORE_QUOTA_STORE_OVERRIDES")
+
+		util.MustBindPFlag("outbox.enabled", flags.Lookup("outbox-enabled"))
+		util.MustBindEnv("outbox.enabled", "OPENFGA_OUTBOX_ENABLED")
+
+		util.MustBindPFlag("outbox.pollInterval", flags.Lookup("outbox-poll-interval"))
+		util.MustBindEnv("outbox.pollInterval", "OPENFGA_OUTBOX_POLL_INTERVAL")
+
+		util.MustBindPFlag("outbox.batchSize", flags.Lookup("outbox-batch-size"))
+		util.MustBindEnv("outbox.batchSize", "OPENFGA_OUTBOX_BATCH_SIZE")
+
+		util.MustBindPFlag("outbox.stdout", flags.Lookup("outbox-stdout"))
+		util.MustBindEnv("outbox.stdout", "OPENFGA_OUTBOX_STDOUT")
+
+		util.MustBindPFlag("outbox.filePath", flags.Lookup("outbox-file-path"))
+		util.MustBindEnv("outbox.filePath", "OPENFGA_OUTBOX_FILE_PATH")
+
+		util.MustBindPFlag("outbox.webhook.url", flags.Lookup("outbox-webhook-url"))
+		util.MustBindEnv("outbox.webhook.url", "OPENFGA_OUTBOX_WEBHOOK_URL")
+
+		util.MustBindPFlag("outbox.webhook.secret", flags.Lookup("outbox-webhook-secret"))
+		util.MustBindEnv("outbox.webhook.secret", "OPENFGA_OUTBOX_WEBHOOK_SECRET")
+
+		util.MustBindPFlag("outbox.webhook.maxRetries", flags.Lookup("outbox-webhook-max-retries"))
+		util.MustBindEnv("outbox.webhook.maxRetries", "OPENFGA_OUTBOX_WEBHOOK_MAX_RETRIES")
+
+		util.MustBindPFlag("outbox.webhook.timeout", flags.Lookup("outbox-webhook-timeout"))
+		util.MustBindEnv("outbox.webhook.timeout", "OPENFGA_OUTBOX_WEBHOOK_TIMEOUT")
+
+		util.MustBindPFlag("checkDatastoreBatching.enabled", flags.Lookup("check-datastore-batching-enabled"))
+		util.MustBindEnv("checkDatastoreBatching.enabled", "OPENFGA_CHECK_DATASTORE_BATCHING_ENABLED")
+
+		util.MustBindPFlag("checkDatastoreBatching.window", flags.Lookup("check-datastore-batching-window"))
+		util.MustBindEnv("checkDatastoreBatching.window", "OPENFGA_CHECK_DATASTORE_BATCHING_WINDOW")
+
+		util.MustBindPFlag("checkDatastoreBatching.maxBatchSize", flags.Lookup("check-datastore-batching-max-batch-size"))
+		util.MustBindEnv("checkDatastoreBatching.maxBatchSize", "OPENFGA_CHECK_DATASTORE_BATCHING_MAX_BATCH_SIZE")
+
 		util.MustBindPFlag("listObjectsDispatchThrottling.enabled", flags.Lookup("listObjects-dispatch-throttling-enabled"))
 		util.MustBindEnv("listObjectsDispatchThrottling.enabled", "OPENFGA_LIST_OBJECTS_DISPATCH_THROTTLING_ENABLED")
 
@@ -3,6 +3,8 @@ package run
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"html/template"
@@ -12,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	goruntime "runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -49,7 +52,10 @@ import (
 	"github.com/openfga/openfga/internal/authn/oidc"
 	"github.com/openfga/openfga/internal/authn/presharedkey"
 	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/changelogpruner"
+	"github.com/openfga/openfga/internal/graph"
 	authnmw "github.com/openfga/openfga/internal/middleware/authn"
+	"github.com/openfga/openfga/internal/storequota"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/middleware"
@@ -59,13 +65,16 @@ import (
 	"github.com/openfga/openfga/pkg/middleware/requestid"
 	"github.com/openfga/openfga/pkg/middleware/storeid"
 	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/outbox"
 	"github.com/openfga/openfga/pkg/server"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/server/health"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/bolt"
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sharding"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
 	"github.com/openfga/openfga/pkg/telemetry"
@@ -142,7 +151,7 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("datastore-engine", defaultConfig.Datastore.Engine, "the datastore engine that will be used for persistence")
 
-	flags.String("datastore-uri", defaultConfig.Datastore.URI, "the connection uri to use to connect to the datastore (for any engine other than 'memory')")
+	flags.String("datastore-uri", defaultConfig.Datastore.URI, "the connection uri to use to connect to the datastore (for any engine other than 'memory'). For 'bolt', the path of the database file")
 
 	flags.String("datastore-username", "", "the connection username to use to connect to the datastore (overwrites any username provided in the connection uri)")
 
@@ -158,7 +167,27 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("datastore-conn-max-lifetime", defaultConfig.Datastore.ConnMaxLifetime, "the maximum amount of time a connection to the datastore may be reused")
 
-	flags.Bool("datastore-metrics-enabled", defaultConfig.Datastore.Metrics.Enabled, "enable/disable sql metrics")
+	flags.Bool("datastore-metrics-enabled", defaultConfig.Datastore.Metrics.Enabled, "enable/disable the datastore metrics: those of the sql connections, and the latency, errors, rows and iterator lifetime of every call to the datastore")
+
+	flags.Int("datastore-metrics-store-label-limit", defaultConfig.Datastore.Metrics.StoreLabelLimit, "the number of stores the metrics of the calls to the datastore are labeled with, the first ones called with, the others being labeled 'other'. If 0, the metrics aren't labeled by store.")
+
+	flags.StringSlice("datastore-read-replica-uris", defaultConfig.Datastore.ReadReplicaURIs, "the connection uris of the read replicas of the datastore ('postgres' and 'mysql' only). The tuple reads which don't require a higher consistency are spread over the healthy replicas; everything else goes to the primary.")
+
+	flags.Duration("datastore-replica-max-lag", defaultConfig.Datastore.ReplicaMaxLag, "how far behind the primary a read replica may be to serve reads. The reads of a lagging replica are routed to the primary.")
+
+	flags.Duration("datastore-replica-check-interval", defaultConfig.Datastore.ReplicaCheckInterval, "how often the lag of the read replicas is measured")
+
+	flags.String("datastore-memory-dir", defaultConfig.Datastore.Memory.Dir, "the directory in which the 'memory' datastore persists its data, as a write-ahead log and snapshots. If empty, the data is lost on shutdown.")
+
+	flags.String("datastore-memory-fsync", defaultConfig.Datastore.Memory.Fsync, "when the 'memory' datastore flushes its changes to disk: 'always' (before acknowledging each change), 'interval' (every second) or 'never' (left to the operating system)")
+
+	flags.Duration("datastore-memory-snapshot-interval", defaultConfig.Datastore.Memory.SnapshotInterval, "how often the 'memory' datastore compacts its write-ahead log into a snapshot")
+
+	flags.StringSlice("datastore-shards", defaultConfig.Datastore.Shards, "the additional datastores the stores are spread over, as '<name>=<uri>', using the same engine as the datastore ('memory' excepted). The datastore holds the placements of the stores, and is itself the 'default' shard. Each shard must be migrated separately.")
+
+	flags.String("datastore-shard-placement", defaultConfig.Datastore.ShardPlacement, "the shard the stores created are placed on: 'default', 'hash' to spread them evenly over all the shards, or the name of a shard")
+
+	flags.Duration("datastore-shard-placement-cache-ttl", defaultConfig.Datastore.ShardPlacementCacheTTL, "how long the placements of the stores are cached. A store being moved to another shard keeps being written to its previous shard by the other servers for up to this long.")
 
 	flags.Bool("playground-enabled", defaultConfig.Playground.Enabled, "enable/disable the OpenFGA Playground")
 
@@ -202,6 +231,10 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint32("max-concurrent-reads-for-check", defaultConfig.MaxConcurrentReadsForCheck, "the maximum allowed number of concurrent datastore reads in a single Check query. A high number will consume more connections from the datastore pool and will attempt to prioritize performance for the request at the expense of other queries performance.")
 
+	flags.Uint32("max-checks-per-batch-check", defaultConfig.MaxChecksPerBatchCheck, "the maximum number of checks allowed in a single BatchCheck request")
+
+	flags.Uint32("max-concurrent-checks-per-batch-check", defaultConfig.MaxConcurrentChecksPerBatchCheck, "the maximum number of checks of a single BatchCheck request that are resolved concurrently. Datastore reads of the whole batch are bounded by 'max-concurrent-reads-for-check'.")
+
 	flags.Uint64("max-condition-evaluation-cost", defaultConfig.MaxConditionEvaluationCost, "the maximum cost for CEL condition evaluation before a request returns an error")
 
 	flags.Int("changelog-horizon-offset", defaultConfig.ChangelogHorizonOffset, "the offset (in minutes) from the current time. Changes that occur after this offset will not be included in the response of ReadChanges")
@@ -228,6 +261,20 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("check-query-cache-ttl", defaultConfig.CheckQueryCache.TTL, "if caching of Check and ListObjects is enabled, this is the TTL of each value")
 
+	flags.String("shared-cache-addr", defaultConfig.Cache.Shared.Addr, "the address (host:port) of a Redis or Valkey server holding a cache shared by all the servers, which the check query and iterator caches use on top of their local cache. If empty, the caches are local only.")
+
+	flags.String("shared-cache-username", defaultConfig.Cache.Shared.Username, "the username to authenticate to the shared cache")
+
+	flags.String("shared-cache-password", defaultConfig.Cache.Shared.Password, "the password to authenticate to the shared cache")
+
+	flags.Int("shared-cache-db", defaultConfig.Cache.Shared.DB, "the database of the shared cache")
+
+	flags.String("shared-cache-key-prefix", defaultConfig.Cache.Shared.KeyPrefix, "a prefix added to the keys of the shared cache, so that several deployments can share a server")
+
+	flags.Duration("shared-cache-timeout", defaultConfig.Cache.Shared.Timeout, "the timeout of each request to the shared cache")
+
+	flags.Duration("shared-cache-backoff", defaultConfig.Cache.Shared.Backoff, "how long the shared cache is bypassed, using only the local cache, after a request to it failed")
+
 	// Unfortunately UintSlice/IntSlice does not work well when used as environment variable, we need to stick with string slice and convert back to integer
 	flags.StringSlice("request-duration-datastore-query-count-buckets", defaultConfig.RequestDurationDatastoreQueryCountBuckets, "datastore query count buckets used in labelling request_duration_ms.")
 
@@ -241,6 +288,92 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint32("check-dispatch-throttling-max-threshold", defaultConfig.CheckDispatchThrottling.MaxThreshold, "define the maximum dispatch threshold beyond which a Check requests will be throttled. 0 will use the 'check-dispatch-throttling-threshold' value as maximum")
 
+	flags.Bool("remote-check-dispatch-enabled", defaultConfig.RemoteCheckDispatch.Enabled, "enable spreading the resolution of Check subproblems across the nodes of the cluster with consistent hashing. Each node resolves, and caches, the subproblems assigned to it, and the nodes reach each other with an internal gRPC service.")
+
+	flags.String("remote-check-dispatch-addr", defaultConfig.RemoteCheckDispatch.Addr, "the host:port address to serve the internal check dispatch gRPC service on. It should only be reachable by the other nodes of the cluster.")
+
+	flags.String("remote-check-dispatch-advertise-addr", defaultConfig.RemoteCheckDispatch.AdvertiseAddr, "the host:port address at which the other nodes of the cluster reach the check dispatch service of this node. It must match the address of this node in 'remote-check-dispatch-peers', or its IP if 'remote-check-dispatch-dns-name' is used.")
+
+	flags.StringSlice("remote-check-dispatch-peers", defaultConfig.RemoteCheckDispatch.Peers, "the host:port check dispatch addresses of the nodes of the cluster.")
+
+	flags.String("remote-check-dispatch-dns-name", defaultConfig.RemoteCheckDispatch.DNSName, "a DNS name resolving to the IPs of the nodes of the cluster (e.g. a Kubernetes headless service), as an alternative to 'remote-check-dispatch-peers'. The nodes are expected to listen on the port of 'remote-check-dispatch-addr'.")
+
+	flags.Duration("remote-check-dispatch-peer-refresh-interval", defaultConfig.RemoteCheckDispatch.PeerRefreshInterval, "how often the nodes of the cluster are discovered again.")
+
+	flags.Duration("remote-check-dispatch-peer-unhealthy-backoff", defaultConfig.RemoteCheckDispatch.PeerUnhealthyBackoff, "for how long a node that could not be reached is not dispatched to. Its subproblems are resolved locally in the meantime.")
+
+	flags.String("remote-check-dispatch-preshared-key", defaultConfig.RemoteCheckDispatch.PresharedKey, "a key the nodes of the cluster authenticate each other with. One of 'remote-check-dispatch-preshared-key' and 'remote-check-dispatch-tls-enabled' is required.")
+
+	flags.Bool("remote-check-dispatch-tls-enabled", defaultConfig.RemoteCheckDispatch.TLS.Enabled, "authenticate the nodes of the cluster to each other with mutual TLS. One of 'remote-check-dispatch-preshared-key' and 'remote-check-dispatch-tls-enabled' is required.")
+
+	flags.String("remote-check-dispatch-tls-cert", defaultConfig.RemoteCheckDispatch.TLS.CertPath, "the (absolute) file path of the certificate the node serves, and presents to the other nodes of the cluster.")
+
+	flags.String("remote-check-dispatch-tls-key", defaultConfig.RemoteCheckDispatch.TLS.KeyPath, "the (absolute) file path of the key of the certificate of the node.")
+
+	flags.String("remote-check-dispatch-tls-ca", defaultConfig.RemoteCheckDispatch.TLS.CAPath, "the (absolute) file path of the CA certificate the certificates of the nodes of the cluster are signed by.")
+
+	flags.Duration("watch-changes-heartbeat-interval", defaultConfig.WatchChanges.HeartbeatInterval, "how long a WatchChanges stream may stay idle before a heartbeat is sent.")
+
+	flags.Duration("watch-changes-min-poll-interval", defaultConfig.WatchChanges.MinPollInterval, "the minimum interval between reads of the changelog by a WatchChanges stream when nothing changed. The interval doubles up to 'watch-changes-max-poll-interval' until changes are read.")
+
+	flags.Duration("watch-changes-max-poll-interval", defaultConfig.WatchChanges.MaxPollInterval, "the maximum interval between reads of the changelog by a WatchChanges stream when nothing changed. If the datastore notifies changes (e.g. postgres), the changelog is only read at this interval in between notifications.")
+
+	flags.Duration("tuple-expiration-reaper-interval", defaultConfig.TupleExpiration.ReaperInterval, "how often the expired tuples are deleted from the datastore. If 0, they are only hidden from reads.")
+
+	flags.Int("tuple-expiration-reaper-batch-size", defaultConfig.TupleExpiration.ReaperBatchSize, "the maximum number of expired tuples deleted from the datastore at once.")
+
+	flags.Duration("changelog-retention-max-age", defaultConfig.ChangelogRetention.MaxAge, "how long the changes are kept in the changelogs. If 0, they aren't deleted by age.")
+
+	flags.Int("changelog-retention-max-count", defaultConfig.ChangelogRetention.MaxCount, "how many of the newest changes of each store are kept in its changelog. If 0, they aren't deleted by count.")
+
+	flags.StringSlice("changelog-retention-store-overrides", defaultConfig.ChangelogRetention.StoreOverrides, "the changelog retentions of specific stores, replacing 'changelog-retention-max-age' and 'changelog-retention-max-count', each as '<store id>:<max age>:<max count>'. An empty max age or max count doesn't bound the changes kept (e.g. '01HVMMBCMGZNT3SED4Z17ECXCA:720h:').")
+
+	flags.Duration("changelog-retention-compact-after", defaultConfig.ChangelogRetention.CompactAfter, "the age after which a write of a tuple followed by its delete are both deleted from the changelog. If 0, the changelogs aren't compacted.")
+
+	flags.Duration("changelog-retention-prune-interval", defaultConfig.ChangelogRetention.PruneInterval, "how often the changelogs are pruned.")
+
+	flags.Int("changelog-retention-prune-batch-size", defaultConfig.ChangelogRetention.PruneBatchSize, "the maximum number of changes deleted from a changelog at once.")
+
+	flags.Duration("store-purge-retention", defaultConfig.StorePurge.Retention, "how long the deleted stores are kept, and can be restored with UndeleteStore, before their data is permanently deleted. If 0, they are kept forever.")
+
+	flags.Duration("store-purge-interval", defaultConfig.StorePurge.Interval, "how often the deleted stores are purged.")
+
+	flags.Int("store-purge-batch-size", defaultConfig.StorePurge.BatchSize, "the maximum number of rows of a deleted store deleted from the datastore at once.")
+
+	flags.Int64("store-quota-max-tuples", defaultConfig.StoreQuota.MaxTuples, "the maximum number of tuples of each store. It requires a datastore which counts the tuples of the stores. It is a soft limit: as the writes of a store running at once are checked against the same count, the store may exceed it by up to their number times the maximum number of tuples per write. If 0, they aren't bounded.")
+
+	flags.Int("store-quota-max-authorization-models", defaultConfig.StoreQuota.MaxAuthorizationModels, "the maximum number of authorization models of each store. If 0, they aren't bounded.")
+
+	flags.Int("store-quota-max-assertions", defaultConfig.StoreQuota.MaxAssertions, "the maximum number of assertions of each authorization model. If 0, they aren't bounded.")
+
+	flags.Float64("store-quota-write-rate", defaultConfig.StoreQuota.WriteRate, "the maximum number of tuples written or deleted per second by each store, over bursts of up to a second of writes. If 0, the writes aren't rate limited.")
+
+	flags.StringSlice("store-quota-store-overrides", defaultConfig.StoreQuota.StoreOverrides, "the quotas of specific stores, replacing the other 'store-quota-*' quotas, each as '<store id>:<max tuples>:<max authorization models>:<max assertions>:<write rate>'. An empty quota doesn't bound the resource (e.g. '01HVMMBCMGZNT3SED4Z17ECXCA:1000000:::50').")
+
+	flags.Bool("outbox-enabled", defaultConfig.Outbox.Enabled, "enable the outbox, which records the changes of the datastore as events along with the changes themselves, and the delivery of the events to the sinks. It requires a datastore with the outbox migration applied.")
+
+	flags.Duration("outbox-poll-interval", defaultConfig.Outbox.PollInterval, "how often the outbox is read when it was found empty, or a sink failed.")
+
+	flags.Int("outbox-batch-size", defaultConfig.Outbox.BatchSize, "the maximum number of events of the outbox delivered to the sinks at once.")
+
+	flags.Bool("outbox-stdout", defaultConfig.Outbox.Stdout, "enable the sink writing the events of the outbox to the standard output, as JSON lines.")
+
+	flags.String("outbox-file-path", defaultConfig.Outbox.FilePath, "the path of the file the events of the outbox are appended to, as JSON lines. If empty, the events aren't written to a file.")
+
+	flags.String("outbox-webhook-url", defaultConfig.Outbox.Webhook.URL, "the URL the events of the outbox are posted to. If empty, the events aren't posted.")
+
+	flags.String("outbox-webhook-secret", defaultConfig.Outbox.Webhook.Secret, "the key of the HMAC-SHA256 signature of the requests of the webhook, sent in the 'X-OpenFGA-Signature' header. If empty, the requests aren't signed.")
+
+	flags.Int("outbox-webhook-max-retries", defaultConfig.Outbox.Webhook.MaxRetries, "how many times a failed request of the webhook is retried, with an exponential backoff, before the events are delivered again later.")
+
+	flags.Duration("outbox-webhook-timeout", defaultConfig.Outbox.Webhook.Timeout, "the timeout of each request of the webhook.")
+
+	flags.Bool("check-datastore-batching-enabled", defaultConfig.CheckDatastoreBatching.Enabled, "enable the batching of the concurrent point lookups of the datastore made while resolving a Check, which are then read with one query per batch.")
+
+	flags.Duration("check-datastore-batching-window", defaultConfig.CheckDatastoreBatching.Window, "how long the point lookups of a Check are collected before being read at once.")
+
+	flags.Int("check-datastore-batching-max-batch-size", defaultConfig.CheckDatastoreBatching.MaxBatchSize, "the maximum number of point lookups of a Check read at once. A batch is read as soon as it is full.")
+
 	flags.Bool("listObjects-dispatch-throttling-enabled", defaultConfig.ListObjectsDispatchThrottling.Enabled, "enable throttling when a ListObjects request's number of dispatches is high. Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch threshold over requests whose dispatch count exceeds the configured threshold.")
 
 	flags.Duration("listObjects-dispatch-throttling-frequency", defaultConfig.ListObjectsDispatchThrottling.Frequency, "defines how frequent ListObjects dispatch throttling will be evaluated. Frequency controls how frequently throttled dispatch ListObjects requests are dispatched.")
@@ -337,6 +470,202 @@ func convertStringArrayToUintArray(stringArray []string) []uint {
 	return uintArray
 }
 
+// checkDispatchPresharedKey authenticates the calls of this node to the check dispatch service of the
+// other nodes of the cluster.
+type checkDispatchPresharedKey string
+
+func (k checkDispatchPresharedKey) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(k)}, nil
+}
+
+func (k checkDispatchPresharedKey) RequireTransportSecurity() bool {
+	return false
+}
+
+// remoteCheckDispatchOptions returns the server options that spread the resolution of Check subproblems
+// across the nodes of the cluster, if enabled.
+func remoteCheckDispatchOptions(config *serverconfig.Config) ([]server.OpenFGAServiceV1Option, error) {
+	if !config.RemoteCheckDispatch.Enabled {
+		return nil, nil
+	}
+
+	var peers graph.PeerDiscoverer = graph.StaticPeers(config.RemoteCheckDispatch.Peers)
+	if config.RemoteCheckDispatch.DNSName != "" {
+		_, port, err := net.SplitHostPort(config.RemoteCheckDispatch.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'remoteCheckDispatch.addr': %w", err)
+		}
+		peers = graph.NewDNSPeerDiscoverer(config.RemoteCheckDispatch.DNSName, port)
+	}
+
+	transportCreds := insecure.NewCredentials()
+	if config.RemoteCheckDispatch.TLS.Enabled {
+		tlsConfig, err := checkDispatchTLSConfig(config.RemoteCheckDispatch.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transportCreds = credentials.NewTLS(&tls.Config{
+			Certificates: tlsConfig.Certificates,
+			RootCAs:      tlsConfig.ClientCAs,
+			MinVersion:   tls.VersionTLS12,
+		})
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(transportCreds)}
+	if config.RemoteCheckDispatch.PresharedKey != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(checkDispatchPresharedKey(config.RemoteCheckDispatch.PresharedKey)))
+	}
+	if config.Trace.Enabled {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	return []server.OpenFGAServiceV1Option{
+		server.WithRemoteCheckDispatchEnabled(true),
+		server.WithRemoteCheckDispatchSelfAddress(config.RemoteCheckDispatch.AdvertiseAddr),
+		server.WithRemoteCheckDispatchPeers(peers),
+		server.WithRemoteCheckDispatchPeerRefreshInterval(config.RemoteCheckDispatch.PeerRefreshInterval),
+		server.WithRemoteCheckDispatchPeerUnhealthyBackoff(config.RemoteCheckDispatch.PeerUnhealthyBackoff),
+		server.WithRemoteCheckDispatchDialOptions(dialOpts...),
+	}, nil
+}
+
+// changelogRetentionConfig returns the configuration of the background pruning of the changelogs.
+func changelogRetentionConfig(config serverconfig.ChangelogRetentionConfig) (changelogpruner.Config, error) {
+	storeRetentions := make(map[string]changelogpruner.Retention, len(config.StoreOverrides))
+	for _, override := range config.StoreOverrides {
+		storeID, maxAge, maxCount, err := serverconfig.ParseChangelogStoreRetention(override)
+		if err != nil {
+			return changelogpruner.Config{}, fmt.Errorf("invalid 'changelogRetention.storeOverrides': %w", err)
+		}
+		storeRetentions[storeID] = changelogpruner.Retention{MaxAge: maxAge, MaxCount: maxCount}
+	}
+
+	return changelogpruner.Config{
+		Retention:       changelogpruner.Retention{MaxAge: config.MaxAge, MaxCount: config.MaxCount},
+		StoreRetentions: storeRetentions,
+		CompactAfter:    config.CompactAfter,
+		Interval:        config.PruneInterval,
+		BatchSize:       config.PruneBatchSize,
+	}, nil
+}
+
+// storeQuotaConfig returns the configuration of the quotas of the stores.
+func storeQuotaConfig(config serverconfig.StoreQuotaConfig) (storequota.Config, error) {
+	storeQuotas := make(map[string]storequota.Quota, len(config.StoreOverrides))
+	for _, override := range config.StoreOverrides {
+		storeID, quota, err := serverconfig.ParseStoreQuotaOverride(override)
+		if err != nil {
+			return storequota.Config{}, fmt.Errorf("invalid 'storeQuota.storeOverrides': %w", err)
+		}
+		storeQuotas[storeID] = storequota.Quota{
+			MaxTuples:              quota.MaxTuples,
+			MaxAuthorizationModels: quota.MaxAuthorizationModels,
+			MaxAssertions:          quota.MaxAssertions,
+			WriteRate:              quota.WriteRate,
+		}
+	}
+
+	return storequota.Config{
+		Quota: storequota.Quota{
+			MaxTuples:              config.MaxTuples,
+			MaxAuthorizationModels: config.MaxAuthorizationModels,
+			MaxAssertions:          config.MaxAssertions,
+			WriteRate:              config.WriteRate,
+		},
+		StoreQuotas: storeQuotas,
+	}, nil
+}
+
+// outboxSinks returns the sinks the events of the outbox are delivered to, none if the outbox is disabled.
+func outboxSinks(config serverconfig.OutboxConfig) []outbox.EventSink {
+	if !config.Enabled {
+		return nil
+	}
+
+	var sinks []outbox.EventSink
+	if config.Stdout {
+		sinks = append(sinks, outbox.NewStdoutSink())
+	}
+	if config.FilePath != "" {
+		sinks = append(sinks, outbox.NewFileSink(config.FilePath))
+	}
+	if config.Webhook.URL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(outbox.WebhookConfig{
+			URL:        config.Webhook.URL,
+			Secret:     config.Webhook.Secret,
+			MaxRetries: config.Webhook.MaxRetries,
+			Timeout:    config.Webhook.Timeout,
+		}))
+	}
+	return sinks
+}
+
+// checkDispatchTLSConfig returns the mutual TLS configuration of the check dispatch service, which requires
+// the certificates of the other nodes of the cluster to be signed by the CA.
+func checkDispatchTLSConfig(config serverconfig.RemoteCheckDispatchTLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.CertPath, config.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the 'remoteCheckDispatch.tls' certificate: %w", err)
+	}
+
+	ca, err := os.ReadFile(config.CAPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 'remoteCheckDispatch.tls.ca': %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("'remoteCheckDispatch.tls.ca' contains no PEM certificate")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    caPool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// checkDispatchServer returns the gRPC server of the internal check dispatch service. It is separate from
+// the server of the OpenFGA API, as it is only meant to be reachable by the other nodes of the cluster,
+// which must authenticate with the preshared key or their certificate.
+func (s *ServerContext) checkDispatchServer(config *serverconfig.Config, svr *server.Server) (*grpc.Server, error) {
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(serverconfig.DefaultMaxRPCMessageSizeInBytes),
+		grpc.ChainUnaryInterceptor(
+			grpc_recovery.UnaryServerInterceptor(
+				grpc_recovery.WithRecoveryHandlerContext(
+					recovery.PanicRecoveryHandler(s.Logger),
+				),
+			),
+		),
+	}
+
+	if config.RemoteCheckDispatch.PresharedKey != "" {
+		authenticator, err := presharedkey.NewPresharedKeyAuthenticator([]string{config.RemoteCheckDispatch.PresharedKey})
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(grpcauth.UnaryServerInterceptor(authnmw.AuthFunc(authenticator))))
+	}
+
+	if config.RemoteCheckDispatch.TLS.Enabled {
+		tlsConfig, err := checkDispatchTLSConfig(config.RemoteCheckDispatch.TLS)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if config.Trace.Enabled {
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+
+	// nosemgrep: grpc-server-insecure-connection
+	dispatchServer := grpc.NewServer(serverOpts...)
+	graph.RegisterCheckDispatchServiceServer(dispatchServer, svr)
+	return dispatchServer, nil
+}
+
 // telemetryConfig returns the function that must be called to shut down tracing.
 // The context provided to this function should be error-free, or shut down will be incomplete.
 func (s *ServerContext) telemetryConfig(config *serverconfig.Config) func() error {
@@ -386,7 +715,16 @@ func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.Op
 		sqlcommon.WithConnMaxIdleTime(config.Datastore.ConnMaxIdleTime),
 		sqlcommon.WithConnMaxLifetime(config.Datastore.ConnMaxLifetime),
 		sqlcommon.WithContinuationTokenSerializer(tokenSerializer),
+		sqlcommon.WithReadReplicaURIs(config.Datastore.ReadReplicaURIs),
+		sqlcommon.WithReplicaMaxLag(config.Datastore.ReplicaMaxLag),
+		sqlcommon.WithReplicaCheckInterval(config.Datastore.ReplicaCheckInterval),
 	}
+	if config.Outbox.Enabled {
+		datastoreOptions = append(datastoreOptions, sqlcommon.WithOutbox())
+	}
+
+	// the shards have no read replicas, and don't export the metrics, which are registered once
+	shardOptions := append(slices.Clone(datastoreOptions), sqlcommon.WithReadReplicaURIs(nil))
 
 	if config.Datastore.Metrics.Enabled {
 		datastoreOptions = append(datastoreOptions, sqlcommon.WithMetrics())
@@ -405,7 +743,23 @@ func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.Op
 			memory.WithMaxTuplesPerWrite(config.MaxTuplesPerWrite),
 			memory.WithContinuationTokenSerializer(tokenSerializer),
 		}
-		datastore = memory.New(opts...)
+		if config.Outbox.Enabled {
+			opts = append(opts, memory.WithOutbox())
+		}
+		if config.Datastore.Memory.Dir == "" {
+			datastore = memory.New(opts...)
+			break
+		}
+
+		opts = append(opts,
+			memory.WithFsyncPolicy(memory.FsyncPolicy(config.Datastore.Memory.Fsync)),
+			memory.WithSnapshotInterval(config.Datastore.Memory.SnapshotInterval),
+			memory.WithLogger(s.Logger),
+		)
+		datastore, err = memory.NewDurable(config.Datastore.Memory.Dir, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("initialize durable memory datastore: %w", err)
+		}
 	case "mysql":
 		datastore, err = mysql.New(config.Datastore.URI, dsCfg)
 		if err != nil {
@@ -421,15 +775,121 @@ func (s *ServerContext) datastoreConfig(config *serverconfig.Config) (storage.Op
 		if err != nil {
 			return nil, nil, fmt.Errorf("initialize sqlite datastore: %w", err)
 		}
+	case "bolt":
+		// the uri is the path of the database file
+		datastore, err = bolt.New(config.Datastore.URI, boltOptions(config, tokenSerializer)...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("initialize bolt datastore: %w", err)
+		}
 	default:
 		return nil, nil, fmt.Errorf("storage engine '%s' is unsupported", config.Datastore.Engine)
 	}
 
 	s.Logger.Info(fmt.Sprintf("using '%v' storage engine", config.Datastore.Engine))
 
+	if len(config.Datastore.Shards) > 0 {
+		sharded, err := s.shardedDatastore(config, datastore, sqlcommon.NewConfig(shardOptions...), tokenSerializer)
+		if err != nil {
+			datastore.Close()
+			return nil, nil, err
+		}
+		datastore = sharded
+	}
+
 	return datastore, tokenSerializer, nil
 }
 
+// checkDatastoreSchema refuses to start the server when the schema of the datastore is older than the one this
+// version of OpenFGA requires, i.e. when it is reachable but not ready. A datastore which isn't reachable yet is left
+// to the readiness checks.
+func (s *ServerContext) checkDatastoreSchema(ctx context.Context, datastore storage.OpenFGADatastore) error {
+	status, err := datastore.IsReady(ctx)
+	if err != nil {
+		s.Logger.Warn("failed to check the schema version of the datastore", zap.Error(err))
+		return nil
+	}
+	if !status.IsReady {
+		return fmt.Errorf("the datastore schema is older than this version of OpenFGA expects: %s", status.Message)
+	}
+	return nil
+}
+
+// boltOptions returns the options of the bolt datastores.
+func boltOptions(config *serverconfig.Config, tokenSerializer encoder.ContinuationTokenSerializer) []bolt.StorageOption {
+	opts := []bolt.StorageOption{
+		bolt.WithMaxTypesPerAuthorizationModel(config.MaxTypesPerAuthorizationModel),
+		bolt.WithMaxTuplesPerWrite(config.MaxTuplesPerWrite),
+		bolt.WithContinuationTokenSerializer(tokenSerializer),
+	}
+	if config.Outbox.Enabled {
+		opts = append(opts, bolt.WithOutbox())
+	}
+	return opts
+}
+
+// shardedDatastore opens the shards of the datastore, with its engine, and returns a datastore routing the stores
+// to them, the datastore being the catalog and the 'default' shard.
+func (s *ServerContext) shardedDatastore(config *serverconfig.Config, datastore storage.OpenFGADatastore, dsCfg *sqlcommon.Config, tokenSerializer encoder.ContinuationTokenSerializer) (storage.OpenFGADatastore, error) {
+	shards := map[string]storage.OpenFGADatastore{"default": datastore}
+	closeShards := func() {
+		for name, shard := range shards {
+			if name != "default" {
+				shard.Close()
+			}
+		}
+	}
+
+	for _, shard := range config.Datastore.Shards {
+		name, uri, err := serverconfig.ParseDatastoreShard(shard)
+		if err != nil {
+			closeShards()
+			return nil, err
+		}
+
+		var ds storage.OpenFGADatastore
+		switch config.Datastore.Engine {
+		case "mysql":
+			ds, err = mysql.New(uri, dsCfg)
+		case "postgres":
+			ds, err = postgres.New(uri, dsCfg)
+		case "sqlite":
+			ds, err = sqlite.New(uri, dsCfg)
+		case "bolt":
+			ds, err = bolt.New(uri, boltOptions(config, tokenSerializer)...)
+		default:
+			err = fmt.Errorf("storage engine '%s' doesn't support shards", config.Datastore.Engine)
+		}
+		if err != nil {
+			closeShards()
+			return nil, fmt.Errorf("initialize shard '%s': %w", name, err)
+		}
+		shards[name] = ds
+	}
+
+	var policy sharding.PlacementPolicy
+	switch config.Datastore.ShardPlacement {
+	case "default":
+		policy = sharding.DefaultShardPlacement()
+	case "hash":
+		policy = sharding.HashPlacement()
+	default:
+		policy = sharding.ShardPlacement(config.Datastore.ShardPlacement)
+	}
+
+	sharded, err := sharding.New(datastore, "default", shards,
+		sharding.WithPlacementPolicy(policy),
+		sharding.WithPlacementCacheTTL(config.Datastore.ShardPlacementCacheTTL),
+		sharding.WithLogger(s.Logger),
+	)
+	if err != nil {
+		closeShards()
+		return nil, fmt.Errorf("initialize sharded datastore: %w", err)
+	}
+
+	s.Logger.Info(fmt.Sprintf("spreading the stores over %d shards", len(shards)))
+	return sharded, nil
+}
+
 func (s *ServerContext) authenticatorConfig(config *serverconfig.Config) (authn.Authenticator, error) {
 	var authenticator authn.Authenticator
 	var err error
@@ -475,6 +935,11 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		return err
 	}
 
+	if err := s.checkDatastoreSchema(ctx, datastore); err != nil {
+		datastore.Close()
+		return err
+	}
+
 	authenticator, err := s.authenticatorConfig(config)
 
 	if err != nil {
@@ -511,7 +976,7 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		timeoutMiddleware := middleware.NewTimeoutInterceptor(config.RequestTimeout, s.Logger)
 
 		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(timeoutMiddleware.NewUnaryTimeoutInterceptor()))
-		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(timeoutMiddleware.NewStreamTimeoutInterceptor()))
+		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(timeoutMiddleware.NewStreamTimeoutInterceptor(server.WatchChangesFullMethod, server.ImportTuplesFullMethod)))
 	}
 
 	serverOpts = append(serverOpts,
@@ -617,7 +1082,22 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 
 	checkDispatchThrottlingConfig := serverconfig.GetCheckDispatchThrottlingConfig(s.Logger, config)
 
-	svr := server.MustNewServerWithOpts(
+	remoteCheckDispatchOpts, err := remoteCheckDispatchOptions(config)
+	if err != nil {
+		return err
+	}
+
+	changelogRetention, err := changelogRetentionConfig(config.ChangelogRetention)
+	if err != nil {
+		return err
+	}
+
+	storeQuota, err := storeQuotaConfig(config.StoreQuota)
+	if err != nil {
+		return err
+	}
+
+	svr := server.MustNewServerWithOpts(append([]server.OpenFGAServiceV1Option{
 		server.WithDatastore(datastore),
 		server.WithContinuationTokenSerializer(continuationTokenSerializer),
 		server.WithAuthorizationModelCacheSize(config.Datastore.MaxCacheSize),
@@ -633,7 +1113,10 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithMaxConcurrentReadsForListObjects(config.MaxConcurrentReadsForListObjects),
 		server.WithMaxConcurrentReadsForCheck(config.MaxConcurrentReadsForCheck),
 		server.WithMaxConcurrentReadsForListUsers(config.MaxConcurrentReadsForListUsers),
+		server.WithMaxChecksPerBatchCheck(config.MaxChecksPerBatchCheck),
+		server.WithMaxConcurrentChecksPerBatchCheck(config.MaxConcurrentChecksPerBatchCheck),
 		server.WithCacheLimit(config.Cache.Limit),
+		server.WithSharedCache(config.Cache.Shared),
 		server.WithCheckIteratorCacheEnabled(config.CheckIteratorCache.Enabled),
 		server.WithCheckIteratorCacheMaxResults(config.CheckIteratorCache.MaxResults),
 		server.WithCheckQueryCacheEnabled(config.CheckQueryCache.Enabled),
@@ -655,8 +1138,17 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithListUsersDispatchThrottlingMaxThreshold(config.ListUsersDispatchThrottling.MaxThreshold),
 		server.WithExperimentals(experimentals...),
 		server.WithAccessControlParams(config.AccessControl.Enabled, config.AccessControl.StoreID, config.AccessControl.ModelID, config.Authn.Method),
+		server.WithWatchChangesHeartbeatInterval(config.WatchChanges.HeartbeatInterval),
+		server.WithWatchChangesPollInterval(config.WatchChanges.MinPollInterval, config.WatchChanges.MaxPollInterval),
+		server.WithTupleExpirationReaper(config.TupleExpiration.ReaperInterval, config.TupleExpiration.ReaperBatchSize),
+		server.WithChangelogRetention(changelogRetention),
+		server.WithStorePurge(config.StorePurge.Retention, config.StorePurge.Interval, config.StorePurge.BatchSize),
+		server.WithStoreQuotas(storeQuota),
+		server.WithOutbox(outbox.Config{PollInterval: config.Outbox.PollInterval, BatchSize: config.Outbox.BatchSize}, outboxSinks(config.Outbox)...),
+		server.WithDatastoreMetrics(config.Datastore.Metrics.Enabled, config.Datastore.Engine, config.Datastore.Metrics.StoreLabelLimit),
+		server.WithCheckDatastoreBatching(config.CheckDatastoreBatching.Enabled, config.CheckDatastoreBatching.Window, config.CheckDatastoreBatching.MaxBatchSize),
 		server.WithContext(ctx),
-	)
+	}, remoteCheckDispatchOpts...)...)
 
 	s.Logger.Info(
 		"starting openfga service...",
@@ -670,6 +1162,12 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 	// nosemgrep: grpc-server-insecure-connection
 	grpcServer := grpc.NewServer(serverOpts...)
 	openfgav1.RegisterOpenFGAServiceServer(grpcServer, svr)
+	server.RegisterBatchCheckServiceServer(grpcServer, svr)
+	server.RegisterExplainCheckServiceServer(grpcServer, svr)
+	server.RegisterDiffAuthorizationModelsServiceServer(grpcServer, svr)
+	server.RegisterUndeleteStoreServiceServer(grpcServer, svr)
+	server.RegisterWatchChangesServiceServer(grpcServer, svr)
+	server.RegisterImportTuplesServiceServer(grpcServer, svr)
 	healthServer := &health.Checker{TargetService: svr, TargetServiceName: openfgav1.OpenFGAService_ServiceDesc.ServiceName}
 	healthv1pb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
@@ -689,6 +1187,29 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		s.Logger.Info("gRPC server shut down.")
 	}()
 
+	var dispatchServer *grpc.Server
+	if config.RemoteCheckDispatch.Enabled {
+		dispatchServer, err = s.checkDispatchServer(config, svr)
+		if err != nil {
+			return err
+		}
+
+		dispatchLis, err := net.Listen("tcp", config.RemoteCheckDispatch.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		go func() {
+			s.Logger.Info(fmt.Sprintf("🔀 starting check dispatch server on '%s'...", config.RemoteCheckDispatch.Addr))
+			if err := dispatchServer.Serve(dispatchLis); err != nil {
+				if !errors.Is(err, grpc.ErrServerStopped) {
+					s.Logger.Fatal("failed to start check dispatch server", zap.Error(err))
+				}
+			}
+			s.Logger.Info("check dispatch server shut down.")
+		}()
+	}
+
 	var httpServer *http.Server
 	if config.HTTP.Enabled {
 		runtime.DefaultContextTimeout = serverconfig.DefaultContextTimeout(config)
@@ -733,11 +1254,32 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 			}),
 			runtime.WithHealthzEndpoint(healthv1pb.NewHealthClient(conn)),
 			runtime.WithOutgoingHeaderMatcher(func(s string) (string, bool) { return s, true }),
+			runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+				if server.IsRevisionHeader(key) || server.IsStoreLabelsHeader(key) || server.IsWriteOptionsHeader(key) {
+					return key, true
+				}
+				return runtime.DefaultHeaderMatcher(key)
+			}),
 		}
 		mux := runtime.NewServeMux(muxOpts...)
 		if err := openfgav1.RegisterOpenFGAServiceHandler(ctx, mux, conn); err != nil {
 			return err
 		}
+		if err := server.RegisterBatchCheckServiceHandler(mux, conn); err != nil {
+			return err
+		}
+		if err := server.RegisterExplainCheckServiceHandler(mux, conn); err != nil {
+			return err
+		}
+		if err := server.RegisterDiffAuthorizationModelsServiceHandler(mux, conn); err != nil {
+			return err
+		}
+		if err := server.RegisterUndeleteStoreServiceHandler(mux, conn); err != nil {
+			return err
+		}
+		if err := server.RegisterWatchChangesServiceHandler(mux, conn); err != nil {
+			return err
+		}
 		handler := http.Handler(mux)
 
 		if config.Trace.Enabled {
@@ -885,6 +1427,10 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 
 	grpcServer.GracefulStop()
 
+	if dispatchServer != nil {
+		dispatchServer.GracefulStop()
+	}
+
 	svr.Close()
 
 	authenticator.Close()
@@ -26,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage/bolt"
+	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
 
@@ -205,6 +207,18 @@ func TestBuildServiceWithPresharedKeyAuthenticationFailsIfZeroKeys(t *testing.T)
 	require.EqualError(t, err, "failed to initialize authenticator: invalid auth configuration, please specify at least one key")
 }
 
+func TestBuildServiceFailsIfDatastoreSchemaIsOutdated(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	cfg.Datastore.Engine = "sqlite"
+	cfg.Datastore.URI = filepath.Join(t.TempDir(), "openfga.db")
+
+	err := runServer(context.Background(), cfg)
+	require.ErrorContains(t, err, "the datastore schema is older than this version of OpenFGA expects: datastore requires migrations")
+}
+
 func TestBuildServiceWithNoAuth(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -733,6 +747,40 @@ func TestHTTPServingTLS(t *testing.T) {
 	})
 }
 
+func TestCheckDispatchTLSConfig(t *testing.T) {
+	caCert, caPEM, caKey := genCACert(t)
+	_, serverPEM, serverKey := genServerCert(t, caCert, caKey)
+	certFile := writeToTempFile(t, serverPEM)
+	keyFile := writeToTempFile(t, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(serverKey),
+	}))
+	caFile := writeToTempFile(t, caPEM)
+	t.Cleanup(func() {
+		os.Remove(certFile.Name())
+		os.Remove(keyFile.Name())
+		os.Remove(caFile.Name())
+	})
+
+	tlsConfig, err := checkDispatchTLSConfig(serverconfig.RemoteCheckDispatchTLSConfig{
+		Enabled:  true,
+		CertPath: certFile.Name(),
+		KeyPath:  keyFile.Name(),
+		CAPath:   caFile.Name(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	_, err = checkDispatchTLSConfig(serverconfig.RemoteCheckDispatchTLSConfig{
+		Enabled:  true,
+		CertPath: certFile.Name(),
+		KeyPath:  keyFile.Name(),
+		CAPath:   keyFile.Name(),
+	})
+	require.EqualError(t, err, "'remoteCheckDispatch.tls.ca' contains no PEM certificate")
+}
+
 func TestGRPCServingTLS(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -950,6 +998,8 @@ func testServerMetricsReporting(t *testing.T, engine string) {
 		"openfga_list_objects_further_eval_required_count",
 		"openfga_list_objects_no_further_eval_required_count",
 		"go_sql_idle_connections",
+		"openfga_datastore_method_duration_ms",
+		"openfga_datastore_method_rows",
 		"openfga_condition_evaluation_cost",
 		"openfga_condition_compilation_duration_ms",
 		"openfga_condition_evaluation_duration_ms",
@@ -1039,6 +1089,10 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.False(t, val.Bool())
 
+	val = res.Get("properties.datastore.properties.metrics.properties.storeLabelLimit.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Datastore.Metrics.StoreLabelLimit)
+
 	val = res.Get("properties.grpc.properties.addr.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.GRPC.Addr)
@@ -1099,6 +1153,14 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.MaxConcurrentReadsForListUsers)
 
+	val = res.Get("properties.maxChecksPerBatchCheck.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MaxChecksPerBatchCheck)
+
+	val = res.Get("properties.maxConcurrentChecksPerBatchCheck.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.MaxConcurrentChecksPerBatchCheck)
+
 	val = res.Get("properties.changelogHorizonOffset.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ChangelogHorizonOffset)
@@ -1249,6 +1311,154 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ListUsersDispatchThrottling.MaxThreshold)
 
+	val = res.Get("properties.remoteCheckDispatch.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.RemoteCheckDispatch.Enabled)
+
+	val = res.Get("properties.remoteCheckDispatch.properties.addr.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.RemoteCheckDispatch.Addr)
+
+	val = res.Get("properties.remoteCheckDispatch.properties.peerRefreshInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.RemoteCheckDispatch.PeerRefreshInterval.String())
+
+	val = res.Get("properties.remoteCheckDispatch.properties.peerUnhealthyBackoff.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.RemoteCheckDispatch.PeerUnhealthyBackoff.String())
+
+	val = res.Get("properties.watchChanges.properties.heartbeatInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.WatchChanges.HeartbeatInterval.String())
+
+	val = res.Get("properties.watchChanges.properties.minPollInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.WatchChanges.MinPollInterval.String())
+
+	val = res.Get("properties.watchChanges.properties.maxPollInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.WatchChanges.MaxPollInterval.String())
+
+	val = res.Get("properties.tupleExpiration.properties.reaperInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.TupleExpiration.ReaperInterval.String())
+
+	val = res.Get("properties.tupleExpiration.properties.reaperBatchSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.TupleExpiration.ReaperBatchSize)
+
+	val = res.Get("properties.changelogRetention.properties.maxAge.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ChangelogRetention.MaxAge.String())
+
+	val = res.Get("properties.changelogRetention.properties.compactAfter.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ChangelogRetention.CompactAfter.String())
+
+	val = res.Get("properties.changelogRetention.properties.pruneInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ChangelogRetention.PruneInterval.String())
+
+	val = res.Get("properties.changelogRetention.properties.pruneBatchSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ChangelogRetention.PruneBatchSize)
+
+	val = res.Get("properties.storePurge.properties.retention.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.StorePurge.Retention.String())
+
+	val = res.Get("properties.storePurge.properties.interval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.StorePurge.Interval.String())
+
+	val = res.Get("properties.storePurge.properties.batchSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StorePurge.BatchSize)
+
+	val = res.Get("properties.storeQuota.properties.maxTuples.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StoreQuota.MaxTuples)
+
+	val = res.Get("properties.storeQuota.properties.maxAuthorizationModels.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StoreQuota.MaxAuthorizationModels)
+
+	val = res.Get("properties.storeQuota.properties.maxAssertions.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.StoreQuota.MaxAssertions)
+
+	val = res.Get("properties.storeQuota.properties.writeRate.default")
+	require.True(t, val.Exists())
+	require.InDelta(t, val.Float(), cfg.StoreQuota.WriteRate, 0)
+
+	val = res.Get("properties.outbox.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.Outbox.Enabled)
+
+	val = res.Get("properties.outbox.properties.pollInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Outbox.PollInterval.String())
+
+	val = res.Get("properties.outbox.properties.batchSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Outbox.BatchSize)
+
+	val = res.Get("properties.outbox.properties.webhook.properties.maxRetries.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.Outbox.Webhook.MaxRetries)
+
+	val = res.Get("properties.outbox.properties.webhook.properties.timeout.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Outbox.Webhook.Timeout.String())
+
+	val = res.Get("properties.checkDatastoreBatching.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.CheckDatastoreBatching.Enabled)
+
+	val = res.Get("properties.checkDatastoreBatching.properties.window.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.CheckDatastoreBatching.Window.String())
+
+	val = res.Get("properties.checkDatastoreBatching.properties.maxBatchSize.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.CheckDatastoreBatching.MaxBatchSize)
+
+	val = res.Get("properties.datastore.properties.replicaMaxLag.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.ReplicaMaxLag.String())
+
+	val = res.Get("properties.datastore.properties.replicaCheckInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.ReplicaCheckInterval.String())
+
+	val = res.Get("properties.datastore.properties.memory.properties.fsync.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.Memory.Fsync)
+
+	val = res.Get("properties.datastore.properties.memory.properties.snapshotInterval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.Memory.SnapshotInterval.String())
+
+	val = res.Get("properties.datastore.properties.shardPlacement.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.ShardPlacement)
+
+	val = res.Get("properties.datastore.properties.shardPlacementCacheTTL.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Datastore.ShardPlacementCacheTTL.String())
+
+	val = res.Get("properties.cache.properties.shared.properties.addr.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Cache.Shared.Addr)
+
+	val = res.Get("properties.cache.properties.shared.properties.timeout.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Cache.Shared.Timeout.String())
+
+	val = res.Get("properties.cache.properties.shared.properties.backoff.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.Cache.Shared.Backoff.String())
+
 	val = res.Get("properties.requestTimeout.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.String(), cfg.RequestTimeout.String())
@@ -1510,6 +1720,62 @@ func TestServerContext_datastoreConfig(t *testing.T) {
 			wantSerializer: nil,
 			wantErr:        errors.New("invalid semicolon separator in query"),
 		},
+		{
+			name: "durable_memory",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "memory",
+					Memory: serverconfig.DatastoreMemoryConfig{
+						Dir:              t.TempDir(),
+						Fsync:            "interval",
+						SnapshotInterval: time.Minute,
+					},
+				},
+			},
+			wantDSType:     &memory.MemoryBackend{},
+			wantSerializer: &encoder.StringContinuationTokenSerializer{},
+			wantErr:        nil,
+		},
+		{
+			name: "durable_memory_bad_fsync",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "memory",
+					Memory: serverconfig.DatastoreMemoryConfig{
+						Dir:              t.TempDir(),
+						Fsync:            "sometimes",
+						SnapshotInterval: time.Minute,
+					},
+				},
+			},
+			wantDSType:     nil,
+			wantSerializer: nil,
+			wantErr:        errors.New("unsupported fsync policy 'sometimes'"),
+		},
+		{
+			name: "bolt",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "bolt",
+					URI:    filepath.Join(t.TempDir(), "openfga.db"),
+				},
+			},
+			wantDSType:     &bolt.Bolt{},
+			wantSerializer: &sqlcommon.SQLContinuationTokenSerializer{},
+			wantErr:        nil,
+		},
+		{
+			name: "bolt_bad_uri",
+			config: &serverconfig.Config{
+				Datastore: serverconfig.DatastoreConfig{
+					Engine: "bolt",
+					URI:    filepath.Join(t.TempDir(), "missing", "openfga.db"),
+				},
+			},
+			wantDSType:     nil,
+			wantSerializer: nil,
+			wantErr:        errors.New("open bolt database"),
+		},
 		{
 			name: "mysql_bad_uri",
 			config: &serverconfig.Config{
@@ -1563,6 +1829,7 @@ func TestServerContext_datastoreConfig(t *testing.T) {
 				assert.ErrorContains(t, err, tt.wantErr.Error())
 			} else {
 				require.NoError(t, err)
+				t.Cleanup(datastore.Close)
 				assert.IsType(t, tt.wantDSType, datastore)
 				assert.Equal(t, tt.wantSerializer, serializer)
 			}
@@ -0,0 +1,22 @@
+package shards
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindMoveStoreFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindMoveStoreFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
+		util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
+		util.MustBindPFlag(datastoreShardsFlag, flags.Lookup(datastoreShardsFlag))
+		util.MustBindPFlag(outboxFlag, flags.Lookup(outboxFlag))
+		util.MustBindPFlag(shardPlacementCacheTTLFlag, flags.Lookup(shardPlacementCacheTTLFlag))
+		util.MustBindPFlag(storeIDFlag, flags.Lookup(storeIDFlag))
+		util.MustBindPFlag(toFlag, flags.Lookup(toFlag))
+	}
+}
@@ -0,0 +1,111 @@
+package shards
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/cmd/util"
+	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage/sharding"
+)
+
+func NewMoveStoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move-store",
+		Short: "Move a store to another shard of a sharded datastore",
+		Long: "Copy a store, with its authorization models, assertions, labels, tuples and changelog, to another shard " +
+			"while the servers keep serving it, then flip its placement and delete it from its previous shard.\n" +
+			"The writes of the store are only frozen in this process while its placement is flipped: the servers " +
+			"keep writing to its previous shard until their cache of its placement expires, so the move waits as " +
+			"long before copying their last changes. The tuples and changes keep their ULIDs, timestamps and " +
+			"expiry, so the continuation tokens of ReadChanges obtained before the move stay valid. A failed move " +
+			"can be retried.",
+		RunE: runMoveStore,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore, which holds the placements of the stores and is the 'default' shard")
+	flags.StringSlice(datastoreShardsFlag, nil, "the other shards of the datastore, as '<name>=<uri>', as given to the servers")
+	flags.Bool(outboxFlag, false, util.OutboxFlagUsage)
+	flags.Duration(shardPlacementCacheTTLFlag, serverconfig.DefaultDatastoreShardPlacementCacheTTL, "how long the servers cache the placements of the stores, as given to them")
+	flags.String(storeIDFlag, "", "the id of the store to move")
+	flags.String(toFlag, "", "the name of the shard to move the store to")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindMoveStoreFlagsFunc(flags)
+
+	return cmd
+}
+
+// moveStoreResult is the outcome of a move, printed once it is done.
+type moveStoreResult struct {
+	StoreID  string `json:"store_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Duration string `json:"duration"`
+}
+
+func runMoveStore(cmd *cobra.Command, _ []string) error {
+	storeID := viper.GetString(storeIDFlag)
+	if storeID == "" {
+		return fmt.Errorf("missing store id")
+	}
+	to := viper.GetString(toFlag)
+	if to == "" {
+		return fmt.Errorf("missing shard to move the store to")
+	}
+	cacheTTL := viper.GetDuration(shardPlacementCacheTTLFlag)
+	if cacheTTL < 0 {
+		return fmt.Errorf("the placement cache TTL must be non-negative")
+	}
+
+	// the progress of the move is logged to stderr, its result being printed to stdout
+	log, err := logger.NewLogger(logger.WithOutputPaths("stderr"))
+	if err != nil {
+		return err
+	}
+
+	ds, err := openShardedDatastore(
+		viper.GetString(datastoreEngineFlag),
+		viper.GetString(datastoreURIFlag),
+		viper.GetStringSlice(datastoreShardsFlag),
+		viper.GetBool(outboxFlag),
+		sharding.WithPlacementCacheTTL(cacheTTL),
+		sharding.WithLogger(log),
+	)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	from, err := ds.ShardOf(cmd.Context(), storeID)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := ds.MoveStore(cmd.Context(), storeID, to); err != nil {
+		return fmt.Errorf("failed to move the store: %w", err)
+	}
+
+	marshalled, err := json.MarshalIndent(moveStoreResult{
+		StoreID:  storeID,
+		From:     from,
+		To:       to,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}, " ", "    ")
+	if err != nil {
+		return fmt.Errorf("error gathering move results: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(marshalled))
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package shards contains the commands to work with the shards of a sharded datastore.
+package shards
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openfga/openfga/cmd/util"
+	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sharding"
+)
+
+const (
+	datastoreEngineFlag        = "datastore-engine"
+	datastoreURIFlag           = "datastore-uri"
+	datastoreShardsFlag        = "datastore-shards"
+	shardPlacementCacheTTLFlag = "datastore-shard-placement-cache-ttl"
+	storeIDFlag                = "store-id"
+	toFlag                     = "to"
+	outboxFlag                 = "outbox"
+
+	// defaultShardName is the name of the shard of the datastore itself, as named by the run command.
+	defaultShardName = "default"
+)
+
+func NewShardsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shards",
+		Short: "Work with the shards of a sharded datastore",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewMoveStoreCommand())
+
+	return cmd
+}
+
+// openShardedDatastore opens the datastore, which is the catalog and the default shard, and its shards, each given
+// as '<name>=<uri>', and returns a datastore routing the stores to them. With outbox, the shards record their changes
+// in their outboxes.
+func openShardedDatastore(engine, uri string, shards []string, outbox bool, opts ...sharding.Option) (*sharding.Datastore, error) {
+	catalog, err := util.OpenDatastore(engine, uri, outbox)
+	if err != nil {
+		return nil, err
+	}
+
+	datastores := map[string]storage.OpenFGADatastore{defaultShardName: catalog}
+	closeAll := func() {
+		for _, ds := range datastores {
+			ds.Close()
+		}
+	}
+
+	for _, shard := range shards {
+		name, shardURI, err := serverconfig.ParseDatastoreShard(shard)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		if _, ok := datastores[name]; ok {
+			closeAll()
+			return nil, fmt.Errorf("the shard '%s' is defined more than once", name)
+		}
+
+		ds, err := util.OpenDatastore(engine, shardURI, outbox)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("shard '%s': %w", name, err)
+		}
+		datastores[name] = ds
+	}
+
+	sharded, err := sharding.New(catalog, defaultShardName, datastores, opts...)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return sharded, nil
+}
@@ -0,0 +1,96 @@
+package shards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMoveStoreCommand(t *testing.T) {
+	ctx := context.Background()
+	_, catalog, catalogURI := util.MustBootstrapDatastore(t, "sqlite")
+	_, shard, shardURI := util.MustBootstrapDatastore(t, "sqlite")
+
+	storeID := ulid.Make().String()
+	_, err := catalog.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "acme"})
+	require.NoError(t, err)
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	require.NoError(t, catalog.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, catalog.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	}))
+
+	var output bytes.Buffer
+	moveCmd := NewMoveStoreCommand()
+	moveCmd.SetOut(&output)
+	moveCmd.SetArgs([]string{
+		"--datastore-engine", "sqlite",
+		"--datastore-uri", catalogURI,
+		"--datastore-shards", "eu=" + shardURI,
+		"--datastore-shard-placement-cache-ttl", "0s",
+		"--store-id", storeID,
+		"--to", "eu",
+	})
+	require.NoError(t, moveCmd.ExecuteContext(ctx))
+
+	var result moveStoreResult
+	require.NoError(t, json.Unmarshal(output.Bytes(), &result))
+	require.Equal(t, storeID, result.StoreID)
+	require.Equal(t, "default", result.From)
+	require.Equal(t, "eu", result.To)
+
+	_, err = catalog.GetStore(ctx, storeID)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	got, err := shard.GetStore(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, "acme", got.GetName())
+
+	_, err = shard.ReadAuthorizationModel(ctx, storeID, model.GetId())
+	require.NoError(t, err)
+	_, err = shard.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+	require.NoError(t, err)
+}
+
+func TestMoveStoreCommandWhenInvalidFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args          []string
+		errorExpected string
+	}{
+		{
+			args:          []string{"--datastore-engine", "sqlite", "--to", "eu"},
+			errorExpected: "missing store id",
+		},
+		{
+			args:          []string{"--datastore-engine", "sqlite", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS"},
+			errorExpected: "missing shard to move the store to",
+		},
+		{
+			args:          []string{"--datastore-engine", "memory", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS", "--to", "eu"},
+			errorExpected: "storage engine 'memory' is unsupported",
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			moveCmd := NewMoveStoreCommand()
+			moveCmd.SetArgs(tc.args)
+			require.ErrorContains(t, moveCmd.Execute(), tc.errorExpected)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package tuples
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	outputFlag            = "output"
+	pageSizeFlag          = "page-size"
+	continuationTokenFlag = "continuation-token"
+
+	defaultExportPageSize = 1000
+)
+
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the tuples of a store to a file",
+		Long: "Export the tuples of a store to a file, reading them directly from the datastore page by page.\n" +
+			"If the export is interrupted, it can be resumed with the continuation token it reports. When resumed, " +
+			"the tuples are appended to the output file.",
+		RunE: runExport,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.String(storeIDFlag, "", "the id of the store to export the tuples of")
+	flags.String(outputFlag, "-", "the file to export the tuples to, or '-' for the standard output")
+	flags.String(formatFlag, formatJSONL, "the format of the file: jsonl, csv or tuple")
+	flags.Int(pageSizeFlag, defaultExportPageSize, "the number of tuples read from the datastore at once")
+	flags.String(continuationTokenFlag, "", "the continuation token reported by an interrupted export, to resume it")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindExportFlagsFunc(flags)
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, _ []string) error {
+	storeID := viper.GetString(storeIDFlag)
+	if storeID == "" {
+		return fmt.Errorf("missing store id")
+	}
+
+	format := viper.GetString(formatFlag)
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+
+	db, err := util.OpenDatastore(viper.GetString(datastoreEngineFlag), viper.GetString(datastoreURIFlag), false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	continuationToken := viper.GetString(continuationTokenFlag)
+
+	var output io.Writer = cmd.OutOrStdout()
+	if file := viper.GetString(outputFlag); file != "-" {
+		flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if continuationToken != "" {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+
+		f, err := os.OpenFile(file, flag, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	count, err := ExportTuples(cmd.Context(), db, storeID, format, output, viper.GetInt(pageSizeFlag), continuationToken)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "exported %d tuples\n", count)
+	return nil
+}
+
+// ExportTuples writes the tuples of the store to output in the given format, starting from the continuation token if
+// it is not empty, and returns how many were written. If it fails, the returned error holds the continuation token
+// to resume the export from, which is past the tuples already written.
+func ExportTuples(ctx context.Context, db storage.OpenFGADatastore, storeID, format string, output io.Writer, pageSize int, continuationToken string) (int, error) {
+	tokenEncoder := encoder.NewBase64Encoder()
+	token, err := tokenEncoder.Decode(continuationToken)
+	if err != nil {
+		return 0, fmt.Errorf("invalid continuation token: %w", err)
+	}
+
+	tupleEncoder, err := newTupleEncoder(format, output, continuationToken != "")
+	if err != nil {
+		return 0, err
+	}
+
+	interrupted := func(err error) error {
+		if len(token) == 0 {
+			return fmt.Errorf("export failed, it must be started over: %w", err)
+		}
+		encodedToken, _ := tokenEncoder.Encode(token)
+		return fmt.Errorf("export interrupted, resume it with --%s=%s: %w", continuationTokenFlag, encodedToken, err)
+	}
+
+	count := 0
+	for {
+		tuples, nextToken, err := db.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(int32(pageSize), string(token)),
+		})
+		if err != nil {
+			return count, interrupted(err)
+		}
+
+		for _, t := range tuples {
+			if err := tupleEncoder.Encode(t.GetKey()); err != nil {
+				return count, interrupted(err)
+			}
+		}
+
+		// the page is only done once it is fully written
+		if err := tupleEncoder.Flush(); err != nil {
+			return count, interrupted(err)
+		}
+		count += len(tuples)
+
+		if len(nextToken) == 0 {
+			return count, nil
+		}
+		token = nextToken
+	}
+}
@@ -0,0 +1,250 @@
+package tuples
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// The formats of the tuples in a file:
+//   - jsonl: one tuple key per line, in the JSON format of the API (e.g. {"object":"document:1","relation":"viewer","user":"user:anne"}).
+//   - csv: a header row naming the columns user, relation, object, and optionally condition_name and condition_context,
+//     the latter being a JSON object.
+//   - tuple: one tuple per line in the format of pkg/tuple (e.g. document:1#viewer@user:anne), which can't hold
+//     conditions.
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+	formatTuple = "tuple"
+)
+
+var formats = []string{formatJSONL, formatCSV, formatTuple}
+
+var csvHeader = []string{"user", "relation", "object", "condition_name", "condition_context"}
+
+// maxLineSize is the size of the longest line that can be read, which is enough for any valid tuple.
+const maxLineSize = 1 << 20
+
+func validateFormat(format string) error {
+	if !slices.Contains(formats, format) {
+		return fmt.Errorf("invalid format '%s', must be one of %s", format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// tupleDecoder reads tuple keys from a file, returning io.EOF once there are none left.
+type tupleDecoder interface {
+	Decode() (*openfgav1.TupleKey, error)
+}
+
+func newTupleDecoder(format string, r io.Reader) (tupleDecoder, error) {
+	switch format {
+	case formatJSONL:
+		return &lineDecoder{scanner: newLineScanner(r), parse: func(line string) (*openfgav1.TupleKey, error) {
+			tk := &openfgav1.TupleKey{}
+			if err := protojson.Unmarshal([]byte(line), tk); err != nil {
+				return nil, err
+			}
+			return tk, nil
+		}}, nil
+	case formatTuple:
+		return &lineDecoder{scanner: newLineScanner(r), parse: tuple.ParseTupleString}, nil
+	case formatCSV:
+		return newCSVDecoder(r)
+	default:
+		return nil, validateFormat(format)
+	}
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return scanner
+}
+
+// lineDecoder decodes a tuple key per line, skipping the blank lines.
+type lineDecoder struct {
+	scanner *bufio.Scanner
+	parse   func(line string) (*openfgav1.TupleKey, error)
+	line    int
+}
+
+func (d *lineDecoder) Decode() (*openfgav1.TupleKey, error) {
+	for d.scanner.Scan() {
+		d.line++
+
+		line := strings.TrimSpace(d.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		tk, err := d.parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", d.line, err)
+		}
+		return tk, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", d.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("missing csv header")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(csvHeader, name) {
+			return nil, fmt.Errorf("unknown csv column '%s'", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range csvHeader[:3] {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing csv column '%s'", name)
+		}
+	}
+
+	return &csvDecoder{reader: reader, columns: columns}, nil
+}
+
+func (d *csvDecoder) Decode() (*openfgav1.TupleKey, error) {
+	record, err := d.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := d.reader.FieldPos(0)
+	field := func(name string) string {
+		i, ok := d.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	tk := tuple.NewTupleKey(field("object"), field("relation"), field("user"))
+
+	if name := field("condition_name"); name != "" {
+		var conditionContext *structpb.Struct
+		if raw := field("condition_context"); raw != "" {
+			conditionContext = &structpb.Struct{}
+			if err := protojson.Unmarshal([]byte(raw), conditionContext); err != nil {
+				return nil, fmt.Errorf("line %d: invalid condition context: %w", line, err)
+			}
+		}
+		tk.Condition = tuple.NewRelationshipCondition(name, conditionContext)
+	}
+
+	return tk, nil
+}
+
+// tupleEncoder writes tuple keys to a file. Flush must be called once they are all written.
+type tupleEncoder interface {
+	Encode(tk *openfgav1.TupleKey) error
+	Flush() error
+}
+
+// newTupleEncoder returns an encoder of the format, writing the csv header unless the file is being appended to.
+func newTupleEncoder(format string, w io.Writer, appending bool) (tupleEncoder, error) {
+	switch format {
+	case formatJSONL:
+		return &lineEncoder{writer: bufio.NewWriter(w), format: func(tk *openfgav1.TupleKey) (string, error) {
+			data, err := protojson.Marshal(tk)
+			return string(data), err
+		}}, nil
+	case formatTuple:
+		return &lineEncoder{writer: bufio.NewWriter(w), format: func(tk *openfgav1.TupleKey) (string, error) {
+			if tk.GetCondition() != nil {
+				return "", fmt.Errorf("tuple '%s' has a condition, which the %s format can't hold", tuple.TupleKeyToString(tk), formatTuple)
+			}
+			return tuple.TupleKeyToString(tk), nil
+		}}, nil
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		if !appending {
+			if err := writer.Write(csvHeader); err != nil {
+				return nil, err
+			}
+		}
+		return &csvEncoder{writer: writer}, nil
+	default:
+		return nil, validateFormat(format)
+	}
+}
+
+type lineEncoder struct {
+	writer *bufio.Writer
+	format func(tk *openfgav1.TupleKey) (string, error)
+}
+
+func (e *lineEncoder) Encode(tk *openfgav1.TupleKey) error {
+	line, err := e.format(tk)
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.writer.WriteString(line); err != nil {
+		return err
+	}
+	return e.writer.WriteByte('\n')
+}
+
+func (e *lineEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) Encode(tk *openfgav1.TupleKey) error {
+	var conditionContext string
+	if tk.GetCondition().GetContext() != nil {
+		data, err := protojson.Marshal(tk.GetCondition().GetContext())
+		if err != nil {
+			return err
+		}
+		conditionContext = string(data)
+	}
+
+	return e.writer.Write([]string{
+		tk.GetUser(),
+		tk.GetRelation(),
+		tk.GetObject(),
+		tk.GetCondition().GetName(),
+		conditionContext,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
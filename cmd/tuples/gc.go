@@ -0,0 +1,179 @@
+package tuples
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	dryRunFlag = "dry-run"
+
+	defaultGCPageSize  = 1000
+	defaultGCBatchSize = 100
+)
+
+func NewGCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc-tuples",
+		Short: "Delete the tuples of a store orphaned by changes of its authorization model",
+		Long: "Find the tuples of a store that are invalid under its authorization model, e.g. because their relation " +
+			"was removed or their type restriction narrowed, and delete them.\n" +
+			"The tuples are read directly from the datastore page by page, and the orphaned tuples of each page are " +
+			"deleted in batches through Write, so their deletes are recorded in the changelog. They are reported as " +
+			"JSON lines as they are found, followed by the counts of tuples scanned and deleted. With --dry-run, " +
+			"they are only reported.",
+		RunE: runGC,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.Bool(outboxFlag, false, util.OutboxFlagUsage)
+	flags.String(storeIDFlag, "", "the id of the store to delete the orphaned tuples of")
+	flags.String(modelIDFlag, "", "the id of the authorization model to validate the tuples against. Defaults to the latest model of the store.")
+	flags.Int(pageSizeFlag, defaultGCPageSize, "the number of tuples read from the datastore at once")
+	flags.Int(batchSizeFlag, defaultGCBatchSize, "the maximum number of tuples deleted at once. It is capped by the maximum number of tuples per write of the datastore")
+	flags.Bool(dryRunFlag, false, "only report the orphaned tuples, without deleting them")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindGCFlagsFunc(flags)
+
+	return cmd
+}
+
+// gcResult is the outcome of a garbage collection, printed once it is done.
+type gcResult struct {
+	ScannedCount int64 `json:"scanned_count"`
+	// DeletedCount is the number of orphaned tuples deleted. It is zero in a dry run.
+	DeletedCount int64 `json:"deleted_count"`
+}
+
+// gcOrphanedTuple is an orphaned tuple found by a garbage collection, printed as soon as it is found.
+type gcOrphanedTuple struct {
+	Tuple string `json:"tuple"`
+	Error string `json:"error"`
+}
+
+func runGC(cmd *cobra.Command, _ []string) error {
+	storeID := viper.GetString(storeIDFlag)
+	if storeID == "" {
+		return fmt.Errorf("missing store id")
+	}
+
+	pageSize, batchSize := viper.GetInt(pageSizeFlag), viper.GetInt(batchSizeFlag)
+	if pageSize <= 0 || batchSize <= 0 {
+		return fmt.Errorf("the page size and batch size must be positive")
+	}
+
+	db, err := util.OpenDatastore(viper.GetString(datastoreEngineFlag), viper.GetString(datastoreURIFlag), viper.GetBool(outboxFlag))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// the report is printed as JSON lines: one per orphaned tuple, and the result last
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	result, err := GCTuples(cmd.Context(), db, storeID, viper.GetString(modelIDFlag), pageSize, batchSize, viper.GetBool(dryRunFlag),
+		func(orphaned gcOrphanedTuple) error {
+			return encoder.Encode(orphaned)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("error gathering gc results: %w", err)
+	}
+
+	return nil
+}
+
+// GCTuples finds the tuples of the store that are invalid under the model with the given id, or the latest model of
+// the store if it is empty, reports them, and deletes them unless dryRun is set.
+//
+// The tuples are scanned page by page, and the orphaned tuples of a page are deleted before the next page is read.
+// The datastores supported by the command paginate ReadPage by ULID, so the deletes don't shift the following pages.
+// The deletes go through Write and ignore the tuples deleted in between, so they are recorded in the changelog and
+// invalidate the caches like any other.
+func GCTuples(
+	ctx context.Context,
+	db storage.OpenFGADatastore,
+	storeID, modelID string,
+	pageSize, batchSize int,
+	dryRun bool,
+	report func(orphaned gcOrphanedTuple) error,
+) (*gcResult, error) {
+	var (
+		model *openfgav1.AuthorizationModel
+		err   error
+	)
+	if modelID != "" {
+		model, err = db.ReadAuthorizationModel(ctx, storeID, modelID)
+	} else {
+		model, err = db.FindLatestAuthorizationModel(ctx, storeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authorization model: %w", err)
+	}
+
+	typesys, err := typesystem.New(model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	result := &gcResult{}
+	batchSize = min(batchSize, db.MaxTuplesPerWrite())
+
+	var continuationToken string
+	for {
+		tuples, nextToken, err := db.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(int32(pageSize), continuationToken),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the tuples: %w", err)
+		}
+
+		var orphaned []*openfgav1.TupleKeyWithoutCondition
+		for _, t := range tuples {
+			result.ScannedCount++
+
+			// the tuples were valid when written, so only their validity under the model is checked
+			tk := t.GetKey()
+			if err := validation.ValidateTupleForRead(typesys, tk); err != nil {
+				orphaned = append(orphaned, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+				if err := report(gcOrphanedTuple{Tuple: tuple.TupleKeyToString(tk), Error: err.Error()}); err != nil {
+					return nil, fmt.Errorf("failed to report the orphaned tuples: %w", err)
+				}
+			}
+		}
+
+		if !dryRun {
+			for start := 0; start < len(orphaned); start += batchSize {
+				batch := orphaned[start:min(start+batchSize, len(orphaned))]
+				if err := db.Write(ctx, storeID, batch, nil, storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore)); err != nil {
+					return nil, fmt.Errorf("gc failed after deleting %d tuples: %w", result.DeletedCount, err)
+				}
+				result.DeletedCount += int64(len(batch))
+			}
+		}
+
+		if len(nextToken) == 0 {
+			return result, nil
+		}
+		continuationToken = string(nextToken)
+	}
+}
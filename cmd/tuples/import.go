@@ -0,0 +1,157 @@
+package tuples
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	modelIDFlag   = "model-id"
+	fileFlag      = "file"
+	batchSizeFlag = "batch-size"
+)
+
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import tuples from a file into a store",
+		Long: "Import the tuples of a file into a store, writing them directly to the datastore in large batches.\n" +
+			"The tuples are validated against the authorization model. The ones that are invalid or already exist " +
+			"are reported, and the others are imported.",
+		RunE: runImport,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.Bool(outboxFlag, false, util.OutboxFlagUsage)
+	flags.String(storeIDFlag, "", "the id of the store to import the tuples into")
+	flags.String(modelIDFlag, "", "the id of the authorization model to validate the tuples against. Defaults to the latest model of the store.")
+	flags.String(fileFlag, "-", "the file to import the tuples from, or '-' for the standard input")
+	flags.String(formatFlag, formatJSONL, "the format of the file: jsonl, csv or tuple")
+	flags.Int(batchSizeFlag, storage.DefaultMaxTuplesPerImport, "the maximum number of tuples written to the datastore at once")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindImportFlagsFunc(flags)
+
+	return cmd
+}
+
+// importResult is the outcome of an import, printed once it is done.
+type importResult struct {
+	ImportedCount int64            `json:"imported_count"`
+	Errors        []importRowError `json:"errors"`
+}
+
+type importRowError struct {
+	// Row is the position of the tuple in the file, starting from 1, not counting blank lines and the csv header.
+	Row   int64  `json:"row"`
+	Tuple string `json:"tuple"`
+	Error string `json:"error"`
+}
+
+func runImport(cmd *cobra.Command, _ []string) error {
+	storeID := viper.GetString(storeIDFlag)
+	if storeID == "" {
+		return fmt.Errorf("missing store id")
+	}
+
+	format := viper.GetString(formatFlag)
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+
+	db, err := util.OpenDatastore(viper.GetString(datastoreEngineFlag), viper.GetString(datastoreURIFlag), viper.GetBool(outboxFlag))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var input io.Reader = cmd.InOrStdin()
+	if file := viper.GetString(fileFlag); file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	result, err := ImportTuples(cmd.Context(), db, storeID, viper.GetString(modelIDFlag), format, input, viper.GetInt(batchSizeFlag))
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := json.MarshalIndent(result, " ", "    ")
+	if err != nil {
+		return fmt.Errorf("error gathering import results: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(marshalled))
+
+	return nil
+}
+
+// ImportTuples imports the tuples read from input, in the given format, into the store. They are validated against
+// the model with the given id, or the latest model of the store if it is empty.
+func ImportTuples(ctx context.Context, db storage.OpenFGADatastore, storeID, modelID, format string, input io.Reader, batchSize int) (*importResult, error) {
+	var (
+		model *openfgav1.AuthorizationModel
+		err   error
+	)
+	if modelID != "" {
+		model, err = db.ReadAuthorizationModel(ctx, storeID, modelID)
+	} else {
+		model, err = db.FindLatestAuthorizationModel(ctx, storeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authorization model: %w", err)
+	}
+
+	typesys, err := typesystem.New(model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	decoder, err := newTupleDecoder(format, input)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []commands.ImportTuplesCommandOption{commands.WithImportTuplesCmdBatchSize(batchSize)}
+	if importer, ok := db.(storage.TupleImporter); ok {
+		opts = append(opts, commands.WithImportTuplesCmdImporter(importer))
+	}
+
+	cmdResult, err := commands.NewImportTuplesCommand(db, opts...).Execute(ctx, storeID, typesys, decoder.Decode)
+	if err != nil {
+		return nil, fmt.Errorf("import failed after importing %d tuples: %w", cmdResult.ImportedCount, err)
+	}
+
+	result := &importResult{
+		ImportedCount: cmdResult.ImportedCount,
+		Errors:        make([]importRowError, 0, len(cmdResult.Errors)),
+	}
+	for _, rowErr := range cmdResult.Errors {
+		result.Errors = append(result.Errors, importRowError{
+			Row:   rowErr.Index + 1,
+			Tuple: tuple.TupleKeyWithConditionToString(rowErr.TupleKey),
+			Error: rowErr.Err.Error(),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,10 @@
+// Package tuples contains the commands to import and export the tuples of a store, and to delete its orphaned tuples.
+package tuples
+
+const (
+	datastoreEngineFlag = "datastore-engine"
+	datastoreURIFlag    = "datastore-uri"
+	storeIDFlag         = "store-id"
+	formatFlag          = "format"
+	outboxFlag          = "outbox"
+)
@@ -0,0 +1,303 @@
+package tuples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const testModel = `
+	model
+		schema 1.1
+
+	type user
+
+	type document
+		relations
+			define viewer: [user, user with in_region]
+
+	condition in_region(region: string) {
+		region == "eu"
+	}`
+
+func TestImportExportTuples(t *testing.T) {
+	ctx := context.Background()
+	_, ds, _ := util.MustBootstrapDatastore(t, "sqlite")
+
+	inputs := map[string]string{
+		formatJSONL: `{"object":"document:1","relation":"viewer","user":"user:anne"}
+{"object":"document:2","relation":"viewer","user":"user:anne","condition":{"name":"in_region","context":{"region":"eu"}}}
+
+{"object":"document:3","relation":"editor","user":"user:anne"}
+{"object":"document:4","relation":"viewer","user":"user:bob"}
+{"object":"document:1","relation":"viewer","user":"user:anne"}
+`,
+		formatCSV: `user,relation,object,condition_name,condition_context
+user:anne,viewer,document:1,,
+user:anne,viewer,document:2,in_region,"{""region"":""eu""}"
+user:anne,editor,document:3,,
+user:bob,viewer,document:4,,
+user:anne,viewer,document:1,,
+`,
+		formatTuple: `document:1#viewer@user:anne
+document:2#viewer@user:anne
+document:3#editor@user:anne
+
+document:4#viewer@user:bob
+document:1#viewer@user:anne
+`,
+	}
+
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			storeID := ulid.Make().String()
+			model := testutils.MustTransformDSLToProtoWithID(testModel)
+			require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+
+			result, err := ImportTuples(ctx, ds, storeID, "", format, strings.NewReader(input), 2)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), result.ImportedCount)
+			require.Len(t, result.Errors, 2)
+			require.Equal(t, int64(3), result.Errors[0].Row)
+			require.Equal(t, "document:3#editor@user:anne", result.Errors[0].Tuple)
+			require.Equal(t, int64(5), result.Errors[1].Row)
+
+			var output bytes.Buffer
+			if format == formatTuple {
+				count, err := ExportTuples(ctx, ds, storeID, format, &output, 1, "")
+				require.NoError(t, err)
+				require.Equal(t, 3, count)
+				return
+			}
+
+			count, err := ExportTuples(ctx, ds, storeID, format, &output, 1, "")
+			require.NoError(t, err)
+			require.Equal(t, 3, count)
+
+			// the export can be imported into another store
+			otherStoreID := ulid.Make().String()
+			require.NoError(t, ds.WriteAuthorizationModel(ctx, otherStoreID, model))
+			result, err = ImportTuples(ctx, ds, otherStoreID, model.GetId(), format, &output, 100)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), result.ImportedCount)
+			require.Empty(t, result.Errors)
+
+			requireSameTuples(t, ds, storeID, otherStoreID)
+		})
+	}
+}
+
+func TestExportTuplesIsResumable(t *testing.T) {
+	ctx := context.Background()
+	_, ds, _ := util.MustBootstrapDatastore(t, "sqlite")
+
+	storeID := ulid.Make().String()
+	tks := make([]*openfgav1.TupleKey, 0, 5)
+	for i := 0; i < cap(tks); i++ {
+		tks = append(tks, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne"))
+	}
+	require.NoError(t, ds.Write(ctx, storeID, nil, tks))
+
+	var output bytes.Buffer
+	count, err := ExportTuples(ctx, ds, storeID, formatTuple, &failingWriter{writer: &output, writesLeft: 2}, 2, "")
+	require.Error(t, err)
+	require.Equal(t, 4, count)
+
+	matches := regexp.MustCompile(`--continuation-token=(\S+):`).FindStringSubmatch(err.Error())
+	require.Len(t, matches, 2)
+
+	count, err = ExportTuples(ctx, ds, storeID, formatTuple, &output, 2, matches[1])
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, len(tks))
+	for i, tk := range tks {
+		require.Equal(t, tuple.TupleKeyToString(tk), lines[i])
+	}
+}
+
+func TestExportTuplesWithConditionInTupleFormat(t *testing.T) {
+	ctx := context.Background()
+	_, ds, _ := util.MustBootstrapDatastore(t, "sqlite")
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "in_region", nil),
+	}))
+
+	_, err := ExportTuples(ctx, ds, storeID, formatTuple, io.Discard, 10, "")
+	require.ErrorContains(t, err, "has a condition")
+}
+
+func TestImportExportCommandsWhenInvalidFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args          []string
+		errorExpected string
+	}{
+		{
+			args:          []string{"--datastore-engine", "memory", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS"},
+			errorExpected: "storage engine 'memory' is unsupported",
+		},
+		{
+			args:          []string{"--datastore-engine", "", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS"},
+			errorExpected: "missing datastore engine type",
+		},
+		{
+			args:          []string{"--datastore-engine", "sqlite"},
+			errorExpected: "missing store id",
+		},
+		{
+			args:          []string{"--datastore-engine", "sqlite", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS", "--format", "xml"},
+			errorExpected: "invalid format 'xml'",
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			importCmd := NewImportCommand()
+			importCmd.SetArgs(tc.args)
+			require.ErrorContains(t, importCmd.Execute(), tc.errorExpected)
+
+			exportCmd := NewExportCommand()
+			exportCmd.SetArgs(tc.args)
+			require.ErrorContains(t, exportCmd.Execute(), tc.errorExpected)
+		})
+	}
+}
+
+func TestGCTuples(t *testing.T) {
+	ctx := context.Background()
+	_, ds, _ := util.MustBootstrapDatastore(t, "sqlite")
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, testutils.MustTransformDSLToProtoWithID(testModel)))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKeyWithCondition("document:2", "viewer", "user:anne", "in_region", nil),
+		tuple.NewTupleKeyWithCondition("document:3", "viewer", "user:bob", "in_region", nil),
+	}))
+
+	// the conditional type restriction is removed
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`)))
+
+	var orphaned []gcOrphanedTuple
+	report := func(o gcOrphanedTuple) error {
+		orphaned = append(orphaned, o)
+		return nil
+	}
+
+	result, err := GCTuples(ctx, ds, storeID, "", 2, 1, true, report)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.ScannedCount)
+	require.Zero(t, result.DeletedCount)
+	require.Len(t, orphaned, 2)
+	require.Equal(t, "document:2#viewer@user:anne", orphaned[0].Tuple)
+
+	tuples, _, err := ds.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{Pagination: storage.NewPaginationOptions(100, "")})
+	require.NoError(t, err)
+	require.Len(t, tuples, 3)
+
+	// the orphaned tuples are deleted page by page, without skipping any of the following pages
+	orphaned = nil
+	result, err = GCTuples(ctx, ds, storeID, "", 2, 1, false, report)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.ScannedCount)
+	require.Equal(t, int64(2), result.DeletedCount)
+	require.Len(t, orphaned, 2)
+
+	tuples, _, err = ds.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{Pagination: storage.NewPaginationOptions(100, "")})
+	require.NoError(t, err)
+	require.Len(t, tuples, 1)
+	require.Equal(t, "document:1#viewer@user:anne", tuple.TupleKeyToString(tuples[0].GetKey()))
+
+	// the deletes are recorded in the changelog
+	changes, _, err := ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{SortDesc: true})
+	require.NoError(t, err)
+	require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, changes[0].GetOperation())
+	require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, changes[1].GetOperation())
+
+	orphaned = nil
+	_, err = GCTuples(ctx, ds, storeID, "", 2, 1, false, report)
+	require.NoError(t, err)
+	require.Empty(t, orphaned)
+}
+
+func TestGCCommandWhenInvalidFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args          []string
+		errorExpected string
+	}{
+		{
+			args:          []string{"--datastore-engine", "sqlite"},
+			errorExpected: "missing store id",
+		},
+		{
+			args:          []string{"--datastore-engine", "sqlite", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS", "--batch-size", "0"},
+			errorExpected: "must be positive",
+		},
+		{
+			args:          []string{"--datastore-engine", "memory", "--store-id", "01JA6WMC6ZPRWQVEH3DVGWF6QS"},
+			errorExpected: "storage engine 'memory' is unsupported",
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			gcCmd := NewGCCommand()
+			gcCmd.SetArgs(tc.args)
+			require.ErrorContains(t, gcCmd.Execute(), tc.errorExpected)
+		})
+	}
+}
+
+func requireSameTuples(t *testing.T, ds storage.OpenFGADatastore, storeID, otherStoreID string) {
+	t.Helper()
+
+	read := func(storeID string) []string {
+		tuples, _, err := ds.ReadPage(context.Background(), storeID, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(100, ""),
+		})
+		require.NoError(t, err)
+
+		keys := make([]string, 0, len(tuples))
+		for _, tp := range tuples {
+			keys = append(keys, fmt.Sprintf("%s %v", tuple.TupleKeyToString(tp.GetKey()), tp.GetKey().GetCondition().GetContext().AsMap()))
+		}
+		return keys
+	}
+
+	require.ElementsMatch(t, read(storeID), read(otherStoreID))
+}
+
+// failingWriter fails once it has been written to writesLeft times.
+type failingWriter struct {
+	writer     io.Writer
+	writesLeft int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writesLeft == 0 {
+		return 0, errors.New("write failed")
+	}
+	w.writesLeft--
+	return w.writer.Write(p)
+}
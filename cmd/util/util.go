@@ -3,8 +3,10 @@
 package util
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/pflag"
@@ -14,6 +16,7 @@ import (
 	"github.com/openfga/openfga/pkg/storage/memory"
 
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/bolt"
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
@@ -48,6 +51,58 @@ func Index[E comparable](s []E, v E) int {
 	return -1
 }
 
+// DatastoreEngines are the engines of the datastores which the commands working on a datastore directly can open,
+// see OpenDatastore.
+var DatastoreEngines = []string{"mysql", "postgres", "sqlite", "bolt"}
+
+// DatastoreEnginesUsage lists the DatastoreEngines for the help of a flag, as "('mysql', ... or 'bolt')".
+func DatastoreEnginesUsage() string {
+	quoted := make([]string, 0, len(DatastoreEngines))
+	for _, engine := range DatastoreEngines {
+		quoted = append(quoted, "'"+engine+"'")
+	}
+	return "(" + strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1] + ")"
+}
+
+// OutboxFlagUsage is the help of the flag of the commands writing to a datastore which enables its outbox.
+const OutboxFlagUsage = "record the changes in the outbox of the datastore, as the servers do with --outbox-enabled, " +
+	"so that they are delivered to its sinks. It requires a datastore with the outbox migration applied"
+
+// OpenDatastore opens the datastore of one of the DatastoreEngines at the URI, for the commands working on a
+// datastore directly. With outbox, the datastore records its changes in its outbox, see storage.EventOutbox.
+func OpenDatastore(engine, uri string, outbox bool) (storage.OpenFGADatastore, error) {
+	var sqlOptions []sqlcommon.DatastoreOption
+	var boltOptions []bolt.StorageOption
+	if outbox {
+		sqlOptions = append(sqlOptions, sqlcommon.WithOutbox())
+		boltOptions = append(boltOptions, bolt.WithOutbox())
+	}
+
+	var (
+		db  storage.OpenFGADatastore
+		err error
+	)
+	switch engine {
+	case "mysql":
+		db, err = mysql.New(uri, sqlcommon.NewConfig(sqlOptions...))
+	case "postgres":
+		db, err = postgres.New(uri, sqlcommon.NewConfig(sqlOptions...))
+	case "sqlite":
+		db, err = sqlite.New(uri, sqlcommon.NewConfig(sqlOptions...))
+	case "bolt":
+		db, err = bolt.New(uri, boltOptions...)
+	case "":
+		return nil, fmt.Errorf("missing datastore engine type")
+	default:
+		return nil, fmt.Errorf("storage engine '%s' is unsupported", engine)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a connection to the datastore: %v", err)
+	}
+	return db, nil
+}
+
 // MustBootstrapDatastore returns the datastore's container, the datastore, and the URI to connect to it.
 // It automatically cleans up the container after the test finishes.
 func MustBootstrapDatastore(t testing.TB, engine string) (storagefixtures.DatastoreTestContainer, storage.OpenFGADatastore, string) {
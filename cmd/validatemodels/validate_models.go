@@ -9,11 +9,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/openfga/openfga/cmd/util"
 	"github.com/openfga/openfga/pkg/storage"
-	"github.com/openfga/openfga/pkg/storage/mysql"
-	"github.com/openfga/openfga/pkg/storage/postgres"
-	"github.com/openfga/openfga/pkg/storage/sqlcommon"
-	"github.com/openfga/openfga/pkg/storage/sqlite"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -32,7 +29,7 @@ func NewValidateCommand() *cobra.Command {
 	}
 
 	flags := cmd.Flags()
-	flags.String(datastoreEngineFlag, "", "the datastore engine")
+	flags.String(datastoreEngineFlag, "", "the datastore engine "+util.DatastoreEnginesUsage())
 	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
 
 	// NOTE: if you add a new flag here, update the function below, too
@@ -55,27 +52,9 @@ func runValidate(_ *cobra.Command, _ []string) error {
 
 	ctx := context.Background()
 
-	var (
-		db  storage.OpenFGADatastore
-		err error
-	)
-	switch engine {
-	case "mysql":
-		db, err = mysql.New(uri, sqlcommon.NewConfig())
-	case "postgres":
-		db, err = postgres.New(uri, sqlcommon.NewConfig())
-	case "sqlite":
-		db, err = sqlite.New(uri, sqlcommon.NewConfig())
-	case "":
-		return fmt.Errorf("missing datastore engine type")
-	case "memory":
-		fallthrough
-	default:
-		return fmt.Errorf("storage engine '%s' is unsupported", engine)
-	}
-
+	db, err := util.OpenDatastore(engine, uri, false)
 	if err != nil {
-		return fmt.Errorf("failed to open a connection to the datastore: %v", err)
+		return err
 	}
 
 	validationResults, err := ValidateAllAuthorizationModels(ctx, db)
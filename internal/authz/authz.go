@@ -31,19 +31,26 @@ const (
 	ReadAuthorizationModels = "ReadAuthorizationModels"
 	Read                    = "Read"
 	Write                   = "Write"
+	ImportTuples            = "ImportTuples"
 	ListObjects             = "ListObjects"
 	StreamedListObjects     = "StreamedListObjects"
 	Check                   = "Check"
+	BatchCheck              = "BatchCheck"
+	ExplainCheck            = "ExplainCheck"
 	ListUsers               = "ListUsers"
 	WriteAssertions         = "WriteAssertions"
 	ReadAssertions          = "ReadAssertions"
 	WriteAuthorizationModel = "WriteAuthorizationModel"
+	DiffAuthorizationModels = "DiffAuthorizationModels"
 	ListStores              = "ListStores"
 	CreateStore             = "CreateStore"
 	GetStore                = "GetStore"
 	DeleteStore             = "DeleteStore"
+	UndeleteStore           = "UndeleteStore"
+	UpdateStore             = "UpdateStore"
 	Expand                  = "Expand"
 	ReadChanges             = "ReadChanges"
+	WatchChanges            = "WatchChanges"
 
 	// Relations.
 	CanCallReadAuthorizationModels  = "can_call_read_authorization_models"
@@ -168,11 +175,11 @@ func (a *Authorizer) getRelation(apiMethod string) (string, error) {
 		return CanCallReadAuthorizationModels, nil
 	case Read:
 		return CanCallRead, nil
-	case Write:
+	case Write, ImportTuples:
 		return CanCallWrite, nil
 	case ListObjects, StreamedListObjects:
 		return CanCallListObjects, nil
-	case Check:
+	case Check, BatchCheck:
 		return CanCallCheck, nil
 	case ListUsers:
 		return CanCallListUsers, nil
@@ -180,7 +187,7 @@ func (a *Authorizer) getRelation(apiMethod string) (string, error) {
 		return CanCallWriteAssertions, nil
 	case ReadAssertions:
 		return CanCallReadAssertions, nil
-	case WriteAuthorizationModel:
+	case WriteAuthorizationModel, DiffAuthorizationModels:
 		return CanCallWriteAuthorizationModels, nil
 	case ListStores:
 		return CanCallListStores, nil
@@ -188,11 +195,11 @@ func (a *Authorizer) getRelation(apiMethod string) (string, error) {
 		return CanCallCreateStore, nil
 	case GetStore:
 		return CanCallGetStore, nil
-	case DeleteStore:
+	case DeleteStore, UndeleteStore, UpdateStore:
 		return CanCallDeleteStore, nil
-	case Expand:
+	case Expand, ExplainCheck:
 		return CanCallExpand, nil
-	case ReadChanges:
+	case ReadChanges, WatchChanges:
 		return CanCallReadChanges, nil
 	default:
 		return "", AuthorizationError{Err: ErrUnknownAPIMethod}.Err
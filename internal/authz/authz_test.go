@@ -34,18 +34,25 @@ func TestGetRelation(t *testing.T) {
 		{name: "ReadAuthorizationModels", expectedResult: CanCallReadAuthorizationModels},
 		{name: "Read", expectedResult: CanCallRead},
 		{name: "Write", expectedResult: CanCallWrite},
+		{name: "ImportTuples", expectedResult: CanCallWrite},
 		{name: "ListObjects", expectedResult: CanCallListObjects},
 		{name: "StreamedListObjects", expectedResult: CanCallListObjects},
 		{name: "Check", expectedResult: CanCallCheck},
+		{name: "BatchCheck", expectedResult: CanCallCheck},
 		{name: "ListUsers", expectedResult: CanCallListUsers},
 		{name: "WriteAssertions", expectedResult: CanCallWriteAssertions},
 		{name: "ReadAssertions", expectedResult: CanCallReadAssertions},
 		{name: "WriteAuthorizationModel", expectedResult: CanCallWriteAuthorizationModels},
+		{name: "DiffAuthorizationModels", expectedResult: CanCallWriteAuthorizationModels},
 		{name: "CreateStore", expectedResult: CanCallCreateStore},
 		{name: "GetStore", expectedResult: CanCallGetStore},
 		{name: "DeleteStore", expectedResult: CanCallDeleteStore},
+		{name: "UndeleteStore", expectedResult: CanCallDeleteStore},
+		{name: "UpdateStore", expectedResult: CanCallDeleteStore},
 		{name: "Expand", expectedResult: CanCallExpand},
+		{name: "ExplainCheck", expectedResult: CanCallExpand},
 		{name: "ReadChanges", expectedResult: CanCallReadChanges},
+		{name: "WatchChanges", expectedResult: CanCallReadChanges},
 		{name: "Unknown", errorMsg: ErrUnknownAPIMethod.Error()},
 	}
 
@@ -0,0 +1,204 @@
+// Package changelogpruner contains the background pruning of the changelogs, which bounds their size.
+package changelogpruner
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var deletedChangesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "changelog_changes_deleted_count",
+	Help:      "The total number of changes deleted from the changelogs by the changelog pruner, labeled by reason ('retention' or 'compaction').",
+}, []string{"reason"})
+
+// Retention bounds the changes kept in the changelog of a store. A zero field doesn't bound them.
+type Retention struct {
+	// MaxAge is how long the changes are kept.
+	MaxAge time.Duration
+	// MaxCount is how many of the newest changes are kept.
+	MaxCount int
+}
+
+// IsBounded reports whether the retention deletes any changes.
+func (r Retention) IsBounded() bool {
+	return r.MaxAge > 0 || r.MaxCount > 0
+}
+
+// Config is the configuration of a [Pruner].
+type Config struct {
+	// Retention is the retention of the changelogs of the stores without one of their own.
+	Retention Retention
+	// StoreRetentions are the retentions of specific stores, by store ID, replacing Retention.
+	StoreRetentions map[string]Retention
+	// CompactAfter is the age after which a write of a tuple followed by its delete are both deleted. If 0, the
+	// changelogs aren't compacted.
+	CompactAfter time.Duration
+	// Interval is how often the changelogs are pruned.
+	Interval time.Duration
+	// BatchSize is the maximum number of changes deleted at once.
+	BatchSize int
+}
+
+// IsEnabled reports whether the configuration deletes any changes.
+func (c Config) IsEnabled() bool {
+	if c.Retention.IsBounded() || c.CompactAfter > 0 {
+		return true
+	}
+	for _, retention := range c.StoreRetentions {
+		if retention.IsBounded() {
+			return true
+		}
+	}
+	return false
+}
+
+// Datastore is a datastore whose changelogs can be pruned.
+type Datastore interface {
+	storage.StoresBackend
+	storage.ChangelogPruner
+}
+
+// Pruner periodically deletes, in batches, the changes of the changelogs of all the stores which their retention
+// no longer keeps, and compacts them.
+type Pruner struct {
+	datastore Datastore
+	config    Config
+	logger    logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New starts a Pruner pruning the changelogs every config.Interval. Close must be called to stop it.
+func New(datastore Datastore, config Config, l logger.Logger) *Pruner {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pruner{
+		datastore: datastore,
+		config:    config,
+		logger:    l,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *Pruner) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune()
+		}
+	}
+}
+
+// prune prunes the changelog of every store, then of the stores with a retention of their own which aren't listed
+// anymore.
+func (p *Pruner) prune() {
+	pruned := make(map[string]struct{})
+
+	var continuationToken string
+	for p.ctx.Err() == nil {
+		stores, token, err := p.datastore.ListStores(p.ctx, storage.ListStoresOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken),
+		})
+		if err != nil {
+			if p.ctx.Err() == nil {
+				p.logger.Error("failed to list the stores to prune their changelog", zap.Error(err))
+			}
+			return
+		}
+
+		for _, store := range stores {
+			p.pruneStore(store.GetId())
+			pruned[store.GetId()] = struct{}{}
+		}
+
+		continuationToken = string(token)
+		if continuationToken == "" {
+			break
+		}
+	}
+
+	for store := range p.config.StoreRetentions {
+		if _, ok := pruned[store]; !ok && p.ctx.Err() == nil {
+			p.pruneStore(store)
+		}
+	}
+}
+
+// pruneStore deletes the changes of the store which its retention no longer keeps, then compacts the older ones,
+// batch after batch, until a batch isn't full.
+func (p *Pruner) pruneStore(store string) {
+	now := time.Now()
+
+	retention, ok := p.config.StoreRetentions[store]
+	if !ok {
+		retention = p.config.Retention
+	}
+
+	if retention.IsBounded() {
+		var olderThan time.Time
+		if retention.MaxAge > 0 {
+			olderThan = now.Add(-retention.MaxAge)
+		}
+
+		for p.ctx.Err() == nil {
+			deleted, err := p.datastore.PruneChanges(p.ctx, store, olderThan, retention.MaxCount, p.config.BatchSize)
+			if err != nil {
+				if p.ctx.Err() == nil {
+					p.logger.Error("failed to prune the changelog", zap.String("store_id", store), zap.Error(err))
+				}
+				return
+			}
+
+			deletedChangesCounter.WithLabelValues("retention").Add(float64(deleted))
+			if deleted < p.config.BatchSize {
+				break
+			}
+		}
+	}
+
+	if p.config.CompactAfter > 0 {
+		// a pair is two changes
+		limit := max(p.config.BatchSize/2, 1)
+		for p.ctx.Err() == nil {
+			compacted, err := p.datastore.CompactChanges(p.ctx, store, now.Add(-p.config.CompactAfter), limit)
+			if err != nil {
+				if p.ctx.Err() == nil {
+					p.logger.Error("failed to compact the changelog", zap.String("store_id", store), zap.Error(err))
+				}
+				return
+			}
+
+			deletedChangesCounter.WithLabelValues("compaction").Add(float64(2 * compacted))
+			if compacted < limit {
+				break
+			}
+		}
+	}
+}
+
+// Close stops the Pruner, waiting for the batch being deleted if any.
+func (p *Pruner) Close() {
+	p.cancel()
+	<-p.done
+}
@@ -0,0 +1,84 @@
+package changelogpruner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestPruner(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	countChanges := func(store string) int {
+		changes, _, err := ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		})
+		if err != nil {
+			require.ErrorIs(t, err, storage.ErrNotFound)
+		}
+		return len(changes)
+	}
+
+	stores := make([]string, 3)
+	for i := range stores {
+		stores[i] = ulid.Make().String()
+		_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: stores[i], Name: "store", CreatedAt: timestamppb.Now()})
+		require.NoError(t, err)
+
+		for j := 0; j < 5; j++ {
+			tk := tuple.NewTupleKey(fmt.Sprintf("document:%d", j), "viewer", "user:anne")
+			require.NoError(t, ds.Write(ctx, stores[i], nil, []*openfgav1.TupleKey{tk}))
+		}
+	}
+	// the last write of the first store is compacted with its delete
+	require.NoError(t, ds.Write(ctx, stores[0], []*openfgav1.TupleKeyWithoutCondition{
+		tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:4", "viewer", "user:anne")),
+	}, nil))
+
+	config := Config{
+		Retention: Retention{MaxCount: 3},
+		StoreRetentions: map[string]Retention{
+			stores[1]: {MaxCount: 1},
+			stores[2]: {},
+		},
+		CompactAfter: time.Nanosecond,
+		Interval:     10 * time.Millisecond,
+		BatchSize:    1,
+	}
+	require.True(t, config.IsEnabled())
+
+	pruner := New(ds.(Datastore), config, logger.NewNoopLogger())
+	t.Cleanup(pruner.Close)
+
+	// the changes are all pruned, though a batch only holds one
+	require.Eventually(t, func() bool {
+		return countChanges(stores[0]) == 1 && countChanges(stores[1]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, 5, countChanges(stores[2]))
+}
+
+func TestConfigIsEnabled(t *testing.T) {
+	require.False(t, Config{}.IsEnabled())
+	require.False(t, Config{StoreRetentions: map[string]Retention{"store": {}}}.IsEnabled())
+	require.True(t, Config{StoreRetentions: map[string]Retention{"store": {MaxAge: time.Hour}}}.IsEnabled())
+	require.True(t, Config{CompactAfter: time.Hour}.IsEnabled())
+}
@@ -7,6 +7,8 @@ type CheckResolverOrderedBuilder struct {
 	cachedCheckResolverOptions             []CachedCheckResolverOpt
 	dispatchThrottlingCheckResolverEnabled bool
 	dispatchThrottlingCheckResolverOptions []DispatchThrottlingCheckResolverOpt
+	remoteCheckResolverEnabled             bool
+	remoteCheckResolverOptions             []RemoteCheckResolverOpt
 }
 
 type CheckResolverOrderedBuilderOpt func(checkResolver *CheckResolverOrderedBuilder)
@@ -34,6 +36,14 @@ func WithDispatchThrottlingCheckResolverOpts(enabled bool, opts ...DispatchThrot
 	}
 }
 
+// WithRemoteCheckResolverOpts sets the opts to be used to build RemoteCheckResolver.
+func WithRemoteCheckResolverOpts(enabled bool, opts ...RemoteCheckResolverOpt) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.remoteCheckResolverEnabled = enabled
+		r.remoteCheckResolverOptions = opts
+	}
+}
+
 func NewOrderedCheckResolvers(opts ...CheckResolverOrderedBuilderOpt) *CheckResolverOrderedBuilder {
 	checkResolverBuilder := &CheckResolverOrderedBuilder{}
 	for _, opt := range opts {
@@ -60,6 +70,10 @@ func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverClose
 		c.resolvers = append(c.resolvers, NewDispatchThrottlingCheckResolver(c.dispatchThrottlingCheckResolverOptions...))
 	}
 
+	if c.remoteCheckResolverEnabled {
+		c.resolvers = append(c.resolvers, NewRemoteCheckResolver(c.remoteCheckResolverOptions...))
+	}
+
 	c.resolvers = append(c.resolvers, NewLocalChecker(c.localCheckerOptions...))
 
 	for i, resolver := range c.resolvers {
@@ -12,6 +12,7 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 		name                                   string
 		CachedCheckResolverEnabled             bool
 		DispatchThrottlingCheckResolverEnabled bool
+		RemoteCheckResolverEnabled             bool
 		expectedResolverOrder                  []CheckResolver
 	}
 
@@ -30,11 +31,17 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 			DispatchThrottlingCheckResolverEnabled: true,
 			expectedResolverOrder:                  []CheckResolver{&DispatchThrottlingCheckResolver{}, &LocalChecker{}},
 		},
+		{
+			name:                       "when_remote_dispatch_alone_is_enabled",
+			RemoteCheckResolverEnabled: true,
+			expectedResolverOrder:      []CheckResolver{&RemoteCheckResolver{}, &LocalChecker{}},
+		},
 		{
 			name:                                   "when_all_are_enabled",
 			CachedCheckResolverEnabled:             true,
 			DispatchThrottlingCheckResolverEnabled: true,
-			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &DispatchThrottlingCheckResolver{}, &LocalChecker{}},
+			RemoteCheckResolverEnabled:             true,
+			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &DispatchThrottlingCheckResolver{}, &RemoteCheckResolver{}, &LocalChecker{}},
 		},
 	}
 
@@ -43,6 +50,7 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 			builder := NewOrderedCheckResolvers([]CheckResolverOrderedBuilderOpt{
 				WithCachedCheckResolverOpts(test.CachedCheckResolverEnabled),
 				WithDispatchThrottlingCheckResolverOpts(test.DispatchThrottlingCheckResolverEnabled),
+				WithRemoteCheckResolverOpts(test.RemoteCheckResolverEnabled),
 			}...)
 			_, checkResolverCloser := builder.Build()
 			t.Cleanup(checkResolverCloser)
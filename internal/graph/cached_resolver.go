@@ -145,6 +145,11 @@ type CheckResponseCacheEntry struct {
 	CheckResponse *ResolveCheckResponse
 }
 
+func init() {
+	// the check responses can be stored in a shared cache
+	storage.RegisterJSONCacheEntry[CheckResponseCacheEntry]("check_response")
+}
+
 func (c *CachedCheckResolver) ResolveCheck(
 	ctx context.Context,
 	req *ResolveCheckRequest,
@@ -160,11 +165,17 @@ func (c *CachedCheckResolver) ResolveCheck(
 
 	tryCache := req.Consistency != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
 
+	// the results of a request bound to a revision must be cached after it, and are not cached at all for an exact one
+	revision, hasRevision := storage.RevisionFromContext(ctx)
+	if hasRevision && revision.Mode == storage.RevisionExact {
+		return c.delegate.ResolveCheck(ctx, req)
+	}
+
 	if tryCache {
 		checkCacheTotalCounter.Inc()
 		if cachedResp := c.cache.Get(cacheKey); cachedResp != nil {
 			res := cachedResp.(*CheckResponseCacheEntry)
-			isValid := res.LastModified.After(req.LastCacheInvalidationTime)
+			isValid := res.LastModified.After(req.LastCacheInvalidationTime) && (!hasRevision || revision.IsCacheable(res.LastModified))
 			span.SetAttributes(attribute.Bool("cached", isValid))
 			if isValid {
 				checkCacheHitCounter.Inc()
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/mock/gomock"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/tuple"
 )
 
@@ -642,6 +643,26 @@ func TestCachedCheckResolver_FieldsInResponse(t *testing.T) {
 	require.True(t, resp.GetResolutionMetadata().CycleDetected)
 }
 
+func TestCheckResponseCacheEntrySerialization(t *testing.T) {
+	entry := &CheckResponseCacheEntry{
+		LastModified: time.Now().UTC(),
+		CheckResponse: &ResolveCheckResponse{
+			Allowed: true,
+			ResolutionMetadata: ResolveCheckResponseMetadata{
+				DatastoreQueryCount: 3,
+				CycleDetected:       true,
+			},
+		},
+	}
+
+	data, err := storage.MarshalCacheEntry(entry)
+	require.NoError(t, err)
+
+	value, err := storage.UnmarshalCacheEntry(data)
+	require.NoError(t, err)
+	require.Equal(t, entry, value)
+}
+
 func TestCachedCheckResolver_ResolveCheck_After_Stop_DoesNotPanic(t *testing.T) {
 	cachedCheckResolver := NewCachedCheckResolver(WithExistingCache(nil)) // create cache inside
 
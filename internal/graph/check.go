@@ -411,7 +411,7 @@ func (c *LocalChecker) dispatch(_ context.Context, parentReq *ResolveCheckReques
 		childRequest.TupleKey = tk
 		childRequest.GetRequestMetadata().Depth--
 
-		resp, err := c.delegate.ResolveCheck(ctx, childRequest)
+		resp, err := resolveInflight(ctx, childRequest, c.delegate.ResolveCheck)
 		if err != nil {
 			return nil, err
 		}
@@ -425,7 +425,7 @@ var _ CheckResolver = (*LocalChecker)(nil)
 func (c *LocalChecker) ResolveCheck(
 	ctx context.Context,
 	req *ResolveCheckRequest,
-) (*ResolveCheckResponse, error) {
+) (resp *ResolveCheckResponse, err error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
@@ -437,6 +437,11 @@ func (c *LocalChecker) ResolveCheck(
 	))
 	defer span.End()
 
+	ctx, explanation := startExplanation(ctx, ExplanationKindCheck, req.GetTupleKey())
+	defer func() {
+		explanation.finish(resp, err)
+	}()
+
 	if req.GetRequestMetadata().Depth == 0 {
 		return nil, ErrResolutionDepthExceeded
 	}
@@ -477,7 +482,7 @@ func (c *LocalChecker) ResolveCheck(
 		return nil, fmt.Errorf("relation '%s' undefined for object type '%s'", relation, objectType)
 	}
 
-	resp, err := c.checkRewrite(ctx, req, rel.GetRewrite())(ctx)
+	resp, err = c.checkRewrite(ctx, req, rel.GetRewrite())(ctx)
 	if err != nil {
 		telemetry.TraceError(span, err)
 		return nil, err
@@ -1265,11 +1270,16 @@ func (c *LocalChecker) checkDirectUserTuple(ctx context.Context, req *ResolveChe
 	storeID := req.GetStoreID()
 	reqTupleKey := req.GetTupleKey()
 
-	return func(ctx context.Context) (*ResolveCheckResponse, error) {
+	return func(ctx context.Context) (resp *ResolveCheckResponse, err error) {
 		ctx, span := tracer.Start(ctx, "checkDirectUserTuple",
 			trace.WithAttributes(attribute.String("tuple_key", tuple.TupleKeyWithConditionToString(reqTupleKey))))
 		defer span.End()
 
+		ctx, explanation := startExplanation(ctx, ExplanationKindDirectUserTuple, reqTupleKey)
+		defer func() {
+			explanation.finish(resp, err)
+		}()
+
 		response := &ResolveCheckResponse{
 			Allowed: false,
 		}
@@ -1295,7 +1305,7 @@ func (c *LocalChecker) checkDirectUserTuple(ctx context.Context, req *ResolveChe
 		if err != nil {
 			return response, nil
 		}
-		tupleKeyConditionFilter := checkutil.BuildTupleKeyConditionFilter(ctx, req.Context, typesys)
+		tupleKeyConditionFilter := explanation.conditionFilter(checkutil.BuildTupleKeyConditionFilter(ctx, req.Context, typesys))
 		conditionMet, err := tupleKeyConditionFilter(tupleKey)
 		if err != nil {
 			telemetry.TraceError(span, err)
@@ -1314,7 +1324,7 @@ func (c *LocalChecker) checkDirectUserTuple(ctx context.Context, req *ResolveChe
 // while the second handler looks up relationships between the target 'object#relation' and any usersets
 // related to it.
 func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckRequest) CheckHandlerFunc {
-	return func(ctx context.Context) (*ResolveCheckResponse, error) {
+	return func(ctx context.Context) (resp *ResolveCheckResponse, err error) {
 		ctx, span := tracer.Start(ctx, "checkDirect")
 		defer span.End()
 
@@ -1322,6 +1332,11 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 			return nil, ctx.Err()
 		}
 
+		ctx, explanation := startExplanation(ctx, ExplanationKindDirect, req.GetTupleKey())
+		defer func() {
+			explanation.finish(resp, err)
+		}()
+
 		typesys, _ := typesystem.TypesystemFromContext(parentctx) // note: use of 'parentctx' not 'ctx' - this is important
 
 		ds, _ := storage.RelationshipTupleReaderFromContext(parentctx)
@@ -1337,7 +1352,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 		checkDirectUserTuple := c.checkDirectUserTuple(parentctx, req)
 
 		// TODO(jpadilla): can we lift this function up?
-		checkDirectUsersetTuples := func(ctx context.Context) (*ResolveCheckResponse, error) {
+		checkDirectUsersetTuples := func(ctx context.Context) (resp *ResolveCheckResponse, err error) {
 			ctx, span := tracer.Start(ctx, "checkDirectUsersetTuples", trace.WithAttributes(attribute.String("userset", tuple.ToObjectRelationString(reqTupleKey.GetObject(), reqTupleKey.GetRelation()))))
 			defer span.End()
 
@@ -1345,6 +1360,11 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				return nil, ctx.Err()
 			}
 
+			ctx, explanation := startExplanation(ctx, ExplanationKindDirectUsersetTuples, reqTupleKey)
+			defer func() {
+				explanation.finish(resp, err)
+			}()
+
 			opts := storage.ReadUsersetTuplesOptions{
 				Consistency: storage.ConsistencyOptions{
 					Preference: req.GetConsistency(),
@@ -1352,16 +1372,20 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 			}
 
 			resolver := c.checkUsersetSlowPath
+			strategy := explanationStrategySlowPath
 
 			if !tuple.IsObjectRelation(reqTupleKey.GetUser()) {
 				if typesys.UsersetCanFastPath(directlyRelatedUsersetTypes) {
 					resolver = c.checkUsersetFastPath
+					strategy = explanationStrategyFastPath
 				} else if c.optimizationsEnabled && typesys.RecursiveUsersetCanFastPath(
 					tuple.ToObjectRelationString(tuple.GetType(reqTupleKey.GetObject()), reqTupleKey.GetRelation()),
 					tuple.GetType(reqTupleKey.GetUser())) {
 					resolver = c.nestedUsersetFastPath
+					strategy = explanationStrategyNestedFastPath
 				}
 			}
+			explanation.setStrategy(strategy)
 
 			iter, err := ds.ReadUsersetTuples(ctx, storeID, storage.ReadUsersetTuplesFilter{
 				Object:                      reqTupleKey.GetObject(),
@@ -1377,7 +1401,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 					storage.NewTupleKeyIteratorFromTupleIterator(iter),
 					validation.FilterInvalidTuples(typesys),
 				),
-				checkutil.BuildTupleKeyConditionFilter(ctx, req.GetContext(), typesys),
+				explanation.conditionFilter(checkutil.BuildTupleKeyConditionFilter(ctx, req.GetContext(), typesys)),
 			)
 			defer filteredIter.Stop()
 
@@ -1399,7 +1423,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 			checkFuncs = append(checkFuncs, checkDirectUsersetTuples)
 		}
 
-		resp, err := union(ctx, c.concurrencyLimit, checkFuncs...)
+		resp, err = union(ctx, c.concurrencyLimit, checkFuncs...)
 		if err != nil {
 			telemetry.TraceError(span, err)
 			return nil, err
@@ -1420,9 +1444,16 @@ func (c *LocalChecker) checkComputedUserset(_ context.Context, req *ResolveCheck
 	childRequest := req.clone()
 	childRequest.TupleKey = rewrittenTupleKey
 
-	return func(ctx context.Context) (*ResolveCheckResponse, error) {
+	return func(ctx context.Context) (resp *ResolveCheckResponse, err error) {
 		ctx, span := tracer.Start(ctx, "checkComputedUserset")
 		defer span.End()
+
+		ctx, explanation := startExplanation(ctx, ExplanationKindComputedUserset, req.GetTupleKey())
+		explanation.setRewrite(rewrite.GetComputedUserset().GetRelation())
+		defer func() {
+			explanation.finish(resp, err)
+		}()
+
 		// No dispatch here, as we don't want to increase resolution depth.
 		return c.ResolveCheck(ctx, childRequest)
 	}
@@ -1520,7 +1551,7 @@ func (c *LocalChecker) checkTTUFastPath(ctx context.Context, req *ResolveCheckRe
 // checkTTU looks up all tuples of the target tupleset relation on the provided object and for each one
 // of them evaluates the computed userset of the TTU rewrite rule for them.
 func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequest, rewrite *openfgav1.Userset) CheckHandlerFunc {
-	return func(ctx context.Context) (*ResolveCheckResponse, error) {
+	return func(ctx context.Context) (resp *ResolveCheckResponse, err error) {
 		ctx, span := tracer.Start(ctx, "checkTTU")
 		defer span.End()
 
@@ -1528,6 +1559,11 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 			return nil, ctx.Err()
 		}
 
+		ctx, explanation := startExplanation(ctx, ExplanationKindTupleToUserset, req.GetTupleKey())
+		defer func() {
+			explanation.finish(resp, err)
+		}()
+
 		typesys, _ := typesystem.TypesystemFromContext(parentctx) // note: use of 'parentctx' not 'ctx' - this is important
 
 		ds, _ := storage.RelationshipTupleReaderFromContext(parentctx)
@@ -1550,6 +1586,7 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 			attribute.String("tupleset_relation", fmt.Sprintf("%s#%s", tuple.GetType(object), tuplesetRelation)),
 			attribute.String("computed_relation", computedRelation),
 		)
+		explanation.setRewrite(fmt.Sprintf("%s from %s", computedRelation, tuplesetRelation))
 
 		opts := storage.ReadOptions{
 			Consistency: storage.ConsistencyOptions{
@@ -1574,11 +1611,12 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 				storage.NewTupleKeyIteratorFromTupleIterator(iter),
 				validation.FilterInvalidTuples(typesys),
 			),
-			checkutil.BuildTupleKeyConditionFilter(ctx, req.GetContext(), typesys),
+			explanation.conditionFilter(checkutil.BuildTupleKeyConditionFilter(ctx, req.GetContext(), typesys)),
 		)
 		defer filteredIter.Stop()
 
 		resolver := c.checkTTUSlowPath
+		strategy := explanationStrategySlowPath
 
 		// TODO: optimize the case where user is an userset.
 		// If the user is a userset, we will not be able to use the shortcut because the algo
@@ -1587,11 +1625,15 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 			if canFastPath := typesys.TTUCanFastPath(
 				tuple.GetType(object), tuplesetRelation, computedRelation); canFastPath {
 				resolver = c.checkTTUFastPath
+				strategy = explanationStrategyFastPath
 			}
 		}
 		if c.optimizationsEnabled && typesys.RecursiveTTUCanFastPath(objectTypeRelation, userType) {
 			resolver = c.nestedTTUFastPath
+			strategy = explanationStrategyNestedFastPath
 		}
+		explanation.setStrategy(strategy)
+
 		return resolver(ctx, req, rewrite, filteredIter)
 	}
 }
@@ -1606,18 +1648,22 @@ func (c *LocalChecker) checkSetOperation(
 	var handlers []CheckHandlerFunc
 
 	var reducerKey string
+	var explanationKind ExplanationKind
 	switch setOpType {
 	case unionSetOperator, intersectionSetOperator, exclusionSetOperator:
 		if setOpType == unionSetOperator {
 			reducerKey = "union"
+			explanationKind = ExplanationKindUnion
 		}
 
 		if setOpType == intersectionSetOperator {
 			reducerKey = "intersection"
+			explanationKind = ExplanationKindIntersection
 		}
 
 		if setOpType == exclusionSetOperator {
 			reducerKey = "exclusion"
+			explanationKind = ExplanationKindExclusion
 		}
 
 		for _, child := range children {
@@ -1633,10 +1679,13 @@ func (c *LocalChecker) checkSetOperation(
 		var err error
 		var resp *ResolveCheckResponse
 		ctx, span := tracer.Start(ctx, reducerKey)
+		ctx, explanation := startExplanation(ctx, explanationKind, req.GetTupleKey())
+		explanation.setExpectedChildren(len(handlers))
 		defer func() {
 			if err != nil {
 				telemetry.TraceError(span, err)
 			}
+			explanation.finish(resp, err)
 			span.End()
 		}()
 
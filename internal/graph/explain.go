@@ -0,0 +1,360 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	DefaultMaxExplanationNodes         = 200
+	DefaultMaxExplanationTuplesPerNode = 20
+
+	// MaxExplanationNodes is the upper bound of nodes an explanation can ever hold, regardless of the
+	// limits requested by callers.
+	MaxExplanationNodes = 2000
+	// MaxExplanationTuplesPerNode is the upper bound of tuples and conditions recorded for a single node.
+	MaxExplanationTuplesPerNode = 200
+)
+
+// ExplanationKind identifies the step of the Check resolution that a node of a CheckExplanation describes.
+type ExplanationKind string
+
+const (
+	// ExplanationKindCheck is the resolution of a single 'object#relation@user' (e.g. the request itself or a dispatch).
+	ExplanationKindCheck ExplanationKind = "check"
+	// ExplanationKindDirect is a direct relationship rewrite, e.g. 'define viewer: [user, group#member]'.
+	ExplanationKindDirect ExplanationKind = "direct"
+	// ExplanationKindDirectUserTuple is the lookup of the exact 'object#relation@user' tuple.
+	ExplanationKindDirectUserTuple ExplanationKind = "direct_user_tuple"
+	// ExplanationKindDirectUsersetTuples is the lookup of usersets and wildcards assigned to 'object#relation'.
+	ExplanationKindDirectUsersetTuples ExplanationKind = "direct_userset_tuples"
+	// ExplanationKindComputedUserset is a computed userset rewrite, e.g. 'define viewer: editor'.
+	ExplanationKindComputedUserset ExplanationKind = "computed_userset"
+	// ExplanationKindTupleToUserset is a tuple to userset rewrite, e.g. 'define viewer: viewer from parent'.
+	ExplanationKindTupleToUserset ExplanationKind = "tuple_to_userset"
+	ExplanationKindUnion          ExplanationKind = "union"
+	ExplanationKindIntersection   ExplanationKind = "intersection"
+	ExplanationKindExclusion      ExplanationKind = "exclusion"
+)
+
+// ExplanationOutcome is the result of a node of a CheckExplanation.
+type ExplanationOutcome string
+
+const (
+	ExplanationOutcomeAllowed       ExplanationOutcome = "allowed"
+	ExplanationOutcomeDenied        ExplanationOutcome = "denied"
+	ExplanationOutcomeCycleDetected ExplanationOutcome = "cycle_detected"
+	ExplanationOutcomeError         ExplanationOutcome = "error"
+	// ExplanationOutcomeCancelled means the node was abandoned before it resolved, typically because a
+	// sibling already decided the outcome of the parent.
+	ExplanationOutcomeCancelled ExplanationOutcome = "cancelled"
+)
+
+// Resolution strategies of the userset and tuple to userset lookups, see LocalChecker.
+const (
+	explanationStrategySlowPath       = "slow_path"
+	explanationStrategyFastPath       = "fast_path"
+	explanationStrategyNestedFastPath = "nested_fast_path"
+)
+
+// ConditionEvaluation records the evaluation of the condition of a tuple.
+type ConditionEvaluation struct {
+	Tuple     string `json:"tuple"`
+	Condition string `json:"condition"`
+	Met       bool   `json:"met"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckExplanation is a node of the tree walked by the LocalChecker to resolve a Check.
+type CheckExplanation struct {
+	Kind ExplanationKind `json:"kind"`
+	// TupleKey is the 'object#relation@user' being resolved by this node.
+	TupleKey string `json:"tuple_key"`
+	// Rewrite describes the rewrite rule of computed usersets and tuple to usersets.
+	Rewrite string `json:"rewrite,omitempty"`
+	// Strategy is the algorithm used to resolve userset and tuple to userset lookups. The fast paths
+	// resolve the lookup without dispatching, so their nodes have no children.
+	Strategy string             `json:"strategy,omitempty"`
+	Outcome  ExplanationOutcome `json:"outcome"`
+	Error    string             `json:"error,omitempty"`
+	// ShortCircuited is true if the node was resolved before all of its operands were, because the
+	// outcome of the evaluated ones was already conclusive.
+	ShortCircuited bool `json:"short_circuited,omitempty"`
+	// MatchedTuples are the tuples read by this node that were valid and whose conditions, if any, were met.
+	MatchedTuples []string               `json:"matched_tuples,omitempty"`
+	Conditions    []*ConditionEvaluation `json:"conditions,omitempty"`
+	Children      []*CheckExplanation    `json:"children,omitempty"`
+	// Truncated is true if tuples, conditions or children of this node were not recorded because the
+	// explanation reached its size limits.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CheckExplainer records the resolution tree of the Checks resolved with a context built by
+// ContextWithCheckExplainer. Only the LocalChecker records nodes, so the CheckResolver used must not
+// serve subproblems from a cache or from another process if a complete tree is wanted.
+type CheckExplainer struct {
+	mu               sync.Mutex
+	maxNodes         int
+	maxTuplesPerNode int
+	nodes            int
+	root             *explanationNode
+}
+
+type CheckExplainerOption func(*CheckExplainer)
+
+// WithMaxExplanationNodes sets the maximum number of nodes recorded, capped to MaxExplanationNodes.
+// A value of zero keeps the default.
+func WithMaxExplanationNodes(n uint32) CheckExplainerOption {
+	return func(e *CheckExplainer) {
+		if n > 0 {
+			e.maxNodes = min(int(n), MaxExplanationNodes)
+		}
+	}
+}
+
+// WithMaxExplanationTuplesPerNode sets the maximum number of tuples and of conditions recorded per node,
+// capped to MaxExplanationTuplesPerNode. A value of zero keeps the default.
+func WithMaxExplanationTuplesPerNode(n uint32) CheckExplainerOption {
+	return func(e *CheckExplainer) {
+		if n > 0 {
+			e.maxTuplesPerNode = min(int(n), MaxExplanationTuplesPerNode)
+		}
+	}
+}
+
+func NewCheckExplainer(opts ...CheckExplainerOption) *CheckExplainer {
+	e := &CheckExplainer{
+		maxNodes:         DefaultMaxExplanationNodes,
+		maxTuplesPerNode: DefaultMaxExplanationTuplesPerNode,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type checkExplainerCtxKey struct{}
+
+type explanationNodeCtxKey struct{}
+
+// ContextWithCheckExplainer returns a context in which the Check resolution is recorded by the explainer.
+func ContextWithCheckExplainer(ctx context.Context, explainer *CheckExplainer) context.Context {
+	return context.WithValue(ctx, checkExplainerCtxKey{}, explainer)
+}
+
+// Explanation returns a snapshot of the recorded tree, or nil if nothing was recorded. Nodes that had not
+// resolved when the snapshot is taken are reported as cancelled.
+func (e *CheckExplainer) Explanation() *CheckExplanation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.root == nil {
+		return nil
+	}
+	return e.root.snapshot()
+}
+
+// explanationNode is a node of the tree being recorded. Nodes are written concurrently by the goroutines
+// resolving the Check, so every access is guarded by the mutex of the explainer.
+type explanationNode struct {
+	explainer   *CheckExplainer
+	explanation CheckExplanation
+	children    []*explanationNode
+	resolved    bool
+	// expectedChildren is the number of operands of set operations, used to tell if some were skipped.
+	expectedChildren int
+	// truncatedSubtree is set on the placeholder node handed out once the explainer is full.
+	truncatedSubtree bool
+}
+
+// startExplanation records a new node under the node of the context, and returns a context in which
+// the nodes started are recorded as its children. It returns a nil node, on which every method is a noop,
+// if the Check is not being explained or if the size limits were reached.
+func startExplanation(ctx context.Context, kind ExplanationKind, tk *openfgav1.TupleKey) (context.Context, *explanationNode) {
+	explainer, ok := ctx.Value(checkExplainerCtxKey{}).(*CheckExplainer)
+	if !ok {
+		return ctx, nil
+	}
+
+	parent, _ := ctx.Value(explanationNodeCtxKey{}).(*explanationNode)
+	if parent != nil && parent.truncatedSubtree {
+		return ctx, nil
+	}
+
+	explainer.mu.Lock()
+	defer explainer.mu.Unlock()
+
+	if parent == nil && explainer.root != nil {
+		// only the first resolution is explained, the explainer must not be shared across requests.
+		return ctx, nil
+	}
+
+	if explainer.nodes >= explainer.maxNodes {
+		if parent != nil {
+			parent.explanation.Truncated = true
+		}
+		return context.WithValue(ctx, explanationNodeCtxKey{}, &explanationNode{truncatedSubtree: true}), nil
+	}
+	explainer.nodes++
+
+	node := &explanationNode{
+		explainer: explainer,
+		explanation: CheckExplanation{
+			Kind:     kind,
+			TupleKey: tuple.TupleKeyToString(tk),
+		},
+	}
+	if parent == nil {
+		explainer.root = node
+	} else {
+		parent.children = append(parent.children, node)
+	}
+
+	return context.WithValue(ctx, explanationNodeCtxKey{}, node), node
+}
+
+func (n *explanationNode) setRewrite(rewrite string) {
+	if n == nil {
+		return
+	}
+	n.explainer.mu.Lock()
+	defer n.explainer.mu.Unlock()
+	n.explanation.Rewrite = rewrite
+}
+
+func (n *explanationNode) setStrategy(strategy string) {
+	if n == nil {
+		return
+	}
+	n.explainer.mu.Lock()
+	defer n.explainer.mu.Unlock()
+	n.explanation.Strategy = strategy
+}
+
+func (n *explanationNode) setExpectedChildren(count int) {
+	if n == nil {
+		return
+	}
+	n.explainer.mu.Lock()
+	defer n.explainer.mu.Unlock()
+	n.expectedChildren = count
+}
+
+// recordTuple records a tuple read by the node, along with the evaluation of its condition if it has one.
+func (n *explanationNode) recordTuple(tk *openfgav1.TupleKey, conditionMet bool, conditionErr error) {
+	if n == nil {
+		return
+	}
+	n.explainer.mu.Lock()
+	defer n.explainer.mu.Unlock()
+
+	limit := n.explainer.maxTuplesPerNode
+
+	if conditionName := tk.GetCondition().GetName(); conditionName != "" {
+		if len(n.explanation.Conditions) >= limit {
+			n.explanation.Truncated = true
+		} else {
+			evaluation := &ConditionEvaluation{
+				Tuple:     tuple.TupleKeyToString(tk),
+				Condition: conditionName,
+				Met:       conditionMet,
+			}
+			if conditionErr != nil {
+				evaluation.Error = conditionErr.Error()
+			}
+			n.explanation.Conditions = append(n.explanation.Conditions, evaluation)
+		}
+	}
+
+	if !conditionMet || conditionErr != nil {
+		return
+	}
+
+	if len(n.explanation.MatchedTuples) >= limit {
+		n.explanation.Truncated = true
+		return
+	}
+	n.explanation.MatchedTuples = append(n.explanation.MatchedTuples, tuple.TupleKeyWithConditionToString(tk))
+}
+
+// conditionFilter wraps a condition filter so that every tuple it is applied to is recorded in the node.
+func (n *explanationNode) conditionFilter(filter storage.TupleKeyConditionFilterFunc) storage.TupleKeyConditionFilterFunc {
+	if n == nil {
+		return filter
+	}
+
+	return func(tk *openfgav1.TupleKey) (bool, error) {
+		met, err := filter(tk)
+		n.recordTuple(tk, met, err)
+		return met, err
+	}
+}
+
+// finish records the outcome of the node.
+func (n *explanationNode) finish(resp *ResolveCheckResponse, err error) {
+	if n == nil {
+		return
+	}
+	n.explainer.mu.Lock()
+	defer n.explainer.mu.Unlock()
+
+	if n.resolved {
+		return
+	}
+	n.resolved = true
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		n.explanation.Outcome = ExplanationOutcomeCancelled
+	case err != nil:
+		n.explanation.Outcome = ExplanationOutcomeError
+		n.explanation.Error = err.Error()
+	case resp.GetCycleDetected():
+		n.explanation.Outcome = ExplanationOutcomeCycleDetected
+	case resp.GetAllowed():
+		n.explanation.Outcome = ExplanationOutcomeAllowed
+	default:
+		n.explanation.Outcome = ExplanationOutcomeDenied
+	}
+}
+
+// snapshot deep copies the subtree rooted at the node. The caller must hold the mutex of the explainer.
+func (n *explanationNode) snapshot() *CheckExplanation {
+	explanation := n.explanation
+	explanation.MatchedTuples = append([]string(nil), n.explanation.MatchedTuples...)
+	explanation.Conditions = make([]*ConditionEvaluation, 0, len(n.explanation.Conditions))
+	for _, condition := range n.explanation.Conditions {
+		c := *condition
+		explanation.Conditions = append(explanation.Conditions, &c)
+	}
+
+	if !n.resolved {
+		explanation.Outcome = ExplanationOutcomeCancelled
+	}
+
+	explanation.Children = make([]*CheckExplanation, 0, len(n.children))
+	for _, child := range n.children {
+		childExplanation := child.snapshot()
+		if childExplanation.Outcome == ExplanationOutcomeCancelled {
+			explanation.ShortCircuited = true
+		}
+		explanation.Children = append(explanation.Children, childExplanation)
+	}
+	if len(n.children) < n.expectedChildren && !explanation.Truncated {
+		explanation.ShortCircuited = true
+	}
+
+	if explanation.Outcome == ExplanationOutcomeCancelled || explanation.Outcome == ExplanationOutcomeError {
+		// an abandoned node did not decide anything, so it did not short-circuit either.
+		explanation.ShortCircuited = false
+	}
+
+	return &explanation
+}
@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func findExplanation(root *CheckExplanation, kind ExplanationKind, tupleKey string) *CheckExplanation {
+	if root == nil {
+		return nil
+	}
+	if root.Kind == kind && root.TupleKey == tupleKey {
+		return root
+	}
+	for _, child := range root.Children {
+		if found := findExplanation(child, kind, tupleKey); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestCheckExplanation(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define viewer: [user]
+
+		type doc
+			relations
+				define parent: [folder]
+				define owner: [user with is_ok]
+				define editor: [user] or owner
+				define viewer: editor or viewer from parent
+				define blocked: [user]
+				define can_view: viewer but not blocked
+
+		condition is_ok(param: string) {
+			param == "ok"
+		}`)
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("doc:1", "parent", "folder:1"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+		tuple.NewTupleKeyWithCondition("doc:1", "owner", "user:bob", "is_ok", nil),
+		tuple.NewTupleKey("doc:1", "blocked", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	checker := NewLocalChecker()
+	t.Cleanup(checker.Close)
+
+	explain := func(t *testing.T, tk *openfgav1.TupleKey, reqCtx map[string]interface{}, opts ...CheckExplainerOption) (*ResolveCheckResponse, *CheckExplanation) {
+		conditionContext, err := structpb.NewStruct(reqCtx)
+		require.NoError(t, err)
+
+		explainer := NewCheckExplainer(opts...)
+		ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+		ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+		ctx = ContextWithCheckExplainer(ctx, explainer)
+
+		resp, err := checker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: model.GetId(),
+			TupleKey:             tk,
+			RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+			Context:              conditionContext,
+		})
+		require.NoError(t, err)
+
+		return resp, explainer.Explanation()
+	}
+
+	t.Run("records_tuple_to_userset", func(t *testing.T) {
+		resp, explanation := explain(t, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), nil)
+		require.True(t, resp.GetAllowed())
+
+		require.Equal(t, ExplanationKindCheck, explanation.Kind)
+		require.Equal(t, "doc:1#viewer@user:anne", explanation.TupleKey)
+		require.Equal(t, ExplanationOutcomeAllowed, explanation.Outcome)
+
+		ttu := findExplanation(explanation, ExplanationKindTupleToUserset, "doc:1#viewer@user:anne")
+		require.NotNil(t, ttu)
+		require.Equal(t, "viewer from parent", ttu.Rewrite)
+		require.Equal(t, ExplanationOutcomeAllowed, ttu.Outcome)
+		require.Equal(t, []string{"doc:1#parent@folder:1"}, ttu.MatchedTuples)
+		require.NotEmpty(t, ttu.Strategy)
+	})
+
+	t.Run("records_conditions", func(t *testing.T) {
+		resp, explanation := explain(t, tuple.NewTupleKey("doc:1", "editor", "user:bob"), map[string]interface{}{"param": "ok"})
+		require.True(t, resp.GetAllowed())
+
+		computed := findExplanation(explanation, ExplanationKindComputedUserset, "doc:1#editor@user:bob")
+		require.NotNil(t, computed)
+		require.Equal(t, "owner", computed.Rewrite)
+
+		directTuple := findExplanation(explanation, ExplanationKindDirectUserTuple, "doc:1#owner@user:bob")
+		require.NotNil(t, directTuple)
+		require.Equal(t, ExplanationOutcomeAllowed, directTuple.Outcome)
+		require.Equal(t, []string{"doc:1#owner@user:bob (condition is_ok)"}, directTuple.MatchedTuples)
+		require.Equal(t, []*ConditionEvaluation{
+			{Tuple: "doc:1#owner@user:bob", Condition: "is_ok", Met: true},
+		}, directTuple.Conditions)
+
+		resp, explanation = explain(t, tuple.NewTupleKey("doc:1", "editor", "user:bob"), map[string]interface{}{"param": "not ok"})
+		require.False(t, resp.GetAllowed())
+		require.Equal(t, ExplanationOutcomeDenied, explanation.Outcome)
+
+		directTuple = findExplanation(explanation, ExplanationKindDirectUserTuple, "doc:1#owner@user:bob")
+		require.NotNil(t, directTuple)
+		require.Empty(t, directTuple.MatchedTuples)
+		require.Equal(t, []*ConditionEvaluation{
+			{Tuple: "doc:1#owner@user:bob", Condition: "is_ok", Met: false},
+		}, directTuple.Conditions)
+	})
+
+	t.Run("records_set_operations", func(t *testing.T) {
+		resp, explanation := explain(t, tuple.NewTupleKey("doc:1", "can_view", "user:anne"), nil)
+		require.False(t, resp.GetAllowed())
+
+		exclusion := findExplanation(explanation, ExplanationKindExclusion, "doc:1#can_view@user:anne")
+		require.NotNil(t, exclusion)
+		require.Equal(t, ExplanationOutcomeDenied, exclusion.Outcome)
+
+		blocked := findExplanation(exclusion, ExplanationKindDirectUserTuple, "doc:1#blocked@user:anne")
+		require.NotNil(t, blocked)
+		require.Equal(t, ExplanationOutcomeAllowed, blocked.Outcome)
+		require.Equal(t, []string{"doc:1#blocked@user:anne"}, blocked.MatchedTuples)
+	})
+
+	t.Run("respects_size_limits", func(t *testing.T) {
+		resp, explanation := explain(t, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), nil, WithMaxExplanationNodes(2))
+		require.True(t, resp.GetAllowed())
+
+		require.Equal(t, ExplanationOutcomeAllowed, explanation.Outcome)
+		require.Len(t, explanation.Children, 1)
+		require.Empty(t, explanation.Children[0].Children)
+		require.True(t, explanation.Children[0].Truncated)
+	})
+
+	t.Run("noop_without_explainer", func(t *testing.T) {
+		ctx, node := startExplanation(context.Background(), ExplanationKindCheck, tuple.NewTupleKey("doc:1", "viewer", "user:anne"))
+		require.Nil(t, node)
+		require.Nil(t, ctx.Value(explanationNodeCtxKey{}))
+
+		// every method of a nil node is a noop
+		node.setRewrite("viewer")
+		node.recordTuple(tuple.NewTupleKey("doc:1", "viewer", "user:anne"), true, nil)
+		node.finish(&ResolveCheckResponse{Allowed: true}, nil)
+	})
+}
+
+func TestCheckExplanationShortCircuit(t *testing.T) {
+	explainer := NewCheckExplainer()
+	ctx := ContextWithCheckExplainer(context.Background(), explainer)
+	tk := tuple.NewTupleKey("doc:1", "viewer", "user:anne")
+
+	ctx, union := startExplanation(ctx, ExplanationKindUnion, tk)
+	union.setExpectedChildren(3)
+
+	_, first := startExplanation(ctx, ExplanationKindDirect, tk)
+	_, second := startExplanation(ctx, ExplanationKindComputedUserset, tk)
+
+	first.finish(&ResolveCheckResponse{Allowed: true}, nil)
+	union.finish(&ResolveCheckResponse{Allowed: true}, nil)
+	second.finish(nil, context.Canceled)
+
+	explanation := explainer.Explanation()
+	require.True(t, explanation.ShortCircuited)
+	require.Len(t, explanation.Children, 2)
+	require.Equal(t, ExplanationOutcomeAllowed, explanation.Children[0].Outcome)
+	require.Equal(t, ExplanationOutcomeCancelled, explanation.Children[1].Outcome)
+	require.False(t, explanation.Children[1].ShortCircuited)
+}
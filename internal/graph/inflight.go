@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/exp/maps"
+	"golang.org/x/sync/singleflight"
+)
+
+type inflightChecksCtxKey struct{}
+
+// ContextWithInflightCheckDeduplication returns a context in which identical Check subproblems that are
+// dispatched concurrently are resolved only once, with the result shared amongst every caller waiting on it.
+// The deduplication is scoped to the lifetime of the returned context, so it is meant to wrap a single request
+// that is expected to resolve overlapping subproblems (e.g. BatchCheck).
+func ContextWithInflightCheckDeduplication(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inflightChecksCtxKey{}, &singleflight.Group{})
+}
+
+// resolveInflight resolves req with the provided resolve function, deduplicating it against identical requests
+// that are in flight if the context was built with ContextWithInflightCheckDeduplication.
+//
+// Two requests are only considered identical if, on top of the cache key, they have visited the same set of
+// paths. Since the visited paths of a subproblem strictly grow as it is dispatched, this guarantees that a
+// request never waits on an ancestor of itself, which would otherwise deadlock in the presence of cycles.
+func resolveInflight(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+	resolve func(context.Context, *ResolveCheckRequest) (*ResolveCheckResponse, error),
+) (*ResolveCheckResponse, error) {
+	group, ok := ctx.Value(inflightChecksCtxKey{}).(*singleflight.Group)
+	if !ok {
+		return resolve(ctx, req)
+	}
+
+	key, err := inflightCheckKey(req)
+	if err != nil {
+		return resolve(ctx, req)
+	}
+
+	res, err, shared := group.Do(key, func() (interface{}, error) {
+		return resolve(ctx, req)
+	})
+	if err != nil {
+		// the resolution we piggybacked on may have been cancelled by its own caller (e.g. because
+		// a sibling branch short-circuited). That outcome is not ours, so resolve it ourselves.
+		if shared && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return resolve(ctx, req)
+		}
+		return nil, err
+	}
+
+	resp := res.(*ResolveCheckResponse)
+	if shared {
+		// return a copy to avoid races across goroutines
+		return resp.clone(), nil
+	}
+
+	return resp, nil
+}
+
+func inflightCheckKey(req *ResolveCheckRequest) (string, error) {
+	cacheKey, err := CheckRequestCacheKey(req)
+	if err != nil {
+		return "", err
+	}
+
+	visited := maps.Keys(req.GetVisitedPaths())
+	slices.Sort(visited)
+
+	hasher := xxhash.New()
+	for _, path := range visited {
+		if _, err := hasher.WriteString(path + ";"); err != nil {
+			return "", err
+		}
+	}
+
+	return cacheKey + "/" + strconv.FormatUint(hasher.Sum64(), 10), nil
+}
@@ -0,0 +1,483 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	defaultPeerRefreshInterval  = 30 * time.Second
+	defaultPeerUnhealthyBackoff = 5 * time.Second
+	defaultRingVirtualNodes     = 128
+)
+
+var remoteCheckDispatchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "remote_check_dispatch_count",
+	Help:      "The total number of Check subproblems routed by the RemoteCheckResolver, by where they were resolved.",
+}, []string{"outcome"})
+
+const (
+	remoteDispatchOutcomeLocal    = "local"
+	remoteDispatchOutcomeRemote   = "remote"
+	remoteDispatchOutcomeFallback = "fallback"
+)
+
+// PeerDiscoverer returns the dispatch addresses of the nodes of the cluster.
+type PeerDiscoverer interface {
+	Peers(ctx context.Context) ([]string, error)
+}
+
+// StaticPeers is a PeerDiscoverer of a fixed list of addresses.
+type StaticPeers []string
+
+var _ PeerDiscoverer = StaticPeers(nil)
+
+func (p StaticPeers) Peers(context.Context) ([]string, error) {
+	return p, nil
+}
+
+// DNSPeerDiscoverer discovers the peers by resolving a DNS name, e.g. that of a Kubernetes headless service,
+// to one address per node, all of them listening on the same port.
+type DNSPeerDiscoverer struct {
+	name     string
+	port     string
+	resolver *net.Resolver
+}
+
+var _ PeerDiscoverer = (*DNSPeerDiscoverer)(nil)
+
+func NewDNSPeerDiscoverer(name, port string) *DNSPeerDiscoverer {
+	return &DNSPeerDiscoverer{
+		name:     name,
+		port:     port,
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (d *DNSPeerDiscoverer) Peers(ctx context.Context) ([]string, error) {
+	hosts, err := d.resolver.LookupHost(ctx, d.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve peers of '%s': %w", d.name, err)
+	}
+
+	peers := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		peers = append(peers, net.JoinHostPort(host, d.port))
+	}
+	return peers, nil
+}
+
+// hashRing assigns keys to members with consistent hashing, so that adding or removing a member only moves
+// the keys of that member. Each member is placed several times on the ring to spread the keys evenly.
+type hashRing struct {
+	hashes  []uint64
+	members []string
+}
+
+func newHashRing(members []string, virtualNodes int) *hashRing {
+	type point struct {
+		hash   uint64
+		member string
+	}
+
+	points := make([]point, 0, len(members)*virtualNodes)
+	for _, member := range members {
+		for i := 0; i < virtualNodes; i++ {
+			points = append(points, point{
+				hash:   xxhash.Sum64String(member + "#" + strconv.Itoa(i)),
+				member: member,
+			})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash == points[j].hash {
+			// keep the ring the same on every node even in the unlikely case of a collision
+			return points[i].member < points[j].member
+		}
+		return points[i].hash < points[j].hash
+	})
+
+	ring := &hashRing{
+		hashes:  make([]uint64, len(points)),
+		members: make([]string, len(points)),
+	}
+	for i, p := range points {
+		ring.hashes[i] = p.hash
+		ring.members[i] = p.member
+	}
+	return ring
+}
+
+// owner returns the member that owns the key, or "" if the ring is empty.
+func (r *hashRing) owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	hash := xxhash.Sum64String(key)
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= hash
+	})
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.members[i]
+}
+
+type remotePeer struct {
+	address string
+	conn    *grpc.ClientConn
+	client  CheckDispatchServiceClient
+	// unhealthyUntil is the time, in Unix nanoseconds, until which the peer is not dispatched to.
+	unhealthyUntil atomic.Int64
+}
+
+func (p *remotePeer) healthy() bool {
+	if time.Now().UnixNano() < p.unhealthyUntil.Load() {
+		return false
+	}
+	return p.conn.GetState() != connectivity.TransientFailure
+}
+
+func (p *remotePeer) markUnhealthy(backoff time.Duration) {
+	p.unhealthyUntil.Store(time.Now().Add(backoff).UnixNano())
+}
+
+type forwardedCheckCtxKey struct{}
+
+// ContextWithForwardedCheck marks req as dispatched by a peer. The RemoteCheckResolver resolves it locally
+// even if its own view of the cluster assigns it to another node, so that nodes with diverging views can't
+// bounce a subproblem between them. The subproblems of req are routed as usual.
+func ContextWithForwardedCheck(ctx context.Context, req *ResolveCheckRequest) context.Context {
+	return context.WithValue(ctx, forwardedCheckCtxKey{}, req)
+}
+
+func isForwardedCheck(ctx context.Context, req *ResolveCheckRequest) bool {
+	forwarded, _ := ctx.Value(forwardedCheckCtxKey{}).(*ResolveCheckRequest)
+	return forwarded != nil && forwarded == req
+}
+
+// RemoteCheckResolver spreads the resolution of Check subproblems across the nodes of an OpenFGA cluster.
+// Each subproblem is assigned to a node by consistent hashing of its store, model, tuple key, contextual
+// tuples and context, which are also the inputs of the check cache key, so each node ends up caching a
+// distinct share of the subproblems. Subproblems assigned to other nodes are sent to them over the
+// CheckDispatch service; the others, and those whose node is unhealthy or fails, are resolved by the
+// delegate.
+type RemoteCheckResolver struct {
+	delegate         CheckResolver
+	logger           logger.Logger
+	self             string
+	discoverer       PeerDiscoverer
+	refreshInterval  time.Duration
+	unhealthyBackoff time.Duration
+	virtualNodes     int
+	dialOptions      []grpc.DialOption
+
+	mu    sync.RWMutex
+	ring  *hashRing
+	peers map[string]*remotePeer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ CheckResolver = (*RemoteCheckResolver)(nil)
+
+// RemoteCheckResolverOpt defines an option that can be used to change the behavior of RemoteCheckResolver
+// instance.
+type RemoteCheckResolverOpt func(*RemoteCheckResolver)
+
+// WithSelfAddress sets the dispatch address of this node, as its peers know it. Subproblems assigned to it
+// are resolved locally.
+func WithSelfAddress(address string) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.self = address
+	}
+}
+
+// WithPeerDiscoverer sets how the nodes of the cluster are discovered.
+func WithPeerDiscoverer(discoverer PeerDiscoverer) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.discoverer = discoverer
+	}
+}
+
+// WithPeerRefreshInterval sets how often the nodes of the cluster are discovered again. Zero disables it.
+func WithPeerRefreshInterval(interval time.Duration) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.refreshInterval = interval
+	}
+}
+
+// WithPeerUnhealthyBackoff sets for how long a peer that could not be reached is skipped.
+func WithPeerUnhealthyBackoff(backoff time.Duration) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.unhealthyBackoff = backoff
+	}
+}
+
+// WithPeerDialOptions sets the options of the connections to the peers. By default, connections are
+// not encrypted.
+func WithPeerDialOptions(opts ...grpc.DialOption) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.dialOptions = opts
+	}
+}
+
+// WithRemoteCheckResolverLogger sets the logger of RemoteCheckResolver.
+func WithRemoteCheckResolverLogger(logger logger.Logger) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.logger = logger
+	}
+}
+
+// NewRemoteCheckResolver constructs a RemoteCheckResolver and discovers the peers. It must be closed, to
+// stop the discovery and close the connections to the peers.
+func NewRemoteCheckResolver(opts ...RemoteCheckResolverOpt) *RemoteCheckResolver {
+	r := &RemoteCheckResolver{
+		logger:           logger.NewNoopLogger(),
+		discoverer:       StaticPeers(nil),
+		refreshInterval:  defaultPeerRefreshInterval,
+		unhealthyBackoff: defaultPeerUnhealthyBackoff,
+		virtualNodes:     defaultRingVirtualNodes,
+		dialOptions:      []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		ring:             newHashRing(nil, 0),
+		peers:            map[string]*remotePeer{},
+		done:             make(chan struct{}),
+	}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.refreshPeers()
+
+	if r.refreshInterval > 0 {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+
+			ticker := time.NewTicker(r.refreshInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-r.done:
+					return
+				case <-ticker.C:
+					r.refreshPeers()
+				}
+			}
+		}()
+	}
+
+	return r
+}
+
+// refreshPeers rebuilds the ring from the discovered peers, connecting to the new ones and disconnecting
+// from those that left. If the discovery fails, the previous peers are kept.
+func (r *RemoteCheckResolver) refreshPeers() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addresses, err := r.discoverer.Peers(ctx)
+	if err != nil {
+		r.logger.Warn("failed to discover check dispatch peers", zap.Error(err))
+		return
+	}
+
+	members := []string{}
+	if r.self != "" {
+		members = append(members, r.self)
+	}
+	for _, address := range addresses {
+		if !slices.Contains(members, address) {
+			members = append(members, address)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peers := make(map[string]*remotePeer, len(members))
+	for _, address := range members {
+		if address == r.self {
+			continue
+		}
+		if peer, ok := r.peers[address]; ok {
+			peers[address] = peer
+			continue
+		}
+
+		conn, err := grpc.NewClient(address, r.dialOptions...)
+		if err != nil {
+			r.logger.Warn("failed to create connection to check dispatch peer", zap.String("peer", address), zap.Error(err))
+			continue
+		}
+		peers[address] = &remotePeer{
+			address: address,
+			conn:    conn,
+			client:  NewCheckDispatchServiceClient(conn),
+		}
+	}
+
+	for address, peer := range r.peers {
+		if _, ok := peers[address]; !ok {
+			_ = peer.conn.Close()
+		}
+	}
+
+	r.peers = peers
+	r.ring = newHashRing(members, r.virtualNodes)
+}
+
+// peerFor returns the peer req is assigned to, or nil if it must be resolved locally.
+func (r *RemoteCheckResolver) peerFor(req *ResolveCheckRequest) (*remotePeer, error) {
+	key, err := CheckRequestCacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owner := r.ring.owner(key)
+	if owner == "" || owner == r.self {
+		return nil, nil
+	}
+	return r.peers[owner], nil
+}
+
+func (r *RemoteCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+func (r *RemoteCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+func (r *RemoteCheckResolver) Close() {
+	close(r.done)
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, peer := range r.peers {
+		_ = peer.conn.Close()
+	}
+	r.peers = map[string]*remotePeer{}
+}
+
+func (r *RemoteCheckResolver) ResolveCheck(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+) (*ResolveCheckResponse, error) {
+	if isForwardedCheck(ctx, req) {
+		remoteCheckDispatchCounter.WithLabelValues(remoteDispatchOutcomeLocal).Inc()
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	peer, err := r.peerFor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if peer == nil {
+		remoteCheckDispatchCounter.WithLabelValues(remoteDispatchOutcomeLocal).Inc()
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("dispatch_peer", peer.address))
+
+	if peer.healthy() {
+		resp, err := r.dispatch(ctx, peer, req)
+		if err == nil {
+			remoteCheckDispatchCounter.WithLabelValues(remoteDispatchOutcomeRemote).Inc()
+			return resp, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if errors.Is(err, ErrResolutionDepthExceeded) {
+			return nil, err
+		}
+
+		if isPeerFailure(err) {
+			peer.markUnhealthy(r.unhealthyBackoff)
+		}
+		r.logger.Warn("failed to dispatch check to peer, resolving it locally", zap.String("peer", peer.address), zap.Error(err))
+	}
+
+	span.SetAttributes(attribute.Bool("dispatch_fallback", true))
+	remoteCheckDispatchCounter.WithLabelValues(remoteDispatchOutcomeFallback).Inc()
+	return r.delegate.ResolveCheck(ctx, req)
+}
+
+func (r *RemoteCheckResolver) dispatch(ctx context.Context, peer *remotePeer, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	dispatchRequest := &DispatchCheckRequest{Request: req}
+	if revision, ok := storage.RevisionFromContext(ctx); ok {
+		dispatchRequest.Revision = &revision
+	}
+
+	resp, err := peer.client.DispatchCheck(ctx, dispatchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestMetadata := req.GetRequestMetadata(); requestMetadata != nil {
+		requestMetadata.DispatchCounter.Add(resp.DispatchCount)
+		if resp.WasThrottled {
+			requestMetadata.WasThrottled.Store(true)
+		}
+	}
+
+	return &ResolveCheckResponse{
+		Allowed: resp.Allowed,
+		ResolutionMetadata: ResolveCheckResponseMetadata{
+			DatastoreQueryCount: resp.DatastoreQueryCount,
+			CycleDetected:       resp.CycleDetected,
+		},
+	}, nil
+}
+
+// isPeerFailure reports whether err means the peer can't serve dispatches, as opposed to a failure to resolve
+// a particular subproblem.
+func isPeerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unimplemented, codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,294 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestHashRing(t *testing.T) {
+	members := []string{"node-a:8082", "node-b:8082", "node-c:8082"}
+	ring := newHashRing(members, defaultRingVirtualNodes)
+
+	t.Run("is_independent_of_member_order", func(t *testing.T) {
+		reversed := newHashRing([]string{members[2], members[1], members[0]}, defaultRingVirtualNodes)
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			require.Equal(t, ring.owner(key), reversed.owner(key))
+		}
+	})
+
+	t.Run("spreads_keys", func(t *testing.T) {
+		owned := map[string]int{}
+		for i := 0; i < 3000; i++ {
+			owned[ring.owner(fmt.Sprintf("key-%d", i))]++
+		}
+		for _, member := range members {
+			require.Greater(t, owned[member], 600, member)
+		}
+	})
+
+	t.Run("only_moves_the_keys_of_a_removed_member", func(t *testing.T) {
+		shrunk := newHashRing(members[:2], defaultRingVirtualNodes)
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			if owner := ring.owner(key); owner != members[2] {
+				require.Equal(t, owner, shrunk.owner(key))
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil, defaultRingVirtualNodes).owner("key"))
+	})
+}
+
+type testCheckDispatchServer struct {
+	requests chan *ResolveCheckRequest
+	err      error
+}
+
+func (s *testCheckDispatchServer) DispatchCheck(_ context.Context, req *DispatchCheckRequest) (*DispatchCheckResponse, error) {
+	s.requests <- req.GetRequest()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &DispatchCheckResponse{Allowed: true, DispatchCount: 3, DatastoreQueryCount: 2}, nil
+}
+
+func startTestCheckDispatchServer(t *testing.T, srv CheckDispatchServiceServer) (string, *grpc.Server) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	RegisterCheckDispatchServiceServer(grpcServer, srv)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	return lis.Addr().String(), grpcServer
+}
+
+func TestRemoteCheckResolver(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	const self = "self:8082"
+
+	// findRequest returns a request that the resolver assigns to a peer if remote is true, or to itself otherwise
+	findRequest := func(t *testing.T, r *RemoteCheckResolver, remote bool) *ResolveCheckRequest {
+		for i := 0; i < 1000; i++ {
+			req := &ResolveCheckRequest{
+				StoreID:              "store",
+				AuthorizationModelID: "model",
+				TupleKey:             tuple.NewTupleKey(fmt.Sprintf("doc:%d", i), "viewer", "user:anne"),
+				RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+				VisitedPaths:         map[string]struct{}{},
+			}
+			peer, err := r.peerFor(req)
+			require.NoError(t, err)
+			if (peer != nil) == remote {
+				return req
+			}
+		}
+		require.FailNow(t, "no request found")
+		return nil
+	}
+
+	t.Run("dispatches_to_the_owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := &testCheckDispatchServer{requests: make(chan *ResolveCheckRequest, 1)}
+		peerAddr, _ := startTestCheckDispatchServer(t, srv)
+
+		r := NewRemoteCheckResolver(WithSelfAddress(self), WithPeerDiscoverer(StaticPeers{self, peerAddr}))
+		t.Cleanup(r.Close)
+
+		delegate := NewMockCheckResolver(ctrl)
+		r.SetDelegate(delegate)
+
+		req := findRequest(t, r, true)
+		conditionContext := testutils.MustNewStruct(t, map[string]interface{}{"x": "y"})
+		req.Context = conditionContext
+		req.ContextualTuples = []*openfgav1.TupleKey{tuple.NewTupleKeyWithCondition("doc:1", "viewer", "user:bob", "cond", nil)}
+		req.VisitedPaths = map[string]struct{}{"doc:1#viewer@user:anne": {}}
+		req.Consistency = openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY
+		req.LastCacheInvalidationTime = time.Unix(100, 0).UTC()
+		req.GetRequestMetadata().DispatchCounter.Store(1)
+
+		// the key of the peer depends on the context, so look again for a request assigned to the peer
+		for peer, _ := r.peerFor(req); peer == nil; peer, _ = r.peerFor(req) {
+			req.TupleKey.Object += "0"
+		}
+
+		delegate.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := r.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, uint32(2), resp.GetResolutionMetadata().DatastoreQueryCount)
+		require.Equal(t, uint32(4), req.GetRequestMetadata().DispatchCounter.Load())
+
+		dispatched := <-srv.requests
+		require.Equal(t, req.GetStoreID(), dispatched.GetStoreID())
+		require.Equal(t, req.GetAuthorizationModelID(), dispatched.GetAuthorizationModelID())
+		require.Equal(t, req.GetTupleKey().String(), dispatched.GetTupleKey().String())
+		require.Len(t, dispatched.GetContextualTuples(), 1)
+		require.Equal(t, req.GetContextualTuples()[0].String(), dispatched.GetContextualTuples()[0].String())
+		require.Equal(t, conditionContext.AsMap(), dispatched.GetContext().AsMap())
+		require.Equal(t, req.GetVisitedPaths(), dispatched.GetVisitedPaths())
+		require.Equal(t, req.GetConsistency(), dispatched.GetConsistency())
+		require.True(t, req.GetLastCacheInvalidationTime().Equal(dispatched.GetLastCacheInvalidationTime()))
+		require.Equal(t, req.GetRequestMetadata().Depth, dispatched.GetRequestMetadata().Depth)
+		require.Zero(t, dispatched.GetRequestMetadata().DispatchCounter.Load())
+	})
+
+	t.Run("resolves_locally_what_it_owns", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := &testCheckDispatchServer{requests: make(chan *ResolveCheckRequest, 1)}
+		peerAddr, _ := startTestCheckDispatchServer(t, srv)
+
+		r := NewRemoteCheckResolver(WithSelfAddress(self), WithPeerDiscoverer(StaticPeers{peerAddr}))
+		t.Cleanup(r.Close)
+
+		delegate := NewMockCheckResolver(ctrl)
+		r.SetDelegate(delegate)
+
+		local := findRequest(t, r, false)
+		delegate.EXPECT().ResolveCheck(gomock.Any(), local).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+		resp, err := r.ResolveCheck(context.Background(), local)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+
+		// a forwarded request is resolved locally even if assigned to another node
+		forwarded := findRequest(t, r, true)
+		delegate.EXPECT().ResolveCheck(gomock.Any(), forwarded).Times(1).Return(&ResolveCheckResponse{Allowed: false}, nil)
+		_, err = r.ResolveCheck(ContextWithForwardedCheck(context.Background(), forwarded), forwarded)
+		require.NoError(t, err)
+
+		require.Empty(t, srv.requests)
+	})
+
+	t.Run("falls_back_to_local_when_the_peer_is_unhealthy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := &testCheckDispatchServer{requests: make(chan *ResolveCheckRequest, 1)}
+		peerAddr, grpcServer := startTestCheckDispatchServer(t, srv)
+		grpcServer.Stop()
+
+		r := NewRemoteCheckResolver(
+			WithSelfAddress(self),
+			WithPeerDiscoverer(StaticPeers{peerAddr}),
+			WithPeerUnhealthyBackoff(time.Hour),
+		)
+		t.Cleanup(r.Close)
+
+		delegate := NewMockCheckResolver(ctrl)
+		r.SetDelegate(delegate)
+
+		req := findRequest(t, r, true)
+		delegate.EXPECT().ResolveCheck(gomock.Any(), req).Times(2).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		resp, err := r.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		peer, err := r.peerFor(req)
+		require.NoError(t, err)
+		require.False(t, peer.healthy())
+
+		// the peer is skipped during the backoff
+		_, err = r.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("propagates_resolution_depth_exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := &testCheckDispatchServer{requests: make(chan *ResolveCheckRequest, 1), err: ErrResolutionDepthExceeded}
+		peerAddr, _ := startTestCheckDispatchServer(t, srv)
+
+		r := NewRemoteCheckResolver(WithSelfAddress(self), WithPeerDiscoverer(StaticPeers{peerAddr}))
+		t.Cleanup(r.Close)
+
+		delegate := NewMockCheckResolver(ctrl)
+		r.SetDelegate(delegate)
+		delegate.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := r.ResolveCheck(context.Background(), findRequest(t, r, true))
+		require.ErrorIs(t, err, ErrResolutionDepthExceeded)
+	})
+
+	t.Run("resolves_locally_on_other_resource_exhausted_errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := &testCheckDispatchServer{
+			requests: make(chan *ResolveCheckRequest, 1),
+			err:      status.Error(codes.ResourceExhausted, "grpc: received message larger than max"),
+		}
+		peerAddr, _ := startTestCheckDispatchServer(t, srv)
+
+		r := NewRemoteCheckResolver(WithSelfAddress(self), WithPeerDiscoverer(StaticPeers{peerAddr}))
+		t.Cleanup(r.Close)
+
+		delegate := NewMockCheckResolver(ctrl)
+		r.SetDelegate(delegate)
+
+		req := findRequest(t, r, true)
+		delegate.EXPECT().ResolveCheck(gomock.Any(), req).Times(1).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		resp, err := r.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+}
+
+func TestDispatchCheckRequestJSON(t *testing.T) {
+	conditionContext, err := structpb.NewStruct(map[string]interface{}{"x": 1})
+	require.NoError(t, err)
+
+	in := &DispatchCheckRequest{Request: &ResolveCheckRequest{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+		Context:              conditionContext,
+		RequestMetadata:      NewCheckRequestMetadata(7),
+	}}
+	revision := storage.Revision{ULID: ulid.Make(), Mode: storage.RevisionExact}
+	in.Revision = &revision
+
+	data, err := in.MarshalJSON()
+	require.NoError(t, err)
+
+	var out DispatchCheckRequest
+	require.NoError(t, out.UnmarshalJSON(data))
+	require.Equal(t, "store", out.GetRequest().GetStoreID())
+	require.Equal(t, uint32(7), out.GetRequest().GetRequestMetadata().Depth)
+	require.Empty(t, out.GetRequest().GetContextualTuples())
+	require.Equal(t, conditionContext.AsMap(), out.GetRequest().GetContext().AsMap())
+	require.Equal(t, &revision, out.Revision)
+}
@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/internal/jsoncodec"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	checkDispatchServiceName = "openfga.internal.v1.CheckDispatchService"
+	dispatchCheckFullMethod  = "/" + checkDispatchServiceName + "/DispatchCheck"
+)
+
+// DispatchCheckRequest is the request a RemoteCheckResolver sends to the peer that owns a Check subproblem.
+// The CheckDispatch service is internal to an OpenFGA cluster, and its messages are encoded as JSON
+// (see jsoncodec).
+type DispatchCheckRequest struct {
+	Request *ResolveCheckRequest
+	// Revision is the revision the reads of the subproblem are bound to, if any (see storage.RevisionFromContext).
+	Revision *storage.Revision
+}
+
+// DispatchCheckResponse is the outcome of a Check subproblem resolved by a peer.
+type DispatchCheckResponse struct {
+	Allowed             bool   `json:"allowed"`
+	CycleDetected       bool   `json:"cycle_detected,omitempty"`
+	DatastoreQueryCount uint32 `json:"datastore_query_count,omitempty"`
+	// DispatchCount is the number of dispatches the peer needed to resolve the subproblem.
+	DispatchCount uint32 `json:"dispatch_count,omitempty"`
+	WasThrottled  bool   `json:"was_throttled,omitempty"`
+}
+
+func (r *DispatchCheckRequest) GetRequest() *ResolveCheckRequest {
+	if r == nil {
+		return nil
+	}
+	return r.Request
+}
+
+type dispatchCheckRequestJSON struct {
+	StoreID                   string                          `json:"store_id"`
+	AuthorizationModelID      string                          `json:"authorization_model_id"`
+	TupleKey                  json.RawMessage                 `json:"tuple_key"`
+	ContextualTuples          []json.RawMessage               `json:"contextual_tuples,omitempty"`
+	Context                   json.RawMessage                 `json:"context,omitempty"`
+	VisitedPaths              []string                        `json:"visited_paths,omitempty"`
+	Depth                     uint32                          `json:"depth"`
+	Consistency               openfgav1.ConsistencyPreference `json:"consistency,omitempty"`
+	LastCacheInvalidationTime time.Time                       `json:"last_cache_invalidation_time"`
+	Revision                  string                          `json:"revision,omitempty"`
+	RevisionMode              storage.RevisionMode            `json:"revision_mode,omitempty"`
+}
+
+func (r *DispatchCheckRequest) MarshalJSON() ([]byte, error) {
+	req := r.GetRequest()
+
+	tupleKey, err := protojson.Marshal(req.GetTupleKey())
+	if err != nil {
+		return nil, err
+	}
+
+	contextualTuples := make([]json.RawMessage, 0, len(req.GetContextualTuples()))
+	for _, tk := range req.GetContextualTuples() {
+		contextualTuple, err := protojson.Marshal(tk)
+		if err != nil {
+			return nil, err
+		}
+		contextualTuples = append(contextualTuples, contextualTuple)
+	}
+
+	var conditionContext json.RawMessage
+	if req.GetContext() != nil {
+		conditionContext, err = protojson.Marshal(req.GetContext())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var depth uint32
+	if requestMetadata := req.GetRequestMetadata(); requestMetadata != nil {
+		depth = requestMetadata.Depth
+	}
+
+	visitedPaths := make([]string, 0, len(req.GetVisitedPaths()))
+	for path := range req.GetVisitedPaths() {
+		visitedPaths = append(visitedPaths, path)
+	}
+
+	out := &dispatchCheckRequestJSON{
+		StoreID:                   req.GetStoreID(),
+		AuthorizationModelID:      req.GetAuthorizationModelID(),
+		TupleKey:                  tupleKey,
+		ContextualTuples:          contextualTuples,
+		Context:                   conditionContext,
+		VisitedPaths:              visitedPaths,
+		Depth:                     depth,
+		Consistency:               req.GetConsistency(),
+		LastCacheInvalidationTime: req.GetLastCacheInvalidationTime(),
+	}
+	if r.Revision != nil {
+		out.Revision = r.Revision.ULID.String()
+		out.RevisionMode = r.Revision.Mode
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the request with a fresh RequestMetadata, so that the dispatches of the peer are
+// counted separately and reported back in DispatchCheckResponse.
+func (r *DispatchCheckRequest) UnmarshalJSON(data []byte) error {
+	var in dispatchCheckRequestJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	tupleKey := &openfgav1.TupleKey{}
+	if err := protojson.Unmarshal(in.TupleKey, tupleKey); err != nil {
+		return err
+	}
+
+	contextualTuples := make([]*openfgav1.TupleKey, 0, len(in.ContextualTuples))
+	for _, raw := range in.ContextualTuples {
+		tk := &openfgav1.TupleKey{}
+		if err := protojson.Unmarshal(raw, tk); err != nil {
+			return err
+		}
+		contextualTuples = append(contextualTuples, tk)
+	}
+
+	var conditionContext *structpb.Struct
+	if len(in.Context) > 0 {
+		conditionContext = &structpb.Struct{}
+		if err := protojson.Unmarshal(in.Context, conditionContext); err != nil {
+			return err
+		}
+	}
+
+	visitedPaths := make(map[string]struct{}, len(in.VisitedPaths))
+	for _, path := range in.VisitedPaths {
+		visitedPaths[path] = struct{}{}
+	}
+
+	r.Request = &ResolveCheckRequest{
+		StoreID:                   in.StoreID,
+		AuthorizationModelID:      in.AuthorizationModelID,
+		TupleKey:                  tupleKey,
+		ContextualTuples:          contextualTuples,
+		Context:                   conditionContext,
+		RequestMetadata:           NewCheckRequestMetadata(in.Depth),
+		VisitedPaths:              visitedPaths,
+		Consistency:               in.Consistency,
+		LastCacheInvalidationTime: in.LastCacheInvalidationTime,
+	}
+
+	r.Revision = nil
+	if in.Revision != "" {
+		revision, err := ulid.ParseStrict(in.Revision)
+		if err != nil {
+			return err
+		}
+		r.Revision = &storage.Revision{ULID: revision, Mode: in.RevisionMode}
+	}
+	return nil
+}
+
+// CheckDispatchServiceServer is the server API for the CheckDispatch service.
+type CheckDispatchServiceServer interface {
+	DispatchCheck(context.Context, *DispatchCheckRequest) (*DispatchCheckResponse, error)
+}
+
+// CheckDispatchServiceDesc is the grpc.ServiceDesc for the CheckDispatch service.
+var CheckDispatchServiceDesc = grpc.ServiceDesc{
+	ServiceName: checkDispatchServiceName,
+	HandlerType: (*CheckDispatchServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DispatchCheck",
+			Handler:    dispatchCheckHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterCheckDispatchServiceServer registers the CheckDispatch service in the given gRPC server. The service
+// is meant to be reachable by the other nodes of the cluster only, so it should be registered in its own server.
+func RegisterCheckDispatchServiceServer(s grpc.ServiceRegistrar, srv CheckDispatchServiceServer) {
+	s.RegisterService(&CheckDispatchServiceDesc, srv)
+}
+
+func dispatchCheckHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DispatchCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		resp, err := srv.(CheckDispatchServiceServer).DispatchCheck(ctx, req.(*DispatchCheckRequest))
+		return resp, dispatchCheckErrorToStatus(err)
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: dispatchCheckFullMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// resolutionDepthExceededReason is the reason of the error info detail of the status returned for
+// ErrResolutionDepthExceeded, which tells it apart from the other ResourceExhausted statuses, such as those of the
+// message size limits of gRPC.
+const resolutionDepthExceededReason = "RESOLUTION_DEPTH_EXCEEDED"
+
+// dispatchCheckErrorToStatus keeps ErrResolutionDepthExceeded recognizable by the caller, which would otherwise
+// resolve the subproblem again locally only to exceed the depth once more.
+func dispatchCheckErrorToStatus(err error) error {
+	if !errors.Is(err, ErrResolutionDepthExceeded) {
+		return err
+	}
+	st, detailErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: resolutionDepthExceededReason,
+		Domain: checkDispatchServiceName,
+	})
+	if detailErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
+
+// dispatchCheckErrorFromStatus returns ErrResolutionDepthExceeded for the statuses returned for it by
+// dispatchCheckErrorToStatus, and the other errors unchanged.
+func dispatchCheckErrorFromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok &&
+			info.GetDomain() == checkDispatchServiceName && info.GetReason() == resolutionDepthExceededReason {
+			return ErrResolutionDepthExceeded
+		}
+	}
+	return err
+}
+
+// CheckDispatchServiceClient is the client API for the CheckDispatch service.
+type CheckDispatchServiceClient interface {
+	DispatchCheck(ctx context.Context, in *DispatchCheckRequest, opts ...grpc.CallOption) (*DispatchCheckResponse, error)
+}
+
+type checkDispatchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCheckDispatchServiceClient(cc grpc.ClientConnInterface) CheckDispatchServiceClient {
+	return &checkDispatchServiceClient{cc: cc}
+}
+
+func (c *checkDispatchServiceClient) DispatchCheck(ctx context.Context, in *DispatchCheckRequest, opts ...grpc.CallOption) (*DispatchCheckResponse, error) {
+	out := new(DispatchCheckResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsoncodec.Name)}, opts...)
+	if err := c.cc.Invoke(ctx, dispatchCheckFullMethod, in, out, opts...); err != nil {
+		return nil, dispatchCheckErrorFromStatus(err)
+	}
+	return out, nil
+}
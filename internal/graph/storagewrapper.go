@@ -254,11 +254,17 @@ func (c *CachedDatastore) newCachedIterator(
 	cacheKey string,
 	invalidEntityKeys []string,
 ) (storage.TupleIterator, error) {
+	// the tuples read at an exact revision are neither served from the cache nor cached
+	revision, hasRevision := storage.RevisionFromContext(ctx)
+	if hasRevision && revision.Mode == storage.RevisionExact {
+		return dsIterFunc(ctx)
+	}
+
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(attribute.String("cache_key", cacheKey))
 	tuplesCacheTotalCounter.Inc()
 
-	if cacheEntry, ok := c.findInCache(store, cacheKey, invalidEntityKeys); ok {
+	if cacheEntry, ok := c.findInCache(store, cacheKey, invalidEntityKeys); ok && (!hasRevision || revision.IsCacheable(cacheEntry.LastModified)) {
 		tuplesCacheHitCounter.Inc()
 		span.SetAttributes(attribute.Bool("cached", true))
 		return storage.NewStaticTupleIterator(cacheEntry.Tuples), nil
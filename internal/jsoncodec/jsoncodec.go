@@ -0,0 +1,31 @@
+// Package jsoncodec provides the gRPC codec of the services whose messages are not protobuf messages,
+// e.g. because they are not part of the openfga/api protobuf definitions. Clients must call those
+// services with the Name content-subtype (see grpc.CallContentSubtype).
+package jsoncodec
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name of the codec, and the content-subtype clients must use.
+const Name = "json"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return Name
+}
@@ -41,6 +41,11 @@ func (m *slowDataStorage) ReadUserTuple(ctx context.Context, store string, key *
 	return m.OpenFGADatastore.ReadUserTuple(ctx, store, key, options)
 }
 
+func (m *slowDataStorage) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	time.Sleep(m.readTuplesDelay)
+	return m.OpenFGADatastore.ReadTuplesBatch(ctx, store, lookups, options)
+}
+
 func (m *slowDataStorage) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
 	time.Sleep(m.readTuplesDelay)
 	return m.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options)
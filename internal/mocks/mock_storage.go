@@ -101,6 +101,21 @@ func (mr *MockTupleBackendMockRecorder) ReadStartingWithUser(ctx, store, filter,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadStartingWithUser", reflect.TypeOf((*MockTupleBackend)(nil).ReadStartingWithUser), ctx, store, filter, options)
 }
 
+// ReadTuplesBatch mocks base method.
+func (m *MockTupleBackend) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTuplesBatch", ctx, store, lookups, options)
+	ret0, _ := ret[0].([]*openfgav1.Tuple)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTuplesBatch indicates an expected call of ReadTuplesBatch.
+func (mr *MockTupleBackendMockRecorder) ReadTuplesBatch(ctx, store, lookups, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTuplesBatch", reflect.TypeOf((*MockTupleBackend)(nil).ReadTuplesBatch), ctx, store, lookups, options)
+}
+
 // ReadUserTuple mocks base method.
 func (m *MockTupleBackend) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
 	m.ctrl.T.Helper()
@@ -132,17 +147,22 @@ func (mr *MockTupleBackendMockRecorder) ReadUsersetTuples(ctx, store, filter, op
 }
 
 // Write mocks base method.
-func (m *MockTupleBackend) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes) error {
+func (m *MockTupleBackend) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes, opts ...storage.TupleWriteOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", ctx, store, d, w)
+	varargs := []any{ctx, store, d, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write.
-func (mr *MockTupleBackendMockRecorder) Write(ctx, store, d, w any) *gomock.Call {
+func (mr *MockTupleBackendMockRecorder) Write(ctx, store, d, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockTupleBackend)(nil).Write), ctx, store, d, w)
+	varargs := append([]any{ctx, store, d, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockTupleBackend)(nil).Write), varargs...)
 }
 
 // MockRelationshipTupleReader is a mock of RelationshipTupleReader interface.
@@ -214,6 +234,21 @@ func (mr *MockRelationshipTupleReaderMockRecorder) ReadStartingWithUser(ctx, sto
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadStartingWithUser", reflect.TypeOf((*MockRelationshipTupleReader)(nil).ReadStartingWithUser), ctx, store, filter, options)
 }
 
+// ReadTuplesBatch mocks base method.
+func (m *MockRelationshipTupleReader) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTuplesBatch", ctx, store, lookups, options)
+	ret0, _ := ret[0].([]*openfgav1.Tuple)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTuplesBatch indicates an expected call of ReadTuplesBatch.
+func (mr *MockRelationshipTupleReaderMockRecorder) ReadTuplesBatch(ctx, store, lookups, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTuplesBatch", reflect.TypeOf((*MockRelationshipTupleReader)(nil).ReadTuplesBatch), ctx, store, lookups, options)
+}
+
 // ReadUserTuple mocks base method.
 func (m *MockRelationshipTupleReader) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
 	m.ctrl.T.Helper()
@@ -282,17 +317,22 @@ func (mr *MockRelationshipTupleWriterMockRecorder) MaxTuplesPerWrite() *gomock.C
 }
 
 // Write mocks base method.
-func (m *MockRelationshipTupleWriter) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes) error {
+func (m *MockRelationshipTupleWriter) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes, opts ...storage.TupleWriteOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", ctx, store, d, w)
+	varargs := []any{ctx, store, d, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write.
-func (mr *MockRelationshipTupleWriterMockRecorder) Write(ctx, store, d, w any) *gomock.Call {
+func (mr *MockRelationshipTupleWriterMockRecorder) Write(ctx, store, d, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockRelationshipTupleWriter)(nil).Write), ctx, store, d, w)
+	varargs := append([]any{ctx, store, d, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockRelationshipTupleWriter)(nil).Write), varargs...)
 }
 
 // MockAuthorizationModelReadBackend is a mock of AuthorizationModelReadBackend interface.
@@ -947,6 +987,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadStartingWithUser(ctx, store, fil
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadStartingWithUser", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadStartingWithUser), ctx, store, filter, options)
 }
 
+// ReadTuplesBatch mocks base method.
+func (m *MockOpenFGADatastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTuplesBatch", ctx, store, lookups, options)
+	ret0, _ := ret[0].([]*openfgav1.Tuple)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTuplesBatch indicates an expected call of ReadTuplesBatch.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadTuplesBatch(ctx, store, lookups, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTuplesBatch", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadTuplesBatch), ctx, store, lookups, options)
+}
+
 // ReadUserTuple mocks base method.
 func (m *MockOpenFGADatastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
 	m.ctrl.T.Helper()
@@ -978,17 +1033,22 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadUsersetTuples(ctx, store, filter
 }
 
 // Write mocks base method.
-func (m *MockOpenFGADatastore) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes) error {
+func (m *MockOpenFGADatastore) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes, opts ...storage.TupleWriteOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", ctx, store, d, w)
+	varargs := []any{ctx, store, d, w}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write.
-func (mr *MockOpenFGADatastoreMockRecorder) Write(ctx, store, d, w any) *gomock.Call {
+func (mr *MockOpenFGADatastoreMockRecorder) Write(ctx, store, d, w any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockOpenFGADatastore)(nil).Write), ctx, store, d, w)
+	varargs := append([]any{ctx, store, d, w}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockOpenFGADatastore)(nil).Write), varargs...)
 }
 
 // WriteAssertions mocks base method.
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -31,11 +33,25 @@ const (
 	DefaultListUsersDeadline                = 3 * time.Second
 	DefaultListUsersMaxResults              = 1000
 	DefaultMaxConcurrentReadsForListUsers   = math.MaxUint32
+	DefaultMaxChecksPerBatchCheck           = 50
+	DefaultMaxConcurrentChecksPerBatchCheck = 50
 
 	DefaultWriteContextByteLimit = 32 * 1_024 // 32KB
 
+	DefaultDatastoreReplicaMaxLag        = 5 * time.Second
+	DefaultDatastoreReplicaCheckInterval = time.Second
+
+	DefaultDatastoreMemoryFsync            = "interval"
+	DefaultDatastoreMemorySnapshotInterval = 5 * time.Minute
+
+	DefaultDatastoreShardPlacement         = "default"
+	DefaultDatastoreShardPlacementCacheTTL = 10 * time.Second
+
 	DefaultCacheLimit = 10000
 
+	DefaultSharedCacheTimeout = 50 * time.Millisecond
+	DefaultSharedCacheBackoff = 5 * time.Second
+
 	DefaultCacheControllerEnabled = false
 	DefaultCacheControllerTTL     = 10 * time.Second
 
@@ -64,18 +80,66 @@ const (
 	DefaultListUsersDispatchThrottlingDefaultThreshold = 100
 	DefaultListUsersDispatchThrottlingMaxThreshold     = 0 // 0 means use the default threshold as max
 
+	DefaultRemoteCheckDispatchEnabled              = false
+	DefaultRemoteCheckDispatchAddr                 = "0.0.0.0:8082"
+	DefaultRemoteCheckDispatchPeerRefreshInterval  = 30 * time.Second
+	DefaultRemoteCheckDispatchPeerUnhealthyBackoff = 5 * time.Second
+
+	DefaultWatchChangesHeartbeatInterval = 15 * time.Second
+	DefaultWatchChangesMinPollInterval   = 250 * time.Millisecond
+	DefaultWatchChangesMaxPollInterval   = 5 * time.Second
+
+	DefaultTupleExpirationReaperInterval  = time.Minute
+	DefaultTupleExpirationReaperBatchSize = 1000
+
+	DefaultChangelogRetentionPruneInterval  = time.Minute
+	DefaultChangelogRetentionPruneBatchSize = 1000
+
+	DefaultStorePurgeInterval  = time.Minute
+	DefaultStorePurgeBatchSize = 1000
+
+	DefaultOutboxEnabled           = false
+	DefaultOutboxPollInterval      = time.Second
+	DefaultOutboxBatchSize         = 100
+	DefaultOutboxWebhookMaxRetries = 3
+	DefaultOutboxWebhookTimeout    = 10 * time.Second
+
+	DefaultCheckDatastoreBatchingEnabled      = false
+	DefaultCheckDatastoreBatchingWindow       = time.Millisecond
+	DefaultCheckDatastoreBatchingMaxBatchSize = 100
+
 	DefaultRequestTimeout     = 3 * time.Second
 	additionalUpstreamTimeout = 3 * time.Second
 )
 
+// shardNameRegex matches the valid names of the shards of the datastore.
+var shardNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
 type DatastoreMetricsConfig struct {
-	// Enabled enables export of the Datastore metrics.
+	// Enabled enables export of the Datastore metrics: those of the connections of the sql datastores, and the
+	// latency, errors, rows and iterator lifetime of every call to the datastore.
 	Enabled bool
+
+	// StoreLabelLimit is the number of stores the metrics of the calls to the datastore are labeled with, the first
+	// ones called with, the others being labeled 'other'. If 0, the metrics aren't labeled by store.
+	StoreLabelLimit int
+}
+
+// DatastoreMemoryConfig defines the settings of the 'memory' datastore engine.
+type DatastoreMemoryConfig struct {
+	// Dir is the directory in which the data is persisted. If empty, the data is lost on shutdown.
+	Dir string
+
+	// Fsync is when the changes are flushed to disk: 'always', 'interval' (every second) or 'never'.
+	Fsync string
+
+	// SnapshotInterval is how often the changes are compacted into a snapshot.
+	SnapshotInterval time.Duration
 }
 
 // DatastoreConfig defines OpenFGA server configurations for datastore specific settings.
 type DatastoreConfig struct {
-	// Engine is the datastore engine to use (e.g. 'memory', 'postgres', 'mysql', 'sqlite')
+	// Engine is the datastore engine to use (e.g. 'memory', 'postgres', 'mysql', 'sqlite', 'bolt')
 	Engine   string
 	URI      string `json:"-"` // private field, won't be logged
 	Username string
@@ -99,6 +163,31 @@ type DatastoreConfig struct {
 
 	// Metrics is configuration for the Datastore metrics.
 	Metrics DatastoreMetricsConfig
+
+	// ReadReplicaURIs are the connection uris of the read replicas of the datastore ('postgres' and 'mysql' only).
+	// The tuple reads which don't require a higher consistency are routed to them.
+	ReadReplicaURIs []string `json:"-"` // private field, won't be logged
+
+	// ReplicaMaxLag is how far behind the primary a read replica may be to serve reads.
+	ReplicaMaxLag time.Duration
+
+	// ReplicaCheckInterval is how often the lag of the read replicas is measured.
+	ReplicaCheckInterval time.Duration
+
+	// Memory is configuration for the 'memory' datastore engine.
+	Memory DatastoreMemoryConfig
+
+	// Shards are the additional datastores the stores are spread over, as '<name>=<uri>' (see ParseDatastoreShard),
+	// using the same engine as the datastore. The datastore holds the placements of the stores, and is itself the
+	// 'default' shard.
+	Shards []string `json:"-"` // private field, won't be logged
+
+	// ShardPlacement is the shard the stores created are placed on: 'default', 'hash' to spread them evenly over
+	// all the shards, or the name of a shard.
+	ShardPlacement string
+
+	// ShardPlacementCacheTTL is how long the placements of the stores are cached.
+	ShardPlacementCacheTTL time.Duration
 }
 
 // GRPCConfig defines OpenFGA server configurations for grpc server specific settings.
@@ -209,6 +298,27 @@ type CheckQueryCache struct {
 
 type CacheConfig struct {
 	Limit uint32
+
+	Shared SharedCacheConfig
+}
+
+// SharedCacheConfig defines configuration for the cache shared with the other servers through a Redis or Valkey
+// server. The check query and iterator caches use it on top of their local cache if Addr is set.
+type SharedCacheConfig struct {
+	// Addr is the address (host:port) of the Redis or Valkey server.
+	Addr     string
+	Username string
+	Password string
+	DB       int
+
+	// KeyPrefix is prepended to the keys, so that several deployments can share a server.
+	KeyPrefix string
+
+	// Timeout is the timeout of each request to the shared cache.
+	Timeout time.Duration
+
+	// Backoff is how long the shared cache is bypassed after a request to it failed.
+	Backoff time.Duration
 }
 
 type CheckIteratorCacheConfig struct {
@@ -224,6 +334,213 @@ type DispatchThrottlingConfig struct {
 	MaxThreshold uint32
 }
 
+// RemoteCheckDispatchConfig defines configurations for spreading the resolution of Check subproblems
+// across the nodes of a cluster.
+type RemoteCheckDispatchConfig struct {
+	Enabled bool
+	// Addr is the address the internal CheckDispatch service listens on.
+	Addr string
+	// AdvertiseAddr is the address at which the other nodes reach the CheckDispatch service of this node.
+	// It must be the same as the address of this node in Peers or, if DNSName is used, its IP and the port of Addr.
+	AdvertiseAddr string
+	// Peers is the static list of the CheckDispatch addresses of the nodes of the cluster.
+	Peers []string
+	// DNSName is resolved to the IPs of the nodes of the cluster, as an alternative to Peers.
+	DNSName string
+	// PeerRefreshInterval is how often the nodes of the cluster are discovered again.
+	PeerRefreshInterval time.Duration
+	// PeerUnhealthyBackoff is for how long a node that could not be reached is not dispatched to.
+	PeerUnhealthyBackoff time.Duration
+	// PresharedKey, if set, is required from, and sent to, the other nodes of the cluster.
+	PresharedKey string
+	// TLS, if enabled, authenticates the nodes of the cluster to each other with mutual TLS.
+	// One of PresharedKey and TLS is required.
+	TLS RemoteCheckDispatchTLSConfig
+}
+
+// RemoteCheckDispatchTLSConfig defines the mutual TLS between the nodes of a cluster. Each node serves,
+// and presents to the other nodes, the certificate, and requires theirs to be signed by the CA.
+type RemoteCheckDispatchTLSConfig struct {
+	Enabled  bool
+	CertPath string `mapstructure:"cert"`
+	KeyPath  string `mapstructure:"key"`
+	CAPath   string `mapstructure:"ca"`
+}
+
+// WatchChangesConfig defines configurations for the WatchChanges streams.
+type WatchChangesConfig struct {
+	// HeartbeatInterval is how long a stream may stay idle before a heartbeat is sent.
+	HeartbeatInterval time.Duration
+	// MinPollInterval and MaxPollInterval bound the interval between reads of the changelog when nothing
+	// changed. Datastores that notify changes are only read every MaxPollInterval, as a safety net.
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+}
+
+// TupleExpirationConfig defines configurations for the deletion of the expired tuples.
+type TupleExpirationConfig struct {
+	// ReaperInterval is how often the expired tuples are deleted. If zero, they are only hidden from reads.
+	ReaperInterval time.Duration
+	// ReaperBatchSize is the maximum number of expired tuples deleted at once.
+	ReaperBatchSize int
+}
+
+// ChangelogRetentionConfig defines configurations for the pruning of the changelogs.
+type ChangelogRetentionConfig struct {
+	// MaxAge is how long the changes are kept. If zero, they aren't deleted by age.
+	MaxAge time.Duration
+	// MaxCount is how many of the newest changes of each store are kept. If zero, they aren't deleted by count.
+	MaxCount int
+	// StoreOverrides are the retentions of specific stores, replacing MaxAge and MaxCount, each as
+	// '<store id>:<max age>:<max count>' (see ParseChangelogStoreRetention).
+	StoreOverrides []string
+	// CompactAfter is the age after which a write of a tuple followed by its delete are both deleted. If zero, the
+	// changelogs aren't compacted.
+	CompactAfter time.Duration
+	// PruneInterval is how often the changelogs are pruned.
+	PruneInterval time.Duration
+	// PruneBatchSize is the maximum number of changes deleted at once.
+	PruneBatchSize int
+}
+
+// StorePurgeConfig defines configurations for the purge of the deleted stores.
+type StorePurgeConfig struct {
+	// Retention is how long the deleted stores are kept, and can be restored with UndeleteStore, before their data is
+	// purged. If zero, they are kept forever.
+	Retention time.Duration
+	// Interval is how often the deleted stores are purged.
+	Interval time.Duration
+	// BatchSize is the maximum number of rows deleted at once.
+	BatchSize int
+}
+
+// StoreQuotaConfig defines configurations for the quotas of the stores. A zero quota doesn't bound the resource.
+type StoreQuotaConfig struct {
+	// MaxTuples is the maximum number of tuples of a store. The writes of the store running at once may exceed it by up
+	// to their own tuples.
+	MaxTuples int64
+	// MaxAuthorizationModels is the maximum number of authorization models of a store.
+	MaxAuthorizationModels int
+	// MaxAssertions is the maximum number of assertions of an authorization model.
+	MaxAssertions int
+	// WriteRate is the maximum number of tuples written or deleted per second by a store.
+	WriteRate float64
+	// StoreOverrides are the quotas of specific stores, replacing the others, each as
+	// '<store id>:<max tuples>:<max authorization models>:<max assertions>:<write rate>' (see ParseStoreQuotaOverride).
+	StoreOverrides []string
+}
+
+// OutboxConfig defines configurations for the outbox of the datastore, which records its changes as events, and the
+// delivery of the events to the sinks.
+type OutboxConfig struct {
+	Enabled bool
+	// PollInterval is how often the outbox is read when it was found empty, or a sink failed.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of events delivered at once.
+	BatchSize int
+	// Stdout enables the sink writing the events to the standard output, as JSON lines.
+	Stdout bool
+	// FilePath, if set, enables the sink appending the events to the file, as JSON lines.
+	FilePath string
+	Webhook  OutboxWebhookConfig
+}
+
+// OutboxWebhookConfig defines configurations for the sink posting the events of the outbox to a webhook.
+type OutboxWebhookConfig struct {
+	// URL, if set, enables the sink.
+	URL string
+	// Secret is the key of the HMAC-SHA256 signature of the requests. If empty, they aren't signed.
+	Secret string
+	// MaxRetries is how many times a failed request is retried.
+	MaxRetries int
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// CheckDatastoreBatchingConfig defines configurations for the batching of the point lookups of the datastore made
+// while resolving a Check.
+type CheckDatastoreBatchingConfig struct {
+	Enabled bool
+	// Window is how long the lookups are collected before being read at once.
+	Window time.Duration
+	// MaxBatchSize is the maximum number of lookups read at once, a batch being read as soon as it is full.
+	MaxBatchSize int
+}
+
+// ParseDatastoreShard parses a shard of the datastore, '<name>=<uri>'.
+func ParseDatastoreShard(shard string) (name, uri string, err error) {
+	name, uri, ok := strings.Cut(shard, "=")
+	if !ok || name == "" || uri == "" {
+		return "", "", errors.New("a shard isn't formatted as '<name>=<uri>'")
+	}
+	return name, uri, nil
+}
+
+// ParseChangelogStoreRetention parses a changelog retention override of a store, '<store id>:<max age>:<max count>'.
+// An empty max age or max count doesn't bound the changes kept.
+func ParseChangelogStoreRetention(override string) (storeID string, maxAge time.Duration, maxCount int, err error) {
+	parts := strings.Split(override, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", 0, 0, fmt.Errorf("'%s' isn't formatted as '<store id>:<max age>:<max count>'", override)
+	}
+
+	if parts[1] != "" {
+		maxAge, err = time.ParseDuration(parts[1])
+		if err != nil || maxAge < 0 {
+			return "", 0, 0, fmt.Errorf("'%s' has an invalid max age", override)
+		}
+	}
+
+	if parts[2] != "" {
+		maxCount, err = strconv.Atoi(parts[2])
+		if err != nil || maxCount < 0 {
+			return "", 0, 0, fmt.Errorf("'%s' has an invalid max count", override)
+		}
+	}
+
+	return parts[0], maxAge, maxCount, nil
+}
+
+// ParseStoreQuotaOverride parses a quota override of a store,
+// '<store id>:<max tuples>:<max authorization models>:<max assertions>:<write rate>'. An empty quota doesn't bound the
+// resource.
+func ParseStoreQuotaOverride(override string) (storeID string, quota StoreQuotaConfig, err error) {
+	parts := strings.Split(override, ":")
+	if len(parts) != 5 || parts[0] == "" {
+		return "", StoreQuotaConfig{}, fmt.Errorf("'%s' isn't formatted as '<store id>:<max tuples>:<max authorization models>:<max assertions>:<write rate>'", override)
+	}
+
+	if parts[1] != "" {
+		quota.MaxTuples, err = strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || quota.MaxTuples < 0 {
+			return "", StoreQuotaConfig{}, fmt.Errorf("'%s' has an invalid max tuples", override)
+		}
+	}
+
+	if parts[2] != "" {
+		quota.MaxAuthorizationModels, err = strconv.Atoi(parts[2])
+		if err != nil || quota.MaxAuthorizationModels < 0 {
+			return "", StoreQuotaConfig{}, fmt.Errorf("'%s' has an invalid max authorization models", override)
+		}
+	}
+
+	if parts[3] != "" {
+		quota.MaxAssertions, err = strconv.Atoi(parts[3])
+		if err != nil || quota.MaxAssertions < 0 {
+			return "", StoreQuotaConfig{}, fmt.Errorf("'%s' has an invalid max assertions", override)
+		}
+	}
+
+	if parts[4] != "" {
+		quota.WriteRate, err = strconv.ParseFloat(parts[4], 64)
+		if err != nil || quota.WriteRate < 0 || math.IsInf(quota.WriteRate, 0) || math.IsNaN(quota.WriteRate) {
+			return "", StoreQuotaConfig{}, fmt.Errorf("'%s' has an invalid write rate", override)
+		}
+	}
+
+	return parts[0], quota, nil
+}
+
 // AccessControlConfig is the configuration for the access control feature.
 type AccessControlConfig struct {
 	Enabled bool
@@ -278,6 +595,13 @@ type Config struct {
 	// allowed in ListUsers queries
 	MaxConcurrentReadsForListUsers uint32
 
+	// MaxChecksPerBatchCheck defines the maximum number of checks allowed in a single BatchCheck request
+	MaxChecksPerBatchCheck uint32
+
+	// MaxConcurrentChecksPerBatchCheck defines the maximum number of checks of a single BatchCheck
+	// request that are resolved concurrently
+	MaxConcurrentChecksPerBatchCheck uint32
+
 	// MaxConditionEvaluationCost defines the maximum cost for CEL condition evaluation before a request returns an error
 	MaxConditionEvaluationCost uint64
 
@@ -319,6 +643,14 @@ type Config struct {
 	CheckDispatchThrottling       DispatchThrottlingConfig
 	ListObjectsDispatchThrottling DispatchThrottlingConfig
 	ListUsersDispatchThrottling   DispatchThrottlingConfig
+	RemoteCheckDispatch           RemoteCheckDispatchConfig
+	WatchChanges                  WatchChangesConfig
+	TupleExpiration               TupleExpirationConfig
+	ChangelogRetention            ChangelogRetentionConfig
+	StorePurge                    StorePurgeConfig
+	StoreQuota                    StoreQuotaConfig
+	Outbox                        OutboxConfig
+	CheckDatastoreBatching        CheckDatastoreBatchingConfig
 
 	RequestDurationDatastoreQueryCountBuckets []string
 	RequestDurationDispatchCountBuckets       []string
@@ -353,6 +685,14 @@ func (cfg *Config) VerifyServerSettings() error {
 		return fmt.Errorf("config 'maxConcurrentReadsForListUsers' cannot be 0")
 	}
 
+	if cfg.MaxChecksPerBatchCheck == 0 {
+		return fmt.Errorf("config 'maxChecksPerBatchCheck' cannot be 0")
+	}
+
+	if cfg.MaxConcurrentChecksPerBatchCheck == 0 {
+		return fmt.Errorf("config 'maxConcurrentChecksPerBatchCheck' cannot be 0")
+	}
+
 	if len(cfg.RequestDurationDatastoreQueryCountBuckets) == 0 {
 		return errors.New("request duration datastore query count buckets must not be empty")
 	}
@@ -468,6 +808,177 @@ func (cfg *Config) VerifyBinarySettings() error {
 		}
 	}
 
+	if cfg.RemoteCheckDispatch.Enabled {
+		if cfg.RemoteCheckDispatch.AdvertiseAddr == "" {
+			return errors.New("'remoteCheckDispatch.advertiseAddr' must be set")
+		}
+		if len(cfg.RemoteCheckDispatch.Peers) == 0 && cfg.RemoteCheckDispatch.DNSName == "" {
+			return errors.New("one of 'remoteCheckDispatch.peers' and 'remoteCheckDispatch.dnsName' must be set")
+		}
+		if len(cfg.RemoteCheckDispatch.Peers) > 0 && cfg.RemoteCheckDispatch.DNSName != "" {
+			return errors.New("'remoteCheckDispatch.peers' and 'remoteCheckDispatch.dnsName' are mutually exclusive")
+		}
+		if cfg.RemoteCheckDispatch.PeerRefreshInterval < 0 {
+			return errors.New("'remoteCheckDispatch.peerRefreshInterval' must be a non-negative time duration")
+		}
+		if cfg.RemoteCheckDispatch.PeerUnhealthyBackoff < 0 {
+			return errors.New("'remoteCheckDispatch.peerUnhealthyBackoff' must be a non-negative time duration")
+		}
+		if cfg.RemoteCheckDispatch.PresharedKey == "" && !cfg.RemoteCheckDispatch.TLS.Enabled {
+			return errors.New("one of 'remoteCheckDispatch.presharedKey' and 'remoteCheckDispatch.tls' must be set, so that the nodes of the cluster authenticate each other")
+		}
+		tlsConfig := cfg.RemoteCheckDispatch.TLS
+		if tlsConfig.Enabled && (tlsConfig.CertPath == "" || tlsConfig.KeyPath == "" || tlsConfig.CAPath == "") {
+			return errors.New("'remoteCheckDispatch.tls.cert', 'remoteCheckDispatch.tls.key' and 'remoteCheckDispatch.tls.ca' configs must be set")
+		}
+	}
+
+	if cfg.WatchChanges.HeartbeatInterval <= 0 {
+		return errors.New("'watchChanges.heartbeatInterval' must be a positive time duration")
+	}
+	if cfg.WatchChanges.MinPollInterval <= 0 || cfg.WatchChanges.MaxPollInterval < cfg.WatchChanges.MinPollInterval {
+		return errors.New("'watchChanges.minPollInterval' must be a positive time duration, not greater than 'watchChanges.maxPollInterval'")
+	}
+
+	if cfg.TupleExpiration.ReaperInterval < 0 {
+		return errors.New("'tupleExpiration.reaperInterval' must be a non-negative time duration")
+	}
+	if cfg.TupleExpiration.ReaperBatchSize <= 0 {
+		return errors.New("'tupleExpiration.reaperBatchSize' must be a positive integer")
+	}
+
+	if cfg.ChangelogRetention.MaxAge < 0 || cfg.ChangelogRetention.CompactAfter < 0 {
+		return errors.New("'changelogRetention.maxAge' and 'changelogRetention.compactAfter' must be non-negative time durations")
+	}
+	if cfg.ChangelogRetention.MaxCount < 0 {
+		return errors.New("'changelogRetention.maxCount' must be a non-negative integer")
+	}
+	for _, override := range cfg.ChangelogRetention.StoreOverrides {
+		if _, _, _, err := ParseChangelogStoreRetention(override); err != nil {
+			return fmt.Errorf("invalid 'changelogRetention.storeOverrides': %w", err)
+		}
+	}
+	if cfg.ChangelogRetention.PruneInterval <= 0 {
+		return errors.New("'changelogRetention.pruneInterval' must be a positive time duration")
+	}
+	if cfg.ChangelogRetention.PruneBatchSize <= 0 {
+		return errors.New("'changelogRetention.pruneBatchSize' must be a positive integer")
+	}
+
+	if cfg.StorePurge.Retention < 0 {
+		return errors.New("'storePurge.retention' must be a non-negative time duration")
+	}
+	if cfg.StorePurge.Interval <= 0 {
+		return errors.New("'storePurge.interval' must be a positive time duration")
+	}
+	if cfg.StorePurge.BatchSize <= 0 {
+		return errors.New("'storePurge.batchSize' must be a positive integer")
+	}
+
+	if cfg.StoreQuota.MaxTuples < 0 || cfg.StoreQuota.MaxAuthorizationModels < 0 || cfg.StoreQuota.MaxAssertions < 0 {
+		return errors.New("'storeQuota.maxTuples', 'storeQuota.maxAuthorizationModels' and 'storeQuota.maxAssertions' must be non-negative integers")
+	}
+	if cfg.StoreQuota.WriteRate < 0 || math.IsInf(cfg.StoreQuota.WriteRate, 0) || math.IsNaN(cfg.StoreQuota.WriteRate) {
+		return errors.New("'storeQuota.writeRate' must be a non-negative number")
+	}
+	for _, override := range cfg.StoreQuota.StoreOverrides {
+		if _, _, err := ParseStoreQuotaOverride(override); err != nil {
+			return fmt.Errorf("invalid 'storeQuota.storeOverrides': %w", err)
+		}
+	}
+
+	if cfg.Outbox.Enabled {
+		if cfg.Outbox.PollInterval <= 0 {
+			return errors.New("'outbox.pollInterval' must be a positive time duration")
+		}
+		if cfg.Outbox.BatchSize <= 0 {
+			return errors.New("'outbox.batchSize' must be a positive integer")
+		}
+		if !cfg.Outbox.Stdout && cfg.Outbox.FilePath == "" && cfg.Outbox.Webhook.URL == "" {
+			return errors.New("the outbox requires a sink: 'outbox.stdout', 'outbox.filePath' or 'outbox.webhook.url'")
+		}
+		if cfg.Outbox.Webhook.MaxRetries < 0 {
+			return errors.New("'outbox.webhook.maxRetries' must be a non-negative integer")
+		}
+		if cfg.Outbox.Webhook.Timeout <= 0 {
+			return errors.New("'outbox.webhook.timeout' must be a positive time duration")
+		}
+	}
+
+	if cfg.CheckDatastoreBatching.Enabled {
+		if cfg.CheckDatastoreBatching.Window <= 0 {
+			return errors.New("'checkDatastoreBatching.window' must be a positive time duration")
+		}
+		if cfg.CheckDatastoreBatching.MaxBatchSize <= 0 {
+			return errors.New("'checkDatastoreBatching.maxBatchSize' must be a positive integer")
+		}
+	}
+
+	if cfg.Datastore.Metrics.StoreLabelLimit < 0 {
+		return errors.New("'datastore.metrics.storeLabelLimit' must be a non-negative integer")
+	}
+
+	if len(cfg.Datastore.ReadReplicaURIs) > 0 {
+		if cfg.Datastore.Engine != "postgres" && cfg.Datastore.Engine != "mysql" {
+			return fmt.Errorf("'datastore.readReplicaURIs' is not supported by the '%s' datastore engine", cfg.Datastore.Engine)
+		}
+		if cfg.Datastore.ReplicaMaxLag <= 0 {
+			return errors.New("'datastore.replicaMaxLag' must be a positive time duration")
+		}
+		if cfg.Datastore.ReplicaCheckInterval <= 0 {
+			return errors.New("'datastore.replicaCheckInterval' must be a positive time duration")
+		}
+	}
+
+	if cfg.Datastore.Memory.Dir != "" {
+		if cfg.Datastore.Engine != "memory" {
+			return fmt.Errorf("'datastore.memory.dir' is not supported by the '%s' datastore engine", cfg.Datastore.Engine)
+		}
+		switch cfg.Datastore.Memory.Fsync {
+		case "always", "interval", "never":
+		default:
+			return fmt.Errorf("'datastore.memory.fsync' must be one of 'always', 'interval' or 'never', not '%s'", cfg.Datastore.Memory.Fsync)
+		}
+		if cfg.Datastore.Memory.SnapshotInterval <= 0 {
+			return errors.New("'datastore.memory.snapshotInterval' must be a positive time duration")
+		}
+	}
+
+	if len(cfg.Datastore.Shards) > 0 {
+		if cfg.Datastore.Engine == "memory" {
+			return errors.New("'datastore.shards' is not supported by the 'memory' datastore engine")
+		}
+		names := map[string]struct{}{"default": {}}
+		for _, shard := range cfg.Datastore.Shards {
+			name, _, err := ParseDatastoreShard(shard)
+			if err != nil {
+				return fmt.Errorf("invalid 'datastore.shards': %w", err)
+			}
+			if !shardNameRegex.MatchString(name) {
+				return fmt.Errorf("invalid 'datastore.shards': '%s' isn't a valid shard name", name)
+			}
+			if _, ok := names[name]; ok {
+				return fmt.Errorf("invalid 'datastore.shards': the shard '%s' is defined more than once", name)
+			}
+			names[name] = struct{}{}
+		}
+		if _, ok := names[cfg.Datastore.ShardPlacement]; !ok && cfg.Datastore.ShardPlacement != "hash" {
+			return fmt.Errorf("'datastore.shardPlacement' must be one of 'default', 'hash' or the name of a shard, not '%s'", cfg.Datastore.ShardPlacement)
+		}
+		if cfg.Datastore.ShardPlacementCacheTTL < 0 {
+			return errors.New("'datastore.shardPlacementCacheTTL' must be a non-negative time duration")
+		}
+	}
+
+	if cfg.Cache.Shared.Addr != "" {
+		if cfg.Cache.Shared.Timeout <= 0 {
+			return errors.New("'cache.shared.timeout' must be a positive time duration")
+		}
+		if cfg.Cache.Shared.Backoff < 0 {
+			return errors.New("'cache.shared.backoff' must be a non-negative time duration")
+		}
+	}
+
 	if cfg.RequestTimeout < 0 {
 		return errors.New("requestTimeout must be a non-negative time duration")
 	}
@@ -566,6 +1077,8 @@ func DefaultConfig() *Config {
 		MaxConcurrentReadsForCheck:                DefaultMaxConcurrentReadsForCheck,
 		MaxConcurrentReadsForListObjects:          DefaultMaxConcurrentReadsForListObjects,
 		MaxConcurrentReadsForListUsers:            DefaultMaxConcurrentReadsForListUsers,
+		MaxChecksPerBatchCheck:                    DefaultMaxChecksPerBatchCheck,
+		MaxConcurrentChecksPerBatchCheck:          DefaultMaxConcurrentChecksPerBatchCheck,
 		MaxConditionEvaluationCost:                DefaultMaxConditionEvaluationCost,
 		ChangelogHorizonOffset:                    DefaultChangelogHorizonOffset,
 		ResolveNodeLimit:                          DefaultResolveNodeLimit,
@@ -583,6 +1096,17 @@ func DefaultConfig() *Config {
 			MaxCacheSize: DefaultMaxAuthorizationModelCacheSize,
 			MaxIdleConns: 10,
 			MaxOpenConns: 30,
+
+			ReplicaMaxLag:        DefaultDatastoreReplicaMaxLag,
+			ReplicaCheckInterval: DefaultDatastoreReplicaCheckInterval,
+
+			Memory: DatastoreMemoryConfig{
+				Fsync:            DefaultDatastoreMemoryFsync,
+				SnapshotInterval: DefaultDatastoreMemorySnapshotInterval,
+			},
+
+			ShardPlacement:         DefaultDatastoreShardPlacement,
+			ShardPlacementCacheTTL: DefaultDatastoreShardPlacementCacheTTL,
 		},
 		GRPC: GRPCConfig{
 			Addr: "0.0.0.0:8081",
@@ -640,6 +1164,10 @@ func DefaultConfig() *Config {
 		},
 		Cache: CacheConfig{
 			Limit: DefaultCacheLimit,
+			Shared: SharedCacheConfig{
+				Timeout: DefaultSharedCacheTimeout,
+				Backoff: DefaultSharedCacheBackoff,
+			},
 		},
 		DispatchThrottling: DispatchThrottlingConfig{
 			Enabled:      DefaultCheckDispatchThrottlingEnabled,
@@ -665,6 +1193,48 @@ func DefaultConfig() *Config {
 			Threshold:    DefaultListUsersDispatchThrottlingDefaultThreshold,
 			MaxThreshold: DefaultListUsersDispatchThrottlingMaxThreshold,
 		},
+		RemoteCheckDispatch: RemoteCheckDispatchConfig{
+			Enabled:              DefaultRemoteCheckDispatchEnabled,
+			Addr:                 DefaultRemoteCheckDispatchAddr,
+			Peers:                []string{},
+			PeerRefreshInterval:  DefaultRemoteCheckDispatchPeerRefreshInterval,
+			PeerUnhealthyBackoff: DefaultRemoteCheckDispatchPeerUnhealthyBackoff,
+		},
+		WatchChanges: WatchChangesConfig{
+			HeartbeatInterval: DefaultWatchChangesHeartbeatInterval,
+			MinPollInterval:   DefaultWatchChangesMinPollInterval,
+			MaxPollInterval:   DefaultWatchChangesMaxPollInterval,
+		},
+		TupleExpiration: TupleExpirationConfig{
+			ReaperInterval:  DefaultTupleExpirationReaperInterval,
+			ReaperBatchSize: DefaultTupleExpirationReaperBatchSize,
+		},
+		ChangelogRetention: ChangelogRetentionConfig{
+			StoreOverrides: []string{},
+			PruneInterval:  DefaultChangelogRetentionPruneInterval,
+			PruneBatchSize: DefaultChangelogRetentionPruneBatchSize,
+		},
+		StorePurge: StorePurgeConfig{
+			Interval:  DefaultStorePurgeInterval,
+			BatchSize: DefaultStorePurgeBatchSize,
+		},
+		StoreQuota: StoreQuotaConfig{
+			StoreOverrides: []string{},
+		},
+		Outbox: OutboxConfig{
+			Enabled:      DefaultOutboxEnabled,
+			PollInterval: DefaultOutboxPollInterval,
+			BatchSize:    DefaultOutboxBatchSize,
+			Webhook: OutboxWebhookConfig{
+				MaxRetries: DefaultOutboxWebhookMaxRetries,
+				Timeout:    DefaultOutboxWebhookTimeout,
+			},
+		},
+		CheckDatastoreBatching: CheckDatastoreBatchingConfig{
+			Enabled:      DefaultCheckDatastoreBatchingEnabled,
+			Window:       DefaultCheckDatastoreBatchingWindow,
+			MaxBatchSize: DefaultCheckDatastoreBatchingMaxBatchSize,
+		},
 		RequestTimeout: DefaultRequestTimeout,
 	}
 }
@@ -402,6 +402,22 @@ func TestVerifyServerSettings(t *testing.T) {
 		require.EqualError(t, err, "config 'maxConcurrentReadsForListUsers' cannot be 0")
 	})
 
+	t.Run("maxChecksPerBatchCheck_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MaxChecksPerBatchCheck = 0
+
+		err := cfg.VerifyServerSettings()
+		require.EqualError(t, err, "config 'maxChecksPerBatchCheck' cannot be 0")
+	})
+
+	t.Run("maxConcurrentChecksPerBatchCheck_not_zero", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.MaxConcurrentChecksPerBatchCheck = 0
+
+		err := cfg.VerifyServerSettings()
+		require.EqualError(t, err, "config 'maxConcurrentChecksPerBatchCheck' cannot be 0")
+	})
+
 	t.Run("empty_request_duration_datastore_query_count_buckets", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.RequestDurationDatastoreQueryCountBuckets = []string{}
@@ -714,6 +730,292 @@ func TestVerifyBinarySettings(t *testing.T) {
 		require.Contains(t, err.Error(), "http.upstreamTimeout must be a non-negative time duration")
 	})
 
+	t.Run("remote_check_dispatch", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.RemoteCheckDispatch.Enabled = true
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'remoteCheckDispatch.advertiseAddr' must be set")
+
+		cfg.RemoteCheckDispatch.AdvertiseAddr = "10.0.0.1:8082"
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "one of 'remoteCheckDispatch.peers' and 'remoteCheckDispatch.dnsName' must be set")
+
+		cfg.RemoteCheckDispatch.Peers = []string{"10.0.0.1:8082", "10.0.0.2:8082"}
+		cfg.RemoteCheckDispatch.DNSName = "openfga-headless"
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'remoteCheckDispatch.peers' and 'remoteCheckDispatch.dnsName' are mutually exclusive")
+
+		cfg.RemoteCheckDispatch.DNSName = ""
+		cfg.RemoteCheckDispatch.PeerUnhealthyBackoff = -1 * time.Second
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'remoteCheckDispatch.peerUnhealthyBackoff' must be a non-negative time duration")
+
+		cfg.RemoteCheckDispatch.PeerUnhealthyBackoff = time.Second
+		err = cfg.VerifyBinarySettings()
+		require.ErrorContains(t, err, "one of 'remoteCheckDispatch.presharedKey' and 'remoteCheckDispatch.tls' must be set")
+
+		cfg.RemoteCheckDispatch.TLS.Enabled = true
+		cfg.RemoteCheckDispatch.TLS.CertPath = "/etc/openfga/node.crt"
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'remoteCheckDispatch.tls.cert', 'remoteCheckDispatch.tls.key' and 'remoteCheckDispatch.tls.ca' configs must be set")
+
+		cfg.RemoteCheckDispatch.TLS.KeyPath = "/etc/openfga/node.key"
+		cfg.RemoteCheckDispatch.TLS.CAPath = "/etc/openfga/ca.crt"
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.RemoteCheckDispatch.TLS = RemoteCheckDispatchTLSConfig{}
+		cfg.RemoteCheckDispatch.PresharedKey = "KEY"
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("watch_changes", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.WatchChanges.HeartbeatInterval = 0
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'watchChanges.heartbeatInterval' must be a positive time duration")
+
+		cfg.WatchChanges.HeartbeatInterval = time.Second
+		cfg.WatchChanges.MinPollInterval = 10 * time.Second
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'watchChanges.minPollInterval' must be a positive time duration, not greater than 'watchChanges.maxPollInterval'")
+
+		cfg.WatchChanges.MinPollInterval = time.Second
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_tuple_expiration_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.TupleExpiration.ReaperInterval = -time.Second
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'tupleExpiration.reaperInterval' must be a non-negative time duration")
+
+		cfg.TupleExpiration.ReaperInterval = 0
+		cfg.TupleExpiration.ReaperBatchSize = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'tupleExpiration.reaperBatchSize' must be a positive integer")
+
+		cfg.TupleExpiration.ReaperBatchSize = 1
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_changelog_retention_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ChangelogRetention.MaxCount = -1
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'changelogRetention.maxCount' must be a non-negative integer")
+
+		cfg.ChangelogRetention.MaxCount = 0
+		cfg.ChangelogRetention.StoreOverrides = []string{"01HVMMBCMGZNT3SED4Z17ECXCA:1h"}
+		err = cfg.VerifyBinarySettings()
+		require.ErrorContains(t, err, "isn't formatted as '<store id>:<max age>:<max count>'")
+
+		cfg.ChangelogRetention.StoreOverrides = []string{"01HVMMBCMGZNT3SED4Z17ECXCA:1h:"}
+		cfg.ChangelogRetention.PruneInterval = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'changelogRetention.pruneInterval' must be a positive time duration")
+
+		cfg.ChangelogRetention.PruneInterval = time.Minute
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_store_purge_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StorePurge.Retention = -time.Hour
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'storePurge.retention' must be a non-negative time duration")
+
+		cfg.StorePurge.Retention = 720 * time.Hour
+		cfg.StorePurge.BatchSize = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'storePurge.batchSize' must be a positive integer")
+
+		cfg.StorePurge.BatchSize = 1
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_store_quota_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StoreQuota.MaxTuples = -1
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'storeQuota.maxTuples', 'storeQuota.maxAuthorizationModels' and 'storeQuota.maxAssertions' must be non-negative integers")
+
+		cfg.StoreQuota.MaxTuples = 1000
+		cfg.StoreQuota.WriteRate = -1
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'storeQuota.writeRate' must be a non-negative number")
+
+		cfg.StoreQuota.WriteRate = 0.5
+		cfg.StoreQuota.StoreOverrides = []string{"01HVMMBCMGZNT3SED4Z17ECXCA:1000"}
+		err = cfg.VerifyBinarySettings()
+		require.ErrorContains(t, err, "isn't formatted as '<store id>:<max tuples>:<max authorization models>:<max assertions>:<write rate>'")
+
+		cfg.StoreQuota.StoreOverrides = []string{"01HVMMBCMGZNT3SED4Z17ECXCA:1000:::fast"}
+		err = cfg.VerifyBinarySettings()
+		require.ErrorContains(t, err, "has an invalid write rate")
+
+		cfg.StoreQuota.StoreOverrides = []string{"01HVMMBCMGZNT3SED4Z17ECXCA:1000:::50"}
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		storeID, quota, err := ParseStoreQuotaOverride("01HVMMBCMGZNT3SED4Z17ECXCA::10:5:2.5")
+		require.NoError(t, err)
+		require.Equal(t, "01HVMMBCMGZNT3SED4Z17ECXCA", storeID)
+		require.Equal(t, StoreQuotaConfig{MaxAuthorizationModels: 10, MaxAssertions: 5, WriteRate: 2.5}, quota)
+	})
+
+	t.Run("invalid_outbox_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Outbox.BatchSize = 0
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.Outbox.Enabled = true
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'outbox.batchSize' must be a positive integer")
+
+		cfg.Outbox.BatchSize = 100
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "the outbox requires a sink: 'outbox.stdout', 'outbox.filePath' or 'outbox.webhook.url'")
+
+		cfg.Outbox.Webhook.URL = "https://example.com/events"
+		cfg.Outbox.Webhook.MaxRetries = -1
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'outbox.webhook.maxRetries' must be a non-negative integer")
+
+		cfg.Outbox.Webhook.MaxRetries = 3
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_datastore_metrics_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.Metrics.StoreLabelLimit = -1
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.metrics.storeLabelLimit' must be a non-negative integer")
+
+		cfg.Datastore.Metrics.StoreLabelLimit = 100
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_check_datastore_batching_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.CheckDatastoreBatching.Window = 0
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.CheckDatastoreBatching.Enabled = true
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'checkDatastoreBatching.window' must be a positive time duration")
+
+		cfg.CheckDatastoreBatching.Window = time.Millisecond
+		cfg.CheckDatastoreBatching.MaxBatchSize = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'checkDatastoreBatching.maxBatchSize' must be a positive integer")
+
+		cfg.CheckDatastoreBatching.MaxBatchSize = 1
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_read_replicas_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.ReadReplicaURIs = []string{"postgres://replica:5432/openfga"}
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.readReplicaURIs' is not supported by the 'memory' datastore engine")
+
+		cfg.Datastore.Engine = "postgres"
+		cfg.Datastore.ReplicaMaxLag = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.replicaMaxLag' must be a positive time duration")
+
+		cfg.Datastore.ReplicaMaxLag = time.Second
+		cfg.Datastore.ReplicaCheckInterval = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.replicaCheckInterval' must be a positive time duration")
+
+		cfg.Datastore.ReplicaCheckInterval = time.Second
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
+	t.Run("invalid_durable_memory_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.Memory.Dir = "/var/lib/openfga"
+		cfg.Datastore.Memory.Fsync = "sometimes"
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.memory.fsync' must be one of 'always', 'interval' or 'never', not 'sometimes'")
+
+		cfg.Datastore.Memory.Fsync = "always"
+		cfg.Datastore.Memory.SnapshotInterval = 0
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.memory.snapshotInterval' must be a positive time duration")
+
+		cfg.Datastore.Memory.SnapshotInterval = time.Minute
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.Datastore.Engine = "postgres"
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.memory.dir' is not supported by the 'postgres' datastore engine")
+	})
+
+	t.Run("invalid_datastore_shards_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Datastore.Shards = []string{"eu=postgres://eu"}
+
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.shards' is not supported by the 'memory' datastore engine")
+
+		cfg.Datastore.Engine = "postgres"
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.Datastore.Shards = []string{"eu"}
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "invalid 'datastore.shards': a shard isn't formatted as '<name>=<uri>'")
+
+		cfg.Datastore.Shards = []string{"e/u=postgres://eu"}
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "invalid 'datastore.shards': 'e/u' isn't a valid shard name")
+
+		cfg.Datastore.Shards = []string{"default=postgres://eu"}
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "invalid 'datastore.shards': the shard 'default' is defined more than once")
+
+		cfg.Datastore.Shards = []string{"eu=postgres://eu", "us=postgres://us"}
+		cfg.Datastore.ShardPlacement = "ap"
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.shardPlacement' must be one of 'default', 'hash' or the name of a shard, not 'ap'")
+
+		for _, placement := range []string{"default", "hash", "us"} {
+			cfg.Datastore.ShardPlacement = placement
+			require.NoError(t, cfg.VerifyBinarySettings())
+		}
+
+		cfg.Datastore.ShardPlacementCacheTTL = -time.Second
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'datastore.shardPlacementCacheTTL' must be a non-negative time duration")
+	})
+
+	t.Run("invalid_shared_cache_config", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Cache.Shared.Timeout = 0
+		require.NoError(t, cfg.VerifyBinarySettings())
+
+		cfg.Cache.Shared.Addr = "localhost:6379"
+		err := cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'cache.shared.timeout' must be a positive time duration")
+
+		cfg.Cache.Shared.Timeout = time.Second
+		cfg.Cache.Shared.Backoff = -time.Second
+		err = cfg.VerifyBinarySettings()
+		require.EqualError(t, err, "'cache.shared.backoff' must be a non-negative time duration")
+
+		cfg.Cache.Shared.Backoff = 0
+		require.NoError(t, cfg.VerifyBinarySettings())
+	})
+
 	t.Run("invalid_log_level", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.Log.Level = "invalid_level"
@@ -0,0 +1,129 @@
+// Package storepurger contains the background purge of the data of the deleted stores.
+package storepurger
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var (
+	purgedRowsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "store_purge_deleted_rows_count",
+		Help:      "The total number of rows of the deleted stores (tuples, changes, authorization models and assertions) permanently deleted by the store purger.",
+	})
+
+	purgedStoresCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "store_purge_purged_stores_count",
+		Help:      "The total number of deleted stores purged by the store purger.",
+	})
+)
+
+// Purger periodically deletes, in batches, the data of the stores deleted longer ago than the retention, then the
+// stores themselves.
+type Purger struct {
+	datastore storage.StorePurger
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+	logger    logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New starts a Purger purging, every interval, the stores deleted longer ago than retention, batchSize rows at a
+// time. Close must be called to stop it.
+func New(datastore storage.StorePurger, retention, interval time.Duration, batchSize int, l logger.Logger) *Purger {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Purger{
+		datastore: datastore,
+		retention: retention,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    l,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *Purger) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge()
+		}
+	}
+}
+
+// purge purges the stores deleted before the retention, page after page, until a page isn't full.
+func (p *Purger) purge() {
+	deletedBefore := time.Now().Add(-p.retention)
+	for p.ctx.Err() == nil {
+		stores, err := p.datastore.ListDeletedStores(p.ctx, deletedBefore, storage.DefaultPageSize)
+		if err != nil {
+			if p.ctx.Err() == nil {
+				p.logger.Error("failed to list the deleted stores to purge", zap.Error(err))
+			}
+			return
+		}
+
+		for _, store := range stores {
+			if !p.purgeStore(store, deletedBefore) {
+				return
+			}
+		}
+
+		if len(stores) < storage.DefaultPageSize {
+			return
+		}
+	}
+}
+
+// purgeStore deletes the data of the store, batch after batch, until a batch isn't full, which means the store
+// itself was deleted. It reports whether it was.
+func (p *Purger) purgeStore(store string, deletedBefore time.Time) bool {
+	for p.ctx.Err() == nil {
+		deleted, err := p.datastore.PurgeStore(p.ctx, store, deletedBefore, p.batchSize)
+		if err != nil {
+			if p.ctx.Err() == nil {
+				p.logger.Error("failed to purge the deleted store", zap.String("store_id", store), zap.Error(err))
+			}
+			return false
+		}
+
+		purgedRowsCounter.Add(float64(deleted))
+		if deleted < p.batchSize {
+			purgedStoresCounter.Inc()
+			p.logger.Info("purged the deleted store", zap.String("store_id", store))
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the Purger, waiting for the batch being deleted if any.
+func (p *Purger) Close() {
+	p.cancel()
+	<-p.done
+}
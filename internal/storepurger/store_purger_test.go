@@ -0,0 +1,63 @@
+package storepurger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestPurger(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	stores := make([]string, 3)
+	for i := range stores {
+		stores[i] = ulid.Make().String()
+		_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: stores[i], Name: "store", CreatedAt: timestamppb.Now()})
+		require.NoError(t, err)
+
+		for j := 0; j < 5; j++ {
+			tk := tuple.NewTupleKey(fmt.Sprintf("document:%d", j), "viewer", "user:anne")
+			require.NoError(t, ds.Write(ctx, stores[i], nil, []*openfgav1.TupleKey{tk}))
+		}
+	}
+	require.NoError(t, ds.DeleteStore(ctx, stores[0]))
+	require.NoError(t, ds.DeleteStore(ctx, stores[1]))
+
+	purger := New(ds.(storage.StorePurger), 0, 10*time.Millisecond, 2, logger.NewNoopLogger())
+	t.Cleanup(purger.Close)
+
+	// the deleted stores are purged, though a batch only holds two rows
+	require.Eventually(t, func() bool {
+		deleted, err := ds.(storage.StorePurger).ListDeletedStores(ctx, time.Now(), storage.DefaultPageSize)
+		require.NoError(t, err)
+		return len(deleted) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	for _, store := range stores[:2] {
+		_, err := ds.ReadUserTuple(ctx, store, tuple.NewTupleKey("document:0", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	}
+
+	_, err := ds.GetStore(ctx, stores[2])
+	require.NoError(t, err)
+	_, err = ds.ReadUserTuple(ctx, stores[2], tuple.NewTupleKey("document:0", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,253 @@
+// Package storequota contains the quotas of the stores, which bound the resources a store can use.
+package storequota
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openfga/openfga/internal/build"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// modelsPageSize is the number of authorization models read at once to count the models of a store.
+	modelsPageSize = 100
+
+	// maxWriteLimiters is the number of write rate limiters kept before those which are full are evicted.
+	maxWriteLimiters = 10000
+)
+
+var quotaExceededCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "store_quota_exceeded_count",
+	Help:      "The total number of requests rejected because they exceeded the quota of their store, labeled by quota ('tuples', 'authorization_models', 'assertions' or 'write_rate').",
+}, []string{"quota"})
+
+// Quota bounds the resources of a store. A zero field doesn't bound them.
+type Quota struct {
+	// MaxTuples is the maximum number of tuples of the store.
+	MaxTuples int64
+	// MaxAuthorizationModels is the maximum number of authorization models of the store.
+	MaxAuthorizationModels int
+	// MaxAssertions is the maximum number of assertions of an authorization model of the store.
+	MaxAssertions int
+	// WriteRate is the maximum number of tuples written or deleted per second by the store, over bursts of up to a
+	// second of writes.
+	WriteRate float64
+}
+
+// IsBounded reports whether the quota bounds any resource.
+func (q Quota) IsBounded() bool {
+	return q.MaxTuples > 0 || q.MaxAuthorizationModels > 0 || q.MaxAssertions > 0 || q.WriteRate > 0
+}
+
+// Config is the configuration of an [Enforcer].
+type Config struct {
+	// Quota is the quota of the stores without one of their own.
+	Quota Quota
+	// StoreQuotas are the quotas of specific stores, by store ID, replacing Quota.
+	StoreQuotas map[string]Quota
+}
+
+// IsEnabled reports whether the configuration bounds the resources of any store.
+func (c Config) IsEnabled() bool {
+	if c.Quota.IsBounded() {
+		return true
+	}
+	for _, quota := range c.StoreQuotas {
+		if quota.IsBounded() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTupleQuotaEnabled reports whether the configuration bounds the number of tuples of any store, which requires
+// the datastore to count them.
+func (c Config) IsTupleQuotaEnabled() bool {
+	if c.Quota.MaxTuples > 0 {
+		return true
+	}
+	for _, quota := range c.StoreQuotas {
+		if quota.MaxTuples > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Enforcer checks the requests against the quotas of their store. The quotas are soft: requests running at once
+// are checked against the same state, so together they may exceed a quota (see CheckWrite for the tuple quota). Instances may be safely shared by
+// multiple goroutines.
+type Enforcer struct {
+	config  Config
+	counter storage.TupleCounter
+	now     func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*writeLimiter // GUARDED_BY(mu)
+}
+
+// New returns an Enforcer of the quotas of the config, counting the tuples of the stores with the counter. The
+// counter may be nil if the config doesn't bound the number of tuples of any store.
+func New(config Config, counter storage.TupleCounter) (*Enforcer, error) {
+	if counter == nil && config.IsTupleQuotaEnabled() {
+		return nil, fmt.Errorf("the store tuple quotas require a datastore which counts the tuples of the stores")
+	}
+
+	return &Enforcer{
+		config:   config,
+		counter:  counter,
+		now:      time.Now,
+		limiters: make(map[string]*writeLimiter),
+	}, nil
+}
+
+// Quota returns the quota of the store.
+func (e *Enforcer) Quota(store string) Quota {
+	if quota, ok := e.config.StoreQuotas[store]; ok {
+		return quota
+	}
+	return e.config.Quota
+}
+
+// CheckWrite checks a write of the store, which adds at most added tuples to it, and writes or deletes written
+// tuples. It returns a ResourceExhausted error if it would take the store over its tuple quota, or if the store
+// writes faster than its write rate.
+//
+// The tuple quota is a soft limit: the count is read before, and outside of, the transaction of the write, so the
+// writes of the store checked at once, by all the servers, may all pass. A store may thus exceed its quota by up to
+// the number of its writes running at once times the maximum number of tuples per write (--max-tuples-per-write).
+// It doesn't grow past that: once the store is over its quota, the writes adding tuples are rejected until it is
+// back under it.
+func (e *Enforcer) CheckWrite(ctx context.Context, store string, added int, written int) error {
+	quota := e.Quota(store)
+
+	if quota.MaxTuples > 0 && added > 0 {
+		count, err := e.counter.CountTuples(ctx, store)
+		if err != nil {
+			return serverErrors.HandleError("", err)
+		}
+		if count+int64(added) > quota.MaxTuples {
+			quotaExceededCounter.WithLabelValues("tuples").Inc()
+			return serverErrors.StoreTupleQuotaExceeded(quota.MaxTuples)
+		}
+	}
+
+	if quota.WriteRate > 0 && !e.allowWrite(store, quota.WriteRate, written) {
+		quotaExceededCounter.WithLabelValues("write_rate").Inc()
+		return serverErrors.StoreWriteRateExceeded(quota.WriteRate)
+	}
+	return nil
+}
+
+// CheckAuthorizationModelWrite checks a write of an authorization model of the store. It returns a
+// ResourceExhausted error if the store already has as many authorization models as its quota.
+func (e *Enforcer) CheckAuthorizationModelWrite(ctx context.Context, store string, backend storage.AuthorizationModelReadBackend) error {
+	maxModels := e.Quota(store).MaxAuthorizationModels
+	if maxModels <= 0 {
+		return nil
+	}
+
+	count := 0
+	var continuationToken string
+	for {
+		models, token, err := backend.ReadAuthorizationModels(ctx, store, storage.ReadAuthorizationModelsOptions{
+			Pagination: storage.NewPaginationOptions(modelsPageSize, continuationToken),
+		})
+		if err != nil {
+			return serverErrors.HandleError("", err)
+		}
+
+		count += len(models)
+		if count >= maxModels {
+			quotaExceededCounter.WithLabelValues("authorization_models").Inc()
+			return serverErrors.StoreAuthorizationModelQuotaExceeded(maxModels)
+		}
+
+		continuationToken = string(token)
+		if continuationToken == "" {
+			return nil
+		}
+	}
+}
+
+// CheckAssertions checks the number of assertions written for an authorization model of the store. It returns a
+// ResourceExhausted error if it exceeds the assertion quota of the store.
+func (e *Enforcer) CheckAssertions(store string, assertions int) error {
+	maxAssertions := e.Quota(store).MaxAssertions
+	if maxAssertions > 0 && assertions > maxAssertions {
+		quotaExceededCounter.WithLabelValues("assertions").Inc()
+		return serverErrors.StoreAssertionQuotaExceeded(maxAssertions)
+	}
+	return nil
+}
+
+// allowWrite takes n tokens from the write limiter of the store, and reports whether it had enough of them.
+func (e *Enforcer) allowWrite(store string, rate float64, n int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+
+	limiter, ok := e.limiters[store]
+	if !ok || limiter.rate != rate {
+		// evict the full limiters now and then, rather than keeping those of every store ever seen
+		if len(e.limiters) >= maxWriteLimiters {
+			for id, l := range e.limiters {
+				if l.isFull(now) {
+					delete(e.limiters, id)
+				}
+			}
+		}
+
+		limiter = newWriteLimiter(rate, now)
+		e.limiters[store] = limiter
+	}
+	return limiter.allow(now, n)
+}
+
+// writeLimiter is a token bucket refilled with rate tokens per second, holding up to a second of them.
+type writeLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newWriteLimiter(rate float64, now time.Time) *writeLimiter {
+	burst := math.Max(math.Ceil(rate), 1)
+	return &writeLimiter{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+func (l *writeLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// allow takes n tokens, or the whole burst if n exceeds it so that the writes bigger than the burst can still go
+// through, and reports whether there were enough of them.
+func (l *writeLimiter) allow(now time.Time, n int) bool {
+	l.refill(now)
+
+	needed := math.Min(float64(n), l.burst)
+	if l.tokens < needed {
+		return false
+	}
+	l.tokens -= needed
+	return true
+}
+
+func (l *writeLimiter) isFull(now time.Time) bool {
+	l.refill(now)
+	return l.tokens >= l.burst
+}
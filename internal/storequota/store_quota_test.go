@@ -0,0 +1,108 @@
+package storequota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Quota: Quota{MaxAuthorizationModels: 1}}, nil)
+	require.NoError(t, err)
+
+	_, err = New(Config{StoreQuotas: map[string]Quota{"store": {MaxTuples: 1}}}, nil)
+	require.ErrorContains(t, err, "require a datastore which counts the tuples")
+}
+
+func TestEnforcer(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	store := ulid.Make().String()
+	other := ulid.Make().String()
+
+	enforcer, err := New(Config{
+		Quota: Quota{MaxTuples: 2, MaxAuthorizationModels: 1, MaxAssertions: 1},
+		StoreQuotas: map[string]Quota{
+			other: {WriteRate: 2},
+		},
+	}, ds.(storage.TupleCounter))
+	require.NoError(t, err)
+
+	now := time.Now()
+	enforcer.now = func() time.Time { return now }
+
+	requireResourceExhausted := func(t *testing.T, err error, expected error) {
+		t.Helper()
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Equal(t, expected, err)
+	}
+
+	t.Run("store_overrides_replace_the_quota", func(t *testing.T) {
+		require.Equal(t, Quota{MaxTuples: 2, MaxAuthorizationModels: 1, MaxAssertions: 1}, enforcer.Quota(store))
+		require.Equal(t, Quota{WriteRate: 2}, enforcer.Quota(other))
+	})
+
+	t.Run("tuples", func(t *testing.T) {
+		require.NoError(t, enforcer.CheckWrite(ctx, store, 2, 2))
+		require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("doc:2", "viewer", "user:anne"),
+		}))
+
+		requireResourceExhausted(t, enforcer.CheckWrite(ctx, store, 1, 1), serverErrors.StoreTupleQuotaExceeded(2))
+
+		// the writes which don't add any tuples are allowed
+		require.NoError(t, enforcer.CheckWrite(ctx, store, 0, 2))
+		require.NoError(t, enforcer.CheckWrite(ctx, other, 100, 0))
+	})
+
+	t.Run("authorization_models", func(t *testing.T) {
+		require.NoError(t, enforcer.CheckAuthorizationModelWrite(ctx, store, ds))
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, store, testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user`)))
+
+		requireResourceExhausted(t, enforcer.CheckAuthorizationModelWrite(ctx, store, ds), serverErrors.StoreAuthorizationModelQuotaExceeded(1))
+		require.NoError(t, enforcer.CheckAuthorizationModelWrite(ctx, other, ds))
+	})
+
+	t.Run("assertions", func(t *testing.T) {
+		require.NoError(t, enforcer.CheckAssertions(store, 1))
+		requireResourceExhausted(t, enforcer.CheckAssertions(store, 2), serverErrors.StoreAssertionQuotaExceeded(1))
+		require.NoError(t, enforcer.CheckAssertions(other, 2))
+	})
+
+	t.Run("write_rate", func(t *testing.T) {
+		require.NoError(t, enforcer.CheckWrite(ctx, other, 1, 1))
+		require.NoError(t, enforcer.CheckWrite(ctx, other, 1, 1))
+		requireResourceExhausted(t, enforcer.CheckWrite(ctx, other, 1, 1), serverErrors.StoreWriteRateExceeded(2))
+
+		// a second later the burst is available again, and a bigger write takes all of it
+		now = now.Add(time.Second)
+		require.NoError(t, enforcer.CheckWrite(ctx, other, 0, 10))
+		requireResourceExhausted(t, enforcer.CheckWrite(ctx, other, 1, 1), serverErrors.StoreWriteRateExceeded(2))
+
+		now = now.Add(500 * time.Millisecond)
+		require.NoError(t, enforcer.CheckWrite(ctx, other, 1, 1))
+
+		// the stores without a write rate are not limited
+		for i := 0; i < 10; i++ {
+			require.NoError(t, enforcer.CheckWrite(ctx, store, 0, 1))
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Package tuplereaper contains the background deletion of the expired tuples.
+package tuplereaper
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var reapedTuplesCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "expired_tuples_deleted_count",
+	Help:      "The total number of expired tuples deleted by the tuple expiration reaper.",
+})
+
+// Reaper periodically deletes the expired tuples of the datastore, in batches, until none are left.
+type Reaper struct {
+	datastore storage.TupleReaper
+	interval  time.Duration
+	batchSize int
+	logger    logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New starts a Reaper deleting the expired tuples every interval, batchSize tuples at a time. Close must be called
+// to stop it.
+func New(datastore storage.TupleReaper, interval time.Duration, batchSize int, l logger.Logger) *Reaper {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Reaper{
+		datastore: datastore,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    l,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+func (r *Reaper) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.reap()
+		}
+	}
+}
+
+// reap deletes the tuples expired by now, batch after batch, until a batch isn't full.
+func (r *Reaper) reap() {
+	now := time.Now()
+	for r.ctx.Err() == nil {
+		deleted, err := r.datastore.DeleteExpiredTuples(r.ctx, now, r.batchSize)
+		if err != nil {
+			if r.ctx.Err() == nil {
+				r.logger.Error("failed to delete the expired tuples", zap.Error(err))
+			}
+			return
+		}
+
+		reapedTuplesCounter.Add(float64(deleted))
+		if deleted < r.batchSize {
+			return
+		}
+	}
+}
+
+// Close stops the Reaper, waiting for the batch being deleted if any.
+func (r *Reaper) Close() {
+	r.cancel()
+	<-r.done
+}
@@ -0,0 +1,52 @@
+package tuplereaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestReaper(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	tks := []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+	}
+	require.NoError(t, ds.Write(ctx, "store", nil, tks[:1]))
+	require.NoError(t, ds.Write(ctx, "store", nil, tks[1:], storage.WithExpiresAt(time.Now().Add(50*time.Millisecond))))
+
+	reaper := New(ds.(storage.TupleReaper), 10*time.Millisecond, 1, logger.NewNoopLogger())
+	t.Cleanup(reaper.Close)
+
+	// the expired tuples are all deleted, though a batch only holds one
+	require.Eventually(t, func() bool {
+		changes, _, err := ds.ReadChanges(ctx, "store", storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		})
+		require.NoError(t, err)
+		return len(changes) == len(tks)+2
+	}, time.Second, 10*time.Millisecond)
+
+	tuples, _, err := ds.ReadPage(ctx, "store", nil, storage.ReadPageOptions{
+		Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+	})
+	require.NoError(t, err)
+	require.Len(t, tuples, 1)
+}
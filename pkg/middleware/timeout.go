@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	grpcvalidator "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
@@ -38,10 +39,14 @@ func (h *TimeoutInterceptor) NewUnaryTimeoutInterceptor() grpc.UnaryServerInterc
 
 // NewStreamTimeoutInterceptor returns an interceptor that will timeout according to the configured timeout.
 // We need to use this middleware instead of relying on runtime.DefaultContextTimeout to allow us
-// to return proper error code.
-func (h *TimeoutInterceptor) NewStreamTimeoutInterceptor() grpc.StreamServerInterceptor {
+// to return proper error code. The streams of longLivedMethods (full method names) are not timed out.
+func (h *TimeoutInterceptor) NewStreamTimeoutInterceptor(longLivedMethods ...string) grpc.StreamServerInterceptor {
 	validator := grpcvalidator.StreamServerInterceptor()
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info != nil && slices.Contains(longLivedMethods, info.FullMethod) {
+			return handler(srv, stream)
+		}
+
 		return validator(srv, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
 			ctx, cancel := context.WithTimeout(stream.Context(), h.timeout)
 			defer cancel()
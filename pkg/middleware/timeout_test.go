@@ -73,3 +73,23 @@ func TestNewStreamTimeoutInterceptor(t *testing.T) {
 	err := interceptor(nil, mockServerGRPCStream{ctx: context.Background()}, nil, handler)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
+
+func TestNewStreamTimeoutInterceptorLongLivedMethod(t *testing.T) {
+	timeoutInterceptor := TimeoutInterceptor{
+		timeout: 5 * time.Millisecond,
+		logger:  logger.NewNoopLogger(),
+	}
+
+	handler := func(srv any, stream grpc.ServerStream) error {
+		ctx := stream.Context()
+		select {
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	interceptor := timeoutInterceptor.NewStreamTimeoutInterceptor("/test.Service/Watch")
+	err := interceptor(nil, mockServerGRPCStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}, handler)
+	require.NoError(t, err)
+}
@@ -0,0 +1,192 @@
+// Package outbox contains the delivery of the events of the outbox of a datastore to pluggable sinks.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var (
+	deliveryLagHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "outbox_delivery_lag_ms",
+		Help:                            "The time (in milliseconds) between the recording of an event in the outbox and its delivery to a sink, labeled by sink.",
+		Buckets:                         []float64{10, 50, 100, 500, 1000, 5000, 10000, 60000, 300000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"sink"})
+
+	deliveredEventsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "outbox_events_delivered_count",
+		Help:      "The total number of events of the outbox delivered to a sink, labeled by sink.",
+	}, []string{"sink"})
+
+	deliveryFailuresCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "outbox_delivery_failures_count",
+		Help:      "The total number of failed deliveries of a batch of events of the outbox to a sink, labeled by sink.",
+	}, []string{"sink"})
+)
+
+// EventSink receives the events of the outbox.
+type EventSink interface {
+	// Name identifies the sink in the logs and metrics.
+	Name() string
+	// Send delivers the events, oldest first. If it fails, the events are sent again later, so a sink may receive
+	// an event more than once.
+	Send(ctx context.Context, events []*storage.Event) error
+}
+
+// Config is the configuration of a [Dispatcher].
+type Config struct {
+	// PollInterval is how often the outbox is read when it was found empty, or a sink failed.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of events read from the outbox, and sent to the sinks, at once.
+	BatchSize int
+}
+
+// Dispatcher delivers the events of the outbox to all its sinks, at least once, and deletes them from the outbox
+// once they all received them.
+type Dispatcher struct {
+	outbox storage.EventOutbox
+	sinks  []EventSink
+	config Config
+	logger logger.Logger
+
+	// delivered are the IDs of the events each sink received which are still in the outbox, so that the sinks which
+	// received a batch don't receive it again while another sink fails it
+	delivered []map[string]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New starts a Dispatcher delivering the events of the outbox to the sinks, per the config. Close must be called
+// to stop it.
+func New(outbox storage.EventOutbox, sinks []EventSink, config Config, l logger.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		outbox:    outbox,
+		sinks:     sinks,
+		config:    config,
+		logger:    l,
+		delivered: make([]map[string]struct{}, len(sinks)),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	for i := range d.delivered {
+		d.delivered[i] = make(map[string]struct{})
+	}
+	go d.run()
+	return d
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatch()
+		}
+	}
+}
+
+// dispatch delivers the events of the outbox, batch after batch, until it is empty or a sink fails.
+func (d *Dispatcher) dispatch() {
+	for d.ctx.Err() == nil {
+		events, err := d.outbox.ReadOutbox(d.ctx, d.config.BatchSize)
+		if err != nil {
+			if d.ctx.Err() == nil {
+				d.logger.Error("failed to read the outbox", zap.Error(err))
+			}
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		if !d.deliver(events) {
+			return
+		}
+
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		if err := d.outbox.DeleteOutboxEvents(d.ctx, ids); err != nil {
+			if d.ctx.Err() == nil {
+				d.logger.Error("failed to delete the delivered events from the outbox", zap.Error(err))
+			}
+			return
+		}
+		for _, delivered := range d.delivered {
+			for _, id := range ids {
+				delete(delivered, id)
+			}
+		}
+
+		if len(events) < d.config.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends the events to the sinks which didn't receive them yet, and reports whether they all have.
+func (d *Dispatcher) deliver(events []*storage.Event) bool {
+	ok := true
+	for i, sink := range d.sinks {
+		name := sink.Name()
+		delivered := d.delivered[i]
+
+		pending := make([]*storage.Event, 0, len(events))
+		for _, event := range events {
+			if _, sent := delivered[event.ID]; !sent {
+				pending = append(pending, event)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		if err := sink.Send(d.ctx, pending); err != nil {
+			ok = false
+			if d.ctx.Err() == nil {
+				deliveryFailuresCounter.WithLabelValues(name).Inc()
+				d.logger.Error("failed to send the events of the outbox", zap.String("sink", name), zap.Error(err))
+			}
+			continue
+		}
+
+		now := time.Now()
+		for _, event := range pending {
+			delivered[event.ID] = struct{}{}
+			deliveryLagHistogram.WithLabelValues(name).Observe(float64(now.Sub(event.Time).Milliseconds()))
+		}
+		deliveredEventsCounter.WithLabelValues(name).Add(float64(len(pending)))
+	}
+	return ok
+}
+
+// Close stops the Dispatcher, waiting for the batch being delivered if any.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	<-d.done
+}
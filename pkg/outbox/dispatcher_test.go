@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// recordingSink records the events it receives, failing the first failures batches.
+type recordingSink struct {
+	name     string
+	failures int
+
+	mu     sync.Mutex
+	events []*storage.Event
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Send(_ context.Context, events []*storage.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *recordingSink) received() []*storage.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New(memory.WithOutbox())
+	t.Cleanup(ds.Close)
+	outbox := ds.(storage.EventOutbox)
+
+	tks := []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+	}
+	for _, tk := range tks {
+		require.NoError(t, ds.Write(ctx, "store", nil, []*openfgav1.TupleKey{tk}))
+	}
+
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", failures: 2}
+	dispatcher := New(outbox, []EventSink{healthy, flaky}, Config{PollInterval: 10 * time.Millisecond, BatchSize: 2}, logger.NewNoopLogger())
+	t.Cleanup(dispatcher.Close)
+
+	// the events are deleted once both sinks received them
+	require.Eventually(t, func() bool {
+		events, err := outbox.ReadOutbox(ctx, 10)
+		require.NoError(t, err)
+		return len(events) == 0 && len(flaky.received()) == len(tks)
+	}, time.Second, 10*time.Millisecond)
+
+	// the healthy sink didn't receive again the events the flaky one failed
+	for _, sink := range []*recordingSink{healthy, flaky} {
+		events := sink.received()
+		require.Len(t, events, len(tks))
+		for i, event := range events {
+			require.Equal(t, tks[i].GetObject(), event.TupleChanges[0].Object)
+		}
+	}
+}
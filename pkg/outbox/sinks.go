@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// SignatureHeader is the header of the requests of a [WebhookSink] holding the signature of their body, as
+	// 'sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret>'.
+	SignatureHeader = "X-OpenFGA-Signature"
+	// TimestampHeader is the header of the requests of a [WebhookSink] holding the time they were signed, in Unix
+	// seconds, so that the receivers can reject the replayed ones.
+	TimestampHeader = "X-OpenFGA-Timestamp"
+)
+
+// WriterSink writes the events to a writer, as JSON lines.
+type WriterSink struct {
+	name   string
+	writer io.Writer
+}
+
+var _ EventSink = (*WriterSink)(nil)
+
+// NewWriterSink returns a sink named name writing the events to the writer.
+func NewWriterSink(name string, writer io.Writer) *WriterSink {
+	return &WriterSink{name: name, writer: writer}
+}
+
+// NewStdoutSink returns a sink writing the events to the standard output.
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink("stdout", os.Stdout)
+}
+
+// Name see [EventSink].Name.
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+// Send see [EventSink].Send.
+func (s *WriterSink) Send(_ context.Context, events []*storage.Event) error {
+	return writeJSONLines(s.writer, events)
+}
+
+// FileSink appends the events to a file, as JSON lines.
+type FileSink struct {
+	path string
+}
+
+var _ EventSink = (*FileSink)(nil)
+
+// NewFileSink returns a sink appending the events to the file at path, which is created if needed.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Name see [EventSink].Name.
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Send see [EventSink].Send. The file is opened for each batch, so that it can be rotated.
+func (s *FileSink) Send(_ context.Context, events []*storage.Event) error {
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONLines(file, events); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeJSONLines writes the events to the writer, one JSON object per line, at once.
+func writeJSONLines(writer io.Writer, events []*storage.Event) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	_, err := writer.Write(buf.Bytes())
+	return err
+}
+
+// WebhookConfig is the configuration of a [WebhookSink].
+type WebhookConfig struct {
+	// URL is the endpoint the events are posted to.
+	URL string
+	// Secret is the key of the signature of the requests. If empty, they aren't signed.
+	Secret string
+	// MaxRetries is how many times a failed request is retried before the batch fails.
+	MaxRetries int
+	// Timeout bounds each request.
+	Timeout time.Duration
+}
+
+// WebhookSink posts the events to an HTTP endpoint, as a JSON object '{"events": [...]}', signed per
+// [SignatureHeader]. The requests failing on the network, or with a 429 or 5xx status, are retried with an
+// exponential backoff.
+type WebhookSink struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+var _ EventSink = (*WebhookSink)(nil)
+
+// NewWebhookSink returns a sink posting the events per the config.
+func NewWebhookSink(config WebhookConfig) *WebhookSink {
+	return &WebhookSink{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Name see [EventSink].Name.
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// webhookBody is the body of the requests of a WebhookSink.
+type webhookBody struct {
+	Events []*storage.Event `json:"events"`
+}
+
+// Send see [EventSink].Send.
+func (s *WebhookSink) Send(ctx context.Context, events []*storage.Event) error {
+	body, err := json.Marshal(webhookBody{Events: events})
+	if err != nil {
+		return err
+	}
+
+	policy := backoff.NewExponentialBackOff()
+	policy.InitialInterval = 100 * time.Millisecond
+	policy.MaxElapsedTime = 0
+	return backoff.Retry(func() error {
+		return s.post(ctx, body)
+	}, backoff.WithContext(backoff.WithMaxRetries(policy, uint64(s.config.MaxRetries)), ctx))
+}
+
+// post makes one request with the body, returning a permanent error unless it should be retried.
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(s.config.Secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return backoff.Permanent(fmt.Errorf("webhook responded with status %d", resp.StatusCode))
+	}
+}
+
+// Sign returns the value of the [SignatureHeader] of a request of a [WebhookSink] with the body, signed at the
+// timestamp with the secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func testEvents() []*storage.Event {
+	return []*storage.Event{
+		storage.NewStoreDeletedEvent("store1"),
+		storage.NewAuthorizationModelWrittenEvent("store2", "model"),
+	}
+}
+
+// readJSONLines decodes the events of the JSON lines.
+func readJSONLines(t *testing.T, r io.Reader) []*storage.Event {
+	t.Helper()
+	var events []*storage.Event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var event storage.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, &event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink("buffer", &buf)
+	require.Equal(t, "buffer", sink.Name())
+
+	events := testEvents()
+	require.NoError(t, sink.Send(context.Background(), events))
+
+	written := readJSONLines(t, &buf)
+	require.Len(t, written, 2)
+	require.Equal(t, events[0].ID, written[0].ID)
+	require.Equal(t, "model", written[1].AuthorizationModelID)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileSink(path)
+
+	events := testEvents()
+	require.NoError(t, sink.Send(context.Background(), events[:1]))
+	require.NoError(t, sink.Send(context.Background(), events[1:]))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	written := readJSONLines(t, file)
+	require.Len(t, written, 2)
+	require.Equal(t, events[0].ID, written[0].ID)
+	require.Equal(t, events[1].ID, written[1].ID)
+}
+
+func TestWebhookSink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("posts_the_signed_events", func(t *testing.T) {
+		var received webhookBody
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, Sign("secret", r.Header.Get(TimestampHeader), body), r.Header.Get(SignatureHeader))
+			require.NoError(t, json.Unmarshal(body, &received))
+		}))
+		t.Cleanup(server.Close)
+
+		sink := NewWebhookSink(WebhookConfig{URL: server.URL, Secret: "secret", MaxRetries: 1, Timeout: time.Second})
+		require.Equal(t, "webhook", sink.Name())
+
+		events := testEvents()
+		require.NoError(t, sink.Send(ctx, events))
+		require.Len(t, received.Events, 2)
+		require.Equal(t, events[1].ID, received.Events[1].ID)
+	})
+
+	t.Run("retries_the_unavailable_endpoints", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch requests.Add(1) {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		sink := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: 2, Timeout: time.Second})
+		require.NoError(t, sink.Send(ctx, testEvents()))
+		require.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("fails_after_the_max_retries", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		sink := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: 2, Timeout: time.Second})
+		require.ErrorContains(t, sink.Send(ctx, testEvents()), "status 500")
+		require.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("doesn't_retry_the_rejected_requests", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(server.Close)
+
+		sink := NewWebhookSink(WebhookConfig{URL: server.URL, MaxRetries: 2, Timeout: time.Second})
+		require.ErrorContains(t, sink.Send(ctx, testEvents()), "status 400")
+		require.Equal(t, int32(1), requests.Load())
+	})
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/internal/utils"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// BatchCheck resolves many checks against the same store and authorization model in a single request.
+// The checks share datastore reads and Check subproblems, so a batch is cheaper than the equivalent
+// number of individual Check calls. Each result is keyed by the correlation ID of its check.
+func (s *Server) BatchCheck(ctx context.Context, req *BatchCheckRequest) (*BatchCheckResponse, error) {
+	start := time.Now()
+
+	ctx, span := tracer.Start(ctx, authz.BatchCheck, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.Int("checks", len(req.GetChecks())),
+		attribute.String("consistency", req.GetConsistency().String()),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.BatchCheck,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.BatchCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := commands.NewBatchCheckCommand(
+		s.checkDatastore,
+		s.checkResolver,
+		typesys,
+		commands.WithBatchCheckCommandLogger(s.logger),
+		commands.WithBatchCheckCommandResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithBatchCheckMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithBatchCheckMaxChecksPerBatch(s.maxChecksPerBatchCheck),
+		commands.WithBatchCheckMaxConcurrentChecks(s.maxConcurrentChecksPerBatchCheck),
+		commands.WithBatchCheckCacheController(s.cacheController),
+	)
+
+	checks := make([]*commands.BatchCheckItem, 0, len(req.GetChecks()))
+	for _, check := range req.GetChecks() {
+		checks = append(checks, &commands.BatchCheckItem{
+			TupleKey:         check.GetTupleKey(),
+			ContextualTuples: check.GetContextualTuples(),
+			Context:          check.GetContext(),
+			CorrelationID:    commands.CorrelationID(check.GetCorrelationId()),
+		})
+	}
+
+	outcomes, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
+		StoreID:     req.GetStoreId(),
+		Checks:      checks,
+		Consistency: req.GetConsistency(),
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		var validationError *commands.BatchCheckValidationError
+		if errors.As(err, &validationError) {
+			return nil, serverErrors.ValidationError(err)
+		}
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	const methodName = "batchcheck"
+
+	result := make(map[string]*BatchCheckSingleResult, len(outcomes))
+	for correlationID, outcome := range outcomes {
+		if outcome.Err != nil {
+			encodedCode := serverErrors.ConvertToEncodedErrorCode(status.Convert(commands.CheckCommandErrorToServerError(outcome.Err)))
+			result[string(correlationID)] = &BatchCheckSingleResult{
+				Error: &BatchCheckError{
+					Code:    encodedCode,
+					Message: outcome.Err.Error(),
+				},
+			}
+			continue
+		}
+
+		allowed := outcome.CheckResponse.GetAllowed()
+		result[string(correlationID)] = &BatchCheckSingleResult{Allowed: allowed}
+		checkResultCounter.With(prometheus.Labels{allowedLabel: strconv.FormatBool(allowed)}).Inc()
+	}
+
+	queryCount := float64(metadata.DatastoreQueryCount)
+	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, queryCount)
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, queryCount))
+	datastoreQueryCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(queryCount)
+
+	dispatchCount := float64(metadata.DispatchCount)
+	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+	dispatchCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(dispatchCount)
+
+	requestDurationHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+		utils.Bucketize(uint(metadata.DatastoreQueryCount), s.requestDurationByQueryHistogramBuckets),
+		utils.Bucketize(uint(metadata.DispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
+		req.GetConsistency().String(),
+	).Observe(float64(time.Since(start).Milliseconds()))
+
+	if metadata.WasThrottled {
+		throttledRequestCounter.WithLabelValues(s.serviceName, methodName).Inc()
+	}
+
+	return &BatchCheckResponse{Result: result}, nil
+}
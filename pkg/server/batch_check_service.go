@@ -0,0 +1,308 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	batchCheckServiceName = "openfga.v1.BatchCheckService"
+	batchCheckFullMethod  = "/" + batchCheckServiceName + "/BatchCheck"
+	batchCheckHTTPPath    = "/stores/{store_id}/batch-check"
+)
+
+// BatchCheckRequest is the request of the BatchCheck RPC. Its messages are not part of the openfga/api
+// protobuf definitions, so it is served by a JSON encoded gRPC service (see jsoncodec).
+type BatchCheckRequest struct {
+	StoreId              string //nolint:revive,stylecheck // named after the protobuf getters
+	AuthorizationModelId string //nolint:revive,stylecheck
+	Checks               []*BatchCheckItem
+	Consistency          openfgav1.ConsistencyPreference
+}
+
+type BatchCheckItem struct {
+	TupleKey         *openfgav1.CheckRequestTupleKey
+	ContextualTuples *openfgav1.ContextualTupleKeys
+	Context          *structpb.Struct
+	CorrelationId    string //nolint:revive,stylecheck
+}
+
+type BatchCheckResponse struct {
+	Result map[string]*BatchCheckSingleResult `json:"result"`
+}
+
+// BatchCheckSingleResult is the outcome of one check of the batch. If Error is set, Allowed is meaningless.
+type BatchCheckSingleResult struct {
+	Allowed bool             `json:"allowed"`
+	Error   *BatchCheckError `json:"error,omitempty"`
+}
+
+type BatchCheckError struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+func (r *BatchCheckRequest) GetStoreId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.StoreId
+}
+
+func (r *BatchCheckRequest) GetAuthorizationModelId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.AuthorizationModelId
+}
+
+func (r *BatchCheckRequest) GetChecks() []*BatchCheckItem {
+	if r == nil {
+		return nil
+	}
+	return r.Checks
+}
+
+func (r *BatchCheckRequest) GetConsistency() openfgav1.ConsistencyPreference {
+	if r == nil {
+		return openfgav1.ConsistencyPreference_UNSPECIFIED
+	}
+	return r.Consistency
+}
+
+func (i *BatchCheckItem) GetTupleKey() *openfgav1.CheckRequestTupleKey {
+	if i == nil {
+		return nil
+	}
+	return i.TupleKey
+}
+
+func (i *BatchCheckItem) GetContextualTuples() *openfgav1.ContextualTupleKeys {
+	if i == nil {
+		return nil
+	}
+	return i.ContextualTuples
+}
+
+func (i *BatchCheckItem) GetContext() *structpb.Struct {
+	if i == nil {
+		return nil
+	}
+	return i.Context
+}
+
+func (i *BatchCheckItem) GetCorrelationId() string { //nolint:revive,stylecheck
+	if i == nil {
+		return ""
+	}
+	return i.CorrelationId
+}
+
+// Validate applies to every check of the batch the same rules that apply to a Check request.
+func (r *BatchCheckRequest) Validate() error {
+	if len(r.GetChecks()) == 0 {
+		return errors.New("invalid BatchCheckRequest.Checks: value must contain at least 1 item(s)")
+	}
+
+	for i, check := range r.GetChecks() {
+		if check.GetCorrelationId() == "" {
+			return fmt.Errorf("invalid BatchCheckRequest.Checks[%d].CorrelationId: value length must be at least 1 runes", i)
+		}
+
+		checkRequest := &openfgav1.CheckRequest{
+			StoreId:              r.GetStoreId(),
+			AuthorizationModelId: r.GetAuthorizationModelId(),
+			TupleKey:             check.GetTupleKey(),
+			ContextualTuples:     check.GetContextualTuples(),
+			Context:              check.GetContext(),
+			Consistency:          r.GetConsistency(),
+		}
+		if err := checkRequest.Validate(); err != nil {
+			return fmt.Errorf("invalid BatchCheckRequest.Checks[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+type batchCheckRequestJSON struct {
+	StoreID              string            `json:"store_id"`
+	AuthorizationModelID string            `json:"authorization_model_id,omitempty"`
+	Checks               []*BatchCheckItem `json:"checks"`
+	Consistency          string            `json:"consistency,omitempty"`
+}
+
+func (r *BatchCheckRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(batchCheckRequestJSON{
+		StoreID:              r.GetStoreId(),
+		AuthorizationModelID: r.GetAuthorizationModelId(),
+		Checks:               r.GetChecks(),
+		Consistency:          r.GetConsistency().String(),
+	})
+}
+
+func (r *BatchCheckRequest) UnmarshalJSON(data []byte) error {
+	var raw batchCheckRequestJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	consistency := openfgav1.ConsistencyPreference_UNSPECIFIED
+	if raw.Consistency != "" {
+		value, ok := openfgav1.ConsistencyPreference_value[raw.Consistency]
+		if !ok {
+			return fmt.Errorf("invalid value for enum field consistency: %q", raw.Consistency)
+		}
+		consistency = openfgav1.ConsistencyPreference(value)
+	}
+
+	*r = BatchCheckRequest{
+		StoreId:              raw.StoreID,
+		AuthorizationModelId: raw.AuthorizationModelID,
+		Checks:               raw.Checks,
+		Consistency:          consistency,
+	}
+	return nil
+}
+
+type batchCheckItemJSON struct {
+	TupleKey         json.RawMessage `json:"tuple_key,omitempty"`
+	ContextualTuples json.RawMessage `json:"contextual_tuples,omitempty"`
+	Context          json.RawMessage `json:"context,omitempty"`
+	CorrelationID    string          `json:"correlation_id"`
+}
+
+func (i *BatchCheckItem) MarshalJSON() ([]byte, error) {
+	raw := batchCheckItemJSON{CorrelationID: i.GetCorrelationId()}
+
+	var err error
+	if raw.TupleKey, err = marshalProtoField(i.GetTupleKey()); err != nil {
+		return nil, err
+	}
+	if raw.ContextualTuples, err = marshalProtoField(i.GetContextualTuples()); err != nil {
+		return nil, err
+	}
+	if raw.Context, err = marshalProtoField(i.GetContext()); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(raw)
+}
+
+func (i *BatchCheckItem) UnmarshalJSON(data []byte) error {
+	var raw batchCheckItemJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	item := BatchCheckItem{CorrelationId: raw.CorrelationID}
+	if len(raw.TupleKey) > 0 {
+		item.TupleKey = &openfgav1.CheckRequestTupleKey{}
+		if err := protojson.Unmarshal(raw.TupleKey, item.TupleKey); err != nil {
+			return fmt.Errorf("invalid tuple_key: %w", err)
+		}
+	}
+	if len(raw.ContextualTuples) > 0 {
+		item.ContextualTuples = &openfgav1.ContextualTupleKeys{}
+		if err := protojson.Unmarshal(raw.ContextualTuples, item.ContextualTuples); err != nil {
+			return fmt.Errorf("invalid contextual_tuples: %w", err)
+		}
+	}
+	if len(raw.Context) > 0 {
+		item.Context = &structpb.Struct{}
+		if err := protojson.Unmarshal(raw.Context, item.Context); err != nil {
+			return fmt.Errorf("invalid context: %w", err)
+		}
+	}
+
+	*i = item
+	return nil
+}
+
+// BatchCheckServiceServer is the server API for the BatchCheck service.
+type BatchCheckServiceServer interface {
+	BatchCheck(context.Context, *BatchCheckRequest) (*BatchCheckResponse, error)
+}
+
+var _ BatchCheckServiceServer = (*Server)(nil)
+
+// BatchCheckServiceDesc is the grpc.ServiceDesc for the BatchCheck service.
+var BatchCheckServiceDesc = grpc.ServiceDesc{
+	ServiceName: batchCheckServiceName,
+	HandlerType: (*BatchCheckServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchCheck",
+			Handler:    batchCheckHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterBatchCheckServiceServer registers the BatchCheck service in the given gRPC server. It must be
+// registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterBatchCheckServiceServer(s grpc.ServiceRegistrar, srv BatchCheckServiceServer) {
+	s.RegisterService(&BatchCheckServiceDesc, srv)
+}
+
+func batchCheckHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BatchCheckServiceServer).BatchCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: batchCheckFullMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BatchCheckServiceServer).BatchCheck(ctx, req.(*BatchCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BatchCheckServiceClient is the client API for the BatchCheck service.
+type BatchCheckServiceClient interface {
+	BatchCheck(ctx context.Context, in *BatchCheckRequest, opts ...grpc.CallOption) (*BatchCheckResponse, error)
+}
+
+type batchCheckServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBatchCheckServiceClient(cc grpc.ClientConnInterface) BatchCheckServiceClient {
+	return &batchCheckServiceClient{cc: cc}
+}
+
+func (c *batchCheckServiceClient) BatchCheck(ctx context.Context, in *BatchCheckRequest, opts ...grpc.CallOption) (*BatchCheckResponse, error) {
+	out := new(BatchCheckResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, batchCheckFullMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterBatchCheckServiceHandler registers the HTTP route of BatchCheck (POST /stores/{store_id}/batch-check)
+// in the gateway mux. Requests are forwarded to the gRPC server through conn, so they go through the same
+// authentication, validation and logging as every other call of the HTTP API.
+func RegisterBatchCheckServiceHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := NewBatchCheckServiceClient(conn)
+
+	return handleJSONPath(mux, batchCheckHTTPPath, batchCheckFullMethod,
+		func(req *BatchCheckRequest, storeID string) {
+			req.StoreId = storeID
+		},
+		client.BatchCheck,
+	)
+}
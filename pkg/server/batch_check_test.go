@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestBatchCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithMaxChecksPerBatchCheck(3),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "batch-check"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type doc
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeModelResp.GetAuthorizationModelId()
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("doc:1", "viewer", "user:anne")},
+		},
+	})
+	require.NoError(t, err)
+
+	request := &BatchCheckRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Checks: []*BatchCheckItem{
+			{TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:anne"), CorrelationId: "allowed"},
+			{TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:bob"), CorrelationId: "denied"},
+			{TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "invalid", "user:anne"), CorrelationId: "invalid"},
+		},
+	}
+
+	requireResult := func(t *testing.T, resp *BatchCheckResponse) {
+		require.Len(t, resp.Result, 3)
+		require.True(t, resp.Result["allowed"].Allowed)
+		require.Nil(t, resp.Result["allowed"].Error)
+		require.False(t, resp.Result["denied"].Allowed)
+		require.Nil(t, resp.Result["denied"].Error)
+		require.NotNil(t, resp.Result["invalid"].Error)
+		require.Equal(t, int32(openfgav1.ErrorCode_validation_error), resp.Result["invalid"].Error.Code)
+		require.Contains(t, resp.Result["invalid"].Error.Message, "relation 'doc#invalid' not found")
+	}
+
+	t.Run("resolves_checks", func(t *testing.T) {
+		resp, err := s.BatchCheck(ctx, request)
+		require.NoError(t, err)
+		requireResult(t, resp)
+	})
+
+	t.Run("rejects_too_many_checks", func(t *testing.T) {
+		_, err := s.BatchCheck(ctx, &BatchCheckRequest{
+			StoreId: storeID,
+			Checks: append(request.GetChecks(), &BatchCheckItem{
+				TupleKey:      tuple.NewCheckRequestTupleKey("doc:2", "viewer", "user:anne"),
+				CorrelationId: "fourth",
+			}),
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.ErrorContains(t, err, "batch check received 4 checks, the maximum allowed is 3")
+	})
+
+	t.Run("validates_request", func(t *testing.T) {
+		_, err := s.BatchCheck(ctx, &BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*BatchCheckItem{
+				{TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:anne")},
+			},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	RegisterBatchCheckServiceServer(grpcServer, s)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		resp, err := NewBatchCheckServiceClient(conn).BatchCheck(ctx, request)
+		require.NoError(t, err)
+		requireResult(t, resp)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		mux := runtime.NewServeMux()
+		require.NoError(t, RegisterBatchCheckServiceHandler(mux, conn))
+
+		httpServer := httptest.NewServer(mux)
+		t.Cleanup(httpServer.Close)
+
+		body, err := json.Marshal(&BatchCheckRequest{
+			AuthorizationModelId: modelID,
+			Checks:               request.GetChecks(),
+		})
+		require.NoError(t, err)
+
+		httpResp, err := http.Post(httpServer.URL+"/stores/"+storeID+"/batch-check", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer httpResp.Body.Close()
+		require.Equal(t, http.StatusOK, httpResp.StatusCode)
+
+		var resp BatchCheckResponse
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&resp))
+		requireResult(t, &resp)
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ graph.CheckDispatchServiceServer = (*Server)(nil)
+
+// DispatchCheck resolves a Check subproblem sent by the RemoteCheckResolver of another node of the cluster. The
+// subproblem goes through the check resolvers of this node, so it is cached here, and its own subproblems
+// are dispatched across the cluster as well.
+//
+// It serves the internal CheckDispatch service, which is not part of the OpenFGA API: the request has
+// already been authorized and validated by the node that received it.
+func (s *Server) DispatchCheck(ctx context.Context, req *graph.DispatchCheckRequest) (*graph.DispatchCheckResponse, error) {
+	resolveCheckRequest := req.GetRequest()
+
+	tk := resolveCheckRequest.GetTupleKey()
+	ctx, span := tracer.Start(ctx, "DispatchCheck", trace.WithAttributes(
+		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(resolveCheckRequest.GetStoreID())},
+		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
+		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
+		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
+	))
+	defer span.End()
+
+	typesys, err := s.resolveTypesystem(ctx, resolveCheckRequest.GetStoreID(), resolveCheckRequest.GetAuthorizationModelID())
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	datastore := storagewrappers.NewInstrumentedOpenFGAStorage(s.checkDatastore)
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx,
+		storagewrappers.NewBoundedConcurrencyTupleReader(
+			storagewrappers.NewCombinedTupleReader(
+				datastore,
+				resolveCheckRequest.GetContextualTuples(),
+			),
+			s.maxConcurrentReadsForCheck,
+		),
+	)
+	ctx = graph.ContextWithForwardedCheck(ctx, resolveCheckRequest)
+	if req.Revision != nil {
+		ctx = storage.ContextWithRevision(ctx, *req.Revision)
+	}
+
+	resp, err := s.checkResolver.ResolveCheck(ctx, resolveCheckRequest)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	requestMetadata := resolveCheckRequest.GetRequestMetadata()
+	return &graph.DispatchCheckResponse{
+		Allowed:             resp.GetAllowed(),
+		CycleDetected:       resp.GetCycleDetected(),
+		DatastoreQueryCount: datastore.GetMetrics().DatastoreQueryCount,
+		DispatchCount:       requestMetadata.DispatchCounter.Load(),
+		WasThrottled:        requestMetadata.WasThrottled.Load(),
+	}, nil
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// countingCheckDispatchServer counts the subproblems a node resolves on behalf of the other nodes.
+type countingCheckDispatchServer struct {
+	*Server
+	dispatched atomic.Uint32
+}
+
+func (s *countingCheckDispatchServer) DispatchCheck(ctx context.Context, req *graph.DispatchCheckRequest) (*graph.DispatchCheckResponse, error) {
+	s.dispatched.Add(1)
+	return s.Server.DispatchCheck(ctx, req)
+}
+
+type testClusterNode struct {
+	server         *countingCheckDispatchServer
+	dispatchServer *grpc.Server
+}
+
+// newTestCluster starts an in-process cluster of OpenFGA servers sharing a datastore, each serving the
+// check dispatch service on its own port.
+func newTestCluster(t *testing.T, size int, opts ...OpenFGAServiceV1Option) []*testClusterNode {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	listeners := make([]net.Listener, size)
+	addresses := make([]string, size)
+	for i := range listeners {
+		lis, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		listeners[i] = lis
+		addresses[i] = lis.Addr().String()
+	}
+
+	nodes := make([]*testClusterNode, size)
+	for i, lis := range listeners {
+		s := MustNewServerWithOpts(append([]OpenFGAServiceV1Option{
+			WithDatastore(ds),
+			WithRemoteCheckDispatchEnabled(true),
+			WithRemoteCheckDispatchSelfAddress(addresses[i]),
+			WithRemoteCheckDispatchPeers(graph.StaticPeers(addresses)),
+			WithRemoteCheckDispatchPeerUnhealthyBackoff(time.Minute),
+		}, opts...)...)
+		t.Cleanup(s.Close)
+
+		node := &testClusterNode{
+			server:         &countingCheckDispatchServer{Server: s},
+			dispatchServer: grpc.NewServer(),
+		}
+		graph.RegisterCheckDispatchServiceServer(node.dispatchServer, node.server)
+		go func() {
+			_ = node.dispatchServer.Serve(lis)
+		}()
+		t.Cleanup(node.dispatchServer.Stop)
+
+		nodes[i] = node
+	}
+
+	return nodes
+}
+
+func TestRemoteCheckDispatch(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	nodes := newTestCluster(t, 3, WithCheckQueryCacheEnabled(true))
+	s := nodes[0].server
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "remote-check-dispatch"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user, group#member]
+
+			type folder
+				relations
+					define viewer: [user, group#member]
+
+			type doc
+				relations
+					define parent: [folder]
+					define editor: [user, group#member]
+					define viewer: editor or viewer from parent`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeModelResp.GetAuthorizationModelId()
+
+	var writes []*openfgav1.TupleKey
+	for i := 0; i < 10; i++ {
+		writes = append(writes,
+			tuple.NewTupleKey(fmt.Sprintf("doc:%d", i), "parent", fmt.Sprintf("folder:%d", i)),
+			tuple.NewTupleKey(fmt.Sprintf("folder:%d", i), "viewer", fmt.Sprintf("group:%d#member", i)),
+			tuple.NewTupleKey(fmt.Sprintf("group:%d", i), "member", fmt.Sprintf("group:sub%d#member", i)),
+			tuple.NewTupleKey(fmt.Sprintf("group:sub%d", i), "member", fmt.Sprintf("user:%d", i)),
+		)
+	}
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: writes},
+	})
+	require.NoError(t, err)
+
+	requireChecks := func(t *testing.T, nodes []*testClusterNode) {
+		for _, node := range nodes {
+			for i := 0; i < 10; i++ {
+				for _, user := range []string{fmt.Sprintf("user:%d", i), "user:other"} {
+					resp, err := node.server.Check(ctx, &openfgav1.CheckRequest{
+						StoreId:              storeID,
+						AuthorizationModelId: modelID,
+						TupleKey:             tuple.NewCheckRequestTupleKey(fmt.Sprintf("doc:%d", i), "viewer", user),
+						// bypass the cache, so that every check is resolved again
+						Consistency: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+					})
+					require.NoError(t, err)
+					require.Equal(t, user != "user:other", resp.GetAllowed(), "doc:%d viewer %s", i, user)
+				}
+			}
+		}
+	}
+
+	t.Run("spreads_subproblems_across_nodes", func(t *testing.T) {
+		requireChecks(t, nodes)
+
+		for i, node := range nodes {
+			require.NotZero(t, node.server.dispatched.Load(), "node %d resolved no subproblem of the others", i)
+		}
+	})
+
+	t.Run("dispatches_contextual_tuples", func(t *testing.T) {
+		for _, node := range nodes {
+			resp, err := node.server.Check(ctx, &openfgav1.CheckRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: modelID,
+				TupleKey:             tuple.NewCheckRequestTupleKey("doc:0", "viewer", "user:contextual"),
+				ContextualTuples: &openfgav1.ContextualTupleKeys{
+					TupleKeys: []*openfgav1.TupleKey{
+						tuple.NewTupleKey("group:sub0", "member", "user:contextual"),
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.True(t, resp.GetAllowed())
+		}
+	})
+
+	t.Run("falls_back_to_local_resolution", func(t *testing.T) {
+		nodes[2].dispatchServer.Stop()
+
+		dispatched := nodes[2].server.dispatched.Load()
+		requireChecks(t, nodes)
+		require.Equal(t, dispatched, nodes[2].server.dispatched.Load())
+	})
+}
@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/sourcegraph/conc/pool"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/internal/cachecontroller"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	defaultMaxChecksPerBatchCheck           = 50
+	defaultMaxConcurrentChecksPerBatchCheck = 50
+)
+
+// CorrelationID is the identifier supplied by the caller to match each check of a batch with its outcome.
+type CorrelationID string
+
+type BatchCheckQuery struct {
+	logger          logger.Logger
+	checkResolver   graph.CheckResolver
+	typesys         *typesystem.TypeSystem
+	datastore       storage.RelationshipTupleReader
+	cacheController cachecontroller.CacheController
+
+	resolveNodeLimit    uint32
+	maxConcurrentReads  uint32
+	maxChecksAllowed    uint32
+	maxConcurrentChecks uint32
+}
+
+type BatchCheckCommandParams struct {
+	StoreID     string
+	Checks      []*BatchCheckItem
+	Consistency openfgav1.ConsistencyPreference
+}
+
+type BatchCheckItem struct {
+	TupleKey         *openfgav1.CheckRequestTupleKey
+	ContextualTuples *openfgav1.ContextualTupleKeys
+	Context          *structpb.Struct
+	CorrelationID    CorrelationID
+}
+
+// BatchCheckOutcome is the result of a single check of the batch. Exactly one of CheckResponse and Err is set.
+type BatchCheckOutcome struct {
+	CheckResponse *graph.ResolveCheckResponse
+	Err           error
+}
+
+type BatchCheckMetadata struct {
+	// DatastoreQueryCount is the number of reads issued to the datastore across every check of the batch.
+	DatastoreQueryCount uint32
+	// DispatchCount is the number of dispatches across every check of the batch.
+	DispatchCount uint32
+	// DuplicateCheckCount is the number of checks that were not resolved because an identical check was
+	// already part of the batch.
+	DuplicateCheckCount int
+	// WasThrottled is true if any check of the batch was throttled.
+	WasThrottled bool
+}
+
+type BatchCheckValidationError struct {
+	Message string
+}
+
+func (e BatchCheckValidationError) Error() string {
+	return e.Message
+}
+
+type BatchCheckQueryOption func(*BatchCheckQuery)
+
+func WithBatchCheckCommandLogger(l logger.Logger) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.logger = l
+	}
+}
+
+func WithBatchCheckCacheController(ctrl cachecontroller.CacheController) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.cacheController = ctrl
+	}
+}
+
+func WithBatchCheckCommandResolveNodeLimit(nl uint32) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.resolveNodeLimit = nl
+	}
+}
+
+// WithBatchCheckMaxConcurrentReads sets the maximum number of datastore reads that may be in flight
+// for the whole batch, as opposed to per check.
+func WithBatchCheckMaxConcurrentReads(m uint32) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.maxConcurrentReads = m
+	}
+}
+
+func WithBatchCheckMaxChecksPerBatch(m uint32) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.maxChecksAllowed = m
+	}
+}
+
+func WithBatchCheckMaxConcurrentChecks(m uint32) BatchCheckQueryOption {
+	return func(c *BatchCheckQuery) {
+		c.maxConcurrentChecks = m
+	}
+}
+
+func NewBatchCheckCommand(datastore storage.RelationshipTupleReader, checkResolver graph.CheckResolver, typesys *typesystem.TypeSystem, opts ...BatchCheckQueryOption) *BatchCheckQuery {
+	cmd := &BatchCheckQuery{
+		logger:              logger.NewNoopLogger(),
+		datastore:           datastore,
+		checkResolver:       checkResolver,
+		typesys:             typesys,
+		cacheController:     cachecontroller.NewNoopCacheController(),
+		resolveNodeLimit:    defaultResolveNodeLimit,
+		maxConcurrentReads:  defaultMaxConcurrentReadsForCheck,
+		maxChecksAllowed:    defaultMaxChecksPerBatchCheck,
+		maxConcurrentChecks: defaultMaxConcurrentChecksPerBatchCheck,
+	}
+
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// Execute resolves every check of the batch concurrently. All the checks share a single budget of datastore
+// reads, identical checks are resolved once, and subproblems and datastore reads that are in flight at the
+// same time are resolved once for the whole batch.
+//
+// An error is returned only if the batch itself is invalid; errors of individual checks are reported in
+// their BatchCheckOutcome.
+func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckCommandParams) (map[CorrelationID]*BatchCheckOutcome, *BatchCheckMetadata, error) {
+	ctx, span := tracer.Start(ctx, "BatchCheck")
+	defer span.End()
+
+	if err := bq.validateBatch(params); err != nil {
+		return nil, nil, err
+	}
+
+	cacheInvalidationTime := time.Time{}
+	if params.Consistency != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		cacheInvalidationTime = bq.cacheController.DetermineInvalidation(ctx, params.StoreID)
+	}
+
+	// identical checks are resolved once, and their outcome is fanned out to every correlation ID.
+	uniqueChecks := make(map[string]*BatchCheckItem, len(params.Checks))
+	correlationIDsByKey := make(map[string][]CorrelationID, len(params.Checks))
+	for _, check := range params.Checks {
+		key, err := graph.CheckRequestCacheKey(&graph.ResolveCheckRequest{
+			StoreID:              params.StoreID,
+			AuthorizationModelID: bq.typesys.GetAuthorizationModelID(),
+			TupleKey:             tuple.ConvertCheckRequestTupleKeyToTupleKey(check.TupleKey),
+			ContextualTuples:     check.ContextualTuples.GetTupleKeys(),
+			Context:              check.Context,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, ok := uniqueChecks[key]; !ok {
+			uniqueChecks[key] = check
+		}
+		correlationIDsByKey[key] = append(correlationIDsByKey[key], check.CorrelationID)
+	}
+
+	instrumented := storagewrappers.NewInstrumentedOpenFGAStorage(bq.datastore)
+	sharedReader := storagewrappers.NewSharedIteratorTupleReader(
+		storagewrappers.NewBoundedConcurrencyTupleReader(instrumented, bq.maxConcurrentReads),
+	)
+
+	ctx = typesystem.ContextWithTypesystem(ctx, bq.typesys)
+	ctx = graph.ContextWithInflightCheckDeduplication(ctx)
+
+	var mu sync.Mutex
+	outcomes := make(map[CorrelationID]*BatchCheckOutcome, len(params.Checks))
+	metadata := &BatchCheckMetadata{
+		DuplicateCheckCount: len(params.Checks) - len(uniqueChecks),
+	}
+
+	p := pool.New().WithMaxGoroutines(int(bq.maxConcurrentChecks))
+	for key, check := range uniqueChecks {
+		p.Go(func() {
+			outcome, reqMetadata := bq.resolveCheck(ctx, params, check, sharedReader, cacheInvalidationTime)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range correlationIDsByKey[key] {
+				outcomes[id] = outcome
+			}
+			if reqMetadata != nil {
+				metadata.DispatchCount += reqMetadata.DispatchCounter.Load()
+				metadata.WasThrottled = metadata.WasThrottled || reqMetadata.WasThrottled.Load()
+			}
+		})
+	}
+	p.Wait()
+
+	metadata.DatastoreQueryCount = instrumented.GetMetrics().DatastoreQueryCount
+
+	span.SetAttributes(
+		attribute.Int("checks", len(params.Checks)),
+		attribute.Int("duplicate_checks", metadata.DuplicateCheckCount),
+	)
+
+	return outcomes, metadata, nil
+}
+
+func (bq *BatchCheckQuery) resolveCheck(
+	ctx context.Context,
+	params *BatchCheckCommandParams,
+	check *BatchCheckItem,
+	datastore storage.RelationshipTupleReader,
+	cacheInvalidationTime time.Time,
+) (*BatchCheckOutcome, *graph.ResolveCheckRequestMetadata) {
+	if err := validateCheckRequest(bq.typesys, check.TupleKey, check.ContextualTuples); err != nil {
+		return &BatchCheckOutcome{Err: err}, nil
+	}
+
+	resolveCheckRequest := graph.ResolveCheckRequest{
+		StoreID:                   params.StoreID,
+		AuthorizationModelID:      bq.typesys.GetAuthorizationModelID(),
+		TupleKey:                  tuple.ConvertCheckRequestTupleKeyToTupleKey(check.TupleKey),
+		ContextualTuples:          check.ContextualTuples.GetTupleKeys(),
+		Context:                   check.Context,
+		VisitedPaths:              make(map[string]struct{}),
+		RequestMetadata:           graph.NewCheckRequestMetadata(bq.resolveNodeLimit),
+		Consistency:               params.Consistency,
+		LastCacheInvalidationTime: cacheInvalidationTime,
+	}
+
+	ctx = storage.ContextWithRelationshipTupleReader(ctx,
+		storagewrappers.NewCombinedTupleReader(datastore, resolveCheckRequest.GetContextualTuples()),
+	)
+
+	resp, err := bq.checkResolver.ResolveCheck(ctx, &resolveCheckRequest)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && resolveCheckRequest.GetRequestMetadata().WasThrottled.Load() {
+			err = &ThrottledError{Cause: err}
+		}
+		return &BatchCheckOutcome{Err: err}, resolveCheckRequest.GetRequestMetadata()
+	}
+
+	return &BatchCheckOutcome{CheckResponse: resp}, resolveCheckRequest.GetRequestMetadata()
+}
+
+func (bq *BatchCheckQuery) validateBatch(params *BatchCheckCommandParams) error {
+	if len(params.Checks) == 0 {
+		return &BatchCheckValidationError{Message: "batch check requires at least one check to evaluate, no checks were received"}
+	}
+
+	if len(params.Checks) > int(bq.maxChecksAllowed) {
+		return &BatchCheckValidationError{
+			Message: fmt.Sprintf("batch check received %d checks, the maximum allowed is %d", len(params.Checks), bq.maxChecksAllowed),
+		}
+	}
+
+	seen := make(map[CorrelationID]struct{}, len(params.Checks))
+	for _, check := range params.Checks {
+		if check.CorrelationID == "" {
+			return &BatchCheckValidationError{
+				Message: fmt.Sprintf("received empty correlation id for tuple: %s", check.TupleKey.String()),
+			}
+		}
+
+		if _, ok := seen[check.CorrelationID]; ok {
+			return &BatchCheckValidationError{
+				Message: fmt.Sprintf("received duplicate correlation id: %s", check.CorrelationID),
+			}
+		}
+		seen[check.CorrelationID] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestBatchCheckCommand(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type doc
+			relations
+				define viewer: [user, group#member]
+				define editor: [user]
+				define can_edit: editor and viewer`,
+		[]string{
+			"group:eng#member@user:jon",
+			"doc:1#viewer@group:eng#member",
+			"doc:1#editor@user:jon",
+			"doc:2#viewer@user:maria",
+		},
+	)
+	ts, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	checker, checkResolverCloser := graph.NewOrderedCheckResolvers().Build()
+	t.Cleanup(checkResolverCloser)
+
+	newItem := func(correlationID, tk string) *BatchCheckItem {
+		tupleKey := tuple.MustParseTupleString(tk)
+		return &BatchCheckItem{
+			TupleKey:      tuple.NewCheckRequestTupleKey(tupleKey.GetObject(), tupleKey.GetRelation(), tupleKey.GetUser()),
+			CorrelationID: CorrelationID(correlationID),
+		}
+	}
+
+	t.Run("resolves_every_check", func(t *testing.T) {
+		cmd := NewBatchCheckCommand(ds, checker, ts)
+		outcomes, metadata, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			StoreID: storeID,
+			Checks: []*BatchCheckItem{
+				newItem("1", "doc:1#viewer@user:jon"),
+				newItem("2", "doc:1#can_edit@user:jon"),
+				newItem("3", "doc:2#viewer@user:jon"),
+				newItem("4", "doc:2#viewer@user:maria"),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, outcomes, 4)
+
+		expected := map[CorrelationID]bool{"1": true, "2": true, "3": false, "4": true}
+		for id, allowed := range expected {
+			require.NoError(t, outcomes[id].Err)
+			require.Equal(t, allowed, outcomes[id].CheckResponse.GetAllowed(), id)
+		}
+		require.Zero(t, metadata.DuplicateCheckCount)
+		require.Positive(t, metadata.DatastoreQueryCount)
+	})
+
+	t.Run("resolves_identical_checks_once", func(t *testing.T) {
+		cmd := NewBatchCheckCommand(ds, checker, ts)
+		outcomes, metadata, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			StoreID: storeID,
+			Checks: []*BatchCheckItem{
+				newItem("a", "doc:1#viewer@user:jon"),
+				newItem("b", "doc:1#viewer@user:jon"),
+				newItem("c", "doc:1#viewer@user:jon"),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, outcomes, 3)
+		require.Equal(t, 2, metadata.DuplicateCheckCount)
+		for _, outcome := range outcomes {
+			require.True(t, outcome.CheckResponse.GetAllowed())
+		}
+	})
+
+	t.Run("reports_invalid_checks_individually", func(t *testing.T) {
+		cmd := NewBatchCheckCommand(ds, checker, ts)
+		outcomes, _, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			StoreID: storeID,
+			Checks: []*BatchCheckItem{
+				newItem("valid", "doc:1#viewer@user:jon"),
+				newItem("invalid", "doc:1#invalid@user:jon"),
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, outcomes["valid"].CheckResponse.GetAllowed())
+		require.ErrorContains(t, outcomes["invalid"].Err, "relation 'doc#invalid' not found")
+	})
+
+	t.Run("uses_contextual_tuples_per_check", func(t *testing.T) {
+		withContextualTuple := newItem("contextual", "doc:3#viewer@user:bob")
+		withContextualTuple.ContextualTuples = &openfgav1.ContextualTupleKeys{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("doc:3", "viewer", "user:bob")},
+		}
+
+		cmd := NewBatchCheckCommand(ds, checker, ts)
+		outcomes, metadata, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			StoreID: storeID,
+			Checks: []*BatchCheckItem{
+				withContextualTuple,
+				newItem("plain", "doc:3#viewer@user:bob"),
+			},
+		})
+		require.NoError(t, err)
+		require.Zero(t, metadata.DuplicateCheckCount)
+		require.True(t, outcomes["contextual"].CheckResponse.GetAllowed())
+		require.False(t, outcomes["plain"].CheckResponse.GetAllowed())
+	})
+
+	t.Run("validates_batch", func(t *testing.T) {
+		tests := map[string]struct {
+			checks        []*BatchCheckItem
+			expectedError string
+		}{
+			"no_checks": {
+				checks:        []*BatchCheckItem{},
+				expectedError: "batch check requires at least one check to evaluate, no checks were received",
+			},
+			"too_many_checks": {
+				checks: []*BatchCheckItem{
+					newItem("1", "doc:1#viewer@user:jon"),
+					newItem("2", "doc:1#viewer@user:jon"),
+					newItem("3", "doc:1#viewer@user:jon"),
+				},
+				expectedError: "batch check received 3 checks, the maximum allowed is 2",
+			},
+			"empty_correlation_id": {
+				checks: []*BatchCheckItem{
+					newItem("", "doc:1#viewer@user:jon"),
+				},
+				expectedError: "received empty correlation id for tuple",
+			},
+			"duplicate_correlation_id": {
+				checks: []*BatchCheckItem{
+					newItem("1", "doc:1#viewer@user:jon"),
+					newItem("1", "doc:2#viewer@user:jon"),
+				},
+				expectedError: "received duplicate correlation id: 1",
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				cmd := NewBatchCheckCommand(ds, checker, ts, WithBatchCheckMaxChecksPerBatch(2))
+				_, _, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+					StoreID: storeID,
+					Checks:  test.checks,
+				})
+
+				var validationError *BatchCheckValidationError
+				require.ErrorAs(t, err, &validationError)
+				require.ErrorContains(t, err, test.expectedError)
+			})
+		}
+	})
+
+	t.Run("resolves_with_bounded_concurrency", func(t *testing.T) {
+		checks := make([]*BatchCheckItem, 0, 20)
+		for i := 0; i < 20; i++ {
+			checks = append(checks, newItem(fmt.Sprintf("%d", i), fmt.Sprintf("doc:%d#viewer@user:jon", i)))
+		}
+
+		cmd := NewBatchCheckCommand(ds, checker, ts, WithBatchCheckMaxConcurrentChecks(5))
+		outcomes, _, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+			StoreID: storeID,
+			Checks:  checks,
+		})
+		require.NoError(t, err)
+		require.Len(t, outcomes, 20)
+		require.True(t, outcomes["1"].CheckResponse.GetAllowed())
+		require.False(t, outcomes["2"].CheckResponse.GetAllowed())
+	})
+}
@@ -33,6 +33,11 @@ type CheckQuery struct {
 
 	resolveNodeLimit   uint32
 	maxConcurrentReads uint32
+
+	// batchingWindow and batchingMaxBatchSize configure the batching of the point lookups of the datastore, which is
+	// disabled if batchingMaxBatchSize is zero.
+	batchingWindow       time.Duration
+	batchingMaxBatchSize int
 }
 
 type CheckCommandParams struct {
@@ -57,6 +62,15 @@ func WithCheckCommandMaxConcurrentReads(m uint32) CheckQueryOption {
 	}
 }
 
+// WithCheckCommandDatastoreBatching batches the concurrent point lookups of the datastore made while resolving the
+// check, see [storagewrappers.BatchingTupleReader].
+func WithCheckCommandDatastoreBatching(window time.Duration, maxBatchSize int) CheckQueryOption {
+	return func(c *CheckQuery) {
+		c.batchingWindow = window
+		c.batchingMaxBatchSize = maxBatchSize
+	}
+}
+
 func WithCheckCommandLogger(l logger.Logger) CheckQueryOption {
 	return func(c *CheckQuery) {
 		c.logger = l
@@ -111,7 +125,12 @@ func (c *CheckQuery) Execute(ctx context.Context, params *CheckCommandParams) (*
 		LastCacheInvalidationTime: cacheInvalidationTime,
 	}
 
-	ctx = buildCheckContext(ctx, c.typesys, c.datastore, c.maxConcurrentReads, resolveCheckRequest.GetContextualTuples())
+	var datastore storage.RelationshipTupleReader = c.datastore
+	if c.batchingMaxBatchSize > 0 {
+		datastore = storagewrappers.NewBatchingTupleReader(datastore, c.batchingWindow, c.batchingMaxBatchSize)
+	}
+
+	ctx = buildCheckContext(ctx, c.typesys, datastore, c.maxConcurrentReads, resolveCheckRequest.GetContextualTuples())
 
 	resp, err := c.checkResolver.ResolveCheck(ctx, &resolveCheckRequest)
 	if err != nil {
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -141,6 +142,46 @@ type doc
 		require.Equal(t, uint32(1), checkResp.GetResolutionMetadata().DatastoreQueryCount)
 	})
 
+	t.Run("batches_the_datastore_lookups", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		cmd := NewCheckCommand(mockDatastore, mockCheckResolver, ts, WithCheckCommandDatastoreBatching(time.Millisecond, 2))
+		mockDatastore.EXPECT().
+			ReadTuplesBatch(gomock.Any(), storeID, gomock.InAnyOrder([]storage.TupleLookup{
+				{Object: "doc:1", Relation: "viewer", User: "user:1"},
+				{Object: "doc:2", Relation: "viewer", User: "user:1"},
+			}), gomock.Any()).
+			Times(1).
+			Return([]*openfgav1.Tuple{{Key: tuple.NewTupleKey("doc:1", "viewer", "user:1")}}, nil)
+		mockCheckResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+				ds, _ := storage.RelationshipTupleReaderFromContext(ctx)
+
+				var allowed, denied error
+				var wg sync.WaitGroup
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					_, allowed = ds.ReadUserTuple(ctx, req.StoreID, tuple.NewTupleKey("doc:1", "viewer", "user:1"), storage.ReadUserTupleOptions{})
+				}()
+				go func() {
+					defer wg.Done()
+					_, denied = ds.ReadUserTuple(ctx, req.StoreID, tuple.NewTupleKey("doc:2", "viewer", "user:1"), storage.ReadUserTupleOptions{})
+				}()
+				wg.Wait()
+
+				require.NoError(t, allowed)
+				require.ErrorIs(t, denied, storage.ErrNotFound)
+				return &graph.ResolveCheckResponse{}, nil
+			})
+		checkResp, _, err := cmd.Execute(context.Background(), &CheckCommandParams{
+			StoreID:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:1"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), checkResp.GetResolutionMetadata().DatastoreQueryCount)
+	})
+
 	t.Run("no_validation_error_but_call_to_resolver_fails", func(t *testing.T) {
 		cmd := NewCheckCommand(mockDatastore, mockCheckResolver, ts)
 		mockCheckResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1).Return(nil, ofga_errors.ErrUnknown)
@@ -13,6 +13,8 @@ import (
 
 type CreateStoreCommand struct {
 	storesBackend storage.StoresBackend
+	labeler       storage.StoreLabeler
+	labels        map[string]string
 	logger        logger.Logger
 }
 
@@ -24,6 +26,14 @@ func WithCreateStoreCmdLogger(l logger.Logger) CreateStoreCmdOption {
 	}
 }
 
+// WithCreateStoreCmdLabels sets the labels of the created store, written with the labeler.
+func WithCreateStoreCmdLabels(labeler storage.StoreLabeler, labels map[string]string) CreateStoreCmdOption {
+	return func(c *CreateStoreCommand) {
+		c.labeler = labeler
+		c.labels = labels
+	}
+}
+
 func NewCreateStoreCommand(
 	storesBackend storage.StoresBackend,
 	opts ...CreateStoreCmdOption,
@@ -40,10 +50,18 @@ func NewCreateStoreCommand(
 }
 
 func (s *CreateStoreCommand) Execute(ctx context.Context, req *openfgav1.CreateStoreRequest) (*openfgav1.CreateStoreResponse, error) {
-	store, err := s.storesBackend.CreateStore(ctx, &openfgav1.Store{
+	newStore := &openfgav1.Store{
 		Id:   ulid.Make().String(),
 		Name: req.GetName(),
-	})
+	}
+
+	var store *openfgav1.Store
+	var err error
+	if len(s.labels) > 0 {
+		store, err = s.labeler.CreateStoreWithLabels(ctx, newStore, s.labels)
+	} else {
+		store, err = s.storesBackend.CreateStore(ctx, newStore)
+	}
 	if err != nil {
 		return nil, serverErrors.HandleError("", err)
 	}
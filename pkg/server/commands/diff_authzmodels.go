@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/validation"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	defaultDiffAuthModelsPageSize         = 1000
+	defaultDiffAuthModelsMaxInvalidTuples = 100
+)
+
+// DiffAuthorizationModelsQuery compares an authorization model with a previous one of a store, and finds the tuples
+// of the store that would become invalid under it.
+type DiffAuthorizationModelsQuery struct {
+	datastore        storage.RelationshipTupleReader
+	pageSize         int
+	maxInvalidTuples int
+}
+
+type DiffAuthModelsQueryOption func(*DiffAuthorizationModelsQuery)
+
+// WithDiffAuthModelsQueryPageSize sets the number of tuples read at once when scanning the tuples of the store.
+func WithDiffAuthModelsQueryPageSize(pageSize int) DiffAuthModelsQueryOption {
+	return func(q *DiffAuthorizationModelsQuery) {
+		q.pageSize = pageSize
+	}
+}
+
+// WithDiffAuthModelsQueryMaxInvalidTuples sets how many of the tuples that would become invalid are reported.
+// All of them are counted regardless.
+func WithDiffAuthModelsQueryMaxInvalidTuples(maxInvalidTuples int) DiffAuthModelsQueryOption {
+	return func(q *DiffAuthorizationModelsQuery) {
+		q.maxInvalidTuples = maxInvalidTuples
+	}
+}
+
+func NewDiffAuthorizationModelsQuery(datastore storage.RelationshipTupleReader, opts ...DiffAuthModelsQueryOption) *DiffAuthorizationModelsQuery {
+	q := &DiffAuthorizationModelsQuery{
+		datastore:        datastore,
+		pageSize:         defaultDiffAuthModelsPageSize,
+		maxInvalidTuples: defaultDiffAuthModelsMaxInvalidTuples,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// InvalidTuple is a tuple of the store that would become invalid under the new model.
+type InvalidTuple struct {
+	Tuple  string `json:"tuple"`
+	Reason string `json:"reason"`
+}
+
+// DiffAuthorizationModelsResult is the outcome of a DiffAuthorizationModelsQuery.
+type DiffAuthorizationModelsResult struct {
+	*typesystem.ModelDiff
+
+	// ScannedTupleCount is the number of tuples of the store read to find the invalid ones. The tuples are
+	// only scanned if the diff is breaking.
+	ScannedTupleCount int64 `json:"scanned_tuple_count"`
+	// InvalidTupleCount is the number of tuples valid under the previous model which are invalid under the new one.
+	InvalidTupleCount int64 `json:"invalid_tuple_count"`
+	// InvalidTuples holds the first of those tuples, up to the configured maximum.
+	InvalidTuples []*InvalidTuple `json:"invalid_tuples,omitempty"`
+}
+
+// Execute compares the model of to with the previous model of from, and counts the tuples of the store that are
+// valid under from but wouldn't be accepted by a Write under to.
+func (q *DiffAuthorizationModelsQuery) Execute(ctx context.Context, storeID string, from, to *typesystem.TypeSystem) (*DiffAuthorizationModelsResult, error) {
+	result := &DiffAuthorizationModelsResult{
+		ModelDiff: typesystem.Diff(from, to),
+	}
+
+	// additions alone can't invalidate tuples
+	if !result.IsBreaking() {
+		return result, nil
+	}
+
+	var continuationToken string
+	for {
+		tuples, nextToken, err := q.datastore.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(int32(q.pageSize), continuationToken),
+		})
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		for _, t := range tuples {
+			result.ScannedTupleCount++
+			q.checkTuple(result, from, to, t.GetKey())
+		}
+
+		if len(nextToken) == 0 {
+			return result, nil
+		}
+		continuationToken = string(nextToken)
+	}
+}
+
+func (q *DiffAuthorizationModelsQuery) checkTuple(result *DiffAuthorizationModelsResult, from, to *typesystem.TypeSystem, tk *openfgav1.TupleKey) {
+	// tuples written under even older models aren't made invalid by the new one
+	if validation.ValidateTupleForWrite(from, tk) != nil {
+		return
+	}
+
+	err := validation.ValidateTupleForWrite(to, tk)
+	if err == nil {
+		return
+	}
+
+	result.InvalidTupleCount++
+	if len(result.InvalidTuples) < q.maxInvalidTuples {
+		result.InvalidTuples = append(result.InvalidTuples, &InvalidTuple{
+			Tuple:  tuple.TupleKeyToString(tk),
+			Reason: err.Error(),
+		})
+	}
+}
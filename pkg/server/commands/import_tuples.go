@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/storequota"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ImportTuplesCommand writes a stream of tuples in large batches, with the datastore's [storage.TupleImporter]
+// if it has one, or in batches of MaxTuplesPerWrite otherwise. Tuples that can't be written are reported,
+// along with their position in the stream, instead of failing the import. Instances may be safely shared by
+// multiple goroutines.
+type ImportTuplesCommand struct {
+	logger                    logger.Logger
+	datastore                 storage.OpenFGADatastore
+	importer                  storage.TupleImporter
+	batchSize                 int
+	conditionContextByteLimit int
+	storeQuotas               *storequota.Enforcer
+}
+
+type ImportTuplesCommandOption func(*ImportTuplesCommand)
+
+func WithImportTuplesCmdLogger(l logger.Logger) ImportTuplesCommandOption {
+	return func(c *ImportTuplesCommand) {
+		c.logger = l
+	}
+}
+
+// WithImportTuplesCmdImporter sets the importer writing the batches. It is usually the datastore itself, before
+// it gets wrapped.
+func WithImportTuplesCmdImporter(importer storage.TupleImporter) ImportTuplesCommandOption {
+	return func(c *ImportTuplesCommand) {
+		c.importer = importer
+	}
+}
+
+// WithImportTuplesCmdBatchSize sets the maximum number of tuples written at once. It is capped by the
+// MaxTuplesPerImport of the importer.
+func WithImportTuplesCmdBatchSize(batchSize int) ImportTuplesCommandOption {
+	return func(c *ImportTuplesCommand) {
+		c.batchSize = batchSize
+	}
+}
+
+func WithImportTuplesCmdConditionContextByteLimit(limit int) ImportTuplesCommandOption {
+	return func(c *ImportTuplesCommand) {
+		c.conditionContextByteLimit = limit
+	}
+}
+
+// WithImportTuplesCmdStoreQuotas sets the enforcer of the tuple quotas of the stores. The imports aren't bound by
+// the write rates of the stores.
+func WithImportTuplesCmdStoreQuotas(enforcer *storequota.Enforcer) ImportTuplesCommandOption {
+	return func(c *ImportTuplesCommand) {
+		c.storeQuotas = enforcer
+	}
+}
+
+// NewImportTuplesCommand creates an ImportTuplesCommand with specified storage.OpenFGADatastore to use for storage.
+func NewImportTuplesCommand(datastore storage.OpenFGADatastore, opts ...ImportTuplesCommandOption) *ImportTuplesCommand {
+	cmd := &ImportTuplesCommand{
+		datastore:                 datastore,
+		logger:                    logger.NewNoopLogger(),
+		batchSize:                 storage.DefaultMaxTuplesPerImport,
+		conditionContextByteLimit: config.DefaultWriteContextByteLimit,
+	}
+
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// ImportTuplesSource returns the next tuple to import, or io.EOF once there are none left.
+type ImportTuplesSource func() (*openfgav1.TupleKey, error)
+
+// ImportTuplesRowError is the reason why a tuple of the stream was not imported.
+type ImportTuplesRowError struct {
+	// Index is the position of the tuple in the stream, starting from 0.
+	Index    int64
+	TupleKey *openfgav1.TupleKey
+	Err      error
+}
+
+// ImportTuplesResult is the outcome of an import.
+type ImportTuplesResult struct {
+	ImportedCount int64
+	Errors        []*ImportTuplesRowError
+}
+
+type indexedTupleKey struct {
+	index    int64
+	tupleKey *openfgav1.TupleKey
+}
+
+// Execute imports the tuples of the source in the store, validating them against the model of typesys. It only
+// fails if the source or the datastore do, or if the import exceeds the tuple quota of the store, in which case the
+// result holds what was imported until then.
+func (c *ImportTuplesCommand) Execute(
+	ctx context.Context,
+	storeID string,
+	typesys *typesystem.TypeSystem,
+	next ImportTuplesSource,
+) (*ImportTuplesResult, error) {
+	ctx, span := tracer.Start(ctx, "ImportTuplesCommand.Execute")
+	defer span.End()
+
+	batchSize := c.batchSize
+	if c.importer != nil {
+		batchSize = min(batchSize, c.importer.MaxTuplesPerImport())
+	} else {
+		batchSize = min(batchSize, c.datastore.MaxTuplesPerWrite())
+	}
+
+	result := &ImportTuplesResult{}
+	batch := make([]indexedTupleKey, 0, batchSize)
+	inBatch := make(map[string]struct{}, batchSize)
+
+	for index := int64(0); ; index++ {
+		tk, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if err := c.validateTuple(typesys, tk); err != nil {
+			result.Errors = append(result.Errors, &ImportTuplesRowError{Index: index, TupleKey: tk, Err: err})
+			continue
+		}
+
+		// a batch with the same tuple twice could never be written
+		key := tupleUtils.TupleKeyToString(tk)
+		if _, ok := inBatch[key]; ok {
+			result.Errors = append(result.Errors, &ImportTuplesRowError{
+				Index:    index,
+				TupleKey: tk,
+				Err:      storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE),
+			})
+			continue
+		}
+		inBatch[key] = struct{}{}
+
+		batch = append(batch, indexedTupleKey{index: index, tupleKey: tk})
+		if len(batch) == batchSize {
+			if err := c.writeBatch(ctx, storeID, batch, result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+			clear(inBatch)
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := c.writeBatch(ctx, storeID, batch, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// writeBatch writes the batch in a single transaction. If some of its tuples already exist, the batch is split
+// in halves that are written separately, until the tuples that exist are isolated and reported.
+func (c *ImportTuplesCommand) writeBatch(ctx context.Context, storeID string, batch []indexedTupleKey, result *ImportTuplesResult) error {
+	if c.storeQuotas != nil {
+		if err := c.storeQuotas.CheckWrite(ctx, storeID, len(batch), 0); err != nil {
+			return err
+		}
+	}
+
+	tks := make([]*openfgav1.TupleKey, 0, len(batch))
+	for _, itk := range batch {
+		tks = append(tks, itk.tupleKey)
+	}
+
+	var err error
+	if c.importer != nil {
+		err = c.importer.ImportTuples(ctx, storeID, tks)
+	} else {
+		err = c.datastore.Write(ctx, storeID, nil, tks)
+	}
+
+	switch {
+	case err == nil:
+		result.ImportedCount += int64(len(batch))
+		return nil
+	case !errors.Is(err, storage.ErrInvalidWriteInput):
+		return err
+	case len(batch) == 1:
+		result.Errors = append(result.Errors, &ImportTuplesRowError{
+			Index:    batch[0].index,
+			TupleKey: batch[0].tupleKey,
+			Err:      storage.InvalidWriteInputError(batch[0].tupleKey, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE),
+		})
+		return nil
+	default:
+		c.logger.DebugWithContext(ctx, "splitting import batch with existing tuples",
+			zap.String("store_id", storeID),
+			zap.Int("batch_size", len(batch)),
+		)
+
+		half := len(batch) / 2
+		if err := c.writeBatch(ctx, storeID, batch[:half], result); err != nil {
+			return err
+		}
+		return c.writeBatch(ctx, storeID, batch[half:], result)
+	}
+}
+
+// validateTuple applies to the tuple the same validations as WriteCommand.
+func (c *ImportTuplesCommand) validateTuple(typesys *typesystem.TypeSystem, tk *openfgav1.TupleKey) error {
+	if err := validation.ValidateTupleForWrite(typesys, tk); err != nil {
+		return err
+	}
+
+	userObject, userRelation := tupleUtils.SplitObjectRelation(tk.GetUser())
+	if tk.GetRelation() == userRelation && tk.GetObject() == userObject {
+		return &tupleUtils.InvalidTupleError{
+			Cause:    fmt.Errorf("cannot write a tuple that is implicit"),
+			TupleKey: tk,
+		}
+	}
+
+	contextSize := proto.Size(tk.GetCondition().GetContext())
+	if contextSize > c.conditionContextByteLimit {
+		return &tupleUtils.InvalidTupleError{
+			Cause:    fmt.Errorf("condition context size limit exceeded: %d bytes exceeds %d bytes", contextSize, c.conditionContextByteLimit),
+			TupleKey: tk,
+		}
+	}
+
+	return nil
+}
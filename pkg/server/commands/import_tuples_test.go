@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func sliceImportTuplesSource(tks []*openfgav1.TupleKey) ImportTuplesSource {
+	return func() (*openfgav1.TupleKey, error) {
+		if len(tks) == 0 {
+			return nil, io.EOF
+		}
+		tk := tks[0]
+		tks = tks[1:]
+		return tk, nil
+	}
+}
+
+func TestImportTuplesCommand(t *testing.T) {
+	ctx := context.Background()
+
+	typesys, err := typesystem.NewAndValidate(ctx, testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`))
+	require.NoError(t, err)
+
+	tks := make([]*openfgav1.TupleKey, 0, 25)
+	for i := 0; i < cap(tks); i++ {
+		tks = append(tks, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne"))
+	}
+
+	tests := map[string][]ImportTuplesCommandOption{
+		"with_an_importer":    {WithImportTuplesCmdBatchSize(10)},
+		"without_an_importer": nil,
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			ds := memory.New()
+			t.Cleanup(ds.Close)
+
+			if opts != nil {
+				opts = append(opts, WithImportTuplesCmdImporter(ds.(storage.TupleImporter)))
+			}
+			cmd := NewImportTuplesCommand(ds, opts...)
+
+			t.Run("imports_all_the_tuples", func(t *testing.T) {
+				storeID := ulid.Make().String()
+
+				result, err := cmd.Execute(ctx, storeID, typesys, sliceImportTuplesSource(tks))
+				require.NoError(t, err)
+				require.Equal(t, int64(len(tks)), result.ImportedCount)
+				require.Empty(t, result.Errors)
+
+				tuples, _, err := ds.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{
+					Pagination: storage.NewPaginationOptions(100, ""),
+				})
+				require.NoError(t, err)
+				require.Len(t, tuples, len(tks))
+			})
+
+			t.Run("reports_the_tuples_that_are_not_imported", func(t *testing.T) {
+				storeID := ulid.Make().String()
+
+				// tks[7] and tks[18] already exist
+				require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tks[7], tks[18]}))
+
+				source := append([]*openfgav1.TupleKey{
+					tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+					tuple.NewTupleKey("document:x", "viewer", "document:x#viewer"),
+				}, tks...)
+				source = append(source, tks[0])
+
+				result, err := cmd.Execute(ctx, storeID, typesys, sliceImportTuplesSource(source))
+				require.NoError(t, err)
+				require.Equal(t, int64(len(tks)-2), result.ImportedCount)
+
+				var indexes []int64
+				for _, rowErr := range result.Errors {
+					require.Equal(t, source[rowErr.Index], rowErr.TupleKey)
+					indexes = append(indexes, rowErr.Index)
+				}
+				require.ElementsMatch(t, []int64{0, 1, 9, 20, 27}, indexes)
+
+				for _, rowErr := range result.Errors {
+					if rowErr.Index > 1 {
+						require.ErrorIs(t, rowErr.Err, storage.ErrInvalidWriteInput)
+					}
+				}
+			})
+		})
+	}
+
+	t.Run("fails_if_the_source_fails", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		sourceErr := errors.New("source error")
+		source := sliceImportTuplesSource(tks[:2])
+
+		result, err := NewImportTuplesCommand(ds).Execute(ctx, ulid.Make().String(), typesys, func() (*openfgav1.TupleKey, error) {
+			tk, err := source()
+			if errors.Is(err, io.EOF) {
+				return nil, sourceErr
+			}
+			return tk, err
+		})
+		require.ErrorIs(t, err, sourceErr)
+		require.Zero(t, result.ImportedCount)
+	})
+}
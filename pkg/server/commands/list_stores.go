@@ -15,6 +15,8 @@ type ListStoresQuery struct {
 	storesBackend storage.StoresBackend
 	logger        logger.Logger
 	encoder       encoder.Encoder
+	namePrefix    string
+	labelSelector storage.LabelSelector
 }
 
 type ListStoresQueryOption func(*ListStoresQuery)
@@ -31,6 +33,15 @@ func WithListStoresQueryEncoder(e encoder.Encoder) ListStoresQueryOption {
 	}
 }
 
+// WithListStoresQueryFilter filters the stores listed to those whose name starts with namePrefix, and whose labels
+// match labelSelector, if set.
+func WithListStoresQueryFilter(namePrefix string, labelSelector storage.LabelSelector) ListStoresQueryOption {
+	return func(q *ListStoresQuery) {
+		q.namePrefix = namePrefix
+		q.labelSelector = labelSelector
+	}
+}
+
 func NewListStoresQuery(storesBackend storage.StoresBackend, opts ...ListStoresQueryOption) *ListStoresQuery {
 	q := &ListStoresQuery{
 		storesBackend: storesBackend,
@@ -51,8 +62,10 @@ func (q *ListStoresQuery) Execute(ctx context.Context, req *openfgav1.ListStores
 	}
 
 	opts := storage.ListStoresOptions{
-		IDs:        storeIDs,
-		Pagination: storage.NewPaginationOptions(req.GetPageSize().GetValue(), string(decodedContToken)),
+		IDs:           storeIDs,
+		NamePrefix:    q.namePrefix,
+		LabelSelector: q.labelSelector,
+		Pagination:    storage.NewPaginationOptions(req.GetPageSize().GetValue(), string(decodedContToken)),
 	}
 	stores, continuationToken, err := q.storesBackend.ListStores(ctx, opts)
 	if err != nil {
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// UndeleteStoreCommand restores a deleted store, along with all its data, within the grace period after it was
+// deleted.
+type UndeleteStoreCommand struct {
+	undeleter   storage.StoreUndeleter
+	gracePeriod time.Duration
+	logger      logger.Logger
+}
+
+type UndeleteStoreCmdOption func(*UndeleteStoreCommand)
+
+func WithUndeleteStoreCmdLogger(l logger.Logger) UndeleteStoreCmdOption {
+	return func(c *UndeleteStoreCommand) {
+		c.logger = l
+	}
+}
+
+// WithUndeleteStoreCmdGracePeriod sets how long after they were deleted the stores can be restored. Zero, the
+// default, means however long ago.
+func WithUndeleteStoreCmdGracePeriod(gracePeriod time.Duration) UndeleteStoreCmdOption {
+	return func(c *UndeleteStoreCommand) {
+		c.gracePeriod = gracePeriod
+	}
+}
+
+func NewUndeleteStoreCommand(
+	undeleter storage.StoreUndeleter,
+	opts ...UndeleteStoreCmdOption,
+) *UndeleteStoreCommand {
+	cmd := &UndeleteStoreCommand{
+		undeleter: undeleter,
+		logger:    logger.NewNoopLogger(),
+	}
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// Execute restores the store and returns it. It returns the store unchanged if it isn't deleted, and
+// StoreIDNotFound if it doesn't exist, or was deleted before the grace period.
+func (c *UndeleteStoreCommand) Execute(ctx context.Context, storeID string) (*openfgav1.Store, error) {
+	var deletedAfter time.Time
+	if c.gracePeriod > 0 {
+		deletedAfter = time.Now().Add(-c.gracePeriod)
+	}
+
+	store, err := c.undeleter.UndeleteStore(ctx, storeID, deletedAfter)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, serverErrors.StoreIDNotFound
+		}
+		return nil, serverErrors.HandleError("Error undeleting store", err)
+	}
+
+	return store, nil
+}
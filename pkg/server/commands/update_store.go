@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// UpdateStoreCommand renames a store and replaces its labels.
+type UpdateStoreCommand struct {
+	labeler storage.StoreLabeler
+	logger  logger.Logger
+}
+
+type UpdateStoreCmdOption func(*UpdateStoreCommand)
+
+func WithUpdateStoreCmdLogger(l logger.Logger) UpdateStoreCmdOption {
+	return func(c *UpdateStoreCommand) {
+		c.logger = l
+	}
+}
+
+func NewUpdateStoreCommand(
+	labeler storage.StoreLabeler,
+	opts ...UpdateStoreCmdOption,
+) *UpdateStoreCommand {
+	cmd := &UpdateStoreCommand{
+		labeler: labeler,
+		logger:  logger.NewNoopLogger(),
+	}
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// Execute renames the store, unless name is empty, and replaces its labels, unless labels is nil, then returns it
+// along with its labels. It returns StoreIDNotFound if the store doesn't exist or is deleted.
+func (c *UpdateStoreCommand) Execute(ctx context.Context, storeID, name string, labels map[string]string) (*openfgav1.Store, map[string]string, error) {
+	store, err := c.labeler.UpdateStore(ctx, storeID, name, labels)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, serverErrors.StoreIDNotFound
+		}
+		return nil, nil, serverErrors.HandleError("Error updating store", err)
+	}
+
+	if labels == nil {
+		labels, err = c.labeler.ReadStoreLabels(ctx, storeID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, nil, serverErrors.StoreIDNotFound
+			}
+			return nil, nil, serverErrors.HandleError("Error reading store labels", err)
+		}
+	}
+
+	return store, labels, nil
+}
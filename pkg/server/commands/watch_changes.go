@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// WatchChangesQuery streams the changes of a store as they are committed. It reads the changelog with a
+// ReadChangesQuery, and waits between reads for the notifications of the datastore if it implements
+// [storage.ChangelogWatcher], or polls it with an exponential backoff otherwise.
+type WatchChangesQuery struct {
+	backend           storage.ChangelogBackend
+	watcher           storage.ChangelogWatcher
+	logger            logger.Logger
+	readChangesOpts   []ReadChangesQueryOption
+	heartbeatInterval time.Duration
+	minPollInterval   time.Duration
+	maxPollInterval   time.Duration
+}
+
+type WatchChangesQueryOption func(*WatchChangesQuery)
+
+func WithWatchChangesQueryLogger(l logger.Logger) WatchChangesQueryOption {
+	return func(q *WatchChangesQuery) {
+		q.logger = l
+	}
+}
+
+// WithWatchChangesQueryWatcher sets the watcher notifying the changes of the changelog. Without one, the changelog
+// is polled.
+func WithWatchChangesQueryWatcher(w storage.ChangelogWatcher) WatchChangesQueryOption {
+	return func(q *WatchChangesQuery) {
+		q.watcher = w
+	}
+}
+
+// WithWatchChangesQueryReadChangesOptions sets the options of the ReadChangesQuery reading the changelog.
+func WithWatchChangesQueryReadChangesOptions(opts ...ReadChangesQueryOption) WatchChangesQueryOption {
+	return func(q *WatchChangesQuery) {
+		q.readChangesOpts = opts
+	}
+}
+
+// WithWatchChangesQueryHeartbeatInterval sets how long the stream may stay idle before a heartbeat is sent.
+func WithWatchChangesQueryHeartbeatInterval(interval time.Duration) WatchChangesQueryOption {
+	return func(q *WatchChangesQuery) {
+		q.heartbeatInterval = interval
+	}
+}
+
+// WithWatchChangesQueryPollInterval sets the bounds of the interval between reads of the changelog when nothing
+// changed. Without a watcher, the interval doubles from minInterval up to maxInterval until changes are read.
+// With a watcher, the changelog is still read every maxInterval, in case a notification was missed.
+func WithWatchChangesQueryPollInterval(minInterval, maxInterval time.Duration) WatchChangesQueryOption {
+	return func(q *WatchChangesQuery) {
+		q.minPollInterval = minInterval
+		q.maxPollInterval = maxInterval
+	}
+}
+
+// NewWatchChangesQuery creates a WatchChangesQuery with specified `ChangelogBackend`.
+func NewWatchChangesQuery(backend storage.ChangelogBackend, opts ...WatchChangesQueryOption) *WatchChangesQuery {
+	q := &WatchChangesQuery{
+		backend:           backend,
+		logger:            logger.NewNoopLogger(),
+		heartbeatInterval: serverconfig.DefaultWatchChangesHeartbeatInterval,
+		minPollInterval:   serverconfig.DefaultWatchChangesMinPollInterval,
+		maxPollInterval:   serverconfig.DefaultWatchChangesMaxPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+type WatchChangesQueryParams struct {
+	StoreID string
+	// ObjectType, if set, restricts the changes to the objects of this type.
+	ObjectType string
+	// Relation, if set, restricts the changes to this relation.
+	Relation string
+	// UserType, if set, restricts the changes to the users of this type (e.g. `user`), or to this userset
+	// type (e.g. `group#member`).
+	UserType string
+	// ContinuationToken is where to resume from. Without a token or a StartTime, the changes are streamed from
+	// the start of the changelog.
+	ContinuationToken string
+	StartTime         *timestamppb.Timestamp
+	PageSize          int32
+}
+
+// WatchChangesSendFunc sends the changes read and the token to resume after them. A heartbeat has no changes.
+type WatchChangesSendFunc func(changes []*openfgav1.TupleChange, continuationToken string) error
+
+// Execute streams the changes with send until ctx is done, or send or a read of the changelog fails. It
+// returns the error of ctx once done.
+func (q *WatchChangesQuery) Execute(ctx context.Context, params *WatchChangesQueryParams, send WatchChangesSendFunc) error {
+	var notifications <-chan struct{}
+	if q.watcher != nil {
+		var err error
+		notifications, err = q.watcher.WatchChangelog(ctx, params.StoreID)
+		if err != nil {
+			q.logger.WarnWithContext(ctx, "failed to watch the changelog, falling back to polling", zap.Error(err))
+			notifications = nil
+		}
+	}
+
+	pageSize := int(params.PageSize)
+	if pageSize == 0 {
+		pageSize = storage.DefaultPageSize
+	}
+
+	readChangesQuery := NewReadChangesQuery(q.backend, q.readChangesOpts...)
+	req := &openfgav1.ReadChangesRequest{
+		StoreId:           params.StoreID,
+		Type:              params.ObjectType,
+		PageSize:          wrapperspb.Int32(int32(pageSize)),
+		ContinuationToken: params.ContinuationToken,
+		StartTime:         params.StartTime,
+	}
+
+	heartbeat := time.NewTicker(q.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	pollInterval := q.minPollInterval
+	for {
+		resp, err := readChangesQuery.Execute(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if token := resp.GetContinuationToken(); token != "" {
+			// the start time only applies until the first change is read
+			req.ContinuationToken = token
+			req.StartTime = nil
+		}
+
+		if changes := filterTupleChanges(resp.GetChanges(), params.Relation, params.UserType); len(changes) > 0 {
+			if err := send(changes, req.GetContinuationToken()); err != nil {
+				return err
+			}
+			heartbeat.Reset(q.heartbeatInterval)
+		}
+
+		if len(resp.GetChanges()) > 0 {
+			pollInterval = q.minPollInterval
+		}
+		if len(resp.GetChanges()) >= pageSize {
+			// more changes are likely pending
+			continue
+		}
+
+		wait := q.maxPollInterval
+		if notifications == nil {
+			wait = pollInterval
+			pollInterval = min(2*pollInterval, q.maxPollInterval)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case _, ok := <-notifications:
+			if !ok {
+				if ctx.Err() == nil {
+					q.logger.WarnWithContext(ctx, "changelog notifications stopped, falling back to polling")
+				}
+				notifications = nil
+			}
+		case <-timer.C:
+		case <-heartbeat.C:
+			if err := send(nil, req.GetContinuationToken()); err != nil {
+				timer.Stop()
+				return err
+			}
+		}
+		timer.Stop()
+	}
+}
+
+// filterTupleChanges returns the changes matching the relation and the user type, when set.
+func filterTupleChanges(changes []*openfgav1.TupleChange, relation, userType string) []*openfgav1.TupleChange {
+	if relation == "" && userType == "" {
+		return changes
+	}
+
+	filtered := make([]*openfgav1.TupleChange, 0, len(changes))
+	for _, change := range changes {
+		tk := change.GetTupleKey()
+		if relation != "" && tk.GetRelation() != relation {
+			continue
+		}
+		if userType != "" && !matchesUserType(tk.GetUser(), userType) {
+			continue
+		}
+		filtered = append(filtered, change)
+	}
+	return filtered
+}
+
+func matchesUserType(user, userType string) bool {
+	userObjectType, _, userRelation := tupleUtils.ToUserParts(user)
+
+	filterType, filterRelation := tupleUtils.SplitObjectRelation(userType)
+	if userObjectType != filterType {
+		return false
+	}
+	return filterRelation == "" || filterRelation == userRelation
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type watchChangesResult struct {
+	changes           []*openfgav1.TupleChange
+	continuationToken string
+}
+
+// startWatchChangesQuery runs the query in the background, and returns the channel of what it sends and a function
+// stopping it and returning its error.
+func startWatchChangesQuery(t *testing.T, q *WatchChangesQuery, params *WatchChangesQueryParams) (<-chan watchChangesResult, func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan watchChangesResult, 100)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- q.Execute(ctx, params, func(changes []*openfgav1.TupleChange, continuationToken string) error {
+			results <- watchChangesResult{changes: changes, continuationToken: continuationToken}
+			return nil
+		})
+	}()
+
+	stop := sync.OnceValue(func() error {
+		cancel()
+		return <-done
+	})
+	t.Cleanup(func() { _ = stop() })
+
+	return results, stop
+}
+
+func receiveChanges(t *testing.T, results <-chan watchChangesResult) watchChangesResult {
+	t.Helper()
+
+	for {
+		select {
+		case result := <-results:
+			if len(result.changes) > 0 {
+				return result
+			}
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no changes received")
+		}
+	}
+}
+
+func TestWatchChangesQuery(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+
+	write := func(t *testing.T, ds storage.OpenFGADatastore, storeID string, tks ...*openfgav1.TupleKey) {
+		require.NoError(t, ds.Write(ctx, storeID, nil, tks))
+	}
+
+	// with the watcher, the changelog is only polled every minute, so that changes are only streamed when notified
+	newQuery := func(ds storage.OpenFGADatastore, opts ...WatchChangesQueryOption) *WatchChangesQuery {
+		return NewWatchChangesQuery(ds, append([]WatchChangesQueryOption{
+			WithWatchChangesQueryWatcher(ds.(storage.ChangelogWatcher)),
+			WithWatchChangesQueryPollInterval(time.Millisecond, time.Minute),
+			WithWatchChangesQueryHeartbeatInterval(time.Minute),
+		}, opts...)...)
+	}
+
+	t.Run("streams_changes_as_they_are_committed", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		storeID := ulid.Make().String()
+
+		write(t, ds, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"))
+
+		results, stop := startWatchChangesQuery(t, newQuery(ds), &WatchChangesQueryParams{StoreID: storeID})
+
+		first := receiveChanges(t, results)
+		require.Len(t, first.changes, 1)
+		require.Equal(t, "document:1", first.changes[0].GetTupleKey().GetObject())
+		require.NotEmpty(t, first.continuationToken)
+
+		write(t, ds, storeID, tuple.NewTupleKey("document:2", "viewer", "user:anne"))
+
+		second := receiveChanges(t, results)
+		require.Len(t, second.changes, 1)
+		require.Equal(t, "document:2", second.changes[0].GetTupleKey().GetObject())
+
+		require.ErrorIs(t, stop(), context.Canceled)
+
+		t.Run("resumes_from_the_continuation_token", func(t *testing.T) {
+			write(t, ds, storeID, tuple.NewTupleKey("document:3", "viewer", "user:anne"))
+
+			results, _ := startWatchChangesQuery(t, newQuery(ds), &WatchChangesQueryParams{
+				StoreID:           storeID,
+				ContinuationToken: second.continuationToken,
+			})
+
+			resumed := receiveChanges(t, results)
+			require.Len(t, resumed.changes, 1)
+			require.Equal(t, "document:3", resumed.changes[0].GetTupleKey().GetObject())
+		})
+	})
+
+	t.Run("reads_all_the_pages", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		storeID := ulid.Make().String()
+
+		for _, object := range []string{"document:1", "document:2", "document:3"} {
+			write(t, ds, storeID, tuple.NewTupleKey(object, "viewer", "user:anne"))
+		}
+
+		results, _ := startWatchChangesQuery(t, newQuery(ds), &WatchChangesQueryParams{StoreID: storeID, PageSize: 2})
+
+		require.Len(t, receiveChanges(t, results).changes, 2)
+		require.Len(t, receiveChanges(t, results).changes, 1)
+	})
+
+	t.Run("filters_the_changes", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		storeID := ulid.Make().String()
+
+		write(t, ds, storeID,
+			tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:1", "editor", "user:anne"),
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("document:1", "viewer", "group:eng"),
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		)
+
+		tests := map[string]struct {
+			params   WatchChangesQueryParams
+			expected []string
+		}{
+			"object_type": {
+				params:   WatchChangesQueryParams{ObjectType: "folder"},
+				expected: []string{"folder:1#viewer@user:anne"},
+			},
+			"relation": {
+				params:   WatchChangesQueryParams{ObjectType: "document", Relation: "editor"},
+				expected: []string{"document:1#editor@user:anne"},
+			},
+			"user_type": {
+				params:   WatchChangesQueryParams{ObjectType: "document", Relation: "viewer", UserType: "group"},
+				expected: []string{"document:1#viewer@group:eng#member", "document:1#viewer@group:eng"},
+			},
+			"userset_type": {
+				params:   WatchChangesQueryParams{UserType: "group#member"},
+				expected: []string{"document:1#viewer@group:eng#member"},
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				params := test.params
+				params.StoreID = storeID
+
+				results, _ := startWatchChangesQuery(t, newQuery(ds), &params)
+
+				var actual []string
+				for _, change := range receiveChanges(t, results).changes {
+					actual = append(actual, tuple.TupleKeyToString(change.GetTupleKey()))
+				}
+				require.Equal(t, test.expected, actual)
+			})
+		}
+	})
+
+	t.Run("sends_heartbeats", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		storeID := ulid.Make().String()
+
+		write(t, ds, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"))
+
+		results, _ := startWatchChangesQuery(t,
+			newQuery(ds, WithWatchChangesQueryHeartbeatInterval(10*time.Millisecond)),
+			&WatchChangesQueryParams{StoreID: storeID, Relation: "editor"},
+		)
+
+		// the change is filtered out, but the heartbeat carries the token after it
+		select {
+		case heartbeat := <-results:
+			require.Empty(t, heartbeat.changes)
+			require.NotEmpty(t, heartbeat.continuationToken)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no heartbeat received")
+		}
+	})
+
+	t.Run("polls_without_a_watcher", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		storeID := ulid.Make().String()
+
+		q := NewWatchChangesQuery(ds,
+			WithWatchChangesQueryPollInterval(time.Millisecond, 10*time.Millisecond),
+			WithWatchChangesQueryHeartbeatInterval(time.Minute),
+		)
+		results, _ := startWatchChangesQuery(t, q, &WatchChangesQueryParams{StoreID: storeID})
+
+		time.Sleep(50 * time.Millisecond)
+		write(t, ds, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"))
+
+		require.Len(t, receiveChanges(t, results).changes, 1)
+	})
+}
+
+func TestMatchesUserType(t *testing.T) {
+	require.True(t, matchesUserType("user:anne", "user"))
+	require.True(t, matchesUserType("user:*", "user"))
+	require.True(t, matchesUserType("group:eng#member", "group"))
+	require.True(t, matchesUserType("group:eng#member", "group#member"))
+	require.False(t, matchesUserType("group:eng", "group#member"))
+	require.False(t, matchesUserType("group:eng#owner", "group#member"))
+	require.False(t, matchesUserType("user:anne", "group"))
+}
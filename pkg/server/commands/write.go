@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/storequota"
 	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
@@ -22,6 +24,7 @@ type WriteCommand struct {
 	logger                    logger.Logger
 	datastore                 storage.OpenFGADatastore
 	conditionContextByteLimit int
+	storeQuotas               *storequota.Enforcer
 }
 
 type WriteCommandOption func(*WriteCommand)
@@ -38,6 +41,13 @@ func WithConditionContextByteLimit(limit int) WriteCommandOption {
 	}
 }
 
+// WithWriteCmdStoreQuotas sets the enforcer of the tuple quotas and write rates of the stores.
+func WithWriteCmdStoreQuotas(enforcer *storequota.Enforcer) WriteCommandOption {
+	return func(wc *WriteCommand) {
+		wc.storeQuotas = enforcer
+	}
+}
+
 // NewWriteCommand creates a WriteCommand with specified storage.OpenFGADatastore to use for storage.
 func NewWriteCommand(datastore storage.OpenFGADatastore, opts ...WriteCommandOption) *WriteCommand {
 	cmd := &WriteCommand{
@@ -53,16 +63,41 @@ func NewWriteCommand(datastore storage.OpenFGADatastore, opts ...WriteCommandOpt
 }
 
 // Execute deletes and writes the specified tuples. Deletes are applied first, then writes.
-func (c *WriteCommand) Execute(ctx context.Context, req *openfgav1.WriteRequest) (*openfgav1.WriteResponse, error) {
+// The options set the write modes of the request, such as ignoring the tuples to write that already
+// exist, or the preconditions the store must satisfy for the write to be applied.
+func (c *WriteCommand) Execute(ctx context.Context, req *openfgav1.WriteRequest, opts ...storage.TupleWriteOption) (*openfgav1.WriteResponse, error) {
 	if err := c.validateWriteRequest(ctx, req); err != nil {
 		return nil, err
 	}
 
+	options := storage.NewTupleWriteOptions(opts...)
+	if err := c.validatePreconditions(options.Preconditions); err != nil {
+		return nil, err
+	}
+
+	if !options.ExpiresAt.IsZero() && !options.ExpiresAt.After(time.Now()) {
+		return nil, serverErrors.ValidationError(fmt.Errorf("the expiry of the tuples must be in the future"))
+	}
+
+	if c.storeQuotas != nil {
+		deletes, writes := len(req.GetDeletes().GetTupleKeys()), len(req.GetWrites().GetTupleKeys())
+		// the deletes of tuples which may not exist don't make room for the writes. The tuple quota is soft, checked
+		// against the count before the write rather than within its transaction, see storequota.Enforcer.CheckWrite
+		added := writes
+		if options.OnMissingDelete != storage.OnMissingDeleteIgnore {
+			added -= deletes
+		}
+		if err := c.storeQuotas.CheckWrite(ctx, req.GetStoreId(), added, deletes+writes); err != nil {
+			return nil, err
+		}
+	}
+
 	err := c.datastore.Write(
 		ctx,
 		req.GetStoreId(),
 		req.GetDeletes().GetTupleKeys(),
 		req.GetWrites().GetTupleKeys(),
+		opts...,
 	)
 	if err != nil {
 		return nil, serverErrors.HandleError("", err)
@@ -170,6 +205,26 @@ func (c *WriteCommand) validateNoDuplicatesAndCorrectSize(
 	return nil
 }
 
+// validatePreconditions ensures the tuples of the preconditions are well-formed and their number fits.
+func (c *WriteCommand) validatePreconditions(preconditions []storage.WritePrecondition) error {
+	for _, precondition := range preconditions {
+		tk := precondition.TupleKey
+		if !tupleUtils.IsValidObject(tk.GetObject()) || !tupleUtils.IsValidRelation(tk.GetRelation()) || !tupleUtils.IsValidUser(tk.GetUser()) {
+			return serverErrors.ValidationError(
+				&tupleUtils.InvalidTupleError{
+					Cause:    fmt.Errorf("the precondition tuple is malformed"),
+					TupleKey: tk,
+				},
+			)
+		}
+	}
+
+	if len(preconditions) > c.datastore.MaxTuplesPerWrite() {
+		return serverErrors.ExceededEntityLimit("write preconditions", c.datastore.MaxTuplesPerWrite())
+	}
+	return nil
+}
+
 // validateNotImplicit ensures the tuple to be written (not deleted) is not of the form `object:id # relation @ object:id#relation`.
 func (c *WriteCommand) validateNotImplicit(
 	tk *openfgav1.TupleKey,
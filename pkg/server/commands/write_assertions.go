@@ -7,6 +7,7 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/openfga/openfga/internal/storequota"
 	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
@@ -24,6 +25,7 @@ type WriteAssertionsCommand struct {
 	datastore               storage.OpenFGADatastore
 	logger                  logger.Logger
 	maxAssertionSizeInBytes int
+	storeQuotas             *storequota.Enforcer
 }
 
 type WriteAssertionsCmdOption func(*WriteAssertionsCommand)
@@ -34,6 +36,13 @@ func WithWriteAssertCmdLogger(l logger.Logger) WriteAssertionsCmdOption {
 	}
 }
 
+// WithWriteAssertCmdStoreQuotas sets the enforcer of the assertion quotas of the stores.
+func WithWriteAssertCmdStoreQuotas(enforcer *storequota.Enforcer) WriteAssertionsCmdOption {
+	return func(c *WriteAssertionsCommand) {
+		c.storeQuotas = enforcer
+	}
+}
+
 func NewWriteAssertionsCommand(
 	datastore storage.OpenFGADatastore, opts ...WriteAssertionsCmdOption) *WriteAssertionsCommand {
 	cmd := &WriteAssertionsCommand{
@@ -80,6 +89,12 @@ func (w *WriteAssertionsCommand) Execute(ctx context.Context, req *openfgav1.Wri
 		return nil, serverErrors.ExceededEntityLimit("bytes", w.maxAssertionSizeInBytes)
 	}
 
+	if w.storeQuotas != nil {
+		if err := w.storeQuotas.CheckAssertions(store, len(assertions)); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, assertion := range assertions {
 		// an assertion should be validated the same as the input tuple key to a Check request
 		if err := validation.ValidateUserObjectRelation(typesys, tupleUtils.ConvertAssertionTupleKeyToTupleKey(assertion.GetTupleKey())); err != nil {
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/storequota"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
@@ -161,4 +162,47 @@ func TestWriteAssertions(t *testing.T) {
 		require.Nil(t, resp)
 		require.ErrorIs(t, err, serverErrors.ExceededEntityLimit("bytes", DefaultMaxAssertionSizeInBytes))
 	})
+	t.Run("validates_the_assertion_quota_of_the_store", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]`)
+
+		assertion := func(user string) *openfgav1.Assertion {
+			return &openfgav1.Assertion{
+				TupleKey:    &openfgav1.AssertionTupleKey{Object: "document:roadmap", Relation: "viewer", User: user},
+				Expectation: true,
+			}
+		}
+
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.GetId()).Times(2).Return(model, nil)
+		mockDatastore.EXPECT().WriteAssertions(gomock.Any(), storeID, model.GetId(), gomock.Len(1)).Times(1).Return(nil)
+
+		enforcer, err := storequota.New(storequota.Config{Quota: storequota.Quota{MaxAssertions: 1}}, nil)
+		require.NoError(t, err)
+		cmd := NewWriteAssertionsCommand(mockDatastore, WithWriteAssertCmdStoreQuotas(enforcer))
+
+		_, err = cmd.Execute(context.Background(), &openfgav1.WriteAssertionsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Assertions:           []*openfgav1.Assertion{assertion("user:anne")},
+		})
+		require.NoError(t, err)
+
+		resp, err := cmd.Execute(context.Background(), &openfgav1.WriteAssertionsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Assertions:           []*openfgav1.Assertion{assertion("user:anne"), assertion("user:bob")},
+		})
+		require.Nil(t, resp)
+		require.ErrorIs(t, err, serverErrors.StoreAssertionQuotaExceeded(1))
+	})
 }
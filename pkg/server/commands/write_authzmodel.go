@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/storequota"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
@@ -22,6 +23,8 @@ type WriteAuthorizationModelCommand struct {
 	backend                          storage.TypeDefinitionWriteBackend
 	logger                           logger.Logger
 	maxAuthorizationModelSizeInBytes int
+	storeQuotas                      *storequota.Enforcer
+	models                           storage.AuthorizationModelReadBackend
 }
 
 type WriteAuthModelOption func(*WriteAuthorizationModelCommand)
@@ -38,6 +41,15 @@ func WithWriteAuthModelMaxSizeInBytes(size int) WriteAuthModelOption {
 	}
 }
 
+// WithWriteAuthModelStoreQuotas sets the enforcer of the authorization model quotas of the stores, which counts the
+// models of the stores with models.
+func WithWriteAuthModelStoreQuotas(enforcer *storequota.Enforcer, models storage.AuthorizationModelReadBackend) WriteAuthModelOption {
+	return func(m *WriteAuthorizationModelCommand) {
+		m.storeQuotas = enforcer
+		m.models = models
+	}
+}
+
 func NewWriteAuthorizationModelCommand(backend storage.TypeDefinitionWriteBackend, opts ...WriteAuthModelOption) *WriteAuthorizationModelCommand {
 	model := &WriteAuthorizationModelCommand{
 		backend:                          backend,
@@ -84,6 +96,12 @@ func (w *WriteAuthorizationModelCommand) Execute(ctx context.Context, req *openf
 		return nil, serverErrors.InvalidAuthorizationModelInput(err)
 	}
 
+	if w.storeQuotas != nil {
+		if err := w.storeQuotas.CheckAuthorizationModelWrite(ctx, req.GetStoreId(), w.models); err != nil {
+			return nil, err
+		}
+	}
+
 	err = w.backend.WriteAuthorizationModel(ctx, req.GetStoreId(), model)
 	if err != nil {
 		return nil, serverErrors.
@@ -11,6 +11,9 @@ import (
 	"go.uber.org/mock/gomock"
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/storequota"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -57,3 +60,36 @@ func TestWriteAuthorizationModel(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteAuthorizationModelWithStoreQuotas(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	enforcer, err := storequota.New(storequota.Config{Quota: storequota.Quota{MaxAuthorizationModels: 2}}, nil)
+	require.NoError(t, err)
+	cmd := NewWriteAuthorizationModelCommand(ds, WithWriteAuthModelStoreQuotas(enforcer, ds))
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+	}
+	for i := 0; i < 2; i++ {
+		_, err = cmd.Execute(ctx, req)
+		require.NoError(t, err)
+	}
+
+	_, err = cmd.Execute(ctx, req)
+	require.Equal(t, serverErrors.StoreAuthorizationModelQuotaExceeded(2), err)
+
+	// the quota is per store
+	_, err = cmd.Execute(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         ulid.Make().String(),
+		TypeDefinitions: req.GetTypeDefinitions(),
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+	})
+	require.NoError(t, err)
+}
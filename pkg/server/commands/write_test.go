@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -16,8 +17,10 @@ import (
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
 	"github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/storequota"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/testutils"
 	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
@@ -272,6 +275,153 @@ func TestTransactionalWriteFailedError(t *testing.T) {
 	require.Nil(t, resp)
 }
 
+func TestWriteCommandWithTupleWriteOptions(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define owner: [user]
+				define viewer: [user]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+
+	owner := tuple.NewTupleKey("document:1", "owner", "user:anne")
+	viewer := tuple.NewTupleKey("document:1", "viewer", "user:bob")
+
+	writeReq := func(tks ...*openfgav1.TupleKey) *openfgav1.WriteRequest {
+		return &openfgav1.WriteRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Writes:               &openfgav1.WriteRequestWrites{TupleKeys: tks},
+		}
+	}
+
+	cmd := NewWriteCommand(ds)
+
+	_, err := cmd.Execute(ctx, writeReq(owner))
+	require.NoError(t, err)
+
+	t.Run("ignores_duplicate_writes", func(t *testing.T) {
+		_, err := cmd.Execute(ctx, writeReq(owner), storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore))
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(ctx, writeReq(owner))
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_write_failed_due_to_invalid_input), status.Code(err))
+	})
+
+	t.Run("returns_failed_precondition_if_a_precondition_does_not_hold", func(t *testing.T) {
+		_, err := cmd.Execute(ctx, writeReq(viewer), storage.WithPreconditions(storage.WritePrecondition{
+			TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(owner),
+			Exists:   false,
+		}))
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = cmd.Execute(ctx, writeReq(viewer), storage.WithPreconditions(storage.WritePrecondition{
+			TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(owner),
+			Exists:   true,
+		}))
+		require.NoError(t, err)
+	})
+
+	t.Run("validates_the_preconditions", func(t *testing.T) {
+		_, err := cmd.Execute(ctx, writeReq(viewer), storage.WithPreconditions(storage.WritePrecondition{
+			TupleKey: &openfgav1.TupleKeyWithoutCondition{Object: "document:1", Relation: "owner", User: "anne bob"},
+		}))
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("rejects_an_expiry_in_the_past", func(t *testing.T) {
+		expiring := tuple.NewTupleKey("document:2", "viewer", "user:bob")
+
+		_, err := cmd.Execute(ctx, writeReq(expiring), storage.WithExpiresAt(time.Now().Add(-time.Minute)))
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+
+		_, err = cmd.Execute(ctx, writeReq(expiring), storage.WithExpiresAt(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+	})
+}
+
+func TestWriteCommandWithStoreQuotas(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+
+	anne := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	bob := tuple.NewTupleKey("document:1", "viewer", "user:bob")
+	charlie := tuple.NewTupleKey("document:1", "viewer", "user:charlie")
+
+	writeReq := func(deletes []*openfgav1.TupleKey, writes ...*openfgav1.TupleKey) *openfgav1.WriteRequest {
+		req := &openfgav1.WriteRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+		}
+		if len(writes) > 0 {
+			req.Writes = &openfgav1.WriteRequestWrites{TupleKeys: writes}
+		}
+		if len(deletes) > 0 {
+			req.Deletes = &openfgav1.WriteRequestDeletes{}
+			for _, tk := range deletes {
+				req.Deletes.TupleKeys = append(req.Deletes.TupleKeys, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+			}
+		}
+		return req
+	}
+
+	t.Run("tuple_quota", func(t *testing.T) {
+		enforcer, err := storequota.New(storequota.Config{Quota: storequota.Quota{MaxTuples: 2}}, ds.(storage.TupleCounter))
+		require.NoError(t, err)
+		cmd := NewWriteCommand(ds, WithWriteCmdStoreQuotas(enforcer))
+
+		_, err = cmd.Execute(ctx, writeReq(nil, anne, bob))
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(ctx, writeReq(nil, charlie))
+		require.Equal(t, serverErrors.StoreTupleQuotaExceeded(2), err)
+
+		// the tuples deleted make room for the tuples written, unless they may not exist
+		_, err = cmd.Execute(ctx, writeReq([]*openfgav1.TupleKey{bob}, charlie), storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore))
+		require.Equal(t, serverErrors.StoreTupleQuotaExceeded(2), err)
+
+		_, err = cmd.Execute(ctx, writeReq([]*openfgav1.TupleKey{bob}, charlie))
+		require.NoError(t, err)
+	})
+
+	t.Run("write_rate", func(t *testing.T) {
+		enforcer, err := storequota.New(storequota.Config{
+			StoreQuotas: map[string]storequota.Quota{storeID: {WriteRate: 0.001}},
+		}, nil)
+		require.NoError(t, err)
+		cmd := NewWriteCommand(ds, WithWriteCmdStoreQuotas(enforcer))
+
+		_, err = cmd.Execute(ctx, writeReq([]*openfgav1.TupleKey{anne}))
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(ctx, writeReq(nil, anne))
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Equal(t, serverErrors.StoreWriteRateExceeded(0.001), err)
+	})
+}
+
 func TestValidateConditionsInTuples(t *testing.T) {
 	type test struct {
 		name          string
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DiffAuthorizationModels compares an authorization model, as it would be written with WriteAuthorizationModel, with
+// a model of the store (the latest by default). Along with the types, relations, rewrites, type restrictions and
+// conditions that changed, it reports the tuples of the store that the new model would make invalid. Since it is
+// meant to be called before writing the model, it requires the same permission as WriteAuthorizationModel.
+func (s *Server) DiffAuthorizationModels(ctx context.Context, req *DiffAuthorizationModelsRequest) (*DiffAuthorizationModelsResponse, error) {
+	ctx, span := tracer.Start(ctx, authz.DiffAuthorizationModels, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.DiffAuthorizationModels,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.DiffAuthorizationModels)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion := req.GetModel().GetSchemaVersion()
+	if schemaVersion == "" {
+		schemaVersion = typesystem.SchemaVersion1_1
+	}
+
+	to, err := typesystem.NewAndValidate(ctx, &openfgav1.AuthorizationModel{
+		Id:              ulid.Make().String(),
+		SchemaVersion:   schemaVersion,
+		TypeDefinitions: req.GetModel().GetTypeDefinitions(),
+		Conditions:      req.GetModel().GetConditions(),
+	})
+	if err != nil {
+		return nil, serverErrors.InvalidAuthorizationModelInput(err)
+	}
+
+	from, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []commands.DiffAuthModelsQueryOption{}
+	if req.MaxInvalidTuples > 0 {
+		opts = append(opts, commands.WithDiffAuthModelsQueryMaxInvalidTuples(int(req.MaxInvalidTuples)))
+	}
+
+	resp, err := commands.NewDiffAuthorizationModelsQuery(s.datastore, opts...).Execute(ctx, req.GetStoreId(), from, to)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Bool("breaking", resp.IsBreaking()),
+		attribute.Int64("invalid_tuple_count", resp.InvalidTupleCount),
+	)
+
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/pkg/server/commands"
+)
+
+const (
+	diffAuthorizationModelsServiceName = "openfga.v1.DiffAuthorizationModelsService"
+	diffAuthorizationModelsFullMethod  = "/" + diffAuthorizationModelsServiceName + "/DiffAuthorizationModels"
+	diffAuthorizationModelsHTTPPath    = "/stores/{store_id}/authorization-models/diff"
+)
+
+// DiffAuthorizationModelsRequest is the request of the DiffAuthorizationModels RPC, served by a JSON encoded gRPC
+// service (see jsoncodec). Its JSON representation is that of the WriteAuthorizationModel request, plus the optional
+// id of the model to compare with and the maximum number of invalid tuples reported.
+type DiffAuthorizationModelsRequest struct {
+	// Model is the new authorization model, as it would be written.
+	Model *openfgav1.WriteAuthorizationModelRequest
+	// AuthorizationModelID is the id of the model to compare with. Empty means the latest model of the store.
+	AuthorizationModelID string
+	// MaxInvalidTuples is the maximum number of invalid tuples reported. Zero means the default.
+	MaxInvalidTuples uint32
+}
+
+// DiffAuthorizationModelsResponse is the outcome of DiffAuthorizationModels.
+type DiffAuthorizationModelsResponse = commands.DiffAuthorizationModelsResult
+
+func (r *DiffAuthorizationModelsRequest) GetModel() *openfgav1.WriteAuthorizationModelRequest {
+	if r == nil {
+		return nil
+	}
+	return r.Model
+}
+
+func (r *DiffAuthorizationModelsRequest) GetStoreId() string { //nolint:revive,stylecheck
+	return r.GetModel().GetStoreId()
+}
+
+func (r *DiffAuthorizationModelsRequest) GetAuthorizationModelId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.AuthorizationModelID
+}
+
+// Validate applies the same rules as the WriteAuthorizationModel request.
+func (r *DiffAuthorizationModelsRequest) Validate() error {
+	if r.GetModel() == nil {
+		return errors.New("invalid DiffAuthorizationModelsRequest: missing model")
+	}
+	return r.GetModel().Validate()
+}
+
+type diffAuthorizationModelsOptionsJSON struct {
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	MaxInvalidTuples     uint32 `json:"max_invalid_tuples,omitempty"`
+}
+
+func (r *DiffAuthorizationModelsRequest) MarshalJSON() ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+
+	model, err := marshalProtoField(r.GetModel())
+	if err != nil {
+		return nil, err
+	}
+	if model != nil {
+		if err := json.Unmarshal(model, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.AuthorizationModelID != "" {
+		fields["authorization_model_id"], _ = json.Marshal(r.AuthorizationModelID)
+	}
+	if r.MaxInvalidTuples > 0 {
+		fields["max_invalid_tuples"], _ = json.Marshal(r.MaxInvalidTuples)
+	}
+
+	return json.Marshal(fields)
+}
+
+func (r *DiffAuthorizationModelsRequest) UnmarshalJSON(data []byte) error {
+	var options diffAuthorizationModelsOptionsJSON
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+
+	model := &openfgav1.WriteAuthorizationModelRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, model); err != nil {
+		return err
+	}
+
+	*r = DiffAuthorizationModelsRequest{
+		Model:                model,
+		AuthorizationModelID: options.AuthorizationModelID,
+		MaxInvalidTuples:     options.MaxInvalidTuples,
+	}
+	return nil
+}
+
+// DiffAuthorizationModelsServiceServer is the server API for the DiffAuthorizationModels service.
+type DiffAuthorizationModelsServiceServer interface {
+	DiffAuthorizationModels(context.Context, *DiffAuthorizationModelsRequest) (*DiffAuthorizationModelsResponse, error)
+}
+
+var _ DiffAuthorizationModelsServiceServer = (*Server)(nil)
+
+// DiffAuthorizationModelsServiceDesc is the grpc.ServiceDesc for the DiffAuthorizationModels service.
+var DiffAuthorizationModelsServiceDesc = grpc.ServiceDesc{
+	ServiceName: diffAuthorizationModelsServiceName,
+	HandlerType: (*DiffAuthorizationModelsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DiffAuthorizationModels",
+			Handler:    diffAuthorizationModelsHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterDiffAuthorizationModelsServiceServer registers the DiffAuthorizationModels service in the given gRPC
+// server. It must be registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterDiffAuthorizationModelsServiceServer(s grpc.ServiceRegistrar, srv DiffAuthorizationModelsServiceServer) {
+	s.RegisterService(&DiffAuthorizationModelsServiceDesc, srv)
+}
+
+func diffAuthorizationModelsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffAuthorizationModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiffAuthorizationModelsServiceServer).DiffAuthorizationModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: diffAuthorizationModelsFullMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiffAuthorizationModelsServiceServer).DiffAuthorizationModels(ctx, req.(*DiffAuthorizationModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DiffAuthorizationModelsServiceClient is the client API for the DiffAuthorizationModels service.
+type DiffAuthorizationModelsServiceClient interface {
+	DiffAuthorizationModels(ctx context.Context, in *DiffAuthorizationModelsRequest, opts ...grpc.CallOption) (*DiffAuthorizationModelsResponse, error)
+}
+
+type diffAuthorizationModelsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDiffAuthorizationModelsServiceClient(cc grpc.ClientConnInterface) DiffAuthorizationModelsServiceClient {
+	return &diffAuthorizationModelsServiceClient{cc: cc}
+}
+
+func (c *diffAuthorizationModelsServiceClient) DiffAuthorizationModels(ctx context.Context, in *DiffAuthorizationModelsRequest, opts ...grpc.CallOption) (*DiffAuthorizationModelsResponse, error) {
+	out := new(DiffAuthorizationModelsResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, diffAuthorizationModelsFullMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterDiffAuthorizationModelsServiceHandler registers the HTTP route of DiffAuthorizationModels
+// (POST /stores/{store_id}/authorization-models/diff) in the gateway mux.
+func RegisterDiffAuthorizationModelsServiceHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := NewDiffAuthorizationModelsServiceClient(conn)
+
+	return handleJSONPath(mux, diffAuthorizationModelsHTTPPath, diffAuthorizationModelsFullMethod,
+		func(req *DiffAuthorizationModelsRequest, storeID string) {
+			if req.Model == nil {
+				req.Model = &openfgav1.WriteAuthorizationModelRequest{}
+			}
+			req.Model.StoreId = storeID
+		},
+		client.DiffAuthorizationModels,
+	)
+}
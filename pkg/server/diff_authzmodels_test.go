@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestDiffAuthorizationModels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "diff"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId: storeID,
+		TypeDefinitions: testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user]
+
+			type document
+				relations
+					define viewer: [user, group#member]`).GetTypeDefinitions(),
+		SchemaVersion: "1.1",
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("document:2", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("group:eng", "member", "user:bob"),
+		}},
+	})
+	require.NoError(t, err)
+
+	newModel := func(dsl string) *openfgav1.WriteAuthorizationModelRequest {
+		model := testutils.MustTransformDSLToProtoWithID(dsl)
+		return &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			TypeDefinitions: model.GetTypeDefinitions(),
+			SchemaVersion:   model.GetSchemaVersion(),
+			Conditions:      model.GetConditions(),
+		}
+	}
+
+	t.Run("reports_the_tuples_made_invalid", func(t *testing.T) {
+		resp, err := s.DiffAuthorizationModels(ctx, &DiffAuthorizationModelsRequest{
+			Model: newModel(`
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user]
+
+				type document
+					relations
+						define viewer: [user]
+						define editor: [user]`),
+			MaxInvalidTuples: 1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document#editor"}, resp.AddedRelations)
+		require.Equal(t, []typesystem.NarrowedTypeRestriction{
+			{Relation: "document#viewer", RemovedTypes: []string{"group#member"}},
+		}, resp.NarrowedTypeRestrictions)
+		require.True(t, resp.IsBreaking())
+		require.Equal(t, int64(4), resp.ScannedTupleCount)
+		require.Equal(t, int64(2), resp.InvalidTupleCount)
+		require.Len(t, resp.InvalidTuples, 1)
+	})
+
+	t.Run("does_not_scan_the_tuples_without_breaking_changes", func(t *testing.T) {
+		resp, err := s.DiffAuthorizationModels(ctx, &DiffAuthorizationModelsRequest{
+			Model: newModel(`
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user]
+
+				type document
+					relations
+						define viewer: [user, group#member]
+
+				type folder`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"folder"}, resp.AddedTypes)
+		require.False(t, resp.IsBreaking())
+		require.Zero(t, resp.ScannedTupleCount)
+	})
+
+	t.Run("rejects_an_invalid_model", func(t *testing.T) {
+		_, err := s.DiffAuthorizationModels(ctx, &DiffAuthorizationModelsRequest{
+			Model: &openfgav1.WriteAuthorizationModelRequest{
+				StoreId: storeID,
+				TypeDefinitions: []*openfgav1.TypeDefinition{
+					{Type: "document", Relations: map[string]*openfgav1.Userset{"viewer": typesystem.ComputedUserset("unknown")}},
+				},
+				SchemaVersion: typesystem.SchemaVersion1_1,
+			},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+	})
+}
+
+func TestDiffAuthorizationModelsJSON(t *testing.T) {
+	in := &DiffAuthorizationModelsRequest{
+		Model: &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         "01JA6WMC6ZPRWQVEH3DVGWF6QS",
+			TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+		},
+		AuthorizationModelID: "01JA6WMDCWVC5PFSRR5ZS5H0K1",
+		MaxInvalidTuples:     5,
+	}
+
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"store_id": "01JA6WMC6ZPRWQVEH3DVGWF6QS",
+		"type_definitions": [{"type": "user"}],
+		"schema_version": "1.1",
+		"authorization_model_id": "01JA6WMDCWVC5PFSRR5ZS5H0K1",
+		"max_invalid_tuples": 5
+	}`, string(data))
+
+	var out DiffAuthorizationModelsRequest
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, in.AuthorizationModelID, out.GetAuthorizationModelId())
+	require.Equal(t, in.MaxInvalidTuples, out.MaxInvalidTuples)
+	require.Equal(t, in.GetStoreId(), out.GetStoreId())
+
+	resp, err := json.Marshal(&DiffAuthorizationModelsResponse{
+		ModelDiff:         &typesystem.ModelDiff{AddedTypes: []string{"folder"}},
+		InvalidTupleCount: 1,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"added_types": ["folder"], "scanned_tuple_count": 0, "invalid_tuple_count": 1}`, string(resp))
+}
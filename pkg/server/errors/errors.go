@@ -21,6 +21,7 @@ var (
 	AuthorizationModelResolutionTooComplex = status.Error(codes.Code(openfgav1.ErrorCode_authorization_model_resolution_too_complex), "Authorization Model resolution required too many rewrite rules to be resolved. Check your authorization model for infinite recursion or too much nesting")
 	InvalidWriteInput                      = status.Error(codes.Code(openfgav1.ErrorCode_invalid_write_input), "Invalid input. Make sure you provide at least one write, or at least one delete")
 	InvalidContinuationToken               = status.Error(codes.Code(openfgav1.ErrorCode_invalid_continuation_token), "Invalid continuation token")
+	ContinuationTokenExpired               = status.Error(codes.OutOfRange, "Continuation token expired: the changes it points to were deleted by the changelog retention. Read the changes again without a continuation token")
 	InvalidStartTime                       = status.Error(codes.Code(openfgav1.ErrorCode_invalid_start_time), "Invalid start time")
 	InvalidExpandInput                     = status.Error(codes.Code(openfgav1.ErrorCode_invalid_expand_input), "Invalid input. Make sure you provide an object and a relation")
 	UnsupportedUserSet                     = status.Error(codes.Code(openfgav1.ErrorCode_unsupported_user_set), "Userset is not supported (right now)")
@@ -101,6 +102,27 @@ func ExceededEntityLimit(entity string, limit int) error {
 		fmt.Sprintf("The number of %s exceeds the allowed limit of %d", entity, limit))
 }
 
+// StoreTupleQuotaExceeded is returned when a write would take the number of tuples of a store over its quota.
+func StoreTupleQuotaExceeded(limit int64) error {
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("store tuple quota exceeded: the write would take the store over its quota of %d tuples", limit))
+}
+
+// StoreAuthorizationModelQuotaExceeded is returned when a store already has as many authorization models as its quota.
+func StoreAuthorizationModelQuotaExceeded(limit int) error {
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("store authorization model quota exceeded: the store already has its quota of %d authorization models", limit))
+}
+
+// StoreAssertionQuotaExceeded is returned when more assertions are written for an authorization model than the quota
+// of its store.
+func StoreAssertionQuotaExceeded(limit int) error {
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("store assertion quota exceeded: the number of assertions exceeds the quota of %d of the store", limit))
+}
+
+// StoreWriteRateExceeded is returned when a store writes tuples faster than its quota.
+func StoreWriteRateExceeded(rate float64) error {
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("store write rate exceeded: the store writes more than its quota of %g tuples per second", rate))
+}
+
 func DuplicateTupleInWrite(tk tuple.TupleWithoutCondition) error {
 	return status.Error(codes.Code(openfgav1.ErrorCode_cannot_allow_duplicate_tuples_in_one_request), fmt.Sprintf("duplicate tuple in write: user: '%s', relation: '%s', object: '%s'", tk.GetUser(), tk.GetRelation(), tk.GetObject()))
 }
@@ -112,6 +134,11 @@ func WriteFailedDueToInvalidInput(err error) error {
 	return status.Error(codes.Code(openfgav1.ErrorCode_write_failed_due_to_invalid_input), "Write failed due to invalid input")
 }
 
+// WritePreconditionFailed is returned when a precondition of a write does not hold.
+func WritePreconditionFailed(err error) error {
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
 func InvalidAuthorizationModelInput(err error) error {
 	return status.Error(codes.Code(openfgav1.ErrorCode_invalid_authorization_model), err.Error())
 }
@@ -124,12 +151,18 @@ func HandleError(public string, err error) error {
 		return status.Error(codes.Aborted, err.Error())
 	case errors.Is(err, storage.ErrInvalidWriteInput):
 		return WriteFailedDueToInvalidInput(err)
+	case errors.Is(err, storage.ErrPreconditionFailed):
+		return WritePreconditionFailed(err)
 	case errors.Is(err, storage.ErrInvalidContinuationToken):
 		return InvalidContinuationToken
+	case errors.Is(err, storage.ErrContinuationTokenExpired):
+		return ContinuationTokenExpired
 	case errors.Is(err, storage.ErrInvalidStartTime):
 		return InvalidStartTime
 	case errors.Is(err, storage.ErrMismatchObjectType):
 		return MismatchObjectType
+	case errors.Is(err, storage.ErrRevisionUnavailable):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	case errors.Is(err, context.Canceled):
 		// cancel by a client is not an "internal server error"
 		return RequestCancelled
@@ -60,6 +60,10 @@ func TestHandleErrors(t *testing.T) {
 			storageErr:              storage.ErrInvalidContinuationToken,
 			expectedTranslatedError: InvalidContinuationToken,
 		},
+		`expired_continuation_token`: {
+			storageErr:              storage.ErrContinuationTokenExpired,
+			expectedTranslatedError: ContinuationTokenExpired,
+		},
 		`invalid_token_for_read_changes_api`: {
 			storageErr:              storage.ErrMismatchObjectType,
 			expectedTranslatedError: MismatchObjectType,
@@ -76,6 +80,14 @@ func TestHandleErrors(t *testing.T) {
 			storageErr:              storage.ErrInvalidWriteInput,
 			expectedTranslatedError: WriteFailedDueToInvalidInput(storage.ErrInvalidWriteInput),
 		},
+		`precondition_failed`: {
+			storageErr:              storage.ErrPreconditionFailed,
+			expectedTranslatedError: status.Error(codes.FailedPrecondition, storage.ErrPreconditionFailed.Error()),
+		},
+		`revision_unavailable`: {
+			storageErr:              storage.ErrRevisionUnavailable,
+			expectedTranslatedError: status.Error(codes.FailedPrecondition, storage.ErrRevisionUnavailable.Error()),
+		},
 		`transaction_failed`: {
 			storageErr:              storage.ErrTransactionalWriteFailed,
 			expectedTranslatedError: status.Error(codes.Aborted, storage.ErrTransactionalWriteFailed.Error()),
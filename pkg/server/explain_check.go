@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/utils"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// ExplainCheck resolves a Check and returns, along with its outcome, the resolution tree that led to it:
+// the rewrites evaluated, the tuples that matched, the conditions evaluated and the branches that
+// short-circuited. Since the explanation reveals tuples, calling it requires the same permission as Expand.
+//
+// Unlike Check, the resolution bypasses the check query cache and dispatch throttling, so that every
+// subproblem shows up in the tree.
+func (s *Server) ExplainCheck(ctx context.Context, req *ExplainCheckRequest) (*ExplainCheckResponse, error) {
+	start := time.Now()
+
+	tk := req.GetCheckRequest().GetTupleKey()
+	ctx, span := tracer.Start(ctx, authz.ExplainCheck, trace.WithAttributes(
+		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreId())},
+		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
+		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
+		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.ExplainCheck,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.ExplainCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	checkReq := req.GetCheckRequest()
+
+	typesys, err := s.resolveTypesystem(ctx, checkReq.GetStoreId(), checkReq.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	explainer := graph.NewCheckExplainer(
+		graph.WithMaxExplanationNodes(req.MaxNodes),
+		graph.WithMaxExplanationTuplesPerNode(req.MaxTuplesPerNode),
+	)
+	ctx = graph.ContextWithCheckExplainer(ctx, explainer)
+
+	checkQuery := commands.NewCheckCommand(
+		s.datastore,
+		s.explainCheckResolver,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithCheckCommandResolveNodeLimit(s.resolveNodeLimit),
+	)
+
+	resp, checkRequestMetadata, err := checkQuery.Execute(ctx, &commands.CheckCommandParams{
+		StoreID:          checkReq.GetStoreId(),
+		TupleKey:         checkReq.GetTupleKey(),
+		ContextualTuples: checkReq.GetContextualTuples(),
+		Context:          checkReq.GetContext(),
+		Consistency:      checkReq.GetConsistency(),
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, commands.CheckCommandErrorToServerError(err)
+	}
+
+	const methodName = "explaincheck"
+
+	span.SetAttributes(attribute.Bool("allowed", resp.GetAllowed()))
+
+	queryCount := float64(resp.GetResolutionMetadata().DatastoreQueryCount)
+	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, queryCount)
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, queryCount))
+	datastoreQueryCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(queryCount)
+
+	rawDispatchCount := checkRequestMetadata.DispatchCounter.Load()
+	dispatchCount := float64(rawDispatchCount)
+	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+	dispatchCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(dispatchCount)
+
+	requestDurationHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+		utils.Bucketize(uint(queryCount), s.requestDurationByQueryHistogramBuckets),
+		utils.Bucketize(uint(rawDispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
+		checkReq.GetConsistency().String(),
+	).Observe(float64(time.Since(start).Milliseconds()))
+
+	return &ExplainCheckResponse{
+		Allowed:     resp.GetAllowed(),
+		Explanation: explainer.Explanation(),
+	}, nil
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/internal/graph"
+)
+
+const (
+	explainCheckServiceName = "openfga.v1.ExplainCheckService"
+	explainCheckFullMethod  = "/" + explainCheckServiceName + "/ExplainCheck"
+	explainCheckHTTPPath    = "/stores/{store_id}/explain-check"
+)
+
+// CheckExplanation is a node of the resolution tree returned by ExplainCheck.
+type CheckExplanation = graph.CheckExplanation
+
+// ExplainCheckRequest is the request of the ExplainCheck RPC, served by a JSON encoded gRPC service
+// (see jsoncodec). Its JSON representation is that of the Check request, plus the optional size limits
+// of the explanation.
+type ExplainCheckRequest struct {
+	CheckRequest *openfgav1.CheckRequest
+	// MaxNodes is the maximum number of nodes of the explanation. Zero means the default.
+	MaxNodes uint32
+	// MaxTuplesPerNode is the maximum number of tuples, and of conditions, recorded per node. Zero means the default.
+	MaxTuplesPerNode uint32
+}
+
+type ExplainCheckResponse struct {
+	Allowed     bool              `json:"allowed"`
+	Explanation *CheckExplanation `json:"explanation,omitempty"`
+}
+
+func (r *ExplainCheckRequest) GetCheckRequest() *openfgav1.CheckRequest {
+	if r == nil {
+		return nil
+	}
+	return r.CheckRequest
+}
+
+func (r *ExplainCheckRequest) GetStoreId() string { //nolint:revive,stylecheck
+	return r.GetCheckRequest().GetStoreId()
+}
+
+func (r *ExplainCheckRequest) GetAuthorizationModelId() string { //nolint:revive,stylecheck
+	return r.GetCheckRequest().GetAuthorizationModelId()
+}
+
+// Validate applies the same rules as the Check request.
+func (r *ExplainCheckRequest) Validate() error {
+	if r.GetCheckRequest() == nil {
+		return errors.New("invalid ExplainCheckRequest: missing check request")
+	}
+	return r.GetCheckRequest().Validate()
+}
+
+type explainCheckLimitsJSON struct {
+	MaxNodes         uint32 `json:"max_nodes,omitempty"`
+	MaxTuplesPerNode uint32 `json:"max_tuples_per_node,omitempty"`
+}
+
+func (r *ExplainCheckRequest) MarshalJSON() ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+
+	checkRequest, err := marshalProtoField(r.GetCheckRequest())
+	if err != nil {
+		return nil, err
+	}
+	if checkRequest != nil {
+		if err := json.Unmarshal(checkRequest, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.MaxNodes > 0 {
+		fields["max_nodes"], _ = json.Marshal(r.MaxNodes)
+	}
+	if r.MaxTuplesPerNode > 0 {
+		fields["max_tuples_per_node"], _ = json.Marshal(r.MaxTuplesPerNode)
+	}
+
+	return json.Marshal(fields)
+}
+
+func (r *ExplainCheckRequest) UnmarshalJSON(data []byte) error {
+	var limits explainCheckLimitsJSON
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return err
+	}
+
+	checkRequest := &openfgav1.CheckRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, checkRequest); err != nil {
+		return err
+	}
+
+	*r = ExplainCheckRequest{
+		CheckRequest:     checkRequest,
+		MaxNodes:         limits.MaxNodes,
+		MaxTuplesPerNode: limits.MaxTuplesPerNode,
+	}
+	return nil
+}
+
+// ExplainCheckServiceServer is the server API for the ExplainCheck service.
+type ExplainCheckServiceServer interface {
+	ExplainCheck(context.Context, *ExplainCheckRequest) (*ExplainCheckResponse, error)
+}
+
+var _ ExplainCheckServiceServer = (*Server)(nil)
+
+// ExplainCheckServiceDesc is the grpc.ServiceDesc for the ExplainCheck service.
+var ExplainCheckServiceDesc = grpc.ServiceDesc{
+	ServiceName: explainCheckServiceName,
+	HandlerType: (*ExplainCheckServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExplainCheck",
+			Handler:    explainCheckHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterExplainCheckServiceServer registers the ExplainCheck service in the given gRPC server. It must be
+// registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterExplainCheckServiceServer(s grpc.ServiceRegistrar, srv ExplainCheckServiceServer) {
+	s.RegisterService(&ExplainCheckServiceDesc, srv)
+}
+
+func explainCheckHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplainCheckServiceServer).ExplainCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: explainCheckFullMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplainCheckServiceServer).ExplainCheck(ctx, req.(*ExplainCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExplainCheckServiceClient is the client API for the ExplainCheck service.
+type ExplainCheckServiceClient interface {
+	ExplainCheck(ctx context.Context, in *ExplainCheckRequest, opts ...grpc.CallOption) (*ExplainCheckResponse, error)
+}
+
+type explainCheckServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExplainCheckServiceClient(cc grpc.ClientConnInterface) ExplainCheckServiceClient {
+	return &explainCheckServiceClient{cc: cc}
+}
+
+func (c *explainCheckServiceClient) ExplainCheck(ctx context.Context, in *ExplainCheckRequest, opts ...grpc.CallOption) (*ExplainCheckResponse, error) {
+	out := new(ExplainCheckResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, explainCheckFullMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterExplainCheckServiceHandler registers the HTTP route of ExplainCheck (POST /stores/{store_id}/explain-check)
+// in the gateway mux.
+func RegisterExplainCheckServiceHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := NewExplainCheckServiceClient(conn)
+
+	return handleJSONPath(mux, explainCheckHTTPPath, explainCheckFullMethod,
+		func(req *ExplainCheckRequest, storeID string) {
+			if req.CheckRequest == nil {
+				req.CheckRequest = &openfgav1.CheckRequest{}
+			}
+			req.CheckRequest.StoreId = storeID
+		},
+		client.ExplainCheck,
+	)
+}
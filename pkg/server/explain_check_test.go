@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestExplainCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		// explanations must not be affected by the check cache
+		WithCheckQueryCacheEnabled(true),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "explain-check"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type doc
+				relations
+					define editor: [user]
+					define viewer: [user] or editor`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeModelResp.GetAuthorizationModelId()
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("doc:1", "editor", "user:anne")},
+		},
+	})
+	require.NoError(t, err)
+
+	request := &ExplainCheckRequest{
+		CheckRequest: &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:anne"),
+		},
+	}
+
+	requireExplanation := func(t *testing.T, resp *ExplainCheckResponse) {
+		require.True(t, resp.Allowed)
+		require.NotNil(t, resp.Explanation)
+		require.Equal(t, graph.ExplanationKindCheck, resp.Explanation.Kind)
+		require.Equal(t, "doc:1#viewer@user:anne", resp.Explanation.TupleKey)
+		require.Equal(t, graph.ExplanationOutcomeAllowed, resp.Explanation.Outcome)
+		require.Len(t, resp.Explanation.Children, 1)
+		require.Equal(t, graph.ExplanationKindUnion, resp.Explanation.Children[0].Kind)
+	}
+
+	t.Run("explains_check", func(t *testing.T) {
+		// populate the check cache, which ExplainCheck must bypass
+		_, err := s.Check(ctx, request.GetCheckRequest())
+		require.NoError(t, err)
+
+		resp, err := s.ExplainCheck(ctx, request)
+		require.NoError(t, err)
+		requireExplanation(t, resp)
+	})
+
+	t.Run("validates_request", func(t *testing.T) {
+		_, err := s.ExplainCheck(ctx, &ExplainCheckRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = s.ExplainCheck(ctx, &ExplainCheckRequest{
+			CheckRequest: &openfgav1.CheckRequest{
+				StoreId:  storeID,
+				TupleKey: tuple.NewCheckRequestTupleKey("doc:1", "invalid", "user:anne"),
+			},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	RegisterExplainCheckServiceServer(grpcServer, s)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		resp, err := NewExplainCheckServiceClient(conn).ExplainCheck(ctx, request)
+		require.NoError(t, err)
+		requireExplanation(t, resp)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		mux := runtime.NewServeMux()
+		require.NoError(t, RegisterExplainCheckServiceHandler(mux, conn))
+
+		httpServer := httptest.NewServer(mux)
+		t.Cleanup(httpServer.Close)
+
+		body, err := json.Marshal(&ExplainCheckRequest{
+			CheckRequest: &openfgav1.CheckRequest{
+				AuthorizationModelId: modelID,
+				TupleKey:             tuple.NewCheckRequestTupleKey("doc:1", "viewer", "user:anne"),
+			},
+			MaxNodes: 1,
+		})
+		require.NoError(t, err)
+
+		httpResp, err := http.Post(httpServer.URL+"/stores/"+storeID+"/explain-check", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer httpResp.Body.Close()
+		require.Equal(t, http.StatusOK, httpResp.StatusCode)
+
+		var resp ExplainCheckResponse
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&resp))
+		require.True(t, resp.Allowed)
+		require.Empty(t, resp.Explanation.Children)
+		require.True(t, resp.Explanation.Truncated)
+	})
+}
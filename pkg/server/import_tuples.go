@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// ImportTuples writes the tuples streamed by the client in large batches, using the bulk insert of the datastore
+// if it has one (see storage.TupleImporter). The tuples are validated like those of a Write, and the ones that
+// are invalid or already exist are reported in the response, with their position in the stream, instead of
+// failing the import. The tuples of a failed import that were already written are not rolled back.
+//
+// Calling it requires the permission to call Write on the whole store, since the tuples are not known upfront.
+func (s *Server) ImportTuples(srv ImportTuplesServerStream) error {
+	ctx := srv.Context()
+
+	req, err := srv.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "the stream must start with the store to import tuples into")
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, span := tracer.Start(ctx, authz.ImportTuples, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if req.GetStoreId() == "" {
+		return status.Error(codes.InvalidArgument, "the stream must start with the store to import tuples into")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.ImportTuples,
+	})
+
+	storeID := req.GetStoreId()
+
+	err = s.checkAuthz(ctx, storeID, authz.ImportTuples)
+	if err != nil {
+		return err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.AuthorizationModelID)
+	if err != nil {
+		return err
+	}
+
+	pending := req.TupleKeys
+	next := func() (*openfgav1.TupleKey, error) {
+		for len(pending) == 0 {
+			req, err := srv.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if !validator.RequestIsValidatedFromContext(ctx) {
+				if err := req.Validate(); err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+			}
+			if (req.StoreID != "" && req.StoreID != storeID) || (req.AuthorizationModelID != "" && req.AuthorizationModelID != typesys.GetAuthorizationModelID()) {
+				return nil, status.Error(codes.InvalidArgument, "the store and model cannot change during the import")
+			}
+			pending = req.TupleKeys
+		}
+
+		tk := pending[0]
+		pending = pending[1:]
+		return tk, nil
+	}
+
+	cmd := commands.NewImportTuplesCommand(s.datastore,
+		commands.WithImportTuplesCmdLogger(s.logger),
+		commands.WithImportTuplesCmdImporter(s.tupleImporter),
+		commands.WithImportTuplesCmdStoreQuotas(s.storeQuotas),
+	)
+	result, err := cmd.Execute(ctx, storeID, typesys, next)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return serverErrors.HandleError("", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int64("imported_count", result.ImportedCount),
+		attribute.Int("error_count", len(result.Errors)),
+	)
+
+	resp := &ImportTuplesResponse{
+		ImportedCount: result.ImportedCount,
+		Errors:        make([]*ImportTuplesError, 0, len(result.Errors)),
+	}
+	for _, rowErr := range result.Errors {
+		resp.Errors = append(resp.Errors, &ImportTuplesError{
+			Index:    rowErr.Index,
+			TupleKey: rowErr.TupleKey,
+			Error:    status.Convert(serverErrors.HandleTupleValidateError(rowErr.Err)).Message(),
+		})
+	}
+
+	return srv.SendAndClose(resp)
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	importTuplesServiceName = "openfga.v1.ImportTuplesService"
+	// ImportTuplesFullMethod is the full gRPC method name of ImportTuples. Its streams last as long as the client
+	// sends tuples, so they must not be subject to the request timeout.
+	ImportTuplesFullMethod = "/" + importTuplesServiceName + "/ImportTuples"
+)
+
+// ImportTuplesRequest is a message of the client stream of the ImportTuples RPC, served by a JSON encoded gRPC
+// service (see jsoncodec). The first message of the stream sets the store and model, which the following
+// messages may leave empty.
+type ImportTuplesRequest struct {
+	StoreID              string `json:"store_id,omitempty"`
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	TupleKeys            []*openfgav1.TupleKey
+}
+
+// ImportTuplesError is the reason why a tuple of the stream was not imported.
+type ImportTuplesError struct {
+	// Index is the position of the tuple in the stream, across all the messages, starting from 0.
+	Index    int64
+	TupleKey *openfgav1.TupleKey
+	Error    string
+}
+
+// ImportTuplesResponse is the outcome of the import, sent once the client closes the stream.
+type ImportTuplesResponse struct {
+	ImportedCount int64                `json:"imported_count"`
+	Errors        []*ImportTuplesError `json:"errors"`
+}
+
+func (r *ImportTuplesRequest) GetStoreId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.StoreID
+}
+
+// Validate applies the rules of the Write request to the store and model IDs, unless both are empty.
+func (r *ImportTuplesRequest) Validate() error {
+	if r.StoreID == "" && r.AuthorizationModelID == "" {
+		return nil
+	}
+	return (&openfgav1.WriteRequest{
+		StoreId:              r.StoreID,
+		AuthorizationModelId: r.AuthorizationModelID,
+	}).Validate()
+}
+
+type importTuplesRequestJSON struct {
+	StoreID              string            `json:"store_id,omitempty"`
+	AuthorizationModelID string            `json:"authorization_model_id,omitempty"`
+	TupleKeys            []json.RawMessage `json:"tuple_keys"`
+}
+
+func (r *ImportTuplesRequest) MarshalJSON() ([]byte, error) {
+	tupleKeys := make([]json.RawMessage, 0, len(r.TupleKeys))
+	for _, tk := range r.TupleKeys {
+		data, err := marshalProtoField(tk)
+		if err != nil {
+			return nil, err
+		}
+		tupleKeys = append(tupleKeys, data)
+	}
+
+	return json.Marshal(&importTuplesRequestJSON{
+		StoreID:              r.StoreID,
+		AuthorizationModelID: r.AuthorizationModelID,
+		TupleKeys:            tupleKeys,
+	})
+}
+
+func (r *ImportTuplesRequest) UnmarshalJSON(data []byte) error {
+	var in importTuplesRequestJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	tupleKeys := make([]*openfgav1.TupleKey, 0, len(in.TupleKeys))
+	for _, raw := range in.TupleKeys {
+		tk := &openfgav1.TupleKey{}
+		if err := protojson.Unmarshal(raw, tk); err != nil {
+			return err
+		}
+		tupleKeys = append(tupleKeys, tk)
+	}
+
+	*r = ImportTuplesRequest{
+		StoreID:              in.StoreID,
+		AuthorizationModelID: in.AuthorizationModelID,
+		TupleKeys:            tupleKeys,
+	}
+	return nil
+}
+
+type importTuplesErrorJSON struct {
+	Index    int64           `json:"index"`
+	TupleKey json.RawMessage `json:"tuple_key"`
+	Error    string          `json:"error"`
+}
+
+func (e *ImportTuplesError) MarshalJSON() ([]byte, error) {
+	tupleKey, err := marshalProtoField(e.TupleKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&importTuplesErrorJSON{
+		Index:    e.Index,
+		TupleKey: tupleKey,
+		Error:    e.Error,
+	})
+}
+
+func (e *ImportTuplesError) UnmarshalJSON(data []byte) error {
+	var in importTuplesErrorJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	tupleKey := &openfgav1.TupleKey{}
+	if len(in.TupleKey) > 0 {
+		if err := protojson.Unmarshal(in.TupleKey, tupleKey); err != nil {
+			return err
+		}
+	}
+
+	*e = ImportTuplesError{
+		Index:    in.Index,
+		TupleKey: tupleKey,
+		Error:    in.Error,
+	}
+	return nil
+}
+
+// ImportTuplesServiceServer is the server API for the ImportTuples service.
+type ImportTuplesServiceServer interface {
+	ImportTuples(ImportTuplesServerStream) error
+}
+
+// ImportTuplesServerStream is the server side of an ImportTuples stream.
+type ImportTuplesServerStream interface {
+	SendAndClose(*ImportTuplesResponse) error
+	Recv() (*ImportTuplesRequest, error)
+	grpc.ServerStream
+}
+
+var _ ImportTuplesServiceServer = (*Server)(nil)
+
+// ImportTuplesServiceDesc is the grpc.ServiceDesc for the ImportTuples service.
+var ImportTuplesServiceDesc = grpc.ServiceDesc{
+	ServiceName: importTuplesServiceName,
+	HandlerType: (*ImportTuplesServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportTuples",
+			Handler:       importTuplesHandler,
+			ClientStreams: true,
+		},
+	},
+}
+
+// RegisterImportTuplesServiceServer registers the ImportTuples service in the given gRPC server. It must be
+// registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterImportTuplesServiceServer(s grpc.ServiceRegistrar, srv ImportTuplesServiceServer) {
+	s.RegisterService(&ImportTuplesServiceDesc, srv)
+}
+
+func importTuplesHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ImportTuplesServiceServer).ImportTuples(&importTuplesServerStream{stream})
+}
+
+type importTuplesServerStream struct {
+	grpc.ServerStream
+}
+
+func (x *importTuplesServerStream) SendAndClose(m *ImportTuplesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *importTuplesServerStream) Recv() (*ImportTuplesRequest, error) {
+	m := new(ImportTuplesRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ImportTuplesServiceClient is the client API for the ImportTuples service.
+type ImportTuplesServiceClient interface {
+	ImportTuples(ctx context.Context, opts ...grpc.CallOption) (ImportTuplesClientStream, error)
+}
+
+// ImportTuplesClientStream is the client side of an ImportTuples stream.
+type ImportTuplesClientStream interface {
+	Send(*ImportTuplesRequest) error
+	CloseAndRecv() (*ImportTuplesResponse, error)
+	grpc.ClientStream
+}
+
+type importTuplesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImportTuplesServiceClient(cc grpc.ClientConnInterface) ImportTuplesServiceClient {
+	return &importTuplesServiceClient{cc: cc}
+}
+
+func (c *importTuplesServiceClient) ImportTuples(ctx context.Context, opts ...grpc.CallOption) (ImportTuplesClientStream, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ImportTuplesServiceDesc.Streams[0], ImportTuplesFullMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &importTuplesClientStream{stream}, nil
+}
+
+type importTuplesClientStream struct {
+	grpc.ClientStream
+}
+
+func (x *importTuplesClientStream) Send(m *ImportTuplesRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *importTuplesClientStream) CloseAndRecv() (*ImportTuplesResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportTuplesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestImportTuples(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "import-tuples"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId: storeID,
+		TypeDefinitions: testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+		SchemaVersion: "1.1",
+	})
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	RegisterImportTuplesServiceServer(grpcServer, s)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	client := NewImportTuplesServiceClient(conn)
+
+	t.Run("imports_the_tuples_of_all_the_messages", func(t *testing.T) {
+		stream, err := client.ImportTuples(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&ImportTuplesRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: writeModelResp.GetAuthorizationModelId(),
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:1", "editor", "user:anne"),
+			},
+		}))
+		for i := 2; i < 5; i++ {
+			require.NoError(t, stream.Send(&ImportTuplesRequest{
+				TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne")},
+			}))
+		}
+		// already written by the first message
+		require.NoError(t, stream.Send(&ImportTuplesRequest{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+		}))
+
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Equal(t, int64(4), resp.ImportedCount)
+		require.Len(t, resp.Errors, 2)
+		require.Equal(t, int64(1), resp.Errors[0].Index)
+		require.Equal(t, "editor", resp.Errors[0].TupleKey.GetRelation())
+		require.Contains(t, resp.Errors[0].Error, "relation 'document#editor' not found")
+		require.Equal(t, int64(5), resp.Errors[1].Index)
+		require.NotEmpty(t, resp.Errors[1].Error)
+
+		tuples, _, err := ds.ReadPage(ctx, storeID, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(10, ""),
+		})
+		require.NoError(t, err)
+		require.Len(t, tuples, 4)
+	})
+
+	t.Run("requires_the_store_in_the_first_message", func(t *testing.T) {
+		stream, err := client.ImportTuples(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&ImportTuplesRequest{
+			TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+		}))
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("rejects_a_change_of_store", func(t *testing.T) {
+		stream, err := client.ImportTuples(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&ImportTuplesRequest{StoreID: storeID}))
+		require.NoError(t, stream.Send(&ImportTuplesRequest{StoreID: "01JA6WMC6ZPRWQVEH3DVGWF6QS"}))
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/internal/jsoncodec"
+)
+
+// Some RPCs (e.g. BatchCheck) have messages that are not part of the openfga/api protobuf definitions. They
+// are served by their own gRPC services whose messages are encoded as JSON (see jsoncodec). Protobuf fields
+// nested in the messages keep their protojson representation, so the payloads look the same as those of
+// the rest of the HTTP API.
+
+const jsonCodecName = jsoncodec.Name
+
+func marshalProtoField[T proto.Message](m T) (json.RawMessage, error) {
+	if !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+}
+
+// handleJSONPath registers a POST route in the gateway mux that decodes the body as Req, sets the store ID
+// from the path and forwards the request to the gRPC server with invoke. Going through the gRPC server means
+// the request gets the same authentication, validation and logging as every other call of the HTTP API.
+func handleJSONPath[Req any, Resp any](
+	mux *runtime.ServeMux,
+	pattern string,
+	fullMethod string,
+	setStoreID func(req *Req, storeID string),
+	invoke func(ctx context.Context, req *Req, opts ...grpc.CallOption) (*Resp, error),
+) error {
+	return mux.HandlePath(http.MethodPost, pattern, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, fullMethod, runtime.WithHTTPPathPattern(pattern))
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		req := new(Req)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		setStoreID(req, pathParams["store_id"])
+
+		var md runtime.ServerMetadata
+		resp, err := invoke(ctx, req, grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+		ctx = runtime.NewServerMetadataContext(ctx, md)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+		}
+	})
+}
+
+// jsonClientStream is the client side of a server streaming RPC of a JSON encoded gRPC service.
+type jsonClientStream[Resp any] interface {
+	Header() (metadata.MD, error)
+	Recv() (*Resp, error)
+}
+
+// handleJSONStreamPath is the equivalent of handleJSONPath for server streaming RPCs. The responses are written
+// as newline delimited JSON, each wrapped in a `result` object, and an error ending the stream is written as
+// an `error` object, like the streaming routes of the gateway.
+//
+// Errors before the server sends the header of the stream (e.g. authentication or validation failures) are
+// returned with their HTTP status instead.
+func handleJSONStreamPath[Req any, Resp any](
+	mux *runtime.ServeMux,
+	pattern string,
+	fullMethod string,
+	setStoreID func(req *Req, storeID string),
+	invoke func(ctx context.Context, req *Req, opts ...grpc.CallOption) (jsonClientStream[Resp], error),
+) error {
+	return mux.HandlePath(http.MethodPost, pattern, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, fullMethod, runtime.WithHTTPPathPattern(pattern))
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		req := new(Req)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		setStoreID(req, pathParams["store_id"])
+
+		stream, err := invoke(ctx, req)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		header, err := stream.Header()
+		if err == nil && header == nil {
+			// the stream ended without a header, and its status is returned by Recv
+			if _, err = stream.Recv(); errors.Is(err, io.EOF) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				return
+			}
+		}
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+
+		encoder := json.NewEncoder(w)
+		for {
+			if flusher != nil {
+				flusher.Flush()
+			}
+
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				streamErr, marshalErr := marshalProtoField(status.Convert(err).Proto())
+				if marshalErr == nil {
+					_ = encoder.Encode(map[string]json.RawMessage{"error": streamErr})
+				}
+				return
+			}
+
+			if err := encoder.Encode(map[string]*Resp{"result": resp}); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -62,6 +62,11 @@ func (s *Server) ListUsers(
 		return nil, err
 	}
 
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
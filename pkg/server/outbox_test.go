@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/outbox"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	config := outbox.Config{PollInterval: 10 * time.Millisecond, BatchSize: 10}
+
+	t.Run("delivers_the_events_to_the_sinks", func(t *testing.T) {
+		ds := memory.New(memory.WithOutbox())
+		t.Cleanup(ds.Close)
+
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		s := MustNewServerWithOpts(WithDatastore(ds), WithOutbox(config, outbox.NewFileSink(path)))
+		t.Cleanup(s.Close)
+
+		createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "outbox"})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			events, err := os.ReadFile(path)
+			return err == nil && strings.Contains(string(events), createStoreResp.GetId())
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("requires_a_datastore_with_an_outbox", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		_, err := NewServerWithOpts(WithDatastore(struct{ storage.OpenFGADatastore }{ds}), WithOutbox(config, outbox.NewStdoutSink()))
+		require.ErrorContains(t, err, "requires a datastore which implements an outbox")
+	})
+}
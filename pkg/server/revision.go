@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// RevisionHeader is the response header holding the revision token of the store once a Write is committed.
+	RevisionHeader = "Openfga-Revision"
+
+	// AtLeastAsFreshHeader is the request header holding a revision token that the reads of a Check, BatchCheck,
+	// ExplainCheck, ListObjects, ListUsers or Read must be at least as fresh as.
+	AtLeastAsFreshHeader = "Openfga-At-Least-As-Fresh"
+
+	// AtRevisionHeader is the request header holding a revision token that the reads of a Check, BatchCheck,
+	// ExplainCheck, ListObjects, ListUsers or Read must be exactly at.
+	AtRevisionHeader = "Openfga-At-Revision"
+)
+
+// IsRevisionHeader reports whether the HTTP header is one of the request headers holding a revision token, which
+// are forwarded to the gRPC server.
+func IsRevisionHeader(header string) bool {
+	return strings.EqualFold(header, AtLeastAsFreshHeader) || strings.EqualFold(header, AtRevisionHeader)
+}
+
+// newRevisionToken returns the opaque token of a revision of the store.
+func newRevisionToken(storeID string, revision ulid.ULID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(storeID + ":" + revision.String()))
+}
+
+// latestRevision returns the revision of the last change of the store, or the zero ULID if it has no change.
+func (s *Server) latestRevision(ctx context.Context, storeID string) (ulid.ULID, error) {
+	_, token, err := s.datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+		SortDesc:   true,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return ulid.ULID{}, nil
+	}
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	revision, _, err := s.tokenSerializer.Deserialize(string(token))
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+	return ulid.Parse(revision)
+}
+
+// parseRevisionToken returns the ULID of the revision token, which must be one of the store.
+func parseRevisionToken(storeID, token string) (ulid.ULID, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ulid.ULID{}, fmt.Errorf("invalid revision token")
+	}
+
+	tokenStoreID, revision, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return ulid.ULID{}, fmt.Errorf("invalid revision token")
+	}
+	if tokenStoreID != storeID {
+		return ulid.ULID{}, fmt.Errorf("the revision token is not one of the store '%s'", storeID)
+	}
+
+	id, err := ulid.ParseStrict(revision)
+	if err != nil {
+		return ulid.ULID{}, fmt.Errorf("invalid revision token")
+	}
+	return id, nil
+}
+
+// contextWithRequestedRevision binds the reads of the request to the revision of its headers, if any.
+func contextWithRequestedRevision(ctx context.Context, storeID string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	atLeastAsFresh := md.Get(AtLeastAsFreshHeader)
+	atRevision := md.Get(AtRevisionHeader)
+
+	var (
+		token string
+		mode  storage.RevisionMode
+	)
+	switch {
+	case len(atLeastAsFresh) > 0 && len(atRevision) > 0:
+		return nil, status.Errorf(codes.InvalidArgument, "only one of the '%s' and '%s' headers can be set", AtLeastAsFreshHeader, AtRevisionHeader)
+	case len(atLeastAsFresh) > 0:
+		token, mode = atLeastAsFresh[0], storage.RevisionAtLeastAsFresh
+	case len(atRevision) > 0:
+		token, mode = atRevision[0], storage.RevisionExact
+	default:
+		return ctx, nil
+	}
+
+	revision, err := parseRevisionToken(storeID, token)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return storage.ContextWithRevision(ctx, storage.Revision{ULID: revision, Mode: mode}), nil
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// headersTransport records the headers set by the server.
+type headersTransport struct {
+	mu      sync.Mutex
+	headers map[string]string
+}
+
+func (h *headersTransport) SetHeader(_ context.Context, key, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.headers[key] = value
+}
+
+func (h *headersTransport) get(key string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.headers[key]
+}
+
+func TestRevisionTokens(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	transport := &headersTransport{headers: make(map[string]string)}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "revisions"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId: storeID,
+		TypeDefinitions: testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+		SchemaVersion: "1.1",
+	})
+	require.NoError(t, err)
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+	})
+	require.NoError(t, err)
+	writtenToken := transport.get(RevisionHeader)
+	require.NotEmpty(t, writtenToken)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk),
+		}},
+	})
+	require.NoError(t, err)
+	deletedToken := transport.get(RevisionHeader)
+	require.NotEqual(t, writtenToken, deletedToken)
+
+	withHeaders := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+
+	check := func(ctx context.Context) (*openfgav1.CheckResponse, error) {
+		return s.Check(ctx, &openfgav1.CheckRequest{StoreId: storeID, TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne")})
+	}
+
+	t.Run("check_at_revision", func(t *testing.T) {
+		resp, err := check(withHeaders(AtRevisionHeader, writtenToken))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		resp, err = check(withHeaders(AtRevisionHeader, deletedToken))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("batch_check_at_revision", func(t *testing.T) {
+		resp, err := s.BatchCheck(withHeaders(AtRevisionHeader, writtenToken), &BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*BatchCheckItem{
+				{TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"), CorrelationId: "anne"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Result["anne"].Allowed)
+	})
+
+	t.Run("explain_check_at_revision", func(t *testing.T) {
+		resp, err := s.ExplainCheck(withHeaders(AtRevisionHeader, writtenToken), &ExplainCheckRequest{
+			CheckRequest: &openfgav1.CheckRequest{StoreId: storeID, TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne")},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Allowed)
+	})
+
+	t.Run("write_without_changes", func(t *testing.T) {
+		// the store is still at the revision of its last change
+		_, err := s.Write(withHeaders(WriteOnMissingHeader, "ignore"), &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk),
+			}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, deletedToken, transport.get(RevisionHeader))
+	})
+
+	t.Run("check_at_least_as_fresh", func(t *testing.T) {
+		resp, err := check(withHeaders(AtLeastAsFreshHeader, writtenToken))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("read_at_revision", func(t *testing.T) {
+		resp, err := s.Read(withHeaders(AtRevisionHeader, writtenToken), &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Len(t, resp.GetTuples(), 1)
+
+		resp, err = s.Read(ctx, &openfgav1.ReadRequest{StoreId: storeID})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetTuples())
+	})
+
+	t.Run("list_objects_at_revision", func(t *testing.T) {
+		resp, err := s.ListObjects(withHeaders(AtRevisionHeader, writtenToken), &openfgav1.ListObjectsRequest{
+			StoreId:  storeID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, resp.GetObjects())
+	})
+
+	t.Run("invalid_tokens", func(t *testing.T) {
+		for name, ctx := range map[string]context.Context{
+			"malformed":    withHeaders(AtRevisionHeader, "not-a-token"),
+			"other_store":  withHeaders(AtRevisionHeader, newRevisionToken("01JA6WMC6ZPRWQVEH3DVGWF6QS", ulid.Make())),
+			"both_headers": withHeaders(AtRevisionHeader, writtenToken, AtLeastAsFreshHeader, writtenToken),
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := check(ctx)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			})
+		}
+	})
+}
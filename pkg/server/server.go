@@ -21,16 +21,22 @@ import (
 
 	"github.com/openfga/openfga/internal/throttler/threshold"
 
+	"github.com/openfga/openfga/internal/changelogpruner"
+	"github.com/openfga/openfga/internal/storepurger"
+	"github.com/openfga/openfga/internal/storequota"
 	"github.com/openfga/openfga/internal/throttler"
+	"github.com/openfga/openfga/internal/tuplereaper"
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -45,9 +51,11 @@ import (
 	"github.com/openfga/openfga/pkg/logger"
 	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
 	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/outbox"
 	"github.com/openfga/openfga/pkg/server/commands"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sharedcache"
 	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
@@ -138,6 +146,14 @@ type Server struct {
 	maxConcurrentReadsForListObjects uint32
 	maxConcurrentReadsForCheck       uint32
 	maxConcurrentReadsForListUsers   uint32
+	checkDatastoreBatchingEnabled    bool
+	checkDatastoreBatchingWindow     time.Duration
+	checkDatastoreBatchingMaxSize    int
+	datastoreMetricsEnabled          bool
+	datastoreMetricsEngine           string
+	datastoreMetricsStoreLabelLimit  int
+	maxChecksPerBatchCheck           uint32
+	maxConcurrentChecksPerBatchCheck uint32
 	maxAuthorizationModelCacheSize   int
 	maxAuthorizationModelSizeInBytes int
 	experimentals                    []ExperimentalFeatureFlag
@@ -149,8 +165,9 @@ type Server struct {
 	typesystemResolver     typesystem.TypesystemResolverFunc
 	typesystemResolverStop func()
 
-	cacheLimit uint32
-	cache      storage.InMemoryCache[any]
+	cacheLimit  uint32
+	sharedCache serverconfig.SharedCacheConfig
+	cache       storage.InMemoryCache[any]
 
 	cacheControllerEnabled bool
 	cacheControllerTTL     time.Duration
@@ -164,6 +181,9 @@ type Server struct {
 
 	checkResolver       graph.CheckResolver
 	checkResolverCloser func()
+	// explainCheckResolver resolves ExplainCheck requests. It has no cache nor throttling, so that
+	// every subproblem is resolved, and recorded, locally.
+	explainCheckResolver graph.CheckResolver
 
 	requestDurationByQueryHistogramBuckets         []uint
 	requestDurationByDispatchCountHistogramBuckets []uint
@@ -173,6 +193,53 @@ type Server struct {
 	checkDispatchThrottlingDefaultThreshold uint32
 	checkDispatchThrottlingMaxThreshold     uint32
 
+	remoteCheckDispatchEnabled              bool
+	remoteCheckDispatchSelfAddress          string
+	remoteCheckDispatchPeers                graph.PeerDiscoverer
+	remoteCheckDispatchPeerRefreshInterval  time.Duration
+	remoteCheckDispatchPeerUnhealthyBackoff time.Duration
+	remoteCheckDispatchDialOptions          []grpc.DialOption
+
+	// changelogWatcher notifies the changes of the changelog to WatchChanges, if the datastore supports it
+	changelogWatcher              storage.ChangelogWatcher
+	watchChangesHeartbeatInterval time.Duration
+	watchChangesMinPollInterval   time.Duration
+	watchChangesMaxPollInterval   time.Duration
+
+	// tupleImporter writes the batches of ImportTuples, if the datastore supports it
+	tupleImporter storage.TupleImporter
+
+	tupleExpirationReaperInterval  time.Duration
+	tupleExpirationReaperBatchSize int
+	// tupleExpirationReaper deletes the expired tuples in the background, if enabled and the datastore supports it
+	tupleExpirationReaper *tuplereaper.Reaper
+
+	changelogRetention changelogpruner.Config
+	// changelogPruner prunes the changelogs in the background, if enabled and the datastore supports it
+	changelogPruner *changelogpruner.Pruner
+
+	// storeLabeler writes and reads the labels of the stores, if the datastore supports it
+	storeLabeler storage.StoreLabeler
+
+	// storeUndeleter restores the deleted stores in UndeleteStore, if the datastore supports it
+	storeUndeleter      storage.StoreUndeleter
+	storePurgeRetention time.Duration
+	storePurgeInterval  time.Duration
+	storePurgeBatchSize int
+	// storePurger purges the deleted stores in the background, if enabled and the datastore supports it
+	storePurger *storepurger.Purger
+
+	storeQuota storequota.Config
+	// storeQuotas enforces the quotas of the stores, if enabled
+	storeQuotas *storequota.Enforcer
+
+	outboxConfig outbox.Config
+	outboxSinks  []outbox.EventSink
+	// eventOutbox is the outbox of the datastore, if the outbox is enabled
+	eventOutbox storage.EventOutbox
+	// outboxDispatcher delivers the events of the outbox to the sinks in the background, if the outbox is enabled
+	outboxDispatcher *outbox.Dispatcher
+
 	listObjectsDispatchThrottlingEnabled      bool
 	listObjectsDispatchThrottlingFrequency    time.Duration
 	listObjectsDispatchDefaultThreshold       uint32
@@ -367,6 +434,21 @@ func WithMaxConcurrentReadsForListUsers(max uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithMaxChecksPerBatchCheck sets the maximum number of checks that can be sent in a single BatchCheck call.
+func WithMaxChecksPerBatchCheck(maxChecks uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.maxChecksPerBatchCheck = maxChecks
+	}
+}
+
+// WithMaxConcurrentChecksPerBatchCheck sets the maximum number of checks of a single BatchCheck call that
+// are resolved concurrently. Datastore reads of the whole batch are further bounded by WithMaxConcurrentReadsForCheck.
+func WithMaxConcurrentChecksPerBatchCheck(maxConcurrentChecks uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.maxConcurrentChecksPerBatchCheck = maxConcurrentChecks
+	}
+}
+
 func WithExperimentals(experimentals ...ExperimentalFeatureFlag) OpenFGAServiceV1Option {
 	return func(s *Server) {
 		s.experimentals = experimentals
@@ -401,6 +483,14 @@ func WithCacheLimit(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithSharedCache sets the cache shared with the other servers, which the check query and iterator caches use on
+// top of their local cache. The shared cache is disabled if the address of the config is empty.
+func WithSharedCache(config serverconfig.SharedCacheConfig) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.sharedCache = config
+	}
+}
+
 // WithCacheControllerEnabled enables cache invalidation of different cache entities.
 func WithCacheControllerEnabled(enabled bool) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -498,6 +588,139 @@ func WithDispatchThrottlingCheckResolverMaxThreshold(maxThreshold uint32) OpenFG
 	}
 }
 
+// WithRemoteCheckDispatchEnabled sets whether Check subproblems are spread across the nodes of the cluster
+// with consistent hashing. See graph.RemoteCheckResolver. The node must also serve the CheckDispatch service
+// (see graph.RegisterCheckDispatchServiceServer) at the address set by WithRemoteCheckDispatchSelfAddress.
+func WithRemoteCheckDispatchEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchEnabled = enabled
+	}
+}
+
+// WithRemoteCheckDispatchSelfAddress sets the address at which the other nodes reach the CheckDispatch
+// service of this node.
+func WithRemoteCheckDispatchSelfAddress(address string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchSelfAddress = address
+	}
+}
+
+// WithRemoteCheckDispatchPeers sets how the other nodes of the cluster are discovered.
+func WithRemoteCheckDispatchPeers(peers graph.PeerDiscoverer) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchPeers = peers
+	}
+}
+
+// WithRemoteCheckDispatchPeerRefreshInterval sets how often the other nodes of the cluster are discovered again.
+func WithRemoteCheckDispatchPeerRefreshInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchPeerRefreshInterval = interval
+	}
+}
+
+// WithRemoteCheckDispatchPeerUnhealthyBackoff sets for how long a node that could not be reached is not
+// dispatched to. Its subproblems are resolved locally in the meantime.
+func WithRemoteCheckDispatchPeerUnhealthyBackoff(backoff time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchPeerUnhealthyBackoff = backoff
+	}
+}
+
+// WithRemoteCheckDispatchDialOptions sets the options of the connections to the other nodes of the cluster.
+func WithRemoteCheckDispatchDialOptions(opts ...grpc.DialOption) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.remoteCheckDispatchDialOptions = opts
+	}
+}
+
+// WithWatchChangesHeartbeatInterval sets how long a WatchChanges stream may stay idle before a heartbeat is sent.
+func WithWatchChangesHeartbeatInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.watchChangesHeartbeatInterval = interval
+	}
+}
+
+// WithWatchChangesPollInterval sets the bounds of the interval between reads of the changelog by WatchChanges
+// when nothing changed. If the datastore notifies the changes of the changelog, it is only read every maxInterval
+// in between notifications.
+func WithWatchChangesPollInterval(minInterval, maxInterval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.watchChangesMinPollInterval = minInterval
+		s.watchChangesMaxPollInterval = maxInterval
+	}
+}
+
+// WithTupleExpirationReaper enables the background deletion of the expired tuples, every interval and batchSize
+// tuples at a time, if the datastore supports it. It is disabled by default, in which case the expired tuples are
+// only hidden from reads.
+func WithTupleExpirationReaper(interval time.Duration, batchSize int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.tupleExpirationReaperInterval = interval
+		s.tupleExpirationReaperBatchSize = batchSize
+	}
+}
+
+// WithChangelogRetention enables the background pruning of the changelogs, per the config, if the datastore supports
+// it. It is disabled by default, in which case the changelogs grow unbounded.
+func WithChangelogRetention(config changelogpruner.Config) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.changelogRetention = config
+	}
+}
+
+// WithStorePurge enables the background purge of the data of the stores deleted longer ago than retention, every
+// interval and batchSize rows at a time, if the datastore supports it. The deleted stores can be restored with
+// UndeleteStore until they are purged. It is disabled by default, in which case the deleted stores are kept forever.
+func WithStorePurge(retention, interval time.Duration, batchSize int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.storePurgeRetention = retention
+		s.storePurgeInterval = interval
+		s.storePurgeBatchSize = batchSize
+	}
+}
+
+// WithStoreQuotas enables the quotas of the stores, per the config. The tuple quotas require a datastore which
+// counts the tuples of the stores. They are disabled by default, in which case the stores are only bound by the
+// limits of each request.
+func WithStoreQuotas(config storequota.Config) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.storeQuota = config
+	}
+}
+
+// WithOutbox enables the delivery of the events of the outbox of the datastore to the sinks, per the config. The
+// datastore must implement storage.EventOutbox, and be configured to record its changes in its outbox. It is
+// disabled by default, or without sinks.
+func WithOutbox(config outbox.Config, sinks ...outbox.EventSink) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.outboxConfig = config
+		s.outboxSinks = sinks
+	}
+}
+
+// WithCheckDatastoreBatching enables the batching of the concurrent point lookups of the datastore made while
+// resolving a Check, which are collected for up to window, or until there are maxBatchSize of them, then read at once.
+// It is disabled by default.
+func WithCheckDatastoreBatching(enabled bool, window time.Duration, maxBatchSize int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkDatastoreBatchingEnabled = enabled
+		s.checkDatastoreBatchingWindow = window
+		s.checkDatastoreBatchingMaxSize = maxBatchSize
+	}
+}
+
+// WithDatastoreMetrics enables the export of the latency, errors, rows and iterator lifetime of every call to the
+// datastore, labeled by the engine and the method, and by store for up to storeLabelLimit stores, if positive (see
+// [storagewrappers.InstrumentedOpenFGADatastore]). It is disabled by default.
+func WithDatastoreMetrics(enabled bool, engine string, storeLabelLimit int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.datastoreMetricsEnabled = enabled
+		s.datastoreMetricsEngine = engine
+		s.datastoreMetricsStoreLabelLimit = storeLabelLimit
+	}
+}
+
 // MustNewServerWithOpts see NewServerWithOpts.
 func MustNewServerWithOpts(opts ...OpenFGAServiceV1Option) *Server {
 	s, err := NewServerWithOpts(opts...)
@@ -599,6 +822,7 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 	s := &Server{
 		logger:                           logger.NewNoopLogger(),
 		encoder:                          encoder.NewBase64Encoder(),
+		tokenSerializer:                  encoder.NewStringContinuationTokenSerializer(),
 		transport:                        gateway.NewNoopTransport(),
 		changelogHorizonOffset:           serverconfig.DefaultChangelogHorizonOffset,
 		resolveNodeLimit:                 serverconfig.DefaultResolveNodeLimit,
@@ -610,12 +834,18 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		maxConcurrentReadsForCheck:       serverconfig.DefaultMaxConcurrentReadsForCheck,
 		maxConcurrentReadsForListObjects: serverconfig.DefaultMaxConcurrentReadsForListObjects,
 		maxConcurrentReadsForListUsers:   serverconfig.DefaultMaxConcurrentReadsForListUsers,
+		maxChecksPerBatchCheck:           serverconfig.DefaultMaxChecksPerBatchCheck,
+		maxConcurrentChecksPerBatchCheck: serverconfig.DefaultMaxConcurrentChecksPerBatchCheck,
 		maxAuthorizationModelSizeInBytes: serverconfig.DefaultMaxAuthorizationModelSizeInBytes,
 		maxAuthorizationModelCacheSize:   serverconfig.DefaultMaxAuthorizationModelCacheSize,
 		experimentals:                    make([]ExperimentalFeatureFlag, 0, 10),
 		AccessControl:                    serverconfig.AccessControlConfig{Enabled: false, StoreID: "", ModelID: ""},
 
 		cacheLimit: serverconfig.DefaultCacheLimit,
+		sharedCache: serverconfig.SharedCacheConfig{
+			Timeout: serverconfig.DefaultSharedCacheTimeout,
+			Backoff: serverconfig.DefaultSharedCacheBackoff,
+		},
 
 		cacheController:        cachecontroller.NewNoopCacheController(),
 		cacheControllerEnabled: serverconfig.DefaultCacheControllerEnabled,
@@ -637,6 +867,13 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		checkDispatchThrottlingFrequency:        serverconfig.DefaultCheckDispatchThrottlingFrequency,
 		checkDispatchThrottlingDefaultThreshold: serverconfig.DefaultCheckDispatchThrottlingDefaultThreshold,
 
+		remoteCheckDispatchPeerRefreshInterval:  serverconfig.DefaultRemoteCheckDispatchPeerRefreshInterval,
+		remoteCheckDispatchPeerUnhealthyBackoff: serverconfig.DefaultRemoteCheckDispatchPeerUnhealthyBackoff,
+
+		watchChangesHeartbeatInterval: serverconfig.DefaultWatchChangesHeartbeatInterval,
+		watchChangesMinPollInterval:   serverconfig.DefaultWatchChangesMinPollInterval,
+		watchChangesMaxPollInterval:   serverconfig.DefaultWatchChangesMaxPollInterval,
+
 		listObjectsDispatchThrottlingEnabled:      serverconfig.DefaultListObjectsDispatchThrottlingEnabled,
 		listObjectsDispatchThrottlingFrequency:    serverconfig.DefaultListObjectsDispatchThrottlingFrequency,
 		listObjectsDispatchDefaultThreshold:       serverconfig.DefaultListObjectsDispatchThrottlingDefaultThreshold,
@@ -675,11 +912,56 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		return nil, fmt.Errorf("ListUsers default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListUsers")
 	}
 
+	if s.remoteCheckDispatchEnabled && s.remoteCheckDispatchSelfAddress == "" {
+		return nil, fmt.Errorf("remote check dispatch requires the address of this node")
+	}
+
+	if s.watchChangesHeartbeatInterval <= 0 || s.watchChangesMinPollInterval <= 0 || s.watchChangesMaxPollInterval < s.watchChangesMinPollInterval {
+		return nil, fmt.Errorf("WatchChanges heartbeat and poll intervals must be positive, with the min poll interval not greater than the max")
+	}
+
+	if s.tupleExpirationReaperInterval < 0 || (s.tupleExpirationReaperInterval > 0 && s.tupleExpirationReaperBatchSize <= 0) {
+		return nil, fmt.Errorf("tuple expiration reaper interval must not be negative, and its batch size must be positive")
+	}
+
+	if s.changelogRetention.IsEnabled() && (s.changelogRetention.Interval <= 0 || s.changelogRetention.BatchSize <= 0) {
+		return nil, fmt.Errorf("changelog retention prune interval and batch size must be positive")
+	}
+
+	if s.storePurgeRetention < 0 || (s.storePurgeRetention > 0 && (s.storePurgeInterval <= 0 || s.storePurgeBatchSize <= 0)) {
+		return nil, fmt.Errorf("store purge retention must not be negative, and its interval and batch size must be positive")
+	}
+
 	err := s.validateAccessControlEnabled()
 	if err != nil {
 		return nil, err
 	}
 
+	if s.datastoreMetricsEnabled {
+		// the calls are instrumented before being cached, so that only those reaching the datastore are recorded, and
+		// before the optional interfaces are looked up, so that their calls are recorded too
+		s.datastore = storagewrappers.NewInstrumentedOpenFGADatastore(s.datastore, s.datastoreMetricsEngine,
+			storagewrappers.WithStoreLabelLimit(s.datastoreMetricsStoreLabelLimit))
+	}
+
+	if s.storeQuota.IsEnabled() {
+		counter, _ := storage.As[storage.TupleCounter](s.datastore)
+		s.storeQuotas, err = storequota.New(s.storeQuota, counter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.outboxSinks) > 0 {
+		if s.outboxConfig.PollInterval <= 0 || s.outboxConfig.BatchSize <= 0 {
+			return nil, fmt.Errorf("outbox poll interval and batch size must be positive")
+		}
+		var ok bool
+		if s.eventOutbox, ok = storage.As[storage.EventOutbox](s.datastore); !ok {
+			return nil, fmt.Errorf("the outbox requires a datastore which implements an outbox")
+		}
+	}
+
 	// below this point, don't throw errors or we may leak resources in tests
 
 	checkDispatchThrottlingOptions := []graph.DispatchThrottlingCheckResolverOpt{}
@@ -695,12 +977,51 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		}
 	}
 
-	s.datastore = storagewrappers.NewCachedOpenFGADatastore(storagewrappers.NewContextWrapper(s.datastore), s.maxAuthorizationModelCacheSize)
+	s.changelogWatcher, _ = storage.As[storage.ChangelogWatcher](s.datastore)
+	s.tupleImporter, _ = storage.As[storage.TupleImporter](s.datastore)
+	if reaper, ok := storage.As[storage.TupleReaper](s.datastore); ok && s.tupleExpirationReaperInterval > 0 {
+		s.tupleExpirationReaper = tuplereaper.New(reaper, s.tupleExpirationReaperInterval, s.tupleExpirationReaperBatchSize, s.logger)
+	}
+	if pruned, ok := storage.As[changelogpruner.Datastore](s.datastore); ok && s.changelogRetention.IsEnabled() {
+		s.changelogPruner = changelogpruner.New(pruned, s.changelogRetention, s.logger)
+	}
+	s.storeLabeler, _ = storage.As[storage.StoreLabeler](s.datastore)
+	s.storeUndeleter, _ = storage.As[storage.StoreUndeleter](s.datastore)
+	if purger, ok := storage.As[storage.StorePurger](s.datastore); ok && s.storePurgeRetention > 0 {
+		s.storePurger = storepurger.New(purger, s.storePurgeRetention, s.storePurgeInterval, s.storePurgeBatchSize, s.logger)
+	}
+	if s.eventOutbox != nil {
+		s.outboxDispatcher = outbox.New(s.eventOutbox, s.outboxSinks, s.outboxConfig, s.logger)
+	}
+	s.datastore = storagewrappers.NewCachedOpenFGADatastore(
+		storagewrappers.NewSnapshotTupleReader(storagewrappers.NewContextWrapper(s.datastore), s.tokenSerializer),
+		s.maxAuthorizationModelCacheSize,
+	)
 
 	if s.cacheLimit > 0 && (s.checkQueryCacheEnabled || s.checkIteratorCacheEnabled) {
 		s.cache = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
 			storage.WithMaxCacheSize[any](int64(s.cacheLimit)),
 		}...)
+
+		if s.sharedCache.Addr != "" {
+			client := redis.NewClient(&redis.Options{
+				Addr:         s.sharedCache.Addr,
+				Username:     s.sharedCache.Username,
+				Password:     s.sharedCache.Password,
+				DB:           s.sharedCache.DB,
+				DialTimeout:  s.sharedCache.Timeout,
+				ReadTimeout:  s.sharedCache.Timeout,
+				WriteTimeout: s.sharedCache.Timeout,
+				// fall back to the local cache right away rather than retrying
+				MaxRetries: -1,
+			})
+			s.cache = sharedcache.New(client, s.cache,
+				sharedcache.WithKeyPrefix(s.sharedCache.KeyPrefix),
+				sharedcache.WithTimeout(s.sharedCache.Timeout),
+				sharedcache.WithBackoff(s.sharedCache.Backoff),
+				sharedcache.WithLogger(s.logger),
+			)
+		}
 	}
 
 	if s.cache != nil && s.cacheControllerEnabled {
@@ -717,15 +1038,36 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		)
 	}
 
+	localCheckerOptions := []graph.LocalCheckerOption{
+		graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		graph.WithOptimizations(s.IsExperimentallyEnabled(ExperimentalCheckOptimizations)),
+	}
+
+	var remoteCheckResolverOptions []graph.RemoteCheckResolverOpt
+	if s.remoteCheckDispatchEnabled {
+		remoteCheckResolverOptions = append(remoteCheckResolverOptions,
+			graph.WithSelfAddress(s.remoteCheckDispatchSelfAddress),
+			graph.WithPeerRefreshInterval(s.remoteCheckDispatchPeerRefreshInterval),
+			graph.WithPeerUnhealthyBackoff(s.remoteCheckDispatchPeerUnhealthyBackoff),
+			graph.WithRemoteCheckResolverLogger(s.logger),
+		)
+		if s.remoteCheckDispatchPeers != nil {
+			remoteCheckResolverOptions = append(remoteCheckResolverOptions, graph.WithPeerDiscoverer(s.remoteCheckDispatchPeers))
+		}
+		if len(s.remoteCheckDispatchDialOptions) > 0 {
+			remoteCheckResolverOptions = append(remoteCheckResolverOptions, graph.WithPeerDialOptions(s.remoteCheckDispatchDialOptions...))
+		}
+	}
+
 	s.checkResolver, s.checkResolverCloser = graph.NewOrderedCheckResolvers([]graph.CheckResolverOrderedBuilderOpt{
-		graph.WithLocalCheckerOpts([]graph.LocalCheckerOption{
-			graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
-			graph.WithOptimizations(s.IsExperimentallyEnabled(ExperimentalCheckOptimizations)),
-		}...),
+		graph.WithLocalCheckerOpts(localCheckerOptions...),
 		graph.WithCachedCheckResolverOpts(s.checkQueryCacheEnabled, checkCacheOptions...),
 		graph.WithDispatchThrottlingCheckResolverOpts(s.checkDispatchThrottlingEnabled, checkDispatchThrottlingOptions...),
+		graph.WithRemoteCheckResolverOpts(s.remoteCheckDispatchEnabled, remoteCheckResolverOptions...),
 	}...).Build()
 
+	s.explainCheckResolver = graph.NewLocalChecker(localCheckerOptions...)
+
 	if s.listObjectsDispatchThrottlingEnabled {
 		s.listObjectsDispatchThrottler = throttler.NewConstantRateThrottler(s.listObjectsDispatchThrottlingFrequency, "list_objects_dispatch_throttle")
 	}
@@ -762,6 +1104,22 @@ func (s *Server) Close() {
 
 	s.checkResolverCloser()
 
+	if s.tupleExpirationReaper != nil {
+		s.tupleExpirationReaper.Close()
+	}
+
+	if s.changelogPruner != nil {
+		s.changelogPruner.Close()
+	}
+
+	if s.storePurger != nil {
+		s.storePurger.Close()
+	}
+
+	if s.outboxDispatcher != nil {
+		s.outboxDispatcher.Close()
+	}
+
 	if s.cache != nil {
 		s.cache.Stop()
 	}
@@ -802,6 +1160,11 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 		return nil, err
 	}
 
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
 	storeID := req.GetStoreId()
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
@@ -917,6 +1280,11 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 		return err
 	}
 
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return err
+	}
+
 	storeID := req.GetStoreId()
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
@@ -1016,6 +1384,11 @@ func (s *Server) Read(ctx context.Context, req *openfgav1.ReadRequest) (*openfga
 		return nil, err
 	}
 
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
 	q := commands.NewReadQuery(s.datastore,
 		commands.WithReadQueryLogger(s.logger),
 		commands.WithReadQueryEncoder(s.encoder),
@@ -1063,16 +1436,37 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 		return nil, err
 	}
 
+	writeOpts, err := requestedWriteOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	cmd := commands.NewWriteCommand(
 		s.datastore,
 		commands.WithWriteCmdLogger(s.logger),
+		commands.WithWriteCmdStoreQuotas(s.storeQuotas),
 	)
-	return cmd.Execute(ctx, &openfgav1.WriteRequest{
+	var revision ulid.ULID
+	resp, err := cmd.Execute(ctx, &openfgav1.WriteRequest{
 		StoreId:              storeID,
 		AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
 		Writes:               req.GetWrites(),
 		Deletes:              req.GetDeletes(),
-	})
+	}, append(writeOpts, storage.WithCommittedRevision(&revision))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision == (ulid.ULID{}) {
+		// the write recorded no change, so the store is at the revision of its last one
+		revision, err = s.latestRevision(ctx, storeID)
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+	}
+	s.transport.SetHeader(ctx, RevisionHeader, newRevisionToken(storeID, revision))
+
+	return resp, nil
 }
 
 func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
@@ -1098,6 +1492,11 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		return nil, err
 	}
 
+	ctx, err = contextWithRequestedRevision(ctx, req.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
 	if !validator.RequestIsValidatedFromContext(ctx) {
 		if err := req.Validate(); err != nil {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -1111,14 +1510,21 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		return nil, err
 	}
 
-	checkQuery := commands.NewCheckCommand(
-		s.checkDatastore,
-		s.checkResolver,
-		typesys,
+	checkOptions := []commands.CheckQueryOption{
 		commands.WithCheckCommandLogger(s.logger),
 		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
 		commands.WithCheckCommandResolveNodeLimit(s.resolveNodeLimit),
 		commands.WithCacheController(s.cacheController),
+	}
+	if s.checkDatastoreBatchingEnabled {
+		checkOptions = append(checkOptions, commands.WithCheckCommandDatastoreBatching(s.checkDatastoreBatchingWindow, s.checkDatastoreBatchingMaxSize))
+	}
+
+	checkQuery := commands.NewCheckCommand(
+		s.checkDatastore,
+		s.checkResolver,
+		typesys,
+		checkOptions...,
 	)
 
 	resp, checkRequestMetadata, err := checkQuery.Execute(ctx, &commands.CheckCommandParams{
@@ -1280,6 +1686,7 @@ func (s *Server) WriteAuthorizationModel(ctx context.Context, req *openfgav1.Wri
 	c := commands.NewWriteAuthorizationModelCommand(s.datastore,
 		commands.WithWriteAuthModelLogger(s.logger),
 		commands.WithWriteAuthModelMaxSizeInBytes(s.maxAuthorizationModelSizeInBytes),
+		commands.WithWriteAuthModelStoreQuotas(s.storeQuotas, s.datastore),
 	)
 	res, err := c.Execute(ctx, req)
 	if err != nil {
@@ -1349,7 +1756,10 @@ func (s *Server) WriteAssertions(ctx context.Context, req *openfgav1.WriteAssert
 		return nil, err
 	}
 
-	c := commands.NewWriteAssertionsCommand(s.datastore, commands.WithWriteAssertCmdLogger(s.logger))
+	c := commands.NewWriteAssertionsCommand(s.datastore,
+		commands.WithWriteAssertCmdLogger(s.logger),
+		commands.WithWriteAssertCmdStoreQuotas(s.storeQuotas),
+	)
 	res, err := c.Execute(ctx, &openfgav1.WriteAssertionsRequest{
 		StoreId:              storeID,
 		AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
@@ -1447,7 +1857,18 @@ func (s *Server) CreateStore(ctx context.Context, req *openfgav1.CreateStoreRequ
 		return nil, err
 	}
 
-	c := commands.NewCreateStoreCommand(s.datastore, commands.WithCreateStoreCmdLogger(s.logger))
+	labels, err := requestedStoreLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) > 0 && s.storeLabeler == nil {
+		return nil, status.Error(codes.Unimplemented, "store labels are not supported by the datastore")
+	}
+
+	c := commands.NewCreateStoreCommand(s.datastore,
+		commands.WithCreateStoreCmdLogger(s.logger),
+		commands.WithCreateStoreCmdLabels(s.storeLabeler, labels),
+	)
 	res, err := c.Execute(ctx, req)
 	if err != nil {
 		return nil, err
@@ -1491,6 +1912,58 @@ func (s *Server) DeleteStore(ctx context.Context, req *openfgav1.DeleteStoreRequ
 	return res, nil
 }
 
+// UpdateStore renames a store and, if the request has the StoreLabelsHeader, replaces its labels. An empty header
+// removes all the labels. It requires the same permission as DeleteStore.
+func (s *Server) UpdateStore(ctx context.Context, req *openfgav1.UpdateStoreRequest) (*openfgav1.UpdateStoreResponse, error) {
+	ctx, span := tracer.Start(ctx, authz.UpdateStore, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.UpdateStore,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.UpdateStore)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := requestedStoreLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storeLabeler == nil {
+		return nil, status.Error(codes.Unimplemented, "UpdateStore is not supported by the datastore")
+	}
+
+	cmd := commands.NewUpdateStoreCommand(s.storeLabeler, commands.WithUpdateStoreCmdLogger(s.logger))
+	store, labels, err := cmd.Execute(ctx, req.GetStoreId(), req.GetName(), labels)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if len(labels) > 0 {
+		s.transport.SetHeader(ctx, StoreLabelsHeader, storage.FormatStoreLabels(labels))
+	}
+
+	return &openfgav1.UpdateStoreResponse{
+		Id:        store.GetId(),
+		Name:      store.GetName(),
+		CreatedAt: store.GetCreatedAt(),
+		UpdatedAt: store.GetUpdatedAt(),
+	}, nil
+}
+
 func (s *Server) GetStore(ctx context.Context, req *openfgav1.GetStoreRequest) (*openfgav1.GetStoreResponse, error) {
 	ctx, span := tracer.Start(ctx, authz.GetStore, trace.WithAttributes(
 		attribute.String("store_id", req.GetStoreId()),
@@ -1514,7 +1987,22 @@ func (s *Server) GetStore(ctx context.Context, req *openfgav1.GetStoreRequest) (
 	}
 
 	q := commands.NewGetStoreQuery(s.datastore, commands.WithGetStoreQueryLogger(s.logger))
-	return q.Execute(ctx, req)
+	res, err := q.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storeLabeler != nil {
+		labels, err := s.storeLabeler.ReadStoreLabels(ctx, req.GetStoreId())
+		if err != nil {
+			return nil, serverErrors.HandleError("Error reading store labels", err)
+		}
+		if len(labels) > 0 {
+			s.transport.SetHeader(ctx, StoreLabelsHeader, storage.FormatStoreLabels(labels))
+		}
+	}
+
+	return res, nil
 }
 
 func (s *Server) ListStores(ctx context.Context, req *openfgav1.ListStoresRequest) (*openfgav1.ListStoresResponse, error) {
@@ -1538,10 +2026,16 @@ func (s *Server) ListStores(ctx context.Context, req *openfgav1.ListStoresReques
 		return nil, err
 	}
 
+	namePrefix, labelSelector, err := requestedStoreFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// even though we have the list of store IDs, we need to call ListStoresQuery to fetch the entire metadata of the store.
 	q := commands.NewListStoresQuery(s.datastore,
 		commands.WithListStoresQueryLogger(s.logger),
 		commands.WithListStoresQueryEncoder(s.encoder),
+		commands.WithListStoresQueryFilter(namePrefix, labelSelector),
 	)
 	return q.Execute(ctx, req, storeIDs)
 }
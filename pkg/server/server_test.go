@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	language "github.com/openfga/language/pkg/go/transformer"
@@ -37,6 +38,7 @@ import (
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sharedcache"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
 	"github.com/openfga/openfga/pkg/storage/sqlite"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
@@ -364,6 +366,21 @@ func TestServerWithMemoryDatastore(t *testing.T) {
 	test.RunAllTests(t, ds)
 }
 
+func TestServerWithDatastoreMetricsKeepsOptionalInterfaces(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds), WithDatastoreMetrics(true, "memory", 0))
+	t.Cleanup(s.Close)
+
+	require.NotNil(t, s.changelogWatcher)
+	require.NotNil(t, s.tupleImporter)
+	require.NotNil(t, s.storeLabeler)
+}
+
 func TestServerWithMySQLDatastore(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -2263,6 +2280,64 @@ func TestServerCheckCache(t *testing.T) {
 		require.Nil(t, s.cache)
 		require.Equal(t, s.datastore, s.checkDatastore)
 	})
+
+	t.Run("shared_cache", func(t *testing.T) {
+		redisServer := miniredis.RunT(t)
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		newServer := func() *Server {
+			s := MustNewServerWithOpts(
+				WithDatastore(ds),
+				WithCheckQueryCacheEnabled(true),
+				WithCheckQueryCacheTTL(1*time.Minute),
+				WithCacheLimit(10),
+				WithSharedCache(serverconfig.SharedCacheConfig{
+					Addr:    redisServer.Addr(),
+					Timeout: time.Second,
+				}),
+			)
+			t.Cleanup(s.Close)
+			return s
+		}
+		first, second := newServer(), newServer()
+		require.IsType(t, &sharedcache.SharedCache{}, first.cache)
+
+		ctx := context.Background()
+		createStoreResp, err := first.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "shared"})
+		require.NoError(t, err)
+		storeID := createStoreResp.GetId()
+
+		writeModelResp, err := first.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId: storeID,
+			TypeDefinitions: language.MustTransformDSLToProto(`
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]`).GetTypeDefinitions(),
+			SchemaVersion: typesystem.SchemaVersion1_1,
+		})
+		require.NoError(t, err)
+
+		checkReq := &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+			TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		}
+		resp, err := first.Check(ctx, checkReq)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.NotEmpty(t, redisServer.Keys())
+
+		require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")}))
+
+		// the second server resolves the check from the entry cached by the first one
+		resp, err = second.Check(ctx, checkReq)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
 }
 
 func TestCheckWithCachedIterator(t *testing.T) {
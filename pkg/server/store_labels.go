@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// StoreLabelsHeader is the request header holding the labels of the store created or updated by CreateStore and
+	// UpdateStore, and the response header holding the labels of the store returned by GetStore and UpdateStore, as
+	// 'key1=value1,key2=value2' (see storage.ParseStoreLabels).
+	StoreLabelsHeader = "Openfga-Store-Labels"
+
+	// StoreNamePrefixHeader is the request header holding the prefix of the names of the stores returned by
+	// ListStores.
+	StoreNamePrefixHeader = "Openfga-Store-Name-Prefix"
+
+	// StoreLabelSelectorHeader is the request header holding the label selector of the stores returned by
+	// ListStores, e.g. 'env=prod,tenant!=acme,!legacy' (see storage.ParseLabelSelector).
+	StoreLabelSelectorHeader = "Openfga-Store-Label-Selector"
+)
+
+// IsStoreLabelsHeader reports whether the HTTP header is one of the request headers of the store labels, which are
+// forwarded to the gRPC server.
+func IsStoreLabelsHeader(header string) bool {
+	return strings.EqualFold(header, StoreLabelsHeader) ||
+		strings.EqualFold(header, StoreNamePrefixHeader) ||
+		strings.EqualFold(header, StoreLabelSelectorHeader)
+}
+
+// requestedStoreLabels returns the labels of the StoreLabelsHeader of the request, or nil if it has none.
+func requestedStoreLabels(ctx context.Context) (map[string]string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := md.Get(StoreLabelsHeader)
+	if len(header) == 0 {
+		return nil, nil
+	}
+
+	labels, err := storage.ParseStoreLabels(strings.Join(header, ","))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: %s", StoreLabelsHeader, err)
+	}
+	return labels, nil
+}
+
+// requestedStoreFilter returns the name prefix and label selector of the StoreNamePrefixHeader and
+// StoreLabelSelectorHeader of the request, if any.
+func requestedStoreFilter(ctx context.Context) (string, storage.LabelSelector, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var namePrefix string
+	if header := md.Get(StoreNamePrefixHeader); len(header) > 0 {
+		namePrefix = header[0]
+	}
+
+	selector, err := storage.ParseLabelSelector(strings.Join(md.Get(StoreLabelSelectorHeader), ","))
+	if err != nil {
+		return "", nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: %s", StoreLabelSelectorHeader, err)
+	}
+	return namePrefix, selector, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+func TestStoreLabels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	transport := &headersTransport{headers: make(map[string]string)}
+	s := MustNewServerWithOpts(WithDatastore(ds), WithTransport(transport))
+	t.Cleanup(s.Close)
+
+	withHeaders := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+
+	prod, err := s.CreateStore(withHeaders(StoreLabelsHeader, "env=prod,tenant=acme"), &openfgav1.CreateStoreRequest{Name: "labels-prod"})
+	require.NoError(t, err)
+	dev, err := s.CreateStore(withHeaders(StoreLabelsHeader, "env=dev"), &openfgav1.CreateStoreRequest{Name: "labels-dev"})
+	require.NoError(t, err)
+	_, err = s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "other"})
+	require.NoError(t, err)
+
+	listStoreIDs := func(ctx context.Context) []string {
+		resp, err := s.ListStores(ctx, &openfgav1.ListStoresRequest{})
+		require.NoError(t, err)
+
+		var ids []string
+		for _, store := range resp.GetStores() {
+			ids = append(ids, store.GetId())
+		}
+		return ids
+	}
+
+	t.Run("get_store_returns_the_labels", func(t *testing.T) {
+		_, err := s.GetStore(ctx, &openfgav1.GetStoreRequest{StoreId: prod.GetId()})
+		require.NoError(t, err)
+		require.Equal(t, "env=prod,tenant=acme", transport.get(StoreLabelsHeader))
+	})
+
+	t.Run("list_stores_filters_by_name_prefix_and_labels", func(t *testing.T) {
+		require.Len(t, listStoreIDs(ctx), 3)
+		require.ElementsMatch(t, []string{prod.GetId(), dev.GetId()}, listStoreIDs(withHeaders(StoreNamePrefixHeader, "labels-")))
+		require.Equal(t, []string{prod.GetId()}, listStoreIDs(withHeaders(StoreLabelSelectorHeader, "env=prod")))
+		require.Equal(t, []string{dev.GetId()}, listStoreIDs(withHeaders(StoreNamePrefixHeader, "labels-", StoreLabelSelectorHeader, "!tenant")))
+	})
+
+	t.Run("update_store_renames_and_replaces_the_labels", func(t *testing.T) {
+		resp, err := s.UpdateStore(withHeaders(StoreLabelsHeader, "env=staging"), &openfgav1.UpdateStoreRequest{
+			StoreId: dev.GetId(),
+			Name:    "labels-staging",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "labels-staging", resp.GetName())
+		require.Equal(t, "env=staging", transport.get(StoreLabelsHeader))
+
+		require.Equal(t, []string{dev.GetId()}, listStoreIDs(withHeaders(StoreLabelSelectorHeader, "env=staging")))
+	})
+
+	t.Run("update_store_without_labels_keeps_them", func(t *testing.T) {
+		resp, err := s.UpdateStore(ctx, &openfgav1.UpdateStoreRequest{StoreId: prod.GetId(), Name: "labels-production"})
+		require.NoError(t, err)
+		require.Equal(t, "labels-production", resp.GetName())
+		require.Equal(t, "env=prod,tenant=acme", transport.get(StoreLabelsHeader))
+	})
+
+	t.Run("update_store_rejects_an_unknown_store", func(t *testing.T) {
+		_, err := s.UpdateStore(ctx, &openfgav1.UpdateStoreRequest{StoreId: "01JA6WMC6ZPRWQVEH3DVGWF6QS", Name: "unknown"})
+		require.Equal(t, codes.Code(openfgav1.NotFoundErrorCode_store_id_not_found), status.Code(err))
+	})
+
+	t.Run("rejects_invalid_headers", func(t *testing.T) {
+		_, err := s.CreateStore(withHeaders(StoreLabelsHeader, "env"), &openfgav1.CreateStoreRequest{Name: "invalid"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = s.ListStores(withHeaders(StoreLabelSelectorHeader, "env=-prod"), &openfgav1.ListStoresRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/storequota"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestStoreQuotas(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	t.Run("enforces_the_quotas_of_the_stores", func(t *testing.T) {
+		s := MustNewServerWithOpts(WithDatastore(ds), WithStoreQuotas(storequota.Config{
+			Quota: storequota.Quota{MaxTuples: 1, MaxAuthorizationModels: 1},
+		}))
+		t.Cleanup(s.Close)
+
+		createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "quotas"})
+		require.NoError(t, err)
+		storeID := createStoreResp.GetId()
+
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]`)
+		writeModelReq := &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			TypeDefinitions: model.GetTypeDefinitions(),
+			SchemaVersion:   model.GetSchemaVersion(),
+		}
+		_, err = s.WriteAuthorizationModel(ctx, writeModelReq)
+		require.NoError(t, err)
+
+		_, err = s.WriteAuthorizationModel(ctx, writeModelReq)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.ErrorIs(t, err, serverErrors.StoreAuthorizationModelQuotaExceeded(1))
+
+		_, err = s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")}},
+		})
+		require.NoError(t, err)
+
+		_, err = s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:bob")}},
+		})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.ErrorIs(t, err, serverErrors.StoreTupleQuotaExceeded(1))
+	})
+
+	t.Run("tuple_quotas_require_a_datastore_counting_the_tuples", func(t *testing.T) {
+		_, err := NewServerWithOpts(WithDatastore(struct{ storage.OpenFGADatastore }{ds}), WithStoreQuotas(storequota.Config{
+			Quota: storequota.Quota{MaxTuples: 1},
+		}))
+		require.ErrorContains(t, err, "require a datastore which counts the tuples")
+	})
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// UndeleteStore restores a deleted store, along with all its data, if it was deleted within the store purge
+// retention (see WithStorePurge). It requires the same permission as DeleteStore.
+func (s *Server) UndeleteStore(ctx context.Context, req *UndeleteStoreRequest) (*UndeleteStoreResponse, error) {
+	ctx, span := tracer.Start(ctx, authz.UndeleteStore, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.UndeleteStore,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.UndeleteStore)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storeUndeleter == nil {
+		return nil, status.Error(codes.Unimplemented, "UndeleteStore is not supported by the datastore")
+	}
+
+	cmd := commands.NewUndeleteStoreCommand(s.storeUndeleter,
+		commands.WithUndeleteStoreCmdLogger(s.logger),
+		commands.WithUndeleteStoreCmdGracePeriod(s.storePurgeRetention),
+	)
+	store, err := cmd.Execute(ctx, req.GetStoreId())
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return &UndeleteStoreResponse{Store: store}, nil
+}
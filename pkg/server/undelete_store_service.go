@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	undeleteStoreServiceName = "openfga.v1.UndeleteStoreService"
+	undeleteStoreFullMethod  = "/" + undeleteStoreServiceName + "/UndeleteStore"
+	undeleteStoreHTTPPath    = "/stores/{store_id}/undelete"
+)
+
+// UndeleteStoreRequest is the request of the UndeleteStore RPC, served by a JSON encoded gRPC service (see
+// jsoncodec).
+type UndeleteStoreRequest struct {
+	StoreID string `json:"store_id"`
+}
+
+// UndeleteStoreResponse is the outcome of UndeleteStore. Its JSON representation is that of the GetStore response.
+type UndeleteStoreResponse struct {
+	Store *openfgav1.Store
+}
+
+func (r *UndeleteStoreRequest) GetStoreId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.StoreID
+}
+
+// Validate applies the same rules as the DeleteStore request.
+func (r *UndeleteStoreRequest) Validate() error {
+	if _, err := ulid.ParseStrict(r.GetStoreId()); err != nil {
+		return errors.New("invalid UndeleteStoreRequest.StoreId: value does not match regex pattern \"^[ABCDEFGHJKMNPQRSTVWXYZ0-9]{26}$\"")
+	}
+	return nil
+}
+
+func (r *UndeleteStoreResponse) MarshalJSON() ([]byte, error) {
+	store, err := marshalProtoField(r.Store)
+	if err != nil || store == nil {
+		return []byte("{}"), err
+	}
+	return store, nil
+}
+
+func (r *UndeleteStoreResponse) UnmarshalJSON(data []byte) error {
+	store := &openfgav1.Store{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, store); err != nil {
+		return err
+	}
+
+	*r = UndeleteStoreResponse{Store: store}
+	return nil
+}
+
+// UndeleteStoreServiceServer is the server API for the UndeleteStore service.
+type UndeleteStoreServiceServer interface {
+	UndeleteStore(context.Context, *UndeleteStoreRequest) (*UndeleteStoreResponse, error)
+}
+
+var _ UndeleteStoreServiceServer = (*Server)(nil)
+
+// UndeleteStoreServiceDesc is the grpc.ServiceDesc for the UndeleteStore service.
+var UndeleteStoreServiceDesc = grpc.ServiceDesc{
+	ServiceName: undeleteStoreServiceName,
+	HandlerType: (*UndeleteStoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UndeleteStore",
+			Handler:    undeleteStoreHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterUndeleteStoreServiceServer registers the UndeleteStore service in the given gRPC server. It must be
+// registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterUndeleteStoreServiceServer(s grpc.ServiceRegistrar, srv UndeleteStoreServiceServer) {
+	s.RegisterService(&UndeleteStoreServiceDesc, srv)
+}
+
+func undeleteStoreHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteStoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UndeleteStoreServiceServer).UndeleteStore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: undeleteStoreFullMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UndeleteStoreServiceServer).UndeleteStore(ctx, req.(*UndeleteStoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UndeleteStoreServiceClient is the client API for the UndeleteStore service.
+type UndeleteStoreServiceClient interface {
+	UndeleteStore(ctx context.Context, in *UndeleteStoreRequest, opts ...grpc.CallOption) (*UndeleteStoreResponse, error)
+}
+
+type undeleteStoreServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUndeleteStoreServiceClient(cc grpc.ClientConnInterface) UndeleteStoreServiceClient {
+	return &undeleteStoreServiceClient{cc: cc}
+}
+
+func (c *undeleteStoreServiceClient) UndeleteStore(ctx context.Context, in *UndeleteStoreRequest, opts ...grpc.CallOption) (*UndeleteStoreResponse, error) {
+	out := new(UndeleteStoreResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	if err := c.cc.Invoke(ctx, undeleteStoreFullMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterUndeleteStoreServiceHandler registers the HTTP route of UndeleteStore (POST /stores/{store_id}/undelete)
+// in the gateway mux. The body of the request is an empty JSON object.
+func RegisterUndeleteStoreServiceHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := NewUndeleteStoreServiceClient(conn)
+
+	return handleJSONPath(mux, undeleteStoreHTTPPath, undeleteStoreFullMethod,
+		func(req *UndeleteStoreRequest, storeID string) {
+			req.StoreID = storeID
+		},
+		client.UndeleteStore,
+	)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestUndeleteStore(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds), WithStorePurge(time.Hour, time.Minute, 100))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "undelete"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+
+	_, err = s.DeleteStore(ctx, &openfgav1.DeleteStoreRequest{StoreId: storeID})
+	require.NoError(t, err)
+
+	_, err = s.GetStore(ctx, &openfgav1.GetStoreRequest{StoreId: storeID})
+	require.Equal(t, codes.Code(openfgav1.NotFoundErrorCode_store_id_not_found), status.Code(err))
+
+	t.Run("restores_the_store_and_its_data", func(t *testing.T) {
+		resp, err := s.UndeleteStore(ctx, &UndeleteStoreRequest{StoreID: storeID})
+		require.NoError(t, err)
+		require.Equal(t, storeID, resp.Store.GetId())
+		require.Equal(t, "undelete", resp.Store.GetName())
+		require.Nil(t, resp.Store.GetDeletedAt())
+
+		_, err = s.GetStore(ctx, &openfgav1.GetStoreRequest{StoreId: storeID})
+		require.NoError(t, err)
+
+		_, err = ds.ReadUserTuple(ctx, storeID, tk, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects_an_unknown_store", func(t *testing.T) {
+		_, err := s.UndeleteStore(ctx, &UndeleteStoreRequest{StoreID: "01JA6WMC6ZPRWQVEH3DVGWF6QS"})
+		require.Equal(t, codes.Code(openfgav1.NotFoundErrorCode_store_id_not_found), status.Code(err))
+	})
+
+	t.Run("rejects_an_invalid_store_id", func(t *testing.T) {
+		_, err := s.UndeleteStore(ctx, &UndeleteStoreRequest{StoreID: "invalid"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUndeleteStoreJSON(t *testing.T) {
+	in := &UndeleteStoreRequest{StoreID: "01JA6WMC6ZPRWQVEH3DVGWF6QS"}
+
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"store_id": "01JA6WMC6ZPRWQVEH3DVGWF6QS"}`, string(data))
+
+	resp, err := json.Marshal(&UndeleteStoreResponse{Store: &openfgav1.Store{Id: in.StoreID, Name: "undelete"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": "01JA6WMC6ZPRWQVEH3DVGWF6QS", "name": "undelete"}`, string(resp))
+
+	var out UndeleteStoreResponse
+	require.NoError(t, json.Unmarshal(resp, &out))
+	require.Equal(t, in.StoreID, out.Store.GetId())
+	require.Equal(t, "undelete", out.Store.GetName())
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/authz"
+	"github.com/openfga/openfga/pkg/middleware/validator"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// WatchChanges streams the changes of a store as they are committed, resuming from the continuation token of
+// the request, like a ReadChanges that never ends. The stream sends a heartbeat, with no changes and the
+// latest continuation token, when it has been idle for the heartbeat interval. Calling it requires the same
+// permission as ReadChanges.
+//
+// The changes are read from the changelog when the datastore notifies them (see storage.ChangelogWatcher),
+// or by polling it otherwise. The stream ends with codes.Unavailable when the server shuts down, and can
+// then be resumed on another server with the last continuation token received.
+func (s *Server) WatchChanges(req *WatchChangesRequest, srv WatchChangesServerStream) error {
+	ctx := srv.Context()
+	ctx, span := tracer.Start(ctx, authz.WatchChanges, trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.KeyValue{Key: "type", Value: attribute.StringValue(req.Type)},
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  authz.WatchChanges,
+	})
+
+	err := s.checkAuthz(ctx, req.GetStoreId(), authz.WatchChanges)
+	if err != nil {
+		return err
+	}
+
+	// the stream must not hold up the graceful shutdown of the server
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.ctx != nil {
+		stopOnShutdown := context.AfterFunc(s.ctx, cancel)
+		defer stopOnShutdown()
+	}
+
+	// let the client know that the stream is established
+	if err := srv.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	readChangesRequest := req.readChangesRequest()
+	q := commands.NewWatchChangesQuery(s.datastore,
+		commands.WithWatchChangesQueryLogger(s.logger),
+		commands.WithWatchChangesQueryWatcher(s.changelogWatcher),
+		commands.WithWatchChangesQueryReadChangesOptions(
+			commands.WithReadChangesQueryLogger(s.logger),
+			commands.WithReadChangesQueryEncoder(s.encoder),
+			commands.WithContinuationTokenSerializer(s.tokenSerializer),
+			commands.WithReadChangeQueryHorizonOffset(s.changelogHorizonOffset),
+		),
+		commands.WithWatchChangesQueryHeartbeatInterval(s.watchChangesHeartbeatInterval),
+		commands.WithWatchChangesQueryPollInterval(s.watchChangesMinPollInterval, s.watchChangesMaxPollInterval),
+	)
+	err = q.Execute(ctx, &commands.WatchChangesQueryParams{
+		StoreID:           req.GetStoreId(),
+		ObjectType:        req.Type,
+		Relation:          req.Relation,
+		UserType:          req.UserType,
+		ContinuationToken: readChangesRequest.GetContinuationToken(),
+		StartTime:         readChangesRequest.GetStartTime(),
+		PageSize:          req.PageSize,
+	}, func(changes []*openfgav1.TupleChange, continuationToken string) error {
+		return srv.Send(&WatchChangesResponse{
+			Changes:           changes,
+			ContinuationToken: continuationToken,
+		})
+	})
+
+	switch {
+	case s.ctx != nil && s.ctx.Err() != nil:
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case err != nil:
+		telemetry.TraceError(span, err)
+		return err
+	default:
+		return nil
+	}
+}
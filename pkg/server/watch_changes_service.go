@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	watchChangesServiceName = "openfga.v1.WatchChangesService"
+	// WatchChangesFullMethod is the full gRPC method name of WatchChanges. Its streams are long-lived, so they
+	// must not be subject to the request timeout.
+	WatchChangesFullMethod = "/" + watchChangesServiceName + "/WatchChanges"
+	watchChangesHTTPPath   = "/stores/{store_id}/watch-changes"
+)
+
+// WatchChangesRequest is the request of the WatchChanges RPC, served by a JSON encoded gRPC service
+// (see jsoncodec).
+type WatchChangesRequest struct {
+	StoreID string `json:"store_id"`
+	// Type, if set, restricts the changes to the objects of this type.
+	Type string `json:"type,omitempty"`
+	// Relation, if set, restricts the changes to this relation.
+	Relation string `json:"relation,omitempty"`
+	// UserType, if set, restricts the changes to the users of this type (e.g. `user`), or to this userset
+	// type (e.g. `group#member`).
+	UserType string `json:"user_type,omitempty"`
+	// ContinuationToken is the token of a previous WatchChanges or ReadChanges response to resume from.
+	ContinuationToken string `json:"continuation_token,omitempty"`
+	// StartTime, if set and without a ContinuationToken, is the time to stream the changes from. Without
+	// both, the changes are streamed from the start of the changelog.
+	StartTime *time.Time `json:"start_time,omitempty"`
+	// PageSize is the maximum number of changes per response. Zero means the default.
+	PageSize int32 `json:"page_size,omitempty"`
+}
+
+// WatchChangesResponse holds the changes committed since the previous response of the stream, and the token to
+// resume after them. A heartbeat has no changes.
+type WatchChangesResponse struct {
+	Changes           []*openfgav1.TupleChange
+	ContinuationToken string
+}
+
+func (r *WatchChangesRequest) GetStoreId() string { //nolint:revive,stylecheck
+	if r == nil {
+		return ""
+	}
+	return r.StoreID
+}
+
+// readChangesRequest returns the equivalent ReadChanges request, whose validation rules apply.
+func (r *WatchChangesRequest) readChangesRequest() *openfgav1.ReadChangesRequest {
+	req := &openfgav1.ReadChangesRequest{
+		StoreId:           r.StoreID,
+		Type:              r.Type,
+		ContinuationToken: r.ContinuationToken,
+	}
+	if r.StartTime != nil {
+		req.StartTime = timestamppb.New(*r.StartTime)
+	}
+	if r.PageSize != 0 {
+		req.PageSize = wrapperspb.Int32(r.PageSize)
+	}
+	return req
+}
+
+// Validate applies the same rules as the ReadChanges request.
+func (r *WatchChangesRequest) Validate() error {
+	return r.readChangesRequest().Validate()
+}
+
+type watchChangesResponseJSON struct {
+	Changes           []json.RawMessage `json:"changes"`
+	ContinuationToken string            `json:"continuation_token"`
+}
+
+func (r *WatchChangesResponse) MarshalJSON() ([]byte, error) {
+	changes := make([]json.RawMessage, 0, len(r.Changes))
+	for _, change := range r.Changes {
+		data, err := marshalProtoField(change)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, data)
+	}
+
+	return json.Marshal(&watchChangesResponseJSON{
+		Changes:           changes,
+		ContinuationToken: r.ContinuationToken,
+	})
+}
+
+func (r *WatchChangesResponse) UnmarshalJSON(data []byte) error {
+	var in watchChangesResponseJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	changes := make([]*openfgav1.TupleChange, 0, len(in.Changes))
+	for _, raw := range in.Changes {
+		change := &openfgav1.TupleChange{}
+		if err := protojson.Unmarshal(raw, change); err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+
+	*r = WatchChangesResponse{
+		Changes:           changes,
+		ContinuationToken: in.ContinuationToken,
+	}
+	return nil
+}
+
+// WatchChangesServiceServer is the server API for the WatchChanges service.
+type WatchChangesServiceServer interface {
+	WatchChanges(*WatchChangesRequest, WatchChangesServerStream) error
+}
+
+// WatchChangesServerStream is the server side of a WatchChanges stream.
+type WatchChangesServerStream interface {
+	Send(*WatchChangesResponse) error
+	grpc.ServerStream
+}
+
+var _ WatchChangesServiceServer = (*Server)(nil)
+
+// WatchChangesServiceDesc is the grpc.ServiceDesc for the WatchChanges service.
+var WatchChangesServiceDesc = grpc.ServiceDesc{
+	ServiceName: watchChangesServiceName,
+	HandlerType: (*WatchChangesServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       watchChangesHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// RegisterWatchChangesServiceServer registers the WatchChanges service in the given gRPC server. It must be
+// registered in the same server as the OpenFGA service, so that both share the same interceptors.
+func RegisterWatchChangesServiceServer(s grpc.ServiceRegistrar, srv WatchChangesServiceServer) {
+	s.RegisterService(&WatchChangesServiceDesc, srv)
+}
+
+func watchChangesHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(WatchChangesRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(WatchChangesServiceServer).WatchChanges(in, &watchChangesServerStream{stream})
+}
+
+type watchChangesServerStream struct {
+	grpc.ServerStream
+}
+
+func (x *watchChangesServerStream) Send(m *WatchChangesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// WatchChangesServiceClient is the client API for the WatchChanges service.
+type WatchChangesServiceClient interface {
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (WatchChangesClientStream, error)
+}
+
+// WatchChangesClientStream is the client side of a WatchChanges stream.
+type WatchChangesClientStream interface {
+	Recv() (*WatchChangesResponse, error)
+	grpc.ClientStream
+}
+
+type watchChangesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWatchChangesServiceClient(cc grpc.ClientConnInterface) WatchChangesServiceClient {
+	return &watchChangesServiceClient{cc: cc}
+}
+
+func (c *watchChangesServiceClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (WatchChangesClientStream, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodecName)}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WatchChangesServiceDesc.Streams[0], WatchChangesFullMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &watchChangesClientStream{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type watchChangesClientStream struct {
+	grpc.ClientStream
+}
+
+func (x *watchChangesClientStream) Recv() (*WatchChangesResponse, error) {
+	m := new(WatchChangesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RegisterWatchChangesServiceHandler registers the HTTP route of WatchChanges (POST /stores/{store_id}/watch-changes)
+// in the gateway mux. The responses are streamed as newline delimited JSON.
+func RegisterWatchChangesServiceHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	client := NewWatchChangesServiceClient(conn)
+
+	return handleJSONStreamPath(mux, watchChangesHTTPPath, WatchChangesFullMethod,
+		func(req *WatchChangesRequest, storeID string) {
+			req.StoreID = storeID
+		},
+		func(ctx context.Context, req *WatchChangesRequest, opts ...grpc.CallOption) (jsonClientStream[WatchChangesResponse], error) {
+			return client.WatchChanges(ctx, req, opts...)
+		},
+	)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestWatchChanges(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	serverCtx, shutdown := context.WithCancel(context.Background())
+	t.Cleanup(shutdown)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithContext(serverCtx),
+		WithWatchChangesHeartbeatInterval(time.Minute),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "watch-changes"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	write := func(t *testing.T, tk *openfgav1.TupleKey) {
+		require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+	}
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	RegisterWatchChangesServiceServer(grpcServer, s)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	client := NewWatchChangesServiceClient(conn)
+
+	var lastToken string
+
+	t.Run("grpc", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := client.WatchChanges(ctx, &WatchChangesRequest{StoreID: storeID, Type: "doc"})
+		require.NoError(t, err)
+
+		write(t, tuple.NewTupleKey("folder:1", "viewer", "user:anne"))
+		write(t, tuple.NewTupleKey("doc:1", "viewer", "user:anne"))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.Len(t, resp.Changes, 1)
+		require.Equal(t, "doc:1", resp.Changes[0].GetTupleKey().GetObject())
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, resp.Changes[0].GetOperation())
+		require.NotEmpty(t, resp.ContinuationToken)
+		lastToken = resp.ContinuationToken
+	})
+
+	t.Run("validates_request", func(t *testing.T) {
+		stream, err := client.WatchChanges(ctx, &WatchChangesRequest{StoreID: "invalid"})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("http", func(t *testing.T) {
+		mux := runtime.NewServeMux()
+		require.NoError(t, RegisterWatchChangesServiceHandler(mux, conn))
+
+		httpServer := httptest.NewServer(mux)
+		t.Cleanup(httpServer.Close)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		body, err := json.Marshal(&WatchChangesRequest{Type: "doc", ContinuationToken: lastToken})
+		require.NoError(t, err)
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL+"/stores/"+storeID+"/watch-changes", bytes.NewReader(body))
+		require.NoError(t, err)
+		httpResp, err := http.DefaultClient.Do(httpReq)
+		require.NoError(t, err)
+		defer httpResp.Body.Close()
+		require.Equal(t, http.StatusOK, httpResp.StatusCode)
+		require.Equal(t, "application/x-ndjson", httpResp.Header.Get("Content-Type"))
+
+		// the response headers are received before any change
+		write(t, tuple.NewTupleKey("doc:2", "viewer", "user:anne"))
+
+		scanner := bufio.NewScanner(httpResp.Body)
+		require.True(t, scanner.Scan())
+
+		var line struct {
+			Result *WatchChangesResponse `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		require.Len(t, line.Result.Changes, 1)
+		require.Equal(t, "doc:2", line.Result.Changes[0].GetTupleKey().GetObject())
+
+		t.Run("returns_errors_with_their_status", func(t *testing.T) {
+			httpResp, err := http.Post(httpServer.URL+"/stores/invalid/watch-changes", "application/json", bytes.NewReader([]byte("{}")))
+			require.NoError(t, err)
+			defer httpResp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, httpResp.StatusCode)
+		})
+	})
+
+	t.Run("ends_on_shutdown", func(t *testing.T) {
+		stream, err := client.WatchChanges(ctx, &WatchChangesRequest{StoreID: storeID, ContinuationToken: lastToken, Type: "doc"})
+		require.NoError(t, err)
+		_, err = stream.Header()
+		require.NoError(t, err)
+
+		shutdown()
+
+		for {
+			_, err = stream.Recv()
+			if err != nil {
+				break
+			}
+		}
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
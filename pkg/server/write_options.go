@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// WriteOnDuplicateHeader is the request header setting what a Write does with the tuples to write that
+	// already exist: 'error', the default, or 'ignore' (see storage.OnDuplicateInsertIgnore).
+	WriteOnDuplicateHeader = "Openfga-Write-On-Duplicate"
+
+	// WriteOnMissingHeader is the request header setting what a Write does with the tuples to delete that don't
+	// exist: 'error', the default, or 'ignore' (see storage.OnMissingDeleteIgnore).
+	WriteOnMissingHeader = "Openfga-Write-On-Missing"
+
+	// WritePreconditionExistsHeader is the request header holding the tuples that must exist for a Write to be
+	// applied, as 'object#relation@user', separated by commas.
+	WritePreconditionExistsHeader = "Openfga-Write-Precondition-Exists"
+
+	// WritePreconditionNotExistsHeader is the request header holding the tuples that must not exist for a Write
+	// to be applied, as 'object#relation@user', separated by commas.
+	WritePreconditionNotExistsHeader = "Openfga-Write-Precondition-Not-Exists"
+
+	// WriteExpiresAtHeader is the request header holding when the tuples written by a Write expire, as an RFC 3339
+	// timestamp (see storage.WithExpiresAt).
+	WriteExpiresAtHeader = "Openfga-Write-Expires-At"
+)
+
+// IsWriteOptionsHeader reports whether the HTTP header is one of the request headers of the write modes, which are
+// forwarded to the gRPC server.
+func IsWriteOptionsHeader(header string) bool {
+	return strings.EqualFold(header, WriteOnDuplicateHeader) ||
+		strings.EqualFold(header, WriteOnMissingHeader) ||
+		strings.EqualFold(header, WritePreconditionExistsHeader) ||
+		strings.EqualFold(header, WritePreconditionNotExistsHeader) ||
+		strings.EqualFold(header, WriteExpiresAtHeader)
+}
+
+// requestedWriteOptions returns the write modes of the headers of the request, if any.
+func requestedWriteOptions(ctx context.Context) ([]storage.TupleWriteOption, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var opts []storage.TupleWriteOption
+	if header := md.Get(WriteOnDuplicateHeader); len(header) > 0 {
+		switch header[0] {
+		case "error":
+		case "ignore":
+			opts = append(opts, storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore))
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: must be 'error' or 'ignore'", WriteOnDuplicateHeader)
+		}
+	}
+
+	if header := md.Get(WriteOnMissingHeader); len(header) > 0 {
+		switch header[0] {
+		case "error":
+		case "ignore":
+			opts = append(opts, storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore))
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: must be 'error' or 'ignore'", WriteOnMissingHeader)
+		}
+	}
+
+	for _, h := range []struct {
+		name   string
+		exists bool
+	}{
+		{WritePreconditionExistsHeader, true},
+		{WritePreconditionNotExistsHeader, false},
+	} {
+		preconditions, err := parseWritePreconditions(md.Get(h.name), h.exists)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: %s", h.name, err)
+		}
+		if len(preconditions) > 0 {
+			opts = append(opts, storage.WithPreconditions(preconditions...))
+		}
+	}
+
+	if header := md.Get(WriteExpiresAtHeader); len(header) > 0 {
+		expiresAt, err := time.Parse(time.RFC3339, header[0])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' header: must be an RFC 3339 timestamp", WriteExpiresAtHeader)
+		}
+		opts = append(opts, storage.WithExpiresAt(expiresAt))
+	}
+
+	return opts, nil
+}
+
+// parseWritePreconditions parses the tuples of the values of a precondition header.
+func parseWritePreconditions(values []string, exists bool) ([]storage.WritePrecondition, error) {
+	var preconditions []storage.WritePrecondition
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+
+			tk, err := tuple.ParseTupleString(s)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a tuple of the form 'object#relation@user'", s)
+			}
+			preconditions = append(preconditions, storage.WritePrecondition{
+				TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(tk),
+				Exists:   exists,
+			})
+		}
+	}
+	return preconditions, nil
+}
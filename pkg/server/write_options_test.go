@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestWriteOptionsHeaders(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "write-options"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId: storeID,
+		TypeDefinitions: testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+		SchemaVersion: "1.1",
+	})
+	require.NoError(t, err)
+
+	withHeaders := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+	write := func(ctx context.Context, tk *openfgav1.TupleKey) error {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+		})
+		return err
+	}
+	deleteTuple := func(ctx context.Context, tk *openfgav1.TupleKey) error {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk),
+			}},
+		})
+		return err
+	}
+
+	anne := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	bob := tuple.NewTupleKey("document:1", "viewer", "user:bob")
+	require.NoError(t, write(ctx, anne))
+
+	t.Run("on_duplicate", func(t *testing.T) {
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_write_failed_due_to_invalid_input), status.Code(write(ctx, anne)))
+		require.NoError(t, write(withHeaders(WriteOnDuplicateHeader, "ignore"), anne))
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_write_failed_due_to_invalid_input), status.Code(write(withHeaders(WriteOnDuplicateHeader, "error"), anne)))
+	})
+
+	t.Run("on_missing", func(t *testing.T) {
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_write_failed_due_to_invalid_input), status.Code(deleteTuple(ctx, bob)))
+		require.NoError(t, deleteTuple(withHeaders(WriteOnMissingHeader, "ignore"), bob))
+	})
+
+	t.Run("preconditions", func(t *testing.T) {
+		err := write(withHeaders(WritePreconditionNotExistsHeader, "document:1#viewer@user:anne"), bob)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		err = write(withHeaders(WritePreconditionExistsHeader, "document:1#viewer@user:anne", WritePreconditionNotExistsHeader, "document:2#viewer@user:anne, document:1#viewer@user:bob"), bob)
+		require.NoError(t, err)
+	})
+
+	t.Run("expires_at", func(t *testing.T) {
+		charlie := tuple.NewTupleKey("document:1", "viewer", "user:charlie")
+		err := write(withHeaders(WriteExpiresAtHeader, time.Now().Add(-time.Minute).Format(time.RFC3339)), charlie)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+
+		require.NoError(t, write(withHeaders(WriteExpiresAtHeader, time.Now().Add(time.Second).Format(time.RFC3339Nano)), charlie))
+		_, err = ds.ReadUserTuple(ctx, storeID, charlie, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+
+		// the tuple is no longer read once expired
+		require.Eventually(t, func() bool {
+			_, err := ds.ReadUserTuple(ctx, storeID, charlie, storage.ReadUserTupleOptions{})
+			return errors.Is(err, storage.ErrNotFound)
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("rejects_invalid_headers", func(t *testing.T) {
+		err := write(withHeaders(WriteOnDuplicateHeader, "skip"), bob)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		err = write(withHeaders(WritePreconditionExistsHeader, "document:1#viewer"), bob)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		err = write(withHeaders(WriteExpiresAtHeader, "tomorrow"), bob)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,2172 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+var tracer = otel.Tracer("openfga/pkg/storage/bolt")
+
+func startTrace(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "bolt."+name)
+}
+
+const (
+	defaultMaxTuplesPerWrite             = 100
+	defaultMaxTypesPerAuthorizationModel = 100
+
+	// openTimeout is how long opening the database waits for another process to release its file.
+	openTimeout = 5 * time.Second
+)
+
+// The top-level buckets of the database. Apart from the stores bucket, they hold one bucket per store.
+var (
+	// storesBucket maps the ID of each store to the store.
+	storesBucket = []byte("stores")
+	// modelsBucket maps the ID of each authorization model to the model. Model IDs are ULIDs, so the keys are
+	// ordered from the oldest model to the latest one.
+	modelsBucket = []byte("authorization_models")
+	// assertionsBucket maps the ID of each authorization model to its assertions.
+	assertionsBucket = []byte("assertions")
+	// tuplesBucket maps the forward key of each tuple (object#relation@user) to its record, so that the tuples of an
+	// object, or of an object and relation, are read with a prefix scan.
+	tuplesBucket = []byte("tuples")
+	// usersBucket indexes the tuples by their reverse key (user, then objecttype#relation@objectid), so that the
+	// objects a user is related to are read with a prefix scan.
+	usersBucket = []byte("tuples_by_user")
+	// changelogBucket maps the ULID of each change to the change, ordering the changes by the time they were made.
+	changelogBucket = []byte("changelog")
+	// expirationsBucket indexes the tuples which expire by their expiry, then their forward key.
+	expirationsBucket = []byte("tuple_expirations")
+	// horizonsBucket maps the ID of each store to the ULID of the last change deleted from its changelog, before
+	// which the continuation tokens have expired. Unlike the other buckets, it doesn't hold one bucket per store.
+	horizonsBucket = []byte("changelog_horizons")
+	// storeLabelsBucket maps the ID of each store with labels to its labels, as JSON. Like the horizons bucket, it
+	// doesn't hold one bucket per store.
+	storeLabelsBucket = []byte("store_labels")
+	// tupleCountsBucket maps the ID of each store with tuples to their number, as a big-endian uint64, kept up to
+	// date as they are written and deleted. Like the horizons bucket, it doesn't hold one bucket per store.
+	tupleCountsBucket = []byte("tuple_counts")
+	// outboxBucket maps the ID of each event of the outbox to the event, as JSON. Event IDs are ULIDs, so the keys
+	// are ordered from the oldest event to the latest one. Like the horizons bucket, it doesn't hold one bucket per
+	// store.
+	outboxBucket = []byte("outbox")
+)
+
+// StorageOption defines a function type used for configuring a [Bolt] instance.
+type StorageOption func(ds *Bolt)
+
+// Bolt provides an implementation of [storage.OpenFGADatastore] persisting the data in a single file, with an
+// embedded bbolt key-value store.
+//
+// bbolt serializes the write transactions, so that a Write is applied atomically and its preconditions are checked
+// against the tuples it modifies, while reads run concurrently on a consistent snapshot of the database.
+// A database file can only be opened by one process at a time.
+type Bolt struct {
+	db                            *bbolt.DB
+	maxTuplesPerWrite             int
+	maxTypesPerAuthorizationModel int
+	tokenSerializer               encoder.ContinuationTokenSerializer
+	changelogBroadcaster          *storage.ChangelogBroadcaster
+	outbox                        bool
+}
+
+// Ensures that [Bolt] implements the [storage.OpenFGADatastore] interface.
+var _ storage.OpenFGADatastore = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.ChangelogWatcher] interface.
+var _ storage.ChangelogWatcher = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.TupleImporter] interface.
+var _ storage.TupleImporter = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.TupleReaper] interface.
+var _ storage.TupleReaper = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.ChangelogPruner] interface.
+var _ storage.ChangelogPruner = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.StoreUndeleter] interface.
+var _ storage.StoreUndeleter = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.StorePurger] interface.
+var _ storage.StorePurger = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.StoreLabeler] interface.
+var _ storage.StoreLabeler = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.TupleCounter] interface.
+var _ storage.TupleCounter = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.RecordCopier] interface.
+var _ storage.RecordCopier = (*Bolt)(nil)
+
+// Ensures that [Bolt] implements the [storage.EventOutbox] interface.
+var _ storage.EventOutbox = (*Bolt)(nil)
+
+// New opens, or creates, the database stored in the file at path and returns a [Bolt] datastore given the options.
+func New(path string, opts ...StorageOption) (*Bolt, error) {
+	ds := &Bolt{
+		maxTuplesPerWrite:             defaultMaxTuplesPerWrite,
+		maxTypesPerAuthorizationModel: defaultMaxTypesPerAuthorizationModel,
+		tokenSerializer:               encoder.NewStringContinuationTokenSerializer(),
+		changelogBroadcaster:          storage.NewChangelogBroadcaster(),
+	}
+
+	for _, opt := range opts {
+		opt(ds)
+	}
+
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt database: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{storesBucket, modelsBucket, assertionsBucket, tuplesBucket, usersBucket, changelogBucket, expirationsBucket, horizonsBucket, storeLabelsBucket, outboxBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		// the tuples written before their counts were kept are counted once
+		if tx.Bucket(tupleCountsBucket) == nil {
+			return initTupleCounts(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("initialize bolt database: %w", err)
+	}
+
+	ds.db = db
+	return ds, nil
+}
+
+// WithMaxTuplesPerWrite returns a [StorageOption] that sets the maximum number of tuples allowed in a single write operation.
+func WithMaxTuplesPerWrite(n int) StorageOption {
+	return func(ds *Bolt) { ds.maxTuplesPerWrite = n }
+}
+
+// WithMaxTypesPerAuthorizationModel returns a [StorageOption] that sets the maximum number of types allowed per authorization model.
+func WithMaxTypesPerAuthorizationModel(n int) StorageOption {
+	return func(ds *Bolt) { ds.maxTypesPerAuthorizationModel = n }
+}
+
+// WithContinuationTokenSerializer returns a [StorageOption] that sets the token serializer of the changes.
+func WithContinuationTokenSerializer(tokenSerializer encoder.ContinuationTokenSerializer) StorageOption {
+	return func(ds *Bolt) { ds.tokenSerializer = tokenSerializer }
+}
+
+// WithOutbox returns a [StorageOption] that enables recording the changes in the outbox, see [storage.EventOutbox].
+func WithOutbox() StorageOption {
+	return func(ds *Bolt) { ds.outbox = true }
+}
+
+// Close closes the database, releasing its file.
+func (s *Bolt) Close() {
+	s.changelogBroadcaster.Close()
+	_ = s.db.Close()
+}
+
+// tupleValue is the value stored under the forward key of a tuple, serialized as JSON.
+type tupleValue struct {
+	ConditionName string `json:"condition_name,omitempty"`
+	// ConditionContext is the context of the condition, serialized as protobuf.
+	ConditionContext []byte     `json:"condition_context,omitempty"`
+	Ulid             string     `json:"ulid"`
+	InsertedAt       time.Time  `json:"inserted_at"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+}
+
+// forwardKey returns the key of a tuple in the tuples bucket of its store.
+func forwardKey(tk tupleUtils.TupleWithoutCondition) []byte {
+	return []byte(tupleUtils.TupleKeyToString(tk))
+}
+
+// forwardPrefix returns the prefix of the forward keys of the tuples which may match the filter. The tuples having
+// the prefix still have to be matched against the filter.
+func forwardPrefix(filter *openfgav1.TupleKey) []byte {
+	objectType, objectID := tupleUtils.SplitObject(filter.GetObject())
+	switch {
+	case objectType == "":
+		return nil
+	case objectID == "":
+		return []byte(objectType + ":")
+	case filter.GetRelation() == "":
+		return []byte(filter.GetObject() + "#")
+	default:
+		return []byte(filter.GetObject() + "#" + filter.GetRelation() + "@")
+	}
+}
+
+// reversePrefix returns the prefix of the reverse keys of the tuples of the user with an object of the type and the
+// relation. Users can hold any character, so the user is prefixed by its length.
+func reversePrefix(user, objectType, relation string) []byte {
+	key := binary.AppendUvarint(nil, uint64(len(user)))
+	key = append(key, user...)
+	return append(key, objectType+"#"+relation+"@"...)
+}
+
+func reverseKey(rec *storage.TupleRecord) []byte {
+	return append(reversePrefix(rec.User, rec.ObjectType, rec.Relation), rec.ObjectID...)
+}
+
+// expirationKey returns the key of a tuple in the expirations bucket of its store, ordering the tuples by expiry.
+func expirationKey(expiresAt time.Time, forwardKey []byte) []byte {
+	key := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(forwardKey)), uint64(expiresAt.UnixNano()))
+	return append(key, forwardKey...)
+}
+
+func encodeTuple(rec *storage.TupleRecord) ([]byte, error) {
+	value := tupleValue{
+		ConditionName: rec.ConditionName,
+		Ulid:          rec.Ulid,
+		InsertedAt:    rec.InsertedAt,
+		ExpiresAt:     rec.ExpiresAt,
+	}
+	if len(rec.ConditionContext.GetFields()) > 0 {
+		conditionContext, err := proto.Marshal(rec.ConditionContext)
+		if err != nil {
+			return nil, err
+		}
+		value.ConditionContext = conditionContext
+	}
+
+	return json.Marshal(value)
+}
+
+func decodeTuple(store string, key, data []byte) (*storage.TupleRecord, error) {
+	object, relationAndUser, _ := strings.Cut(string(key), "#")
+	objectType, objectID := tupleUtils.SplitObject(object)
+	relation, user, found := strings.Cut(relationAndUser, "@")
+	if !found {
+		return nil, fmt.Errorf("malformed tuple key %q", key)
+	}
+
+	var value tupleValue
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("malformed tuple %q: %w", key, err)
+	}
+
+	var conditionContext *structpb.Struct
+	if len(value.ConditionContext) > 0 {
+		conditionContext = &structpb.Struct{}
+		if err := proto.Unmarshal(value.ConditionContext, conditionContext); err != nil {
+			return nil, fmt.Errorf("malformed tuple %q: %w", key, err)
+		}
+	}
+
+	return &storage.TupleRecord{
+		Store:            store,
+		ObjectType:       objectType,
+		ObjectID:         objectID,
+		Relation:         relation,
+		User:             user,
+		ConditionName:    value.ConditionName,
+		ConditionContext: conditionContext,
+		Ulid:             value.Ulid,
+		InsertedAt:       value.InsertedAt,
+		ExpiresAt:        value.ExpiresAt,
+	}, nil
+}
+
+// match returns true if all the fields in t [*storage.TupleRecord] are equal to
+// the same field in the target [*openfgav1.TupleKey]. If the input Object
+// doesn't specify an ID, only the Object Types are compared. If a field
+// in the input parameter is empty, it is ignored in the comparison.
+func match(t *storage.TupleRecord, target *openfgav1.TupleKey) bool {
+	if target.GetObject() != "" {
+		objectType, objectID := tupleUtils.SplitObject(target.GetObject())
+		if objectType != t.ObjectType || (objectID != "" && objectID != t.ObjectID) {
+			return false
+		}
+	}
+	if target.GetRelation() != "" && t.Relation != target.GetRelation() {
+		return false
+	}
+	if target.GetUser() != "" && t.User != target.GetUser() {
+		return false
+	}
+	return true
+}
+
+// storeBucket returns the bucket of the store in the top-level bucket, or nil if there is none yet.
+func storeBucket(tx *bbolt.Tx, name []byte, store string) *bbolt.Bucket {
+	return tx.Bucket(name).Bucket([]byte(store))
+}
+
+// createStoreBucket returns the bucket of the store in the top-level bucket, creating it if needed.
+func createStoreBucket(tx *bbolt.Tx, name []byte, store string) (*bbolt.Bucket, error) {
+	return tx.Bucket(name).CreateBucketIfNotExists([]byte(store))
+}
+
+// seekAfter moves the cursor to the first key after key.
+func seekAfter(c *bbolt.Cursor, key []byte) ([]byte, []byte) {
+	k, v := c.Seek(key)
+	if bytes.Equal(k, key) {
+		return c.Next()
+	}
+	return k, v
+}
+
+// seekBefore moves the cursor to the last key before key.
+func seekBefore(c *bbolt.Cursor, key []byte) ([]byte, []byte) {
+	if k, _ := c.Seek(key); k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
+
+// readTuples returns the tuples of the store matching the filter which haven't expired, starting after the forward
+// key from when it is not empty. When limit is positive, it returns at most limit tuples, along with the key of the
+// last one if there are more.
+func readTuples(tx *bbolt.Tx, store string, filter *openfgav1.TupleKey, from []byte, limit int) ([]*storage.TupleRecord, []byte, error) {
+	b := storeBucket(tx, tuplesBucket, store)
+	if b == nil {
+		return nil, nil, nil
+	}
+
+	prefix := forwardPrefix(filter)
+	if len(from) > 0 && !bytes.HasPrefix(from, prefix) {
+		return nil, nil, storage.ErrInvalidContinuationToken
+	}
+
+	c := b.Cursor()
+	k, v := c.Seek(prefix)
+	if len(from) > 0 {
+		k, v = seekAfter(c, from)
+	}
+
+	now := time.Now()
+	var records []*storage.TupleRecord
+	var last []byte
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		rec, err := decodeTuple(store, k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !match(rec, filter) || rec.IsExpired(now) {
+			continue
+		}
+
+		if limit > 0 && len(records) == limit {
+			// there are more tuples after the page
+			return records, last, nil
+		}
+
+		records = append(records, rec)
+		last = bytes.Clone(k)
+	}
+
+	return records, nil, nil
+}
+
+func asTuples(records []*storage.TupleRecord) []*openfgav1.Tuple {
+	tuples := make([]*openfgav1.Tuple, 0, len(records))
+	for _, rec := range records {
+		tuples = append(tuples, rec.AsTuple())
+	}
+	return tuples
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (s *Bolt) Read(ctx context.Context, store string, key *openfgav1.TupleKey, _ storage.ReadOptions) (storage.TupleIterator, error) {
+	_, span := startTrace(ctx, "Read")
+	defer span.End()
+
+	var records []*storage.TupleRecord
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		records, _, err = readTuples(tx, store, key, nil, 0)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return storage.NewStaticTupleIterator(asTuples(records)), nil
+}
+
+// ReadPage see [storage.RelationshipTupleReader].ReadPage.
+func (s *Bolt) ReadPage(ctx context.Context, store string, key *openfgav1.TupleKey, options storage.ReadPageOptions) ([]*openfgav1.Tuple, []byte, error) {
+	_, span := startTrace(ctx, "ReadPage")
+	defer span.End()
+
+	var records []*storage.TupleRecord
+	var continuationToken []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		records, continuationToken, err = readTuples(tx, store, key, []byte(options.Pagination.From), options.Pagination.PageSize)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, nil, err
+	}
+
+	return asTuples(records), continuationToken, nil
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (s *Bolt) ReadUserTuple(ctx context.Context, store string, key *openfgav1.TupleKey, _ storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	_, span := startTrace(ctx, "ReadUserTuple")
+	defer span.End()
+
+	var rec *storage.TupleRecord
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, tuplesBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		k := forwardKey(key)
+		data := b.Get(k)
+		if data == nil {
+			return nil
+		}
+
+		var err error
+		rec, err = decodeTuple(store, k, data)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if rec == nil || rec.IsExpired(time.Now()) {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return nil, storage.ErrNotFound
+	}
+
+	return rec.AsTuple(), nil
+}
+
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *Bolt) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, _ storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	_, span := startTrace(ctx, "ReadTuplesBatch")
+	defer span.End()
+
+	var matches []*openfgav1.Tuple
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, tuplesBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		now := time.Now()
+		seen := make(map[string]struct{}, len(lookups))
+		add := func(rec *storage.TupleRecord) {
+			key := tupleUtils.TupleKeyToString(rec.AsTuple().GetKey())
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				matches = append(matches, rec.AsTuple())
+			}
+		}
+
+		for _, lookup := range lookups {
+			if lookup.User == "" {
+				records, _, err := readTuples(tx, store, &openfgav1.TupleKey{Object: lookup.Object, Relation: lookup.Relation}, nil, 0)
+				if err != nil {
+					return err
+				}
+				for _, rec := range records {
+					if tupleUtils.GetUserTypeFromUser(rec.User) == tupleUtils.UserSet {
+						add(rec)
+					}
+				}
+				continue
+			}
+
+			k := forwardKey(tupleUtils.NewTupleKey(lookup.Object, lookup.Relation, lookup.User))
+			data := b.Get(k)
+			if data == nil {
+				continue
+			}
+			rec, err := decodeTuple(store, k, data)
+			if err != nil {
+				return err
+			}
+			if !rec.IsExpired(now) {
+				add(rec)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (s *Bolt) ReadUsersetTuples(
+	ctx context.Context,
+	store string,
+	filter storage.ReadUsersetTuplesFilter,
+	_ storage.ReadUsersetTuplesOptions,
+) (storage.TupleIterator, error) {
+	_, span := startTrace(ctx, "ReadUsersetTuples")
+	defer span.End()
+
+	var records []*storage.TupleRecord
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		records, _, err = readTuples(tx, store, &openfgav1.TupleKey{Object: filter.Object, Relation: filter.Relation}, nil, 0)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	var matches []*openfgav1.Tuple
+	for _, rec := range records {
+		if tupleUtils.GetUserTypeFromUser(rec.User) != tupleUtils.UserSet {
+			continue
+		}
+
+		if len(filter.AllowedUserTypeRestrictions) == 0 { // 1.0 model.
+			matches = append(matches, rec.AsTuple())
+			continue
+		}
+
+		// 1.1 model: see if the tuple found is of an allowed type.
+		userType := tupleUtils.GetType(rec.User)
+		_, userRelation := tupleUtils.SplitObjectRelation(rec.User)
+		for _, allowedType := range filter.AllowedUserTypeRestrictions {
+			if allowedType.GetType() == userType && allowedType.GetRelation() == userRelation {
+				matches = append(matches, rec.AsTuple())
+				break
+			}
+		}
+	}
+
+	return storage.NewStaticTupleIterator(matches), nil
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (s *Bolt) ReadStartingWithUser(
+	ctx context.Context,
+	store string,
+	filter storage.ReadStartingWithUserFilter,
+	_ storage.ReadStartingWithUserOptions,
+) (storage.TupleIterator, error) {
+	_, span := startTrace(ctx, "ReadStartingWithUser")
+	defer span.End()
+
+	var matches []*openfgav1.Tuple
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		users := storeBucket(tx, usersBucket, store)
+		tuples := storeBucket(tx, tuplesBucket, store)
+		if users == nil || tuples == nil {
+			return nil
+		}
+
+		now := time.Now()
+		for _, userFilter := range filter.UserFilter {
+			prefix := reversePrefix(tupleUtils.GetObjectRelationAsString(userFilter), filter.ObjectType, filter.Relation)
+
+			c := users.Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				objectID := string(k[len(prefix):])
+				if filter.ObjectIDs != nil && !filter.ObjectIDs.Exists(objectID) {
+					continue
+				}
+
+				key := forwardKey(tupleUtils.NewTupleKey(
+					tupleUtils.BuildObject(filter.ObjectType, objectID),
+					filter.Relation,
+					tupleUtils.GetObjectRelationAsString(userFilter),
+				))
+				data := tuples.Get(key)
+				if data == nil {
+					return fmt.Errorf("tuple %q is indexed but missing", key)
+				}
+
+				rec, err := decodeTuple(store, key, data)
+				if err != nil {
+					return err
+				}
+				if !rec.IsExpired(now) {
+					matches = append(matches, rec.AsTuple())
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return storage.NewStaticTupleIterator(matches), nil
+}
+
+// initTupleCounts creates the bucket of the tuple counts, and counts the tuples of each store.
+func initTupleCounts(tx *bbolt.Tx) error {
+	counts, err := tx.CreateBucket(tupleCountsBucket)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(tuplesBucket).ForEachBucket(func(store []byte) error {
+		var n uint64
+		c := tx.Bucket(tuplesBucket).Bucket(store).Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			n++
+		}
+		if n == 0 {
+			return nil
+		}
+		return counts.Put(bytes.Clone(store), binary.BigEndian.AppendUint64(nil, n))
+	})
+}
+
+// tupleWriter applies the changes to the tuples of a store within a write transaction, maintaining the indexes and
+// the tuple count, and recording the changes in the changelog.
+type tupleWriter struct {
+	store       string
+	tuples      *bbolt.Bucket
+	users       *bbolt.Bucket
+	changelog   *bbolt.Bucket
+	expirations *bbolt.Bucket
+	counts      *bbolt.Bucket
+	now         time.Time
+	entropy     io.Reader
+	// lastChange is the ULID of the last change appended to the changelog, if any.
+	lastChange ulid.ULID
+}
+
+func newTupleWriter(tx *bbolt.Tx, store string, now time.Time) (*tupleWriter, error) {
+	tuples, err := createStoreBucket(tx, tuplesBucket, store)
+	if err != nil {
+		return nil, err
+	}
+	users, err := createStoreBucket(tx, usersBucket, store)
+	if err != nil {
+		return nil, err
+	}
+	changelog, err := createStoreBucket(tx, changelogBucket, store)
+	if err != nil {
+		return nil, err
+	}
+	expirations, err := createStoreBucket(tx, expirationsBucket, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tupleWriter{
+		store:       store,
+		tuples:      tuples,
+		users:       users,
+		changelog:   changelog,
+		expirations: expirations,
+		counts:      tx.Bucket(tupleCountsBucket),
+		now:         now,
+		entropy:     ulid.DefaultEntropy(),
+	}, nil
+}
+
+// addCount adds delta to the tuple count of the store.
+func (w *tupleWriter) addCount(delta int64) error {
+	var n uint64
+	if data := w.counts.Get([]byte(w.store)); data != nil {
+		n = binary.BigEndian.Uint64(data)
+	}
+	n = uint64(int64(n) + delta)
+	if n == 0 {
+		return w.counts.Delete([]byte(w.store))
+	}
+	return w.counts.Put([]byte(w.store), binary.BigEndian.AppendUint64(nil, n))
+}
+
+// get returns the record of the tuple, expired or not, or nil if there is none.
+func (w *tupleWriter) get(tk tupleUtils.TupleWithoutCondition) (*storage.TupleRecord, error) {
+	key := forwardKey(tk)
+	data := w.tuples.Get(key)
+	if data == nil {
+		return nil, nil
+	}
+	return decodeTuple(w.store, key, data)
+}
+
+func (w *tupleWriter) appendChange(tk *openfgav1.TupleKey, operation openfgav1.TupleOperation) error {
+	data, err := proto.Marshal(&openfgav1.TupleChange{
+		TupleKey:  tk,
+		Operation: operation,
+		Timestamp: timestamppb.New(w.now),
+	})
+	if err != nil {
+		return err
+	}
+
+	id := ulid.MustNew(ulid.Timestamp(w.now), w.entropy)
+	w.lastChange = id
+	return w.changelog.Put(id[:], data)
+}
+
+// putChange puts the change in the changelog as it is, under its own ULID.
+func (w *tupleWriter) putChange(change *storage.ChangeRecord) error {
+	id, err := ulid.Parse(change.Ulid)
+	if err != nil {
+		return fmt.Errorf("invalid change ULID '%s': %w", change.Ulid, err)
+	}
+
+	data, err := proto.Marshal(&openfgav1.TupleChange{
+		TupleKey:  change.TupleKey,
+		Operation: change.Operation,
+		Timestamp: timestamppb.New(change.InsertedAt),
+	})
+	if err != nil {
+		return err
+	}
+	return w.changelog.Put(id[:], data)
+}
+
+func (w *tupleWriter) delete(rec *storage.TupleRecord) error {
+	if err := w.remove(rec); err != nil {
+		return err
+	}
+
+	// Redact the condition info.
+	object := tupleUtils.BuildObject(rec.ObjectType, rec.ObjectID)
+	return w.appendChange(tupleUtils.NewTupleKey(object, rec.Relation, rec.User), openfgav1.TupleOperation_TUPLE_OPERATION_DELETE)
+}
+
+// remove removes the tuple and its indexes, without recording its delete.
+func (w *tupleWriter) remove(rec *storage.TupleRecord) error {
+	object := tupleUtils.BuildObject(rec.ObjectType, rec.ObjectID)
+	key := forwardKey(tupleUtils.NewTupleKey(object, rec.Relation, rec.User))
+	if err := w.tuples.Delete(key); err != nil {
+		return err
+	}
+	if err := w.users.Delete(reverseKey(rec)); err != nil {
+		return err
+	}
+	if rec.ExpiresAt != nil {
+		if err := w.expirations.Delete(expirationKey(*rec.ExpiresAt, key)); err != nil {
+			return err
+		}
+	}
+	return w.addCount(-1)
+}
+
+func (w *tupleWriter) write(tk *openfgav1.TupleKey, expiresAt *time.Time) error {
+	objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+	rec := &storage.TupleRecord{
+		Store:            w.store,
+		ObjectType:       objectType,
+		ObjectID:         objectID,
+		Relation:         tk.GetRelation(),
+		User:             tk.GetUser(),
+		ConditionName:    tk.GetCondition().GetName(),
+		ConditionContext: tk.GetCondition().GetContext(),
+		Ulid:             ulid.MustNew(ulid.Timestamp(w.now), w.entropy).String(),
+		InsertedAt:       w.now,
+		ExpiresAt:        expiresAt,
+	}
+
+	if err := w.put(rec); err != nil {
+		return err
+	}
+	return w.appendChange(rec.AsTuple().GetKey(), openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+}
+
+// put puts the tuple and its indexes, without recording its write. The store must have no tuple with the same key.
+func (w *tupleWriter) put(rec *storage.TupleRecord) error {
+	data, err := encodeTuple(rec)
+	if err != nil {
+		return err
+	}
+
+	key := forwardKey(rec.AsTuple().GetKey())
+	if err := w.tuples.Put(key, data); err != nil {
+		return err
+	}
+	if err := w.users.Put(reverseKey(rec), []byte{}); err != nil {
+		return err
+	}
+	if rec.ExpiresAt != nil {
+		if err := w.expirations.Put(expirationKey(*rec.ExpiresAt, key), []byte{}); err != nil {
+			return err
+		}
+	}
+	return w.addCount(1)
+}
+
+// Write see [storage.RelationshipTupleWriter].Write.
+func (s *Bolt) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
+	_, span := startTrace(ctx, "Write")
+	defer span.End()
+
+	options := storage.NewTupleWriteOptions(opts...)
+
+	var expiresAt *time.Time
+	if !options.ExpiresAt.IsZero() {
+		expiresAt = &options.ExpiresAt
+	}
+
+	var revision ulid.ULID
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		// the time is taken once the write transactions before this one committed, so that the changes are
+		// ordered by their ULIDs as they are committed
+		now := time.Now().UTC()
+		w, err := newTupleWriter(tx, store, now)
+		if err != nil {
+			return err
+		}
+
+		// expired tuples are deleted first, so that they can be written again
+		var changes []storage.EventTupleChange
+		keys := make([]tupleUtils.TupleWithoutCondition, 0, len(options.Preconditions)+len(deletes)+len(writes))
+		for _, precondition := range options.Preconditions {
+			keys = append(keys, precondition.TupleKey)
+		}
+		for _, tk := range deletes {
+			keys = append(keys, tk)
+		}
+		for _, tk := range writes {
+			keys = append(keys, tk)
+		}
+		for _, tk := range keys {
+			rec, err := w.get(tk)
+			if err != nil {
+				return err
+			}
+			if rec != nil && rec.IsExpired(now) {
+				if err := w.delete(rec); err != nil {
+					return err
+				}
+				changes = append(changes, storage.NewTupleDeleteChange(rec.AsTuple().GetKey()))
+			}
+		}
+
+		deletesToApply, writesToApply, err := w.validate(deletes, writes, options)
+		if err != nil {
+			return err
+		}
+
+		for _, rec := range deletesToApply {
+			if err := w.delete(rec); err != nil {
+				return err
+			}
+			changes = append(changes, storage.NewTupleDeleteChange(rec.AsTuple().GetKey()))
+		}
+		for _, tk := range writesToApply {
+			if err := w.write(tk, expiresAt); err != nil {
+				return err
+			}
+			changes = append(changes, storage.NewTupleWriteChange(tk))
+		}
+		revision = w.lastChange
+		return s.putOutboxEvent(tx, storage.NewTuplesWrittenEvent(store, changes))
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	if revision != (ulid.ULID{}) {
+		options.SetCommittedRevision(revision)
+	}
+	s.changelogBroadcaster.Notify(store)
+	return nil
+}
+
+// validate checks the preconditions, and returns the records to delete and the tuples to write once the ones to
+// ignore are removed.
+func (w *tupleWriter) validate(
+	deletes []*openfgav1.TupleKeyWithoutCondition,
+	writes []*openfgav1.TupleKey,
+	options storage.TupleWriteOptions,
+) ([]*storage.TupleRecord, []*openfgav1.TupleKey, error) {
+	for _, precondition := range options.Preconditions {
+		rec, err := w.get(precondition.TupleKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if (rec != nil) != precondition.Exists {
+			return nil, nil, storage.PreconditionFailedError(precondition)
+		}
+	}
+
+	var deletesToApply []*storage.TupleRecord
+	for _, tk := range deletes {
+		rec, err := w.get(tk)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rec == nil {
+			if options.OnMissingDelete == storage.OnMissingDeleteIgnore {
+				continue
+			}
+			return nil, nil, storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE)
+		}
+		deletesToApply = append(deletesToApply, rec)
+	}
+
+	var writesToApply []*openfgav1.TupleKey
+	for _, tk := range writes {
+		rec, err := w.get(tk)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rec != nil {
+			if options.OnDuplicateInsert != storage.OnDuplicateInsertIgnore {
+				return nil, nil, storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+			}
+			if !rec.HasCondition(tk.GetCondition()) {
+				return nil, nil, storage.DuplicateWithDifferentConditionError(tk)
+			}
+			continue
+		}
+		writesToApply = append(writesToApply, tk)
+	}
+	return deletesToApply, writesToApply, nil
+}
+
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples.
+func (s *Bolt) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	_, span := startTrace(ctx, "DeleteExpiredTuples")
+	defer span.End()
+
+	deleted := 0
+	var modifiedStores []string
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		// the buckets are collected first, as they can't be modified while they are iterated over
+		var stores []string
+		err := tx.Bucket(expirationsBucket).ForEachBucket(func(store []byte) error {
+			stores = append(stores, string(store))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, store := range stores {
+			if deleted == limit {
+				break
+			}
+
+			changes, err := deleteExpiredTuples(tx, store, now, limit-deleted)
+			if err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				modifiedStores = append(modifiedStores, store)
+			}
+			deleted += len(changes)
+
+			if err := s.putOutboxEvent(tx, storage.NewTuplesExpiredEvent(store, changes)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+
+	for _, store := range modifiedStores {
+		s.changelogBroadcaster.Notify(store)
+	}
+	return deleted, nil
+}
+
+// deleteExpiredTuples deletes up to limit tuples of the store which expired at or before now, or all of them if
+// limit is negative, and records their deletes. It returns the changes of the tuples deleted, for the outbox.
+func deleteExpiredTuples(tx *bbolt.Tx, store string, now time.Time, limit int) ([]storage.EventTupleChange, error) {
+	w, err := newTupleWriter(tx, store, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	end := binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano()))
+	var expired [][]byte
+	c := w.expirations.Cursor()
+	for k, _ := c.First(); k != nil && len(expired) != limit && bytes.Compare(k[:8], end) <= 0; k, _ = c.Next() {
+		expired = append(expired, bytes.Clone(k))
+	}
+
+	changes := make([]storage.EventTupleChange, 0, len(expired))
+	for _, k := range expired {
+		data := w.tuples.Get(k[8:])
+		if data == nil {
+			return nil, fmt.Errorf("expiring tuple %q is missing", k[8:])
+		}
+
+		rec, err := decodeTuple(store, k[8:], data)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.delete(rec); err != nil {
+			return nil, err
+		}
+		changes = append(changes, storage.NewTupleDeleteChange(rec.AsTuple().GetKey()))
+	}
+
+	return changes, nil
+}
+
+// WatchChangelog see [storage.ChangelogWatcher].WatchChangelog.
+func (s *Bolt) WatchChangelog(ctx context.Context, store string) (<-chan struct{}, error) {
+	return s.changelogBroadcaster.Watch(ctx, store), nil
+}
+
+// ReadChanges see [storage.ChangelogBackend].ReadChanges.
+func (s *Bolt) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, []byte, error) {
+	_, span := startTrace(ctx, "ReadChanges")
+	defer span.End()
+
+	objectType := filter.ObjectType
+
+	var from []byte
+	if options.Pagination.From != "" {
+		concreteToken, typeInToken, err := s.tokenSerializer.Deserialize(options.Pagination.From)
+		if err != nil {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+		parsed, err := ulid.Parse(concreteToken)
+		if err != nil {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+		if typeInToken != "" && typeInToken != objectType {
+			return nil, nil, storage.ErrMismatchObjectType
+		}
+		from = parsed[:]
+	}
+
+	pageSize := storage.DefaultPageSize
+	if options.Pagination.PageSize > 0 {
+		pageSize = options.Pagination.PageSize
+	}
+
+	var changes []*openfgav1.TupleChange
+	var last ulid.ULID
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if horizon := tx.Bucket(horizonsBucket).Get([]byte(store)); from != nil && bytes.Compare(from, horizon) < 0 {
+			return storage.ErrContinuationTokenExpired
+		}
+
+		b := storeBucket(tx, changelogBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		var k, v []byte
+		next := c.Next
+		switch {
+		case options.SortDesc && from != nil:
+			k, v = seekBefore(c, from)
+		case options.SortDesc:
+			k, v = c.Last()
+		case from != nil:
+			k, v = seekAfter(c, from)
+		default:
+			k, v = c.First()
+		}
+		if options.SortDesc {
+			next = c.Prev
+		}
+
+		horizon := time.Now().UTC().Add(-filter.HorizonOffset)
+		for ; k != nil && len(changes) < pageSize; k, v = next() {
+			change := &openfgav1.TupleChange{}
+			if err := proto.Unmarshal(v, change); err != nil {
+				return fmt.Errorf("malformed change %x: %w", k, err)
+			}
+
+			if change.GetTimestamp().AsTime().After(horizon) {
+				if options.SortDesc {
+					continue
+				}
+				break
+			}
+			if objectType != "" && !strings.HasPrefix(change.GetTupleKey().GetObject(), objectType+":") {
+				continue
+			}
+
+			changes = append(changes, change)
+			copy(last[:], k)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, nil, err
+	}
+
+	if len(changes) == 0 {
+		return nil, nil, storage.ErrNotFound
+	}
+
+	continuationToken, err := s.tokenSerializer.Serialize(last.String(), objectType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return changes, continuationToken, nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Bolt) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	_, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	var deleted int
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, changelogBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		var cutoff []byte
+		if !olderThan.IsZero() {
+			id := storage.ChangesBefore(olderThan)
+			cutoff = id[:]
+		}
+
+		c := b.Cursor()
+		if keepCount > 0 {
+			k, _ := c.Last()
+			for i := 1; k != nil && i < keepCount; i++ {
+				k, _ = c.Prev()
+			}
+			if k != nil && bytes.Compare(k, cutoff) > 0 {
+				cutoff = bytes.Clone(k)
+			}
+		}
+
+		var ulids [][]byte
+		for k, _ := c.First(); k != nil && len(ulids) < limit && bytes.Compare(k, cutoff) < 0; k, _ = c.Next() {
+			ulids = append(ulids, bytes.Clone(k))
+		}
+
+		deleted = len(ulids)
+		return deleteChanges(tx, b, store, ulids)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (s *Bolt) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	_, span := startTrace(ctx, "CompactChanges")
+	defer span.End()
+
+	if olderThan.IsZero() {
+		return 0, nil
+	}
+
+	var compacted int
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, changelogBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		cutoff := storage.ChangesBefore(olderThan)
+		collapsible := storage.NewCollapsibleChanges()
+		c := b.Cursor()
+		for k, v := c.First(); k != nil && collapsible.Len() < limit && bytes.Compare(k, cutoff[:]) < 0; k, v = c.Next() {
+			change := &openfgav1.TupleChange{}
+			if err := proto.Unmarshal(v, change); err != nil {
+				return fmt.Errorf("malformed change %x: %w", k, err)
+			}
+
+			var id ulid.ULID
+			copy(id[:], k)
+			collapsible.Add(id.String(), change.GetTupleKey(), change.GetOperation())
+		}
+
+		collapsed, _ := collapsible.ULIDs(limit)
+		ulids := make([][]byte, 0, len(collapsed))
+		for _, id := range collapsed {
+			parsed := ulid.MustParse(id)
+			ulids = append(ulids, parsed[:])
+		}
+
+		compacted = len(ulids) / 2
+		return deleteChanges(tx, b, store, ulids)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+
+	return compacted, nil
+}
+
+// deleteChanges deletes the changes from the changelog bucket of the store, and moves its horizon past them.
+func deleteChanges(tx *bbolt.Tx, changelog *bbolt.Bucket, store string, ulids [][]byte) error {
+	horizons := tx.Bucket(horizonsBucket)
+	horizon := horizons.Get([]byte(store))
+	for _, id := range ulids {
+		if err := changelog.Delete(id); err != nil {
+			return err
+		}
+		if bytes.Compare(id, horizon) > 0 {
+			horizon = id
+		}
+	}
+
+	if len(ulids) == 0 {
+		return nil
+	}
+	return horizons.Put([]byte(store), bytes.Clone(horizon))
+}
+
+// ReadAuthorizationModel see [storage.AuthorizationModelReadBackend].ReadAuthorizationModel.
+func (s *Bolt) ReadAuthorizationModel(ctx context.Context, store string, id string) (*openfgav1.AuthorizationModel, error) {
+	_, span := startTrace(ctx, "ReadAuthorizationModel")
+	defer span.End()
+
+	var model *openfgav1.AuthorizationModel
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, modelsBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		data := b.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		model = &openfgav1.AuthorizationModel{}
+		return proto.Unmarshal(data, model)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if len(model.GetTypeDefinitions()) == 0 {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return nil, storage.ErrNotFound
+	}
+
+	return model, nil
+}
+
+// ReadAuthorizationModels see [storage.AuthorizationModelReadBackend].ReadAuthorizationModels.
+func (s *Bolt) ReadAuthorizationModels(ctx context.Context, store string, options storage.ReadAuthorizationModelsOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
+	_, span := startTrace(ctx, "ReadAuthorizationModels")
+	defer span.End()
+
+	pageSize := storage.DefaultPageSize
+	if options.Pagination.PageSize > 0 {
+		pageSize = options.Pagination.PageSize
+	}
+
+	var models []*openfgav1.AuthorizationModel
+	var continuationToken []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, modelsBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		// From newest to oldest.
+		c := b.Cursor()
+		k, v := c.Last()
+		if options.Pagination.From != "" {
+			k, v = seekBefore(c, []byte(options.Pagination.From))
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			if len(models) == pageSize {
+				continuationToken = []byte(models[len(models)-1].GetId())
+				return nil
+			}
+
+			model := &openfgav1.AuthorizationModel{}
+			if err := proto.Unmarshal(v, model); err != nil {
+				return err
+			}
+			models = append(models, model)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, nil, err
+	}
+
+	return models, continuationToken, nil
+}
+
+// FindLatestAuthorizationModel see [storage.AuthorizationModelReadBackend].FindLatestAuthorizationModel.
+func (s *Bolt) FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error) {
+	_, span := startTrace(ctx, "FindLatestAuthorizationModel")
+	defer span.End()
+
+	var model *openfgav1.AuthorizationModel
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, modelsBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		// the model IDs are ULIDs, so the latest model is the last one
+		_, data := b.Cursor().Last()
+		if data == nil {
+			return nil
+		}
+
+		model = &openfgav1.AuthorizationModel{}
+		return proto.Unmarshal(data, model)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if model == nil {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return nil, storage.ErrNotFound
+	}
+
+	return model, nil
+}
+
+// MaxTypesPerAuthorizationModel see [storage.TypeDefinitionWriteBackend].MaxTypesPerAuthorizationModel.
+func (s *Bolt) MaxTypesPerAuthorizationModel() int {
+	return s.maxTypesPerAuthorizationModel
+}
+
+// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
+func (s *Bolt) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
+	_, span := startTrace(ctx, "WriteAuthorizationModel")
+	defer span.End()
+
+	data, err := proto.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createStoreBucket(tx, modelsBucket, store)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(model.GetId()), data); err != nil {
+			return err
+		}
+		return s.putOutboxEvent(tx, storage.NewAuthorizationModelWrittenEvent(store, model.GetId()))
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// CreateStore see [storage.StoresBackend].CreateStore.
+func (s *Bolt) CreateStore(ctx context.Context, newStore *openfgav1.Store) (*openfgav1.Store, error) {
+	_, span := startTrace(ctx, "CreateStore")
+	defer span.End()
+
+	store, err := s.createStore(newStore, nil)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return store, nil
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels.
+func (s *Bolt) CreateStoreWithLabels(ctx context.Context, newStore *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	_, span := startTrace(ctx, "CreateStoreWithLabels")
+	defer span.End()
+
+	store, err := s.createStore(newStore, labels)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Bolt) createStore(newStore *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	now := timestamppb.New(time.Now().UTC())
+	store := &openfgav1.Store{
+		Id:        newStore.GetId(),
+		Name:      newStore.GetName(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	data, err := proto.Marshal(store)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(storesBucket)
+		if b.Get([]byte(store.GetId())) != nil {
+			return storage.ErrCollision
+		}
+		if err := b.Put([]byte(store.GetId()), data); err != nil {
+			return err
+		}
+		if err := putStoreLabels(tx, store.GetId(), labels); err != nil {
+			return err
+		}
+		return s.putOutboxEvent(tx, storage.NewStoreCreatedEvent(store))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (s *Bolt) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	_, span := startTrace(ctx, "UpdateStore")
+	defer span.End()
+
+	var store *openfgav1.Store
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		store, err = getStore(tx, id)
+		if err != nil {
+			return err
+		}
+		if store == nil || store.GetDeletedAt() != nil {
+			return storage.ErrNotFound
+		}
+
+		if name != "" {
+			store.Name = name
+		}
+		store.UpdatedAt = timestamppb.New(time.Now().UTC())
+		data, err := proto.Marshal(store)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(storesBucket).Put([]byte(id), data); err != nil {
+			return err
+		}
+
+		if labels == nil {
+			return nil
+		}
+		return putStoreLabels(tx, id, labels)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (s *Bolt) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	_, span := startTrace(ctx, "ReadStoreLabels")
+	defer span.End()
+
+	var labels map[string]string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		store, err := getStore(tx, id)
+		if err != nil {
+			return err
+		}
+		if store == nil || store.GetDeletedAt() != nil {
+			return storage.ErrNotFound
+		}
+
+		labels, err = getStoreLabels(tx, id)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// getStoreLabels returns the labels of the store, which are empty if it has none.
+func getStoreLabels(tx *bbolt.Tx, id string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if data := tx.Bucket(storeLabelsBucket).Get([]byte(id)); data != nil {
+		if err := json.Unmarshal(data, &labels); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+// putStoreLabels replaces the labels of the store.
+func putStoreLabels(tx *bbolt.Tx, id string, labels map[string]string) error {
+	b := tx.Bucket(storeLabelsBucket)
+	if len(labels) == 0 {
+		return b.Delete([]byte(id))
+	}
+
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(id), data)
+}
+
+// getStore returns the store, deleted or not, or nil if there is none.
+func getStore(tx *bbolt.Tx, id string) (*openfgav1.Store, error) {
+	data := tx.Bucket(storesBucket).Get([]byte(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	store := &openfgav1.Store{}
+	if err := proto.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetStore see [storage.StoresBackend].GetStore.
+func (s *Bolt) GetStore(ctx context.Context, id string) (*openfgav1.Store, error) {
+	_, span := startTrace(ctx, "GetStore")
+	defer span.End()
+
+	var store *openfgav1.Store
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		store, err = getStore(tx, id)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if store == nil || store.GetDeletedAt() != nil {
+		return nil, storage.ErrNotFound
+	}
+
+	return store, nil
+}
+
+// ListStores see [storage.StoresBackend].ListStores.
+func (s *Bolt) ListStores(ctx context.Context, options storage.ListStoresOptions) ([]*openfgav1.Store, []byte, error) {
+	_, span := startTrace(ctx, "ListStores")
+	defer span.End()
+
+	pageSize := storage.DefaultPageSize
+	if options.Pagination.PageSize > 0 {
+		pageSize = options.Pagination.PageSize
+	}
+
+	ids := make(map[string]struct{}, len(options.IDs))
+	for _, id := range options.IDs {
+		ids[id] = struct{}{}
+	}
+
+	var stores []*openfgav1.Store
+	var continuationToken []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		// From oldest to newest.
+		c := tx.Bucket(storesBucket).Cursor()
+		k, v := c.First()
+		if options.Pagination.From != "" {
+			k, v = seekAfter(c, []byte(options.Pagination.From))
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if _, ok := ids[string(k)]; len(ids) > 0 && !ok {
+				continue
+			}
+
+			store := &openfgav1.Store{}
+			if err := proto.Unmarshal(v, store); err != nil {
+				return err
+			}
+			if store.GetDeletedAt() != nil || !strings.HasPrefix(store.GetName(), options.NamePrefix) {
+				continue
+			}
+			if len(options.LabelSelector) > 0 {
+				labels, err := getStoreLabels(tx, store.GetId())
+				if err != nil {
+					return err
+				}
+				if !options.LabelSelector.Matches(labels) {
+					continue
+				}
+			}
+
+			if len(stores) == pageSize {
+				continuationToken = []byte(stores[len(stores)-1].GetId())
+				return nil
+			}
+			stores = append(stores, store)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, nil, err
+	}
+
+	return stores, continuationToken, nil
+}
+
+// DeleteStore see [storage.StoresBackend].DeleteStore. The store is soft-deleted: its data is kept until it is
+// purged (see PurgeStore).
+func (s *Bolt) DeleteStore(ctx context.Context, id string) error {
+	_, span := startTrace(ctx, "DeleteStore")
+	defer span.End()
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		store, err := getStore(tx, id)
+		if err != nil || store == nil || store.GetDeletedAt() != nil {
+			return err
+		}
+
+		store.DeletedAt = timestamppb.New(time.Now().UTC())
+		data, err := proto.Marshal(store)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(storesBucket).Put([]byte(id), data); err != nil {
+			return err
+		}
+		return s.putOutboxEvent(tx, storage.NewStoreDeletedEvent(id))
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (s *Bolt) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	_, span := startTrace(ctx, "UndeleteStore")
+	defer span.End()
+
+	var store *openfgav1.Store
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		store, err = getStore(tx, id)
+		if err != nil {
+			return err
+		}
+		if store == nil || (store.GetDeletedAt() != nil && store.GetDeletedAt().AsTime().Before(deletedAfter)) {
+			return storage.ErrNotFound
+		}
+		if store.GetDeletedAt() == nil {
+			return nil
+		}
+
+		store.DeletedAt = nil
+		store.UpdatedAt = timestamppb.New(time.Now().UTC())
+		data, err := proto.Marshal(store)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(storesBucket).Put([]byte(id), data)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores.
+func (s *Bolt) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	_, span := startTrace(ctx, "ListDeletedStores")
+	defer span.End()
+
+	var deleted []*openfgav1.Store
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(storesBucket).ForEach(func(_, data []byte) error {
+			store := &openfgav1.Store{}
+			if err := proto.Unmarshal(data, store); err != nil {
+				return err
+			}
+			if store.GetDeletedAt() != nil && store.GetDeletedAt().AsTime().Before(deletedBefore) {
+				deleted = append(deleted, store)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].GetDeletedAt().AsTime().Before(deleted[j].GetDeletedAt().AsTime())
+	})
+
+	ids := make([]string, 0, min(len(deleted), limit))
+	for _, store := range deleted[:min(len(deleted), limit)] {
+		ids = append(ids, store.GetId())
+	}
+	return ids, nil
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore. The entries of the indexes of the tuples count as rows.
+func (s *Bolt) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	_, span := startTrace(ctx, "PurgeStore")
+	defer span.End()
+
+	deleted := 0
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		store, err := getStore(tx, id)
+		if err != nil || store.GetDeletedAt() == nil || !store.GetDeletedAt().AsTime().Before(deletedBefore) {
+			return err
+		}
+
+		buckets := [][]byte{tuplesBucket, usersBucket, expirationsBucket, changelogBucket, modelsBucket, assertionsBucket}
+		for _, name := range buckets {
+			b := storeBucket(tx, name, id)
+			if b == nil {
+				continue
+			}
+
+			var keys [][]byte
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil && deleted+len(keys) < limit; k, _ = c.Next() {
+				keys = append(keys, k)
+			}
+			for _, k := range keys {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			deleted += len(keys)
+		}
+
+		if deleted == limit {
+			return nil
+		}
+
+		// no entries are left: the store itself is deleted last
+		for _, name := range buckets {
+			if storeBucket(tx, name, id) != nil {
+				if err := tx.Bucket(name).DeleteBucket([]byte(id)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Bucket(horizonsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(storeLabelsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(tupleCountsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(storesBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
+func (s *Bolt) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
+	_, span := startTrace(ctx, "WriteAssertions")
+	defer span.End()
+
+	data, err := proto.Marshal(&openfgav1.Assertions{Assertions: assertions})
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createStoreBucket(tx, assertionsBucket, store)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(modelID), data)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// ReadAssertions see [storage.AssertionsBackend].ReadAssertions.
+func (s *Bolt) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error) {
+	_, span := startTrace(ctx, "ReadAssertions")
+	defer span.End()
+
+	assertions := &openfgav1.Assertions{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, assertionsBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		data := b.Get([]byte(modelID))
+		if data == nil {
+			return nil
+		}
+		return proto.Unmarshal(data, assertions)
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	if assertions.GetAssertions() == nil {
+		return []*openfgav1.Assertion{}, nil
+	}
+	return assertions.GetAssertions(), nil
+}
+
+// ImportTuples see [storage.TupleImporter].ImportTuples.
+func (s *Bolt) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ctx, span := startTrace(ctx, "ImportTuples")
+	defer span.End()
+
+	if len(writes) > s.MaxTuplesPerImport() {
+		return storage.ErrExceededWriteBatchLimit
+	}
+
+	return s.Write(ctx, store, nil, writes)
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples.
+func (s *Bolt) CountTuples(ctx context.Context, store string) (int64, error) {
+	_, span := startTrace(ctx, "CountTuples")
+	defer span.End()
+
+	var n int64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if data := tx.Bucket(tupleCountsBucket).Get([]byte(store)); data != nil {
+			n = int64(binary.BigEndian.Uint64(data))
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords. The tuples are ordered by their forward key, which
+// is the continuation token of the next page and orders them as [storage.CompareTupleRecords] unless their object
+// types, IDs or relations are prefixes of one another.
+func (s *Bolt) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	_, span := startTrace(ctx, "ReadTupleRecords")
+	defer span.End()
+
+	var records []*storage.TupleRecord
+	var continuationToken []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, tuplesBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		k, v := c.First()
+		if options.From != "" {
+			k, v = seekAfter(c, []byte(options.From))
+		}
+		var last []byte
+		for ; k != nil; k, v = c.Next() {
+			if options.PageSize > 0 && len(records) == options.PageSize {
+				// there are more tuples after the page
+				continuationToken = last
+				return nil
+			}
+
+			rec, err := decodeTuple(store, k, v)
+			if err != nil {
+				return err
+			}
+			records = append(records, rec)
+			last = bytes.Clone(k)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, nil, err
+	}
+	return records, continuationToken, nil
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (s *Bolt) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	_, span := startTrace(ctx, "ReadTupleRecord")
+	defer span.End()
+
+	var rec *storage.TupleRecord
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, tuplesBucket, store)
+		if b == nil {
+			return storage.ErrNotFound
+		}
+
+		k := forwardKey(key)
+		data := b.Get(k)
+		if data == nil {
+			return storage.ErrNotFound
+		}
+
+		var err error
+		rec, err = decodeTuple(store, k, data)
+		return err
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (s *Bolt) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	_, span := startTrace(ctx, "ReadChangeRecords")
+	defer span.End()
+
+	var from []byte
+	if after != "" {
+		id, err := ulid.Parse(after)
+		if err != nil {
+			return nil, storage.ErrInvalidContinuationToken
+		}
+		from = id[:]
+	}
+
+	var records []*storage.ChangeRecord
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := storeBucket(tx, changelogBucket, store)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		k, v := c.First()
+		if from != nil {
+			k, v = seekAfter(c, from)
+		}
+		for ; k != nil && (limit <= 0 || len(records) < limit); k, v = c.Next() {
+			change := &openfgav1.TupleChange{}
+			if err := proto.Unmarshal(v, change); err != nil {
+				return fmt.Errorf("malformed change %x: %w", k, err)
+			}
+
+			var id ulid.ULID
+			copy(id[:], k)
+			records = append(records, &storage.ChangeRecord{
+				Ulid:       id.String(),
+				Operation:  change.GetOperation(),
+				TupleKey:   change.GetTupleKey(),
+				InsertedAt: change.GetTimestamp().AsTime(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return records, nil
+}
+
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (s *Bolt) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	_, span := startTrace(ctx, "ImportRecords")
+	defer span.End()
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		w, err := newTupleWriter(tx, store, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		for _, change := range records.Changes {
+			if err := w.putChange(change); err != nil {
+				return err
+			}
+		}
+
+		for _, tk := range records.Deletes {
+			existing, err := w.get(tk)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				continue
+			}
+			if err := w.remove(existing); err != nil {
+				return err
+			}
+		}
+
+		for _, record := range records.Tuples {
+			tk := record.AsTuple().GetKey()
+			existing, err := w.get(tk)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				if err := w.remove(existing); err != nil {
+					return err
+				}
+			}
+
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			err = w.put(&storage.TupleRecord{
+				Store:            store,
+				ObjectType:       objectType,
+				ObjectID:         objectID,
+				Relation:         tk.GetRelation(),
+				User:             tk.GetUser(),
+				ConditionName:    record.ConditionName,
+				ConditionContext: record.ConditionContext,
+				Ulid:             record.Ulid,
+				InsertedAt:       record.InsertedAt,
+				ExpiresAt:        record.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	if len(records.Changes) > 0 {
+		s.changelogBroadcaster.Notify(store)
+	}
+	return nil
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox.
+func (s *Bolt) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	_, span := startTrace(ctx, "ReadOutbox")
+	defer span.End()
+
+	var events []*storage.Event
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(outboxBucket).Cursor()
+		for k, v := c.First(); k != nil && len(events) < limit; k, v = c.Next() {
+			var event storage.Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return fmt.Errorf("decode outbox event '%s': %w", k, err)
+			}
+			events = append(events, &event)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents.
+func (s *Bolt) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	_, span := startTrace(ctx, "DeleteOutboxEvents")
+	defer span.End()
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(outboxBucket)
+		for _, id := range ids {
+			if err := b.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	return nil
+}
+
+// putOutboxEvent puts the event in the outbox within the write transaction, if the outbox is enabled and the event
+// isn't nil.
+func (s *Bolt) putOutboxEvent(tx *bbolt.Tx, event *storage.Event) error {
+	if !s.outbox || event == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(outboxBucket).Put([]byte(event.ID), data)
+}
+
+// MaxTuplesPerImport see [storage.TupleImporter].MaxTuplesPerImport.
+func (s *Bolt) MaxTuplesPerImport() int {
+	return storage.DefaultMaxTuplesPerImport
+}
+
+// MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
+func (s *Bolt) MaxTuplesPerWrite() int {
+	return s.maxTuplesPerWrite
+}
+
+// IsReady see [storage.OpenFGADatastore].IsReady.
+func (s *Bolt) IsReady(context.Context) (storage.ReadinessStatus, error) {
+	if err := s.db.View(func(*bbolt.Tx) error { return nil }); err != nil {
+		if errors.Is(err, bbolt.ErrDatabaseNotOpen) {
+			return storage.ReadinessStatus{Message: "database closed"}, nil
+		}
+		return storage.ReadinessStatus{}, err
+	}
+	return storage.ReadinessStatus{IsReady: true}, nil
+}
@@ -0,0 +1,82 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestBoltDatastore(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	tokenSerializer := encoder.NewStringContinuationTokenSerializer()
+	ds, err := New(filepath.Join(t.TempDir(), "openfga.db"), WithContinuationTokenSerializer(tokenSerializer))
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	test.RunAllTests(t, ds, tokenSerializer)
+}
+
+func TestBoltDatastoreWithSqlTokenSerializer(t *testing.T) {
+	tokenSerializer := sqlcommon.NewSQLContinuationTokenSerializer()
+	ds, err := New(filepath.Join(t.TempDir(), "openfga.db"), WithContinuationTokenSerializer(tokenSerializer))
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	t.Run("TestReadChanges", func(t *testing.T) { test.ReadChangesTest(t, ds, tokenSerializer) })
+}
+
+func TestBoltDatastoreOutbox(t *testing.T) {
+	ds, err := New(filepath.Join(t.TempDir(), "openfga.db"), WithOutbox())
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	test.EventOutboxTest(t, ds, ds)
+}
+
+func TestBoltDatastoreIsPersisted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "openfga.db")
+	storeID := ulid.Make().String()
+	tk := tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:github.com|anne@test.com", "condition1", nil)
+
+	ds, err := New(path)
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+	ds.Close()
+
+	ds, err = New(path)
+	require.NoError(t, err)
+	defer ds.Close()
+
+	got, err := ds.ReadUserTuple(ctx, storeID, tk, storage.ReadUserTupleOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "condition1", got.GetKey().GetCondition().GetName())
+
+	iter, err := ds.ReadStartingWithUser(ctx, storeID, storage.ReadStartingWithUserFilter{
+		ObjectType: "document",
+		Relation:   "viewer",
+		UserFilter: []*openfgav1.ObjectRelation{{Object: tk.GetUser()}},
+	}, storage.ReadStartingWithUserOptions{})
+	require.NoError(t, err)
+	defer iter.Stop()
+	_, err = iter.Next(ctx)
+	require.NoError(t, err)
+
+	status, err := ds.IsReady(ctx)
+	require.NoError(t, err)
+	require.True(t, status.IsReady)
+}
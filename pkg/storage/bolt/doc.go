@@ -0,0 +1,3 @@
+// Package bolt contains an implementation of the storage interface that works with an embedded bbolt key-value store,
+// persisting a server's data in a single file without an external database.
+package bolt
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrCacheEntryNotSerializable is returned when serializing a cache entry whose type has no registered codec.
+var ErrCacheEntryNotSerializable = errors.New("cache entry type is not serializable")
+
+// cacheEntryCodec serializes the entries of one type, so that they can be stored in a cache shared by several servers.
+type cacheEntryCodec struct {
+	name      string
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte) (any, error)
+}
+
+var (
+	cacheEntryCodecsMu     sync.RWMutex
+	cacheEntryCodecsByType = map[reflect.Type]*cacheEntryCodec{}
+	cacheEntryCodecsByName = map[string]*cacheEntryCodec{}
+)
+
+func init() {
+	RegisterJSONCacheEntry[ChangelogCacheEntry]("changelog")
+	RegisterJSONCacheEntry[InvalidEntityCacheEntry]("invalid_entity")
+	RegisterCacheEntryCodec("tuple_iterator", marshalTupleIteratorCacheEntry, unmarshalTupleIteratorCacheEntry)
+}
+
+// RegisterCacheEntryCodec registers how the cache entries of type T are serialized, under a name which identifies
+// their type in the serialized form. It panics if the name or the type is already registered, so it is meant to be
+// called from an init function.
+func RegisterCacheEntryCodec[T any](name string, marshal func(T) ([]byte, error), unmarshal func([]byte) (T, error)) {
+	cacheEntryCodecsMu.Lock()
+	defer cacheEntryCodecsMu.Unlock()
+
+	typ := reflect.TypeFor[T]()
+	if _, ok := cacheEntryCodecsByType[typ]; ok {
+		panic(fmt.Sprintf("cache entry codec already registered for type %s", typ))
+	}
+	if _, ok := cacheEntryCodecsByName[name]; ok {
+		panic(fmt.Sprintf("cache entry codec already registered with name %q", name))
+	}
+
+	codec := &cacheEntryCodec{
+		name: name,
+		marshal: func(value any) ([]byte, error) {
+			return marshal(value.(T))
+		},
+		unmarshal: func(data []byte) (any, error) {
+			return unmarshal(data)
+		},
+	}
+	cacheEntryCodecsByType[typ] = codec
+	cacheEntryCodecsByName[name] = codec
+}
+
+// RegisterJSONCacheEntry registers the cache entries of type *T, serialized as JSON.
+func RegisterJSONCacheEntry[T any](name string) {
+	RegisterCacheEntryCodec(name,
+		func(entry *T) ([]byte, error) {
+			return json.Marshal(entry)
+		},
+		func(data []byte) (*T, error) {
+			entry := new(T)
+			if err := json.Unmarshal(data, entry); err != nil {
+				return nil, err
+			}
+			return entry, nil
+		},
+	)
+}
+
+// MarshalCacheEntry serializes a cache entry whose type is registered, prefixed by the name of its type.
+// It returns ErrCacheEntryNotSerializable if the type isn't registered.
+func MarshalCacheEntry(value any) ([]byte, error) {
+	cacheEntryCodecsMu.RLock()
+	codec, ok := cacheEntryCodecsByType[reflect.TypeOf(value)]
+	cacheEntryCodecsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrCacheEntryNotSerializable, value)
+	}
+
+	data, err := codec.marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]byte(codec.name), 0), data...), nil
+}
+
+// UnmarshalCacheEntry deserializes a cache entry serialized by MarshalCacheEntry.
+func UnmarshalCacheEntry(data []byte) (any, error) {
+	name, payload, found := bytes.Cut(data, []byte{0})
+	if !found {
+		return nil, fmt.Errorf("malformed cache entry")
+	}
+
+	cacheEntryCodecsMu.RLock()
+	codec, ok := cacheEntryCodecsByName[string(name)]
+	cacheEntryCodecsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrCacheEntryNotSerializable, name)
+	}
+
+	return codec.unmarshal(payload)
+}
+
+// tupleIteratorCacheEntryJSON is the serialized form of a TupleIteratorCacheEntry: the tuples are protobuf messages.
+type tupleIteratorCacheEntryJSON struct {
+	Tuples       [][]byte  `json:"tuples"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+func marshalTupleIteratorCacheEntry(entry *TupleIteratorCacheEntry) ([]byte, error) {
+	serialized := tupleIteratorCacheEntryJSON{
+		Tuples:       make([][]byte, 0, len(entry.Tuples)),
+		LastModified: entry.LastModified,
+	}
+	for _, t := range entry.Tuples {
+		data, err := proto.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		serialized.Tuples = append(serialized.Tuples, data)
+	}
+
+	return json.Marshal(serialized)
+}
+
+func unmarshalTupleIteratorCacheEntry(data []byte) (*TupleIteratorCacheEntry, error) {
+	var serialized tupleIteratorCacheEntryJSON
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, err
+	}
+
+	entry := &TupleIteratorCacheEntry{
+		Tuples:       make([]*openfgav1.Tuple, 0, len(serialized.Tuples)),
+		LastModified: serialized.LastModified,
+	}
+	for _, data := range serialized.Tuples {
+		t := &openfgav1.Tuple{}
+		if err := proto.Unmarshal(data, t); err != nil {
+			return nil, err
+		}
+		entry.Tuples = append(entry.Tuples, t)
+	}
+
+	return entry, nil
+}
@@ -4,8 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 func TestInMemoryCache(t *testing.T) {
@@ -41,3 +44,33 @@ func TestInMemoryCache(t *testing.T) {
 		cache.Stop()
 	})
 }
+
+func TestCacheEntrySerialization(t *testing.T) {
+	lastModified := time.Now().UTC()
+
+	t.Run("registered_entries", func(t *testing.T) {
+		for _, entry := range []any{
+			&ChangelogCacheEntry{LastModified: lastModified},
+			&InvalidEntityCacheEntry{LastModified: lastModified},
+			&TupleIteratorCacheEntry{Tuples: []*openfgav1.Tuple{{Key: &openfgav1.TupleKey{Object: "document:1", Relation: "viewer", User: "user:anne"}}}, LastModified: lastModified},
+		} {
+			data, err := MarshalCacheEntry(entry)
+			require.NoError(t, err)
+
+			value, err := UnmarshalCacheEntry(data)
+			require.NoError(t, err)
+			require.Empty(t, cmp.Diff(entry, value, protocmp.Transform()))
+		}
+	})
+
+	t.Run("unregistered_entries", func(t *testing.T) {
+		_, err := MarshalCacheEntry("value")
+		require.ErrorIs(t, err, ErrCacheEntryNotSerializable)
+
+		_, err = UnmarshalCacheEntry([]byte("unknown\x00{}"))
+		require.ErrorIs(t, err, ErrCacheEntryNotSerializable)
+
+		_, err = UnmarshalCacheEntry([]byte("malformed"))
+		require.Error(t, err)
+	})
+}
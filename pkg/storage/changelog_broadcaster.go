@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// ChangelogBroadcaster notifies the watchers of the changelog of each store. It helps datastores implement
+// [ChangelogWatcher].
+type ChangelogBroadcaster struct {
+	mu sync.Mutex
+	// map: store => set of watchers
+	watchers map[string]map[chan struct{}]struct{} // GUARDED_BY(mu).
+	closed   bool                                  // GUARDED_BY(mu).
+}
+
+func NewChangelogBroadcaster() *ChangelogBroadcaster {
+	return &ChangelogBroadcaster{
+		watchers: map[string]map[chan struct{}]struct{}{},
+	}
+}
+
+// Watch returns a channel that receives a value after each call to Notify for the store. The channel is
+// closed once ctx is done, or when the broadcaster is closed.
+func (b *ChangelogBroadcaster) Watch(ctx context.Context, store string) <-chan struct{} {
+	// a pending notification covers all the changes committed until it is received
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	if b.watchers[store] == nil {
+		b.watchers[store] = map[chan struct{}]struct{}{}
+	}
+	b.watchers[store][ch] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.watchers[store][ch]; !ok {
+			return
+		}
+		delete(b.watchers[store], ch)
+		if len(b.watchers[store]) == 0 {
+			delete(b.watchers, store)
+		}
+		close(ch)
+	})
+
+	return ch
+}
+
+// Notify signals the watchers of the store that changes have been committed. It never blocks.
+func (b *ChangelogBroadcaster) Notify(store string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.watchers[store] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// NotifyAll signals the watchers of every store, e.g. after notifications may have been missed.
+func (b *ChangelogBroadcaster) NotifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, watchers := range b.watchers {
+		for ch := range watchers {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Close closes the channels of all the watchers.
+func (b *ChangelogBroadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, watchers := range b.watchers {
+		for ch := range watchers {
+			close(ch)
+		}
+	}
+	b.watchers = map[string]map[chan struct{}]struct{}{}
+	b.closed = true
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogBroadcaster(t *testing.T) {
+	requireNotified := func(t *testing.T, ch <-chan struct{}) {
+		t.Helper()
+		select {
+		case _, ok := <-ch:
+			require.True(t, ok)
+		default:
+			require.Fail(t, "not notified")
+		}
+	}
+
+	requireNotNotified := func(t *testing.T, ch <-chan struct{}) {
+		t.Helper()
+		select {
+		case <-ch:
+			require.Fail(t, "unexpected notification")
+		default:
+		}
+	}
+
+	t.Run("notifies_the_watchers_of_the_store", func(t *testing.T) {
+		b := NewChangelogBroadcaster()
+		defer b.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := b.Watch(ctx, "store")
+		second := b.Watch(ctx, "store")
+		other := b.Watch(ctx, "other")
+
+		b.Notify("store")
+		// notifications are coalesced until received
+		b.Notify("store")
+
+		requireNotified(t, first)
+		requireNotified(t, second)
+		requireNotNotified(t, first)
+		requireNotNotified(t, other)
+
+		b.NotifyAll()
+		requireNotified(t, first)
+		requireNotified(t, other)
+	})
+
+	t.Run("closes_the_channel_once_ctx_is_done", func(t *testing.T) {
+		b := NewChangelogBroadcaster()
+		defer b.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := b.Watch(ctx, "store")
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			require.False(t, ok)
+		case <-time.After(time.Second):
+			require.Fail(t, "channel not closed")
+		}
+
+		b.Notify("store")
+	})
+
+	t.Run("closes_the_channels_on_close", func(t *testing.T) {
+		b := NewChangelogBroadcaster()
+
+		ch := b.Watch(context.Background(), "store")
+		b.Close()
+
+		_, ok := <-ch
+		require.False(t, ok)
+
+		_, ok = <-b.Watch(context.Background(), "store")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// ChangesBefore returns the lowest ULID with the time t: the changes made before t have a lower ULID.
+func ChangesBefore(t time.Time) ulid.ULID {
+	var id ulid.ULID
+	_ = id.SetTime(ulid.Timestamp(t))
+	return id
+}
+
+// CollapsibleChanges finds the changes of a changelog which can be collapsed, because a tuple is written then
+// deleted. Both changes can then be deleted without changing what a reader of the whole changelog ends up with.
+type CollapsibleChanges struct {
+	// map: tuple key => ulid of its write, if it is the last change of the tuple added
+	writes map[string]string
+	pairs  [][2]string
+}
+
+// NewCollapsibleChanges creates a [CollapsibleChanges].
+func NewCollapsibleChanges() *CollapsibleChanges {
+	return &CollapsibleChanges{writes: make(map[string]string)}
+}
+
+// Add adds the next change of the changelog, in the order of their ULIDs.
+func (c *CollapsibleChanges) Add(id string, tk tuple.TupleWithoutCondition, operation openfgav1.TupleOperation) {
+	key := tuple.TupleKeyToString(tk)
+	switch operation {
+	case openfgav1.TupleOperation_TUPLE_OPERATION_WRITE:
+		c.writes[key] = id
+	case openfgav1.TupleOperation_TUPLE_OPERATION_DELETE:
+		if write, ok := c.writes[key]; ok {
+			c.pairs = append(c.pairs, [2]string{write, id})
+			delete(c.writes, key)
+		}
+	}
+}
+
+// Len returns the number of pairs of collapsible changes found.
+func (c *CollapsibleChanges) Len() int {
+	return len(c.pairs)
+}
+
+// ULIDs returns the ULIDs of up to limit of the pairs of collapsible changes found, first found first, and the
+// highest of these ULIDs.
+func (c *CollapsibleChanges) ULIDs(limit int) ([]string, string) {
+	pairs := c.pairs
+	if len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	if len(pairs) == 0 {
+		return nil, ""
+	}
+
+	ulids := make([]string, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		ulids = append(ulids, pair[0], pair[1])
+	}
+	// the pairs are found in the order of their delete
+	return ulids, pairs[len(pairs)-1][1]
+}
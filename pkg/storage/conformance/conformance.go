@@ -0,0 +1,183 @@
+// Package conformance runs the scenarios of the storage test suite against a datastore, so that the implementations
+// of storage.OpenFGADatastore outside of this repository can check that they behave like the built-in ones.
+//
+// Within a Go test, use [RunT]. Outside of one, e.g. from a command, use [Run].
+package conformance
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/test"
+)
+
+// Status is the outcome of a scenario.
+type Status string
+
+const (
+	StatusPassed  Status = "PASS"
+	StatusFailed  Status = "FAIL"
+	StatusSkipped Status = "SKIP"
+)
+
+// Result is the outcome of a scenario, and how long it took.
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report holds the results of the scenarios run, in the order they ran.
+type Report struct {
+	Results []Result `json:"results"`
+
+	mu sync.Mutex
+}
+
+// Passed reports whether none of the scenarios failed.
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes the report to the writer, as a table of the results followed by their totals.
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	var total time.Duration
+	counts := map[Status]int{}
+	for _, result := range r.Results {
+		total += result.Duration
+		counts[result.Status]++
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Status, result.Name, result.Duration.Round(time.Millisecond)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped in %s\n",
+		counts[StatusPassed], counts[StatusFailed], counts[StatusSkipped], total.Round(time.Millisecond))
+	return err
+}
+
+// record returns the test running the scenario, which adds its result to the report.
+func (r *Report) record(scenario test.Scenario) func(t *testing.T) {
+	return func(t *testing.T) {
+		start := time.Now()
+		defer func() {
+			status := StatusPassed
+			switch {
+			case t.Failed():
+				status = StatusFailed
+			case t.Skipped():
+				status = StatusSkipped
+			}
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.Results = append(r.Results, Result{Name: scenario.Name, Status: status, Duration: time.Since(start)})
+		}()
+
+		scenario.Run(t)
+	}
+}
+
+// Option configures a run of the scenarios.
+type Option func(*options)
+
+type options struct {
+	tokenSerializer encoder.ContinuationTokenSerializer
+	pattern         *regexp.Regexp
+	verbose         bool
+}
+
+// WithContinuationTokenSerializer sets the serializer of the continuation tokens of the datastore. It defaults to
+// encoder.NewStringContinuationTokenSerializer.
+func WithContinuationTokenSerializer(tokenSerializer encoder.ContinuationTokenSerializer) Option {
+	return func(o *options) {
+		o.tokenSerializer = tokenSerializer
+	}
+}
+
+// WithPattern only runs the scenarios whose name matches the pattern.
+func WithPattern(pattern *regexp.Regexp) Option {
+	return func(o *options) {
+		o.pattern = pattern
+	}
+}
+
+// WithVerbose logs every scenario and subtest as it runs, rather than only the failures. It only applies to [Run].
+func WithVerbose(verbose bool) Option {
+	return func(o *options) {
+		o.verbose = verbose
+	}
+}
+
+// scenarios returns the scenarios of the datastore to run, each recording its result in the report.
+func scenarios(ds storage.OpenFGADatastore, report *Report, opts []Option) ([]testing.InternalTest, options) {
+	o := options{tokenSerializer: encoder.NewStringContinuationTokenSerializer()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var tests []testing.InternalTest
+	for _, scenario := range test.Scenarios(ds, o.tokenSerializer) {
+		if o.pattern != nil && !o.pattern.MatchString(scenario.Name) {
+			continue
+		}
+		tests = append(tests, testing.InternalTest{Name: scenario.Name, F: report.record(scenario)})
+	}
+	return tests, o
+}
+
+// RunT runs the scenarios against the datastore as subtests of t, and returns their results.
+func RunT(t *testing.T, ds storage.OpenFGADatastore, opts ...Option) *Report {
+	report := &Report{}
+	tests, _ := scenarios(ds, report, opts)
+	for _, test := range tests {
+		t.Run(test.Name, test.F)
+	}
+	return report
+}
+
+// Run runs the scenarios against the datastore, and returns their results. The failures are written to the standard
+// output as they happen, as 'go test' does.
+//
+// Run drives the testing package outside of a test binary, which it can only do once per process, and can't be
+// called from a Go test: use [RunT] instead.
+func Run(ds storage.OpenFGADatastore, opts ...Option) (*Report, error) {
+	if testing.Testing() {
+		return nil, errors.New("conformance.Run can't be called from a test binary, use conformance.RunT")
+	}
+	if flag.Parsed() {
+		return nil, errors.New("conformance.Run requires the flags of the process not to be parsed")
+	}
+
+	report := &Report{}
+	tests, o := scenarios(ds, report, opts)
+
+	testing.Init()
+	if err := flag.CommandLine.Parse([]string{"-test.count=1", "-test.v=" + strconv.FormatBool(o.verbose)}); err != nil {
+		return nil, err
+	}
+
+	// the exit code is left out, the report holding the outcome of every scenario
+	testing.MainStart(deps{}, tests, nil, nil, nil).Run()
+	return report, nil
+}
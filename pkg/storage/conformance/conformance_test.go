@@ -0,0 +1,65 @@
+package conformance
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/test"
+)
+
+func TestRunT(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	t.Run("runs_every_scenario", func(t *testing.T) {
+		report := RunT(t, ds)
+		require.True(t, report.Passed())
+
+		scenarios := test.Scenarios(ds, encoder.NewStringContinuationTokenSerializer())
+		require.Len(t, report.Results, len(scenarios))
+		for i, result := range report.Results {
+			require.Equal(t, scenarios[i].Name, result.Name)
+			require.Equal(t, StatusPassed, result.Status)
+			require.Positive(t, result.Duration)
+		}
+	})
+
+	t.Run("runs_the_scenarios_matching_the_pattern", func(t *testing.T) {
+		report := RunT(t, ds, WithPattern(regexp.MustCompile("AuthorizationModel")))
+		require.Len(t, report.Results, 3)
+	})
+
+	t.Run("can't_run_from_a_test_binary", func(t *testing.T) {
+		_, err := Run(ds)
+		require.ErrorContains(t, err, "use conformance.RunT")
+	})
+}
+
+func TestReport(t *testing.T) {
+	report := &Report{Results: []Result{
+		{Name: "TestStore", Status: StatusPassed, Duration: 1500 * time.Microsecond},
+		{Name: "TestReadChanges", Status: StatusFailed, Duration: 2 * time.Second},
+		{Name: "TestTupleCounter", Status: StatusSkipped},
+	}}
+	require.False(t, report.Passed())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Print(&buf))
+	require.Equal(t, `PASS  TestStore         2ms
+FAIL  TestReadChanges   2s
+SKIP  TestTupleCounter  0s
+
+1 passed, 1 failed, 1 skipped in 2.002s
+`, buf.String())
+}
@@ -0,0 +1,63 @@
+package conformance
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// corpusEntry is the seed of a fuzz test of the testing package, which deps has to mention, though Run runs none.
+type corpusEntry = struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []any
+	Generation int
+	IsSeed     bool
+}
+
+var errFuzzingUnsupported = errors.New("fuzzing is not supported")
+
+// deps are the dependencies testing.MainStart expects from 'go test', with neither profiling, test logs, coverage
+// nor fuzzing.
+type deps struct{}
+
+func (deps) ImportPath() string { return "" }
+
+func (deps) ModulePath() string { return "" }
+
+func (deps) MatchString(pat, str string) (bool, error) { return regexp.MatchString(pat, str) }
+
+func (deps) SetPanicOnExit0(bool) {}
+
+func (deps) StartCPUProfile(io.Writer) error { return nil }
+
+func (deps) StopCPUProfile() {}
+
+func (deps) StartTestLog(io.Writer) {}
+
+func (deps) StopTestLog() error { return nil }
+
+func (deps) WriteProfileTo(string, io.Writer, int) error { return nil }
+
+func (deps) CoordinateFuzzing(time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, string, string) error {
+	return errFuzzingUnsupported
+}
+
+func (deps) RunFuzzWorker(func(corpusEntry) error) error { return errFuzzingUnsupported }
+
+func (deps) ReadCorpus(string, []reflect.Type) ([]corpusEntry, error) {
+	return nil, errFuzzingUnsupported
+}
+
+func (deps) CheckCorpus([]any, []reflect.Type) error { return nil }
+
+func (deps) ResetCoverage() {}
+
+func (deps) SnapshotCoverage() {}
+
+func (deps) InitRuntimeCoverage() (string, func(string, string) (string, error), func() float64) {
+	return "", nil, nil
+}
@@ -26,6 +26,9 @@ var (
 	// already existed or the tuple to be deleted did not exist.
 	ErrInvalidWriteInput = errors.New("invalid write input")
 
+	// ErrPreconditionFailed is returned when a precondition of a write does not hold.
+	ErrPreconditionFailed = errors.New("write precondition failed")
+
 	// ErrTransactionalWriteFailed is returned when two writes attempt to write the same tuple at the same time.
 	ErrTransactionalWriteFailed = errors.New("transactional write failed due to conflict")
 
@@ -34,6 +37,14 @@ var (
 
 	// ErrNotFound is returned when the object does not exist.
 	ErrNotFound = errors.New("not found")
+
+	// ErrRevisionUnavailable is returned when a store can't be read at an exact revision, because the changelog no
+	// longer holds the changes made since.
+	ErrRevisionUnavailable = errors.New("the store can no longer be read at the revision")
+
+	// ErrContinuationTokenExpired is returned by ReadChanges when the continuation token points before changes
+	// which were deleted from the changelog (see ChangelogPruner).
+	ErrContinuationTokenExpired = errors.New("continuation token expired")
 )
 
 // ExceededMaxTypeDefinitionsLimitError constructs an error indicating that
@@ -69,3 +80,33 @@ func InvalidWriteInputError(tk tuple.TupleWithoutCondition, operation openfgav1.
 		return nil
 	}
 }
+
+// DuplicateWithDifferentConditionError generates an error for a tuple to be written which already
+// exists with a different condition, even though duplicate writes are ignored.
+func DuplicateWithDifferentConditionError(tk tuple.TupleWithoutCondition) error {
+	return fmt.Errorf(
+		"cannot write a tuple which already exists with a different condition: user: '%s', relation: '%s', object: '%s': %w",
+		tk.GetUser(),
+		tk.GetRelation(),
+		tk.GetObject(),
+		ErrInvalidWriteInput,
+	)
+}
+
+// PreconditionFailedError generates an error for a write precondition which does not hold,
+// that is a tuple which was expected to exist and does not, or the other way around.
+func PreconditionFailedError(precondition WritePrecondition) error {
+	tk := precondition.TupleKey
+	state := "does not exist"
+	if !precondition.Exists {
+		state = "already exists"
+	}
+	return fmt.Errorf(
+		"precondition failed, tuple %s: user: '%s', relation: '%s', object: '%s': %w",
+		state,
+		tk.GetUser(),
+		tk.GetRelation(),
+		tk.GetObject(),
+		ErrPreconditionFailed,
+	)
+}
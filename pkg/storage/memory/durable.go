@@ -0,0 +1,754 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// FsyncPolicy defines when the write-ahead log of a durable [MemoryBackend] is flushed to disk.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways flushes each change before it is acknowledged: no acknowledged change is lost on a crash.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval flushes the changes every second: up to a second of changes may be lost on a crash.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves flushing the changes to the operating system, so only a clean shutdown is durable.
+	FsyncNever FsyncPolicy = "never"
+
+	DefaultFsyncPolicy      = FsyncInterval
+	DefaultSnapshotInterval = 5 * time.Minute
+
+	// fsyncInterval is how often the write-ahead log is flushed with the FsyncInterval policy.
+	fsyncInterval = time.Second
+
+	snapshotFileName = "snapshot"
+	walFileName      = "wal"
+)
+
+// IsValidFsyncPolicy reports whether the policy is one of the supported ones.
+func IsValidFsyncPolicy(policy FsyncPolicy) bool {
+	switch policy {
+	case FsyncAlways, FsyncInterval, FsyncNever:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithFsyncPolicy returns a [StorageOption] that sets when a durable [MemoryBackend] flushes its write-ahead log.
+func WithFsyncPolicy(policy FsyncPolicy) StorageOption {
+	return func(ds *MemoryBackend) { ds.fsyncPolicy = policy }
+}
+
+// WithSnapshotInterval returns a [StorageOption] that sets how often a durable [MemoryBackend] compacts its
+// write-ahead log into a snapshot, when it has changed.
+func WithSnapshotInterval(interval time.Duration) StorageOption {
+	return func(ds *MemoryBackend) { ds.snapshotInterval = interval }
+}
+
+// WithLogger returns a [StorageOption] that sets the logger of a durable [MemoryBackend].
+func WithLogger(l logger.Logger) StorageOption {
+	return func(ds *MemoryBackend) { ds.logger = l }
+}
+
+// durability holds the background work of a durable MemoryBackend.
+type durability struct {
+	dir    string
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDurable creates a [MemoryBackend] which keeps its data in memory, but persists it in the directory so that it
+// survives restarts.
+//
+// Every change (tuple writes and deletes, authorization models, assertions and stores) is appended to a write-ahead
+// log before it is applied, and flushed to disk according to the [FsyncPolicy]. The log is periodically compacted
+// into a snapshot of the whole datastore. On creation, the last snapshot is loaded and the log replayed on top of it.
+// Each snapshot and log entry has a checksum: if one doesn't match, NewDurable fails with [ErrCorrupted], except
+// for an entry cut short at the end of the log, as left by a crash while appending it, which is discarded.
+//
+// A directory must only be used by one MemoryBackend at a time. Close must be called to stop the background work
+// and take a last snapshot.
+func NewDurable(dir string, opts ...StorageOption) (storage.OpenFGADatastore, error) {
+	ds := newMemoryBackend(opts...)
+	if !IsValidFsyncPolicy(ds.fsyncPolicy) {
+		return nil, fmt.Errorf("unsupported fsync policy '%s'", ds.fsyncPolicy)
+	}
+	if ds.snapshotInterval <= 0 {
+		return nil, errors.New("the snapshot interval must be positive")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create memory datastore directory: %w", err)
+	}
+
+	seq, err := ds.loadSnapshot(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open write-ahead log: %w", err)
+	}
+
+	ds.wal = &writeAheadLog{file: file, policy: ds.fsyncPolicy, seq: seq}
+	if err := ds.replay(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ds.durability = &durability{dir: dir, cancel: cancel}
+	ds.durability.wg.Add(1)
+	go func() {
+		defer ds.durability.wg.Done()
+		ds.runDurability(ctx)
+	}()
+
+	return ds, nil
+}
+
+// runDurability flushes the write-ahead log and takes the snapshots until ctx is done.
+func (s *MemoryBackend) runDurability(ctx context.Context) {
+	snapshots := time.NewTicker(s.snapshotInterval)
+	defer snapshots.Stop()
+
+	var syncs <-chan time.Time
+	if s.fsyncPolicy == FsyncInterval {
+		ticker := time.NewTicker(fsyncInterval)
+		defer ticker.Stop()
+		syncs = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncs:
+			if err := s.wal.sync(); err != nil {
+				s.logger.Error("failed to sync the write-ahead log of the memory datastore", zap.Error(err))
+			}
+		case <-snapshots.C:
+			if err := s.snapshot(); err != nil {
+				s.logger.Error("failed to snapshot the memory datastore", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *MemoryBackend) closeDurable() {
+	s.durability.cancel()
+	s.durability.wg.Wait()
+
+	if err := s.snapshot(); err != nil {
+		s.logger.Error("failed to snapshot the memory datastore", zap.Error(err))
+	}
+	if err := s.wal.close(); err != nil {
+		s.logger.Error("failed to close the write-ahead log of the memory datastore", zap.Error(err))
+	}
+}
+
+// log appends the entry to the write-ahead log of a durable MemoryBackend. It must be called with the lock guarding
+// the data the entry changes, before applying the change.
+func (s *MemoryBackend) log(entry *walEntry) error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.append(entry)
+}
+
+// replay applies the entries of the write-ahead log which the snapshot doesn't cover.
+func (s *MemoryBackend) replay() error {
+	file := s.wal.file
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("read write-ahead log: %w", err)
+	}
+
+	var size int64
+	for {
+		payload, err := readFrame(file)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errTornFrame) {
+			s.logger.Warn("discarding the incomplete last entry of the write-ahead log of the memory datastore",
+				zap.Int64("offset", size),
+			)
+			if err := file.Truncate(size); err != nil {
+				return fmt.Errorf("truncate write-ahead log: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read write-ahead log entry at offset %d: %w", size, err)
+		}
+
+		entry, err := decodeWALEntry(payload)
+		if err != nil {
+			return fmt.Errorf("%w: write-ahead log entry at offset %d: %w", ErrCorrupted, size, err)
+		}
+
+		size += int64(frameHeaderSize + len(payload))
+
+		// the log isn't reset yet when the process stopped right after a snapshot
+		if entry.Seq <= s.wal.seq {
+			continue
+		}
+		if entry.Seq != s.wal.seq+1 {
+			return fmt.Errorf("%w: write-ahead log entry %d follows entry %d", ErrCorrupted, entry.Seq, s.wal.seq)
+		}
+
+		s.apply(entry)
+		s.wal.seq = entry.Seq
+	}
+
+	s.wal.size = size
+	return nil
+}
+
+// apply applies a change read from the write-ahead log.
+func (s *MemoryBackend) apply(entry *walEntry) {
+	switch entry.Op {
+	case walOpTuples:
+		s.applyTupleMutation(entry.Store, entry.Tuples)
+	case walOpWriteAuthorizationModel:
+		s.applyAuthorizationModel(entry.Store, entry.AuthorizationModel)
+	case walOpWriteAssertions:
+		s.assertions[fmt.Sprintf("%s|%s", entry.Store, entry.AuthorizationModelID)] = entry.Assertions
+	case walOpCreateStore, walOpUpdateStore:
+		s.stores[entry.Store] = entry.StoreData
+		s.applyStoreLabels(entry.Store, entry.StoreLabels)
+	case walOpDeleteStore, walOpUndeleteStore:
+		s.stores[entry.Store] = entry.StoreData
+	case walOpPurgeStore:
+		s.applyPurgeStore(entry.Store, entry.PurgeLimit)
+	case walOpDeleteChanges:
+		s.applyDeletedChanges(entry.Store, entry.DeletedChanges)
+	case walOpDeleteOutboxEvents:
+		s.applyDeletedOutboxEvents(entry.DeletedEvents)
+	}
+	s.applyOutboxEvent(entry.Event)
+}
+
+// snapshot writes the whole datastore to a new snapshot, then empties the write-ahead log, unless nothing changed
+// since the last snapshot. The changes are blocked meanwhile.
+func (s *MemoryBackend) snapshot() error {
+	s.mutexTuples.RLock()
+	defer s.mutexTuples.RUnlock()
+	s.mutexModels.RLock()
+	defer s.mutexModels.RUnlock()
+	s.mutexStores.RLock()
+	defer s.mutexStores.RUnlock()
+	s.mutexAssertions.RLock()
+	defer s.mutexAssertions.RUnlock()
+	s.mutexOutbox.Lock()
+	defer s.mutexOutbox.Unlock()
+
+	s.wal.mu.Lock()
+	seq, size := s.wal.seq, s.wal.size
+	s.wal.mu.Unlock()
+	if size == 0 {
+		return nil
+	}
+
+	payload, err := s.encodeSnapshot(seq)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	path := filepath.Join(s.durability.dir, snapshotFileName)
+	if err := writeFileAtomically(path, encodeFrame(payload)); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	if err := s.wal.reset(); err != nil {
+		return fmt.Errorf("reset write-ahead log: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomically replaces the file at path with one holding data, so that it holds either the former or the
+// new content even after a crash.
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// sync the directory, so that the rename is durable
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// loadSnapshot loads the snapshot at path if there is one, and returns the sequence number of the last entry of the
+// write-ahead log it covers.
+func (s *MemoryBackend) loadSnapshot(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	payload, err := readFrame(file)
+	if errors.Is(err, io.EOF) || errors.Is(err, errTornFrame) {
+		return 0, fmt.Errorf("%w: snapshot is truncated", ErrCorrupted)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	seq, err := s.decodeSnapshot(payload)
+	if err != nil {
+		return 0, fmt.Errorf("%w: snapshot: %w", ErrCorrupted, err)
+	}
+	return seq, nil
+}
+
+type walOp string
+
+const (
+	walOpTuples                  walOp = "tuples"
+	walOpWriteAuthorizationModel walOp = "write_authorization_model"
+	walOpWriteAssertions         walOp = "write_assertions"
+	walOpCreateStore             walOp = "create_store"
+	walOpUpdateStore             walOp = "update_store"
+	walOpDeleteStore             walOp = "delete_store"
+	walOpUndeleteStore           walOp = "undelete_store"
+	walOpPurgeStore              walOp = "purge_store"
+	walOpDeleteChanges           walOp = "delete_changes"
+	walOpDeleteOutboxEvents      walOp = "delete_outbox_events"
+)
+
+// walEntry is a change of a durable MemoryBackend, as appended to its write-ahead log.
+type walEntry struct {
+	Seq   uint64
+	Op    walOp
+	Store string
+
+	Tuples               *tupleMutation
+	AuthorizationModel   *openfgav1.AuthorizationModel
+	AuthorizationModelID string
+	Assertions           []*openfgav1.Assertion
+	StoreData            *openfgav1.Store
+	StoreLabels          map[string]string
+	DeletedChanges       []ulid.ULID
+	PurgeLimit           int
+
+	// Event is the event of the change recorded in the outbox, if any.
+	Event         *storage.Event
+	DeletedEvents []string
+}
+
+// The serialized forms of the entries and the snapshots: JSON, holding the protobuf messages as bytes.
+type (
+	walEntryJSON struct {
+		Seq                  uint64             `json:"seq"`
+		Op                   walOp              `json:"op"`
+		Store                string             `json:"store"`
+		Tuples               *tupleMutationJSON `json:"tuples,omitempty"`
+		AuthorizationModel   []byte             `json:"authorization_model,omitempty"`
+		AuthorizationModelID string             `json:"authorization_model_id,omitempty"`
+		Assertions           []byte             `json:"assertions,omitempty"`
+		StoreData            []byte             `json:"store_data,omitempty"`
+		StoreLabels          map[string]string  `json:"store_labels,omitempty"`
+		DeletedChanges       []string           `json:"deleted_changes,omitempty"`
+		PurgeLimit           int                `json:"purge_limit,omitempty"`
+		Event                *storage.Event     `json:"event,omitempty"`
+		DeletedEvents        []string           `json:"deleted_events,omitempty"`
+	}
+
+	tupleMutationJSON struct {
+		Deleted []*tupleRecordJSON `json:"deleted,omitempty"`
+		Written []*tupleRecordJSON `json:"written,omitempty"`
+		Changes []*tupleChangeJSON `json:"changes,omitempty"`
+	}
+
+	tupleRecordJSON struct {
+		ObjectType       string     `json:"object_type"`
+		ObjectID         string     `json:"object_id"`
+		Relation         string     `json:"relation"`
+		User             string     `json:"user"`
+		ConditionName    string     `json:"condition_name,omitempty"`
+		ConditionContext []byte     `json:"condition_context,omitempty"`
+		Ulid             string     `json:"ulid,omitempty"`
+		InsertedAt       time.Time  `json:"inserted_at"`
+		ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	}
+
+	tupleChangeJSON struct {
+		Ulid   string `json:"ulid"`
+		Change []byte `json:"change"`
+	}
+
+	snapshotJSON struct {
+		Seq                 uint64                               `json:"seq"`
+		Stores              [][]byte                             `json:"stores"`
+		AuthorizationModels map[string][]*authorizationModelJSON `json:"authorization_models"`
+		Assertions          map[string][]byte                    `json:"assertions"`
+		Tuples              map[string]*tupleMutationJSON        `json:"tuples"`
+		ChangelogHorizons   map[string]string                    `json:"changelog_horizons,omitempty"`
+		StoreLabels         map[string]map[string]string         `json:"store_labels,omitempty"`
+		Outbox              []*storage.Event                     `json:"outbox,omitempty"`
+	}
+
+	authorizationModelJSON struct {
+		Model  []byte `json:"model"`
+		Latest bool   `json:"latest"`
+	}
+)
+
+func encodeWALEntry(entry *walEntry) ([]byte, error) {
+	serialized := walEntryJSON{
+		Seq:                  entry.Seq,
+		Op:                   entry.Op,
+		Store:                entry.Store,
+		AuthorizationModelID: entry.AuthorizationModelID,
+		Event:                entry.Event,
+	}
+
+	var err error
+	switch entry.Op {
+	case walOpTuples:
+		serialized.Tuples, err = encodeTupleMutation(entry.Tuples.Deleted, entry.Tuples.Written, entry.Tuples.Changes)
+	case walOpWriteAuthorizationModel:
+		serialized.AuthorizationModel, err = proto.Marshal(entry.AuthorizationModel)
+	case walOpWriteAssertions:
+		serialized.Assertions, err = proto.Marshal(&openfgav1.Assertions{Assertions: entry.Assertions})
+	case walOpCreateStore, walOpUpdateStore:
+		serialized.StoreData, err = proto.Marshal(entry.StoreData)
+		serialized.StoreLabels = entry.StoreLabels
+	case walOpDeleteStore, walOpUndeleteStore:
+		serialized.StoreData, err = proto.Marshal(entry.StoreData)
+	case walOpPurgeStore:
+		serialized.PurgeLimit = entry.PurgeLimit
+	case walOpDeleteChanges:
+		for _, id := range entry.DeletedChanges {
+			serialized.DeletedChanges = append(serialized.DeletedChanges, id.String())
+		}
+	case walOpDeleteOutboxEvents:
+		serialized.DeletedEvents = entry.DeletedEvents
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(serialized)
+}
+
+func decodeWALEntry(payload []byte) (*walEntry, error) {
+	var serialized walEntryJSON
+	if err := json.Unmarshal(payload, &serialized); err != nil {
+		return nil, err
+	}
+
+	entry := &walEntry{
+		Seq:                  serialized.Seq,
+		Op:                   serialized.Op,
+		Store:                serialized.Store,
+		AuthorizationModelID: serialized.AuthorizationModelID,
+		Event:                serialized.Event,
+	}
+
+	switch entry.Op {
+	case walOpTuples:
+		mutation, err := decodeTupleMutation(entry.Store, serialized.Tuples)
+		if err != nil {
+			return nil, err
+		}
+		entry.Tuples = mutation
+	case walOpWriteAuthorizationModel:
+		entry.AuthorizationModel = &openfgav1.AuthorizationModel{}
+		if err := proto.Unmarshal(serialized.AuthorizationModel, entry.AuthorizationModel); err != nil {
+			return nil, err
+		}
+	case walOpWriteAssertions:
+		assertions := &openfgav1.Assertions{}
+		if err := proto.Unmarshal(serialized.Assertions, assertions); err != nil {
+			return nil, err
+		}
+		entry.Assertions = assertions.GetAssertions()
+	case walOpCreateStore, walOpUpdateStore, walOpDeleteStore, walOpUndeleteStore:
+		entry.StoreData = &openfgav1.Store{}
+		if err := proto.Unmarshal(serialized.StoreData, entry.StoreData); err != nil {
+			return nil, err
+		}
+		entry.StoreLabels = serialized.StoreLabels
+	case walOpPurgeStore:
+		entry.PurgeLimit = serialized.PurgeLimit
+	case walOpDeleteChanges:
+		for _, id := range serialized.DeletedChanges {
+			parsed, err := ulid.Parse(id)
+			if err != nil {
+				return nil, err
+			}
+			entry.DeletedChanges = append(entry.DeletedChanges, parsed)
+		}
+	case walOpDeleteOutboxEvents:
+		entry.DeletedEvents = serialized.DeletedEvents
+	default:
+		return nil, fmt.Errorf("unknown operation %q", entry.Op)
+	}
+
+	return entry, nil
+}
+
+func encodeTupleMutation(deleted, written []*storage.TupleRecord, changes []*tupleChangeRec) (*tupleMutationJSON, error) {
+	serialized := &tupleMutationJSON{}
+	for _, tr := range deleted {
+		// only the key of a deleted tuple is needed
+		serialized.Deleted = append(serialized.Deleted, &tupleRecordJSON{
+			ObjectType: tr.ObjectType,
+			ObjectID:   tr.ObjectID,
+			Relation:   tr.Relation,
+			User:       tr.User,
+		})
+	}
+
+	for _, tr := range written {
+		record := &tupleRecordJSON{
+			ObjectType:    tr.ObjectType,
+			ObjectID:      tr.ObjectID,
+			Relation:      tr.Relation,
+			User:          tr.User,
+			ConditionName: tr.ConditionName,
+			Ulid:          tr.Ulid,
+			InsertedAt:    tr.InsertedAt,
+			ExpiresAt:     tr.ExpiresAt,
+		}
+		if tr.ConditionContext != nil {
+			conditionContext, err := proto.Marshal(tr.ConditionContext)
+			if err != nil {
+				return nil, err
+			}
+			record.ConditionContext = conditionContext
+		}
+		serialized.Written = append(serialized.Written, record)
+	}
+
+	for _, change := range changes {
+		data, err := proto.Marshal(change.Change)
+		if err != nil {
+			return nil, err
+		}
+		serialized.Changes = append(serialized.Changes, &tupleChangeJSON{Ulid: change.Ulid.String(), Change: data})
+	}
+
+	return serialized, nil
+}
+
+func decodeTupleMutation(store string, serialized *tupleMutationJSON) (*tupleMutation, error) {
+	mutation := &tupleMutation{}
+	if serialized == nil {
+		return mutation, nil
+	}
+
+	for _, record := range serialized.Deleted {
+		mutation.Deleted = append(mutation.Deleted, &storage.TupleRecord{
+			Store:      store,
+			ObjectType: record.ObjectType,
+			ObjectID:   record.ObjectID,
+			Relation:   record.Relation,
+			User:       record.User,
+		})
+	}
+
+	for _, record := range serialized.Written {
+		tr := &storage.TupleRecord{
+			Store:         store,
+			ObjectType:    record.ObjectType,
+			ObjectID:      record.ObjectID,
+			Relation:      record.Relation,
+			User:          record.User,
+			ConditionName: record.ConditionName,
+			Ulid:          record.Ulid,
+			InsertedAt:    record.InsertedAt,
+			ExpiresAt:     record.ExpiresAt,
+		}
+		if record.ConditionContext != nil {
+			tr.ConditionContext = &structpb.Struct{}
+			if err := proto.Unmarshal(record.ConditionContext, tr.ConditionContext); err != nil {
+				return nil, err
+			}
+		}
+		mutation.Written = append(mutation.Written, tr)
+	}
+
+	for _, change := range serialized.Changes {
+		id, err := ulid.Parse(change.Ulid)
+		if err != nil {
+			return nil, err
+		}
+		rec := &tupleChangeRec{Change: &openfgav1.TupleChange{}, Ulid: id}
+		if err := proto.Unmarshal(change.Change, rec.Change); err != nil {
+			return nil, err
+		}
+		mutation.Changes = append(mutation.Changes, rec)
+	}
+
+	return mutation, nil
+}
+
+// encodeSnapshot serializes the whole datastore. It must be called with all the locks held.
+func (s *MemoryBackend) encodeSnapshot(seq uint64) ([]byte, error) {
+	serialized := snapshotJSON{
+		Seq:                 seq,
+		AuthorizationModels: make(map[string][]*authorizationModelJSON, len(s.authorizationModels)),
+		Assertions:          make(map[string][]byte, len(s.assertions)),
+		Tuples:              make(map[string]*tupleMutationJSON, len(s.tuples)),
+		ChangelogHorizons:   make(map[string]string, len(s.changelogHorizons)),
+		StoreLabels:         s.storeLabels,
+		Outbox:              s.outbox,
+	}
+
+	for store, horizon := range s.changelogHorizons {
+		serialized.ChangelogHorizons[store] = horizon.String()
+	}
+
+	for _, store := range s.stores {
+		data, err := proto.Marshal(store)
+		if err != nil {
+			return nil, err
+		}
+		serialized.Stores = append(serialized.Stores, data)
+	}
+
+	for store, models := range s.authorizationModels {
+		for _, entry := range models {
+			data, err := proto.Marshal(entry.model)
+			if err != nil {
+				return nil, err
+			}
+			serialized.AuthorizationModels[store] = append(serialized.AuthorizationModels[store], &authorizationModelJSON{Model: data, Latest: entry.latest})
+		}
+	}
+
+	for id, assertions := range s.assertions {
+		data, err := proto.Marshal(&openfgav1.Assertions{Assertions: assertions})
+		if err != nil {
+			return nil, err
+		}
+		serialized.Assertions[id] = data
+	}
+
+	// the tuples and changes of a store are written as the mutation creating them
+	stores := make(map[string]struct{}, len(s.tuples))
+	for store := range s.tuples {
+		stores[store] = struct{}{}
+	}
+	for store := range s.changes {
+		stores[store] = struct{}{}
+	}
+	for store := range stores {
+		mutation, err := encodeTupleMutation(nil, s.tuples[store], s.changes[store])
+		if err != nil {
+			return nil, err
+		}
+		serialized.Tuples[store] = mutation
+	}
+
+	return json.Marshal(serialized)
+}
+
+// decodeSnapshot loads a snapshot into the datastore and returns the sequence number it covers.
+func (s *MemoryBackend) decodeSnapshot(payload []byte) (uint64, error) {
+	var serialized snapshotJSON
+	if err := json.Unmarshal(payload, &serialized); err != nil {
+		return 0, err
+	}
+
+	for _, data := range serialized.Stores {
+		store := &openfgav1.Store{}
+		if err := proto.Unmarshal(data, store); err != nil {
+			return 0, err
+		}
+		s.stores[store.GetId()] = store
+	}
+
+	for store, labels := range serialized.StoreLabels {
+		s.storeLabels[store] = labels
+	}
+
+	s.outbox = serialized.Outbox
+
+	for store, models := range serialized.AuthorizationModels {
+		s.authorizationModels[store] = make(map[string]*AuthorizationModelEntry, len(models))
+		for _, entry := range models {
+			model := &openfgav1.AuthorizationModel{}
+			if err := proto.Unmarshal(entry.Model, model); err != nil {
+				return 0, err
+			}
+			s.authorizationModels[store][model.GetId()] = &AuthorizationModelEntry{model: model, latest: entry.Latest}
+		}
+	}
+
+	for id, data := range serialized.Assertions {
+		if !strings.Contains(id, "|") {
+			return 0, fmt.Errorf("malformed assertions key %q", id)
+		}
+		assertions := &openfgav1.Assertions{}
+		if err := proto.Unmarshal(data, assertions); err != nil {
+			return 0, err
+		}
+		s.assertions[id] = assertions.GetAssertions()
+	}
+
+	for store, serializedMutation := range serialized.Tuples {
+		mutation, err := decodeTupleMutation(store, serializedMutation)
+		if err != nil {
+			return 0, err
+		}
+		s.applyTupleMutation(store, mutation)
+	}
+
+	for store, horizon := range serialized.ChangelogHorizons {
+		parsed, err := ulid.Parse(horizon)
+		if err != nil {
+			return 0, err
+		}
+		s.changelogHorizons[store] = parsed
+	}
+
+	return serialized.Seq, nil
+}
@@ -0,0 +1,342 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestDurableMemdbStorage(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	tokenSerializer := encoder.NewStringContinuationTokenSerializer()
+	ds, err := NewDurable(t.TempDir(), WithContinuationTokenSerializer(tokenSerializer))
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	test.RunAllTests(t, ds, tokenSerializer)
+}
+
+// crash stops a durable datastore without the final snapshot, as if the process was killed.
+func crash(t *testing.T, ds storage.OpenFGADatastore) {
+	backend := ds.(*MemoryBackend)
+	backend.durability.cancel()
+	backend.durability.wg.Wait()
+	require.NoError(t, backend.wal.file.Close())
+}
+
+// writeDurableData writes a store, a model, assertions and tuples, deleting one of them.
+func writeDurableData(t *testing.T, ds storage.OpenFGADatastore, storeID string) *openfgav1.AuthorizationModel {
+	ctx := context.Background()
+
+	_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "durable", CreatedAt: timestamppb.Now(), UpdatedAt: timestamppb.Now()})
+	require.NoError(t, err)
+
+	model := &openfgav1.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: "1.1",
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{Type: "user"},
+			{Type: "document"},
+		},
+	}
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.WriteAssertions(ctx, storeID, model.GetId(), []*openfgav1.Assertion{
+		{TupleKey: tuple.NewAssertionTupleKey("document:1", "viewer", "user:anne"), Expectation: true},
+	}))
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:anne", "condition1", nil),
+		tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+	}))
+	require.NoError(t, ds.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+		tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:2", "viewer", "user:bob")),
+	}, nil))
+
+	return model
+}
+
+func requireDurableData(t *testing.T, ds storage.OpenFGADatastore, storeID string, model *openfgav1.AuthorizationModel) {
+	ctx := context.Background()
+
+	store, err := ds.GetStore(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, "durable", store.GetName())
+
+	latest, err := ds.FindLatestAuthorizationModel(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, model.GetId(), latest.GetId())
+
+	assertions, err := ds.ReadAssertions(ctx, storeID, model.GetId())
+	require.NoError(t, err)
+	require.Len(t, assertions, 1)
+
+	got, err := ds.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "condition1", got.GetKey().GetCondition().GetName())
+
+	_, err = ds.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:2", "viewer", "user:bob"), storage.ReadUserTupleOptions{})
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	changes, _, err := ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+}
+
+func TestDurableMemdbStorageIsPersisted(t *testing.T) {
+	t.Run("from_the_snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+		storeID := ulid.Make().String()
+
+		ds, err := NewDurable(dir)
+		require.NoError(t, err)
+		model := writeDurableData(t, ds, storeID)
+		ds.Close()
+
+		info, err := os.Stat(filepath.Join(dir, walFileName))
+		require.NoError(t, err)
+		require.Zero(t, info.Size())
+
+		ds, err = NewDurable(dir)
+		require.NoError(t, err)
+		defer ds.Close()
+		requireDurableData(t, ds, storeID, model)
+	})
+
+	t.Run("from_the_write_ahead_log", func(t *testing.T) {
+		dir := t.TempDir()
+		storeID := ulid.Make().String()
+
+		ds, err := NewDurable(dir, WithFsyncPolicy(FsyncAlways))
+		require.NoError(t, err)
+		model := writeDurableData(t, ds, storeID)
+		crash(t, ds)
+
+		_, err = os.Stat(filepath.Join(dir, snapshotFileName))
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		ds, err = NewDurable(dir)
+		require.NoError(t, err)
+		defer ds.Close()
+		requireDurableData(t, ds, storeID, model)
+	})
+
+	t.Run("from_the_snapshot_and_the_write_ahead_log", func(t *testing.T) {
+		dir := t.TempDir()
+		storeID := ulid.Make().String()
+
+		ds, err := NewDurable(dir, WithSnapshotInterval(time.Hour))
+		require.NoError(t, err)
+		model := writeDurableData(t, ds, storeID)
+		require.NoError(t, ds.(*MemoryBackend).snapshot())
+
+		tk := tuple.NewTupleKey("document:3", "viewer", "user:charlie")
+		require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{tk}))
+		crash(t, ds)
+
+		ds, err = NewDurable(dir)
+		require.NoError(t, err)
+		defer ds.Close()
+		_, err = ds.ReadUserTuple(context.Background(), storeID, tk, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+
+		changes, _, err := ds.ReadChanges(context.Background(), storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{})
+		require.NoError(t, err)
+		require.Len(t, changes, 4)
+		latest, err := ds.FindLatestAuthorizationModel(context.Background(), storeID)
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), latest.GetId())
+	})
+}
+
+func TestDurableMemdbStorageRecovery(t *testing.T) {
+	setup := func(t *testing.T) (string, string, *openfgav1.AuthorizationModel) {
+		dir := t.TempDir()
+		storeID := ulid.Make().String()
+
+		ds, err := NewDurable(dir)
+		require.NoError(t, err)
+		model := writeDurableData(t, ds, storeID)
+		crash(t, ds)
+		return dir, storeID, model
+	}
+
+	t.Run("torn_last_entry_is_discarded", func(t *testing.T) {
+		dir, storeID, model := setup(t)
+
+		path := filepath.Join(dir, walFileName)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+		require.NoError(t, err)
+		frame := encodeFrame([]byte(`{"seq":100}`))
+		_, err = file.Write(frame[:len(frame)-3])
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		ds, err := NewDurable(dir)
+		require.NoError(t, err)
+		requireDurableData(t, ds, storeID, model)
+
+		// new entries are appended after the last complete one
+		tk := tuple.NewTupleKey("document:3", "viewer", "user:charlie")
+		require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{tk}))
+		crash(t, ds)
+
+		ds, err = NewDurable(dir)
+		require.NoError(t, err)
+		defer ds.Close()
+		_, err = ds.ReadUserTuple(context.Background(), storeID, tk, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("corrupted_entry_is_detected", func(t *testing.T) {
+		dir, _, _ := setup(t)
+
+		path := filepath.Join(dir, walFileName)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[frameHeaderSize+1] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = NewDurable(dir)
+		require.ErrorIs(t, err, ErrCorrupted)
+	})
+
+	t.Run("corrupted_snapshot_is_detected", func(t *testing.T) {
+		dir := t.TempDir()
+		ds, err := NewDurable(dir)
+		require.NoError(t, err)
+		writeDurableData(t, ds, ulid.Make().String())
+		ds.Close()
+
+		path := filepath.Join(dir, snapshotFileName)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-2] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = NewDurable(dir)
+		require.ErrorIs(t, err, ErrCorrupted)
+	})
+
+	t.Run("invalid_settings", func(t *testing.T) {
+		_, err := NewDurable(t.TempDir(), WithFsyncPolicy("sometimes"))
+		require.Error(t, err)
+
+		_, err = NewDurable(t.TempDir(), WithSnapshotInterval(0))
+		require.Error(t, err)
+	})
+}
+
+func TestDurableMemdbStorageChangelogPruningIsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	storeID := ulid.Make().String()
+	ctx := context.Background()
+
+	ds, err := NewDurable(dir, WithFsyncPolicy(FsyncAlways))
+	require.NoError(t, err)
+	writeDurableData(t, ds, storeID)
+
+	_, token, err := ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, ""),
+	})
+	require.NoError(t, err)
+
+	pruned, err := ds.(storage.ChangelogPruner).PruneChanges(ctx, storeID, time.Time{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	crash(t, ds)
+
+	ds, err = NewDurable(dir)
+	require.NoError(t, err)
+	defer ds.Close()
+
+	changes, _, err := ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	_, _, err = ds.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+		Pagination: storage.NewPaginationOptions(1, string(token)),
+	})
+	require.ErrorIs(t, err, storage.ErrContinuationTokenExpired)
+}
+
+func TestDurableMemdbStorageStorePurgeIsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	storeID := ulid.Make().String()
+	ctx := context.Background()
+
+	ds, err := NewDurable(dir, WithFsyncPolicy(FsyncAlways))
+	require.NoError(t, err)
+	writeDurableData(t, ds, storeID)
+	require.NoError(t, ds.DeleteStore(ctx, storeID))
+
+	purger := ds.(storage.StorePurger)
+	deleted, err := purger.PurgeStore(ctx, storeID, time.Now().Add(time.Hour), 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	crash(t, ds)
+
+	ds, err = NewDurable(dir)
+	require.NoError(t, err)
+	defer ds.Close()
+
+	// the tuples were purged, but the store is still deleted
+	_, err = ds.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+	require.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = ds.GetStore(ctx, storeID)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	deletedStores, err := ds.(storage.StorePurger).ListDeletedStores(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{storeID}, deletedStores)
+}
+
+func TestDurableMemdbStorageStoreLabelsArePersisted(t *testing.T) {
+	ctx := context.Background()
+
+	for name, withSnapshot := range map[string]bool{"from_the_write_ahead_log": false, "from_the_snapshot": true} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			storeID := ulid.Make().String()
+
+			ds, err := NewDurable(dir, WithFsyncPolicy(FsyncAlways))
+			require.NoError(t, err)
+			labeler := ds.(storage.StoreLabeler)
+			_, err = labeler.CreateStoreWithLabels(ctx, &openfgav1.Store{Id: storeID, Name: "labels"}, map[string]string{"env": "prod", "tenant": "acme"})
+			require.NoError(t, err)
+			_, err = labeler.UpdateStore(ctx, storeID, "renamed", map[string]string{"env": "dev"})
+			require.NoError(t, err)
+			if withSnapshot {
+				require.NoError(t, ds.(*MemoryBackend).snapshot())
+			}
+			crash(t, ds)
+
+			ds, err = NewDurable(dir)
+			require.NoError(t, err)
+			defer ds.Close()
+
+			store, err := ds.GetStore(ctx, storeID)
+			require.NoError(t, err)
+			require.Equal(t, "renamed", store.GetName())
+
+			labels, err := ds.(storage.StoreLabeler).ReadStoreLabels(ctx, storeID)
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"env": "dev"}, labels)
+		})
+	}
+}
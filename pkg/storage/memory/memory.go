@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/telemetry"
 	tupleUtils "github.com/openfga/openfga/pkg/tuple"
@@ -116,8 +118,8 @@ const (
 	defaultMaxTypesPerAuthorizationModel = 100
 )
 
-// MemoryBackend provides an ephemeral memory-backed implementation of [storage.OpenFGADatastore].
-// These instances may be safely shared by multiple go-routines.
+// MemoryBackend provides a memory-backed implementation of [storage.OpenFGADatastore], which is ephemeral unless
+// created with [NewDurable]. These instances may be safely shared by multiple go-routines.
 type MemoryBackend struct {
 	maxTuplesPerWrite             int
 	maxTypesPerAuthorizationModel int
@@ -130,6 +132,8 @@ type MemoryBackend struct {
 	// ChangelogBackend
 	// map: store => set of changes
 	changes map[string][]*tupleChangeRec // GUARDED_BY(mutexTuples).
+	// map: store => ulid of the last change deleted from the changelog
+	changelogHorizons map[string]ulid.ULID // GUARDED_BY(mutexTuples).
 
 	// AuthorizationModelBackend
 	// map: store = > map: type definition id => type definition
@@ -137,20 +141,66 @@ type MemoryBackend struct {
 	mutexModels         sync.RWMutex
 
 	// map: store id => store data
-	stores      map[string]*openfgav1.Store // GUARDED_BY(mutexStores).
+	stores map[string]*openfgav1.Store // GUARDED_BY(mutexStores).
+	// map: store id => labels
+	storeLabels map[string]map[string]string // GUARDED_BY(mutexStores).
 	mutexStores sync.RWMutex
 
 	// map: store id | authz model id => assertions
 	assertions      map[string][]*openfgav1.Assertion // GUARDED_BY(mutexAssertions).
 	mutexAssertions sync.RWMutex
 
+	// EventOutbox, recording the changes only if enabled with [WithOutbox]
+	outboxEnabled bool
+	outbox        []*storage.Event // GUARDED_BY(mutexOutbox).
+	mutexOutbox   sync.Mutex
+
 	// ContinuationTokenSerializer required to serialize the token
 	tokenSerializer encoder.ContinuationTokenSerializer
+
+	changelogBroadcaster *storage.ChangelogBroadcaster
+
+	// durability settings and write-ahead log of a durable MemoryBackend, see [NewDurable].
+	fsyncPolicy      FsyncPolicy
+	snapshotInterval time.Duration
+	logger           logger.Logger
+	wal              *writeAheadLog // nil unless durable.
+	durability       *durability    // nil unless durable.
 }
 
 // Ensures that [MemoryBackend] implements the [storage.OpenFGADatastore] interface.
 var _ storage.OpenFGADatastore = (*MemoryBackend)(nil)
 
+// Ensures that [MemoryBackend] implements the [storage.ChangelogWatcher] interface.
+var _ storage.ChangelogWatcher = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.TupleImporter] interface.
+var _ storage.TupleImporter = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.TupleReaper] interface.
+var _ storage.TupleReaper = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.ChangelogPruner] interface.
+var _ storage.ChangelogPruner = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.StoreUndeleter] interface.
+var _ storage.StoreUndeleter = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.StorePurger] interface.
+var _ storage.StorePurger = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.StoreLabeler] interface.
+var _ storage.StoreLabeler = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.TupleCounter] interface.
+var _ storage.TupleCounter = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.EventOutbox] interface.
+var _ storage.EventOutbox = (*MemoryBackend)(nil)
+
+// Ensures that [MemoryBackend] implements the [storage.RecordCopier] interface.
+var _ storage.RecordCopier = (*MemoryBackend)(nil)
+
 // AuthorizationModelEntry represents an entry in a storage system
 // that holds information about an authorization model.
 type AuthorizationModelEntry struct {
@@ -160,15 +210,25 @@ type AuthorizationModelEntry struct {
 
 // New creates a new [MemoryBackend] given the options.
 func New(opts ...StorageOption) storage.OpenFGADatastore {
+	return newMemoryBackend(opts...)
+}
+
+func newMemoryBackend(opts ...StorageOption) *MemoryBackend {
 	ds := &MemoryBackend{
 		maxTuplesPerWrite:             defaultMaxTuplesPerWrite,
 		maxTypesPerAuthorizationModel: defaultMaxTypesPerAuthorizationModel,
 		tuples:                        make(map[string][]*storage.TupleRecord, 0),
 		changes:                       make(map[string][]*tupleChangeRec, 0),
+		changelogHorizons:             make(map[string]ulid.ULID),
 		authorizationModels:           make(map[string]map[string]*AuthorizationModelEntry),
 		stores:                        make(map[string]*openfgav1.Store, 0),
+		storeLabels:                   make(map[string]map[string]string),
 		assertions:                    make(map[string][]*openfgav1.Assertion, 0),
 		tokenSerializer:               encoder.NewStringContinuationTokenSerializer(),
+		changelogBroadcaster:          storage.NewChangelogBroadcaster(),
+		fsyncPolicy:                   DefaultFsyncPolicy,
+		snapshotInterval:              DefaultSnapshotInterval,
+		logger:                        logger.NewNoopLogger(),
 	}
 
 	for _, opt := range opts {
@@ -197,8 +257,18 @@ func WithContinuationTokenSerializer(tokenSerializer encoder.ContinuationTokenSe
 	return func(ds *MemoryBackend) { ds.tokenSerializer = tokenSerializer }
 }
 
-// Close does not do anything for [MemoryBackend].
-func (s *MemoryBackend) Close() {}
+// WithOutbox returns a [StorageOption] that enables recording the changes in the outbox, see [storage.EventOutbox].
+func WithOutbox() StorageOption {
+	return func(ds *MemoryBackend) { ds.outboxEnabled = true }
+}
+
+// Close does not do anything for an ephemeral [MemoryBackend]. A durable one takes a last snapshot and closes its
+// write-ahead log.
+func (s *MemoryBackend) Close() {
+	if s.wal != nil {
+		s.closeDurable()
+	}
+}
 
 // Read see [storage.RelationshipTupleReader].Read.
 func (s *MemoryBackend) Read(ctx context.Context, store string, key *openfgav1.TupleKey, _ storage.ReadOptions) (storage.TupleIterator, error) {
@@ -221,6 +291,11 @@ func (s *MemoryBackend) ReadPage(ctx context.Context, store string, key *openfga
 	return it.ToArray(ctx)
 }
 
+// WatchChangelog see [storage.ChangelogWatcher].WatchChangelog.
+func (s *MemoryBackend) WatchChangelog(ctx context.Context, store string) (<-chan struct{}, error) {
+	return s.changelogBroadcaster.Watch(ctx, store), nil
+}
+
 // ReadChanges see [storage.ChangelogBackend].ReadChanges.
 func (s *MemoryBackend) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, []byte, error) {
 	_, span := tracer.Start(ctx, "memory.ReadChanges")
@@ -243,6 +318,10 @@ func (s *MemoryBackend) ReadChanges(ctx context.Context, store string, filter st
 			return nil, nil, storage.ErrInvalidContinuationToken
 		}
 		from = &parsed
+
+		if horizon, ok := s.changelogHorizons[store]; ok && from.Compare(horizon) < 0 {
+			return nil, nil, storage.ErrContinuationTokenExpired
+		}
 	}
 
 	objectType := filter.ObjectType
@@ -311,15 +390,11 @@ func (s *MemoryBackend) read(ctx context.Context, store string, tk *openfgav1.Tu
 	s.mutexTuples.RLock()
 	defer s.mutexTuples.RUnlock()
 
+	now := time.Now()
 	var matches []*storage.TupleRecord
-	if tk.GetObject() == "" && tk.GetRelation() == "" && tk.GetUser() == "" {
-		matches = make([]*storage.TupleRecord, len(s.tuples[store]))
-		copy(matches, s.tuples[store])
-	} else {
-		for _, t := range s.tuples[store] {
-			if match(t, tk) {
-				matches = append(matches, t)
-			}
+	for _, t := range s.tuples[store] {
+		if match(t, tk) && !t.IsExpired(now) {
+			matches = append(matches, t)
 		}
 	}
 
@@ -354,7 +429,7 @@ type tupleChangeRec struct {
 }
 
 // Write see [storage.RelationshipTupleWriter].Write.
-func (s *MemoryBackend) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes) error {
+func (s *MemoryBackend) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
 	_, span := tracer.Start(ctx, "memory.Write")
 	defer span.End()
 
@@ -362,21 +437,32 @@ func (s *MemoryBackend) Write(ctx context.Context, store string, deletes storage
 	defer s.mutexTuples.Unlock()
 
 	now := timestamppb.Now()
+	options := storage.NewTupleWriteOptions(opts...)
 
-	if err := validateTuples(s.tuples[store], deletes, writes); err != nil {
+	// expired tuples are deleted first, so that they can be written again
+	mutation := s.expireTuples(store, now, -1)
+	deletes, writes, err := validateTuples(mutation.kept, deletes, writes, options)
+	if err != nil {
 		return err
 	}
 
+	var expiresAt *time.Time
+	if !options.ExpiresAt.IsZero() {
+		expiresAt = &options.ExpiresAt
+	}
+
 	var records []*storage.TupleRecord
+	changes := expiredChanges(mutation)
 	entropy := ulid.DefaultEntropy()
 Delete:
-	for _, tr := range s.tuples[store] {
+	for _, tr := range mutation.kept {
 		t := tr.AsTuple()
 		tk := t.GetKey()
 		for _, k := range deletes {
 			if match(tr, tupleUtils.TupleKeyWithoutConditionToTupleKey(k)) {
-				s.changes[store] = append(
-					s.changes[store],
+				mutation.Deleted = append(mutation.Deleted, tr)
+				mutation.Changes = append(
+					mutation.Changes,
 					&tupleChangeRec{
 						Change: &openfgav1.TupleChange{
 							TupleKey:  tupleUtils.NewTupleKey(tk.GetObject(), tk.GetRelation(), tk.GetUser()), // Redact the condition info.
@@ -386,6 +472,7 @@ Delete:
 						Ulid: ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy),
 					},
 				)
+				changes = append(changes, storage.NewTupleDeleteChange(k))
 				continue Delete
 			}
 		}
@@ -409,7 +496,7 @@ Write:
 
 		objectType, objectID := tupleUtils.SplitObject(t.GetObject())
 
-		records = append(records, &storage.TupleRecord{
+		record := &storage.TupleRecord{
 			Store:            store,
 			ObjectType:       objectType,
 			ObjectID:         objectID,
@@ -419,7 +506,10 @@ Write:
 			ConditionContext: conditionContext,
 			Ulid:             ulid.MustNew(ulid.Timestamp(now.AsTime()), ulid.DefaultEntropy()).String(),
 			InsertedAt:       now.AsTime(),
-		})
+			ExpiresAt:        expiresAt,
+		}
+		records = append(records, record)
+		mutation.Written = append(mutation.Written, record)
 
 		tk := tupleUtils.NewTupleKeyWithCondition(
 			tupleUtils.BuildObject(objectType, objectID),
@@ -429,7 +519,7 @@ Write:
 			conditionContext,
 		)
 
-		s.changes[store] = append(s.changes[store], &tupleChangeRec{
+		mutation.Changes = append(mutation.Changes, &tupleChangeRec{
 			Change: &openfgav1.TupleChange{
 				TupleKey:  tk,
 				Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
@@ -437,37 +527,286 @@ Write:
 			},
 			Ulid: ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy),
 		})
+		changes = append(changes, storage.NewTupleWriteChange(t))
+	}
+
+	event := s.outboxEvent(storage.NewTuplesWrittenEvent(store, changes))
+	if err := s.log(&walEntry{Op: walOpTuples, Store: store, Tuples: mutation, Event: event}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
 	}
 	s.tuples[store] = records
+	s.changes[store] = append(s.changes[store], mutation.Changes...)
+	s.applyOutboxEvent(event)
+	if len(mutation.Changes) > 0 {
+		options.SetCommittedRevision(mutation.Changes[len(mutation.Changes)-1].Ulid)
+	}
+	s.changelogBroadcaster.Notify(store)
 	return nil
 }
 
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples.
+func (s *MemoryBackend) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	_, span := tracer.Start(ctx, "memory.DeleteExpiredTuples")
+	defer span.End()
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+
+	deleted := 0
+	for store := range s.tuples {
+		if deleted == limit {
+			break
+		}
+
+		mutation := s.expireTuples(store, timestamppb.New(now), limit-deleted)
+		if len(mutation.Deleted) == 0 {
+			continue
+		}
+
+		event := s.outboxEvent(storage.NewTuplesExpiredEvent(store, expiredChanges(mutation)))
+		if err := s.log(&walEntry{Op: walOpTuples, Store: store, Tuples: mutation, Event: event}); err != nil {
+			telemetry.TraceError(span, err)
+			return deleted, err
+		}
+		s.applyTupleMutation(store, mutation)
+		s.applyOutboxEvent(event)
+		s.changelogBroadcaster.Notify(store)
+		deleted += len(mutation.Deleted)
+	}
+	return deleted, nil
+}
+
+// tupleMutation is a change of the tuples of a store: the tuples deleted and written, along with their changes.
+type tupleMutation struct {
+	Deleted []*storage.TupleRecord
+	Written []*storage.TupleRecord
+	Changes []*tupleChangeRec
+
+	// kept are the tuples of the store which aren't deleted.
+	kept []*storage.TupleRecord
+}
+
+// expireTuples returns the deletion of up to limit tuples of the store which expired at or before now, or all of
+// them if limit is negative, without applying it. It must be called with mutexTuples locked.
+func (s *MemoryBackend) expireTuples(store string, now *timestamppb.Timestamp, limit int) *tupleMutation {
+	mutation := &tupleMutation{kept: make([]*storage.TupleRecord, 0, len(s.tuples[store]))}
+	entropy := ulid.DefaultEntropy()
+	for _, tr := range s.tuples[store] {
+		if len(mutation.Deleted) == limit || !tr.IsExpired(now.AsTime()) {
+			mutation.kept = append(mutation.kept, tr)
+			continue
+		}
+
+		mutation.Deleted = append(mutation.Deleted, tr)
+		mutation.Changes = append(mutation.Changes, &tupleChangeRec{
+			Change: &openfgav1.TupleChange{
+				TupleKey:  tupleUtils.NewTupleKey(tupleUtils.BuildObject(tr.ObjectType, tr.ObjectID), tr.Relation, tr.User),
+				Operation: openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+				Timestamp: now,
+			},
+			Ulid: ulid.MustNew(ulid.Timestamp(now.AsTime()), entropy),
+		})
+	}
+
+	return mutation
+}
+
+// expiredChanges returns the changes of the expired tuples deleted by the mutation, for the outbox.
+func expiredChanges(mutation *tupleMutation) []storage.EventTupleChange {
+	changes := make([]storage.EventTupleChange, 0, len(mutation.Deleted))
+	for _, tr := range mutation.Deleted {
+		changes = append(changes, storage.NewTupleDeleteChange(tr.AsTuple().GetKey()))
+	}
+	return changes
+}
+
+// applyTupleMutation removes the deleted tuples from the store, then adds the written ones and records the changes.
+// It must be called with mutexTuples locked.
+func (s *MemoryBackend) applyTupleMutation(store string, mutation *tupleMutation) {
+	if len(mutation.Deleted) > 0 {
+		deleted := make(map[string]struct{}, len(mutation.Deleted))
+		for _, tr := range mutation.Deleted {
+			deleted[tupleUtils.TupleKeyToString(tr.AsTuple().GetKey())] = struct{}{}
+		}
+
+		records := make([]*storage.TupleRecord, 0, len(s.tuples[store]))
+		for _, tr := range s.tuples[store] {
+			if _, ok := deleted[tupleUtils.TupleKeyToString(tr.AsTuple().GetKey())]; !ok {
+				records = append(records, tr)
+			}
+		}
+		s.tuples[store] = records
+	}
+
+	s.tuples[store] = append(s.tuples[store], mutation.Written...)
+
+	// the changes imported (see ImportRecords) may be older than the last change of the changelog
+	changes := s.changes[store]
+	n := len(changes)
+	changes = append(changes, mutation.Changes...)
+	if !slices.IsSortedFunc(changes[max(n-1, 0):], compareChanges) {
+		slices.SortFunc(changes, compareChanges)
+	}
+	s.changes[store] = changes
+}
+
+func compareChanges(a, b *tupleChangeRec) int {
+	return a.Ulid.Compare(b.Ulid)
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *MemoryBackend) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	_, span := tracer.Start(ctx, "memory.PruneChanges")
+	defer span.End()
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+
+	changes := s.changes[store]
+	var cutoff ulid.ULID
+	if !olderThan.IsZero() {
+		cutoff = storage.ChangesBefore(olderThan)
+	}
+	if keepCount > 0 && len(changes) > keepCount {
+		if oldestKept := changes[len(changes)-keepCount].Ulid; oldestKept.Compare(cutoff) > 0 {
+			cutoff = oldestKept
+		}
+	}
+
+	var ulids []ulid.ULID
+	for _, change := range changes {
+		if len(ulids) == limit || change.Ulid.Compare(cutoff) >= 0 {
+			break
+		}
+		ulids = append(ulids, change.Ulid)
+	}
+	if len(ulids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.log(&walEntry{Op: walOpDeleteChanges, Store: store, DeletedChanges: ulids}); err != nil {
+		return 0, err
+	}
+	s.applyDeletedChanges(store, ulids)
+
+	return len(ulids), nil
+}
+
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (s *MemoryBackend) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	_, span := tracer.Start(ctx, "memory.CompactChanges")
+	defer span.End()
+
+	if olderThan.IsZero() {
+		return 0, nil
+	}
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+
+	cutoff := storage.ChangesBefore(olderThan)
+	collapsible := storage.NewCollapsibleChanges()
+	for _, change := range s.changes[store] {
+		if collapsible.Len() == limit || change.Ulid.Compare(cutoff) >= 0 {
+			break
+		}
+		collapsible.Add(change.Ulid.String(), change.Change.GetTupleKey(), change.Change.GetOperation())
+	}
+
+	collapsed, _ := collapsible.ULIDs(limit)
+	if len(collapsed) == 0 {
+		return 0, nil
+	}
+
+	ulids := make([]ulid.ULID, 0, len(collapsed))
+	for _, id := range collapsed {
+		ulids = append(ulids, ulid.MustParse(id))
+	}
+
+	if err := s.log(&walEntry{Op: walOpDeleteChanges, Store: store, DeletedChanges: ulids}); err != nil {
+		return 0, err
+	}
+	s.applyDeletedChanges(store, ulids)
+
+	return len(ulids) / 2, nil
+}
+
+// applyDeletedChanges deletes the changes from the changelog of the store, and moves its horizon past them. It must
+// be called with mutexTuples locked.
+func (s *MemoryBackend) applyDeletedChanges(store string, ulids []ulid.ULID) {
+	deleted := make(map[ulid.ULID]struct{}, len(ulids))
+	horizon := s.changelogHorizons[store]
+	for _, id := range ulids {
+		deleted[id] = struct{}{}
+		if id.Compare(horizon) > 0 {
+			horizon = id
+		}
+	}
+	s.changelogHorizons[store] = horizon
+
+	changes := make([]*tupleChangeRec, 0, len(s.changes[store]))
+	for _, change := range s.changes[store] {
+		if _, ok := deleted[change.Ulid]; !ok {
+			changes = append(changes, change)
+		}
+	}
+	s.changes[store] = changes
+}
+
+// validateTuples checks the preconditions, and returns the deletes and writes to apply once the ones
+// to ignore are removed.
 func validateTuples(
 	records []*storage.TupleRecord,
 	deletes []*openfgav1.TupleKeyWithoutCondition,
 	writes []*openfgav1.TupleKey,
-) error {
+	options storage.TupleWriteOptions,
+) ([]*openfgav1.TupleKeyWithoutCondition, []*openfgav1.TupleKey, error) {
+	for _, precondition := range options.Preconditions {
+		if find(records, tupleUtils.TupleKeyWithoutConditionToTupleKey(precondition.TupleKey)) != precondition.Exists {
+			return nil, nil, storage.PreconditionFailedError(precondition)
+		}
+	}
+
+	var deletesToApply []*openfgav1.TupleKeyWithoutCondition
 	for _, tk := range deletes {
 		if !find(records, tupleUtils.TupleKeyWithoutConditionToTupleKey(tk)) {
-			return storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE)
+			if options.OnMissingDelete == storage.OnMissingDeleteIgnore {
+				continue
+			}
+			return nil, nil, storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE)
 		}
+		deletesToApply = append(deletesToApply, tk)
 	}
+
+	var writesToApply []*openfgav1.TupleKey
 	for _, tk := range writes {
-		if find(records, tk) {
-			return storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+		if record := findRecord(records, tk); record != nil {
+			if options.OnDuplicateInsert != storage.OnDuplicateInsertIgnore {
+				return nil, nil, storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
+			}
+			if !record.HasCondition(tk.GetCondition()) {
+				return nil, nil, storage.DuplicateWithDifferentConditionError(tk)
+			}
+			continue
 		}
+		writesToApply = append(writesToApply, tk)
 	}
-	return nil
+	return deletesToApply, writesToApply, nil
 }
 
 // find returns true if there is any [*storage.TupleRecord] for which match returns true.
 func find(records []*storage.TupleRecord, tupleKey *openfgav1.TupleKey) bool {
+	return findRecord(records, tupleKey) != nil
+}
+
+func findRecord(records []*storage.TupleRecord, tupleKey *openfgav1.TupleKey) *storage.TupleRecord {
 	for _, tr := range records {
 		if match(tr, tupleKey) {
-			return true
+			return tr
 		}
 	}
-	return false
+	return nil
 }
 
 // ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
@@ -478,8 +817,9 @@ func (s *MemoryBackend) ReadUserTuple(ctx context.Context, store string, key *op
 	s.mutexTuples.RLock()
 	defer s.mutexTuples.RUnlock()
 
+	now := time.Now()
 	for _, t := range s.tuples[store] {
-		if match(t, key) {
+		if match(t, key) && !t.IsExpired(now) {
 			return t.AsTuple(), nil
 		}
 	}
@@ -488,6 +828,47 @@ func (s *MemoryBackend) ReadUserTuple(ctx context.Context, store string, key *op
 	return nil, storage.ErrNotFound
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *MemoryBackend) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, _ storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	_, span := tracer.Start(ctx, "memory.ReadTuplesBatch")
+	defer span.End()
+
+	users := make(map[string]struct{}, len(lookups))
+	usersets := make(map[string]struct{}, len(lookups))
+	for _, lookup := range lookups {
+		if lookup.User == "" {
+			usersets[tupleUtils.ToObjectRelationString(lookup.Object, lookup.Relation)] = struct{}{}
+			continue
+		}
+		users[tupleUtils.TupleKeyToString(tupleUtils.NewTupleKey(lookup.Object, lookup.Relation, lookup.User))] = struct{}{}
+	}
+
+	s.mutexTuples.RLock()
+	defer s.mutexTuples.RUnlock()
+
+	now := time.Now()
+	var matches []*openfgav1.Tuple
+	for _, t := range s.tuples[store] {
+		if t.IsExpired(now) {
+			continue
+		}
+
+		tk := tupleUtils.NewTupleKey(tupleUtils.BuildObject(t.ObjectType, t.ObjectID), t.Relation, t.User)
+		if _, ok := users[tupleUtils.TupleKeyToString(tk)]; ok {
+			matches = append(matches, t.AsTuple())
+			continue
+		}
+		if tupleUtils.GetUserTypeFromUser(t.User) != tupleUtils.UserSet {
+			continue
+		}
+		if _, ok := usersets[tupleUtils.ToObjectRelationString(tk.GetObject(), tk.GetRelation())]; ok {
+			matches = append(matches, t.AsTuple())
+		}
+	}
+
+	return matches, nil
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
 func (s *MemoryBackend) ReadUsersetTuples(
 	ctx context.Context,
@@ -501,12 +882,13 @@ func (s *MemoryBackend) ReadUsersetTuples(
 	s.mutexTuples.RLock()
 	defer s.mutexTuples.RUnlock()
 
+	now := time.Now()
 	var matches []*storage.TupleRecord
 	for _, t := range s.tuples[store] {
 		if match(t, &openfgav1.TupleKey{
 			Object:   filter.Object,
 			Relation: filter.Relation,
-		}) && tupleUtils.GetUserTypeFromUser(t.User) == tupleUtils.UserSet {
+		}) && tupleUtils.GetUserTypeFromUser(t.User) == tupleUtils.UserSet && !t.IsExpired(now) {
 			if len(filter.AllowedUserTypeRestrictions) == 0 { // 1.0 model.
 				matches = append(matches, t)
 				continue
@@ -540,12 +922,17 @@ func (s *MemoryBackend) ReadStartingWithUser(
 	s.mutexTuples.RLock()
 	defer s.mutexTuples.RUnlock()
 
+	now := time.Now()
 	var matches []*storage.TupleRecord
 	for _, t := range s.tuples[store] {
 		if t.ObjectType != filter.ObjectType {
 			continue
 		}
 
+		if t.IsExpired(now) {
+			continue
+		}
+
 		if t.Relation != filter.Relation {
 			continue
 		}
@@ -698,6 +1085,19 @@ func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store strin
 	s.mutexModels.Lock()
 	defer s.mutexModels.Unlock()
 
+	event := s.outboxEvent(storage.NewAuthorizationModelWrittenEvent(store, model.GetId()))
+	if err := s.log(&walEntry{Op: walOpWriteAuthorizationModel, Store: store, AuthorizationModel: model, Event: event}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	s.applyAuthorizationModel(store, model)
+	s.applyOutboxEvent(event)
+
+	return nil
+}
+
+// applyAuthorizationModel adds the model to the store as its latest one. It must be called with mutexModels locked.
+func (s *MemoryBackend) applyAuthorizationModel(store string, model *openfgav1.AuthorizationModel) {
 	if _, ok := s.authorizationModels[store]; !ok {
 		s.authorizationModels[store] = make(map[string]*AuthorizationModelEntry)
 	}
@@ -710,8 +1110,6 @@ func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store strin
 		model:  model,
 		latest: true,
 	}
-
-	return nil
 }
 
 // CreateStore adds a new store to the [MemoryBackend].
@@ -719,6 +1117,28 @@ func (s *MemoryBackend) CreateStore(ctx context.Context, newStore *openfgav1.Sto
 	_, span := tracer.Start(ctx, "memory.CreateStore")
 	defer span.End()
 
+	store, err := s.createStore(newStore, nil)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return store, nil
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels.
+func (s *MemoryBackend) CreateStoreWithLabels(ctx context.Context, newStore *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	_, span := tracer.Start(ctx, "memory.CreateStoreWithLabels")
+	defer span.End()
+
+	store, err := s.createStore(newStore, labels)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *MemoryBackend) createStore(newStore *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
 	s.mutexStores.Lock()
 	defer s.mutexStores.Unlock()
 
@@ -727,17 +1147,91 @@ func (s *MemoryBackend) CreateStore(ctx context.Context, newStore *openfgav1.Sto
 	}
 
 	now := timestamppb.New(time.Now().UTC())
-	s.stores[newStore.GetId()] = &openfgav1.Store{
+	store := &openfgav1.Store{
 		Id:        newStore.GetId(),
 		Name:      newStore.GetName(),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	return s.stores[newStore.GetId()], nil
+	event := s.outboxEvent(storage.NewStoreCreatedEvent(store))
+	if err := s.log(&walEntry{Op: walOpCreateStore, Store: store.GetId(), StoreData: store, StoreLabels: labels, Event: event}); err != nil {
+		return nil, err
+	}
+	s.stores[store.GetId()] = store
+	s.applyStoreLabels(store.GetId(), labels)
+	s.applyOutboxEvent(event)
+
+	return store, nil
 }
 
-// DeleteStore removes a store from the [MemoryBackend].
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (s *MemoryBackend) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	_, span := tracer.Start(ctx, "memory.UpdateStore")
+	defer span.End()
+
+	s.mutexStores.Lock()
+	defer s.mutexStores.Unlock()
+
+	store := s.stores[id]
+	if store == nil || store.GetDeletedAt() != nil {
+		return nil, storage.ErrNotFound
+	}
+
+	updated := &openfgav1.Store{
+		Id:        store.GetId(),
+		Name:      store.GetName(),
+		CreatedAt: store.GetCreatedAt(),
+		UpdatedAt: timestamppb.New(time.Now().UTC()),
+	}
+	if name != "" {
+		updated.Name = name
+	}
+	if labels == nil {
+		labels = s.storeLabels[id]
+	}
+
+	// the entry holds all the labels, so that replaying it doesn't depend on those before
+	if err := s.log(&walEntry{Op: walOpUpdateStore, Store: id, StoreData: updated, StoreLabels: labels}); err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	s.stores[id] = updated
+	s.applyStoreLabels(id, labels)
+
+	return updated, nil
+}
+
+// applyStoreLabels replaces the labels of the store. It must be called with mutexStores locked.
+func (s *MemoryBackend) applyStoreLabels(store string, labels map[string]string) {
+	if len(labels) == 0 {
+		delete(s.storeLabels, store)
+		return
+	}
+	s.storeLabels[store] = maps.Clone(labels)
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (s *MemoryBackend) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	_, span := tracer.Start(ctx, "memory.ReadStoreLabels")
+	defer span.End()
+
+	s.mutexStores.RLock()
+	defer s.mutexStores.RUnlock()
+
+	if s.stores[id] == nil || s.stores[id].GetDeletedAt() != nil {
+		return nil, storage.ErrNotFound
+	}
+
+	labels := maps.Clone(s.storeLabels[id])
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return labels, nil
+}
+
+// DeleteStore removes a store from the [MemoryBackend]. The store is soft-deleted: its data is kept until it is
+// purged (see PurgeStore).
 func (s *MemoryBackend) DeleteStore(ctx context.Context, id string) error {
 	_, span := tracer.Start(ctx, "memory.DeleteStore")
 	defer span.End()
@@ -745,10 +1239,160 @@ func (s *MemoryBackend) DeleteStore(ctx context.Context, id string) error {
 	s.mutexStores.Lock()
 	defer s.mutexStores.Unlock()
 
-	delete(s.stores, id)
+	store := s.stores[id]
+	if store == nil || store.GetDeletedAt() != nil {
+		return nil
+	}
+
+	deleted := &openfgav1.Store{
+		Id:        store.GetId(),
+		Name:      store.GetName(),
+		CreatedAt: store.GetCreatedAt(),
+		UpdatedAt: store.GetUpdatedAt(),
+		DeletedAt: timestamppb.New(time.Now().UTC()),
+	}
+	event := s.outboxEvent(storage.NewStoreDeletedEvent(id))
+	if err := s.log(&walEntry{Op: walOpDeleteStore, Store: id, StoreData: deleted, Event: event}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	s.stores[id] = deleted
+	s.applyOutboxEvent(event)
 	return nil
 }
 
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (s *MemoryBackend) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	_, span := tracer.Start(ctx, "memory.UndeleteStore")
+	defer span.End()
+
+	s.mutexStores.Lock()
+	defer s.mutexStores.Unlock()
+
+	store := s.stores[id]
+	if store == nil || (store.GetDeletedAt() != nil && store.GetDeletedAt().AsTime().Before(deletedAfter)) {
+		return nil, storage.ErrNotFound
+	}
+	if store.GetDeletedAt() == nil {
+		return store, nil
+	}
+
+	restored := &openfgav1.Store{
+		Id:        store.GetId(),
+		Name:      store.GetName(),
+		CreatedAt: store.GetCreatedAt(),
+		UpdatedAt: timestamppb.New(time.Now().UTC()),
+	}
+	if err := s.log(&walEntry{Op: walOpUndeleteStore, Store: id, StoreData: restored}); err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+	s.stores[id] = restored
+	return restored, nil
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores.
+func (s *MemoryBackend) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	_, span := tracer.Start(ctx, "memory.ListDeletedStores")
+	defer span.End()
+
+	s.mutexStores.RLock()
+	defer s.mutexStores.RUnlock()
+
+	var deleted []*openfgav1.Store
+	for _, store := range s.stores {
+		if store.GetDeletedAt() != nil && store.GetDeletedAt().AsTime().Before(deletedBefore) {
+			deleted = append(deleted, store)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].GetDeletedAt().AsTime().Before(deleted[j].GetDeletedAt().AsTime())
+	})
+
+	ids := make([]string, 0, min(len(deleted), limit))
+	for _, store := range deleted[:min(len(deleted), limit)] {
+		ids = append(ids, store.GetId())
+	}
+	return ids, nil
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore.
+func (s *MemoryBackend) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	_, span := tracer.Start(ctx, "memory.PurgeStore")
+	defer span.End()
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+	s.mutexStores.Lock()
+	defer s.mutexStores.Unlock()
+	s.mutexAssertions.Lock()
+	defer s.mutexAssertions.Unlock()
+
+	store := s.stores[id]
+	if store.GetDeletedAt() == nil || !store.GetDeletedAt().AsTime().Before(deletedBefore) {
+		return 0, nil
+	}
+
+	if err := s.log(&walEntry{Op: walOpPurgeStore, Store: id, PurgeLimit: limit}); err != nil {
+		telemetry.TraceError(span, err)
+		return 0, err
+	}
+	return s.applyPurgeStore(id, limit), nil
+}
+
+// applyPurgeStore deletes up to limit of the tuples, changes, authorization models and assertions of the store, in
+// this order, then the store itself once none are left, and returns how many were deleted. It must be called with
+// all the mutexes locked.
+func (s *MemoryBackend) applyPurgeStore(store string, limit int) int {
+	deleted := 0
+	take := func(n int) int {
+		n = min(n, limit-deleted)
+		deleted += n
+		return n
+	}
+
+	if tuples := s.tuples[store]; len(tuples) > 0 {
+		s.tuples[store] = tuples[take(len(tuples)):]
+	}
+	if changes := s.changes[store]; len(changes) > 0 {
+		s.changes[store] = changes[take(len(changes)):]
+	}
+
+	// in the order of their IDs, so that replaying the purge deletes the same ones
+	modelIDs := make([]string, 0, len(s.authorizationModels[store]))
+	for modelID := range s.authorizationModels[store] {
+		modelIDs = append(modelIDs, modelID)
+	}
+	slices.Sort(modelIDs)
+	for _, modelID := range modelIDs[:take(len(modelIDs))] {
+		delete(s.authorizationModels[store], modelID)
+	}
+
+	var assertionsIDs []string
+	for assertionsID := range s.assertions {
+		if strings.HasPrefix(assertionsID, store+"|") {
+			assertionsIDs = append(assertionsIDs, assertionsID)
+		}
+	}
+	slices.Sort(assertionsIDs)
+	for _, assertionsID := range assertionsIDs[:take(len(assertionsIDs))] {
+		delete(s.assertions, assertionsID)
+	}
+
+	if deleted < limit {
+		delete(s.tuples, store)
+		delete(s.changes, store)
+		delete(s.changelogHorizons, store)
+		delete(s.authorizationModels, store)
+		delete(s.stores, store)
+		delete(s.storeLabels, store)
+	}
+
+	return deleted
+}
+
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
 func (s *MemoryBackend) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	_, span := tracer.Start(ctx, "memory.WriteAssertions")
@@ -757,6 +1401,10 @@ func (s *MemoryBackend) WriteAssertions(ctx context.Context, store, modelID stri
 	s.mutexAssertions.Lock()
 	defer s.mutexAssertions.Unlock()
 
+	if err := s.log(&walEntry{Op: walOpWriteAssertions, Store: store, AuthorizationModelID: modelID, Assertions: assertions}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
 	assertionsID := fmt.Sprintf("%s|%s", store, modelID)
 	s.assertions[assertionsID] = assertions
 
@@ -779,6 +1427,262 @@ func (s *MemoryBackend) ReadAssertions(ctx context.Context, store, modelID strin
 	return assertions, nil
 }
 
+// ImportTuples see [storage.TupleImporter].ImportTuples.
+func (s *MemoryBackend) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ctx, span := tracer.Start(ctx, "memory.ImportTuples")
+	defer span.End()
+
+	if len(writes) > s.MaxTuplesPerImport() {
+		return storage.ErrExceededWriteBatchLimit
+	}
+
+	return s.Write(ctx, store, nil, writes)
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox.
+func (s *MemoryBackend) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	_, span := tracer.Start(ctx, "memory.ReadOutbox")
+	defer span.End()
+
+	s.mutexOutbox.Lock()
+	defer s.mutexOutbox.Unlock()
+
+	return slices.Clone(s.outbox[:min(limit, len(s.outbox))]), nil
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents.
+func (s *MemoryBackend) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	_, span := tracer.Start(ctx, "memory.DeleteOutboxEvents")
+	defer span.End()
+
+	s.mutexOutbox.Lock()
+	defer s.mutexOutbox.Unlock()
+
+	if err := s.log(&walEntry{Op: walOpDeleteOutboxEvents, DeletedEvents: ids}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	s.applyDeletedOutboxEvents(ids)
+	return nil
+}
+
+// outboxEvent returns the event if the outbox is enabled, and nil otherwise.
+func (s *MemoryBackend) outboxEvent(event *storage.Event) *storage.Event {
+	if !s.outboxEnabled {
+		return nil
+	}
+	return event
+}
+
+// applyOutboxEvent adds the event to the outbox, unless it is nil.
+func (s *MemoryBackend) applyOutboxEvent(event *storage.Event) {
+	if event == nil {
+		return
+	}
+
+	s.mutexOutbox.Lock()
+	defer s.mutexOutbox.Unlock()
+	s.outbox = append(s.outbox, event)
+}
+
+// applyDeletedOutboxEvents removes the events with the IDs from the outbox. It must be called with mutexOutbox
+// locked.
+func (s *MemoryBackend) applyDeletedOutboxEvents(ids []string) {
+	deleted := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		deleted[id] = struct{}{}
+	}
+
+	s.outbox = slices.DeleteFunc(s.outbox, func(event *storage.Event) bool {
+		_, ok := deleted[event.ID]
+		return ok
+	})
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples. The tuples of a store are held in a slice, whose length is
+// their count.
+func (s *MemoryBackend) CountTuples(ctx context.Context, store string) (int64, error) {
+	_, span := tracer.Start(ctx, "memory.CountTuples")
+	defer span.End()
+
+	s.mutexTuples.RLock()
+	defer s.mutexTuples.RUnlock()
+
+	return int64(len(s.tuples[store])), nil
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords. The tuples are ordered by
+// [storage.CompareTupleRecords], the continuation token being the last one of the page, as a string.
+func (s *MemoryBackend) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	_, span := tracer.Start(ctx, "memory.ReadTupleRecords")
+	defer span.End()
+
+	var from *storage.TupleRecord
+	if options.From != "" {
+		tk, err := tupleUtils.ParseTupleString(options.From)
+		if err != nil {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		from = &storage.TupleRecord{ObjectType: objectType, ObjectID: objectID, Relation: tk.GetRelation(), User: tk.GetUser()}
+	}
+
+	s.mutexTuples.RLock()
+	records := make([]*storage.TupleRecord, 0, len(s.tuples[store]))
+	for _, t := range s.tuples[store] {
+		if from == nil || storage.CompareTupleRecords(t, from) > 0 {
+			record := *t
+			records = append(records, &record)
+		}
+	}
+	s.mutexTuples.RUnlock()
+
+	slices.SortFunc(records, storage.CompareTupleRecords)
+	if options.PageSize > 0 && options.PageSize < len(records) {
+		records = records[:options.PageSize]
+		return records, []byte(tupleUtils.TupleKeyToString(records[len(records)-1].AsTuple().GetKey())), nil
+	}
+	return records, nil, nil
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (s *MemoryBackend) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	_, span := tracer.Start(ctx, "memory.ReadTupleRecord")
+	defer span.End()
+
+	s.mutexTuples.RLock()
+	defer s.mutexTuples.RUnlock()
+
+	if record := s.findTuple(store, key); record != nil {
+		found := *record
+		return &found, nil
+	}
+	return nil, storage.ErrNotFound
+}
+
+// findTuple returns the tuple of the store with the object, relation and user of the key, expired or not, or nil if
+// there is none. It must be called with mutexTuples locked.
+func (s *MemoryBackend) findTuple(store string, key *openfgav1.TupleKey) *storage.TupleRecord {
+	for _, t := range s.tuples[store] {
+		if match(t, tupleUtils.NewTupleKey(key.GetObject(), key.GetRelation(), key.GetUser())) {
+			return t
+		}
+	}
+	return nil
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (s *MemoryBackend) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	_, span := tracer.Start(ctx, "memory.ReadChangeRecords")
+	defer span.End()
+
+	s.mutexTuples.RLock()
+	defer s.mutexTuples.RUnlock()
+
+	changes := s.changes[store]
+	if after != "" {
+		id, err := ulid.Parse(after)
+		if err != nil {
+			return nil, storage.ErrInvalidContinuationToken
+		}
+		start, found := slices.BinarySearchFunc(changes, id, func(change *tupleChangeRec, id ulid.ULID) int {
+			return change.Ulid.Compare(id)
+		})
+		if found {
+			start++
+		}
+		changes = changes[start:]
+	}
+	if limit > 0 && limit < len(changes) {
+		changes = changes[:limit]
+	}
+
+	records := make([]*storage.ChangeRecord, 0, len(changes))
+	for _, change := range changes {
+		records = append(records, &storage.ChangeRecord{
+			Ulid:       change.Ulid.String(),
+			Operation:  change.Change.GetOperation(),
+			TupleKey:   change.Change.GetTupleKey(),
+			InsertedAt: change.Change.GetTimestamp().AsTime(),
+		})
+	}
+	return records, nil
+}
+
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (s *MemoryBackend) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	_, span := tracer.Start(ctx, "memory.ImportRecords")
+	defer span.End()
+
+	s.mutexTuples.Lock()
+	defer s.mutexTuples.Unlock()
+
+	mutation := &tupleMutation{}
+	for _, change := range records.Changes {
+		id, err := ulid.Parse(change.Ulid)
+		if err != nil {
+			return fmt.Errorf("invalid change ULID '%s': %w", change.Ulid, err)
+		}
+		_, found := slices.BinarySearchFunc(s.changes[store], id, func(change *tupleChangeRec, id ulid.ULID) int {
+			return change.Ulid.Compare(id)
+		})
+		if found {
+			continue
+		}
+
+		mutation.Changes = append(mutation.Changes, &tupleChangeRec{
+			Change: &openfgav1.TupleChange{
+				TupleKey:  change.TupleKey,
+				Operation: change.Operation,
+				Timestamp: timestamppb.New(change.InsertedAt),
+			},
+			Ulid: id,
+		})
+	}
+	slices.SortFunc(mutation.Changes, compareChanges)
+
+	for _, tk := range records.Deletes {
+		if existing := s.findTuple(store, tupleUtils.TupleKeyWithoutConditionToTupleKey(tk)); existing != nil {
+			mutation.Deleted = append(mutation.Deleted, existing)
+		}
+	}
+	for _, record := range records.Tuples {
+		tk := record.AsTuple().GetKey()
+		if existing := s.findTuple(store, tk); existing != nil {
+			mutation.Deleted = append(mutation.Deleted, existing)
+		}
+
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		mutation.Written = append(mutation.Written, &storage.TupleRecord{
+			Store:            store,
+			ObjectType:       objectType,
+			ObjectID:         objectID,
+			Relation:         tk.GetRelation(),
+			User:             tk.GetUser(),
+			ConditionName:    record.ConditionName,
+			ConditionContext: record.ConditionContext,
+			Ulid:             record.Ulid,
+			InsertedAt:       record.InsertedAt,
+			ExpiresAt:        record.ExpiresAt,
+		})
+	}
+
+	if err := s.log(&walEntry{Op: walOpTuples, Store: store, Tuples: mutation}); err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	s.applyTupleMutation(store, mutation)
+	if len(mutation.Changes) > 0 {
+		s.changelogBroadcaster.Notify(store)
+	}
+	return nil
+}
+
+// MaxTuplesPerImport see [storage.TupleImporter].MaxTuplesPerImport.
+func (s *MemoryBackend) MaxTuplesPerImport() int {
+	return storage.DefaultMaxTuplesPerImport
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *MemoryBackend) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWrite
@@ -797,7 +1701,7 @@ func (s *MemoryBackend) GetStore(ctx context.Context, storeID string) (*openfgav
 	s.mutexStores.RLock()
 	defer s.mutexStores.RUnlock()
 
-	if s.stores[storeID] == nil {
+	if s.stores[storeID] == nil || s.stores[storeID].GetDeletedAt() != nil {
 		return nil, storage.ErrNotFound
 	}
 
@@ -814,7 +1718,10 @@ func (s *MemoryBackend) ListStores(ctx context.Context, options storage.ListStor
 
 	stores := make([]*openfgav1.Store, 0, len(s.stores))
 	for _, t := range s.stores {
-		stores = append(stores, t)
+		if t.GetDeletedAt() == nil && strings.HasPrefix(t.GetName(), options.NamePrefix) &&
+			options.LabelSelector.Matches(s.storeLabels[t.GetId()]) {
+			stores = append(stores, t)
+		}
 	}
 
 	if len(options.IDs) > 0 {
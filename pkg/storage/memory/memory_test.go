@@ -32,6 +32,11 @@ func TestMemdbStorageWithSqlTokenSerializer(t *testing.T) {
 	t.Run("TestReadChanges", func(t *testing.T) { test.ReadChangesTest(t, ds, tokenSerializer) })
 }
 
+func TestMemdbStorageOutbox(t *testing.T) {
+	ds := newMemoryBackend(WithOutbox())
+	test.EventOutboxTest(t, ds, ds)
+}
+
 func TestStaticTupleIterator(t *testing.T) {
 	t.Run("empty_iterator", func(t *testing.T) {
 		tests := []struct {
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// frameHeaderSize is the size of the header of a frame: the size of its payload, then the CRC-32C checksum of
+	// the payload, both as big-endian uint32.
+	frameHeaderSize = 8
+	// maxFrameSize bounds the size of a payload, so that a corrupted size isn't trusted.
+	maxFrameSize = 1 << 30
+)
+
+// ErrCorrupted is returned by [NewDurable] when the snapshot or the write-ahead log of a durable [MemoryBackend]
+// fails their checksum or can't be decoded.
+var ErrCorrupted = errors.New("memory datastore files are corrupted")
+
+// errTornFrame is returned when a frame is cut short by the end of the file, as when the process crashed while
+// appending it.
+var errTornFrame = errors.New("torn frame")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// encodeFrame returns the payload prefixed by the header of its frame.
+func encodeFrame(payload []byte) []byte {
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(payload, crcTable))
+	return append(frame, payload...)
+}
+
+// readFrame returns the payload of the next frame of r, io.EOF if there is none, or errTornFrame if the frame is
+// incomplete. A frame failing its checksum is reported as ErrCorrupted.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errTornFrame
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrCorrupted, size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errTornFrame
+		}
+		return nil, err
+	}
+
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+
+	return payload, nil
+}
+
+// writeAheadLog is the file to which a durable MemoryBackend appends its changes, as frames holding a walEntry,
+// before applying them.
+type writeAheadLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	policy FsyncPolicy
+	// seq is the sequence number of the last entry appended, or covered by the last snapshot.
+	seq uint64 // GUARDED_BY(mu).
+	// size is the size of the file, up to the end of the last complete frame.
+	size int64 // GUARDED_BY(mu).
+	// dirty reports whether entries were appended since the file was last synced.
+	dirty bool // GUARDED_BY(mu).
+}
+
+// append numbers the entry and appends it to the log. With the FsyncAlways policy, the file is synced before it
+// returns. If it fails, the log is left as it was.
+func (w *writeAheadLog) append(entry *walEntry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entry.Seq = w.seq + 1
+	payload, err := encodeWALEntry(entry)
+	if err != nil {
+		return fmt.Errorf("encode write-ahead log entry: %w", err)
+	}
+
+	frame := encodeFrame(payload)
+	if _, err := w.file.Write(frame); err != nil {
+		// drop the partial frame, so that the next entries aren't appended after it
+		_ = w.file.Truncate(w.size)
+		return fmt.Errorf("append to write-ahead log: %w", err)
+	}
+
+	if w.policy == FsyncAlways {
+		if err := w.file.Sync(); err != nil {
+			_ = w.file.Truncate(w.size)
+			return fmt.Errorf("sync write-ahead log: %w", err)
+		}
+	}
+
+	w.seq = entry.Seq
+	w.size += int64(len(frame))
+	w.dirty = w.policy != FsyncAlways
+	return nil
+}
+
+// sync flushes the entries appended since the last sync to disk.
+func (w *writeAheadLog) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.dirty {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+// reset empties the log once a snapshot covers its entries.
+func (w *writeAheadLog) reset() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	w.size = 0
+	w.dirty = false
+	return nil
+}
+
+func (w *writeAheadLog) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.file.Sync(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
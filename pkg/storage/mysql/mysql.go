@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-sql-driver/mysql"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/pressly/goose/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.opentelemetry.io/otel"
@@ -40,6 +42,7 @@ type Datastore struct {
 	stbl                   sq.StatementBuilderType
 	db                     *sql.DB
 	dbInfo                 *sqlcommon.DBInfo
+	readReplicas           *sqlcommon.ReadReplicas
 	logger                 logger.Logger
 	dbStatsCollector       prometheus.Collector
 	tokenSerializer        encoder.ContinuationTokenSerializer
@@ -50,22 +53,48 @@ type Datastore struct {
 // Ensures that Datastore implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
-// New creates a new [Datastore] storage.
+// Ensures that Datastore implements the TupleImporter interface.
+var _ storage.TupleImporter = (*Datastore)(nil)
+
+// Ensures that Datastore implements the TupleReaper interface.
+var _ storage.TupleReaper = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the StoreUndeleter interface.
+var _ storage.StoreUndeleter = (*Datastore)(nil)
+
+// Ensures that Datastore implements the StorePurger interface.
+var _ storage.StorePurger = (*Datastore)(nil)
+
+// Ensures that Datastore implements the StoreLabeler interface.
+var _ storage.StoreLabeler = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.TupleCounter] interface.
+var _ storage.TupleCounter = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.EventOutbox] interface.
+var _ storage.EventOutbox = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.RecordCopier] interface.
+var _ storage.RecordCopier = (*Datastore)(nil)
+
+// New creates a new [Datastore] storage. The tuple reads are routed to the read replicas of the config, if any
+// (see [sqlcommon.ReadReplicas]).
 func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
-	if cfg.Username != "" || cfg.Password != "" {
-		dsnCfg, err := mysql.ParseDSN(uri)
-		if err != nil {
-			return nil, fmt.Errorf("parse mysql connection dsn: %w", err)
-		}
+	uri, err := withCredentials(uri, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-		if cfg.Username != "" {
-			dsnCfg.User = cfg.Username
-		}
-		if cfg.Password != "" {
-			dsnCfg.Passwd = cfg.Password
+	replicaURIs := make([]string, 0, len(cfg.ReadReplicaURIs))
+	for _, replicaURI := range cfg.ReadReplicaURIs {
+		replicaURI, err := withCredentials(replicaURI, cfg)
+		if err != nil {
+			return nil, err
 		}
-
-		uri = dsnCfg.FormatDSN()
+		replicaURIs = append(replicaURIs, replicaURI)
 	}
 
 	if cfg.TokenSerializer == nil {
@@ -76,11 +105,43 @@ func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 	if err != nil {
 		return nil, fmt.Errorf("initialize mysql connection: %w", err)
 	}
-	return NewWithDB(db, cfg)
+
+	replicas, err := sqlcommon.OpenReadReplicas("mysql", replicaURIs, cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newWithDBs(db, replicas, cfg)
+}
+
+// withCredentials overrides the username and password of the connection dsn with those of the config.
+func withCredentials(uri string, cfg *sqlcommon.Config) (string, error) {
+	if cfg.Username == "" && cfg.Password == "" {
+		return uri, nil
+	}
+
+	dsnCfg, err := mysql.ParseDSN(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse mysql connection dsn: %w", err)
+	}
+
+	if cfg.Username != "" {
+		dsnCfg.User = cfg.Username
+	}
+	if cfg.Password != "" {
+		dsnCfg.Passwd = cfg.Password
+	}
+
+	return dsnCfg.FormatDSN(), nil
 }
 
 // NewWithDB creates a new [Datastore] storage with the provided database connection.
 func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
+	return newWithDBs(db, nil, cfg)
+}
+
+func newWithDBs(db *sql.DB, replicas []*sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 	if cfg.MaxOpenConns != 0 {
 		db.SetMaxOpenConns(cfg.MaxOpenConns)
 	}
@@ -110,6 +171,9 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 		return nil
 	}, policy)
 	if err != nil {
+		for _, replica := range replicas {
+			replica.Close()
+		}
 		return nil, fmt.Errorf("ping db: %w", err)
 	}
 
@@ -121,13 +185,22 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 		}
 	}
 
-	stbl := sq.StatementBuilder.RunWith(db)
+	stbl := newStatementBuilder(db)
 	dbInfo := sqlcommon.NewDBInfo(db, stbl, HandleSQLError)
+	dbInfo.Outbox = cfg.Outbox
+	dbInfo.InsertIgnore = func(builder sq.InsertBuilder) sq.InsertBuilder {
+		return builder.Options("IGNORE")
+	}
+	dbInfo.UpsertTupleCount = func(builder sq.InsertBuilder) sq.InsertBuilder {
+		return builder.Suffix("ON DUPLICATE KEY UPDATE tuple_count = tuple_count + VALUES(tuple_count)")
+	}
+	dbInfo.InsertedAtPrecision = time.Second // inserted_at is a TIMESTAMP
 
 	return &Datastore{
 		stbl:                   stbl,
 		db:                     db,
 		dbInfo:                 dbInfo,
+		readReplicas:           sqlcommon.NewReadReplicas(stbl, replicas, newStatementBuilder, replicaLag, cfg),
 		logger:                 cfg.Logger,
 		tokenSerializer:        cfg.TokenSerializer,
 		dbStatsCollector:       collector,
@@ -136,8 +209,57 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 	}, nil
 }
 
+func newStatementBuilder(db *sql.DB) sq.StatementBuilderType {
+	return sq.StatementBuilder.RunWith(db)
+}
+
+// replicaLag see [sqlcommon.ReplicaLagFunc]. The lag of a replica is the Seconds_Behind_Source of its replication
+// status, which is null when the replication is stopped.
+func replicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		// not a replica
+		return 0, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.Atoi(values[i].String)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, errors.New("missing Seconds_Behind_Source in the replica status")
+}
+
 // Close see [storage.OpenFGADatastore].Close.
 func (s *Datastore) Close() {
+	s.readReplicas.Close()
 	if s.dbStatsCollector != nil {
 		prometheus.Unregister(s.dbStatsCollector)
 	}
@@ -149,12 +271,12 @@ func (s *Datastore) Read(
 	ctx context.Context,
 	store string,
 	tupleKey *openfgav1.TupleKey,
-	_ storage.ReadOptions,
+	options storage.ReadOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "Read")
 	defer span.End()
 
-	return s.read(ctx, store, tupleKey, nil)
+	return s.read(ctx, store, tupleKey, nil, options.Consistency)
 }
 
 // ReadPage see [storage.RelationshipTupleReader].ReadPage.
@@ -167,7 +289,7 @@ func (s *Datastore) ReadPage(
 	ctx, span := startTrace(ctx, "ReadPage")
 	defer span.End()
 
-	iter, err := s.read(ctx, store, tupleKey, &options)
+	iter, err := s.read(ctx, store, tupleKey, &options, options.Consistency)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -176,18 +298,25 @@ func (s *Datastore) ReadPage(
 	return iter.ToArray(options.Pagination)
 }
 
-func (s *Datastore) read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options *storage.ReadPageOptions) (*sqlcommon.SQLTupleIterator, error) {
+func (s *Datastore) read(
+	ctx context.Context,
+	store string,
+	tupleKey *openfgav1.TupleKey,
+	options *storage.ReadPageOptions,
+	consistency storage.ConsistencyOptions,
+) (*sqlcommon.SQLTupleIterator, error) {
 	ctx, span := startTrace(ctx, "read")
 	defer span.End()
 
-	sb := s.stbl.
+	sb := s.readReplicas.StatementBuilder(ctx, consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
 			"condition_name", "condition_context", "ulid", "inserted_at",
 		).
 		From("tuple").
-		Where(sq.Eq{"store": store})
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 	if options != nil {
 		sb = sb.OrderBy("ulid")
 	}
@@ -230,6 +359,7 @@ func (s *Datastore) Write(
 	store string,
 	deletes storage.Deletes,
 	writes storage.Writes,
+	opts ...storage.TupleWriteOption,
 ) error {
 	ctx, span := startTrace(ctx, "Write")
 	defer span.End()
@@ -238,11 +368,28 @@ func (s *Datastore) Write(
 		return storage.ErrExceededWriteBatchLimit
 	}
 
-	return sqlcommon.Write(ctx, s.dbInfo, store, deletes, writes, time.Now().UTC())
+	return sqlcommon.Write(ctx, s.dbInfo, store, deletes, writes, storage.NewTupleWriteOptions(opts...), time.Now().UTC())
+}
+
+// ImportTuples see [storage.TupleImporter].ImportTuples.
+func (s *Datastore) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ctx, span := startTrace(ctx, "ImportTuples")
+	defer span.End()
+
+	if len(writes) > s.MaxTuplesPerImport() {
+		return storage.ErrExceededWriteBatchLimit
+	}
+
+	return sqlcommon.ImportTuples(ctx, s.dbInfo, store, writes, time.Now().UTC())
+}
+
+// MaxTuplesPerImport see [storage.TupleImporter].MaxTuplesPerImport.
+func (s *Datastore) MaxTuplesPerImport() int {
+	return storage.DefaultMaxTuplesPerImport
 }
 
 // ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
-func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, _ storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
 	ctx, span := startTrace(ctx, "ReadUserTuple")
 	defer span.End()
 
@@ -253,7 +400,7 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 	var conditionContext []byte
 	var record storage.TupleRecord
 
-	err := s.stbl.
+	err := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"object_type", "object_id", "relation",
 			"_user",
@@ -268,6 +415,7 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 			"_user":       tupleKey.GetUser(),
 			"user_type":   userType,
 		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
 		QueryRowContext(ctx).
 		Scan(
 			&record.ObjectType,
@@ -296,17 +444,34 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 	return record.AsTuple(), nil
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *Datastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	ctx, span := startTrace(ctx, "ReadTuplesBatch")
+	defer span.End()
+
+	if len(lookups) == 0 {
+		return nil, nil
+	}
+
+	tuples, err := sqlcommon.ReadTuplesBatch(ctx, s.readReplicas.StatementBuilder(ctx, options.Consistency), store, lookups)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return tuples, nil
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
 func (s *Datastore) ReadUsersetTuples(
 	ctx context.Context,
 	store string,
 	filter storage.ReadUsersetTuplesFilter,
-	_ storage.ReadUsersetTuplesOptions,
+	options storage.ReadUsersetTuplesOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "ReadUsersetTuples")
 	defer span.End()
 
-	sb := s.stbl.
+	sb := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
@@ -314,7 +479,8 @@ func (s *Datastore) ReadUsersetTuples(
 		).
 		From("tuple").
 		Where(sq.Eq{"store": store}).
-		Where(sq.Eq{"user_type": tupleUtils.UserSet})
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	objectType, objectID := tupleUtils.SplitObject(filter.Object)
 	if objectType != "" {
@@ -355,7 +521,7 @@ func (s *Datastore) ReadStartingWithUser(
 	ctx context.Context,
 	store string,
 	filter storage.ReadStartingWithUserFilter,
-	_ storage.ReadStartingWithUserOptions,
+	options storage.ReadStartingWithUserOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "ReadStartingWithUser")
 	defer span.End()
@@ -369,7 +535,7 @@ func (s *Datastore) ReadStartingWithUser(
 		targetUsersArg = append(targetUsersArg, targetUser)
 	}
 
-	builder := s.stbl.
+	builder := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
@@ -381,7 +547,8 @@ func (s *Datastore) ReadStartingWithUser(
 			"object_type": filter.ObjectType,
 			"relation":    filter.Relation,
 			"_user":       targetUsersArg,
-		})
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	if filter.ObjectIDs != nil && filter.ObjectIDs.Size() > 0 {
 		builder = builder.Where(sq.Eq{"object_id": filter.ObjectIDs.Values()})
@@ -395,6 +562,35 @@ func (s *Datastore) ReadStartingWithUser(
 	return sqlcommon.NewSQLTupleIterator(rows), nil
 }
 
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples.
+func (s *Datastore) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "DeleteExpiredTuples")
+	defer span.End()
+
+	stores, err := sqlcommon.DeleteExpiredTuples(ctx, s.dbInfo, now.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(stores), nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	return sqlcommon.PruneChanges(ctx, s.dbInfo, store, olderThan, keepCount, limit)
+}
+
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (s *Datastore) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "CompactChanges")
+	defer span.End()
+
+	return sqlcommon.CompactChanges(ctx, s.dbInfo, store, olderThan, limit)
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *Datastore) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWriteField
@@ -514,6 +710,11 @@ func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*o
 	ctx, span := startTrace(ctx, "CreateStore")
 	defer span.End()
 
+	if s.dbInfo.Outbox {
+		// the store and its event are written in one transaction
+		return sqlcommon.CreateStoreWithLabels(ctx, s.dbInfo, store, nil)
+	}
+
 	var id, name string
 	var createdAt, updatedAt time.Time
 
@@ -596,20 +797,10 @@ func (s *Datastore) ListStores(ctx context.Context, options storage.ListStoresOp
 	ctx, span := startTrace(ctx, "ListStores")
 	defer span.End()
 
-	var whereClause sq.Sqlizer
-	if len(options.IDs) > 0 {
-		whereClause = sq.And{
-			sq.Eq{"deleted_at": nil},
-			sq.Eq{"id": options.IDs},
-		}
-	} else {
-		whereClause = sq.Eq{"deleted_at": nil}
-	}
-
 	sb := s.stbl.
 		Select("id", "name", "created_at", "updated_at").
 		From("store").
-		Where(whereClause).
+		Where(sqlcommon.ListStoresFilter(options)).
 		OrderBy("id")
 
 	if options.Pagination.From != "" {
@@ -667,18 +858,136 @@ func (s *Datastore) DeleteStore(ctx context.Context, id string) error {
 	ctx, span := startTrace(ctx, "DeleteStore")
 	defer span.End()
 
-	_, err := s.stbl.
-		Update("store").
-		Set("deleted_at", sq.Expr("NOW()")).
-		Where(sq.Eq{"id": id}).
-		ExecContext(ctx)
+	return sqlcommon.DeleteStore(ctx, s.dbInfo, id)
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels.
+func (s *Datastore) CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "CreateStoreWithLabels")
+	defer span.End()
+
+	return sqlcommon.CreateStoreWithLabels(ctx, s.dbInfo, store, labels)
+}
+
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (s *Datastore) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UpdateStore")
+	defer span.End()
+
+	return sqlcommon.UpdateStore(ctx, s.dbInfo, id, name, labels)
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (s *Datastore) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	ctx, span := startTrace(ctx, "ReadStoreLabels")
+	defer span.End()
+
+	return sqlcommon.ReadStoreLabels(ctx, s.dbInfo, id)
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples.
+func (s *Datastore) CountTuples(ctx context.Context, store string) (int64, error) {
+	ctx, span := startTrace(ctx, "CountTuples")
+	defer span.End()
+
+	count, err := sqlcommon.CountTuples(ctx, s.stbl, store)
 	if err != nil {
-		return HandleSQLError(err)
+		return 0, HandleSQLError(err)
+	}
+	return count, nil
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords.
+func (s *Datastore) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecords")
+	defer span.End()
+
+	records, token, err := sqlcommon.ReadTupleRecords(ctx, s.stbl, store, options)
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+	return records, token, nil
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (s *Datastore) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecord")
+	defer span.End()
+
+	record, err := sqlcommon.ReadTupleRecord(ctx, s.stbl, store, key)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return record, nil
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (s *Datastore) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	ctx, span := startTrace(ctx, "ReadChangeRecords")
+	defer span.End()
+
+	records, err := sqlcommon.ReadChangeRecords(ctx, s.stbl, store, after, limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
 	}
+	return records, nil
+}
 
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (s *Datastore) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	ctx, span := startTrace(ctx, "ImportRecords")
+	defer span.End()
+
+	return sqlcommon.ImportRecords(ctx, s.dbInfo, store, records)
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox.
+func (s *Datastore) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	ctx, span := startTrace(ctx, "ReadOutbox")
+	defer span.End()
+
+	events, err := sqlcommon.ReadOutbox(ctx, s.stbl, limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return events, nil
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents.
+func (s *Datastore) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	ctx, span := startTrace(ctx, "DeleteOutboxEvents")
+	defer span.End()
+
+	if err := sqlcommon.DeleteOutboxEvents(ctx, s.stbl, ids); err != nil {
+		return HandleSQLError(err)
+	}
 	return nil
 }
 
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (s *Datastore) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UndeleteStore")
+	defer span.End()
+
+	return sqlcommon.UndeleteStore(ctx, s.dbInfo, id, deletedAfter)
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores.
+func (s *Datastore) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	ctx, span := startTrace(ctx, "ListDeletedStores")
+	defer span.End()
+
+	return sqlcommon.ListDeletedStores(ctx, s.dbInfo, deletedBefore, limit)
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore.
+func (s *Datastore) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PurgeStore")
+	defer span.End()
+
+	return sqlcommon.PurgeStore(ctx, s.dbInfo, id, deletedBefore, limit)
+}
+
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
 func (s *Datastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	ctx, span := startTrace(ctx, "WriteAssertions")
@@ -775,6 +1084,14 @@ func (s *Datastore) ReadChanges(
 			return nil, nil, storage.ErrMismatchObjectType
 		}
 
+		horizon, err := sqlcommon.ReadChangelogHorizon(ctx, s.stbl, store)
+		if err != nil {
+			return nil, nil, HandleSQLError(err)
+		}
+		if token < horizon {
+			return nil, nil, storage.ErrContinuationTokenExpired
+		}
+
 		sb = sqlcommon.AddFromUlid(sb, token, options.SortDesc)
 	}
 	if options.Pagination.PageSize > 0 {
@@ -849,7 +1166,7 @@ func (s *Datastore) ReadChanges(
 
 // IsReady see [sqlcommon.IsReady].
 func (s *Datastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
-	return sqlcommon.IsReady(ctx, s.db)
+	return sqlcommon.IsReady(ctx, s.db, goose.DialectMySQL)
 }
 
 // HandleSQLError processes an SQL error and converts it into a more
@@ -859,7 +1176,14 @@ func HandleSQLError(err error, args ...interface{}) error {
 		return storage.ErrNotFound
 	}
 
-	if me, ok := err.(*mysql.MySQLError); ok && me.Number == 1062 {
+	// The serializable transactions of conditional writes, and the locking reads of the writes, may deadlock
+	// with concurrent writes.
+	var me *mysql.MySQLError
+	if errors.As(err, &me) && me.Number == 1213 {
+		return fmt.Errorf("%w: %w", storage.ErrTransactionalWriteFailed, err)
+	}
+
+	if errors.As(err, &me) && me.Number == 1062 {
 		if len(args) > 0 {
 			if tk, ok := args[0].(*openfgav1.TupleKey); ok {
 				return storage.InvalidWriteInputError(tk, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE)
@@ -37,6 +37,16 @@ func TestMySQLDatastore(t *testing.T) {
 	test.RunAllTests(t, ds, sqlcommon.NewSQLContinuationTokenSerializer())
 }
 
+func TestMySQLDatastoreOutbox(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig(sqlcommon.WithOutbox()))
+	require.NoError(t, err)
+	defer ds.Close()
+	test.EventOutboxTest(t, ds, ds)
+}
+
 func TestMySQLDatastoreAfterCloseIsNotReady(t *testing.T) {
 	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
 
@@ -84,6 +94,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{firstTuple},
+				storage.TupleWriteOptions{},
 				time.Now())
 			require.NoError(t, err)
 
@@ -93,6 +104,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{secondTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-1))
 			require.NoError(t, err)
 
@@ -102,6 +114,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{thirdTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-2))
 			require.NoError(t, err)
 
@@ -186,6 +199,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{firstTuple},
+				storage.TupleWriteOptions{},
 				time.Now())
 			require.NoError(t, err)
 
@@ -195,6 +209,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{secondTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-1))
 			require.NoError(t, err)
 
@@ -204,6 +219,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{thirdTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-2))
 			require.NoError(t, err)
 
@@ -247,6 +263,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{firstTuple},
+		storage.TupleWriteOptions{},
 		time.Now())
 	require.NoError(t, err)
 
@@ -256,6 +273,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{secondTuple},
+		storage.TupleWriteOptions{},
 		time.Now().Add(time.Minute*-1))
 	require.NoError(t, err)
 
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// EventType is the kind of change an [Event] records.
+type EventType string
+
+const (
+	// EventTuplesWritten records a write of the tuples of a store: the tuples it wrote and deleted, including the
+	// expired tuples it deleted to write them again.
+	EventTuplesWritten EventType = "tuples.written"
+	// EventTuplesExpired records the deletion of expired tuples of a store by the datastore, see [TupleReaper].
+	EventTuplesExpired EventType = "tuples.expired"
+	// EventAuthorizationModelWritten records a write of an authorization model of a store.
+	EventAuthorizationModelWritten EventType = "authorization_model.written"
+	// EventStoreCreated records the creation of a store.
+	EventStoreCreated EventType = "store.created"
+	// EventStoreDeleted records the deletion of a store.
+	EventStoreDeleted EventType = "store.deleted"
+)
+
+// TupleOperation is the operation of an [EventTupleChange].
+type TupleOperation string
+
+const (
+	TupleOperationWrite  TupleOperation = "write"
+	TupleOperationDelete TupleOperation = "delete"
+)
+
+// Event is a change of a datastore, recorded in its outbox along with the change itself, see [EventOutbox].
+type Event struct {
+	// ID is a ULID: the events recorded later have greater IDs.
+	ID      string    `json:"id"`
+	Type    EventType `json:"type"`
+	StoreID string    `json:"store_id"`
+	Time    time.Time `json:"time"`
+
+	// TupleChanges are the tuples written and deleted, for an EventTuplesWritten or EventTuplesExpired.
+	TupleChanges []EventTupleChange `json:"tuple_changes,omitempty"`
+	// AuthorizationModelID is the ID of the authorization model written, for an EventAuthorizationModelWritten.
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	// StoreName is the name of the store, for an EventStoreCreated.
+	StoreName string `json:"store_name,omitempty"`
+}
+
+// EventTupleChange is a tuple written or deleted, see [EventTuplesWritten] and [EventTuplesExpired].
+type EventTupleChange struct {
+	Operation     TupleOperation `json:"operation"`
+	Object        string         `json:"object"`
+	Relation      string         `json:"relation"`
+	User          string         `json:"user"`
+	ConditionName string         `json:"condition_name,omitempty"`
+}
+
+// NewTupleWriteChange returns the change of a written tuple.
+func NewTupleWriteChange(tk *openfgav1.TupleKey) EventTupleChange {
+	return EventTupleChange{
+		Operation:     TupleOperationWrite,
+		Object:        tk.GetObject(),
+		Relation:      tk.GetRelation(),
+		User:          tk.GetUser(),
+		ConditionName: tk.GetCondition().GetName(),
+	}
+}
+
+// NewTupleWriteChanges returns the changes of the written tuples.
+func NewTupleWriteChanges(writes Writes) []EventTupleChange {
+	changes := make([]EventTupleChange, 0, len(writes))
+	for _, tk := range writes {
+		changes = append(changes, NewTupleWriteChange(tk))
+	}
+	return changes
+}
+
+// NewTupleDeleteChange returns the change of a deleted tuple.
+func NewTupleDeleteChange(tk tuple.TupleWithoutCondition) EventTupleChange {
+	return EventTupleChange{
+		Operation: TupleOperationDelete,
+		Object:    tk.GetObject(),
+		Relation:  tk.GetRelation(),
+		User:      tk.GetUser(),
+	}
+}
+
+// newEvent returns an event of the store, with a new ID.
+func newEvent(eventType EventType, store string) *Event {
+	now := time.Now().UTC()
+	return &Event{
+		ID:      ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String(),
+		Type:    eventType,
+		StoreID: store,
+		Time:    now,
+	}
+}
+
+// NewTuplesWrittenEvent returns the event of a write of the tuples of the store, or nil if it changed none.
+func NewTuplesWrittenEvent(store string, changes []EventTupleChange) *Event {
+	if len(changes) == 0 {
+		return nil
+	}
+	event := newEvent(EventTuplesWritten, store)
+	event.TupleChanges = changes
+	return event
+}
+
+// NewTuplesExpiredEvent returns the event of the deletion of expired tuples of the store, or nil if there are none.
+func NewTuplesExpiredEvent(store string, changes []EventTupleChange) *Event {
+	if len(changes) == 0 {
+		return nil
+	}
+	event := newEvent(EventTuplesExpired, store)
+	event.TupleChanges = changes
+	return event
+}
+
+// NewTuplesExpiredEvents returns the events of the deletion of expired tuples across stores, one per store, where
+// stores holds the store of each of the changes.
+func NewTuplesExpiredEvents(stores []string, changes []EventTupleChange) []*Event {
+	var events []*Event
+	byStore := make(map[string]*Event)
+	for i, store := range stores {
+		event, ok := byStore[store]
+		if !ok {
+			event = newEvent(EventTuplesExpired, store)
+			byStore[store] = event
+			events = append(events, event)
+		}
+		event.TupleChanges = append(event.TupleChanges, changes[i])
+	}
+	return events
+}
+
+// NewAuthorizationModelWrittenEvent returns the event of a write of an authorization model of the store.
+func NewAuthorizationModelWrittenEvent(store string, modelID string) *Event {
+	event := newEvent(EventAuthorizationModelWritten, store)
+	event.AuthorizationModelID = modelID
+	return event
+}
+
+// NewStoreCreatedEvent returns the event of the creation of the store.
+func NewStoreCreatedEvent(store *openfgav1.Store) *Event {
+	event := newEvent(EventStoreCreated, store.GetId())
+	event.StoreName = store.GetName()
+	return event
+}
+
+// NewStoreDeletedEvent returns the event of the deletion of the store.
+func NewStoreDeletedEvent(store string) *Event {
+	return newEvent(EventStoreDeleted, store)
+}
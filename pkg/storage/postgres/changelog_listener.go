@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// changelogChannel is the channel on which the stores with new changes are notified, with the store as payload.
+const changelogChannel = "openfga_changelog"
+
+const changelogListenerMaxBackoff = 30 * time.Second
+
+// notifyChangelogStatement notifies the listeners of every OpenFGA instance sharing the database that the
+// changelog of the store has changed. Run as part of the transaction inserting the changes, the notification
+// is delivered once, and only if, the transaction commits.
+const notifyChangelogStatement = "SELECT pg_notify($1, $2)"
+
+// notifyChangelog see [sqlcommon.DBInfo].NotifyChangelog.
+func notifyChangelog(ctx context.Context, txn *sql.Tx, store string) error {
+	_, err := txn.ExecContext(ctx, notifyChangelogStatement, changelogChannel, store)
+	return err
+}
+
+// startChangelogListener starts listening to the notifications of the changelog the first time it is called.
+func (s *Datastore) startChangelogListener() {
+	s.changelogListenerOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.changelogListenerCancel = cancel
+		s.changelogListenerDone = make(chan struct{})
+
+		go func() {
+			defer close(s.changelogListenerDone)
+			s.listenChangelog(ctx)
+		}()
+	})
+}
+
+// stopChangelogListener stops the listener, if started, and waits for it to return.
+func (s *Datastore) stopChangelogListener() {
+	s.changelogListenerOnce.Do(func() {})
+	if s.changelogListenerCancel != nil {
+		s.changelogListenerCancel()
+		<-s.changelogListenerDone
+	}
+	s.changelogBroadcaster.Close()
+}
+
+// listenChangelog forwards the notifications of the changelog to the broadcaster until ctx is done. The
+// connection is re-established with an exponential backoff whenever it fails.
+func (s *Datastore) listenChangelog(ctx context.Context) {
+	policy := backoff.NewExponentialBackOff()
+	policy.MaxInterval = changelogListenerMaxBackoff
+	policy.MaxElapsedTime = 0
+
+	for {
+		err := s.listenChangelogOnce(ctx, policy.Reset)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// notifications may have been missed while reconnecting
+		s.changelogBroadcaster.NotifyAll()
+
+		wait := policy.NextBackOff()
+		s.logger.Warn("changelog listener failed", zap.Error(err), zap.Duration("retry_in", wait))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// listenChangelogOnce listens to the notifications of the changelog on a dedicated connection until it fails,
+// calling onListen once listening.
+func (s *Datastore) listenChangelogOnce(ctx context.Context, onListen func()) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		pgxConn := stdlibConn.Conn()
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+changelogChannel); err != nil {
+			// the connection can't be returned to the pool in a listening state
+			return errors.Join(driver.ErrBadConn, err)
+		}
+		onListen()
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return errors.Join(driver.ErrBadConn, err)
+			}
+			s.changelogBroadcaster.Notify(notification.Payload)
+		}
+	})
+}
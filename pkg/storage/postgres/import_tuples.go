@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// pgUniqueViolationCode is the SQLSTATE of a row violating a unique constraint.
+const pgUniqueViolationCode = "23505"
+
+// ImportTuples see [storage.TupleImporter].ImportTuples. The tuples and their changes are written with COPY.
+func (s *Datastore) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ctx, span := startTrace(ctx, "ImportTuples")
+	defer span.End()
+
+	if len(writes) > s.MaxTuplesPerImport() {
+		return storage.ErrExceededWriteBatchLimit
+	}
+
+	now := time.Now().UTC()
+	tupleRows := make([][]any, 0, len(writes))
+	changelogRows := make([][]any, 0, len(writes))
+	for _, tk := range writes {
+		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+
+		conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(tk.GetCondition())
+		if err != nil {
+			return err
+		}
+
+		tupleRows = append(tupleRows, []any{
+			store, objectType, objectID, tk.GetRelation(), tk.GetUser(), tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+			conditionName, conditionContext, id, now,
+		})
+		changelogRows = append(changelogRows, []any{
+			store, objectType, objectID, tk.GetRelation(), tk.GetUser(),
+			conditionName, conditionContext, int32(openfgav1.TupleOperation_TUPLE_OPERATION_WRITE), id, now,
+		})
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		return pgx.BeginFunc(ctx, stdlibConn.Conn(), func(txn pgx.Tx) error {
+			_, err := txn.CopyFrom(ctx,
+				pgx.Identifier{"tuple"},
+				[]string{
+					"store", "object_type", "object_id", "relation", "_user", "user_type",
+					"condition_name", "condition_context", "ulid", "inserted_at",
+				},
+				pgx.CopyFromRows(tupleRows),
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = txn.CopyFrom(ctx,
+				pgx.Identifier{"changelog"},
+				[]string{
+					"store", "object_type", "object_id", "relation", "_user",
+					"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+				},
+				pgx.CopyFromRows(changelogRows),
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := addTupleCount(ctx, txn, store, int64(len(writes))); err != nil {
+				return err
+			}
+
+			if _, err := txn.Exec(ctx, notifyChangelogStatement, changelogChannel, store); err != nil {
+				return err
+			}
+
+			if !s.dbInfo.Outbox {
+				return nil
+			}
+			return insertOutboxEvent(ctx, txn, storage.NewTuplesWrittenEvent(store, storage.NewTupleWriteChanges(writes)))
+		})
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+			return sqlcommon.ImportCollisionError(storage.ErrCollision)
+		}
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// addTupleCount is sqlcommon.AddTupleCount, within a pgx transaction.
+func addTupleCount(ctx context.Context, txn pgx.Tx, store string, delta int64) error {
+	_, err := txn.Exec(ctx,
+		"INSERT INTO store_tuple_count (store, shard, tuple_count) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (store, shard) DO UPDATE SET tuple_count = store_tuple_count.tuple_count + excluded.tuple_count",
+		store, rand.IntN(sqlcommon.TupleCountShards), delta,
+	)
+	return err
+}
+
+// insertOutboxEvent is sqlcommon.InsertOutboxEvent, within a pgx transaction.
+func insertOutboxEvent(ctx context.Context, txn pgx.Tx, event *storage.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = txn.Exec(ctx,
+		"INSERT INTO outbox (id, store, event_type, payload, inserted_at) VALUES ($1, $2, $3, $4, $5)",
+		event.ID, event.StoreID, string(event.Type), payload, event.Time,
+	)
+	return err
+}
+
+// MaxTuplesPerImport see [storage.TupleImporter].MaxTuplesPerImport.
+func (s *Datastore) MaxTuplesPerImport() int {
+	return storage.DefaultMaxTuplesPerImport
+}
@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/openfga/openfga/pkg/encoder"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver.
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/pressly/goose/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.opentelemetry.io/otel"
@@ -32,6 +35,12 @@ import (
 
 var tracer = otel.Tracer("openfga/pkg/storage/postgres")
 
+// pgSerializationFailureCode is the SQLSTATE of a serializable transaction conflicting with a concurrent one.
+const pgSerializationFailureCode = "40001"
+
+// pgDeadlockDetectedCode is the SQLSTATE of a transaction aborted to break a deadlock with concurrent ones.
+const pgDeadlockDetectedCode = "40P01"
+
 func startTrace(ctx context.Context, name string) (context.Context, trace.Span) {
 	return tracer.Start(ctx, "postgres."+name)
 }
@@ -41,45 +50,67 @@ type Datastore struct {
 	stbl                   sq.StatementBuilderType
 	db                     *sql.DB
 	dbInfo                 *sqlcommon.DBInfo
+	readReplicas           *sqlcommon.ReadReplicas
 	logger                 logger.Logger
 	tokenSerializer        encoder.ContinuationTokenSerializer
 	dbStatsCollector       prometheus.Collector
 	maxTuplesPerWriteField int
 	maxTypesPerModelField  int
+
+	changelogBroadcaster    *storage.ChangelogBroadcaster
+	changelogListenerOnce   sync.Once
+	changelogListenerCancel context.CancelFunc
+	changelogListenerDone   chan struct{}
 }
 
 // Ensures that Datastore implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
-// New creates a new [Datastore] storage.
-func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
-	if cfg.Username != "" || cfg.Password != "" {
-		parsed, err := url.Parse(uri)
-		if err != nil {
-			return nil, fmt.Errorf("parse postgres connection uri: %w", err)
-		}
+// Ensures that Datastore implements the TupleImporter interface.
+var _ storage.TupleImporter = (*Datastore)(nil)
 
-		username := ""
-		if cfg.Username != "" {
-			username = cfg.Username
-		} else if parsed.User != nil {
-			username = parsed.User.Username()
-		}
+// Ensures that Datastore implements the TupleReaper interface.
+var _ storage.TupleReaper = (*Datastore)(nil)
 
-		switch {
-		case cfg.Password != "":
-			parsed.User = url.UserPassword(username, cfg.Password)
-		case parsed.User != nil:
-			if password, ok := parsed.User.Password(); ok {
-				parsed.User = url.UserPassword(username, password)
-			} else {
-				parsed.User = url.User(username)
-			}
-		default:
-			parsed.User = url.User(username)
-		}
+// Ensures that Datastore implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that Datastore implements the StoreUndeleter interface.
+var _ storage.StoreUndeleter = (*Datastore)(nil)
 
-		uri = parsed.String()
+// Ensures that Datastore implements the StorePurger interface.
+var _ storage.StorePurger = (*Datastore)(nil)
+
+// Ensures that Datastore implements the StoreLabeler interface.
+var _ storage.StoreLabeler = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.TupleCounter] interface.
+var _ storage.TupleCounter = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.EventOutbox] interface.
+var _ storage.EventOutbox = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.RecordCopier] interface.
+var _ storage.RecordCopier = (*Datastore)(nil)
+
+// Ensures that Datastore implements the ChangelogWatcher interface.
+var _ storage.ChangelogWatcher = (*Datastore)(nil)
+
+// New creates a new [Datastore] storage. The tuple reads are routed to the read replicas of the config, if any
+// (see [sqlcommon.ReadReplicas]).
+func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
+	uri, err := withCredentials(uri, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaURIs := make([]string, 0, len(cfg.ReadReplicaURIs))
+	for _, replicaURI := range cfg.ReadReplicaURIs {
+		replicaURI, err := withCredentials(replicaURI, cfg)
+		if err != nil {
+			return nil, err
+		}
+		replicaURIs = append(replicaURIs, replicaURI)
 	}
 
 	if cfg.TokenSerializer == nil {
@@ -90,11 +121,56 @@ func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 	if err != nil {
 		return nil, fmt.Errorf("initialize postgres connection: %w", err)
 	}
-	return NewWithDB(db, cfg)
+
+	replicas, err := sqlcommon.OpenReadReplicas("pgx", replicaURIs, cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newWithDBs(db, replicas, cfg)
+}
+
+// withCredentials overrides the username and password of the connection uri with those of the config.
+func withCredentials(uri string, cfg *sqlcommon.Config) (string, error) {
+	if cfg.Username == "" && cfg.Password == "" {
+		return uri, nil
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse postgres connection uri: %w", err)
+	}
+
+	username := ""
+	if cfg.Username != "" {
+		username = cfg.Username
+	} else if parsed.User != nil {
+		username = parsed.User.Username()
+	}
+
+	switch {
+	case cfg.Password != "":
+		parsed.User = url.UserPassword(username, cfg.Password)
+	case parsed.User != nil:
+		if password, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(username, password)
+		} else {
+			parsed.User = url.User(username)
+		}
+	default:
+		parsed.User = url.User(username)
+	}
+
+	return parsed.String(), nil
 }
 
 // NewWithDB creates a new [Datastore] storage with the provided database connection.
 func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
+	return newWithDBs(db, nil, cfg)
+}
+
+func newWithDBs(db *sql.DB, replicas []*sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 	if cfg.MaxOpenConns != 0 {
 		db.SetMaxOpenConns(cfg.MaxOpenConns)
 	}
@@ -124,6 +200,9 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 		return nil
 	}, policy)
 	if err != nil {
+		for _, replica := range replicas {
+			replica.Close()
+		}
 		return nil, fmt.Errorf("ping db: %w", err)
 	}
 
@@ -135,23 +214,58 @@ func NewWithDB(db *sql.DB, cfg *sqlcommon.Config) (*Datastore, error) {
 		}
 	}
 
-	stbl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).RunWith(db)
+	stbl := newStatementBuilder(db)
 	dbInfo := sqlcommon.NewDBInfo(db, stbl, HandleSQLError)
+	dbInfo.Outbox = cfg.Outbox
+	dbInfo.NotifyChangelog = notifyChangelog
 
 	return &Datastore{
 		stbl:                   stbl,
 		db:                     db,
 		dbInfo:                 dbInfo,
+		readReplicas:           sqlcommon.NewReadReplicas(stbl, replicas, newStatementBuilder, replicaLag, cfg),
 		logger:                 cfg.Logger,
 		tokenSerializer:        cfg.TokenSerializer,
 		dbStatsCollector:       collector,
 		maxTuplesPerWriteField: cfg.MaxTuplesPerWriteField,
 		maxTypesPerModelField:  cfg.MaxTypesPerModelField,
+		changelogBroadcaster:   storage.NewChangelogBroadcaster(),
 	}, nil
 }
 
+func newStatementBuilder(db *sql.DB) sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar).RunWith(db)
+}
+
+// replicaLag see [sqlcommon.ReplicaLagFunc]. A replica which replayed all the WAL it received has no lag, otherwise
+// its lag is the age of the last transaction it replayed. Having replayed all it received says nothing of a replica
+// whose WAL receiver stopped streaming, so it is an error. The status of the receiver is only visible to the roles
+// with the privileges of pg_read_all_stats: for the others, the replica is only checked to have one.
+func replicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var inRecovery, streaming bool
+	var lagSeconds float64
+	err := db.QueryRowContext(ctx, `
+		SELECT
+			pg_is_in_recovery(),
+			EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status IS NULL OR status = 'streaming'),
+			CASE
+				WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+				ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+			END`).Scan(&inRecovery, &streaming, &lagSeconds)
+	if err != nil {
+		return 0, err
+	}
+	if inRecovery && !streaming {
+		return 0, errors.New("the WAL receiver is not streaming")
+	}
+
+	return time.Duration(lagSeconds * float64(time.Second)), nil
+}
+
 // Close see [storage.OpenFGADatastore].Close.
 func (s *Datastore) Close() {
+	s.stopChangelogListener()
+	s.readReplicas.Close()
 	if s.dbStatsCollector != nil {
 		prometheus.Unregister(s.dbStatsCollector)
 	}
@@ -163,12 +277,12 @@ func (s *Datastore) Read(
 	ctx context.Context,
 	store string,
 	tupleKey *openfgav1.TupleKey,
-	_ storage.ReadOptions,
+	options storage.ReadOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "Read")
 	defer span.End()
 
-	return s.read(ctx, store, tupleKey, nil)
+	return s.read(ctx, store, tupleKey, nil, options.Consistency)
 }
 
 // ReadPage see [storage.RelationshipTupleReader].ReadPage.
@@ -181,7 +295,7 @@ func (s *Datastore) ReadPage(
 	ctx, span := startTrace(ctx, "ReadPage")
 	defer span.End()
 
-	iter, err := s.read(ctx, store, tupleKey, &options)
+	iter, err := s.read(ctx, store, tupleKey, &options, options.Consistency)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -190,18 +304,25 @@ func (s *Datastore) ReadPage(
 	return iter.ToArray(options.Pagination)
 }
 
-func (s *Datastore) read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options *storage.ReadPageOptions) (*sqlcommon.SQLTupleIterator, error) {
+func (s *Datastore) read(
+	ctx context.Context,
+	store string,
+	tupleKey *openfgav1.TupleKey,
+	options *storage.ReadPageOptions,
+	consistency storage.ConsistencyOptions,
+) (*sqlcommon.SQLTupleIterator, error) {
 	ctx, span := startTrace(ctx, "read")
 	defer span.End()
 
-	sb := s.stbl.
+	sb := s.readReplicas.StatementBuilder(ctx, consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
 			"condition_name", "condition_context", "ulid", "inserted_at",
 		).
 		From("tuple").
-		Where(sq.Eq{"store": store})
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 	if options != nil {
 		sb = sb.OrderBy("ulid")
 	}
@@ -245,6 +366,7 @@ func (s *Datastore) Write(
 	store string,
 	deletes storage.Deletes,
 	writes storage.Writes,
+	opts ...storage.TupleWriteOption,
 ) error {
 	ctx, span := startTrace(ctx, "Write")
 	defer span.End()
@@ -253,11 +375,11 @@ func (s *Datastore) Write(
 		return storage.ErrExceededWriteBatchLimit
 	}
 
-	return sqlcommon.Write(ctx, s.dbInfo, store, deletes, writes, time.Now().UTC())
+	return sqlcommon.Write(ctx, s.dbInfo, store, deletes, writes, storage.NewTupleWriteOptions(opts...), time.Now().UTC())
 }
 
 // ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
-func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, _ storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
 	ctx, span := startTrace(ctx, "ReadUserTuple")
 	defer span.End()
 
@@ -268,7 +390,7 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 	var conditionContext []byte
 	var record storage.TupleRecord
 
-	err := s.stbl.
+	err := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"object_type", "object_id", "relation",
 			"_user",
@@ -283,6 +405,7 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 			"_user":       tupleKey.GetUser(),
 			"user_type":   userType,
 		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
 		QueryRowContext(ctx).
 		Scan(
 			&record.ObjectType,
@@ -311,17 +434,34 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 	return record.AsTuple(), nil
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *Datastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	ctx, span := startTrace(ctx, "ReadTuplesBatch")
+	defer span.End()
+
+	if len(lookups) == 0 {
+		return nil, nil
+	}
+
+	tuples, err := sqlcommon.ReadTuplesBatch(ctx, s.readReplicas.StatementBuilder(ctx, options.Consistency), store, lookups)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return tuples, nil
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
 func (s *Datastore) ReadUsersetTuples(
 	ctx context.Context,
 	store string,
 	filter storage.ReadUsersetTuplesFilter,
-	_ storage.ReadUsersetTuplesOptions,
+	options storage.ReadUsersetTuplesOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "ReadUsersetTuples")
 	defer span.End()
 
-	sb := s.stbl.
+	sb := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
@@ -329,7 +469,8 @@ func (s *Datastore) ReadUsersetTuples(
 		).
 		From("tuple").
 		Where(sq.Eq{"store": store}).
-		Where(sq.Eq{"user_type": tupleUtils.UserSet})
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	objectType, objectID := tupleUtils.SplitObject(filter.Object)
 	if objectType != "" {
@@ -370,7 +511,7 @@ func (s *Datastore) ReadStartingWithUser(
 	ctx context.Context,
 	store string,
 	filter storage.ReadStartingWithUserFilter,
-	_ storage.ReadStartingWithUserOptions,
+	options storage.ReadStartingWithUserOptions,
 ) (storage.TupleIterator, error) {
 	ctx, span := startTrace(ctx, "ReadStartingWithUser")
 	defer span.End()
@@ -384,7 +525,7 @@ func (s *Datastore) ReadStartingWithUser(
 		targetUsersArg = append(targetUsersArg, targetUser)
 	}
 
-	builder := s.stbl.
+	builder := s.readReplicas.StatementBuilder(ctx, options.Consistency).
 		Select(
 			"store", "object_type", "object_id", "relation",
 			"_user",
@@ -396,7 +537,8 @@ func (s *Datastore) ReadStartingWithUser(
 			"object_type": filter.ObjectType,
 			"relation":    filter.Relation,
 			"_user":       targetUsersArg,
-		})
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	if filter.ObjectIDs != nil && filter.ObjectIDs.Size() > 0 {
 		builder = builder.Where(sq.Eq{"object_id": filter.ObjectIDs.Values()})
@@ -410,6 +552,35 @@ func (s *Datastore) ReadStartingWithUser(
 	return sqlcommon.NewSQLTupleIterator(rows), nil
 }
 
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples.
+func (s *Datastore) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "DeleteExpiredTuples")
+	defer span.End()
+
+	stores, err := sqlcommon.DeleteExpiredTuples(ctx, s.dbInfo, now.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(stores), nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
+	defer span.End()
+
+	return sqlcommon.PruneChanges(ctx, s.dbInfo, store, olderThan, keepCount, limit)
+}
+
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (s *Datastore) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "CompactChanges")
+	defer span.End()
+
+	return sqlcommon.CompactChanges(ctx, s.dbInfo, store, olderThan, limit)
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *Datastore) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWriteField
@@ -529,6 +700,11 @@ func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*o
 	ctx, span := startTrace(ctx, "CreateStore")
 	defer span.End()
 
+	if s.dbInfo.Outbox {
+		// the store and its event are written in one transaction
+		return sqlcommon.CreateStoreWithLabels(ctx, s.dbInfo, store, nil)
+	}
+
 	var id, name string
 	var createdAt, updatedAt time.Time
 
@@ -588,20 +764,10 @@ func (s *Datastore) ListStores(ctx context.Context, options storage.ListStoresOp
 	ctx, span := startTrace(ctx, "ListStores")
 	defer span.End()
 
-	var whereClause sq.Sqlizer
-	if len(options.IDs) > 0 {
-		whereClause = sq.And{
-			sq.Eq{"deleted_at": nil},
-			sq.Eq{"id": options.IDs},
-		}
-	} else {
-		whereClause = sq.Eq{"deleted_at": nil}
-	}
-
 	sb := s.stbl.
 		Select("id", "name", "created_at", "updated_at").
 		From("store").
-		Where(whereClause).
+		Where(sqlcommon.ListStoresFilter(options)).
 		OrderBy("id")
 
 	if options.Pagination.From != "" {
@@ -659,18 +825,136 @@ func (s *Datastore) DeleteStore(ctx context.Context, id string) error {
 	ctx, span := startTrace(ctx, "DeleteStore")
 	defer span.End()
 
-	_, err := s.stbl.
-		Update("store").
-		Set("deleted_at", sq.Expr("NOW()")).
-		Where(sq.Eq{"id": id}).
-		ExecContext(ctx)
+	return sqlcommon.DeleteStore(ctx, s.dbInfo, id)
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels.
+func (s *Datastore) CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "CreateStoreWithLabels")
+	defer span.End()
+
+	return sqlcommon.CreateStoreWithLabels(ctx, s.dbInfo, store, labels)
+}
+
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (s *Datastore) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UpdateStore")
+	defer span.End()
+
+	return sqlcommon.UpdateStore(ctx, s.dbInfo, id, name, labels)
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (s *Datastore) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	ctx, span := startTrace(ctx, "ReadStoreLabels")
+	defer span.End()
+
+	return sqlcommon.ReadStoreLabels(ctx, s.dbInfo, id)
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples.
+func (s *Datastore) CountTuples(ctx context.Context, store string) (int64, error) {
+	ctx, span := startTrace(ctx, "CountTuples")
+	defer span.End()
+
+	count, err := sqlcommon.CountTuples(ctx, s.stbl, store)
 	if err != nil {
-		return HandleSQLError(err)
+		return 0, HandleSQLError(err)
+	}
+	return count, nil
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords.
+func (s *Datastore) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecords")
+	defer span.End()
+
+	records, token, err := sqlcommon.ReadTupleRecords(ctx, s.stbl, store, options)
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+	return records, token, nil
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (s *Datastore) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecord")
+	defer span.End()
+
+	record, err := sqlcommon.ReadTupleRecord(ctx, s.stbl, store, key)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return record, nil
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (s *Datastore) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	ctx, span := startTrace(ctx, "ReadChangeRecords")
+	defer span.End()
+
+	records, err := sqlcommon.ReadChangeRecords(ctx, s.stbl, store, after, limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
 	}
+	return records, nil
+}
 
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (s *Datastore) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	ctx, span := startTrace(ctx, "ImportRecords")
+	defer span.End()
+
+	return sqlcommon.ImportRecords(ctx, s.dbInfo, store, records)
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox.
+func (s *Datastore) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	ctx, span := startTrace(ctx, "ReadOutbox")
+	defer span.End()
+
+	events, err := sqlcommon.ReadOutbox(ctx, s.stbl, limit)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return events, nil
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents.
+func (s *Datastore) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	ctx, span := startTrace(ctx, "DeleteOutboxEvents")
+	defer span.End()
+
+	if err := sqlcommon.DeleteOutboxEvents(ctx, s.stbl, ids); err != nil {
+		return HandleSQLError(err)
+	}
 	return nil
 }
 
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (s *Datastore) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UndeleteStore")
+	defer span.End()
+
+	return sqlcommon.UndeleteStore(ctx, s.dbInfo, id, deletedAfter)
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores.
+func (s *Datastore) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	ctx, span := startTrace(ctx, "ListDeletedStores")
+	defer span.End()
+
+	return sqlcommon.ListDeletedStores(ctx, s.dbInfo, deletedBefore, limit)
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore.
+func (s *Datastore) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PurgeStore")
+	defer span.End()
+
+	return sqlcommon.PurgeStore(ctx, s.dbInfo, id, deletedBefore, limit)
+}
+
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
 func (s *Datastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	ctx, span := startTrace(ctx, "WriteAssertions")
@@ -725,6 +1009,13 @@ func (s *Datastore) ReadAssertions(ctx context.Context, store, modelID string) (
 	return assertions.GetAssertions(), nil
 }
 
+// WatchChangelog see [storage.ChangelogWatcher].WatchChangelog. The changes are notified with LISTEN/NOTIFY, so
+// that the changes committed by the other OpenFGA instances sharing the database are notified as well.
+func (s *Datastore) WatchChangelog(ctx context.Context, store string) (<-chan struct{}, error) {
+	s.startChangelogListener()
+	return s.changelogBroadcaster.Watch(ctx, store), nil
+}
+
 // ReadChanges see [storage.ChangelogBackend].ReadChanges.
 func (s *Datastore) ReadChanges(
 	ctx context.Context,
@@ -767,6 +1058,14 @@ func (s *Datastore) ReadChanges(
 			return nil, nil, storage.ErrMismatchObjectType
 		}
 
+		horizon, err := sqlcommon.ReadChangelogHorizon(ctx, s.stbl, store)
+		if err != nil {
+			return nil, nil, HandleSQLError(err)
+		}
+		if token < horizon {
+			return nil, nil, storage.ErrContinuationTokenExpired
+		}
+
 		sb = sqlcommon.AddFromUlid(sb, token, options.SortDesc)
 	}
 	if options.Pagination.PageSize > 0 {
@@ -841,7 +1140,7 @@ func (s *Datastore) ReadChanges(
 
 // IsReady see [sqlcommon.IsReady].
 func (s *Datastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
-	return sqlcommon.IsReady(ctx, s.db)
+	return sqlcommon.IsReady(ctx, s.db, goose.DialectPostgres)
 }
 
 // HandleSQLError processes an SQL error and converts it into a more
@@ -851,6 +1150,11 @@ func HandleSQLError(err error, args ...interface{}) error {
 		return storage.ErrNotFound
 	}
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == pgSerializationFailureCode || pgErr.Code == pgDeadlockDetectedCode) {
+		return fmt.Errorf("%w: %w", storage.ErrTransactionalWriteFailed, err)
+	}
+
 	if strings.Contains(err.Error(), "duplicate key value") {
 		if len(args) > 0 {
 			if tk, ok := args[0].(*openfgav1.TupleKey); ok {
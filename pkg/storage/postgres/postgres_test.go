@@ -32,6 +32,16 @@ func TestPostgresDatastore(t *testing.T) {
 	test.RunAllTests(t, ds, sqlcommon.NewSQLContinuationTokenSerializer())
 }
 
+func TestPostgresDatastoreOutbox(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "postgres")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig(sqlcommon.WithOutbox()))
+	require.NoError(t, err)
+	defer ds.Close()
+	test.EventOutboxTest(t, ds, ds)
+}
+
 func TestPostgresDatastoreAfterCloseIsNotReady(t *testing.T) {
 	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "postgres")
 
@@ -80,6 +90,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{firstTuple},
+				storage.TupleWriteOptions{},
 				time.Now())
 			require.NoError(t, err)
 
@@ -89,6 +100,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{secondTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-1))
 			require.NoError(t, err)
 
@@ -97,6 +109,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{thirdTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-2))
 			require.NoError(t, err)
 
@@ -181,6 +194,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{firstTuple},
+				storage.TupleWriteOptions{},
 				time.Now())
 			require.NoError(t, err)
 
@@ -190,6 +204,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{secondTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-1))
 			require.NoError(t, err)
 
@@ -198,6 +213,7 @@ func TestCtxCancel(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{thirdTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-2))
 			require.NoError(t, err)
 
@@ -240,6 +256,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{firstTuple},
+		storage.TupleWriteOptions{},
 		time.Now())
 	require.NoError(t, err)
 
@@ -249,6 +266,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{secondTuple},
+		storage.TupleWriteOptions{},
 		time.Now().Add(time.Minute*-1))
 	require.NoError(t, err)
 
@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"cmp"
 	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -25,23 +27,66 @@ type TupleRecord struct {
 	ConditionContext *structpb.Struct
 	Ulid             string
 	InsertedAt       time.Time
+	// ExpiresAt is when the tuple expires, or nil if it never does.
+	ExpiresAt *time.Time
 }
 
 // AsTuple converts a [TupleRecord] into a [*openfgav1.Tuple].
 func (t *TupleRecord) AsTuple() *openfgav1.Tuple {
-	user := t.User
-	if t.User == "" {
-		user = tupleutils.FromUserParts(t.UserObjectType, t.UserObjectID, t.UserRelation)
-	}
-
 	return &openfgav1.Tuple{
 		Key: tupleutils.NewTupleKeyWithCondition(
 			tupleutils.BuildObject(t.ObjectType, t.ObjectID),
 			t.Relation,
-			user,
+			t.user(),
 			t.ConditionName,
 			t.ConditionContext,
 		),
 		Timestamp: timestamppb.New(t.InsertedAt),
 	}
 }
+
+// user returns the user of the tuple, building it from its parts when the record has them only.
+func (t *TupleRecord) user() string {
+	if t.User == "" {
+		return tupleutils.FromUserParts(t.UserObjectType, t.UserObjectID, t.UserRelation)
+	}
+	return t.User
+}
+
+// IsExpired reports whether the [TupleRecord] has expired at the given time.
+func (t *TupleRecord) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(now)
+}
+
+// HasCondition reports whether the [TupleRecord] has the given condition, an empty context being the same as none.
+func (t *TupleRecord) HasCondition(condition *openfgav1.RelationshipCondition) bool {
+	if t.ConditionName != condition.GetName() {
+		return false
+	}
+
+	if len(t.ConditionContext.GetFields()) == 0 || len(condition.GetContext().GetFields()) == 0 {
+		return len(t.ConditionContext.GetFields()) == len(condition.GetContext().GetFields())
+	}
+
+	return proto.Equal(t.ConditionContext, condition.GetContext())
+}
+
+// CompareTupleRecords orders the tuple records by object type, object ID, relation and user, as returned by
+// [RecordCopier].ReadTupleRecords.
+func CompareTupleRecords(a, b *TupleRecord) int {
+	return cmp.Or(
+		cmp.Compare(a.ObjectType, b.ObjectType),
+		cmp.Compare(a.ObjectID, b.ObjectID),
+		cmp.Compare(a.Relation, b.Relation),
+		cmp.Compare(a.user(), b.user()),
+	)
+}
+
+// ChangeRecord represents a change of the changelog of a store, as it is stored.
+type ChangeRecord struct {
+	Ulid      string
+	Operation openfgav1.TupleOperation
+	// TupleKey is the tuple changed, along with its condition if it was written.
+	TupleKey   *openfgav1.TupleKey
+	InsertedAt time.Time
+}
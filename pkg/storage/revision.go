@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+type revisionCtxKey struct{}
+
+// RevisionMode is how the reads of a request are bound to a [Revision].
+type RevisionMode int
+
+const (
+	// RevisionAtLeastAsFresh requires the reads to observe all the changes committed before the revision was taken.
+	RevisionAtLeastAsFresh RevisionMode = iota + 1
+	// RevisionExact requires the reads to observe the store exactly as it was when the revision was taken.
+	RevisionExact
+)
+
+// Revision is a point in the changelog of a store. Its ULID orders after those of the changes committed before it
+// was taken, and before those of the changes committed after.
+type Revision struct {
+	ULID ulid.ULID
+	Mode RevisionMode
+}
+
+// IsCacheable reports whether results cached at the given time can be served for the revision. Results are never
+// served, nor cached, for an exact revision, since caches hold the latest state of the store.
+func (r Revision) IsCacheable(cachedAt time.Time) bool {
+	if r.Mode == RevisionExact {
+		return false
+	}
+
+	// ULIDs have a millisecond precision, so the results must be cached after the millisecond of the revision.
+	return cachedAt.After(ulid.Time(r.ULID.Time()).Add(time.Millisecond))
+}
+
+// revisionValue is the revision of a request, along with the values memoized for it by the reads of the request.
+type revisionValue struct {
+	revision Revision
+	mu       sync.Mutex
+	memo     map[any]any
+}
+
+// ContextWithRevision sets the revision the reads of the request are bound to in the context.
+func ContextWithRevision(parent context.Context, revision Revision) context.Context {
+	return context.WithValue(parent, revisionCtxKey{}, &revisionValue{revision: revision})
+}
+
+// RevisionFromContext returns the revision the reads of the request are bound to, if any.
+func RevisionFromContext(ctx context.Context) (Revision, bool) {
+	value, ok := ctx.Value(revisionCtxKey{}).(*revisionValue)
+	if !ok {
+		return Revision{}, false
+	}
+	return value.revision, true
+}
+
+// RevisionMemo returns the value memoized under the key for the revision of the request, which newValue creates on
+// first use. The value is shared by the reads of the request, which may be concurrent. It returns nil if the reads
+// of the request are not bound to a revision.
+func RevisionMemo(ctx context.Context, key any, newValue func() any) any {
+	value, ok := ctx.Value(revisionCtxKey{}).(*revisionValue)
+	if !ok {
+		return nil
+	}
+
+	value.mu.Lock()
+	defer value.mu.Unlock()
+
+	memoized, ok := value.memo[key]
+	if !ok {
+		if value.memo == nil {
+			value.memo = make(map[any]any)
+		}
+		memoized = newValue()
+		value.memo[key] = memoized
+	}
+	return memoized
+}
@@ -0,0 +1,106 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// CatalogStoreID is the ID of the store of the catalog datastore which holds the placements of the stores, as
+	// tuples 'store:<store id>#shard@shard:<shard name>'. It isn't created with CreateStore, so it isn't listed.
+	CatalogStoreID = "00000000000000000000000000"
+
+	placementObjectType = "store"
+	placementRelation   = "shard"
+	placementUserType   = "shard"
+)
+
+// placement returns the name of the shard the store is placed on, from the cache or else from the catalog.
+func (d *Datastore) placement(ctx context.Context, store string) (string, error) {
+	d.mu.Lock()
+	cached, ok := d.placements[store]
+	d.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.shard, nil
+	}
+
+	name, _, err := d.readPlacement(ctx, store)
+	if err != nil {
+		return "", err
+	}
+	d.cachePlacement(store, name)
+	return name, nil
+}
+
+func (d *Datastore) cachePlacement(store, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// evict the expired placements now and then, rather than keeping those of every store ever seen
+	if len(d.placements) >= 10000 {
+		now := time.Now()
+		for id, cached := range d.placements {
+			if now.After(cached.expiresAt) {
+				delete(d.placements, id)
+			}
+		}
+	}
+
+	d.placements[store] = cachedPlacement{shard: name, expiresAt: time.Now().Add(d.cacheTTL)}
+}
+
+// readPlacement reads the placement of the store from the catalog, and reports whether the store has one, the
+// stores without one being on the default shard.
+func (d *Datastore) readPlacement(ctx context.Context, store string) (string, bool, error) {
+	iter, err := d.catalog.Read(ctx, CatalogStoreID, tuple.NewTupleKey(placementObject(store), placementRelation, ""), storage.ReadOptions{
+		Consistency: storage.ConsistencyOptions{Preference: openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("read placement of store '%s': %w", store, err)
+	}
+	defer iter.Stop()
+
+	t, err := iter.Next(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrIteratorDone) {
+			return d.defaultShard, false, nil
+		}
+		return "", false, fmt.Errorf("read placement of store '%s': %w", store, err)
+	}
+
+	_, name := tuple.SplitObject(t.GetKey().GetUser())
+	if _, ok := d.shards[name]; !ok {
+		return "", false, fmt.Errorf("store '%s' is placed on the shard '%s': %w", store, name, ErrUnknownShard)
+	}
+	return name, true, nil
+}
+
+// writePlacement replaces the placement of the store on the shard from, or its lack of one if from is empty, with
+// its placement on the shard to, failing if it changed in the meantime.
+func (d *Datastore) writePlacement(ctx context.Context, store, from, to string) error {
+	var deletes storage.Deletes
+	if from != "" {
+		deletes = storage.Deletes{tuple.TupleKeyToTupleKeyWithoutCondition(placementTupleKey(store, from))}
+	}
+
+	err := d.catalog.Write(ctx, CatalogStoreID, deletes, storage.Writes{placementTupleKey(store, to)})
+	if err != nil {
+		return fmt.Errorf("write placement of store '%s': %w", store, err)
+	}
+	return nil
+}
+
+func placementObject(store string) string {
+	return tuple.BuildObject(placementObjectType, store)
+}
+
+func placementTupleKey(store, shard string) *openfgav1.TupleKey {
+	return tuple.NewTupleKey(placementObject(store), placementRelation, tuple.BuildObject(placementUserType, shard))
+}
@@ -0,0 +1,3 @@
+// Package sharding contains a datastore which spreads the stores over several datastores, the shards, routing the
+// calls of every store to the shard it is placed on.
+package sharding
@@ -0,0 +1,156 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/storage/storecopy"
+)
+
+const (
+	// moveCatchUpRounds is the maximum number of times the changes made while moving a store are copied before
+	// its writes are frozen, so that a store written continuously is still moved.
+	moveCatchUpRounds = 10
+)
+
+// MoveStore moves the store, along with its authorization models, assertions, labels, tuples and changelog, to
+// the shard to, while it keeps being read and written:
+//
+//  1. the store is created on the shard, and its authorization models and assertions are copied;
+//  2. its changelog is copied to the shard, along with the tuples it changed, then its tuples are reconciled with
+//     those of the store, in case its changelog was pruned;
+//  3. the changes made in the meantime are copied, until there are none left, or a few times;
+//  4. its writes are frozen in this process while the last changes, authorization models and assertions are
+//     copied, and its placement is flipped to the shard;
+//  5. once the placement cache TTL has elapsed, the changes written by the other processes which hadn't seen its
+//     new placement yet are copied, then the store is deleted from its previous shard, from which it is
+//     eventually purged (see storage.StorePurger).
+//
+// The tuples and changes are copied as they are stored (see storage.RecordCopier), so they keep their ULIDs,
+// timestamps and expiry, and the continuation tokens of ReadChanges obtained before the move stay valid. If the move
+// fails, it can be retried, and it resumes from the copy on the shard.
+func (d *Datastore) MoveStore(ctx context.Context, store, to string) error {
+	target, ok := d.shards[to]
+	if !ok {
+		return fmt.Errorf("shard '%s': %w", to, ErrUnknownShard)
+	}
+
+	from, placed, err := d.readPlacement(ctx, store)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	source := d.shards[from]
+	s, err := source.GetStore(ctx, store)
+	if err != nil {
+		return fmt.Errorf("get store: %w", err)
+	}
+
+	m, err := storecopy.New(store, source, target)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("moving store",
+		zap.String("store_id", store),
+		zap.String("from", from),
+		zap.String("to", to),
+	)
+
+	if err := m.CreateStore(ctx, s); err != nil {
+		return err
+	}
+	if err := m.CopyModels(ctx); err != nil {
+		return err
+	}
+
+	after, err := m.CopyChanges(ctx, "")
+	if err != nil {
+		return err
+	}
+	if err := m.ReconcileTuples(ctx); err != nil {
+		return err
+	}
+
+	// copy the changes made in the meantime until there are none left, so that the writes are frozen briefly
+	for i := 0; i < moveCatchUpRounds; i++ {
+		next, err := m.CopyChanges(ctx, after)
+		if err != nil {
+			return err
+		}
+		if next == after {
+			break
+		}
+		after = next
+	}
+
+	after, err = d.flip(ctx, m, after, store, from, placed, to)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("flipped store placement",
+		zap.String("store_id", store),
+		zap.String("from", from),
+		zap.String("to", to),
+	)
+
+	if d.cacheTTL > 0 {
+		timer := time.NewTimer(d.cacheTTL)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("store moved, but not deleted from the shard '%s': %w", from, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	if _, err := m.CopyChanges(ctx, after); err != nil {
+		return fmt.Errorf("store moved, but not deleted from the shard '%s': %w", from, err)
+	}
+	if err := source.DeleteStore(ctx, store); err != nil {
+		return fmt.Errorf("store moved, but not deleted from the shard '%s': %w", from, err)
+	}
+
+	d.logger.Info("moved store",
+		zap.String("store_id", store),
+		zap.String("from", from),
+		zap.String("to", to),
+	)
+	return nil
+}
+
+// flip freezes the writes of the store while its last changes, authorization models, assertions and labels are
+// copied, then flips its placement, and returns the ULID of the last change copied.
+func (d *Datastore) flip(ctx context.Context, m *storecopy.Copier, after, store, from string, placed bool, to string) (string, error) {
+	lock := d.storeLock(store)
+	lock.Lock()
+	defer lock.Unlock()
+
+	after, err := m.CopyChanges(ctx, after)
+	if err != nil {
+		return "", err
+	}
+	if err := m.CopyModels(ctx); err != nil {
+		return "", err
+	}
+	if err := m.CopyLabels(ctx); err != nil {
+		return "", err
+	}
+
+	if !placed {
+		from = ""
+	}
+	if err := d.writePlacement(ctx, store, from, to); err != nil {
+		return "", err
+	}
+	d.cachePlacement(store, to)
+
+	return after, nil
+}
@@ -0,0 +1,57 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// PlacementPolicy picks the shard of the stores created.
+type PlacementPolicy interface {
+	// Place returns the name of the shard, one of shards, the store is created on, or an empty name for the default
+	// shard.
+	Place(ctx context.Context, store *openfgav1.Store, shards []string) (string, error)
+}
+
+// PlacementPolicyFunc is an adapter allowing a function to be used as a [PlacementPolicy].
+type PlacementPolicyFunc func(ctx context.Context, store *openfgav1.Store, shards []string) (string, error)
+
+// Place calls f(ctx, store, shards).
+func (f PlacementPolicyFunc) Place(ctx context.Context, store *openfgav1.Store, shards []string) (string, error) {
+	return f(ctx, store, shards)
+}
+
+// DefaultShardPlacement places all the stores on the default shard, from which they can be moved to another one
+// with MoveStore.
+func DefaultShardPlacement() PlacementPolicy {
+	return defaultShardPlacement{}
+}
+
+type defaultShardPlacement struct{}
+
+func (defaultShardPlacement) Place(context.Context, *openfgav1.Store, []string) (string, error) {
+	return "", nil
+}
+
+// ShardPlacement places all the stores on the shard.
+func ShardPlacement(shard string) PlacementPolicy {
+	return PlacementPolicyFunc(func(_ context.Context, _ *openfgav1.Store, shards []string) (string, error) {
+		for _, name := range shards {
+			if name == shard {
+				return shard, nil
+			}
+		}
+		return "", fmt.Errorf("shard '%s': %w", shard, ErrUnknownShard)
+	})
+}
+
+// HashPlacement spreads the stores evenly over the shards, by the hash of their ID.
+func HashPlacement() PlacementPolicy {
+	return PlacementPolicyFunc(func(_ context.Context, store *openfgav1.Store, shards []string) (string, error) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(store.GetId()))
+		return shards[h.Sum32()%uint32(len(shards))], nil
+	})
+}
@@ -0,0 +1,897 @@
+package sharding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// DefaultPlacementCacheTTL is how long the placements of the stores are cached by default.
+	DefaultPlacementCacheTTL = 10 * time.Second
+
+	// storeLockStripes is the number of locks the stores are spread over, to freeze the writes of a store while it
+	// is moved without keeping a lock per store.
+	storeLockStripes = 64
+)
+
+var (
+	_ storage.OpenFGADatastore = (*Datastore)(nil)
+	_ storage.TupleImporter    = (*Datastore)(nil)
+	_ storage.TupleReaper      = (*Datastore)(nil)
+	_ storage.ChangelogPruner  = (*Datastore)(nil)
+	_ storage.StoreUndeleter   = (*Datastore)(nil)
+	_ storage.StorePurger      = (*Datastore)(nil)
+	_ storage.StoreLabeler     = (*Datastore)(nil)
+	_ storage.TupleCounter     = (*Datastore)(nil)
+	_ storage.EventOutbox      = (*Datastore)(nil)
+	_ storage.RecordCopier     = (*Datastore)(nil)
+)
+
+var (
+	shardNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+	// ErrUnknownShard is returned when a shard isn't one of the shards of the datastore.
+	ErrUnknownShard = errors.New("unknown shard")
+
+	// ErrUnsupported is returned when the shard of a store doesn't implement an optional interface of the
+	// datastore, e.g. storage.StoreLabeler.
+	ErrUnsupported = errors.New("unsupported by the datastore of the shard")
+)
+
+// Datastore is a [storage.OpenFGADatastore] which spreads the stores over several datastores, the shards. Every
+// store is placed on one shard, which holds all its data, and its calls are routed to it. The placements are
+// persisted in a catalog datastore, which may be one of the shards, and the stores without one, e.g. created before
+// the datastore was sharded, are on the default shard.
+//
+// The stores created are placed by a [PlacementPolicy], and they can be moved to another shard online with MoveStore.
+// ListStores lists the stores of every shard, one shard after the other.
+//
+// The placements are cached for the placement cache TTL, so when the datastore is used by several processes, the
+// calls of a moved store may still be routed to its previous shard by the other processes for up to the TTL after
+// its move (see MoveStore).
+type Datastore struct {
+	catalog      storage.OpenFGADatastore
+	shards       map[string]storage.OpenFGADatastore
+	names        []string // the names of the shards, sorted
+	defaultShard string
+	policy       PlacementPolicy
+	cacheTTL     time.Duration
+	logger       logger.Logger
+
+	mu         sync.Mutex
+	placements map[string]cachedPlacement // GUARDED_BY(mu)
+
+	// storeLocks are read-locked by the writes of the stores, and locked by MoveStore to freeze them.
+	storeLocks [storeLockStripes]sync.RWMutex
+}
+
+// cachedPlacement is the placement of a store, cached until expiresAt.
+type cachedPlacement struct {
+	shard     string
+	expiresAt time.Time
+}
+
+// Option defines a function type used for configuring a [Datastore].
+type Option func(*Datastore)
+
+// WithPlacementPolicy sets the policy which places the stores created. Defaults to placing them on the default shard.
+func WithPlacementPolicy(policy PlacementPolicy) Option {
+	return func(d *Datastore) {
+		d.policy = policy
+	}
+}
+
+// WithPlacementCacheTTL sets how long the placements of the stores are cached. Defaults to
+// DefaultPlacementCacheTTL.
+func WithPlacementCacheTTL(ttl time.Duration) Option {
+	return func(d *Datastore) {
+		d.cacheTTL = ttl
+	}
+}
+
+// WithLogger sets the logger of the moves of the stores.
+func WithLogger(l logger.Logger) Option {
+	return func(d *Datastore) {
+		d.logger = l
+	}
+}
+
+// New creates a new [Datastore] spreading the stores over the shards, by name, with their placements persisted in
+// the catalog, which may be one of the shards. The names of the shards must be made of up to 64 letters, digits,
+// '_' and '-', and the default shard must be one of them. The Datastore takes ownership of the shards and of the
+// catalog, which are closed along with it.
+func New(catalog storage.OpenFGADatastore, defaultShard string, shards map[string]storage.OpenFGADatastore, opts ...Option) (*Datastore, error) {
+	if _, ok := shards[defaultShard]; !ok {
+		return nil, fmt.Errorf("the default shard '%s' is not one of the shards: %w", defaultShard, ErrUnknownShard)
+	}
+
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		if !shardNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid shard name '%s': it must be made of up to 64 letters, digits, '_' and '-'", name)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	d := &Datastore{
+		catalog:      catalog,
+		shards:       shards,
+		names:        names,
+		defaultShard: defaultShard,
+		policy:       DefaultShardPlacement(),
+		cacheTTL:     DefaultPlacementCacheTTL,
+		logger:       logger.NewNoopLogger(),
+		placements:   make(map[string]cachedPlacement),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+// Shards returns the names of the shards, sorted.
+func (d *Datastore) Shards() []string {
+	return slices.Clone(d.names)
+}
+
+// ShardOf returns the name of the shard the store is placed on.
+func (d *Datastore) ShardOf(ctx context.Context, store string) (string, error) {
+	return d.placement(ctx, store)
+}
+
+// shardOf returns the datastore of the shard the store is placed on.
+func (d *Datastore) shardOf(ctx context.Context, store string) (storage.OpenFGADatastore, error) {
+	name, err := d.placement(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return d.shards[name], nil
+}
+
+// lockStore read-locks the store for a write, which MoveStore waits for before flipping its placement, and returns
+// the datastore of the shard the store is placed on once locked, along with the function unlocking it.
+func (d *Datastore) lockStore(ctx context.Context, store string) (storage.OpenFGADatastore, func(), error) {
+	lock := d.storeLock(store)
+	lock.RLock()
+
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		lock.RUnlock()
+		return nil, nil, err
+	}
+	return ds, lock.RUnlock, nil
+}
+
+func (d *Datastore) storeLock(store string) *sync.RWMutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(store))
+	return &d.storeLocks[h.Sum32()%storeLockStripes]
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (d *Datastore) Read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadOptions) (storage.TupleIterator, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.Read(ctx, store, tupleKey, options)
+}
+
+// ReadPage see [storage.RelationshipTupleReader].ReadPage.
+func (d *Datastore) ReadPage(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadPageOptions) ([]*openfgav1.Tuple, []byte, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds.ReadPage(ctx, store, tupleKey, options)
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (d *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadUserTuple(ctx, store, tupleKey, options)
+}
+
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (d *Datastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadTuplesBatch(ctx, store, lookups, options)
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (d *Datastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadUsersetTuples(ctx, store, filter, options)
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (d *Datastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadStartingWithUser(ctx, store, filter, options)
+}
+
+// Write see [storage.RelationshipTupleWriter].Write.
+func (d *Datastore) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
+	ds, unlock, err := d.lockStore(ctx, store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return ds.Write(ctx, store, deletes, writes, opts...)
+}
+
+// MaxTuplesPerWrite returns the smallest maximum number of tuples per write of the shards.
+func (d *Datastore) MaxTuplesPerWrite() int {
+	maxTuples := 0
+	for i, name := range d.names {
+		if n := d.shards[name].MaxTuplesPerWrite(); i == 0 || n < maxTuples {
+			maxTuples = n
+		}
+	}
+	return maxTuples
+}
+
+// ReadAssertions see [storage.AssertionsBackend].ReadAssertions.
+func (d *Datastore) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadAssertions(ctx, store, modelID)
+}
+
+// WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
+func (d *Datastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
+	ds, unlock, err := d.lockStore(ctx, store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return ds.WriteAssertions(ctx, store, modelID, assertions)
+}
+
+// ReadAuthorizationModel see [storage.AuthorizationModelReadBackend].ReadAuthorizationModel.
+func (d *Datastore) ReadAuthorizationModel(ctx context.Context, store string, id string) (*openfgav1.AuthorizationModel, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadAuthorizationModel(ctx, store, id)
+}
+
+// ReadAuthorizationModels see [storage.AuthorizationModelReadBackend].ReadAuthorizationModels.
+func (d *Datastore) ReadAuthorizationModels(ctx context.Context, store string, options storage.ReadAuthorizationModelsOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds.ReadAuthorizationModels(ctx, store, options)
+}
+
+// FindLatestAuthorizationModel see [storage.AuthorizationModelReadBackend].FindLatestAuthorizationModel.
+func (d *Datastore) FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return ds.FindLatestAuthorizationModel(ctx, store)
+}
+
+// MaxTypesPerAuthorizationModel returns the smallest maximum number of types per authorization model of the shards.
+func (d *Datastore) MaxTypesPerAuthorizationModel() int {
+	maxTypes := 0
+	for i, name := range d.names {
+		if n := d.shards[name].MaxTypesPerAuthorizationModel(); i == 0 || n < maxTypes {
+			maxTypes = n
+		}
+	}
+	return maxTypes
+}
+
+// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
+func (d *Datastore) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
+	ds, unlock, err := d.lockStore(ctx, store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return ds.WriteAuthorizationModel(ctx, store, model)
+}
+
+// CreateStore creates the store on the shard picked by the placement policy, then records its placement.
+func (d *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
+	return d.createStore(ctx, store, func(ds storage.OpenFGADatastore) (*openfgav1.Store, error) {
+		return ds.CreateStore(ctx, store)
+	})
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels. The store is placed like with
+// CreateStore.
+func (d *Datastore) CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	return d.createStore(ctx, store, func(ds storage.OpenFGADatastore) (*openfgav1.Store, error) {
+		labeler, ok := storage.As[storage.StoreLabeler](ds)
+		if !ok {
+			return nil, fmt.Errorf("store labels: %w", ErrUnsupported)
+		}
+		return labeler.CreateStoreWithLabels(ctx, store, labels)
+	})
+}
+
+func (d *Datastore) createStore(ctx context.Context, store *openfgav1.Store, create func(storage.OpenFGADatastore) (*openfgav1.Store, error)) (*openfgav1.Store, error) {
+	name, err := d.policy.Place(ctx, store, d.Shards())
+	if err != nil {
+		return nil, fmt.Errorf("place store: %w", err)
+	}
+	if name == "" {
+		name = d.defaultShard
+	}
+	ds, ok := d.shards[name]
+	if !ok {
+		return nil, fmt.Errorf("store placed on the shard '%s': %w", name, ErrUnknownShard)
+	}
+
+	// the placement of a store which was purged is left behind, and the store may exist on its shard
+	current, placed, err := d.readPlacement(ctx, store.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.shards[current].GetStore(ctx, store.GetId()); err == nil {
+		return nil, storage.ErrCollision
+	}
+
+	created, err := create(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != name || (!placed && name != d.defaultShard) {
+		from := ""
+		if placed {
+			from = current
+		}
+		if err := d.writePlacement(ctx, store.GetId(), from, name); err != nil {
+			// the store is unreachable without its placement
+			_ = ds.DeleteStore(ctx, store.GetId())
+			return nil, err
+		}
+	}
+	d.cachePlacement(store.GetId(), name)
+
+	return created, nil
+}
+
+// DeleteStore see [storage.StoresBackend].DeleteStore. The store keeps its placement, so that it can be restored.
+func (d *Datastore) DeleteStore(ctx context.Context, id string) error {
+	ds, unlock, err := d.lockStore(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return ds.DeleteStore(ctx, id)
+}
+
+// GetStore see [storage.StoresBackend].GetStore.
+func (d *Datastore) GetStore(ctx context.Context, id string) (*openfgav1.Store, error) {
+	ds, err := d.shardOf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ds.GetStore(ctx, id)
+}
+
+// listStoresToken is the continuation token of ListStores, the position of every shard with stores left.
+type listStoresToken struct {
+	Shards map[string]listStoresPosition `json:"shards"`
+}
+
+// listStoresPosition is the position of a shard in ListStores: the continuation token of the page of its next store,
+// and the ID of the last store of the page which was already listed, if any.
+type listStoresPosition struct {
+	Token []byte `json:"token,omitempty"`
+	After string `json:"after,omitempty"`
+}
+
+// listedStore is a store read from a shard by ListStores, along with the continuation token of its page.
+type listedStore struct {
+	store *openfgav1.Store
+	shard string
+	token []byte
+}
+
+// ListStores lists the stores of all the shards, merged in the order of their IDs, skipping the stores which are
+// placed on another shard, e.g. the copies of the stores being moved. It reads up to a page of stores from every
+// shard, and the continuation token holds the position of every shard.
+func (d *Datastore) ListStores(ctx context.Context, options storage.ListStoresOptions) ([]*openfgav1.Store, []byte, error) {
+	from := listStoresToken{Shards: make(map[string]listStoresPosition, len(d.names))}
+	if options.Pagination.From == "" {
+		for _, name := range d.names {
+			from.Shards[name] = listStoresPosition{}
+		}
+	} else if err := json.Unmarshal([]byte(options.Pagination.From), &from); err != nil {
+		return nil, nil, storage.ErrInvalidContinuationToken
+	}
+
+	pageSize := options.Pagination.PageSize
+	var candidates []listedStore
+	next := listStoresToken{Shards: make(map[string]listStoresPosition, len(from.Shards))}
+	for name, position := range from.Shards {
+		if _, ok := d.shards[name]; !ok {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+
+		listed, token, err := d.listShardStores(ctx, name, position, options, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, listed...)
+		if len(token) > 0 {
+			// the shard has stores left after its candidates, if they are all listed
+			next.Shards[name] = listStoresPosition{Token: token}
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b listedStore) int {
+		return strings.Compare(a.store.GetId(), b.store.GetId())
+	})
+
+	stores := make([]*openfgav1.Store, 0, min(pageSize, len(candidates)))
+	listed := make(map[string]string, len(from.Shards))
+	for _, candidate := range candidates[:min(pageSize, len(candidates))] {
+		stores = append(stores, candidate.store)
+		listed[candidate.shard] = candidate.store.GetId()
+	}
+	resumed := make(map[string]struct{}, len(from.Shards))
+	for _, candidate := range candidates[len(stores):] {
+		if _, ok := resumed[candidate.shard]; ok {
+			continue
+		}
+		resumed[candidate.shard] = struct{}{}
+
+		// the shard resumes from the page of its first candidate which wasn't listed
+		after := from.Shards[candidate.shard].After
+		if id, ok := listed[candidate.shard]; ok {
+			after = id
+		}
+		next.Shards[candidate.shard] = listStoresPosition{Token: candidate.token, After: after}
+	}
+
+	if len(next.Shards) == 0 {
+		return stores, nil, nil
+	}
+
+	token, err := json.Marshal(next)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stores, token, nil
+}
+
+// listShardStores reads the stores of the shard from its position, skipping those placed on another shard, until
+// there are pageSize of them or none are left, and returns them along with the continuation token of the shard
+// after them.
+func (d *Datastore) listShardStores(ctx context.Context, name string, position listStoresPosition, options storage.ListStoresOptions, pageSize int) ([]listedStore, []byte, error) {
+	var listed []listedStore
+	token := position.Token
+	for len(listed) < pageSize {
+		shardOptions := options
+		shardOptions.Pagination = storage.NewPaginationOptions(int32(pageSize), string(token))
+		page, next, err := d.shards[name].ListStores(ctx, shardOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, store := range page {
+			if position.After != "" && store.GetId() <= position.After {
+				continue
+			}
+
+			placement, err := d.placement(ctx, store.GetId())
+			if err != nil {
+				return nil, nil, err
+			}
+			if placement == name {
+				listed = append(listed, listedStore{store: store, shard: name, token: token})
+			}
+		}
+
+		token = next
+		if len(token) == 0 {
+			break
+		}
+	}
+	return listed, token, nil
+}
+
+// ReadChanges see [storage.ChangelogBackend].ReadChanges. The continuation tokens of a store moved to another shard
+// may no longer be valid.
+func (d *Datastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, []byte, error) {
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds.ReadChanges(ctx, store, filter, options)
+}
+
+// ImportTuples see [storage.TupleImporter].ImportTuples.
+func (d *Datastore) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ds, unlock, err := d.lockStore(ctx, store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	importer, ok := storage.As[storage.TupleImporter](ds)
+	if !ok {
+		return fmt.Errorf("import tuples: %w", ErrUnsupported)
+	}
+	return importer.ImportTuples(ctx, store, writes)
+}
+
+// MaxTuplesPerImport returns the smallest maximum number of tuples per import of the shards, or their maximum
+// number of tuples per write if they can't import tuples.
+func (d *Datastore) MaxTuplesPerImport() int {
+	maxTuples := 0
+	for i, name := range d.names {
+		n := d.shards[name].MaxTuplesPerWrite()
+		if importer, ok := storage.As[storage.TupleImporter](d.shards[name]); ok {
+			n = importer.MaxTuplesPerImport()
+		}
+		if i == 0 || n < maxTuples {
+			maxTuples = n
+		}
+	}
+	return maxTuples
+}
+
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples. The expired tuples are deleted from the shards
+// one after the other, skipping those which can't.
+func (d *Datastore) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	deleted := 0
+	for _, name := range d.names {
+		reaper, ok := storage.As[storage.TupleReaper](d.shards[name])
+		if !ok {
+			continue
+		}
+
+		n, err := reaper.DeleteExpiredTuples(ctx, now, limit-deleted)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		if deleted >= limit {
+			break
+		}
+	}
+	return deleted, nil
+}
+
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (d *Datastore) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	pruner, err := shardAs[storage.ChangelogPruner](ctx, d, store, "changelog pruning")
+	if err != nil {
+		return 0, err
+	}
+	return pruner.PruneChanges(ctx, store, olderThan, keepCount, limit)
+}
+
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (d *Datastore) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	pruner, err := shardAs[storage.ChangelogPruner](ctx, d, store, "changelog pruning")
+	if err != nil {
+		return 0, err
+	}
+	return pruner.CompactChanges(ctx, store, olderThan, limit)
+}
+
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (d *Datastore) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	undeleter, err := shardAs[storage.StoreUndeleter](ctx, d, id, "undelete store")
+	if err != nil {
+		return nil, err
+	}
+	return undeleter.UndeleteStore(ctx, id, deletedAfter)
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores. The deleted stores of all the shards are listed,
+// including the copies left behind by the stores moved to another shard.
+func (d *Datastore) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	var ids []string
+	for _, name := range d.names {
+		purger, ok := storage.As[storage.StorePurger](d.shards[name])
+		if !ok {
+			continue
+		}
+
+		deleted, err := purger.ListDeletedStores(ctx, deletedBefore, limit-len(ids))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, deleted...)
+		if len(ids) >= limit {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore. The store is purged from all the shards it was deleted from,
+// since a copy of it is left behind on its previous shard once moved.
+func (d *Datastore) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	purged := 0
+	for _, name := range d.names {
+		purger, ok := storage.As[storage.StorePurger](d.shards[name])
+		if !ok {
+			continue
+		}
+
+		n, err := purger.PurgeStore(ctx, id, deletedBefore, limit-purged)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+		if purged >= limit {
+			break
+		}
+	}
+	return purged, nil
+}
+
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (d *Datastore) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	ds, unlock, err := d.lockStore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	labeler, ok := storage.As[storage.StoreLabeler](ds)
+	if !ok {
+		return nil, fmt.Errorf("store labels: %w", ErrUnsupported)
+	}
+	return labeler.UpdateStore(ctx, id, name, labels)
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (d *Datastore) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	labeler, err := shardAs[storage.StoreLabeler](ctx, d, id, "store labels")
+	if err != nil {
+		return nil, err
+	}
+	return labeler.ReadStoreLabels(ctx, id)
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples.
+func (d *Datastore) CountTuples(ctx context.Context, store string) (int64, error) {
+	counter, err := shardAs[storage.TupleCounter](ctx, d, store, "tuple counts")
+	if err != nil {
+		return 0, err
+	}
+	return counter.CountTuples(ctx, store)
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords.
+func (d *Datastore) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	copier, err := shardAs[storage.RecordCopier](ctx, d, store, "record copies")
+	if err != nil {
+		return nil, nil, err
+	}
+	return copier.ReadTupleRecords(ctx, store, options)
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (d *Datastore) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	copier, err := shardAs[storage.RecordCopier](ctx, d, store, "record copies")
+	if err != nil {
+		return nil, err
+	}
+	return copier.ReadTupleRecord(ctx, store, key)
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (d *Datastore) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	copier, err := shardAs[storage.RecordCopier](ctx, d, store, "record copies")
+	if err != nil {
+		return nil, err
+	}
+	return copier.ReadChangeRecords(ctx, store, after, limit)
+}
+
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (d *Datastore) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	copier, err := shardAs[storage.RecordCopier](ctx, d, store, "record copies")
+	if err != nil {
+		return err
+	}
+	return copier.ImportRecords(ctx, store, records)
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox. The events of the shards are merged in the order of their IDs,
+// which are prefixed with the name of their shard as '<shard>/<id>'. The shards without an outbox are skipped.
+//
+// The events of the catalog store, and the deletions of the copies left behind by the stores moved to another shard
+// or of their expired tuples, are deleted from the outbox rather than returned. The other changes made by a move, which copy the store to its
+// new shard, are returned like any other.
+func (d *Datastore) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	type shardEvent struct {
+		shard string
+		event *storage.Event
+	}
+
+	var events []shardEvent
+	for _, name := range d.names {
+		outbox, ok := storage.As[storage.EventOutbox](d.shards[name])
+		if !ok {
+			continue
+		}
+
+		shardEvents, err := d.readShardOutbox(ctx, name, outbox, limit)
+		if err != nil {
+			return nil, fmt.Errorf("shard '%s': %w", name, err)
+		}
+		for _, event := range shardEvents {
+			events = append(events, shardEvent{shard: name, event: event})
+		}
+	}
+
+	slices.SortFunc(events, func(a, b shardEvent) int {
+		return strings.Compare(a.event.ID, b.event.ID)
+	})
+
+	merged := make([]*storage.Event, 0, min(limit, len(events)))
+	for _, e := range events[:min(limit, len(events))] {
+		event := *e.event
+		event.ID = e.shard + "/" + event.ID
+		merged = append(merged, &event)
+	}
+	return merged, nil
+}
+
+// readShardOutbox reads up to limit events of the outbox of the shard, deleting those which aren't returned by
+// ReadOutbox.
+func (d *Datastore) readShardOutbox(ctx context.Context, name string, outbox storage.EventOutbox, limit int) ([]*storage.Event, error) {
+	for {
+		events, err := outbox.ReadOutbox(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		var kept []*storage.Event
+		var dropped []string
+		for _, event := range events {
+			drop := event.StoreID == CatalogStoreID
+			if !drop && (event.Type == storage.EventStoreDeleted || event.Type == storage.EventTuplesExpired) {
+				shard, err := d.ShardOf(ctx, event.StoreID)
+				if err != nil {
+					return nil, err
+				}
+				drop = shard != name
+			}
+
+			if drop {
+				dropped = append(dropped, event.ID)
+			} else {
+				kept = append(kept, event)
+			}
+		}
+
+		if len(dropped) == 0 {
+			return kept, nil
+		}
+		if err := outbox.DeleteOutboxEvents(ctx, dropped); err != nil {
+			return nil, err
+		}
+		if len(kept) > 0 {
+			return kept, nil
+		}
+	}
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents. The IDs are those returned by ReadOutbox,
+// prefixed with the name of their shard.
+func (d *Datastore) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	byShard := make(map[string][]string)
+	for _, id := range ids {
+		name, shardID, ok := strings.Cut(id, "/")
+		if !ok {
+			continue
+		}
+		byShard[name] = append(byShard[name], shardID)
+	}
+
+	for _, name := range d.names {
+		if len(byShard[name]) == 0 {
+			continue
+		}
+		outbox, ok := storage.As[storage.EventOutbox](d.shards[name])
+		if !ok {
+			continue
+		}
+		if err := outbox.DeleteOutboxEvents(ctx, byShard[name]); err != nil {
+			return fmt.Errorf("shard '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// shardAs returns the datastore of the shard the store is placed on as T, or ErrUnsupported if it doesn't
+// implement it.
+func shardAs[T any](ctx context.Context, d *Datastore, store string, feature string) (T, error) {
+	var zero T
+	ds, err := d.shardOf(ctx, store)
+	if err != nil {
+		return zero, err
+	}
+
+	t, ok := storage.As[T](ds)
+	if !ok {
+		return zero, fmt.Errorf("%s: %w", feature, ErrUnsupported)
+	}
+	return t, nil
+}
+
+// IsReady reports whether the catalog and all the shards are ready.
+func (d *Datastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
+	status, err := d.catalog.IsReady(ctx)
+	if err != nil || !status.IsReady {
+		return status, err
+	}
+
+	for _, name := range d.names {
+		status, err := d.shards[name].IsReady(ctx)
+		if err != nil {
+			return status, fmt.Errorf("shard '%s': %w", name, err)
+		}
+		if !status.IsReady {
+			status.Message = fmt.Sprintf("shard '%s': %s", name, status.Message)
+			return status, nil
+		}
+	}
+	return storage.ReadinessStatus{IsReady: true}, nil
+}
+
+// Close closes the catalog and the shards.
+func (d *Datastore) Close() {
+	closed := make(map[storage.OpenFGADatastore]struct{}, len(d.shards)+1)
+	for _, ds := range append([]storage.OpenFGADatastore{d.catalog}, d.shardDatastores()...) {
+		if _, ok := closed[ds]; ok {
+			continue
+		}
+		closed[ds] = struct{}{}
+		ds.Close()
+	}
+}
+
+func (d *Datastore) shardDatastores() []storage.OpenFGADatastore {
+	datastores := make([]storage.OpenFGADatastore, 0, len(d.names))
+	for _, name := range d.names {
+		datastores = append(datastores, d.shards[name])
+	}
+	return datastores
+}
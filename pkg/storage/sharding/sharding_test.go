@@ -0,0 +1,370 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// newShardedDatastore returns a Datastore over three memory shards, the first being the catalog and the default
+// shard.
+func newShardedDatastore(t *testing.T, opts ...Option) (*Datastore, map[string]storage.OpenFGADatastore) {
+	t.Helper()
+
+	shards := map[string]storage.OpenFGADatastore{
+		"a": memory.New(),
+		"b": memory.New(),
+		"c": memory.New(),
+	}
+	ds, err := New(shards["a"], "a", shards, opts...)
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	return ds, shards
+}
+
+func TestShardedDatastore(t *testing.T) {
+	ds, _ := newShardedDatastore(t, WithPlacementPolicy(HashPlacement()))
+	test.RunAllTests(t, ds, encoder.NewStringContinuationTokenSerializer())
+}
+
+func TestShardedDatastoreOutbox(t *testing.T) {
+	shards := map[string]storage.OpenFGADatastore{
+		"a": memory.New(memory.WithOutbox()),
+		"b": memory.New(memory.WithOutbox()),
+		"c": memory.New(memory.WithOutbox()),
+	}
+	ds, err := New(shards["a"], "a", shards, WithPlacementPolicy(HashPlacement()))
+	require.NoError(t, err)
+	t.Cleanup(ds.Close)
+
+	test.EventOutboxTest(t, ds, ds)
+}
+
+func TestNew(t *testing.T) {
+	shards := map[string]storage.OpenFGADatastore{"a": memory.New()}
+	t.Cleanup(shards["a"].Close)
+
+	_, err := New(shards["a"], "b", shards)
+	require.ErrorIs(t, err, ErrUnknownShard)
+
+	_, err = New(shards["a"], "a", map[string]storage.OpenFGADatastore{"a": shards["a"], "b/c": shards["a"]})
+	require.ErrorContains(t, err, "invalid shard name 'b/c'")
+}
+
+func TestPlacement(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores_are_created_on_the_shard_picked_by_the_policy", func(t *testing.T) {
+		ds, shards := newShardedDatastore(t, WithPlacementPolicy(ShardPlacement("b")))
+
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "acme"})
+		require.NoError(t, err)
+
+		shard, err := ds.ShardOf(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, "b", shard)
+
+		_, err = shards["a"].GetStore(ctx, store.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+		_, err = shards["b"].GetStore(ctx, store.GetId())
+		require.NoError(t, err)
+
+		err = ds.Write(ctx, store.GetId(), nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:1", "viewer", "user:anne")})
+		require.NoError(t, err)
+		_, err = shards["b"].ReadUserTuple(ctx, store.GetId(), tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("placements_are_persisted_in_the_catalog", func(t *testing.T) {
+		ds, shards := newShardedDatastore(t, WithPlacementPolicy(ShardPlacement("c")))
+
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "acme"})
+		require.NoError(t, err)
+
+		reopened, err := New(shards["a"], "a", shards)
+		require.NoError(t, err)
+
+		shard, err := reopened.ShardOf(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, "c", shard)
+
+		got, err := reopened.GetStore(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, "acme", got.GetName())
+	})
+
+	t.Run("stores_without_a_placement_are_on_the_default_shard", func(t *testing.T) {
+		ds, shards := newShardedDatastore(t)
+
+		store, err := shards["a"].CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "legacy"})
+		require.NoError(t, err)
+
+		got, err := ds.GetStore(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, "legacy", got.GetName())
+	})
+
+	t.Run("unknown_shard", func(t *testing.T) {
+		ds, _ := newShardedDatastore(t, WithPlacementPolicy(ShardPlacement("d")))
+
+		_, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "acme"})
+		require.ErrorIs(t, err, ErrUnknownShard)
+	})
+}
+
+func TestListStores(t *testing.T) {
+	ctx := context.Background()
+	ds, shards := newShardedDatastore(t, WithPlacementPolicy(HashPlacement()))
+
+	var ids []string
+	for i := 0; i < 10; i++ {
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "store"})
+		require.NoError(t, err)
+		ids = append(ids, store.GetId())
+	}
+
+	// a copy of a store on another shard, as left while it is moved, isn't listed
+	shard, err := ds.ShardOf(ctx, ids[0])
+	require.NoError(t, err)
+	copyShard := "a"
+	if shard == "a" {
+		copyShard = "b"
+	}
+	_, err = shards[copyShard].CreateStore(ctx, &openfgav1.Store{Id: ids[0], Name: "copy"})
+	require.NoError(t, err)
+
+	for pageSize := 1; pageSize <= 11; pageSize++ {
+		var listed []string
+		var token []byte
+		for {
+			stores, next, err := ds.ListStores(ctx, storage.ListStoresOptions{
+				Pagination: storage.NewPaginationOptions(int32(pageSize), string(token)),
+			})
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(stores), pageSize)
+			for _, store := range stores {
+				require.Equal(t, "store", store.GetName())
+				listed = append(listed, store.GetId())
+			}
+
+			if len(next) == 0 {
+				break
+			}
+			token = next
+		}
+		// the stores of the shards are merged in the order of their IDs
+		require.Equal(t, ids, listed)
+	}
+
+	_, _, err = ds.ListStores(ctx, storage.ListStoresOptions{
+		Pagination: storage.NewPaginationOptions(3, `{"shards":{"d":{}}}`),
+	})
+	require.ErrorIs(t, err, storage.ErrInvalidContinuationToken)
+}
+
+func TestMoveStore(t *testing.T) {
+	ctx := context.Background()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type doc
+			relations
+				define viewer: [user]`)
+	latestModel := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type doc
+			relations
+				define viewer: [user]
+				define editor: [user]`)
+
+	setup := func(t *testing.T) (*Datastore, map[string]storage.OpenFGADatastore, string) {
+		ds, shards := newShardedDatastore(t, WithPlacementCacheTTL(0))
+
+		store, err := ds.CreateStoreWithLabels(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "acme"}, map[string]string{"tier": "gold"})
+		require.NoError(t, err)
+
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, store.GetId(), model))
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, store.GetId(), latestModel))
+		require.NoError(t, ds.WriteAssertions(ctx, store.GetId(), model.GetId(), []*openfgav1.Assertion{
+			{TupleKey: tuple.NewAssertionTupleKey("doc:1", "viewer", "user:anne"), Expectation: true},
+		}))
+
+		require.NoError(t, ds.Write(ctx, store.GetId(), nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("doc:1", "viewer", "user:bob"),
+		}))
+		require.NoError(t, ds.Write(ctx, store.GetId(), []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("doc:1", "viewer", "user:bob")),
+		}, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("doc:2", "editor", "user:bob"),
+		}))
+
+		return ds, shards, store.GetId()
+	}
+
+	requireMoved := func(t *testing.T, ds *Datastore, shards map[string]storage.OpenFGADatastore, store string, extraKeys ...string) {
+		shard, err := ds.ShardOf(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, "b", shard)
+
+		_, err = shards["a"].GetStore(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		got, err := ds.GetStore(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, "acme", got.GetName())
+
+		labels, err := ds.ReadStoreLabels(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"tier": "gold"}, labels)
+
+		latest, err := shards["b"].FindLatestAuthorizationModel(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, latestModel.GetId(), latest.GetId())
+
+		assertions, err := shards["b"].ReadAssertions(ctx, store, model.GetId())
+		require.NoError(t, err)
+		require.Len(t, assertions, 1)
+
+		tuples, _, err := shards["b"].ReadPage(ctx, store, nil, storage.ReadPageOptions{Pagination: storage.NewPaginationOptions(1000, "")})
+		require.NoError(t, err)
+		var keys []string
+		for _, tp := range tuples {
+			keys = append(keys, tuple.TupleKeyToString(tp.GetKey()))
+		}
+		require.ElementsMatch(t, append([]string{"doc:1#viewer@user:anne", "doc:2#editor@user:bob"}, extraKeys...), keys)
+	}
+
+	readChanges := func(t *testing.T, ds storage.OpenFGADatastore, store string) ([]*openfgav1.TupleChange, string) {
+		changes, token, err := ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(100, ""),
+		})
+		require.NoError(t, err)
+		return changes, string(token)
+	}
+
+	readRecord := func(t *testing.T, ds storage.OpenFGADatastore, store string, tk *openfgav1.TupleKey) *storage.TupleRecord {
+		copier, ok := storage.As[storage.RecordCopier](ds)
+		require.True(t, ok)
+		record, err := copier.ReadTupleRecord(ctx, store, tk)
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("moves_the_store_with_its_changelog_as_it_is", func(t *testing.T) {
+		ds, shards, store := setup(t)
+
+		anne := tuple.NewTupleKey("doc:1", "viewer", "user:anne")
+		sourceRecord := readRecord(t, shards["a"], store, anne)
+		sourceChanges, token := readChanges(t, shards["a"], store)
+
+		require.NoError(t, ds.MoveStore(ctx, store, "b"))
+		requireMoved(t, ds, shards, store)
+
+		// the changes keep their timestamps, and the tuples their ULIDs
+		changes, _ := readChanges(t, shards["b"], store)
+		if diff := cmp.Diff(sourceChanges, changes, protocmp.Transform()); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+		require.Equal(t, sourceRecord.Ulid, readRecord(t, shards["b"], store, anne).Ulid)
+
+		// the writes now go to the new shard
+		require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:3", "viewer", "user:carl")}))
+		_, err := shards["b"].ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:3", "viewer", "user:carl"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+
+		// the continuation tokens obtained before the move are still valid
+		changes, _, err = ds.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(100, token),
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, "doc:3#viewer@user:carl", tuple.TupleKeyToString(changes[0].GetTupleKey()))
+
+		// moving it again to the same shard does nothing
+		require.NoError(t, ds.MoveStore(ctx, store, "b"))
+	})
+
+	t.Run("reconciles_the_tuples_of_a_pruned_changelog", func(t *testing.T) {
+		ds, shards, store := setup(t)
+
+		anne := tuple.NewTupleKey("doc:1", "viewer", "user:anne")
+		carl := tuple.NewTupleKey("doc:3", "viewer", "user:carl")
+		require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{carl}, storage.WithExpiresAt(time.Now().Add(time.Hour))))
+		require.NoError(t, ds.Write(ctx, store, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:4", "viewer", "user:dan")}))
+		anneRecord := readRecord(t, shards["a"], store, anne)
+		carlRecord := readRecord(t, shards["a"], store, carl)
+
+		pruned, err := ds.PruneChanges(ctx, store, time.Time{}, 1, 100)
+		require.NoError(t, err)
+		require.Equal(t, 5, pruned)
+
+		require.NoError(t, ds.MoveStore(ctx, store, "b"))
+		requireMoved(t, ds, shards, store, "doc:3#viewer@user:carl", "doc:4#viewer@user:dan")
+
+		// the tuples reconciled keep their ULIDs and expiry
+		require.Equal(t, anneRecord.Ulid, readRecord(t, shards["b"], store, anne).Ulid)
+		record := readRecord(t, shards["b"], store, carl)
+		require.Equal(t, carlRecord.Ulid, record.Ulid)
+		require.NotNil(t, record.ExpiresAt)
+		require.True(t, carlRecord.ExpiresAt.Equal(*record.ExpiresAt))
+	})
+
+	t.Run("reconciles_the_tuples_over_several_pages", func(t *testing.T) {
+		ds, shards, store := setup(t)
+
+		var extraKeys []string
+		for batch := 0; batch < 3; batch++ {
+			var writes []*openfgav1.TupleKey
+			for i := 0; i < 50; i++ {
+				tk := tuple.NewTupleKey(fmt.Sprintf("doc:%d", 100+batch*50+i), "viewer", "user:eve")
+				writes = append(writes, tk)
+				extraKeys = append(extraKeys, tuple.TupleKeyToString(tk))
+			}
+			require.NoError(t, ds.Write(ctx, store, nil, writes))
+		}
+
+		// a tuple left on the target by an earlier attempt, which the store doesn't have
+		require.NoError(t, shards["b"].Write(ctx, store, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:120", "viewer", "user:stale")}))
+
+		_, err := ds.PruneChanges(ctx, store, time.Time{}, 1, 1000)
+		require.NoError(t, err)
+
+		require.NoError(t, ds.MoveStore(ctx, store, "b"))
+		requireMoved(t, ds, shards, store, extraKeys...)
+	})
+
+	t.Run("unknown_shard", func(t *testing.T) {
+		ds, _, store := setup(t)
+
+		err := ds.MoveStore(ctx, store, "d")
+		require.ErrorIs(t, err, ErrUnknownShard)
+	})
+
+	t.Run("deleted_store", func(t *testing.T) {
+		ds, _, store := setup(t)
+		require.NoError(t, ds.DeleteStore(ctx, store))
+
+		err := ds.MoveStore(ctx, store, "b")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
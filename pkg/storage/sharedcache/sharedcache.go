@@ -0,0 +1,219 @@
+// Package sharedcache contains a cache shared by several OpenFGA servers through a Redis (or Valkey) server.
+package sharedcache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	defaultTimeout = 50 * time.Millisecond
+	defaultBackoff = 5 * time.Second
+)
+
+var sharedCacheErrorCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "shared_cache_error_count",
+	Help:      "The total number of failed requests to the shared cache, after which it is bypassed for a while.",
+})
+
+var _ storage.InMemoryCache[any] = (*SharedCache)(nil)
+
+// SharedCache is a cache shared by several servers, layered over a local cache: entries are read from the local
+// cache first, then from the shared one, and written to both. Entries whose type is not registered with
+// storage.RegisterCacheEntryCodec are only cached locally.
+//
+// When a request to the shared cache fails, the shared cache is bypassed for a while and only the local cache is
+// used, so that a shared cache being down degrades the caching without failing or slowing down queries.
+type SharedCache struct {
+	local     storage.InMemoryCache[any]
+	client    redis.UniversalClient
+	keyPrefix string
+	timeout   time.Duration
+	backoff   time.Duration
+	logger    logger.Logger
+
+	// unavailableUntil is the time, in Unix nanoseconds, until which the shared cache is bypassed.
+	unavailableUntil atomic.Int64
+	closeOnce        sync.Once
+}
+
+type SharedCacheOpt func(*SharedCache)
+
+// WithKeyPrefix sets a prefix added to the keys of the shared cache, so that it can hold the entries of
+// several deployments.
+func WithKeyPrefix(prefix string) SharedCacheOpt {
+	return func(c *SharedCache) {
+		c.keyPrefix = prefix
+	}
+}
+
+// WithTimeout sets the timeout of the requests to the shared cache.
+func WithTimeout(timeout time.Duration) SharedCacheOpt {
+	return func(c *SharedCache) {
+		c.timeout = timeout
+	}
+}
+
+// WithBackoff sets how long the shared cache is bypassed after a request to it failed.
+func WithBackoff(backoff time.Duration) SharedCacheOpt {
+	return func(c *SharedCache) {
+		c.backoff = backoff
+	}
+}
+
+func WithLogger(l logger.Logger) SharedCacheOpt {
+	return func(c *SharedCache) {
+		c.logger = l
+	}
+}
+
+// New returns a SharedCache storing its entries in the local cache and through the client. The cache takes ownership
+// of both: stopping it stops the local cache and closes the client.
+func New(client redis.UniversalClient, local storage.InMemoryCache[any], opts ...SharedCacheOpt) *SharedCache {
+	c := &SharedCache{
+		local:   local,
+		client:  client,
+		timeout: defaultTimeout,
+		backoff: defaultBackoff,
+		logger:  logger.NewNoopLogger(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Get see [storage.InMemoryCache].Get. An entry found in the shared cache only is also cached locally, until it
+// expires from the shared cache.
+func (c *SharedCache) Get(key string) any {
+	if value := c.local.Get(key); value != nil {
+		return value
+	}
+
+	if !c.available() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var (
+		get *redis.StringCmd
+		ttl *redis.DurationCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.keyPrefix+key)
+		ttl = pipe.PTTL(ctx, c.keyPrefix+key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		c.fail(err)
+		return nil
+	}
+
+	data, _ := get.Bytes()
+	value, err := storage.UnmarshalCacheEntry(data)
+	if err != nil {
+		c.logger.Debug("discarding shared cache entry", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+
+	// a negative TTL means the key doesn't expire
+	expiration := ttl.Val()
+	if expiration < 0 {
+		expiration = math.MaxInt
+	}
+	c.local.Set(key, value, expiration)
+
+	return value
+}
+
+// Set see [storage.InMemoryCache].Set.
+func (c *SharedCache) Set(key string, value any, ttl time.Duration) {
+	c.local.Set(key, value, ttl)
+
+	if ttl <= 0 || !c.available() {
+		return
+	}
+
+	data, err := storage.MarshalCacheEntry(value)
+	if err != nil {
+		if !errors.Is(err, storage.ErrCacheEntryNotSerializable) {
+			c.logger.Debug("failed to serialize shared cache entry", zap.String("key", key), zap.Error(err))
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.keyPrefix+key, data, ttl).Err(); err != nil {
+		c.fail(err)
+	}
+}
+
+// Delete see [storage.InMemoryCache].Delete.
+func (c *SharedCache) Delete(key string) {
+	c.local.Delete(key)
+
+	if !c.available() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.keyPrefix+key).Err(); err != nil {
+		c.fail(err)
+	}
+}
+
+// Stop see [storage.InMemoryCache].Stop.
+func (c *SharedCache) Stop() {
+	c.closeOnce.Do(func() {
+		c.local.Stop()
+		_ = c.client.Close()
+	})
+}
+
+func (c *SharedCache) available() bool {
+	return time.Now().UnixNano() >= c.unavailableUntil.Load()
+}
+
+// fail bypasses the shared cache for the backoff duration.
+func (c *SharedCache) fail(err error) {
+	sharedCacheErrorCounter.Inc()
+
+	until := c.unavailableUntil.Load()
+	now := time.Now()
+	if now.UnixNano() < until {
+		// another request already failed
+		return
+	}
+
+	if c.unavailableUntil.CompareAndSwap(until, now.Add(c.backoff).UnixNano()) {
+		c.logger.Warn("shared cache unavailable, falling back to the local cache",
+			zap.Duration("backoff", c.backoff),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,106 @@
+package sharedcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func newSharedCache(t *testing.T, addr string, opts ...SharedCacheOpt) *SharedCache {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	cache := New(client, storage.NewInMemoryLRUCache[any](), append([]SharedCacheOpt{WithTimeout(time.Second)}, opts...)...)
+	t.Cleanup(cache.Stop)
+	return cache
+}
+
+func TestSharedCache(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	server := miniredis.RunT(t)
+
+	first := newSharedCache(t, server.Addr(), WithKeyPrefix("test/"))
+	second := newSharedCache(t, server.Addr(), WithKeyPrefix("test/"))
+
+	lastModified := time.Now().UTC().Truncate(time.Millisecond)
+
+	t.Run("entries_are_shared", func(t *testing.T) {
+		entry := &storage.TupleIteratorCacheEntry{
+			Tuples: []*openfgav1.Tuple{
+				{Key: tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+			},
+			LastModified: lastModified,
+		}
+		first.Set("ic.key", entry, time.Minute)
+		require.True(t, server.Exists("test/ic.key"))
+		require.Equal(t, time.Minute, server.TTL("test/ic.key"))
+
+		cached, ok := second.Get("ic.key").(*storage.TupleIteratorCacheEntry)
+		require.True(t, ok)
+		require.True(t, lastModified.Equal(cached.LastModified))
+		require.Len(t, cached.Tuples, 1)
+		require.Equal(t, "document:1#viewer@user:anne", tuple.TupleKeyToString(cached.Tuples[0].GetKey()))
+	})
+
+	t.Run("entries_are_cached_locally", func(t *testing.T) {
+		first.Set("cc.store", &storage.ChangelogCacheEntry{LastModified: lastModified}, time.Minute)
+		require.NotNil(t, second.Get("cc.store"))
+
+		server.FlushAll()
+		require.NotNil(t, second.Get("cc.store"))
+	})
+
+	t.Run("deletes_are_shared", func(t *testing.T) {
+		first.Set("iq.key", &storage.InvalidEntityCacheEntry{LastModified: lastModified}, time.Minute)
+		second.Delete("iq.key")
+		require.False(t, server.Exists("test/iq.key"))
+		require.Nil(t, newSharedCache(t, server.Addr(), WithKeyPrefix("test/")).Get("iq.key"))
+	})
+
+	t.Run("unregistered_entries_are_local", func(t *testing.T) {
+		first.Set("key", "value", time.Minute)
+		require.Equal(t, "value", first.Get("key"))
+		require.False(t, server.Exists("test/key"))
+		require.Nil(t, second.Get("key"))
+	})
+
+	t.Run("malformed_entries_are_ignored", func(t *testing.T) {
+		require.NoError(t, server.Set("test/malformed", "value"))
+		require.Nil(t, second.Get("malformed"))
+	})
+}
+
+func TestSharedCacheFallsBackToLocalCache(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	server := miniredis.RunT(t)
+	cache := newSharedCache(t, server.Addr(), WithBackoff(time.Hour))
+
+	server.SetError("unavailable")
+
+	entry := &storage.ChangelogCacheEntry{LastModified: time.Now()}
+	cache.Set("cc.store", entry, time.Minute)
+	require.False(t, cache.available())
+	require.Equal(t, entry, cache.Get("cc.store"))
+
+	// the shared cache is bypassed until the backoff elapses
+	server.SetError("")
+	cache.Set("cc.other", entry, time.Minute)
+	require.False(t, server.Exists("cc.other"))
+
+	cache.unavailableUntil.Store(0)
+	cache.Set("cc.other", entry, time.Minute)
+	require.True(t, server.Exists("cc.other"))
+}
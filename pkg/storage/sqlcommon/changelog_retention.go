@@ -0,0 +1,215 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// ReadChangelogHorizon returns the ULID of the last change of the store deleted from the changelog, or an empty
+// string if none was. Continuation tokens pointing before it have expired. The statement builder must run with the
+// database.
+func ReadChangelogHorizon(ctx context.Context, stbl sq.StatementBuilderType, store string) (string, error) {
+	var horizon string
+	err := stbl.
+		Select("ulid").
+		From("changelog_horizon").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(&horizon)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return horizon, err
+}
+
+// ChangelogPruneCutoff returns the ULID before which the changes of the store are pruned by
+// [storage.ChangelogPruner].PruneChanges, or an empty string if none are. The statement builder must run with the
+// database.
+func ChangelogPruneCutoff(ctx context.Context, stbl sq.StatementBuilderType, store string, olderThan time.Time, keepCount int) (string, error) {
+	var cutoff string
+	if !olderThan.IsZero() {
+		cutoff = storage.ChangesBefore(olderThan).String()
+	}
+
+	if keepCount > 0 {
+		var oldestKept string
+		err := stbl.
+			Select("ulid").
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			OrderBy("ulid desc").
+			Offset(uint64(keepCount - 1)).
+			Limit(1).
+			QueryRowContext(ctx).
+			Scan(&oldestKept)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+		if oldestKept > cutoff {
+			cutoff = oldestKept
+		}
+	}
+
+	return cutoff, nil
+}
+
+// AdvanceChangelogHorizon records that the changes of the store up to the change horizon were deleted from the
+// changelog, unless later ones already were. The statement builder must run with the transaction deleting them.
+func AdvanceChangelogHorizon(ctx context.Context, stbl sq.StatementBuilderType, store, horizon string) error {
+	res, err := stbl.
+		Update("changelog_horizon").
+		Set("ulid", horizon).
+		Where(sq.Eq{"store": store}).
+		Where(sq.Lt{"ulid": horizon}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	if updated, err := res.RowsAffected(); err != nil || updated > 0 {
+		return err
+	}
+
+	var count int
+	err = stbl.
+		Select("COUNT(*)").
+		From("changelog_horizon").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = stbl.
+		Insert("changelog_horizon").
+		Columns("store", "ulid").
+		Values(store, horizon).
+		ExecContext(ctx)
+	return err
+}
+
+// PruneChanges provides the common method for pruning the changelog across sql storage.
+func PruneChanges(ctx context.Context, dbInfo *DBInfo, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	cutoff, err := ChangelogPruneCutoff(ctx, dbInfo.stbl, store, olderThan, keepCount)
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	if cutoff == "" {
+		return 0, nil
+	}
+
+	rows, err := dbInfo.stbl.
+		Select("ulid").
+		From("changelog").
+		Where(sq.Eq{"store": store}).
+		Where(sq.Lt{"ulid": cutoff}).
+		OrderBy("ulid").
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var ulids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return 0, dbInfo.HandleSQLError(err)
+		}
+		ulids = append(ulids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+
+	if len(ulids) == 0 {
+		return 0, nil
+	}
+
+	if err := deleteChanges(ctx, dbInfo, store, ulids, ulids[len(ulids)-1]); err != nil {
+		return 0, err
+	}
+	return len(ulids), nil
+}
+
+// CompactChanges provides the common method for compacting the changelog across sql storage.
+func CompactChanges(ctx context.Context, dbInfo *DBInfo, store string, olderThan time.Time, limit int) (int, error) {
+	if olderThan.IsZero() {
+		return 0, nil
+	}
+
+	rows, err := dbInfo.stbl.
+		Select("ulid", "object_type", "object_id", "relation", "_user", "operation").
+		From("changelog").
+		Where(sq.Eq{"store": store}).
+		Where(sq.Lt{"ulid": storage.ChangesBefore(olderThan).String()}).
+		OrderBy("ulid").
+		QueryContext(ctx)
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	collapsible := storage.NewCollapsibleChanges()
+	for collapsible.Len() < limit && rows.Next() {
+		var id, objectType, objectID, relation, user string
+		var operation int
+		if err := rows.Scan(&id, &objectType, &objectID, &relation, &user, &operation); err != nil {
+			return 0, dbInfo.HandleSQLError(err)
+		}
+		tk := tupleUtils.NewTupleKey(tupleUtils.BuildObject(objectType, objectID), relation, user)
+		collapsible.Add(id, tk, openfgav1.TupleOperation(operation))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	_ = rows.Close()
+
+	ulids, horizon := collapsible.ULIDs(limit)
+	if len(ulids) == 0 {
+		return 0, nil
+	}
+
+	if err := deleteChanges(ctx, dbInfo, store, ulids, horizon); err != nil {
+		return 0, err
+	}
+	return len(ulids) / 2, nil
+}
+
+// deleteChanges deletes the changes of the store, and advances its horizon, in a single transaction.
+func deleteChanges(ctx context.Context, dbInfo *DBInfo, store string, ulids []string, horizon string) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	_, err = dbInfo.stbl.
+		Delete("changelog").
+		Where(sq.Eq{"store": store, "ulid": ulids}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	if err := AdvanceChangelogHorizon(ctx, dbInfo.stbl.RunWith(txn), store, horizon); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	return nil
+}
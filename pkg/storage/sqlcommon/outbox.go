@@ -0,0 +1,91 @@
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// InsertOutboxEvent inserts the event in the outbox, unless it is nil. The statement builder must run with the
+// transaction making the change of the event.
+func InsertOutboxEvent(ctx context.Context, stbl sq.StatementBuilderType, event *storage.Event) error {
+	if event == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = stbl.
+		Insert("outbox").
+		Columns("id", "store", "event_type", "payload", "inserted_at").
+		Values(event.ID, event.StoreID, string(event.Type), payload, event.Time).
+		ExecContext(ctx)
+	return err
+}
+
+// recordEvent inserts the event in the outbox as part of the transaction, if the outbox is enabled.
+func (dbInfo *DBInfo) recordEvent(ctx context.Context, txn sq.BaseRunner, event *storage.Event) error {
+	if !dbInfo.Outbox {
+		return nil
+	}
+	if err := InsertOutboxEvent(ctx, dbInfo.stbl.RunWith(txn), event); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	return nil
+}
+
+// ReadOutbox provides the common method for reading the events of the outbox across sql storage. The statement
+// builder must run with the database.
+func ReadOutbox(ctx context.Context, stbl sq.StatementBuilderType, limit int) ([]*storage.Event, error) {
+	rows, err := stbl.
+		Select("id", "payload").
+		From("outbox").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*storage.Event
+	for rows.Next() {
+		var id string
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			return nil, err
+		}
+
+		var event storage.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode outbox event '%s': %w", id, err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// DeleteOutboxEvents provides the common method for deleting events from the outbox across sql storage. The
+// statement builder must run with the database.
+func DeleteOutboxEvents(ctx context.Context, stbl sq.StatementBuilderType, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := stbl.
+		Delete("outbox").
+		Where(sq.Eq{"id": ids}).
+		ExecContext(ctx)
+	return err
+}
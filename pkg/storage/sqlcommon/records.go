@@ -0,0 +1,319 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// tupleRecordColumns are the columns of a tuple read as it is stored, in the order of scanTupleRecord.
+var tupleRecordColumns = []string{
+	"store", "object_type", "object_id", "relation", "_user",
+	"condition_name", "condition_context", "ulid", "inserted_at", "expires_at",
+}
+
+// ReadTupleRecords provides the common method for reading the tuples of a store as they are stored across sql
+// storage, ordered by their primary key. The continuation token is the last tuple, as a string. The statement builder
+// must run with the database.
+func ReadTupleRecords(ctx context.Context, stbl sq.StatementBuilderType, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	sb := stbl.
+		Select(tupleRecordColumns...).
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		OrderBy("object_type", "object_id", "relation", "_user")
+	if options.From != "" {
+		tk, err := tupleUtils.ParseTupleString(options.From)
+		if err != nil {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		sb = sb.Where(sq.Expr("(object_type, object_id, relation, _user) > (?, ?, ?, ?)", objectType, objectID, tk.GetRelation(), tk.GetUser()))
+	}
+	if options.PageSize > 0 {
+		sb = sb.Limit(uint64(options.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var records []*storage.TupleRecord
+	for rows.Next() {
+		record, err := scanTupleRecord(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if options.PageSize > 0 && len(records) > options.PageSize {
+		records = records[:options.PageSize]
+		return records, []byte(tupleUtils.TupleKeyToString(records[len(records)-1].AsTuple().GetKey())), nil
+	}
+	return records, nil, nil
+}
+
+// ReadTupleRecord provides the common method for reading a tuple of a store as it is stored across sql storage,
+// expired or not. It returns storage.ErrNotFound if there is none. The statement builder must run with the database.
+func ReadTupleRecord(ctx context.Context, stbl sq.StatementBuilderType, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+	row := stbl.
+		Select(tupleRecordColumns...).
+		From("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    key.GetRelation(),
+			"_user":       key.GetUser(),
+		}).
+		QueryRowContext(ctx)
+
+	record, err := scanTupleRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	return record, err
+}
+
+func scanTupleRecord(row sq.RowScanner) (*storage.TupleRecord, error) {
+	var record storage.TupleRecord
+	var conditionName sql.NullString
+	var conditionContext []byte
+	var expiresAt sql.NullTime
+	err := row.Scan(
+		&record.Store,
+		&record.ObjectType,
+		&record.ObjectID,
+		&record.Relation,
+		&record.User,
+		&conditionName,
+		&conditionContext,
+		&record.Ulid,
+		&record.InsertedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.ConditionName = conditionName.String
+	if conditionContext != nil {
+		record.ConditionContext = &structpb.Struct{}
+		if err := proto.Unmarshal(conditionContext, record.ConditionContext); err != nil {
+			return nil, err
+		}
+	}
+	if expiresAt.Valid {
+		record.ExpiresAt = &expiresAt.Time
+	}
+	return &record, nil
+}
+
+// ReadChangeRecords provides the common method for reading the changes of a store as they are stored across sql
+// storage. The statement builder must run with the database.
+func ReadChangeRecords(ctx context.Context, stbl sq.StatementBuilderType, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	sb := stbl.
+		Select(
+			"ulid", "object_type", "object_id", "relation", "_user",
+			"operation", "condition_name", "condition_context", "inserted_at",
+		).
+		From("changelog").
+		Where(sq.Eq{"store": store}).
+		OrderBy("ulid")
+	if after != "" {
+		sb = sb.Where(sq.Gt{"ulid": after})
+	}
+	if limit > 0 {
+		sb = sb.Limit(uint64(limit))
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*storage.ChangeRecord
+	for rows.Next() {
+		var record storage.ChangeRecord
+		var objectType, objectID, relation, user string
+		var operation int
+		var conditionName sql.NullString
+		var conditionContext []byte
+		err := rows.Scan(
+			&record.Ulid,
+			&objectType,
+			&objectID,
+			&relation,
+			&user,
+			&operation,
+			&conditionName,
+			&conditionContext,
+			&record.InsertedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var conditionContextStruct *structpb.Struct
+		if conditionContext != nil {
+			conditionContextStruct = &structpb.Struct{}
+			if err := proto.Unmarshal(conditionContext, conditionContextStruct); err != nil {
+				return nil, err
+			}
+		}
+
+		record.Operation = openfgav1.TupleOperation(operation)
+		record.TupleKey = tupleUtils.NewTupleKeyWithCondition(
+			tupleUtils.BuildObject(objectType, objectID),
+			relation,
+			user,
+			conditionName.String,
+			conditionContextStruct,
+		)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// ImportRecords provides the common method for importing the tuples and changes of a store as they are stored
+// across sql storage, see [storage.RecordCopier].ImportRecords.
+func ImportRecords(ctx context.Context, dbInfo *DBInfo, store string, records storage.RecordImport) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	if len(records.Changes) > 0 {
+		changelogBuilder := dbInfo.stbl.
+			Insert("changelog").
+			Columns(
+				"store", "object_type", "object_id", "relation", "_user",
+				"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+			)
+		for _, change := range records.Changes {
+			tk := change.TupleKey
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			conditionName, conditionContext, err := MarshalRelationshipCondition(tk.GetCondition())
+			if err != nil {
+				return err
+			}
+
+			changelogBuilder = changelogBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(), tk.GetUser(),
+				conditionName, conditionContext, change.Operation, change.Ulid, change.InsertedAt.UTC().Truncate(dbInfo.InsertedAtPrecision),
+			)
+		}
+
+		// the changes copied already are skipped
+		if _, err := dbInfo.InsertIgnore(changelogBuilder).RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn.
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+
+	var tupleDelta int64
+	for _, tk := range records.Deletes {
+		deleted, err := deleteTupleRecord(ctx, dbInfo, txn, store, tk)
+		if err != nil {
+			return err
+		}
+		tupleDelta -= deleted
+	}
+
+	if len(records.Tuples) > 0 {
+		insertBuilder := dbInfo.stbl.
+			Insert("tuple").
+			Columns(
+				"store", "object_type", "object_id", "relation", "_user", "user_type",
+				"condition_name", "condition_context", "ulid", "inserted_at", "expires_at",
+			)
+		for _, record := range records.Tuples {
+			tk := record.AsTuple().GetKey()
+			replaced, err := deleteTupleRecord(ctx, dbInfo, txn, store, tk)
+			if err != nil {
+				return err
+			}
+			tupleDelta += 1 - replaced
+
+			conditionName, conditionContext, err := MarshalRelationshipCondition(tk.GetCondition())
+			if err != nil {
+				return err
+			}
+
+			var expiresAt *time.Time
+			if record.ExpiresAt != nil {
+				expiresAt = new(time.Time)
+				*expiresAt = record.ExpiresAt.UTC().Truncate(time.Microsecond)
+			}
+
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			insertBuilder = insertBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(), tk.GetUser(), tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+				conditionName, conditionContext, record.Ulid, record.InsertedAt.UTC().Truncate(dbInfo.InsertedAtPrecision), expiresAt,
+			)
+		}
+
+		if _, err := insertBuilder.RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn.
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+
+	if err := AddTupleCount(ctx, dbInfo.stbl.RunWith(txn), dbInfo.UpsertTupleCount, store, tupleDelta); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	if len(records.Changes) > 0 {
+		if err := dbInfo.notifyChangelog(ctx, txn, store); err != nil {
+			return err
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	return nil
+}
+
+// deleteTupleRecord deletes the tuple of the store with the key, expired or not, as part of the transaction, and
+// returns how many tuples were deleted.
+func deleteTupleRecord(ctx context.Context, dbInfo *DBInfo, txn *sql.Tx, store string, tk tupleUtils.TupleWithoutCondition) (int64, error) {
+	objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+	res, err := dbInfo.stbl.
+		Delete("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    tk.GetRelation(),
+			"_user":       tk.GetUser(),
+		}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	return deleted, nil
+}
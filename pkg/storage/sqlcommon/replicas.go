@@ -0,0 +1,266 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	primaryPool = "primary"
+
+	DefaultReplicaMaxLag        = 5 * time.Second
+	DefaultReplicaCheckInterval = time.Second
+)
+
+var (
+	readRoutingCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "datastore_read_routing_count",
+		Help:      "The total number of tuple reads routed to each pool (the primary or a read replica) of the datastore.",
+	}, []string{"pool"})
+
+	replicaLagGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "datastore_replica_lag_seconds",
+		Help:      "How far behind the primary each read replica of the datastore was when last checked.",
+	}, []string{"pool"})
+
+	replicaHealthyGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "datastore_replica_healthy",
+		Help:      "Whether each read replica of the datastore can serve reads (1) or not (0).",
+	}, []string{"pool"})
+)
+
+// ReplicaLagFunc returns how far behind its primary the database is. A database which isn't a replica has no lag.
+type ReplicaLagFunc func(ctx context.Context, db *sql.DB) (time.Duration, error)
+
+// ReadReplicas routes the tuple reads of a datastore between its primary database and its read replicas.
+//
+// Reads preferring to minimize latency are spread over the healthy replicas, and fall back to the primary when
+// there are none. Reads requiring a higher consistency, or bound to a revision the replicas have not replayed yet,
+// go to the primary, as does anything else than tuple reads (writes, authorization models, changes, ...).
+//
+// The replicas are checked in the background: a replica is unhealthy when its lag can't be measured, exceeds the
+// configured maximum, or wasn't measured recently.
+type ReadReplicas struct {
+	primary  sq.StatementBuilderType
+	replicas []*readReplica
+	lagFunc  ReplicaLagFunc
+	maxLag   time.Duration
+	interval time.Duration
+	logger   logger.Logger
+
+	next   atomic.Uint64
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type readReplica struct {
+	pool string
+	db   *sql.DB
+	stbl sq.StatementBuilderType
+
+	// checkedAt is the time, in Unix nanoseconds, the lag of the replica was last measured. It is zero while the
+	// replica is unhealthy.
+	checkedAt atomic.Int64
+	// freshAsOf is the time, in Unix nanoseconds, up to which the replica is known to have replayed the changes
+	// of the primary.
+	freshAsOf atomic.Int64
+	// unhealthy reports whether the last check of the replica failed, to only log when it becomes unhealthy.
+	unhealthy atomic.Bool
+}
+
+// NewReadReplicas returns the routing of the reads between the primary and the replica databases, whose statements
+// are built by newStatementBuilder. The replicas are checked a first time before it returns, then every check
+// interval until Close is called. Without replicas, all the reads go to the primary.
+func NewReadReplicas(
+	primary sq.StatementBuilderType,
+	replicas []*sql.DB,
+	newStatementBuilder func(*sql.DB) sq.StatementBuilderType,
+	lagFunc ReplicaLagFunc,
+	cfg *Config,
+) *ReadReplicas {
+	r := &ReadReplicas{
+		primary:  primary,
+		lagFunc:  lagFunc,
+		maxLag:   cfg.ReplicaMaxLag,
+		interval: cfg.ReplicaCheckInterval,
+		logger:   cfg.Logger,
+	}
+	if r.maxLag <= 0 {
+		r.maxLag = DefaultReplicaMaxLag
+	}
+	if r.interval <= 0 {
+		r.interval = DefaultReplicaCheckInterval
+	}
+	if r.logger == nil {
+		r.logger = logger.NewNoopLogger()
+	}
+
+	for i, db := range replicas {
+		r.replicas = append(r.replicas, &readReplica{
+			pool: "replica_" + strconv.Itoa(i),
+			db:   db,
+			stbl: newStatementBuilder(db),
+		})
+	}
+
+	if len(r.replicas) == 0 {
+		return r
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.check(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check(ctx)
+			}
+		}
+	}()
+
+	return r
+}
+
+// StatementBuilder returns the statement builder of the pool a tuple read with the given consistency should use.
+func (r *ReadReplicas) StatementBuilder(ctx context.Context, consistency storage.ConsistencyOptions) sq.StatementBuilderType {
+	if replica := r.route(ctx, consistency); replica != nil {
+		readRoutingCounter.WithLabelValues(replica.pool).Inc()
+		return replica.stbl
+	}
+
+	readRoutingCounter.WithLabelValues(primaryPool).Inc()
+	return r.primary
+}
+
+func (r *ReadReplicas) route(ctx context.Context, consistency storage.ConsistencyOptions) *readReplica {
+	if len(r.replicas) == 0 || consistency.Preference == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return nil
+	}
+
+	// the replicas can serve the reads bound to a revision once they have replayed the millisecond of the revision.
+	// An exact revision is rebuilt from the changes read from the primary, so its reads need the latest tuples.
+	var minFreshness time.Time
+	if revision, ok := storage.RevisionFromContext(ctx); ok {
+		if revision.Mode == storage.RevisionExact {
+			return nil
+		}
+		minFreshness = ulid.Time(revision.ULID.Time()).Add(time.Millisecond)
+	}
+
+	now := time.Now()
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		replica := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if replica.isHealthy(now, 2*r.interval) && !time.Unix(0, replica.freshAsOf.Load()).Before(minFreshness) {
+			return replica
+		}
+	}
+
+	return nil
+}
+
+func (rr *readReplica) isHealthy(now time.Time, maxCheckAge time.Duration) bool {
+	checkedAt := rr.checkedAt.Load()
+	return checkedAt != 0 && now.Sub(time.Unix(0, checkedAt)) <= maxCheckAge
+}
+
+// check measures the lag of every replica.
+func (r *ReadReplicas) check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.interval)
+		now := time.Now()
+		lag, err := r.lagFunc(checkCtx, replica.db)
+		cancel()
+
+		healthy := err == nil && lag <= r.maxLag
+		if wasUnhealthy := replica.unhealthy.Swap(!healthy); !healthy && !wasUnhealthy {
+			r.logger.Warn("read replica unhealthy, routing its reads to the primary",
+				zap.String("pool", replica.pool),
+				zap.Duration("lag", lag),
+				zap.Error(err),
+			)
+		}
+
+		if healthy {
+			replica.freshAsOf.Store(now.Add(-lag).UnixNano())
+			replica.checkedAt.Store(now.UnixNano())
+			replicaHealthyGauge.WithLabelValues(replica.pool).Set(1)
+		} else {
+			replica.checkedAt.Store(0)
+			replicaHealthyGauge.WithLabelValues(replica.pool).Set(0)
+		}
+		if err == nil {
+			replicaLagGauge.WithLabelValues(replica.pool).Set(lag.Seconds())
+		}
+	}
+}
+
+// Close stops checking the replicas and closes their databases.
+func (r *ReadReplicas) Close() {
+	if r.cancel != nil {
+		r.cancel()
+		r.wg.Wait()
+	}
+	for _, replica := range r.replicas {
+		replica.db.Close()
+	}
+}
+
+// OpenReadReplicas opens the databases of the replicas with the connection settings of the config. Unlike the
+// primary, the replicas are not waited for: an unreachable replica is only unhealthy.
+func OpenReadReplicas(driverName string, uris []string, cfg *Config) ([]*sql.DB, error) {
+	dbs := make([]*sql.DB, 0, len(uris))
+	for _, uri := range uris {
+		db, err := sql.Open(driverName, uri)
+		if err != nil {
+			for _, opened := range dbs {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("initialize read replica connection: %w", err)
+		}
+
+		if cfg.MaxOpenConns != 0 {
+			db.SetMaxOpenConns(cfg.MaxOpenConns)
+		}
+		if cfg.MaxIdleConns != 0 {
+			db.SetMaxIdleConns(cfg.MaxIdleConns)
+		}
+		if cfg.ConnMaxIdleTime != 0 {
+			db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+		}
+		if cfg.ConnMaxLifetime != 0 {
+			db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		}
+
+		dbs = append(dbs, db)
+	}
+
+	return dbs, nil
+}
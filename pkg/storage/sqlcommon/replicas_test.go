@@ -0,0 +1,128 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	_ "modernc.org/sqlite" // SQLite driver.
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// replicaLags is a ReplicaLagFunc returning the lag set for each replica.
+type replicaLags struct {
+	mu   sync.Mutex
+	lags map[*sql.DB]time.Duration
+	errs map[*sql.DB]error
+}
+
+func (l *replicaLags) lag(_ context.Context, db *sql.DB) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lags[db], l.errs[db]
+}
+
+func (l *replicaLags) set(db *sql.DB, lag time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lags[db] = lag
+	l.errs[db] = err
+}
+
+func TestReadReplicas(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	openDB := func() *sql.DB {
+		db, err := sql.Open("sqlite", ":memory:")
+		require.NoError(t, err)
+		return db
+	}
+	primary, first, second := openDB(), openDB(), openDB()
+	t.Cleanup(func() { primary.Close() })
+
+	lags := &replicaLags{lags: map[*sql.DB]time.Duration{}, errs: map[*sql.DB]error{}}
+	lags.set(second, 10*time.Second, nil)
+
+	replicas := NewReadReplicas(sq.StatementBuilder.RunWith(primary), []*sql.DB{first, second},
+		func(db *sql.DB) sq.StatementBuilderType { return sq.StatementBuilder.RunWith(db) },
+		lags.lag,
+		NewConfig(WithReplicaMaxLag(time.Second), WithReplicaCheckInterval(time.Hour)),
+	)
+	t.Cleanup(replicas.Close)
+
+	routedPool := func(ctx context.Context, preference openfgav1.ConsistencyPreference) string {
+		replica := replicas.route(ctx, storage.ConsistencyOptions{Preference: preference})
+		if replica == nil {
+			return primaryPool
+		}
+		return replica.pool
+	}
+
+	ctx := context.Background()
+
+	t.Run("lagging_replicas_are_skipped", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.Equal(t, "replica_0", routedPool(ctx, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+			require.Equal(t, "replica_0", routedPool(ctx, openfgav1.ConsistencyPreference_UNSPECIFIED))
+		}
+	})
+
+	t.Run("higher_consistency_reads_go_to_the_primary", func(t *testing.T) {
+		require.Equal(t, primaryPool, routedPool(ctx, openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+	})
+
+	t.Run("reads_are_spread_over_the_healthy_replicas", func(t *testing.T) {
+		lags.set(second, 0, nil)
+		replicas.check(ctx)
+
+		pools := map[string]bool{}
+		for i := 0; i < 4; i++ {
+			pools[routedPool(ctx, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY)] = true
+		}
+		require.Equal(t, map[string]bool{"replica_0": true, "replica_1": true}, pools)
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		lags.set(first, 0, nil)
+		lags.set(second, 0, nil)
+		replicas.check(ctx)
+
+		past := ulid.MustNew(ulid.Timestamp(time.Now().Add(-time.Minute)), nil)
+		atLeastAsFresh := storage.ContextWithRevision(ctx, storage.Revision{ULID: past, Mode: storage.RevisionAtLeastAsFresh})
+		require.NotEqual(t, primaryPool, routedPool(atLeastAsFresh, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+
+		exact := storage.ContextWithRevision(ctx, storage.Revision{ULID: past, Mode: storage.RevisionExact})
+		require.Equal(t, primaryPool, routedPool(exact, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+
+		// the replicas haven't replayed a revision taken after they were checked
+		future := ulid.MustNew(ulid.Timestamp(time.Now().Add(time.Second)), nil)
+		notReplayed := storage.ContextWithRevision(ctx, storage.Revision{ULID: future, Mode: storage.RevisionAtLeastAsFresh})
+		require.Equal(t, primaryPool, routedPool(notReplayed, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+	})
+
+	t.Run("unhealthy_replicas_fall_back_to_the_primary", func(t *testing.T) {
+		lags.set(first, 0, errors.New("connection refused"))
+		lags.set(second, 0, errors.New("connection refused"))
+		replicas.check(ctx)
+
+		require.Equal(t, primaryPool, routedPool(ctx, openfgav1.ConsistencyPreference_MINIMIZE_LATENCY))
+	})
+}
+
+func TestReadReplicasWithoutReplicas(t *testing.T) {
+	replicas := NewReadReplicas(sq.StatementBuilder, nil, nil, nil, NewConfig())
+	t.Cleanup(replicas.Close)
+
+	require.Nil(t, replicas.route(context.Background(), storage.ConsistencyOptions{}))
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -41,6 +42,16 @@ type Config struct {
 	ConnMaxLifetime time.Duration
 
 	ExportMetrics bool
+
+	// ReadReplicaURIs are the connection uris of the read replicas of the primary database, see [ReadReplicas].
+	ReadReplicaURIs []string
+	// ReplicaMaxLag is how far behind the primary a replica may be to serve reads.
+	ReplicaMaxLag time.Duration
+	// ReplicaCheckInterval is how often the lag of the replicas is measured.
+	ReplicaCheckInterval time.Duration
+
+	// Outbox enables recording the changes in the outbox, see [storage.EventOutbox].
+	Outbox bool
 }
 
 // DatastoreOption defines a function type
@@ -131,6 +142,38 @@ func WithMetrics() DatastoreOption {
 	}
 }
 
+// WithReadReplicaURIs returns a DatastoreOption that sets
+// the connection uris of the read replicas in the Config.
+func WithReadReplicaURIs(uris []string) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ReadReplicaURIs = uris
+	}
+}
+
+// WithReplicaMaxLag returns a DatastoreOption that sets how far
+// behind the primary a read replica may be in the Config.
+func WithReplicaMaxLag(d time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ReplicaMaxLag = d
+	}
+}
+
+// WithReplicaCheckInterval returns a DatastoreOption that sets
+// how often the read replicas are checked in the Config.
+func WithReplicaCheckInterval(d time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ReplicaCheckInterval = d
+	}
+}
+
+// WithOutbox returns a DatastoreOption that enables
+// recording the changes in the outbox in the Config.
+func WithOutbox() DatastoreOption {
+	return func(cfg *Config) {
+		cfg.Outbox = true
+	}
+}
+
 // NewConfig creates a new Config instance with default values
 // and applies any provided DatastoreOption modifications.
 func NewConfig(opts ...DatastoreOption) *Config {
@@ -423,6 +466,20 @@ type DBInfo struct {
 	db             *sql.DB
 	stbl           sq.StatementBuilderType
 	HandleSQLError errorHandlerFn
+	// Outbox enables recording the changes in the outbox, see [storage.EventOutbox].
+	Outbox bool
+	// InsertIgnore turns an insert of tuples into one skipping, instead of failing on, the tuples which already
+	// exist. It defaults to 'ON CONFLICT DO NOTHING'.
+	InsertIgnore func(sq.InsertBuilder) sq.InsertBuilder
+	// UpsertTupleCount turns the insert of a shard of the tuple count of a store into one adding to the shard if it
+	// already exists. It defaults to [UpsertTupleCount].
+	UpsertTupleCount func(sq.InsertBuilder) sq.InsertBuilder
+	// NotifyChangelog, if set, is called as part of every transaction inserting changes in the changelog of a
+	// store, so that the notification is sent if, and only if, the changes are committed.
+	NotifyChangelog func(ctx context.Context, txn *sql.Tx, store string) error
+	// InsertedAtPrecision is the precision the insertion times of the tuples and changes are stored with, which
+	// those imported are truncated to rather than rounded by the database. It defaults to a microsecond.
+	InsertedAtPrecision time.Duration
 }
 
 type errorHandlerFn func(error, ...interface{}) error
@@ -433,9 +490,34 @@ func NewDBInfo(db *sql.DB, stbl sq.StatementBuilderType, errorHandler errorHandl
 		db:             db,
 		stbl:           stbl,
 		HandleSQLError: errorHandler,
+		InsertIgnore: func(builder sq.InsertBuilder) sq.InsertBuilder {
+			return builder.Suffix("ON CONFLICT DO NOTHING")
+		},
+		UpsertTupleCount:    UpsertTupleCount,
+		InsertedAtPrecision: time.Microsecond,
 	}
 }
 
+// notifyChangelog notifies, as part of the transaction, that the changelogs of the stores changed, if enabled.
+func (dbInfo *DBInfo) notifyChangelog(ctx context.Context, txn *sql.Tx, stores ...string) error {
+	if dbInfo.NotifyChangelog == nil {
+		return nil
+	}
+
+	notified := make(map[string]struct{}, len(stores))
+	for _, store := range stores {
+		if _, ok := notified[store]; ok {
+			continue
+		}
+		notified[store] = struct{}{}
+
+		if err := dbInfo.NotifyChangelog(ctx, txn, store); err != nil {
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+	return nil
+}
+
 // Write provides the common method for writing to database across sql storage.
 func Write(
 	ctx context.Context,
@@ -443,9 +525,16 @@ func Write(
 	store string,
 	deletes storage.Deletes,
 	writes storage.Writes,
+	options storage.TupleWriteOptions,
 	now time.Time,
 ) error {
-	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	var txnOptions *sql.TxOptions
+	if len(options.Preconditions) > 0 {
+		// The tuples checked by the preconditions must not change until the write is committed.
+		txnOptions = &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+
+	txn, err := dbInfo.db.BeginTx(ctx, txnOptions)
 	if err != nil {
 		return dbInfo.HandleSQLError(err)
 	}
@@ -453,6 +542,17 @@ func Write(
 		_ = txn.Rollback()
 	}()
 
+	for _, precondition := range options.Preconditions {
+		record, err := readTupleForWrite(ctx, dbInfo, txn, store, precondition.TupleKey, now)
+		if err != nil {
+			return err
+		}
+
+		if (record != nil) != precondition.Exists {
+			return storage.PreconditionFailedError(precondition)
+		}
+	}
+
 	changelogBuilder := dbInfo.stbl.
 		Insert("changelog").
 		Columns(
@@ -460,6 +560,36 @@ func Write(
 			"condition_name", "condition_context", "operation", "ulid", "inserted_at",
 		)
 
+	// changes counts the deletes and writes which are not ignored, and tupleDelta how they change the tuple count.
+	changes := 0
+	var tupleDelta int64
+	// eventChanges are the deletes and writes which are not ignored, including the expired tuples deleted.
+	var eventChanges []storage.EventTupleChange
+	// revision is the ULID of the last delete or write recorded in the changelog, which orders after the others.
+	var revision string
+
+	// expired tuples are deleted first, so that they can be written again
+	if len(writes) > 0 {
+		keys := sq.Or{}
+		for _, tk := range writes {
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			keys = append(keys, sq.Eq{
+				"object_type": objectType,
+				"object_id":   objectID,
+				"relation":    tk.GetRelation(),
+				"_user":       tk.GetUser(),
+			})
+		}
+
+		deleted, expiredChanges, err := deleteExpiredTuples(ctx, dbInfo, txn, sq.And{sq.Eq{"store": store}, keys}, now, 0, &changelogBuilder)
+		if err != nil {
+			return err
+		}
+		changes += len(deleted)
+		tupleDelta -= int64(len(deleted))
+		eventChanges = append(eventChanges, expiredChanges...)
+	}
+
 	deleteBuilder := dbInfo.stbl.Delete("tuple")
 
 	for _, tk := range deletes {
@@ -475,6 +605,7 @@ func Write(
 				"_user":       tk.GetUser(),
 				"user_type":   tupleUtils.GetUserTypeFromUser(tk.GetUser()),
 			}).
+			Where(NotExpired(now)).
 			RunWith(txn). // Part of a txn.
 			ExecContext(ctx)
 		if err != nil {
@@ -486,6 +617,10 @@ func Write(
 			return dbInfo.HandleSQLError(err)
 		}
 
+		if rowsAffected == 0 && options.OnMissingDelete == storage.OnMissingDeleteIgnore {
+			continue
+		}
+
 		if rowsAffected != 1 {
 			return storage.InvalidWriteInputError(
 				tk,
@@ -493,6 +628,10 @@ func Write(
 			)
 		}
 
+		changes++
+		tupleDelta--
+		revision = id
+		eventChanges = append(eventChanges, storage.NewTupleDeleteChange(tk))
 		changelogBuilder = changelogBuilder.Values(
 			store, objectType, objectID,
 			tk.GetRelation(), tk.GetUser(),
@@ -506,9 +645,15 @@ func Write(
 		Insert("tuple").
 		Columns(
 			"store", "object_type", "object_id", "relation", "_user", "user_type",
-			"condition_name", "condition_context", "ulid", "inserted_at",
+			"condition_name", "condition_context", "ulid", "inserted_at", "expires_at",
 		)
 
+	var expiresAt *time.Time
+	if !options.ExpiresAt.IsZero() {
+		expiresAt = new(time.Time)
+		*expiresAt = options.ExpiresAt.UTC()
+	}
+
 	for _, tk := range writes {
 		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
@@ -518,7 +663,7 @@ func Write(
 			return err
 		}
 
-		_, err = insertBuilder.
+		builder := insertBuilder.
 			Values(
 				store,
 				objectType,
@@ -530,13 +675,43 @@ func Write(
 				conditionContext,
 				id,
 				sq.Expr("NOW()"),
-			).
+				expiresAt,
+			)
+		if options.OnDuplicateInsert == storage.OnDuplicateInsertIgnore {
+			// The tuple is inserted unless it exists, so that concurrent writes of the same tuple don't race
+			// between reading and inserting it.
+			builder = dbInfo.InsertIgnore(builder)
+		}
+
+		res, err := builder.
 			RunWith(txn). // Part of a txn.
 			ExecContext(ctx)
 		if err != nil {
 			return dbInfo.HandleSQLError(err, tk)
 		}
 
+		if options.OnDuplicateInsert == storage.OnDuplicateInsertIgnore {
+			inserted, err := res.RowsAffected()
+			if err != nil {
+				return dbInfo.HandleSQLError(err)
+			}
+
+			if inserted == 0 {
+				record, err := readTupleForWrite(ctx, dbInfo, txn, store, tk, now)
+				if err != nil {
+					return err
+				}
+				if record != nil && !record.HasCondition(tk.GetCondition()) {
+					return storage.DuplicateWithDifferentConditionError(tk)
+				}
+				continue
+			}
+		}
+
+		changes++
+		tupleDelta++
+		revision = id
+		eventChanges = append(eventChanges, storage.NewTupleWriteChange(tk))
 		changelogBuilder = changelogBuilder.Values(
 			store,
 			objectType,
@@ -551,20 +726,297 @@ func Write(
 		)
 	}
 
-	if len(writes) > 0 || len(deletes) > 0 {
+	if changes > 0 {
 		_, err := changelogBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
 		if err != nil {
 			return dbInfo.HandleSQLError(err)
 		}
+		if err := dbInfo.notifyChangelog(ctx, txn, store); err != nil {
+			return err
+		}
+	}
+
+	if err := AddTupleCount(ctx, dbInfo.stbl.RunWith(txn), dbInfo.UpsertTupleCount, store, tupleDelta); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	if err := dbInfo.recordEvent(ctx, txn, storage.NewTuplesWrittenEvent(store, eventChanges)); err != nil {
+		return err
 	}
 
 	if err := txn.Commit(); err != nil {
 		return dbInfo.HandleSQLError(err)
 	}
 
+	if revision != "" {
+		options.SetCommittedRevision(ulid.MustParse(revision))
+	}
 	return nil
 }
 
+// importTuplesPerStatement is the number of rows of the multi-row INSERT statements of ImportTuples, which
+// keeps the number of placeholders of a statement under the limits of the databases.
+const importTuplesPerStatement = 1000
+
+// ImportTuples provides the common method for importing tuples with multi-row INSERT statements across sql storage.
+func ImportTuples(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store string,
+	writes storage.Writes,
+	now time.Time,
+) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	for start := 0; start < len(writes); start += importTuplesPerStatement {
+		insertBuilder := dbInfo.stbl.
+			Insert("tuple").
+			Columns(
+				"store", "object_type", "object_id", "relation", "_user", "user_type",
+				"condition_name", "condition_context", "ulid", "inserted_at",
+			)
+		changelogBuilder := dbInfo.stbl.
+			Insert("changelog").
+			Columns(
+				"store", "object_type", "object_id", "relation", "_user",
+				"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+			)
+
+		for _, tk := range writes[start:min(start+importTuplesPerStatement, len(writes))] {
+			id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+
+			conditionName, conditionContext, err := MarshalRelationshipCondition(tk.GetCondition())
+			if err != nil {
+				return err
+			}
+
+			insertBuilder = insertBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(), tk.GetUser(), tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+				conditionName, conditionContext, id, sq.Expr("NOW()"),
+			)
+			changelogBuilder = changelogBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(), tk.GetUser(),
+				conditionName, conditionContext, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, id, sq.Expr("NOW()"),
+			)
+		}
+
+		if _, err := insertBuilder.RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn.
+			return ImportCollisionError(dbInfo.HandleSQLError(err))
+		}
+
+		if _, err := changelogBuilder.RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn.
+			return dbInfo.HandleSQLError(err)
+		}
+	}
+
+	if err := AddTupleCount(ctx, dbInfo.stbl.RunWith(txn), dbInfo.UpsertTupleCount, store, int64(len(writes))); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	if err := dbInfo.notifyChangelog(ctx, txn, store); err != nil {
+		return err
+	}
+
+	if err := dbInfo.recordEvent(ctx, txn, storage.NewTuplesWrittenEvent(store, storage.NewTupleWriteChanges(writes))); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ImportCollisionError turns the storage.ErrCollision of a batch of tuples to import, some of which already
+// exist, into the storage.ErrInvalidWriteInput that ImportTuples must return.
+func ImportCollisionError(err error) error {
+	if errors.Is(err, storage.ErrCollision) {
+		return fmt.Errorf("cannot import tuples which already exist: %w", storage.ErrInvalidWriteInput)
+	}
+	return err
+}
+
+// readTupleForWrite reads, as part of the write transaction, the latest committed version of the tuple with the
+// given key, returning nil if it doesn't exist or has expired. The tuple is locked until the write commits.
+func readTupleForWrite(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	txn *sql.Tx,
+	store string,
+	tk tupleUtils.TupleWithoutCondition,
+	now time.Time,
+) (*storage.TupleRecord, error) {
+	objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+
+	var conditionName sql.NullString
+	var conditionContext []byte
+	err := dbInfo.stbl.
+		Select("condition_name", "condition_context").
+		From("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    tk.GetRelation(),
+			"_user":       tk.GetUser(),
+			"user_type":   tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+		}).
+		Where(NotExpired(now)).
+		Suffix("FOR SHARE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&conditionName, &conditionContext)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	record := &storage.TupleRecord{ConditionName: conditionName.String}
+	if conditionContext != nil {
+		var conditionContextStruct structpb.Struct
+		if err := proto.Unmarshal(conditionContext, &conditionContextStruct); err != nil {
+			return nil, err
+		}
+		record.ConditionContext = &conditionContextStruct
+	}
+
+	return record, nil
+}
+
+// NotExpired filters out the tuples which have expired at the given time, which must be in UTC.
+func NotExpired(now time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{"expires_at": nil}, sq.Gt{"expires_at": now}}
+}
+
+// DeleteExpiredTuples provides the common method for deleting expired tuples across sql storage.
+// It returns the stores of the tuples deleted, once per tuple.
+func DeleteExpiredTuples(ctx context.Context, dbInfo *DBInfo, now time.Time, limit int) ([]string, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	changelogBuilder := dbInfo.stbl.
+		Insert("changelog").
+		Columns(
+			"store", "object_type", "object_id", "relation", "_user",
+			"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+		)
+
+	stores, eventChanges, err := deleteExpiredTuples(ctx, dbInfo, txn, nil, now, limit, &changelogBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stores) > 0 {
+		if _, err := changelogBuilder.RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn.
+			return nil, dbInfo.HandleSQLError(err)
+		}
+		if err := SubtractDeletedTuples(ctx, dbInfo.stbl.RunWith(txn), dbInfo.UpsertTupleCount, stores); err != nil {
+			return nil, dbInfo.HandleSQLError(err)
+		}
+		if err := dbInfo.notifyChangelog(ctx, txn, stores...); err != nil {
+			return nil, err
+		}
+		for _, event := range storage.NewTuplesExpiredEvents(stores, eventChanges) {
+			if err := dbInfo.recordEvent(ctx, txn, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return stores, nil
+}
+
+// deleteExpiredTuples deletes, as part of the transaction, the tuples matching the filter which expired at or
+// before now, up to limit if it is positive. Their deletes are added to the changelog insert, and returned along with
+// their stores, once per tuple, for the outbox.
+func deleteExpiredTuples(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	txn *sql.Tx,
+	filter sq.Sqlizer,
+	now time.Time,
+	limit int,
+	changelogBuilder *sq.InsertBuilder,
+) ([]string, []storage.EventTupleChange, error) {
+	sb := dbInfo.stbl.
+		Select("store", "object_type", "object_id", "relation", "_user", "ulid").
+		From("tuple").
+		Where(sq.LtOrEq{"expires_at": now}).
+		Suffix("FOR UPDATE")
+	if filter != nil {
+		sb = sb.Where(filter)
+	}
+	if limit > 0 {
+		sb = sb.OrderBy("expires_at").Limit(uint64(limit))
+	}
+
+	rows, err := sb.RunWith(txn).QueryContext(ctx) // Part of a txn.
+	if err != nil {
+		return nil, nil, dbInfo.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var stores, ulids []string
+	var changes []storage.EventTupleChange
+	for rows.Next() {
+		var store, objectType, objectID, relation, user, tupleUlid string
+		if err := rows.Scan(&store, &objectType, &objectID, &relation, &user, &tupleUlid); err != nil {
+			return nil, nil, dbInfo.HandleSQLError(err)
+		}
+
+		stores = append(stores, store)
+		ulids = append(ulids, tupleUlid)
+		changes = append(changes, storage.NewTupleDeleteChange(&openfgav1.TupleKeyWithoutCondition{
+			Object:   tupleUtils.BuildObject(objectType, objectID),
+			Relation: relation,
+			User:     user,
+		}))
+		*changelogBuilder = changelogBuilder.Values(
+			store, objectType, objectID, relation, user,
+			"", nil, // Redact condition info for deletes since we only need the base triplet (object, relation, user).
+			openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+			ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String(), sq.Expr("NOW()"),
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, dbInfo.HandleSQLError(err)
+	}
+
+	if len(ulids) == 0 {
+		return nil, nil, nil
+	}
+
+	_, err = dbInfo.stbl.
+		Delete("tuple").
+		Where(sq.Eq{"ulid": ulids}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return nil, nil, dbInfo.HandleSQLError(err)
+	}
+
+	return stores, changes, nil
+}
+
 // WriteAuthorizationModel writes an authorization model for the given store in one row.
 func WriteAuthorizationModel(
 	ctx context.Context,
@@ -584,15 +1036,32 @@ func WriteAuthorizationModel(
 		return err
 	}
 
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
 	_, err = dbInfo.stbl.
 		Insert("authorization_model").
 		Columns("store", "authorization_model_id", "schema_version", "type", "type_definition", "serialized_protobuf").
 		Values(store, model.GetId(), schemaVersion, "", nil, pbdata).
+		RunWith(txn). // Part of a txn.
 		ExecContext(ctx)
 	if err != nil {
 		return dbInfo.HandleSQLError(err)
 	}
 
+	if err := dbInfo.recordEvent(ctx, txn, storage.NewAuthorizationModelWrittenEvent(store, model.GetId())); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
 	return nil
 }
 
@@ -717,8 +1186,9 @@ func ReadAuthorizationModel(
 }
 
 // IsReady returns true if the connection to the datastore is successful
-// and the datastore has the latest migration applied.
-func IsReady(ctx context.Context, db *sql.DB) (storage.ReadinessStatus, error) {
+// and the datastore has the latest migration applied. The dialect is the one
+// of the migrations of the datastore.
+func IsReady(ctx context.Context, db *sql.DB, dialect goose.Dialect) (storage.ReadinessStatus, error) {
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
@@ -726,7 +1196,7 @@ func IsReady(ctx context.Context, db *sql.DB) (storage.ReadinessStatus, error) {
 		return storage.ReadinessStatus{}, err
 	}
 
-	revision, err := goose.GetDBVersion(db)
+	revision, err := schemaRevision(ctx, db, dialect)
 	if err != nil {
 		return storage.ReadinessStatus{}, err
 	}
@@ -743,6 +1213,42 @@ func IsReady(ctx context.Context, db *sql.DB) (storage.ReadinessStatus, error) {
 	}, nil
 }
 
+// migrationsTableQueries are the queries of the dialects counting the tables of the current schema with a given name.
+var migrationsTableQueries = map[goose.Dialect]string{
+	goose.DialectPostgres: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1",
+	goose.DialectMySQL:    "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?",
+	goose.DialectSQLite3:  "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?",
+}
+
+// schemaRevision returns the latest migration applied to the datastore, or 0 if
+// none was, without creating the table of the migrations as goose does.
+func schemaRevision(ctx context.Context, db *sql.DB, dialect goose.Dialect) (int64, error) {
+	query, ok := migrationsTableQueries[dialect]
+	if !ok {
+		return 0, fmt.Errorf("unsupported dialect '%s'", dialect)
+	}
+
+	// only a missing table means that no migration was applied, the other errors are returned
+	var tables int
+	if err := db.QueryRowContext(ctx, query, goose.DefaultTablename).Scan(&tables); err != nil {
+		return 0, err
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+
+	store, err := database.NewStore(dialect, goose.DefaultTablename)
+	if err != nil {
+		return 0, err
+	}
+
+	revision, err := store.GetLatestVersion(ctx, db)
+	if errors.Is(err, database.ErrVersionNotFound) {
+		return 0, nil
+	}
+	return revision, err
+}
+
 func AddFromUlid(sb sq.SelectBuilder, fromUlid string, sortDescending bool) sq.SelectBuilder {
 	if sortDescending {
 		return sb.Where(sq.Lt{"ulid": fromUlid})
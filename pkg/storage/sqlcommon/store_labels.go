@@ -0,0 +1,229 @@
+package sqlcommon
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// ListStoresFilter returns the conditions on the store table of the stores listed with the options: those not
+// deleted, with one of the IDs, the name prefix and labels matching the label selector, if set.
+func ListStoresFilter(options storage.ListStoresOptions) sq.And {
+	where := sq.And{sq.Eq{"deleted_at": nil}}
+	if len(options.IDs) > 0 {
+		where = append(where, sq.Eq{"id": options.IDs})
+	}
+	if options.NamePrefix != "" {
+		where = append(where, sq.Expr("substr(name, 1, ?) = ?", utf8.RuneCountInString(options.NamePrefix), options.NamePrefix))
+	}
+	for _, requirement := range options.LabelSelector {
+		where = append(where, labelRequirementFilter(requirement))
+	}
+	return where
+}
+
+// labelRequirementFilter returns the condition on the store table of the stores meeting the requirement, as a
+// subquery of their labels.
+func labelRequirementFilter(requirement storage.LabelRequirement) sq.Sqlizer {
+	labels := sq.Select("1").
+		From("store_label").
+		Where("store_label.store = store.id").
+		Where(sq.Eq{"store_label.label_key": requirement.Key})
+	if requirement.Operator == storage.LabelEquals || requirement.Operator == storage.LabelNotEquals {
+		labels = labels.Where(sq.Eq{"store_label.label_value": requirement.Value})
+	}
+
+	query, args, err := labels.ToSql()
+	if err != nil {
+		// the query is built from constants
+		panic(err)
+	}
+
+	if requirement.Operator == storage.LabelNotEquals || requirement.Operator == storage.LabelNotExists {
+		return sq.Expr("NOT EXISTS ("+query+")", args...)
+	}
+	return sq.Expr("EXISTS ("+query+")", args...)
+}
+
+// WriteStoreLabels replaces the labels of the store. The statement builder must run with the transaction writing
+// the store.
+func WriteStoreLabels(ctx context.Context, stbl sq.StatementBuilderType, store string, labels map[string]string) error {
+	_, err := stbl.
+		Delete("store_label").
+		Where(sq.Eq{"store": store}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	insert := stbl.
+		Insert("store_label").
+		Columns("store", "label_key", "label_value")
+	for key, value := range labels {
+		insert = insert.Values(store, key, value)
+	}
+	_, err = insert.ExecContext(ctx)
+	return err
+}
+
+// ReadStoreLabels provides the common method for reading the labels of a store across sql storage.
+func ReadStoreLabels(ctx context.Context, dbInfo *DBInfo, id string) (map[string]string, error) {
+	var storeID string
+	err := dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		QueryRowContext(ctx).
+		Scan(&storeID)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	rows, err := dbInfo.stbl.
+		Select("label_key", "label_value").
+		From("store_label").
+		Where(sq.Eq{"store": id}).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	labels := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, dbInfo.HandleSQLError(err)
+		}
+		labels[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return labels, nil
+}
+
+// CreateStoreWithLabels provides the common method for creating a store along with its labels across sql storage.
+func CreateStoreWithLabels(ctx context.Context, dbInfo *DBInfo, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	_, err = dbInfo.stbl.
+		Insert("store").
+		Columns("id", "name", "created_at", "updated_at").
+		Values(store.GetId(), store.GetName(), sq.Expr("NOW()"), sq.Expr("NOW()")).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	if err := WriteStoreLabels(ctx, dbInfo.stbl.RunWith(txn), store.GetId(), labels); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	created, err := selectStore(ctx, dbInfo.stbl.RunWith(txn), store.GetId())
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	if err := dbInfo.recordEvent(ctx, txn, storage.NewStoreCreatedEvent(created)); err != nil {
+		return nil, err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return created, nil
+}
+
+// UpdateStore provides the common method for renaming a store and replacing its labels across sql storage.
+func UpdateStore(ctx context.Context, dbInfo *DBInfo, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var storeID string
+	err = dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&storeID)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	update := dbInfo.stbl.
+		Update("store").
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id})
+	if name != "" {
+		update = update.Set("name", name)
+	}
+	if _, err := update.RunWith(txn).ExecContext(ctx); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	if labels != nil {
+		if err := WriteStoreLabels(ctx, dbInfo.stbl.RunWith(txn), id, labels); err != nil {
+			return nil, dbInfo.HandleSQLError(err)
+		}
+	}
+
+	updated, err := selectStore(ctx, dbInfo.stbl.RunWith(txn), id)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return updated, nil
+}
+
+// selectStore reads the store, which must not be deleted. It returns sql.ErrNoRows if it is.
+func selectStore(ctx context.Context, stbl sq.StatementBuilderType, id string) (*openfgav1.Store, error) {
+	var name string
+	var createdAt, updatedAt time.Time
+	err := stbl.
+		Select("name", "created_at", "updated_at").
+		From("store").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		QueryRowContext(ctx).
+		Scan(&name, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openfgav1.Store{
+		Id:        id,
+		Name:      name,
+		CreatedAt: timestamppb.New(createdAt),
+		UpdatedAt: timestamppb.New(updatedAt),
+	}, nil
+}
@@ -0,0 +1,244 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// StorePurgeTables are the tables holding the data of a store, in the order they are purged, along with the column
+// identifying the rows of a store deleted in a batch.
+var StorePurgeTables = []struct {
+	Table string
+	Key   string
+}{
+	{Table: "tuple", Key: "ulid"},
+	{Table: "changelog", Key: "ulid"},
+	{Table: "authorization_model", Key: "authorization_model_id"},
+	{Table: "assertion", Key: "authorization_model_id"},
+	{Table: "store_label", Key: "label_key"},
+	{Table: "store_tuple_count", Key: "store"},
+}
+
+// DeleteStore provides the common method for deleting a store across sql storage.
+func DeleteStore(ctx context.Context, dbInfo *DBInfo, id string) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	res, err := dbInfo.stbl.
+		Update("store").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+	if deleted > 0 {
+		if err := dbInfo.recordEvent(ctx, txn, storage.NewStoreDeletedEvent(id)); err != nil {
+			return err
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return dbInfo.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// UndeleteStore provides the common method for restoring a deleted store across sql storage.
+func UndeleteStore(ctx context.Context, dbInfo *DBInfo, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	where := sq.And{sq.Eq{"id": id}, sq.NotEq{"deleted_at": nil}}
+	if !deletedAfter.IsZero() {
+		where = append(where, sq.GtOrEq{"deleted_at": deletedAfter})
+	}
+
+	_, err := dbInfo.stbl.
+		Update("store").
+		Set("deleted_at", nil).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(where).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	var name string
+	var createdAt, updatedAt time.Time
+	err = dbInfo.stbl.
+		Select("name", "created_at", "updated_at").
+		From("store").
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		QueryRowContext(ctx).
+		Scan(&name, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return &openfgav1.Store{
+		Id:        id,
+		Name:      name,
+		CreatedAt: timestamppb.New(createdAt),
+		UpdatedAt: timestamppb.New(updatedAt),
+	}, nil
+}
+
+// ListDeletedStores provides the common method for listing the stores to purge across sql storage.
+func ListDeletedStores(ctx context.Context, dbInfo *DBInfo, deletedBefore time.Time, limit int) ([]string, error) {
+	rows, err := dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Lt{"deleted_at": deletedBefore}).
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, dbInfo.HandleSQLError(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbInfo.HandleSQLError(err)
+	}
+
+	return ids, nil
+}
+
+// PurgeStore provides the common method for purging a deleted store across sql storage. The row of the store is
+// locked for the duration of the purge, so that it can't be restored meanwhile.
+func PurgeStore(ctx context.Context, dbInfo *DBInfo, id string, deletedBefore time.Time, limit int) (int, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var storeID string
+	err = dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Eq{"id": id}).
+		Where(sq.Lt{"deleted_at": deletedBefore}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&storeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+
+	deleted := 0
+	for _, table := range StorePurgeTables {
+		if deleted >= limit {
+			break
+		}
+
+		n, err := PurgeStoreRows(ctx, dbInfo.stbl.RunWith(txn), table.Table, table.Key, id, limit-deleted)
+		if err != nil {
+			return 0, dbInfo.HandleSQLError(err)
+		}
+		deleted += n
+	}
+
+	if deleted < limit {
+		// no rows are left: the store itself is deleted last
+		_, err := dbInfo.stbl.
+			Delete("changelog_horizon").
+			Where(sq.Eq{"store": id}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return 0, dbInfo.HandleSQLError(err)
+		}
+
+		_, err = dbInfo.stbl.
+			Delete("store").
+			Where(sq.Eq{"id": id}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return 0, dbInfo.HandleSQLError(err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return 0, dbInfo.HandleSQLError(err)
+	}
+
+	return deleted, nil
+}
+
+// PurgeStoreRows deletes the rows of the store from the table, those of up to limit distinct values of the key
+// column, and returns how many were deleted. The statement builder must run with the transaction of the purge.
+func PurgeStoreRows(ctx context.Context, stbl sq.StatementBuilderType, table, key, store string, limit int) (int, error) {
+	rows, err := stbl.
+		Select(key).
+		Distinct().
+		From(table).
+		Where(sq.Eq{"store": store}).
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return 0, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	_ = rows.Close()
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	res, err := stbl.
+		Delete(table).
+		Where(sq.Eq{"store": store, key: keys}).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := res.RowsAffected()
+	return int(deleted), err
+}
@@ -0,0 +1,73 @@
+package sqlcommon
+
+import (
+	"context"
+	"math/rand/v2"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// TupleCountShards is the number of rows the tuple count of a store is spread over. A write adds to one of them at
+// random, so that the concurrent writes of a store seldom wait on one another to commit.
+const TupleCountShards = 16
+
+// UpsertTupleCount turns the insert of a shard of a tuple count into one adding to the shard if it already exists,
+// with 'ON CONFLICT DO UPDATE'. MySQL needs its own, see [DBInfo].
+func UpsertTupleCount(builder sq.InsertBuilder) sq.InsertBuilder {
+	return builder.Suffix("ON CONFLICT (store, shard) DO UPDATE SET tuple_count = store_tuple_count.tuple_count + excluded.tuple_count")
+}
+
+// AddTupleCount adds delta to a shard of the tuple count of the store, which is created if it doesn't exist yet.
+// The statement builder must run with the transaction writing or deleting the tuples, and upsert must turn the insert
+// of the shard into an upsert, see [UpsertTupleCount].
+func AddTupleCount(
+	ctx context.Context,
+	stbl sq.StatementBuilderType,
+	upsert func(sq.InsertBuilder) sq.InsertBuilder,
+	store string,
+	delta int64,
+) error {
+	if delta == 0 {
+		return nil
+	}
+
+	_, err := upsert(stbl.
+		Insert("store_tuple_count").
+		Columns("store", "shard", "tuple_count").
+		Values(store, rand.IntN(TupleCountShards), delta)).
+		ExecContext(ctx)
+	return err
+}
+
+// CountTuples provides the common method for reading the tuple count of a store across sql storage, summing its
+// shards. The statement builder must run with the database.
+func CountTuples(ctx context.Context, stbl sq.StatementBuilderType, store string) (int64, error) {
+	var count int64
+	err := stbl.
+		Select("COALESCE(SUM(tuple_count), 0)").
+		From("store_tuple_count").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(&count)
+	return count, err
+}
+
+// SubtractDeletedTuples subtracts the tuples deleted from the tuple counts of their stores, given once per tuple.
+// The statement builder must run with the transaction deleting them, see [AddTupleCount].
+func SubtractDeletedTuples(
+	ctx context.Context,
+	stbl sq.StatementBuilderType,
+	upsert func(sq.InsertBuilder) sq.InsertBuilder,
+	stores []string,
+) error {
+	deltas := make(map[string]int64)
+	for _, store := range stores {
+		deltas[store]--
+	}
+	for store, delta := range deltas {
+		if err := AddTupleCount(ctx, stbl, upsert, store, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package sqlcommon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+// TupleLookupsFilter returns the condition on the tuple table of the tuples matched by any of the lookups, as one row
+// value comparison with IN for the lookups with a user, and another one for those without. userColumns are the
+// columns holding the user of the tuples, and userValues splits a user into their values.
+func TupleLookupsFilter(lookups []storage.TupleLookup, userColumns []string, userValues func(user string) []interface{}) sq.Or {
+	var userRows, usersetRows int
+	var userArgs, usersetArgs []interface{}
+	for _, lookup := range lookups {
+		objectType, objectID := tupleUtils.SplitObject(lookup.Object)
+		if lookup.User == "" {
+			usersetRows++
+			usersetArgs = append(usersetArgs, objectType, objectID, lookup.Relation)
+			continue
+		}
+
+		userRows++
+		userArgs = append(userArgs, objectType, objectID, lookup.Relation)
+		userArgs = append(userArgs, userValues(lookup.User)...)
+	}
+
+	var where sq.Or
+	if userRows > 0 {
+		columns := append([]string{"object_type", "object_id", "relation"}, userColumns...)
+		where = append(where, sq.Expr(rowsIn(columns, userRows), userArgs...))
+	}
+	if usersetRows > 0 {
+		where = append(where, sq.And{
+			sq.Eq{"user_type": tupleUtils.UserSet},
+			sq.Expr(rowsIn([]string{"object_type", "object_id", "relation"}, usersetRows), usersetArgs...),
+		})
+	}
+	return where
+}
+
+// rowsIn returns the comparison of the columns, as a row value, with rows of placeholders: '(a, b) IN ((?, ?), (?, ?))'.
+func rowsIn(columns []string, rows int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	return "(" + strings.Join(columns, ", ") + ") IN (" + strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ") + ")"
+}
+
+// ReadTuplesBatch provides the common method for reading the tuples matched by any of the lookups, in a single query,
+// across sql storage whose tuple table holds the user in the '_user' column. The statement builder picks the
+// database the query runs on.
+func ReadTuplesBatch(ctx context.Context, stbl sq.StatementBuilderType, store string, lookups []storage.TupleLookup) ([]*openfgav1.Tuple, error) {
+	rows, err := stbl.
+		Select(
+			"store", "object_type", "object_id", "relation",
+			"_user",
+			"condition_name", "condition_context", "ulid", "inserted_at",
+		).
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		Where(TupleLookupsFilter(lookups, []string{"_user"}, func(user string) []interface{} {
+			return []interface{}{user}
+		})).
+		Where(NotExpired(time.Now().UTC())).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := NewSQLTupleIterator(rows)
+	defer iter.Stop()
+
+	var tuples []*openfgav1.Tuple
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return tuples, nil
+			}
+			return nil, err
+		}
+		tuples = append(tuples, t)
+	}
+}
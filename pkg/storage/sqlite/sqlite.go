@@ -14,6 +14,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/pressly/goose/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.opentelemetry.io/otel"
@@ -48,11 +49,39 @@ type Datastore struct {
 	dbStatsCollector       prometheus.Collector
 	maxTuplesPerWriteField int
 	maxTypesPerModelField  int
+	outbox                 bool
 }
 
 // Ensures that SQLite implements the OpenFGADatastore interface.
 var _ storage.OpenFGADatastore = (*Datastore)(nil)
 
+// Ensures that SQLite implements the TupleImporter interface.
+var _ storage.TupleImporter = (*Datastore)(nil)
+
+// Ensures that SQLite implements the TupleReaper interface.
+var _ storage.TupleReaper = (*Datastore)(nil)
+
+// Ensures that SQLite implements the ChangelogPruner interface.
+var _ storage.ChangelogPruner = (*Datastore)(nil)
+
+// Ensures that SQLite implements the StoreUndeleter interface.
+var _ storage.StoreUndeleter = (*Datastore)(nil)
+
+// Ensures that SQLite implements the StorePurger interface.
+var _ storage.StorePurger = (*Datastore)(nil)
+
+// Ensures that SQLite implements the StoreLabeler interface.
+var _ storage.StoreLabeler = (*Datastore)(nil)
+
+// Ensures that SQLite implements the TupleCounter interface.
+var _ storage.TupleCounter = (*Datastore)(nil)
+
+// Ensures that SQLite implements the EventOutbox interface.
+var _ storage.EventOutbox = (*Datastore)(nil)
+
+// Ensures that [Datastore] implements the [storage.RecordCopier] interface.
+var _ storage.RecordCopier = (*Datastore)(nil)
+
 // Prepare a raw DSN from config for use with SQLite, specifying defaults for journal mode and busy timeout.
 func PrepareDSN(uri string) (string, error) {
 	// Set journal mode and busy timeout pragmas if not specified.
@@ -129,6 +158,7 @@ func New(uri string, cfg *sqlcommon.Config) (*Datastore, error) {
 		dbStatsCollector:       collector,
 		maxTuplesPerWriteField: cfg.MaxTuplesPerWriteField,
 		maxTypesPerModelField:  cfg.MaxTypesPerModelField,
+		outbox:                 cfg.Outbox,
 	}, nil
 }
 
@@ -183,7 +213,8 @@ func (s *Datastore) read(ctx context.Context, store string, tupleKey *openfgav1.
 			"condition_name", "condition_context", "ulid", "inserted_at",
 		).
 		From("tuple").
-		Where(sq.Eq{"store": store})
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 	if options != nil {
 		sb = sb.OrderBy("ulid")
 	}
@@ -231,6 +262,7 @@ func (s *Datastore) Write(
 	store string,
 	deletes storage.Deletes,
 	writes storage.Writes,
+	opts ...storage.TupleWriteOption,
 ) error {
 	ctx, span := startTrace(ctx, "Write")
 	defer span.End()
@@ -239,7 +271,7 @@ func (s *Datastore) Write(
 		return storage.ErrExceededWriteBatchLimit
 	}
 
-	return s.write(ctx, store, deletes, writes, time.Now().UTC())
+	return s.write(ctx, store, deletes, writes, storage.NewTupleWriteOptions(opts...), time.Now().UTC())
 }
 
 // Write provides the common method for writing to database across sql storage.
@@ -248,8 +280,11 @@ func (s *Datastore) write(
 	store string,
 	deletes storage.Deletes,
 	writes storage.Writes,
+	options storage.TupleWriteOptions,
 	now time.Time,
 ) error {
+	// The transactions are immediate (see PrepareDSN), so that the tuples checked by the preconditions
+	// cannot change until the write is committed.
 	var txn *sql.Tx
 	err := busyRetry(func() error {
 		var err error
@@ -263,6 +298,17 @@ func (s *Datastore) write(
 		_ = txn.Rollback()
 	}()
 
+	for _, precondition := range options.Preconditions {
+		record, err := s.readTupleForWrite(ctx, txn, store, precondition.TupleKey, now)
+		if err != nil {
+			return err
+		}
+
+		if (record != nil) != precondition.Exists {
+			return storage.PreconditionFailedError(precondition)
+		}
+	}
+
 	changelogBuilder := s.stbl.
 		Insert("changelog").
 		Columns(
@@ -280,6 +326,39 @@ func (s *Datastore) write(
 			"inserted_at",
 		)
 
+	// changes counts the deletes and writes which are not ignored, and tupleDelta how they change the tuple count.
+	changes := 0
+	var tupleDelta int64
+	// eventChanges are the deletes and writes which are not ignored, including the expired tuples deleted.
+	var eventChanges []storage.EventTupleChange
+	// revision is the ULID of the last delete or write recorded in the changelog, which orders after the others.
+	var revision string
+
+	// expired tuples are deleted first, so that they can be written again
+	if len(writes) > 0 {
+		keys := sq.Or{}
+		for _, tk := range writes {
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+			keys = append(keys, sq.Eq{
+				"object_type":      objectType,
+				"object_id":        objectID,
+				"relation":         tk.GetRelation(),
+				"user_object_type": userObjectType,
+				"user_object_id":   userObjectID,
+				"user_relation":    userRelation,
+			})
+		}
+
+		deleted, expiredChanges, err := s.deleteExpiredTuples(ctx, txn, sq.And{sq.Eq{"store": store}, keys}, now, 0, &changelogBuilder)
+		if err != nil {
+			return err
+		}
+		changes += len(deleted)
+		tupleDelta -= int64(len(deleted))
+		eventChanges = append(eventChanges, expiredChanges...)
+	}
+
 	deleteBuilder := s.stbl.Delete("tuple")
 
 	for _, tk := range deletes {
@@ -301,6 +380,7 @@ func (s *Datastore) write(
 					"user_relation":    userRelation,
 					"user_type":        tupleUtils.GetUserTypeFromUser(tk.GetUser()),
 				}).
+				Where(sqlcommon.NotExpired(now)).
 				RunWith(txn). // Part of a txn.
 				ExecContext(ctx)
 			return err
@@ -314,6 +394,10 @@ func (s *Datastore) write(
 			return HandleSQLError(err)
 		}
 
+		if rowsAffected == 0 && options.OnMissingDelete == storage.OnMissingDeleteIgnore {
+			continue
+		}
+
 		if rowsAffected != 1 {
 			return storage.InvalidWriteInputError(
 				tk,
@@ -321,6 +405,10 @@ func (s *Datastore) write(
 			)
 		}
 
+		changes++
+		tupleDelta--
+		revision = id
+		eventChanges = append(eventChanges, storage.NewTupleDeleteChange(tk))
 		changelogBuilder = changelogBuilder.Values(
 			store,
 			objectType,
@@ -352,13 +440,34 @@ func (s *Datastore) write(
 			"condition_context",
 			"ulid",
 			"inserted_at",
+			"expires_at",
 		)
 
+	var expiresAt *time.Time
+	if !options.ExpiresAt.IsZero() {
+		expiresAt = new(time.Time)
+		*expiresAt = options.ExpiresAt.UTC()
+	}
+
 	for _, tk := range writes {
 		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
 		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
 
+		if options.OnDuplicateInsert == storage.OnDuplicateInsertIgnore {
+			record, err := s.readTupleForWrite(ctx, txn, store, tk, now)
+			if err != nil {
+				return err
+			}
+
+			if record != nil {
+				if !record.HasCondition(tk.GetCondition()) {
+					return storage.DuplicateWithDifferentConditionError(tk)
+				}
+				continue
+			}
+		}
+
 		conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(tk.GetCondition())
 		if err != nil {
 			return err
@@ -379,6 +488,7 @@ func (s *Datastore) write(
 					conditionContext,
 					id,
 					sq.Expr("datetime('subsec')"),
+					expiresAt,
 				).
 				RunWith(txn). // Part of a txn.
 				ExecContext(ctx)
@@ -388,6 +498,10 @@ func (s *Datastore) write(
 			return HandleSQLError(err, tk)
 		}
 
+		changes++
+		tupleDelta++
+		revision = id
+		eventChanges = append(eventChanges, storage.NewTupleWriteChange(tk))
 		changelogBuilder = changelogBuilder.Values(
 			store,
 			objectType,
@@ -404,7 +518,7 @@ func (s *Datastore) write(
 		)
 	}
 
-	if len(writes) > 0 || len(deletes) > 0 {
+	if changes > 0 {
 		err := busyRetry(func() error {
 			_, err := changelogBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
 			return err
@@ -414,6 +528,20 @@ func (s *Datastore) write(
 		}
 	}
 
+	err = busyRetry(func() error {
+		return sqlcommon.AddTupleCount(ctx, s.stbl.RunWith(txn), sqlcommon.UpsertTupleCount, store, tupleDelta)
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	err = busyRetry(func() error {
+		return s.insertOutboxEvent(ctx, txn, storage.NewTuplesWrittenEvent(store, eventChanges))
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
 	err = busyRetry(func() error {
 		return txn.Commit()
 	})
@@ -421,6 +549,9 @@ func (s *Datastore) write(
 		return HandleSQLError(err)
 	}
 
+	if revision != "" {
+		options.SetCommittedRevision(ulid.MustParse(revision))
+	}
 	return nil
 }
 
@@ -454,6 +585,7 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 			"user_relation":    userRelation,
 			"user_type":        userType,
 		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
 		QueryRowContext(ctx).
 		Scan(
 			&record.ObjectType,
@@ -484,6 +616,52 @@ func (s *Datastore) ReadUserTuple(ctx context.Context, store string, tupleKey *o
 	return record.AsTuple(), nil
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *Datastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, _ storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	ctx, span := startTrace(ctx, "ReadTuplesBatch")
+	defer span.End()
+
+	if len(lookups) == 0 {
+		return nil, nil
+	}
+
+	rows, err := s.stbl.
+		Select(
+			"store", "object_type", "object_id", "relation",
+			"user_object_type", "user_object_id", "user_relation",
+			"condition_name", "condition_context", "ulid", "inserted_at",
+		).
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.TupleLookupsFilter(lookups,
+			[]string{"user_object_type", "user_object_id", "user_relation"},
+			func(user string) []interface{} {
+				userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(user)
+				return []interface{}{userObjectType, userObjectID, userRelation}
+			},
+		)).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	iter := NewSQLTupleIterator(rows)
+	defer iter.Stop()
+
+	var tuples []*openfgav1.Tuple
+	for {
+		t, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return tuples, nil
+			}
+			return nil, HandleSQLError(err)
+		}
+		tuples = append(tuples, t)
+	}
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
 func (s *Datastore) ReadUsersetTuples(
 	ctx context.Context,
@@ -502,7 +680,8 @@ func (s *Datastore) ReadUsersetTuples(
 		).
 		From("tuple").
 		Where(sq.Eq{"store": store}).
-		Where(sq.Eq{"user_type": tupleUtils.UserSet})
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	objectType, objectID := tupleUtils.SplitObject(filter.Object)
 	if objectType != "" {
@@ -575,7 +754,8 @@ func (s *Datastore) ReadStartingWithUser(
 			"object_type": filter.ObjectType,
 			"relation":    filter.Relation,
 		}).
-		Where(targetUsersArg)
+		Where(targetUsersArg).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	if filter.ObjectIDs != nil && filter.ObjectIDs.Size() > 0 {
 		builder = builder.Where(sq.Eq{"object_id": filter.ObjectIDs.Values()})
@@ -735,34 +915,105 @@ func (s *Datastore) MaxTypesPerAuthorizationModel() int {
 	return s.maxTypesPerModelField
 }
 
-// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
-func (s *Datastore) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
-	ctx, span := startTrace(ctx, "WriteAuthorizationModel")
+// importTuplesPerStatement is the number of rows of the multi-row INSERT statements of ImportTuples, which
+// keeps the number of placeholders of a statement under the SQLite limit.
+const importTuplesPerStatement = 500
+
+// ImportTuples see [storage.TupleImporter].ImportTuples.
+func (s *Datastore) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	ctx, span := startTrace(ctx, "ImportTuples")
 	defer span.End()
 
-	schemaVersion := model.GetSchemaVersion()
-	typeDefinitions := model.GetTypeDefinitions()
+	if len(writes) > s.MaxTuplesPerImport() {
+		return storage.ErrExceededWriteBatchLimit
+	}
 
-	if len(typeDefinitions) < 1 {
-		return nil
+	now := time.Now().UTC()
+
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
 	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
 
-	if len(typeDefinitions) > s.MaxTypesPerAuthorizationModel() {
-		return storage.ExceededMaxTypeDefinitionsLimitError(s.maxTypesPerModelField)
+	for start := 0; start < len(writes); start += importTuplesPerStatement {
+		insertBuilder := s.stbl.
+			Insert("tuple").
+			Columns(
+				"store", "object_type", "object_id", "relation",
+				"user_object_type", "user_object_id", "user_relation", "user_type",
+				"condition_name", "condition_context", "ulid", "inserted_at",
+			)
+		changelogBuilder := s.stbl.
+			Insert("changelog").
+			Columns(
+				"store", "object_type", "object_id", "relation",
+				"user_object_type", "user_object_id", "user_relation",
+				"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+			)
+
+		for _, tk := range writes[start:min(start+importTuplesPerStatement, len(writes))] {
+			id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+
+			conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(tk.GetCondition())
+			if err != nil {
+				return err
+			}
+
+			insertBuilder = insertBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(),
+				userObjectType, userObjectID, userRelation, tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+				conditionName, conditionContext, id, sq.Expr("datetime('subsec')"),
+			)
+			changelogBuilder = changelogBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(),
+				userObjectType, userObjectID, userRelation,
+				conditionName, conditionContext, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, id, sq.Expr("datetime('subsec')"),
+			)
+		}
+
+		err := busyRetry(func() error {
+			_, err := insertBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
+			return err
+		})
+		if err != nil {
+			return sqlcommon.ImportCollisionError(HandleSQLError(err))
+		}
+
+		err = busyRetry(func() error {
+			_, err := changelogBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
+			return err
+		})
+		if err != nil {
+			return HandleSQLError(err)
+		}
 	}
 
-	pbdata, err := proto.Marshal(model)
+	err = busyRetry(func() error {
+		return sqlcommon.AddTupleCount(ctx, s.stbl.RunWith(txn), sqlcommon.UpsertTupleCount, store, int64(len(writes)))
+	})
 	if err != nil {
-		return err
+		return HandleSQLError(err)
 	}
 
 	err = busyRetry(func() error {
-		_, err := s.stbl.
-			Insert("authorization_model").
-			Columns("store", "authorization_model_id", "schema_version", "serialized_protobuf").
-			Values(store, model.GetId(), schemaVersion, pbdata).
-			ExecContext(ctx)
-		return err
+		return s.insertOutboxEvent(ctx, txn, storage.NewTuplesWrittenEvent(store, storage.NewTupleWriteChanges(writes)))
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
 	})
 	if err != nil {
 		return HandleSQLError(err)
@@ -771,155 +1022,1252 @@ func (s *Datastore) WriteAuthorizationModel(ctx context.Context, store string, m
 	return nil
 }
 
-// CreateStore adds a new store to storage.
-func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
-	ctx, span := startTrace(ctx, "CreateStore")
-	defer span.End()
+// MaxTuplesPerImport see [storage.TupleImporter].MaxTuplesPerImport.
+func (s *Datastore) MaxTuplesPerImport() int {
+	return storage.DefaultMaxTuplesPerImport
+}
 
-	var id, name string
-	var createdAt, updatedAt time.Time
+// DeleteExpiredTuples see [storage.TupleReaper].DeleteExpiredTuples.
+func (s *Datastore) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "DeleteExpiredTuples")
+	defer span.End()
 
+	var txn *sql.Tx
 	err := busyRetry(func() error {
-		return s.stbl.
-			Insert("store").
-			Columns("id", "name", "created_at", "updated_at").
-			Values(store.GetId(), store.GetName(), sq.Expr("datetime('subsec')"), sq.Expr("datetime('subsec')")).
-			Suffix("returning id, name, created_at, updated_at").
-			QueryRowContext(ctx).
-			Scan(&id, &name, &createdAt, &updatedAt)
+		var err error
+		txn, err = s.db.BeginTx(ctx, nil)
+		return err
 	})
 	if err != nil {
-		return nil, HandleSQLError(err)
+		return 0, HandleSQLError(err)
 	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
 
-	return &openfgav1.Store{
-		Id:        id,
-		Name:      name,
-		CreatedAt: timestamppb.New(createdAt),
-		UpdatedAt: timestamppb.New(updatedAt),
-	}, nil
-}
+	changelogBuilder := s.stbl.
+		Insert("changelog").
+		Columns(
+			"store", "object_type", "object_id", "relation",
+			"user_object_type", "user_object_id", "user_relation",
+			"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+		)
 
-// GetStore retrieves the details of a specific store using its storeID.
-func (s *Datastore) GetStore(ctx context.Context, id string) (*openfgav1.Store, error) {
-	ctx, span := startTrace(ctx, "GetStore")
-	defer span.End()
+	stores, eventChanges, err := s.deleteExpiredTuples(ctx, txn, nil, now.UTC(), limit, &changelogBuilder)
+	if err != nil {
+		return 0, err
+	}
 
-	row := s.stbl.
-		Select("id", "name", "created_at", "updated_at").
-		From("store").
-		Where(sq.Eq{
-			"id":         id,
-			"deleted_at": nil,
-		}).
-		QueryRowContext(ctx)
+	if len(stores) > 0 {
+		err := busyRetry(func() error {
+			_, err := changelogBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
+			return err
+		})
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
 
-	var storeID, name string
-	var createdAt, updatedAt time.Time
-	err := row.Scan(&storeID, &name, &createdAt, &updatedAt)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, storage.ErrNotFound
+		err = busyRetry(func() error {
+			return sqlcommon.SubtractDeletedTuples(ctx, s.stbl.RunWith(txn), sqlcommon.UpsertTupleCount, stores)
+		})
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+
+		for _, event := range storage.NewTuplesExpiredEvents(stores, eventChanges) {
+			err = busyRetry(func() error {
+				return s.insertOutboxEvent(ctx, txn, event)
+			})
+			if err != nil {
+				return 0, HandleSQLError(err)
+			}
 		}
-		return nil, HandleSQLError(err)
 	}
 
-	return &openfgav1.Store{
-		Id:        storeID,
-		Name:      name,
-		CreatedAt: timestamppb.New(createdAt),
-		UpdatedAt: timestamppb.New(updatedAt),
-	}, nil
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	return len(stores), nil
 }
 
-// ListStores provides a paginated list of all stores present in the storage.
-func (s *Datastore) ListStores(ctx context.Context, options storage.ListStoresOptions) ([]*openfgav1.Store, []byte, error) {
-	ctx, span := startTrace(ctx, "ListStores")
+// PruneChanges see [storage.ChangelogPruner].PruneChanges.
+func (s *Datastore) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PruneChanges")
 	defer span.End()
 
-	var whereClause sq.Sqlizer
-	if len(options.IDs) > 0 {
-		whereClause = sq.And{
-			sq.Eq{"deleted_at": nil},
-			sq.Eq{"id": options.IDs},
-		}
-	} else {
-		whereClause = sq.Eq{"deleted_at": nil}
-	}
+	var ulids []string
+	err := busyRetry(func() error {
+		ulids = nil
 
-	sb := s.stbl.
-		Select("id", "name", "created_at", "updated_at").
-		From("store").
-		Where(whereClause).
-		OrderBy("id")
+		cutoff, err := sqlcommon.ChangelogPruneCutoff(ctx, s.stbl, store, olderThan, keepCount)
+		if err != nil || cutoff == "" {
+			return err
+		}
 
-	if options.Pagination.From != "" {
-		token, _, err := s.tokenSerializer.Deserialize(options.Pagination.From)
+		rows, err := s.stbl.
+			Select("ulid").
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			Where(sq.Lt{"ulid": cutoff}).
+			OrderBy("ulid").
+			Limit(uint64(limit)).
+			QueryContext(ctx)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
-		sb = sb.Where(sq.GtOrEq{"id": token})
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ulids = append(ulids, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
 	}
-	if options.Pagination.PageSize > 0 {
-		sb = sb.Limit(uint64(options.Pagination.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+
+	if len(ulids) == 0 {
+		return 0, nil
 	}
 
-	rows, err := sb.QueryContext(ctx)
-	if err != nil {
-		return nil, nil, HandleSQLError(err)
+	if err := s.deleteChanges(ctx, store, ulids, ulids[len(ulids)-1]); err != nil {
+		return 0, err
 	}
-	defer rows.Close()
+	return len(ulids), nil
+}
 
-	var stores []*openfgav1.Store
-	var id string
-	for rows.Next() {
+// CompactChanges see [storage.ChangelogPruner].CompactChanges.
+func (s *Datastore) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "CompactChanges")
+	defer span.End()
+
+	if olderThan.IsZero() {
+		return 0, nil
+	}
+
+	var collapsible *storage.CollapsibleChanges
+	err := busyRetry(func() error {
+		collapsible = storage.NewCollapsibleChanges()
+
+		rows, err := s.stbl.
+			Select(
+				"ulid", "object_type", "object_id", "relation",
+				"user_object_type", "user_object_id", "user_relation", "operation",
+			).
+			From("changelog").
+			Where(sq.Eq{"store": store}).
+			Where(sq.Lt{"ulid": storage.ChangesBefore(olderThan).String()}).
+			OrderBy("ulid").
+			QueryContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for collapsible.Len() < limit && rows.Next() {
+			var id, objectType, objectID, relation, userObjectType, userObjectID, userRelation string
+			var operation int
+			if err := rows.Scan(&id, &objectType, &objectID, &relation, &userObjectType, &userObjectID, &userRelation, &operation); err != nil {
+				return err
+			}
+			tk := tupleUtils.NewTupleKey(
+				tupleUtils.BuildObject(objectType, objectID),
+				relation,
+				tupleUtils.FromUserParts(userObjectType, userObjectID, userRelation),
+			)
+			collapsible.Add(id, tk, openfgav1.TupleOperation(operation))
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	ulids, horizon := collapsible.ULIDs(limit)
+	if len(ulids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.deleteChanges(ctx, store, ulids, horizon); err != nil {
+		return 0, err
+	}
+	return len(ulids) / 2, nil
+}
+
+// deleteChanges deletes the changes of the store, and advances its horizon, in a single transaction.
+func (s *Datastore) deleteChanges(ctx context.Context, store string, ulids []string, horizon string) error {
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	err = busyRetry(func() error {
+		_, err := s.stbl.
+			Delete("changelog").
+			Where(sq.Eq{"store": store, "ulid": ulids}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		return sqlcommon.AdvanceChangelogHorizon(ctx, s.stbl.RunWith(txn), store, horizon)
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	return nil
+}
+
+// deleteExpiredTuples deletes, as part of the transaction, the tuples matching the filter which expired at or
+// before now, up to limit if it is positive. Their deletes are added to the changelog insert, and returned along with
+// their stores, once per tuple, for the outbox.
+func (s *Datastore) deleteExpiredTuples(
+	ctx context.Context,
+	txn *sql.Tx,
+	filter sq.Sqlizer,
+	now time.Time,
+	limit int,
+	changelogBuilder *sq.InsertBuilder,
+) ([]string, []storage.EventTupleChange, error) {
+	sb := s.stbl.
+		Select(
+			"store", "object_type", "object_id", "relation",
+			"user_object_type", "user_object_id", "user_relation", "ulid",
+		).
+		From("tuple").
+		Where(sq.LtOrEq{"expires_at": now})
+	if filter != nil {
+		sb = sb.Where(filter)
+	}
+	if limit > 0 {
+		sb = sb.OrderBy("expires_at").Limit(uint64(limit))
+	}
+
+	var stores, ulids []string
+	var changes []storage.EventTupleChange
+	initialChangelogBuilder := *changelogBuilder
+	err := busyRetry(func() error {
+		stores, ulids, changes = nil, nil, nil
+		*changelogBuilder = initialChangelogBuilder
+
+		rows, err := sb.RunWith(txn).QueryContext(ctx) // Part of a txn.
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var store, objectType, objectID, relation, userObjectType, userObjectID, userRelation, tupleUlid string
+			if err := rows.Scan(&store, &objectType, &objectID, &relation, &userObjectType, &userObjectID, &userRelation, &tupleUlid); err != nil {
+				return err
+			}
+
+			stores = append(stores, store)
+			ulids = append(ulids, tupleUlid)
+			changes = append(changes, storage.NewTupleDeleteChange(&openfgav1.TupleKeyWithoutCondition{
+				Object:   tupleUtils.BuildObject(objectType, objectID),
+				Relation: relation,
+				User:     tupleUtils.FromUserParts(userObjectType, userObjectID, userRelation),
+			}))
+			*changelogBuilder = changelogBuilder.Values(
+				store, objectType, objectID, relation,
+				userObjectType, userObjectID, userRelation,
+				"", nil, // Redact condition info for deletes since we only need the base triplet (object, relation, user).
+				openfgav1.TupleOperation_TUPLE_OPERATION_DELETE,
+				ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String(),
+				sq.Expr("datetime('subsec')"),
+			)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+
+	if len(ulids) == 0 {
+		return nil, nil, nil
+	}
+
+	err = busyRetry(func() error {
+		_, err := s.stbl.
+			Delete("tuple").
+			Where(sq.Eq{"ulid": ulids}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+
+	return stores, changes, nil
+}
+
+// readTupleForWrite reads, as part of the write transaction, the tuple with the given key,
+// returning nil if it doesn't exist or has expired.
+func (s *Datastore) readTupleForWrite(
+	ctx context.Context,
+	txn *sql.Tx,
+	store string,
+	tk tupleUtils.TupleWithoutCondition,
+	now time.Time,
+) (*storage.TupleRecord, error) {
+	objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+	userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+
+	var conditionName sql.NullString
+	var conditionContext []byte
+	err := busyRetry(func() error {
+		return s.stbl.
+			Select("condition_name", "condition_context").
+			From("tuple").
+			Where(sq.Eq{
+				"store":            store,
+				"object_type":      objectType,
+				"object_id":        objectID,
+				"relation":         tk.GetRelation(),
+				"user_object_type": userObjectType,
+				"user_object_id":   userObjectID,
+				"user_relation":    userRelation,
+				"user_type":        tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+			}).
+			Where(sqlcommon.NotExpired(now)).
+			RunWith(txn). // Part of a txn.
+			QueryRowContext(ctx).
+			Scan(&conditionName, &conditionContext)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, HandleSQLError(err)
+	}
+
+	record := &storage.TupleRecord{ConditionName: conditionName.String}
+	if conditionContext != nil {
+		var conditionContextStruct structpb.Struct
+		if err := proto.Unmarshal(conditionContext, &conditionContextStruct); err != nil {
+			return nil, err
+		}
+		record.ConditionContext = &conditionContextStruct
+	}
+
+	return record, nil
+}
+
+// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
+func (s *Datastore) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
+	ctx, span := startTrace(ctx, "WriteAuthorizationModel")
+	defer span.End()
+
+	schemaVersion := model.GetSchemaVersion()
+	typeDefinitions := model.GetTypeDefinitions()
+
+	if len(typeDefinitions) < 1 {
+		return nil
+	}
+
+	if len(typeDefinitions) > s.MaxTypesPerAuthorizationModel() {
+		return storage.ExceededMaxTypeDefinitionsLimitError(s.maxTypesPerModelField)
+	}
+
+	pbdata, err := proto.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeStore(ctx, func(txn *sql.Tx) error {
+		_, err := s.stbl.
+			Insert("authorization_model").
+			Columns("store", "authorization_model_id", "schema_version", "serialized_protobuf").
+			Values(store, model.GetId(), schemaVersion, pbdata).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		return s.insertOutboxEvent(ctx, txn, storage.NewAuthorizationModelWrittenEvent(store, model.GetId()))
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// CreateStore adds a new store to storage.
+func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "CreateStore")
+	defer span.End()
+
+	if s.outbox {
+		// the store and its event are written in one transaction
+		return s.CreateStoreWithLabels(ctx, store, nil)
+	}
+
+	var id, name string
+	var createdAt, updatedAt time.Time
+
+	err := busyRetry(func() error {
+		return s.stbl.
+			Insert("store").
+			Columns("id", "name", "created_at", "updated_at").
+			Values(store.GetId(), store.GetName(), sq.Expr("datetime('subsec')"), sq.Expr("datetime('subsec')")).
+			Suffix("returning id, name, created_at, updated_at").
+			QueryRowContext(ctx).
+			Scan(&id, &name, &createdAt, &updatedAt)
+	})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return &openfgav1.Store{
+		Id:        id,
+		Name:      name,
+		CreatedAt: timestamppb.New(createdAt),
+		UpdatedAt: timestamppb.New(updatedAt),
+	}, nil
+}
+
+// GetStore retrieves the details of a specific store using its storeID.
+func (s *Datastore) GetStore(ctx context.Context, id string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "GetStore")
+	defer span.End()
+
+	row := s.stbl.
+		Select("id", "name", "created_at", "updated_at").
+		From("store").
+		Where(sq.Eq{
+			"id":         id,
+			"deleted_at": nil,
+		}).
+		QueryRowContext(ctx)
+
+	var storeID, name string
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&storeID, &name, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+
+	return &openfgav1.Store{
+		Id:        storeID,
+		Name:      name,
+		CreatedAt: timestamppb.New(createdAt),
+		UpdatedAt: timestamppb.New(updatedAt),
+	}, nil
+}
+
+// ListStores provides a paginated list of all stores present in the storage.
+func (s *Datastore) ListStores(ctx context.Context, options storage.ListStoresOptions) ([]*openfgav1.Store, []byte, error) {
+	ctx, span := startTrace(ctx, "ListStores")
+	defer span.End()
+
+	sb := s.stbl.
+		Select("id", "name", "created_at", "updated_at").
+		From("store").
+		Where(sqlcommon.ListStoresFilter(options)).
+		OrderBy("id")
+
+	if options.Pagination.From != "" {
+		token, _, err := s.tokenSerializer.Deserialize(options.Pagination.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		sb = sb.Where(sq.GtOrEq{"id": token})
+	}
+	if options.Pagination.PageSize > 0 {
+		sb = sb.Limit(uint64(options.Pagination.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var stores []*openfgav1.Store
+	var id string
+	for rows.Next() {
 		var name string
 		var createdAt, updatedAt time.Time
 		err := rows.Scan(&id, &name, &createdAt, &updatedAt)
 		if err != nil {
-			return nil, nil, HandleSQLError(err)
+			return nil, nil, HandleSQLError(err)
+		}
+
+		stores = append(stores, &openfgav1.Store{
+			Id:        id,
+			Name:      name,
+			CreatedAt: timestamppb.New(createdAt),
+			UpdatedAt: timestamppb.New(updatedAt),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+
+	if len(stores) > options.Pagination.PageSize {
+		contToken, err := s.tokenSerializer.Serialize(id, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return stores[:options.Pagination.PageSize], contToken, nil
+	}
+
+	return stores, nil, nil
+}
+
+// DeleteStore removes a store from storage.
+func (s *Datastore) DeleteStore(ctx context.Context, id string) error {
+	ctx, span := startTrace(ctx, "DeleteStore")
+	defer span.End()
+
+	err := s.writeStore(ctx, func(txn *sql.Tx) error {
+		res, err := s.stbl.
+			Update("store").
+			Set("deleted_at", sq.Expr("datetime('subsec')")).
+			Where(sq.Eq{"id": id}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := res.RowsAffected()
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return s.insertOutboxEvent(ctx, txn, storage.NewStoreDeletedEvent(id))
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler].CreateStoreWithLabels.
+func (s *Datastore) CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "CreateStoreWithLabels")
+	defer span.End()
+
+	err := s.writeStore(ctx, func(txn *sql.Tx) error {
+		_, err := s.stbl.
+			Insert("store").
+			Columns("id", "name", "created_at", "updated_at").
+			Values(store.GetId(), store.GetName(), sq.Expr("datetime('subsec')"), sq.Expr("datetime('subsec')")).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := sqlcommon.WriteStoreLabels(ctx, s.stbl.RunWith(txn), store.GetId(), labels); err != nil {
+			return err
+		}
+
+		return s.insertOutboxEvent(ctx, txn, storage.NewStoreCreatedEvent(store))
+	})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return s.GetStore(ctx, store.GetId())
+}
+
+// UpdateStore see [storage.StoreLabeler].UpdateStore.
+func (s *Datastore) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UpdateStore")
+	defer span.End()
+
+	err := s.writeStore(ctx, func(txn *sql.Tx) error {
+		update := s.stbl.
+			Update("store").
+			Set("updated_at", sq.Expr("datetime('subsec')")).
+			Where(sq.Eq{"id": id, "deleted_at": nil})
+		if name != "" {
+			update = update.Set("name", name)
+		}
+		res, err := update.RunWith(txn).ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		updated, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return storage.ErrNotFound
+		}
+
+		if labels == nil {
+			return nil
+		}
+		return sqlcommon.WriteStoreLabels(ctx, s.stbl.RunWith(txn), id, labels)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+
+	return s.GetStore(ctx, id)
+}
+
+// writeStore runs fn in a transaction, retried while the database is busy.
+func (s *Datastore) writeStore(ctx context.Context, fn func(txn *sql.Tx) error) error {
+	return busyRetry(func() error {
+		txn, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = txn.Rollback()
+		}()
+
+		if err := fn(txn); err != nil {
+			return err
+		}
+		return txn.Commit()
+	})
+}
+
+// ReadStoreLabels see [storage.StoreLabeler].ReadStoreLabels.
+func (s *Datastore) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	ctx, span := startTrace(ctx, "ReadStoreLabels")
+	defer span.End()
+
+	if _, err := s.GetStore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.stbl.
+		Select("label_key", "label_value").
+		From("store_label").
+		Where(sq.Eq{"store": id}).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	labels := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, HandleSQLError(err)
+		}
+		labels[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return labels, nil
+}
+
+// CountTuples see [storage.TupleCounter].CountTuples.
+func (s *Datastore) CountTuples(ctx context.Context, store string) (int64, error) {
+	ctx, span := startTrace(ctx, "CountTuples")
+	defer span.End()
+
+	var count int64
+	err := busyRetry(func() error {
+		var err error
+		count, err = sqlcommon.CountTuples(ctx, s.stbl, store)
+		return err
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return count, nil
+}
+
+// tupleRecordColumns are the columns of a tuple read as it is stored, in the order of scanTupleRecord.
+var tupleRecordColumns = []string{
+	"store", "object_type", "object_id", "relation",
+	"user_object_type", "user_object_id", "user_relation",
+	"condition_name", "condition_context", "ulid", "inserted_at", "expires_at",
+}
+
+// ReadTupleRecords see [storage.RecordCopier].ReadTupleRecords. The tuples are ordered by their primary key, which
+// orders the users by type, ID and relation, the continuation token being the last one as a string.
+func (s *Datastore) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecords")
+	defer span.End()
+
+	sb := s.stbl.
+		Select(tupleRecordColumns...).
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		OrderBy("object_type", "object_id", "relation", "user_object_type", "user_object_id", "user_relation")
+	if options.From != "" {
+		tk, err := tupleUtils.ParseTupleString(options.From)
+		if err != nil {
+			return nil, nil, storage.ErrInvalidContinuationToken
+		}
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+		sb = sb.Where(sq.Expr(
+			"(object_type, object_id, relation, user_object_type, user_object_id, user_relation) > (?, ?, ?, ?, ?, ?)",
+			objectType, objectID, tk.GetRelation(), userObjectType, userObjectID, userRelation,
+		))
+	}
+	if options.PageSize > 0 {
+		sb = sb.Limit(uint64(options.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	var records []*storage.TupleRecord
+	err := busyRetry(func() error {
+		records = nil
+		rows, err := sb.QueryContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			record, err := scanTupleRecord(rows)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, nil, HandleSQLError(err)
+	}
+
+	if options.PageSize > 0 && len(records) > options.PageSize {
+		records = records[:options.PageSize]
+		return records, []byte(tupleUtils.TupleKeyToString(records[len(records)-1].AsTuple().GetKey())), nil
+	}
+	return records, nil, nil
+}
+
+// ReadTupleRecord see [storage.RecordCopier].ReadTupleRecord.
+func (s *Datastore) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	ctx, span := startTrace(ctx, "ReadTupleRecord")
+	defer span.End()
+
+	objectType, objectID := tupleUtils.SplitObject(key.GetObject())
+	userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(key.GetUser())
+
+	var record *storage.TupleRecord
+	err := busyRetry(func() error {
+		var err error
+		record, err = scanTupleRecord(s.stbl.
+			Select(tupleRecordColumns...).
+			From("tuple").
+			Where(sq.Eq{
+				"store":            store,
+				"object_type":      objectType,
+				"object_id":        objectID,
+				"relation":         key.GetRelation(),
+				"user_object_type": userObjectType,
+				"user_object_id":   userObjectID,
+				"user_relation":    userRelation,
+			}).
+			QueryRowContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return record, nil
+}
+
+func scanTupleRecord(row sq.RowScanner) (*storage.TupleRecord, error) {
+	var record storage.TupleRecord
+	var conditionName sql.NullString
+	var conditionContext []byte
+	var expiresAt sql.NullTime
+	err := row.Scan(
+		&record.Store,
+		&record.ObjectType,
+		&record.ObjectID,
+		&record.Relation,
+		&record.UserObjectType,
+		&record.UserObjectID,
+		&record.UserRelation,
+		&conditionName,
+		&conditionContext,
+		&record.Ulid,
+		&record.InsertedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.ConditionName = conditionName.String
+	if conditionContext != nil {
+		record.ConditionContext = &structpb.Struct{}
+		if err := proto.Unmarshal(conditionContext, record.ConditionContext); err != nil {
+			return nil, err
+		}
+	}
+	if expiresAt.Valid {
+		record.ExpiresAt = &expiresAt.Time
+	}
+	return &record, nil
+}
+
+// ReadChangeRecords see [storage.RecordCopier].ReadChangeRecords.
+func (s *Datastore) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	ctx, span := startTrace(ctx, "ReadChangeRecords")
+	defer span.End()
+
+	sb := s.stbl.
+		Select(
+			"ulid", "object_type", "object_id", "relation",
+			"user_object_type", "user_object_id", "user_relation",
+			"operation", "condition_name", "condition_context", "inserted_at",
+		).
+		From("changelog").
+		Where(sq.Eq{"store": store}).
+		OrderBy("ulid")
+	if after != "" {
+		sb = sb.Where(sq.Gt{"ulid": after})
+	}
+	if limit > 0 {
+		sb = sb.Limit(uint64(limit))
+	}
+
+	var records []*storage.ChangeRecord
+	err := busyRetry(func() error {
+		records = nil
+		rows, err := sb.QueryContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var record storage.ChangeRecord
+			var objectType, objectID, relation, userObjectType, userObjectID, userRelation string
+			var operation int
+			var conditionName sql.NullString
+			var conditionContext []byte
+			err := rows.Scan(
+				&record.Ulid,
+				&objectType,
+				&objectID,
+				&relation,
+				&userObjectType,
+				&userObjectID,
+				&userRelation,
+				&operation,
+				&conditionName,
+				&conditionContext,
+				&record.InsertedAt,
+			)
+			if err != nil {
+				return err
+			}
+
+			var conditionContextStruct *structpb.Struct
+			if conditionContext != nil {
+				conditionContextStruct = &structpb.Struct{}
+				if err := proto.Unmarshal(conditionContext, conditionContextStruct); err != nil {
+					return err
+				}
+			}
+
+			record.Operation = openfgav1.TupleOperation(operation)
+			record.TupleKey = tupleUtils.NewTupleKeyWithCondition(
+				tupleUtils.BuildObject(objectType, objectID),
+				relation,
+				tupleUtils.FromUserParts(userObjectType, userObjectID, userRelation),
+				conditionName.String,
+				conditionContextStruct,
+			)
+			records = append(records, &record)
 		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	return records, nil
+}
 
-		stores = append(stores, &openfgav1.Store{
-			Id:        id,
-			Name:      name,
-			CreatedAt: timestamppb.New(createdAt),
-			UpdatedAt: timestamppb.New(updatedAt),
+// ImportRecords see [storage.RecordCopier].ImportRecords.
+func (s *Datastore) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	ctx, span := startTrace(ctx, "ImportRecords")
+	defer span.End()
+
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	if len(records.Changes) > 0 {
+		changelogBuilder := s.stbl.
+			Insert("changelog").
+			Columns(
+				"store", "object_type", "object_id", "relation",
+				"user_object_type", "user_object_id", "user_relation",
+				"condition_name", "condition_context", "operation", "ulid", "inserted_at",
+			).
+			Suffix("ON CONFLICT DO NOTHING") // the changes copied already are skipped
+		for _, change := range records.Changes {
+			tk := change.TupleKey
+			objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+			userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+			conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(tk.GetCondition())
+			if err != nil {
+				return err
+			}
+
+			changelogBuilder = changelogBuilder.Values(
+				store, objectType, objectID, tk.GetRelation(),
+				userObjectType, userObjectID, userRelation,
+				conditionName, conditionContext, change.Operation, change.Ulid, formatTimestamp(change.InsertedAt),
+			)
+		}
+
+		err := busyRetry(func() error {
+			_, err := changelogBuilder.RunWith(txn).ExecContext(ctx) // Part of a txn.
+			return err
 		})
+		if err != nil {
+			return HandleSQLError(err)
+		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, nil, HandleSQLError(err)
+	var tupleDelta int64
+	for _, tk := range records.Deletes {
+		deleted, err := s.deleteTupleRecord(ctx, txn, store, tk)
+		if err != nil {
+			return err
+		}
+		tupleDelta -= deleted
 	}
 
-	if len(stores) > options.Pagination.PageSize {
-		contToken, err := s.tokenSerializer.Serialize(id, "")
+	insertBuilder := s.stbl.
+		Insert("tuple").
+		Columns(
+			"store", "object_type", "object_id", "relation",
+			"user_object_type", "user_object_id", "user_relation", "user_type",
+			"condition_name", "condition_context", "ulid", "inserted_at", "expires_at",
+		)
+	for _, record := range records.Tuples {
+		tk := record.AsTuple().GetKey()
+		replaced, err := s.deleteTupleRecord(ctx, txn, store, tk)
 		if err != nil {
-			return nil, nil, err
+			return err
+		}
+		tupleDelta += 1 - replaced
+
+		conditionName, conditionContext, err := sqlcommon.MarshalRelationshipCondition(tk.GetCondition())
+		if err != nil {
+			return err
+		}
+
+		var expiresAt *time.Time
+		if record.ExpiresAt != nil {
+			expiresAt = new(time.Time)
+			*expiresAt = record.ExpiresAt.UTC()
+		}
+
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+		err = busyRetry(func() error {
+			_, err := insertBuilder.
+				Values(
+					store, objectType, objectID, tk.GetRelation(),
+					userObjectType, userObjectID, userRelation, tupleUtils.GetUserTypeFromUser(tk.GetUser()),
+					conditionName, conditionContext, record.Ulid, formatTimestamp(record.InsertedAt), expiresAt,
+				).
+				RunWith(txn). // Part of a txn.
+				ExecContext(ctx)
+			return err
+		})
+		if err != nil {
+			return HandleSQLError(err, tk)
 		}
-		return stores[:options.Pagination.PageSize], contToken, nil
 	}
 
-	return stores, nil, nil
+	err = busyRetry(func() error {
+		return sqlcommon.AddTupleCount(ctx, s.stbl.RunWith(txn), sqlcommon.UpsertTupleCount, store, tupleDelta)
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
 }
 
-// DeleteStore removes a store from storage.
-func (s *Datastore) DeleteStore(ctx context.Context, id string) error {
-	ctx, span := startTrace(ctx, "DeleteStore")
+// deleteTupleRecord deletes the tuple of the store with the key, expired or not, as part of the transaction, and
+// returns how many tuples were deleted.
+func (s *Datastore) deleteTupleRecord(ctx context.Context, txn *sql.Tx, store string, tk tupleUtils.TupleWithoutCondition) (int64, error) {
+	objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+	userObjectType, userObjectID, userRelation := tupleUtils.ToUserParts(tk.GetUser())
+
+	var res sql.Result
+	err := busyRetry(func() error {
+		var err error
+		res, err = s.stbl.
+			Delete("tuple").
+			Where(sq.Eq{
+				"store":            store,
+				"object_type":      objectType,
+				"object_id":        objectID,
+				"relation":         tk.GetRelation(),
+				"user_object_type": userObjectType,
+				"user_object_id":   userObjectID,
+				"user_relation":    userRelation,
+			}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, HandleSQLError(err, tk)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	return deleted, nil
+}
+
+// formatTimestamp formats the time as datetime('subsec') does, so that it compares with the timestamps set by
+// the database.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.000")
+}
+
+// ReadOutbox see [storage.EventOutbox].ReadOutbox.
+func (s *Datastore) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	ctx, span := startTrace(ctx, "ReadOutbox")
 	defer span.End()
 
-	_, err := s.stbl.
-		Update("store").
-		Set("deleted_at", sq.Expr("datetime('subsec')")).
-		Where(sq.Eq{"id": id}).
-		ExecContext(ctx)
+	var events []*storage.Event
+	err := busyRetry(func() error {
+		var err error
+		events, err = sqlcommon.ReadOutbox(ctx, s.stbl, limit)
+		return err
+	})
 	if err != nil {
-		return HandleSQLError(err)
+		return nil, HandleSQLError(err)
 	}
+	return events, nil
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox].DeleteOutboxEvents.
+func (s *Datastore) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	ctx, span := startTrace(ctx, "DeleteOutboxEvents")
+	defer span.End()
 
+	err := busyRetry(func() error {
+		return sqlcommon.DeleteOutboxEvents(ctx, s.stbl, ids)
+	})
+	if err != nil {
+		return HandleSQLError(err)
+	}
 	return nil
 }
 
+// insertOutboxEvent inserts the event in the outbox as part of the transaction, if the outbox is enabled.
+func (s *Datastore) insertOutboxEvent(ctx context.Context, txn *sql.Tx, event *storage.Event) error {
+	if !s.outbox {
+		return nil
+	}
+	return sqlcommon.InsertOutboxEvent(ctx, s.stbl.RunWith(txn), event)
+}
+
+// UndeleteStore see [storage.StoreUndeleter].UndeleteStore.
+func (s *Datastore) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	ctx, span := startTrace(ctx, "UndeleteStore")
+	defer span.End()
+
+	where := sq.And{sq.Eq{"id": id}, sq.NotEq{"deleted_at": nil}}
+	if !deletedAfter.IsZero() {
+		where = append(where, sq.Expr("deleted_at >= datetime(?, 'subsec')", deletedAfter.UTC().Format(time.RFC3339Nano)))
+	}
+
+	err := busyRetry(func() error {
+		_, err := s.stbl.
+			Update("store").
+			Set("deleted_at", nil).
+			Set("updated_at", sq.Expr("datetime('subsec')")).
+			Where(where).
+			ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return s.GetStore(ctx, id)
+}
+
+// ListDeletedStores see [storage.StorePurger].ListDeletedStores.
+func (s *Datastore) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	ctx, span := startTrace(ctx, "ListDeletedStores")
+	defer span.End()
+
+	rows, err := s.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Expr("deleted_at < datetime(?, 'subsec')", deletedBefore.UTC().Format(time.RFC3339Nano))).
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, HandleSQLError(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return ids, nil
+}
+
+// PurgeStore see [storage.StorePurger].PurgeStore.
+func (s *Datastore) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	ctx, span := startTrace(ctx, "PurgeStore")
+	defer span.End()
+
+	var txn *sql.Tx
+	err := busyRetry(func() error {
+		var err error
+		txn, err = s.db.BeginTx(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var count int
+	err = s.stbl.
+		Select("COUNT(*)").
+		From("store").
+		Where(sq.Eq{"id": id}).
+		Where(sq.Expr("deleted_at < datetime(?, 'subsec')", deletedBefore.UTC().Format(time.RFC3339Nano))).
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&count)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, table := range sqlcommon.StorePurgeTables {
+		if deleted >= limit {
+			break
+		}
+
+		err := busyRetry(func() error {
+			n, err := sqlcommon.PurgeStoreRows(ctx, s.stbl.RunWith(txn), table.Table, table.Key, id, limit-deleted)
+			deleted += n
+			return err
+		})
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+	}
+
+	if deleted < limit {
+		// no rows are left: the store itself is deleted last
+		err := busyRetry(func() error {
+			_, err := s.stbl.
+				Delete("changelog_horizon").
+				Where(sq.Eq{"store": id}).
+				RunWith(txn). // Part of a txn.
+				ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			_, err = s.stbl.
+				Delete("store").
+				Where(sq.Eq{"id": id}).
+				RunWith(txn). // Part of a txn.
+				ExecContext(ctx)
+			return err
+		})
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+	}
+
+	err = busyRetry(func() error {
+		return txn.Commit()
+	})
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	return deleted, nil
+}
+
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
 func (s *Datastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	ctx, span := startTrace(ctx, "WriteAssertions")
@@ -1019,6 +2367,14 @@ func (s *Datastore) ReadChanges(
 			return nil, nil, storage.ErrMismatchObjectType
 		}
 
+		horizon, err := sqlcommon.ReadChangelogHorizon(ctx, s.stbl, store)
+		if err != nil {
+			return nil, nil, HandleSQLError(err)
+		}
+		if token < horizon {
+			return nil, nil, storage.ErrContinuationTokenExpired
+		}
+
 		sb = sqlcommon.AddFromUlid(sb, token, options.SortDesc)
 	}
 	if options.Pagination.PageSize > 0 {
@@ -1095,7 +2451,7 @@ func (s *Datastore) ReadChanges(
 
 // IsReady see [sqlcommon.IsReady].
 func (s *Datastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
-	return sqlcommon.IsReady(ctx, s.db)
+	return sqlcommon.IsReady(ctx, s.db, goose.DialectSQLite3)
 }
 
 // HandleSQLError processes an SQL error and converts it into a more
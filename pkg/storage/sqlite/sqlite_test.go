@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -25,6 +26,16 @@ func TestSQLiteDatastore(t *testing.T) {
 	test.RunAllTests(t, ds, sqlcommon.NewSQLContinuationTokenSerializer())
 }
 
+func TestSQLiteDatastoreOutbox(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig(sqlcommon.WithOutbox()))
+	require.NoError(t, err)
+	defer ds.Close()
+	test.EventOutboxTest(t, ds, ds)
+}
+
 func TestSQLiteDatastoreAfterCloseIsNotReady(t *testing.T) {
 	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
 
@@ -37,6 +48,17 @@ func TestSQLiteDatastoreAfterCloseIsNotReady(t *testing.T) {
 	require.False(t, status.IsReady)
 }
 
+func TestSQLiteDatastoreWithoutMigrationsIsNotReady(t *testing.T) {
+	ds, err := New("file:"+filepath.Join(t.TempDir(), "openfga.sqlite"), sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+
+	status, err := ds.IsReady(context.Background())
+	require.NoError(t, err)
+	require.False(t, status.IsReady)
+	require.Contains(t, status.Message, "at revision '0'")
+}
+
 // TestReadEnsureNoOrder asserts that the read response is not ordered by ulid.
 func TestReadEnsureNoOrder(t *testing.T) {
 	tests := []struct {
@@ -72,6 +94,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{firstTuple},
+				storage.TupleWriteOptions{},
 				time.Now())
 			require.NoError(t, err)
 
@@ -80,6 +103,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{secondTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-1))
 			require.NoError(t, err)
 
@@ -87,6 +111,7 @@ func TestReadEnsureNoOrder(t *testing.T) {
 				store,
 				[]*openfgav1.TupleKeyWithoutCondition{},
 				[]*openfgav1.TupleKey{thirdTuple},
+				storage.TupleWriteOptions{},
 				time.Now().Add(time.Minute*-2))
 			require.NoError(t, err)
 
@@ -159,6 +184,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{firstTuple},
+		storage.TupleWriteOptions{},
 		time.Now())
 	require.NoError(t, err)
 
@@ -167,6 +193,7 @@ func TestReadPageEnsureOrder(t *testing.T) {
 		store,
 		[]*openfgav1.TupleKeyWithoutCondition{},
 		[]*openfgav1.TupleKey{secondTuple},
+		storage.TupleWriteOptions{},
 		time.Now().Add(time.Minute*-1))
 	require.NoError(t, err)
 
@@ -6,7 +6,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
 )
 
 type ctxKey string
@@ -18,6 +21,11 @@ const (
 	// which is a balance between efficiency and resource usage.
 	DefaultMaxTuplesPerWrite = 100
 
+	// DefaultMaxTuplesPerImport specifies the default maximum number of tuples that can be written
+	// in a single import operation (see TupleImporter). Imports write in large batches to reduce the
+	// number of round trips and transactions needed to seed a store.
+	DefaultMaxTuplesPerImport = 10000
+
 	// DefaultMaxTypesPerAuthorizationModel defines the default upper limit on the number of distinct
 	// types that can be included in a single authorization model. This constraint helps in managing
 	// the complexity and ensuring the maintainability of the authorization models. The limit is
@@ -87,8 +95,12 @@ type ReadAuthorizationModelsOptions struct {
 // be used with the ListStores method.
 type ListStoresOptions struct {
 	// IDs is a list of store IDs to filter the results.
-	IDs        []string
-	Pagination PaginationOptions
+	IDs []string
+	// NamePrefix filters the results to the stores whose name starts with it, if not empty.
+	NamePrefix string
+	// LabelSelector filters the results to the stores whose labels match it (see StoreLabeler), if not empty.
+	LabelSelector LabelSelector
+	Pagination    PaginationOptions
 }
 
 // ReadChangesOptions represents the options that can
@@ -123,6 +135,12 @@ type ReadUserTupleOptions struct {
 	Consistency ConsistencyOptions
 }
 
+// ReadTuplesBatchOptions represents the options that can
+// be used with the ReadTuplesBatch method.
+type ReadTuplesBatchOptions struct {
+	Consistency ConsistencyOptions
+}
+
 // ReadUsersetTuplesOptions represents the options that can
 // be used with the ReadUsersetTuples method.
 type ReadUsersetTuplesOptions struct {
@@ -141,6 +159,105 @@ type Writes = []*openfgav1.TupleKey
 // Deletes is a typesafe alias for Delete arguments.
 type Deletes = []*openfgav1.TupleKeyWithoutCondition
 
+// OnDuplicateInsert determines what Write does with a tuple to write that already exists.
+type OnDuplicateInsert int
+
+const (
+	// OnDuplicateInsertError fails the write with ErrInvalidWriteInput. This is the default.
+	OnDuplicateInsertError OnDuplicateInsert = iota
+
+	// OnDuplicateInsertIgnore skips the tuple if it already exists with the same condition, without
+	// recording a change. It still fails the write with ErrInvalidWriteInput if the condition differs.
+	OnDuplicateInsertIgnore
+)
+
+// OnMissingDelete determines what Write does with a tuple to delete that doesn't exist.
+type OnMissingDelete int
+
+const (
+	// OnMissingDeleteError fails the write with ErrInvalidWriteInput. This is the default.
+	OnMissingDeleteError OnMissingDelete = iota
+
+	// OnMissingDeleteIgnore skips the tuple, without recording a change.
+	OnMissingDeleteIgnore
+)
+
+// WritePrecondition asserts that a tuple exists, or doesn't exist, before a write is applied.
+type WritePrecondition struct {
+	TupleKey *openfgav1.TupleKeyWithoutCondition
+	Exists   bool
+}
+
+// TupleWriteOptions represents the options that can
+// be used with the Write method.
+type TupleWriteOptions struct {
+	OnDuplicateInsert OnDuplicateInsert
+	OnMissingDelete   OnMissingDelete
+	Preconditions     []WritePrecondition
+	// ExpiresAt, if not zero, is when the tuples written expire.
+	ExpiresAt time.Time
+	// CommittedRevision, if not nil, is set to the ULID of the last change recorded by the write once committed.
+	CommittedRevision *ulid.ULID
+}
+
+// TupleWriteOption defines a function type
+// used for configuring a TupleWriteOptions object.
+type TupleWriteOption func(*TupleWriteOptions)
+
+// WithOnDuplicateInsert sets what Write does with the tuples to write that already exist.
+func WithOnDuplicateInsert(onDuplicateInsert OnDuplicateInsert) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.OnDuplicateInsert = onDuplicateInsert
+	}
+}
+
+// WithOnMissingDelete sets what Write does with the tuples to delete that don't exist.
+func WithOnMissingDelete(onMissingDelete OnMissingDelete) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.OnMissingDelete = onMissingDelete
+	}
+}
+
+// WithPreconditions adds preconditions that must hold before Write applies its deletes and writes.
+func WithPreconditions(preconditions ...WritePrecondition) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.Preconditions = append(opts.Preconditions, preconditions...)
+	}
+}
+
+// WithExpiresAt sets when the tuples written expire. Once expired, they are no longer read, and they are
+// eventually deleted (see TupleReaper).
+func WithExpiresAt(expiresAt time.Time) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.ExpiresAt = expiresAt
+	}
+}
+
+// WithCommittedRevision makes Write set the revision to the ULID of the last change it recorded in the changelog,
+// once committed. The revision is left untouched if the write recorded no change.
+func WithCommittedRevision(revision *ulid.ULID) TupleWriteOption {
+	return func(opts *TupleWriteOptions) {
+		opts.CommittedRevision = revision
+	}
+}
+
+// SetCommittedRevision sets the committed revision requested by the options, if any, to the ULID of the last change
+// recorded by the write. Datastores call it once the write is committed.
+func (o TupleWriteOptions) SetCommittedRevision(revision ulid.ULID) {
+	if o.CommittedRevision != nil {
+		*o.CommittedRevision = revision
+	}
+}
+
+// NewTupleWriteOptions creates a new [TupleWriteOptions] instance from the options given to Write.
+func NewTupleWriteOptions(opts ...TupleWriteOption) TupleWriteOptions {
+	var options TupleWriteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 // A TupleBackend provides a read/write interface for managing tuples.
 type TupleBackend interface {
 	RelationshipTupleReader
@@ -179,6 +296,17 @@ type RelationshipTupleReader interface {
 		options ReadUserTupleOptions,
 	) (*openfgav1.Tuple, error)
 
+	// ReadTuplesBatch returns the tuples matching any of the lookups, reading them all at once instead of one
+	// lookup at a time, e.g. with a single query. A tuple matched by several lookups is returned once, and lookups
+	// without a match aren't an error: unlike ReadUserTuple, it doesn't return [ErrNotFound].
+	// There is NO guarantee on the order of the tuples returned.
+	ReadTuplesBatch(
+		ctx context.Context,
+		store string,
+		lookups []TupleLookup,
+		options ReadTuplesBatchOptions,
+	) ([]*openfgav1.Tuple, error)
+
 	// ReadUsersetTuples returns all userset tuples for a specified object and relation.
 	// For example, given the following relationship tuples:
 	//	document:doc1, viewer, user:*
@@ -223,8 +351,13 @@ type RelationshipTupleWriter interface {
 	// `deletes` before adding new values in `writes`, returning the time of the transaction, or an error.
 	// If there are more than MaxTuplesPerWrite, it must return ErrExceededWriteBatchLimit.
 	// If two requests attempt to write the same tuple at the same time, it must return ErrTransactionalWriteFailed.
-	// If the tuple to be written already existed or the tuple to be deleted didn't exist, it must return ErrInvalidWriteInput.
-	Write(ctx context.Context, store string, d Deletes, w Writes) error
+	// If the tuple to be written already existed or the tuple to be deleted didn't exist, it must return ErrInvalidWriteInput,
+	// unless the TupleWriteOptions say to ignore them.
+	// If a precondition of the TupleWriteOptions doesn't hold before the write, it must return ErrPreconditionFailed.
+	// The preconditions are checked, and the deletes and writes applied, atomically.
+	// Tuples that have expired are treated as if they didn't exist: writing one replaces it, recording its delete
+	// in the changelog, and the read methods must not return them.
+	Write(ctx context.Context, store string, d Deletes, w Writes, opts ...TupleWriteOption) error
 
 	// MaxTuplesPerWrite returns the maximum number of items (writes and deletes combined)
 	// allowed in a single write transaction.
@@ -254,6 +387,26 @@ type ReadUsersetTuplesFilter struct {
 	AllowedUserTypeRestrictions []*openfgav1.RelationReference // Optional.
 }
 
+// TupleLookup is a lookup of ReadTuplesBatch. With a User, it matches the tuple with the object, relation and user,
+// as ReadUserTuple. Without one, it matches the tuples of the object and relation whose user is a userset or a
+// wildcard, as ReadUsersetTuples without AllowedUserTypeRestrictions.
+type TupleLookup struct {
+	Object   string // Required.
+	Relation string // Required.
+	User     string // Optional.
+}
+
+// Matches reports whether the tuple key is matched by the lookup.
+func (l TupleLookup) Matches(tk *openfgav1.TupleKey) bool {
+	if tk.GetObject() != l.Object || tk.GetRelation() != l.Relation {
+		return false
+	}
+	if l.User == "" {
+		return tuple.GetUserTypeFromUser(tk.GetUser()) == tuple.UserSet
+	}
+	return tk.GetUser() == l.User
+}
+
 // AuthorizationModelReadBackend provides a read interface for managing type definitions.
 type AuthorizationModelReadBackend interface {
 	// ReadAuthorizationModel reads the model corresponding to store and model ID.
@@ -319,6 +472,164 @@ type ChangelogBackend interface {
 	ReadChanges(ctx context.Context, store string, filter ReadChangesFilter, options ReadChangesOptions) ([]*openfgav1.TupleChange, []byte, error)
 }
 
+// ChangelogWatcher is implemented by the datastores that can signal when changes are committed to the changelog
+// of a store, so that its readers don't have to poll ReadChanges.
+type ChangelogWatcher interface {
+	// WatchChangelog returns a channel that receives a value after changes have been committed to the changelog
+	// of the store. Notifications may be coalesced, or spurious, so the changes must then be read with
+	// ReadChanges. The channel is closed once ctx is done, or if the datastore can no longer notify changes.
+	WatchChangelog(ctx context.Context, store string) (<-chan struct{}, error)
+}
+
+// TupleImporter is implemented by the datastores that can write large batches of tuples more efficiently than
+// Write, e.g. with COPY or multi-row INSERT statements.
+type TupleImporter interface {
+	// ImportTuples writes the tuples, and their changes, in a single transaction. It is not bound by
+	// MaxTuplesPerWrite, but by MaxTuplesPerImport. If a tuple already exists, it must return
+	// ErrInvalidWriteInput and write none of them.
+	ImportTuples(ctx context.Context, store string, writes Writes) error
+
+	// MaxTuplesPerImport returns the maximum number of tuples allowed in a single ImportTuples call.
+	MaxTuplesPerImport() int
+}
+
+// TupleReaper is implemented by the datastores that can delete the tuples which have expired (see WithExpiresAt).
+type TupleReaper interface {
+	// DeleteExpiredTuples deletes, across all stores, up to limit tuples which expired at or before now, recording
+	// their deletes in the changelog. It returns how many were deleted, which is less than limit once there are
+	// none left.
+	DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// ChangelogPruner is implemented by the datastores that can delete changes from their changelog, to bound its size.
+// Once changes of a store are deleted, ReadChanges returns ErrContinuationTokenExpired for the continuation tokens
+// pointing before the last of them.
+type ChangelogPruner interface {
+	// PruneChanges deletes up to limit of the oldest changes of the store which were made before olderThan, or
+	// which aren't among its keepCount newest changes. A zero olderThan or keepCount doesn't bound the changes.
+	// It returns how many were deleted, which is less than limit once there are none left.
+	PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error)
+
+	// CompactChanges deletes up to limit pairs of changes of the store, made before olderThan, where a tuple is
+	// written then deleted (see CollapsibleChanges). It returns how many pairs were deleted, which is less than
+	// limit once there are none left.
+	CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error)
+}
+
+// StoreUndeleter is implemented by the datastores which keep the data of the deleted stores, so that they can be
+// restored.
+type StoreUndeleter interface {
+	// UndeleteStore restores the store, if it was deleted at or after deletedAfter, and returns it. A zero
+	// deletedAfter restores it however long ago it was deleted. It returns the store unchanged if it isn't deleted,
+	// and ErrNotFound if it doesn't exist or was deleted before deletedAfter.
+	UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error)
+}
+
+// StorePurger is implemented by the datastores which can permanently delete the data of the deleted stores.
+type StorePurger interface {
+	// ListDeletedStores returns the IDs of up to limit stores deleted before deletedBefore, which aren't purged yet.
+	ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error)
+
+	// PurgeStore permanently deletes up to limit rows of the data of the store (tuples, changes, authorization
+	// models and assertions), then the store itself once none are left, if it was deleted before deletedBefore.
+	// It returns how many rows were deleted, which is less than limit once the store is purged, or if it
+	// wasn't deleted before deletedBefore.
+	PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error)
+}
+
+// StoreLabeler is implemented by the datastores which can label the stores with key/value pairs, and update them.
+// The labels are validated by ValidateStoreLabels beforehand.
+type StoreLabeler interface {
+	// CreateStoreWithLabels is CreateStore, also writing the labels of the store atomically.
+	CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error)
+
+	// UpdateStore renames the store, unless name is empty, and replaces its labels, unless labels is nil, then
+	// returns it. It returns ErrNotFound if the store doesn't exist or is deleted.
+	UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error)
+
+	// ReadStoreLabels returns the labels of the store, which are empty if it has none. It returns ErrNotFound if
+	// the store doesn't exist or is deleted.
+	ReadStoreLabels(ctx context.Context, id string) (map[string]string, error)
+}
+
+// TupleCounter is implemented by the datastores which keep count of the tuples of each store as they are written and
+// deleted, rather than counting them when asked.
+type TupleCounter interface {
+	// CountTuples returns the number of tuples of the store, including those which expired but weren't deleted yet.
+	// It is zero if the store has none or doesn't exist.
+	CountTuples(ctx context.Context, store string) (int64, error)
+}
+
+// EventOutbox is implemented by the datastores which, when configured to, record an [Event] of each change of the
+// tuples, authorization models and stores in an outbox, atomically with the change itself. The events stay in the
+// outbox until they are deleted, once delivered.
+type EventOutbox interface {
+	// ReadOutbox returns up to limit events of the outbox, oldest first.
+	ReadOutbox(ctx context.Context, limit int) ([]*Event, error)
+
+	// DeleteOutboxEvents deletes the events with the IDs from the outbox. The IDs which aren't in it are ignored.
+	DeleteOutboxEvents(ctx context.Context, ids []string) error
+}
+
+// RecordImport is a batch of tuples and changes written as they are stored by [RecordCopier].ImportRecords.
+type RecordImport struct {
+	// Changes are added to the changelog of the store, except those it has already, identified by their ULID.
+	Changes []*ChangeRecord
+	// Deletes are the tuples deleted from the store. The tuples it doesn't have are ignored.
+	Deletes Deletes
+	// Tuples are written to the store, replacing the tuples with the same object, relation and user.
+	Tuples []*TupleRecord
+}
+
+// RecordCopier is implemented by the datastores whose tuples and changelog can be read and written as they are
+// stored, keeping their ULIDs, timestamps and expiry, so that a store can be copied to another datastore as it is.
+type RecordCopier interface {
+	// ReadTupleRecords returns a page of the tuples of the store, including those which expired but weren't deleted
+	// yet, along with the continuation token of the next page, which is empty if there is none. The tuples are
+	// ordered by object type, object ID, relation and user (see CompareTupleRecords), so that the tuples of two
+	// datastores can be compared as they are read, though the collation of the datastore may order some of them
+	// differently.
+	ReadTupleRecords(ctx context.Context, store string, options PaginationOptions) ([]*TupleRecord, []byte, error)
+
+	// ReadTupleRecord returns the tuple of the store with the object, relation and user of the key, even if it
+	// expired. It returns ErrNotFound if there is none.
+	ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*TupleRecord, error)
+
+	// ReadChangeRecords returns up to limit changes of the changelog of the store, oldest first, starting after the
+	// change with the ULID after, or from the first one if it is empty.
+	ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*ChangeRecord, error)
+
+	// ImportRecords applies the import to the store in a single transaction. Unlike Write, it records no change of
+	// its own, nor any event in the outbox (see EventOutbox).
+	ImportRecords(ctx context.Context, store string, records RecordImport) error
+}
+
+// DatastoreWrapper is implemented by the datastores which wrap another one. They may implement every optional
+// interface, such as TupleImporter, whether the datastore they wrap does or not, so the optional interfaces are
+// looked up with As.
+type DatastoreWrapper interface {
+	// Unwrap returns the datastore wrapped.
+	Unwrap() OpenFGADatastore
+}
+
+// As returns the datastore as the optional interface T, if it implements it, and so do the datastores it wraps (see
+// DatastoreWrapper).
+func As[T any](datastore OpenFGADatastore) (T, bool) {
+	var zero T
+	t, ok := datastore.(T)
+	if !ok {
+		return zero, false
+	}
+
+	for wrapper, ok := datastore.(DatastoreWrapper); ok; wrapper, ok = datastore.(DatastoreWrapper) {
+		datastore = wrapper.Unwrap()
+		if _, ok := datastore.(T); !ok {
+			return zero, false
+		}
+	}
+	return t, true
+}
+
 // OpenFGADatastore is an interface that defines a set of methods for interacting
 // with and managing data in an OpenFGA (Fine-Grained Authorization) system.
 type OpenFGADatastore interface {
@@ -0,0 +1,228 @@
+package storagewrappers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+var _ storage.RelationshipTupleReader = (*BatchingTupleReader)(nil)
+
+var (
+	tupleBatchSizeHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "datastore_tuple_batch_size",
+		Help:                            "The number of ReadUserTuple and ReadUsersetTuples lookups read at once from the datastore by a batch",
+		Buckets:                         []float64{1, 2, 5, 10, 25, 50, 100, 250, 500},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"grpc_service", "grpc_method"})
+)
+
+// BatchingTupleReader is a wrapper for a datastore that batches the point lookups of the concurrent calls to
+// ReadUserTuple and ReadUsersetTuples, in the manner of a dataloader: the lookups are collected for up to a window,
+// or until there are enough of them to fill a batch, then read at once with ReadTuplesBatch, and the tuples read are
+// fanned back out to the callers. The other reads are passed through.
+//
+// Every lookup waits for up to the window before being read, so the reader pays off when many lookups are issued
+// concurrently, as when resolving a Check on a wide model. It should not be shared across multiple requests.
+type BatchingTupleReader struct {
+	storage.RelationshipTupleReader
+	window       time.Duration
+	maxBatchSize int
+
+	mu      sync.Mutex
+	pending map[tupleBatchKey]*tupleBatch // GUARDED_BY(mu)
+}
+
+// tupleBatchKey identifies the lookups that can be read in the same batch.
+type tupleBatchKey struct {
+	store       string
+	consistency openfgav1.ConsistencyPreference
+}
+
+// tupleBatch is a batch of lookups, read once it is full or its window has elapsed.
+type tupleBatch struct {
+	key tupleBatchKey
+	// ctx is the context of the first lookup, without its cancellation, since the batch is read for all the
+	// lookups.
+	ctx     context.Context
+	lookups []storage.TupleLookup
+	seen    map[storage.TupleLookup]struct{}
+	timer   *time.Timer
+
+	// done is closed once the batch has been read, into tuples or err.
+	done   chan struct{}
+	tuples []*openfgav1.Tuple
+	err    error
+}
+
+// NewBatchingTupleReader creates a new [BatchingTupleReader] wrapping the datastore, which reads the lookups once
+// the window has elapsed since the first of a batch, or once there are maxBatchSize of them. Both must be positive.
+func NewBatchingTupleReader(wrapped storage.RelationshipTupleReader, window time.Duration, maxBatchSize int) *BatchingTupleReader {
+	return &BatchingTupleReader{
+		RelationshipTupleReader: wrapped,
+		window:                  window,
+		maxBatchSize:            maxBatchSize,
+		pending:                 make(map[tupleBatchKey]*tupleBatch),
+	}
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (b *BatchingTupleReader) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	lookup := storage.TupleLookup{
+		Object:   tupleKey.GetObject(),
+		Relation: tupleKey.GetRelation(),
+		User:     tupleKey.GetUser(),
+	}
+
+	tuples, err := b.lookup(ctx, store, options.Consistency, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tuples {
+		if lookup.Matches(t.GetKey()) {
+			return t, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples. The filters without an object ID or
+// relation aren't point lookups, and are passed through.
+func (b *BatchingTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	if _, objectID := tupleUtils.SplitObject(filter.Object); objectID == "" || filter.Relation == "" {
+		return b.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter, options)
+	}
+
+	lookup := storage.TupleLookup{
+		Object:   filter.Object,
+		Relation: filter.Relation,
+	}
+
+	tuples, err := b.lookup(ctx, store, options.Consistency, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*openfgav1.Tuple
+	for _, t := range tuples {
+		if lookup.Matches(t.GetKey()) && allowedUserset(t.GetKey().GetUser(), filter.AllowedUserTypeRestrictions) {
+			matches = append(matches, t)
+		}
+	}
+	return storage.NewStaticTupleIterator(matches), nil
+}
+
+// allowedUserset reports whether the userset or wildcard is of one of the allowed user types, which are all allowed
+// if there are none (1.0 models).
+func allowedUserset(user string, allowedUserTypeRestrictions []*openfgav1.RelationReference) bool {
+	if len(allowedUserTypeRestrictions) == 0 {
+		return true
+	}
+
+	userType := tupleUtils.GetType(user)
+	_, userRelation := tupleUtils.SplitObjectRelation(user)
+	for _, allowedType := range allowedUserTypeRestrictions {
+		switch allowedType.GetRelationOrWildcard().(type) {
+		case *openfgav1.RelationReference_Relation:
+			if allowedType.GetType() == userType && allowedType.GetRelation() == userRelation {
+				return true
+			}
+		case *openfgav1.RelationReference_Wildcard:
+			if user == tupleUtils.TypedPublicWildcard(allowedType.GetType()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup adds the lookup to the pending batch of the store and consistency, and returns the tuples read by the batch
+// once it is, which may be matched by the other lookups of the batch too.
+func (b *BatchingTupleReader) lookup(ctx context.Context, store string, consistency storage.ConsistencyOptions, lookup storage.TupleLookup) ([]*openfgav1.Tuple, error) {
+	batch, full := b.add(ctx, tupleBatchKey{store: store, consistency: consistency.Preference}, lookup)
+	if full {
+		b.read(batch)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-batch.done:
+		return batch.tuples, batch.err
+	}
+}
+
+// add adds the lookup to the pending batch of the key, starting a new one if there is none, and reports whether the
+// batch is full, in which case it is no longer pending and must be read by the caller.
+func (b *BatchingTupleReader) add(ctx context.Context, key tupleBatchKey, lookup storage.TupleLookup) (*tupleBatch, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.pending[key]
+	if !ok {
+		batch = &tupleBatch{
+			key:  key,
+			ctx:  context.WithoutCancel(ctx),
+			seen: make(map[storage.TupleLookup]struct{}),
+			done: make(chan struct{}),
+		}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.window, func() {
+			b.flush(batch)
+		})
+	}
+
+	if _, ok := batch.seen[lookup]; !ok {
+		batch.seen[lookup] = struct{}{}
+		batch.lookups = append(batch.lookups, lookup)
+	}
+
+	if len(batch.lookups) < b.maxBatchSize {
+		return batch, false
+	}
+
+	delete(b.pending, key)
+	batch.timer.Stop()
+	return batch, true
+}
+
+// flush reads the batch once its window has elapsed, unless it was already read because it was full.
+func (b *BatchingTupleReader) flush(batch *tupleBatch) {
+	b.mu.Lock()
+	if b.pending[batch.key] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, batch.key)
+	b.mu.Unlock()
+
+	b.read(batch)
+}
+
+// read reads the tuples of the lookups of the batch, which must no longer be pending, and wakes up their callers.
+func (b *BatchingTupleReader) read(batch *tupleBatch) {
+	defer close(batch.done)
+
+	rpcInfo := telemetry.RPCInfoFromContext(batch.ctx)
+	tupleBatchSizeHistogram.WithLabelValues(
+		rpcInfo.Service,
+		rpcInfo.Method,
+	).Observe(float64(len(batch.lookups)))
+
+	batch.tuples, batch.err = b.RelationshipTupleReader.ReadTuplesBatch(batch.ctx, batch.key.store, batch.lookups, storage.ReadTuplesBatchOptions{
+		Consistency: storage.ConsistencyOptions{Preference: batch.key.consistency},
+	})
+}
@@ -0,0 +1,173 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestBatchingTupleReader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("doc:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("doc:1", "viewer", "user:*"),
+		tuple.NewTupleKey("doc:2", "viewer", "user:bob"),
+	})
+	require.NoError(t, err)
+
+	t.Run("concurrent_lookups_are_read_in_one_batch", func(t *testing.T) {
+		instrumented := NewInstrumentedOpenFGAStorage(ds)
+		reader := NewBatchingTupleReader(instrumented, 10*time.Millisecond, 100)
+
+		var wg errgroup.Group
+		wg.Go(func() error {
+			tp, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+			if err != nil {
+				return err
+			}
+			require.Equal(t, "user:anne", tp.GetKey().GetUser())
+			return nil
+		})
+		wg.Go(func() error {
+			tp, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:2", "viewer", "user:bob"), storage.ReadUserTupleOptions{})
+			if err != nil {
+				return err
+			}
+			require.Equal(t, "doc:2", tp.GetKey().GetObject())
+			return nil
+		})
+		wg.Go(func() error {
+			_, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:2", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+			if !errors.Is(err, storage.ErrNotFound) {
+				return errors.New("expected ErrNotFound")
+			}
+			return nil
+		})
+		wg.Go(func() error {
+			iter, err := reader.ReadUsersetTuples(ctx, store, storage.ReadUsersetTuplesFilter{
+				Object:   "doc:1",
+				Relation: "viewer",
+				AllowedUserTypeRestrictions: []*openfgav1.RelationReference{
+					typesystem.DirectRelationReference("group", "member"),
+				},
+			}, storage.ReadUsersetTuplesOptions{})
+			if err != nil {
+				return err
+			}
+			defer iter.Stop()
+
+			tp, err := iter.Next(ctx)
+			if err != nil {
+				return err
+			}
+			require.Equal(t, "group:eng#member", tp.GetKey().GetUser())
+			_, err = iter.Next(ctx)
+			require.ErrorIs(t, err, storage.ErrIteratorDone)
+			return nil
+		})
+		require.NoError(t, wg.Wait())
+
+		require.Equal(t, uint32(1), instrumented.GetMetrics().DatastoreQueryCount)
+	})
+
+	t.Run("full_batches_are_read_without_waiting_for_the_window", func(t *testing.T) {
+		reader := NewBatchingTupleReader(ds, time.Hour, 1)
+
+		tp, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:anne", tp.GetKey().GetUser())
+
+		iter, err := reader.ReadUsersetTuples(ctx, store, storage.ReadUsersetTuplesFilter{
+			Object:   "doc:1",
+			Relation: "viewer",
+			AllowedUserTypeRestrictions: []*openfgav1.RelationReference{
+				typesystem.WildcardRelationReference("user"),
+			},
+		}, storage.ReadUsersetTuplesOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+
+		tp, err = iter.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "user:*", tp.GetKey().GetUser())
+	})
+
+	t.Run("lookups_of_different_consistencies_are_read_in_different_batches", func(t *testing.T) {
+		instrumented := NewInstrumentedOpenFGAStorage(ds)
+		reader := NewBatchingTupleReader(instrumented, time.Millisecond, 100)
+
+		var wg errgroup.Group
+		for _, preference := range []openfgav1.ConsistencyPreference{
+			openfgav1.ConsistencyPreference_MINIMIZE_LATENCY,
+			openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+		} {
+			wg.Go(func() error {
+				_, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{
+					Consistency: storage.ConsistencyOptions{Preference: preference},
+				})
+				return err
+			})
+		}
+		require.NoError(t, wg.Wait())
+
+		require.Equal(t, uint32(2), instrumented.GetMetrics().DatastoreQueryCount)
+	})
+
+	t.Run("errors_are_returned_to_all_the_lookups", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mocks.NewMockRelationshipTupleReader(mockController)
+		mockDatastore.EXPECT().
+			ReadTuplesBatch(gomock.Any(), store, gomock.Len(2), gomock.Any()).
+			Return(nil, errors.New("boom"))
+
+		reader := NewBatchingTupleReader(mockDatastore, time.Hour, 2)
+
+		var wg errgroup.Group
+		for _, user := range []string{"user:anne", "user:bob"} {
+			wg.Go(func() error {
+				_, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:1", "viewer", user), storage.ReadUserTupleOptions{})
+				require.EqualError(t, err, "boom")
+				return nil
+			})
+		}
+		require.NoError(t, wg.Wait())
+	})
+
+	t.Run("cancelled_lookups_stop_waiting", func(t *testing.T) {
+		reader := NewBatchingTupleReader(ds, 50*time.Millisecond, 100)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+
+		_, err := reader.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// let the batch be read before checking for leaks
+		time.Sleep(100 * time.Millisecond)
+	})
+}
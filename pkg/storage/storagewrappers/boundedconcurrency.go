@@ -79,6 +79,25 @@ func (b *BoundedConcurrencyTupleReader) Read(ctx context.Context, store string,
 	return b.RelationshipTupleReader.Read(ctx, store, tupleKey, options)
 }
 
+// ReadTuplesBatch returns the tuples matching any of the lookups.
+func (b *BoundedConcurrencyTupleReader) ReadTuplesBatch(
+	ctx context.Context,
+	store string,
+	lookups []storage.TupleLookup,
+	options storage.ReadTuplesBatchOptions,
+) ([]*openfgav1.Tuple, error) {
+	err := b.waitForLimiter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		<-b.limiter
+	}()
+
+	return b.RelationshipTupleReader.ReadTuplesBatch(ctx, store, lookups, options)
+}
+
 // ReadUsersetTuples returns all userset tuples for a specified object and relation.
 func (b *BoundedConcurrencyTupleReader) ReadUsersetTuples(
 	ctx context.Context,
@@ -90,6 +90,46 @@ func (c *CombinedTupleReader) ReadUserTuple(
 	return c.RelationshipTupleReader.ReadUserTuple(ctx, store, tk, options)
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader.ReadTuplesBatch]. As with ReadUserTuple, the lookups of a user
+// matched by a contextual tuple aren't read from the datastore.
+func (c *CombinedTupleReader) ReadTuplesBatch(
+	ctx context.Context,
+	store string,
+	lookups []storage.TupleLookup,
+	options storage.ReadTuplesBatchOptions,
+) ([]*openfgav1.Tuple, error) {
+	var tuples []*openfgav1.Tuple
+	var remaining []storage.TupleLookup
+	matchedContextualTuples := make(map[int]struct{})
+	for _, lookup := range lookups {
+		matched := false
+		for i, tk := range c.contextualTuples {
+			if !lookup.Matches(tk) {
+				continue
+			}
+			matched = true
+			if _, ok := matchedContextualTuples[i]; !ok {
+				matchedContextualTuples[i] = struct{}{}
+				tuples = append(tuples, &openfgav1.Tuple{Key: tk})
+			}
+		}
+		if !matched || lookup.User == "" {
+			remaining = append(remaining, lookup)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return tuples, nil
+	}
+
+	persisted, err := c.RelationshipTupleReader.ReadTuplesBatch(ctx, store, remaining, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tuples, persisted...), nil
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader.ReadUsersetTuples].
 func (c *CombinedTupleReader) ReadUsersetTuples(
 	ctx context.Context,
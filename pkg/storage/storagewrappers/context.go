@@ -58,6 +58,13 @@ func (c *ContextTracerWrapper) ReadUserTuple(ctx context.Context, store string,
 	return c.OpenFGADatastore.ReadUserTuple(queryCtx, store, tupleKey, options)
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (c *ContextTracerWrapper) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	queryCtx := queryContext(ctx)
+
+	return c.OpenFGADatastore.ReadTuplesBatch(queryCtx, store, lookups, options)
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
 func (c *ContextTracerWrapper) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
 	queryCtx := queryContext(ctx)
@@ -61,6 +61,13 @@ func (m *InstrumentedOpenFGAStorage) ReadUserTuple(ctx context.Context, store st
 	return m.RelationshipTupleReader.ReadUserTuple(ctx, store, tupleKey, options)
 }
 
+// ReadTuplesBatch see [storage.RelationshipTupleReader.ReadTuplesBatch].
+func (m *InstrumentedOpenFGAStorage) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	m.increaseReads()
+
+	return m.RelationshipTupleReader.ReadTuplesBatch(ctx, store, lookups, options)
+}
+
 // ReadUsersetTuples see [storage.RelationshipTupleReader.ReadUsersetTuples].
 func (m *InstrumentedOpenFGAStorage) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
 	m.increaseReads()
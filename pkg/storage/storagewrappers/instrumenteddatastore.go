@@ -0,0 +1,652 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+var (
+	_ storage.OpenFGADatastore = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.DatastoreWrapper = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.ChangelogWatcher = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.TupleImporter    = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.TupleReaper      = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.ChangelogPruner  = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.StoreUndeleter   = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.StorePurger      = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.StoreLabeler     = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.TupleCounter     = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.EventOutbox      = (*InstrumentedOpenFGADatastore)(nil)
+	_ storage.RecordCopier     = (*InstrumentedOpenFGADatastore)(nil)
+)
+
+// otherStoresLabel is the store label of the stores beyond the limit of an [InstrumentedOpenFGADatastore].
+const otherStoresLabel = "other"
+
+var (
+	datastoreMethodDurationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "datastore_method_duration_ms",
+		Help:                            "The duration of the calls to the methods of the datastore, the iterators returned being timed until they are returned",
+		Buckets:                         []float64{1, 3, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"engine", "method", "store"})
+
+	datastoreMethodErrorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "datastore_method_error_count",
+		Help:      "The number of errors returned by the methods of the datastore, and by the iterators they return, by class of error",
+	}, []string{"engine", "method", "store", "error"})
+
+	datastoreMethodRowsHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "datastore_method_rows",
+		Help:                            "The number of tuples, changes, authorization models, assertions or stores returned by the read methods of the datastore, those of the iterators being counted once they are stopped",
+		Buckets:                         []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"engine", "method", "store"})
+
+	datastoreIteratorLifetimeHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "datastore_iterator_lifetime_ms",
+		Help:                            "The time between the return of the iterators of the datastore and their stop",
+		Buckets:                         []float64{1, 3, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"engine", "method", "store"})
+)
+
+// errorClasses are the classes of the errors of the datastore, by which the errors are counted. The errors which
+// match none are counted as 'other'.
+var errorClasses = []struct {
+	err   error
+	class string
+}{
+	{storage.ErrNotFound, "not_found"},
+	{storage.ErrCollision, "collision"},
+	{storage.ErrInvalidContinuationToken, "invalid_continuation_token"},
+	{storage.ErrContinuationTokenExpired, "continuation_token_expired"},
+	{storage.ErrInvalidStartTime, "invalid_start_time"},
+	{storage.ErrMismatchObjectType, "mismatch_object_type"},
+	{storage.ErrInvalidWriteInput, "invalid_write_input"},
+	{storage.ErrPreconditionFailed, "precondition_failed"},
+	{storage.ErrTransactionalWriteFailed, "transactional_write_failed"},
+	{storage.ErrExceededWriteBatchLimit, "exceeded_write_batch_limit"},
+	{storage.ErrRevisionUnavailable, "revision_unavailable"},
+	{errors.ErrUnsupported, "unsupported"},
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+}
+
+// errorClass returns the class of the error of the datastore.
+func errorClass(err error) string {
+	for _, c := range errorClasses {
+		if errors.Is(err, c.err) {
+			return c.class
+		}
+	}
+	return "other"
+}
+
+// InstrumentedOpenFGADatastore is a wrapper for a datastore that exports, for every method, its latency, the errors
+// it returns by class, the rows it returns, and the lifetime of the iterators it returns. The metrics are labeled by
+// the engine of the datastore and the method, and by the store if enabled with [WithStoreLabelLimit].
+//
+// Unlike [InstrumentedOpenFGAStorage], which counts the reads of a request, it is shared by all the requests. It
+// implements the optional interfaces of the datastores, such as [storage.TupleImporter], and instruments them too, so
+// they must be looked up with [storage.As]. Their methods return errors.ErrUnsupported if the datastore wrapped
+// doesn't implement them.
+type InstrumentedOpenFGADatastore struct {
+	storage.OpenFGADatastore
+	engine string
+
+	storeLabelLimit int
+	mu              sync.RWMutex
+	labeledStores   map[string]struct{} // GUARDED_BY(mu)
+}
+
+// InstrumentedOpenFGADatastoreOption is an option of an [InstrumentedOpenFGADatastore].
+type InstrumentedOpenFGADatastoreOption func(*InstrumentedOpenFGADatastore)
+
+// WithStoreLabelLimit labels the metrics by store, for up to limit stores, the first ones called with, so that the
+// cardinality of the metrics stays bounded. The calls of the other stores are labeled 'other'. If the limit isn't
+// positive, which is the default, the metrics aren't labeled by store.
+func WithStoreLabelLimit(limit int) InstrumentedOpenFGADatastoreOption {
+	return func(d *InstrumentedOpenFGADatastore) {
+		d.storeLabelLimit = limit
+	}
+}
+
+// NewInstrumentedOpenFGADatastore creates a new [InstrumentedOpenFGADatastore] wrapping the datastore of the engine.
+func NewInstrumentedOpenFGADatastore(wrapped storage.OpenFGADatastore, engine string, opts ...InstrumentedOpenFGADatastoreOption) *InstrumentedOpenFGADatastore {
+	d := &InstrumentedOpenFGADatastore{
+		OpenFGADatastore: wrapped,
+		engine:           engine,
+		labeledStores:    make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// storeLabel returns the label of the store, which is empty if the metrics aren't labeled by store.
+func (d *InstrumentedOpenFGADatastore) storeLabel(store string) string {
+	if d.storeLabelLimit <= 0 || store == "" {
+		return ""
+	}
+
+	d.mu.RLock()
+	_, ok := d.labeledStores[store]
+	d.mu.RUnlock()
+	if ok {
+		return store
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.labeledStores[store]; ok {
+		return store
+	}
+	if len(d.labeledStores) >= d.storeLabelLimit {
+		return otherStoresLabel
+	}
+	d.labeledStores[store] = struct{}{}
+	return store
+}
+
+// datastoreCall is a call to a method of the datastore, whose metrics are recorded once it returns.
+type datastoreCall struct {
+	engine string
+	method string
+	store  string
+	start  time.Time
+}
+
+func (d *InstrumentedOpenFGADatastore) start(method, store string) *datastoreCall {
+	return &datastoreCall{engine: d.engine, method: method, store: d.storeLabel(store), start: time.Now()}
+}
+
+// done records the duration of the call, and its error, if any.
+func (c *datastoreCall) done(err error) {
+	datastoreMethodDurationHistogram.WithLabelValues(c.engine, c.method, c.store).Observe(float64(time.Since(c.start).Milliseconds()))
+	if err != nil {
+		c.error(err)
+	}
+}
+
+// doneWithRows records the duration of the call, and its error, or the number of rows it returned.
+func (c *datastoreCall) doneWithRows(rows int, err error) {
+	c.done(err)
+	if err == nil {
+		c.rows(rows)
+	}
+}
+
+func (c *datastoreCall) error(err error) {
+	datastoreMethodErrorCounter.WithLabelValues(c.engine, c.method, c.store, errorClass(err)).Inc()
+}
+
+func (c *datastoreCall) rows(rows int) {
+	datastoreMethodRowsHistogram.WithLabelValues(c.engine, c.method, c.store).Observe(float64(rows))
+}
+
+// doneWithIterator records the duration of the call, and its error, or returns the iterator instrumented.
+func (c *datastoreCall) doneWithIterator(iter storage.TupleIterator, err error) (storage.TupleIterator, error) {
+	c.done(err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTupleIterator{TupleIterator: iter, call: c, returned: time.Now()}, nil
+}
+
+// instrumentedTupleIterator is an iterator of the datastore, which records its errors, and, once stopped, its
+// lifetime and the number of tuples it returned.
+type instrumentedTupleIterator struct {
+	storage.TupleIterator
+	call     *datastoreCall
+	returned time.Time
+	rows     int
+	stopOnce sync.Once
+}
+
+// Next see [storage.Iterator.Next].
+func (i *instrumentedTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	t, err := i.TupleIterator.Next(ctx)
+	switch {
+	case err == nil:
+		i.rows++
+	case !errors.Is(err, storage.ErrIteratorDone):
+		i.call.error(err)
+	}
+	return t, err
+}
+
+// Stop see [storage.Iterator.Stop].
+func (i *instrumentedTupleIterator) Stop() {
+	i.stopOnce.Do(func() {
+		datastoreIteratorLifetimeHistogram.WithLabelValues(i.call.engine, i.call.method, i.call.store).Observe(float64(time.Since(i.returned).Milliseconds()))
+		i.call.rows(i.rows)
+	})
+	i.TupleIterator.Stop()
+}
+
+// Read see [storage.RelationshipTupleReader.Read].
+func (d *InstrumentedOpenFGADatastore) Read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadOptions) (storage.TupleIterator, error) {
+	c := d.start("Read", store)
+	return c.doneWithIterator(d.OpenFGADatastore.Read(ctx, store, tupleKey, options))
+}
+
+// ReadPage see [storage.RelationshipTupleReader.ReadPage].
+func (d *InstrumentedOpenFGADatastore) ReadPage(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadPageOptions) ([]*openfgav1.Tuple, []byte, error) {
+	c := d.start("ReadPage", store)
+	tuples, token, err := d.OpenFGADatastore.ReadPage(ctx, store, tupleKey, options)
+	c.doneWithRows(len(tuples), err)
+	return tuples, token, err
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader.ReadUserTuple].
+func (d *InstrumentedOpenFGADatastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	c := d.start("ReadUserTuple", store)
+	tuple, err := d.OpenFGADatastore.ReadUserTuple(ctx, store, tupleKey, options)
+	c.doneWithRows(1, err)
+	return tuple, err
+}
+
+// ReadTuplesBatch see [storage.RelationshipTupleReader.ReadTuplesBatch].
+func (d *InstrumentedOpenFGADatastore) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	c := d.start("ReadTuplesBatch", store)
+	tuples, err := d.OpenFGADatastore.ReadTuplesBatch(ctx, store, lookups, options)
+	c.doneWithRows(len(tuples), err)
+	return tuples, err
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader.ReadUsersetTuples].
+func (d *InstrumentedOpenFGADatastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	c := d.start("ReadUsersetTuples", store)
+	return c.doneWithIterator(d.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options))
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader.ReadStartingWithUser].
+func (d *InstrumentedOpenFGADatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	c := d.start("ReadStartingWithUser", store)
+	return c.doneWithIterator(d.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options))
+}
+
+// Write see [storage.RelationshipTupleWriter.Write].
+func (d *InstrumentedOpenFGADatastore) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes, opts ...storage.TupleWriteOption) error {
+	c := d.start("Write", store)
+	err := d.OpenFGADatastore.Write(ctx, store, deletes, writes, opts...)
+	c.done(err)
+	return err
+}
+
+// ReadAuthorizationModel see [storage.AuthorizationModelReadBackend.ReadAuthorizationModel].
+func (d *InstrumentedOpenFGADatastore) ReadAuthorizationModel(ctx context.Context, store string, id string) (*openfgav1.AuthorizationModel, error) {
+	c := d.start("ReadAuthorizationModel", store)
+	model, err := d.OpenFGADatastore.ReadAuthorizationModel(ctx, store, id)
+	c.doneWithRows(1, err)
+	return model, err
+}
+
+// ReadAuthorizationModels see [storage.AuthorizationModelReadBackend.ReadAuthorizationModels].
+func (d *InstrumentedOpenFGADatastore) ReadAuthorizationModels(ctx context.Context, store string, options storage.ReadAuthorizationModelsOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
+	c := d.start("ReadAuthorizationModels", store)
+	models, token, err := d.OpenFGADatastore.ReadAuthorizationModels(ctx, store, options)
+	c.doneWithRows(len(models), err)
+	return models, token, err
+}
+
+// FindLatestAuthorizationModel see [storage.AuthorizationModelReadBackend.FindLatestAuthorizationModel].
+func (d *InstrumentedOpenFGADatastore) FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error) {
+	c := d.start("FindLatestAuthorizationModel", store)
+	model, err := d.OpenFGADatastore.FindLatestAuthorizationModel(ctx, store)
+	c.doneWithRows(1, err)
+	return model, err
+}
+
+// WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend.WriteAuthorizationModel].
+func (d *InstrumentedOpenFGADatastore) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
+	c := d.start("WriteAuthorizationModel", store)
+	err := d.OpenFGADatastore.WriteAuthorizationModel(ctx, store, model)
+	c.done(err)
+	return err
+}
+
+// CreateStore see [storage.StoresBackend.CreateStore].
+func (d *InstrumentedOpenFGADatastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
+	c := d.start("CreateStore", store.GetId())
+	created, err := d.OpenFGADatastore.CreateStore(ctx, store)
+	c.done(err)
+	return created, err
+}
+
+// DeleteStore see [storage.StoresBackend.DeleteStore].
+func (d *InstrumentedOpenFGADatastore) DeleteStore(ctx context.Context, id string) error {
+	c := d.start("DeleteStore", id)
+	err := d.OpenFGADatastore.DeleteStore(ctx, id)
+	c.done(err)
+	return err
+}
+
+// GetStore see [storage.StoresBackend.GetStore].
+func (d *InstrumentedOpenFGADatastore) GetStore(ctx context.Context, id string) (*openfgav1.Store, error) {
+	c := d.start("GetStore", id)
+	store, err := d.OpenFGADatastore.GetStore(ctx, id)
+	c.doneWithRows(1, err)
+	return store, err
+}
+
+// ListStores see [storage.StoresBackend.ListStores].
+func (d *InstrumentedOpenFGADatastore) ListStores(ctx context.Context, options storage.ListStoresOptions) ([]*openfgav1.Store, []byte, error) {
+	c := d.start("ListStores", "")
+	stores, token, err := d.OpenFGADatastore.ListStores(ctx, options)
+	c.doneWithRows(len(stores), err)
+	return stores, token, err
+}
+
+// WriteAssertions see [storage.AssertionsBackend.WriteAssertions].
+func (d *InstrumentedOpenFGADatastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
+	c := d.start("WriteAssertions", store)
+	err := d.OpenFGADatastore.WriteAssertions(ctx, store, modelID, assertions)
+	c.done(err)
+	return err
+}
+
+// ReadAssertions see [storage.AssertionsBackend.ReadAssertions].
+func (d *InstrumentedOpenFGADatastore) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error) {
+	c := d.start("ReadAssertions", store)
+	assertions, err := d.OpenFGADatastore.ReadAssertions(ctx, store, modelID)
+	c.doneWithRows(len(assertions), err)
+	return assertions, err
+}
+
+// ReadChanges see [storage.ChangelogBackend.ReadChanges].
+func (d *InstrumentedOpenFGADatastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, []byte, error) {
+	c := d.start("ReadChanges", store)
+	changes, token, err := d.OpenFGADatastore.ReadChanges(ctx, store, filter, options)
+	c.doneWithRows(len(changes), err)
+	return changes, token, err
+}
+
+// IsReady see [storage.OpenFGADatastore.IsReady].
+func (d *InstrumentedOpenFGADatastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
+	c := d.start("IsReady", "")
+	status, err := d.OpenFGADatastore.IsReady(ctx)
+	c.done(err)
+	return status, err
+}
+
+// Unwrap see [storage.DatastoreWrapper.Unwrap].
+func (d *InstrumentedOpenFGADatastore) Unwrap() storage.OpenFGADatastore {
+	return d.OpenFGADatastore
+}
+
+// optionalInterface returns the datastore wrapped as the optional interface T, or errors.ErrUnsupported if it doesn't
+// implement it.
+func optionalInterface[T any](d *InstrumentedOpenFGADatastore, method string) (T, error) {
+	t, ok := d.OpenFGADatastore.(T)
+	if !ok {
+		return t, fmt.Errorf("%s: %w", method, errors.ErrUnsupported)
+	}
+	return t, nil
+}
+
+// WatchChangelog see [storage.ChangelogWatcher.WatchChangelog].
+func (d *InstrumentedOpenFGADatastore) WatchChangelog(ctx context.Context, store string) (<-chan struct{}, error) {
+	c := d.start("WatchChangelog", store)
+	watcher, err := optionalInterface[storage.ChangelogWatcher](d, "WatchChangelog")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	notifications, err := watcher.WatchChangelog(ctx, store)
+	c.done(err)
+	return notifications, err
+}
+
+// ImportTuples see [storage.TupleImporter.ImportTuples].
+func (d *InstrumentedOpenFGADatastore) ImportTuples(ctx context.Context, store string, writes storage.Writes) error {
+	c := d.start("ImportTuples", store)
+	importer, err := optionalInterface[storage.TupleImporter](d, "ImportTuples")
+	if err != nil {
+		c.done(err)
+		return err
+	}
+	err = importer.ImportTuples(ctx, store, writes)
+	c.done(err)
+	return err
+}
+
+// MaxTuplesPerImport see [storage.TupleImporter.MaxTuplesPerImport]. It is zero if the datastore wrapped isn't a
+// [storage.TupleImporter].
+func (d *InstrumentedOpenFGADatastore) MaxTuplesPerImport() int {
+	importer, err := optionalInterface[storage.TupleImporter](d, "MaxTuplesPerImport")
+	if err != nil {
+		return 0
+	}
+	return importer.MaxTuplesPerImport()
+}
+
+// DeleteExpiredTuples see [storage.TupleReaper.DeleteExpiredTuples].
+func (d *InstrumentedOpenFGADatastore) DeleteExpiredTuples(ctx context.Context, now time.Time, limit int) (int, error) {
+	c := d.start("DeleteExpiredTuples", "")
+	reaper, err := optionalInterface[storage.TupleReaper](d, "DeleteExpiredTuples")
+	if err != nil {
+		c.done(err)
+		return 0, err
+	}
+	deleted, err := reaper.DeleteExpiredTuples(ctx, now, limit)
+	c.done(err)
+	return deleted, err
+}
+
+// PruneChanges see [storage.ChangelogPruner.PruneChanges].
+func (d *InstrumentedOpenFGADatastore) PruneChanges(ctx context.Context, store string, olderThan time.Time, keepCount int, limit int) (int, error) {
+	c := d.start("PruneChanges", store)
+	pruner, err := optionalInterface[storage.ChangelogPruner](d, "PruneChanges")
+	if err != nil {
+		c.done(err)
+		return 0, err
+	}
+	deleted, err := pruner.PruneChanges(ctx, store, olderThan, keepCount, limit)
+	c.done(err)
+	return deleted, err
+}
+
+// CompactChanges see [storage.ChangelogPruner.CompactChanges].
+func (d *InstrumentedOpenFGADatastore) CompactChanges(ctx context.Context, store string, olderThan time.Time, limit int) (int, error) {
+	c := d.start("CompactChanges", store)
+	pruner, err := optionalInterface[storage.ChangelogPruner](d, "CompactChanges")
+	if err != nil {
+		c.done(err)
+		return 0, err
+	}
+	deleted, err := pruner.CompactChanges(ctx, store, olderThan, limit)
+	c.done(err)
+	return deleted, err
+}
+
+// UndeleteStore see [storage.StoreUndeleter.UndeleteStore].
+func (d *InstrumentedOpenFGADatastore) UndeleteStore(ctx context.Context, id string, deletedAfter time.Time) (*openfgav1.Store, error) {
+	c := d.start("UndeleteStore", id)
+	undeleter, err := optionalInterface[storage.StoreUndeleter](d, "UndeleteStore")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	store, err := undeleter.UndeleteStore(ctx, id, deletedAfter)
+	c.done(err)
+	return store, err
+}
+
+// ListDeletedStores see [storage.StorePurger.ListDeletedStores].
+func (d *InstrumentedOpenFGADatastore) ListDeletedStores(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	c := d.start("ListDeletedStores", "")
+	purger, err := optionalInterface[storage.StorePurger](d, "ListDeletedStores")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	ids, err := purger.ListDeletedStores(ctx, deletedBefore, limit)
+	c.doneWithRows(len(ids), err)
+	return ids, err
+}
+
+// PurgeStore see [storage.StorePurger.PurgeStore].
+func (d *InstrumentedOpenFGADatastore) PurgeStore(ctx context.Context, id string, deletedBefore time.Time, limit int) (int, error) {
+	c := d.start("PurgeStore", id)
+	purger, err := optionalInterface[storage.StorePurger](d, "PurgeStore")
+	if err != nil {
+		c.done(err)
+		return 0, err
+	}
+	deleted, err := purger.PurgeStore(ctx, id, deletedBefore, limit)
+	c.done(err)
+	return deleted, err
+}
+
+// CreateStoreWithLabels see [storage.StoreLabeler.CreateStoreWithLabels].
+func (d *InstrumentedOpenFGADatastore) CreateStoreWithLabels(ctx context.Context, store *openfgav1.Store, labels map[string]string) (*openfgav1.Store, error) {
+	c := d.start("CreateStoreWithLabels", store.GetId())
+	labeler, err := optionalInterface[storage.StoreLabeler](d, "CreateStoreWithLabels")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	created, err := labeler.CreateStoreWithLabels(ctx, store, labels)
+	c.done(err)
+	return created, err
+}
+
+// UpdateStore see [storage.StoreLabeler.UpdateStore].
+func (d *InstrumentedOpenFGADatastore) UpdateStore(ctx context.Context, id string, name string, labels map[string]string) (*openfgav1.Store, error) {
+	c := d.start("UpdateStore", id)
+	labeler, err := optionalInterface[storage.StoreLabeler](d, "UpdateStore")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	store, err := labeler.UpdateStore(ctx, id, name, labels)
+	c.done(err)
+	return store, err
+}
+
+// ReadStoreLabels see [storage.StoreLabeler.ReadStoreLabels].
+func (d *InstrumentedOpenFGADatastore) ReadStoreLabels(ctx context.Context, id string) (map[string]string, error) {
+	c := d.start("ReadStoreLabels", id)
+	labeler, err := optionalInterface[storage.StoreLabeler](d, "ReadStoreLabels")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	labels, err := labeler.ReadStoreLabels(ctx, id)
+	c.doneWithRows(len(labels), err)
+	return labels, err
+}
+
+// CountTuples see [storage.TupleCounter.CountTuples].
+func (d *InstrumentedOpenFGADatastore) CountTuples(ctx context.Context, store string) (int64, error) {
+	c := d.start("CountTuples", store)
+	counter, err := optionalInterface[storage.TupleCounter](d, "CountTuples")
+	if err != nil {
+		c.done(err)
+		return 0, err
+	}
+	count, err := counter.CountTuples(ctx, store)
+	c.done(err)
+	return count, err
+}
+
+// ReadTupleRecords see [storage.RecordCopier.ReadTupleRecords].
+func (d *InstrumentedOpenFGADatastore) ReadTupleRecords(ctx context.Context, store string, options storage.PaginationOptions) ([]*storage.TupleRecord, []byte, error) {
+	c := d.start("ReadTupleRecords", store)
+	copier, err := optionalInterface[storage.RecordCopier](d, "ReadTupleRecords")
+	if err != nil {
+		c.done(err)
+		return nil, nil, err
+	}
+	records, token, err := copier.ReadTupleRecords(ctx, store, options)
+	c.done(err)
+	return records, token, err
+}
+
+// ReadTupleRecord see [storage.RecordCopier.ReadTupleRecord].
+func (d *InstrumentedOpenFGADatastore) ReadTupleRecord(ctx context.Context, store string, key *openfgav1.TupleKey) (*storage.TupleRecord, error) {
+	c := d.start("ReadTupleRecord", store)
+	copier, err := optionalInterface[storage.RecordCopier](d, "ReadTupleRecord")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	record, err := copier.ReadTupleRecord(ctx, store, key)
+	c.done(err)
+	return record, err
+}
+
+// ReadChangeRecords see [storage.RecordCopier.ReadChangeRecords].
+func (d *InstrumentedOpenFGADatastore) ReadChangeRecords(ctx context.Context, store string, after string, limit int) ([]*storage.ChangeRecord, error) {
+	c := d.start("ReadChangeRecords", store)
+	copier, err := optionalInterface[storage.RecordCopier](d, "ReadChangeRecords")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	records, err := copier.ReadChangeRecords(ctx, store, after, limit)
+	c.done(err)
+	return records, err
+}
+
+// ImportRecords see [storage.RecordCopier.ImportRecords].
+func (d *InstrumentedOpenFGADatastore) ImportRecords(ctx context.Context, store string, records storage.RecordImport) error {
+	c := d.start("ImportRecords", store)
+	copier, err := optionalInterface[storage.RecordCopier](d, "ImportRecords")
+	if err != nil {
+		c.done(err)
+		return err
+	}
+	err = copier.ImportRecords(ctx, store, records)
+	c.done(err)
+	return err
+}
+
+// ReadOutbox see [storage.EventOutbox.ReadOutbox].
+func (d *InstrumentedOpenFGADatastore) ReadOutbox(ctx context.Context, limit int) ([]*storage.Event, error) {
+	c := d.start("ReadOutbox", "")
+	outbox, err := optionalInterface[storage.EventOutbox](d, "ReadOutbox")
+	if err != nil {
+		c.done(err)
+		return nil, err
+	}
+	events, err := outbox.ReadOutbox(ctx, limit)
+	c.doneWithRows(len(events), err)
+	return events, err
+}
+
+// DeleteOutboxEvents see [storage.EventOutbox.DeleteOutboxEvents].
+func (d *InstrumentedOpenFGADatastore) DeleteOutboxEvents(ctx context.Context, ids []string) error {
+	c := d.start("DeleteOutboxEvents", "")
+	outbox, err := optionalInterface[storage.EventOutbox](d, "DeleteOutboxEvents")
+	if err != nil {
+		c.done(err)
+		return err
+	}
+	err = outbox.DeleteOutboxEvents(ctx, ids)
+	c.done(err)
+	return err
+}
@@ -0,0 +1,161 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// histogramSamples returns the number and the sum of the samples of the histogram with the labels.
+func histogramSamples(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) (uint64, float64) {
+	var m dto.Metric
+	require.NoError(t, histogram.WithLabelValues(labels...).(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestInstrumentedOpenFGADatastore(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	store := ulid.Make().String()
+	otherStore := ulid.Make().String()
+	// the metrics are global, so they are labeled with an engine of the test only
+	engine := "instrumented_test"
+
+	ds := NewInstrumentedOpenFGADatastore(memory.New(), engine, WithStoreLabelLimit(1))
+	t.Cleanup(ds.Close)
+
+	err := ds.Write(ctx, store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("doc:2", "viewer", "user:bob"),
+	})
+	require.NoError(t, err)
+
+	t.Run("records_the_duration_of_the_calls", func(t *testing.T) {
+		count, _ := histogramSamples(t, datastoreMethodDurationHistogram, engine, "Write", store)
+		require.Equal(t, uint64(1), count)
+	})
+
+	t.Run("records_the_rows_and_lifetime_of_the_iterators", func(t *testing.T) {
+		iter, err := ds.Read(ctx, store, tuple.NewTupleKey("doc:", "viewer", ""), storage.ReadOptions{})
+		require.NoError(t, err)
+		for {
+			_, err := iter.Next(ctx)
+			if errors.Is(err, storage.ErrIteratorDone) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		iter.Stop()
+		iter.Stop()
+
+		count, sum := histogramSamples(t, datastoreMethodRowsHistogram, engine, "Read", store)
+		require.Equal(t, uint64(1), count)
+		require.InDelta(t, 2, sum, 0)
+
+		count, _ = histogramSamples(t, datastoreIteratorLifetimeHistogram, engine, "Read", store)
+		require.Equal(t, uint64(1), count)
+	})
+
+	t.Run("records_the_rows_of_the_reads", func(t *testing.T) {
+		tuples, _, err := ds.ReadPage(ctx, store, nil, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(1, ""),
+		})
+		require.NoError(t, err)
+		require.Len(t, tuples, 1)
+
+		count, sum := histogramSamples(t, datastoreMethodRowsHistogram, engine, "ReadPage", store)
+		require.Equal(t, uint64(1), count)
+		require.InDelta(t, 1, sum, 0)
+	})
+
+	t.Run("counts_the_errors_by_class", func(t *testing.T) {
+		_, err := ds.ReadUserTuple(ctx, store, tuple.NewTupleKey("doc:3", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		err = ds.Write(ctx, store, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:1", "viewer", "user:anne")})
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+
+		require.InDelta(t, 1, testutil.ToFloat64(datastoreMethodErrorCounter.WithLabelValues(engine, "ReadUserTuple", store, "not_found")), 0)
+		require.InDelta(t, 1, testutil.ToFloat64(datastoreMethodErrorCounter.WithLabelValues(engine, "Write", store, "invalid_write_input")), 0)
+
+		// the rows of the failed calls aren't recorded
+		count, _ := histogramSamples(t, datastoreMethodRowsHistogram, engine, "ReadUserTuple", store)
+		require.Equal(t, uint64(0), count)
+	})
+
+	t.Run("bounds_the_cardinality_of_the_store_label", func(t *testing.T) {
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user`)
+		require.NoError(t, ds.WriteAuthorizationModel(ctx, otherStore, model))
+
+		count, _ := histogramSamples(t, datastoreMethodDurationHistogram, engine, "WriteAuthorizationModel", otherStoresLabel)
+		require.Equal(t, uint64(1), count)
+
+		_, _, err := ds.ListStores(ctx, storage.ListStoresOptions{Pagination: storage.NewPaginationOptions(10, "")})
+		require.NoError(t, err)
+		count, _ = histogramSamples(t, datastoreMethodDurationHistogram, engine, "ListStores", "")
+		require.Equal(t, uint64(1), count)
+	})
+
+	t.Run("instruments_the_optional_interfaces", func(t *testing.T) {
+		importer, ok := storage.As[storage.TupleImporter](ds)
+		require.True(t, ok)
+		require.NoError(t, importer.ImportTuples(ctx, store, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:3", "viewer", "user:anne")}))
+
+		count, _ := histogramSamples(t, datastoreMethodDurationHistogram, engine, "ImportTuples", store)
+		require.Equal(t, uint64(1), count)
+
+		counter, ok := storage.As[storage.TupleCounter](ds)
+		require.True(t, ok)
+		tuples, err := counter.CountTuples(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), tuples)
+	})
+
+	t.Run("hides_the_optional_interfaces_of_the_datastore_wrapped", func(t *testing.T) {
+		// the datastore wrapped only implements storage.OpenFGADatastore
+		wrapped := NewInstrumentedOpenFGADatastore(struct{ storage.OpenFGADatastore }{ds.OpenFGADatastore}, "hidden_instrumented_test")
+
+		_, ok := storage.As[storage.TupleImporter](wrapped)
+		require.False(t, ok)
+
+		err := wrapped.ImportTuples(ctx, store, nil)
+		require.ErrorIs(t, err, errors.ErrUnsupported)
+		require.InDelta(t, 1, testutil.ToFloat64(datastoreMethodErrorCounter.WithLabelValues("hidden_instrumented_test", "ImportTuples", "", "unsupported")), 0)
+	})
+
+	t.Run("without_store_label", func(t *testing.T) {
+		unlabeled := NewInstrumentedOpenFGADatastore(ds.OpenFGADatastore, "unlabeled_instrumented_test")
+		_, err := unlabeled.FindLatestAuthorizationModel(ctx, otherStore)
+		require.NoError(t, err)
+
+		count, sum := histogramSamples(t, datastoreMethodRowsHistogram, "unlabeled_instrumented_test", "FindLatestAuthorizationModel", "")
+		require.Equal(t, uint64(1), count)
+		require.InDelta(t, 1, sum, 0)
+	})
+}
+
+func TestErrorClass(t *testing.T) {
+	require.Equal(t, "transactional_write_failed", errorClass(storage.ErrTransactionalWriteFailed))
+	require.Equal(t, "not_found", errorClass(errors.Join(errors.New("read store"), storage.ErrNotFound)))
+	require.Equal(t, "deadline_exceeded", errorClass(context.DeadlineExceeded))
+	require.Equal(t, "other", errorClass(errors.New("connection refused")))
+}
@@ -0,0 +1,143 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var _ storage.RelationshipTupleReader = (*SharedIteratorTupleReader)(nil)
+
+// SharedIteratorTupleReader is a wrapper over a RelationshipTupleReader that deduplicates identical reads
+// that are in flight at the same time. The first caller performs the read and drains the resulting iterator;
+// every concurrent caller with the same query receives its own static iterator over the same results.
+//
+// It must only be used for the lifetime of a single request (e.g. a BatchCheck), since results are not
+// invalidated by writes.
+type SharedIteratorTupleReader struct {
+	storage.RelationshipTupleReader
+	sf *singleflight.Group
+}
+
+// NewSharedIteratorTupleReader returns a wrapper over a datastore that shares the results of concurrent
+// identical calls to Read, ReadUserTuple, ReadUsersetTuples and ReadStartingWithUser.
+func NewSharedIteratorTupleReader(wrapped storage.RelationshipTupleReader) *SharedIteratorTupleReader {
+	return &SharedIteratorTupleReader{
+		RelationshipTupleReader: wrapped,
+		sf:                      &singleflight.Group{},
+	}
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (s *SharedIteratorTupleReader) Read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadOptions) (storage.TupleIterator, error) {
+	key := fmt.Sprintf("r/%s/%s/%s", store, tuple.TupleKeyToString(tupleKey), options.Consistency.Preference)
+
+	return s.shared(ctx, key, func(ctx context.Context) (storage.TupleIterator, error) {
+		return s.RelationshipTupleReader.Read(ctx, store, tupleKey, options)
+	})
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (s *SharedIteratorTupleReader) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	key := fmt.Sprintf("ru/%s/%s/%s", store, tuple.TupleKeyToString(tupleKey), options.Consistency.Preference)
+
+	read := func() (interface{}, error) {
+		return s.RelationshipTupleReader.ReadUserTuple(ctx, store, tupleKey, options)
+	}
+
+	res, err, shared := s.sf.Do(key, read)
+	if err != nil {
+		if !shouldRetryShared(ctx, err, shared) {
+			return nil, err
+		}
+
+		res, err = read()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res.(*openfgav1.Tuple), nil
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (s *SharedIteratorTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("rut/%s/%s#%s/%s", store, filter.Object, filter.Relation, options.Consistency.Preference))
+	for _, userset := range filter.AllowedUserTypeRestrictions {
+		if userset.GetWildcard() != nil {
+			b.WriteString(fmt.Sprintf("/%s:*", userset.GetType()))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("/%s#%s", userset.GetType(), userset.GetRelation()))
+	}
+
+	return s.shared(ctx, b.String(), func(ctx context.Context) (storage.TupleIterator, error) {
+		return s.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter, options)
+	})
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (s *SharedIteratorTupleReader) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("rswu/%s/%s#%s/%s", store, filter.ObjectType, filter.Relation, options.Consistency.Preference))
+	for _, objectRel := range filter.UserFilter {
+		b.WriteString("/" + tuple.ToObjectRelationString(objectRel.GetObject(), objectRel.GetRelation()))
+	}
+	if filter.ObjectIDs != nil {
+		b.WriteString("/" + strings.Join(filter.ObjectIDs.Values(), ","))
+	}
+
+	return s.shared(ctx, b.String(), func(ctx context.Context) (storage.TupleIterator, error) {
+		return s.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter, options)
+	})
+}
+
+func (s *SharedIteratorTupleReader) shared(ctx context.Context, key string, iterFunc func(context.Context) (storage.TupleIterator, error)) (storage.TupleIterator, error) {
+	read := func() (interface{}, error) {
+		iter, err := iterFunc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Stop()
+
+		var tuples []*openfgav1.Tuple
+		for {
+			t, err := iter.Next(ctx)
+			if err != nil {
+				if errors.Is(err, storage.ErrIteratorDone) {
+					return tuples, nil
+				}
+				return nil, err
+			}
+			tuples = append(tuples, t)
+		}
+	}
+
+	res, err, shared := s.sf.Do(key, read)
+	if err != nil {
+		if !shouldRetryShared(ctx, err, shared) {
+			return nil, err
+		}
+
+		res, err = read()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return storage.NewStaticTupleIterator(res.([]*openfgav1.Tuple)), nil
+}
+
+// shouldRetryShared returns true if the error of a shared read was caused by the cancellation of the caller
+// that performed it, rather than by the caller receiving it.
+func shouldRetryShared(ctx context.Context, err error, shared bool) bool {
+	return shared && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+}
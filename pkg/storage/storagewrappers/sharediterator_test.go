@@ -0,0 +1,148 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestSharedIteratorTupleReader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	store := ulid.Make().String()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.Write(context.Background(), store, []*openfgav1.TupleKeyWithoutCondition{}, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("doc:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("doc:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("doc:2", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	t.Run("concurrent_identical_reads_are_shared", func(t *testing.T) {
+		instrumented := NewInstrumentedOpenFGAStorage(mocks.NewMockSlowDataStorage(ds, 500*time.Millisecond))
+		sharedReader := NewSharedIteratorTupleReader(instrumented)
+
+		const numRoutine = 5
+		var wg errgroup.Group
+		for i := 0; i < numRoutine; i++ {
+			wg.Go(func() error {
+				iter, err := sharedReader.Read(context.Background(), store, tuple.NewTupleKey("doc:1", "viewer", ""), storage.ReadOptions{})
+				if err != nil {
+					return err
+				}
+				defer iter.Stop()
+
+				tuples, err := storage.NewTupleKeyIteratorFromTupleIterator(iter).Next(context.Background())
+				if err != nil {
+					return err
+				}
+				require.Equal(t, "doc:1", tuples.GetObject())
+				return nil
+			})
+		}
+		require.NoError(t, wg.Wait())
+		require.Equal(t, uint32(1), instrumented.GetMetrics().DatastoreQueryCount)
+	})
+
+	t.Run("different_reads_are_not_shared", func(t *testing.T) {
+		instrumented := NewInstrumentedOpenFGAStorage(ds)
+		sharedReader := NewSharedIteratorTupleReader(instrumented)
+
+		tk, err := sharedReader.ReadUserTuple(context.Background(), store, tuple.NewTupleKey("doc:1", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "user:anne", tk.GetKey().GetUser())
+
+		iter, err := sharedReader.ReadUsersetTuples(context.Background(), store, storage.ReadUsersetTuplesFilter{
+			Object:   "doc:1",
+			Relation: "viewer",
+		}, storage.ReadUsersetTuplesOptions{})
+		require.NoError(t, err)
+		t.Cleanup(iter.Stop)
+
+		usersets, err := storage.NewTupleKeyIteratorFromTupleIterator(iter).Next(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "group:eng#member", usersets.GetUser())
+
+		iter, err = sharedReader.ReadStartingWithUser(context.Background(), store, storage.ReadStartingWithUserFilter{
+			ObjectType: "doc",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:anne"}},
+		}, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		t.Cleanup(iter.Stop)
+
+		var objects []string
+		for {
+			tk, err := iter.Next(context.Background())
+			if err != nil {
+				require.ErrorIs(t, err, storage.ErrIteratorDone)
+				break
+			}
+			objects = append(objects, tk.GetKey().GetObject())
+		}
+		require.ElementsMatch(t, []string{"doc:1", "doc:2"}, objects)
+		require.Equal(t, uint32(3), instrumented.GetMetrics().DatastoreQueryCount)
+	})
+
+	t.Run("canceled_leader_does_not_fail_followers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		mockReader := mocks.NewMockRelationshipTupleReader(ctrl)
+
+		leaderCtx, cancel := context.WithCancel(context.Background())
+		followerStarted := make(chan struct{})
+
+		gomock.InOrder(
+			mockReader.EXPECT().Read(gomock.Any(), store, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ string, _ *openfgav1.TupleKey, _ storage.ReadOptions) (storage.TupleIterator, error) {
+					<-followerStarted
+					cancel()
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}),
+			mockReader.EXPECT().Read(gomock.Any(), store, gomock.Any(), gomock.Any()).
+				Return(storage.NewStaticTupleIterator(nil), nil),
+		)
+
+		sharedReader := NewSharedIteratorTupleReader(mockReader)
+
+		var wg errgroup.Group
+		wg.Go(func() error {
+			_, err := sharedReader.Read(leaderCtx, store, tuple.NewTupleKey("doc:2", "viewer", ""), storage.ReadOptions{})
+			require.ErrorIs(t, err, context.Canceled)
+			return nil
+		})
+
+		// give the leader time to start the shared read.
+		time.Sleep(50 * time.Millisecond)
+		wg.Go(func() error {
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				close(followerStarted)
+			}()
+			iter, err := sharedReader.Read(context.Background(), store, tuple.NewTupleKey("doc:2", "viewer", ""), storage.ReadOptions{})
+			if err != nil {
+				return err
+			}
+			iter.Stop()
+			return nil
+		})
+
+		require.NoError(t, wg.Wait())
+	})
+}
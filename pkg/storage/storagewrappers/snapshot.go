@@ -0,0 +1,547 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// snapshotChangesPageSize is the number of changes read at once from the changelog to revert them.
+	snapshotChangesPageSize = 1000
+
+	// snapshotRefreshLookback is how long before the last change read the changelog is read again when a snapshot
+	// is brought up to date, so that the changes committed after others with a later ULID are reverted too.
+	snapshotRefreshLookback = time.Second
+)
+
+// SnapshotTupleReader is a wrapper for a datastore that reads the stores exactly as they were at the revision of the
+// request, if it is an exact one (see [storage.ContextWithRevision]). The changes committed after the revision are
+// reverted from the results of the reads using the changelog. They are read once per store by the first read of the
+// request, and the following reads only read the changes committed since. The other reads are passed through.
+type SnapshotTupleReader struct {
+	storage.OpenFGADatastore
+	tokenSerializer encoder.ContinuationTokenSerializer
+}
+
+var _ storage.OpenFGADatastore = (*SnapshotTupleReader)(nil)
+
+// NewSnapshotTupleReader creates a new [SnapshotTupleReader] wrapping the datastore. The token serializer must be the
+// one of the datastore's ReadChanges.
+func NewSnapshotTupleReader(inner storage.OpenFGADatastore, tokenSerializer encoder.ContinuationTokenSerializer) *SnapshotTupleReader {
+	return &SnapshotTupleReader{
+		OpenFGADatastore: inner,
+		tokenSerializer:  tokenSerializer,
+	}
+}
+
+// snapshot holds how a store differs at a revision from its latest state. It is never modified once read.
+type snapshot struct {
+	// written holds the keys of the tuples written since the revision, which didn't exist at it.
+	written map[string]struct{}
+	// deleted holds the tuples deleted since the revision, which existed at it, by key.
+	deleted map[string]*openfgav1.Tuple
+}
+
+// changed reports whether the tuple was written or deleted since the revision, in which case its latest state isn't
+// the one at the revision.
+func (s *snapshot) changed(t *openfgav1.Tuple) bool {
+	key := tupleUtils.TupleKeyToString(t.GetKey())
+	_, written := s.written[key]
+	_, deleted := s.deleted[key]
+	return written || deleted
+}
+
+// snapshotKey is the key of the snapshots memoized for the revision of a request (see [storage.RevisionMemo]).
+type snapshotKey struct {
+	store    string
+	revision ulid.ULID
+}
+
+// snapshotState is the latest snapshot of a store at a revision read by the reads of a request.
+type snapshotState struct {
+	mu       sync.Mutex
+	snapshot *snapshot
+	// last is the ULID of the last change read from the changelog, if any.
+	last ulid.ULID
+}
+
+// exactRevision returns the exact revision of the request, if any.
+func exactRevision(ctx context.Context) (ulid.ULID, bool) {
+	revision, ok := storage.RevisionFromContext(ctx)
+	if !ok || revision.Mode != storage.RevisionExact {
+		return ulid.ULID{}, false
+	}
+	return revision.ULID, true
+}
+
+// Read see [storage.RelationshipTupleReader].Read.
+func (s *SnapshotTupleReader) Read(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadOptions) (storage.TupleIterator, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.Read(ctx, store, tupleKey, options)
+	}
+
+	iter, err := s.OpenFGADatastore.Read(ctx, store, tupleKey, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revertIterator(ctx, store, revision, iter, func(t *openfgav1.Tuple) bool {
+		return matchTupleKey(tupleKey, t.GetKey())
+	})
+}
+
+// ReadPage see [storage.RelationshipTupleReader].ReadPage. The tuples deleted since the revision are returned along
+// with the last page.
+func (s *SnapshotTupleReader) ReadPage(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadPageOptions) ([]*openfgav1.Tuple, []byte, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.ReadPage(ctx, store, tupleKey, options)
+	}
+
+	tuples, continuationToken, err := s.OpenFGADatastore.ReadPage(ctx, store, tupleKey, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snap, err := s.snapshot(ctx, store, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// as in ReadUserTuple, the tuples deleted since the revision are returned as they were at it, even if written
+	// again since
+	reverted := make([]*openfgav1.Tuple, 0, len(tuples))
+	for _, t := range tuples {
+		if snap.changed(t) {
+			continue
+		}
+		reverted = append(reverted, t)
+	}
+
+	if len(continuationToken) == 0 {
+		for _, t := range snap.deleted {
+			if matchTupleKey(tupleKey, t.GetKey()) {
+				reverted = append(reverted, t)
+			}
+		}
+	}
+
+	return reverted, continuationToken, nil
+}
+
+// ReadUserTuple see [storage.RelationshipTupleReader].ReadUserTuple.
+func (s *SnapshotTupleReader) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgav1.TupleKey, options storage.ReadUserTupleOptions) (*openfgav1.Tuple, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.ReadUserTuple(ctx, store, tupleKey, options)
+	}
+
+	t, err := s.OpenFGADatastore.ReadUserTuple(ctx, store, tupleKey, options)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	snap, err := s.snapshot(ctx, store, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	key := tupleUtils.TupleKeyToString(tupleKey)
+	if deleted, ok := snap.deleted[key]; ok {
+		return deleted, nil
+	}
+	if _, ok := snap.written[key]; ok || t == nil {
+		return nil, storage.ErrNotFound
+	}
+	return t, nil
+}
+
+// ReadTuplesBatch see [storage.RelationshipTupleReader].ReadTuplesBatch.
+func (s *SnapshotTupleReader) ReadTuplesBatch(ctx context.Context, store string, lookups []storage.TupleLookup, options storage.ReadTuplesBatchOptions) ([]*openfgav1.Tuple, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.ReadTuplesBatch(ctx, store, lookups, options)
+	}
+
+	tuples, err := s.OpenFGADatastore.ReadTuplesBatch(ctx, store, lookups, options)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := s.snapshot(ctx, store, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// as in ReadUserTuple, the tuples deleted since the revision are returned as they were at it, even if written
+	// again since
+	reverted := make([]*openfgav1.Tuple, 0, len(tuples))
+	for _, t := range tuples {
+		if !snap.changed(t) {
+			reverted = append(reverted, t)
+		}
+	}
+
+	for _, t := range snap.deleted {
+		for _, lookup := range lookups {
+			if lookup.Matches(t.GetKey()) {
+				reverted = append(reverted, t)
+				break
+			}
+		}
+	}
+
+	return reverted, nil
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader].ReadUsersetTuples.
+func (s *SnapshotTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter, options storage.ReadUsersetTuplesOptions) (storage.TupleIterator, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options)
+	}
+
+	iter, err := s.OpenFGADatastore.ReadUsersetTuples(ctx, store, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revertIterator(ctx, store, revision, iter, func(t *openfgav1.Tuple) bool {
+		tk := t.GetKey()
+		if tk.GetObject() != filter.Object || tk.GetRelation() != filter.Relation ||
+			tupleUtils.GetUserTypeFromUser(tk.GetUser()) != tupleUtils.UserSet {
+			return false
+		}
+		if len(filter.AllowedUserTypeRestrictions) == 0 {
+			return true
+		}
+
+		userType := tupleUtils.GetType(tk.GetUser())
+		_, userRelation := tupleUtils.SplitObjectRelation(tk.GetUser())
+		for _, allowedType := range filter.AllowedUserTypeRestrictions {
+			if allowedType.GetType() == userType && allowedType.GetRelation() == userRelation {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader].ReadStartingWithUser.
+func (s *SnapshotTupleReader) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	revision, ok := exactRevision(ctx)
+	if !ok {
+		return s.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options)
+	}
+
+	iter, err := s.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revertIterator(ctx, store, revision, iter, func(t *openfgav1.Tuple) bool {
+		tk := t.GetKey()
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		if objectType != filter.ObjectType || tk.GetRelation() != filter.Relation {
+			return false
+		}
+		if filter.ObjectIDs != nil && !filter.ObjectIDs.Exists(objectID) {
+			return false
+		}
+
+		for _, userFilter := range filter.UserFilter {
+			targetUser := userFilter.GetObject()
+			if userFilter.GetRelation() != "" {
+				targetUser = tupleUtils.GetObjectRelationAsString(userFilter)
+			}
+			if targetUser == tk.GetUser() {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// revertIterator returns an iterator over the tuples of iter which weren't changed since the revision, followed by
+// the tuples deleted since the revision which match the filter of the read.
+func (s *SnapshotTupleReader) revertIterator(
+	ctx context.Context,
+	store string,
+	revision ulid.ULID,
+	iter storage.TupleIterator,
+	match func(t *openfgav1.Tuple) bool,
+) (storage.TupleIterator, error) {
+	// the changelog is read once the read is started, so that the changes committed in between are reverted
+	snap, err := s.snapshot(ctx, store, revision)
+	if err != nil {
+		iter.Stop()
+		return nil, err
+	}
+
+	return &snapshotIterator{
+		iter:     iter,
+		snapshot: snap,
+		match:    match,
+	}, nil
+}
+
+// snapshot returns how the store differs at the revision from its latest state. The snapshot is memoized for the
+// revision of the request, and brought up to date with the changes committed since it was last read.
+func (s *SnapshotTupleReader) snapshot(ctx context.Context, store string, revision ulid.ULID) (*snapshot, error) {
+	state, ok := storage.RevisionMemo(ctx, snapshotKey{store: store, revision: revision}, func() any {
+		return &snapshotState{}
+	}).(*snapshotState)
+	if !ok {
+		state = &snapshotState{}
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	from := revision
+	if state.snapshot != nil && state.last.Time() > revision.Time()+uint64(snapshotRefreshLookback.Milliseconds()) {
+		_ = from.SetTime(state.last.Time() - uint64(snapshotRefreshLookback.Milliseconds()))
+		from.SetEntropy(make([]byte, 10))
+	}
+
+	// only the first change of a tuple since the revision tells whether it existed at it
+	var written []string
+	var deleted []string
+	changed := func(key string) bool {
+		if state.snapshot == nil {
+			return false
+		}
+		_, isWritten := state.snapshot.written[key]
+		_, isDeleted := state.snapshot.deleted[key]
+		return isWritten || isDeleted
+	}
+	seen := make(map[string]struct{})
+	last, err := s.readChanges(ctx, store, from, false, func(change *openfgav1.TupleChange) bool {
+		key := tupleUtils.TupleKeyToString(change.GetTupleKey())
+		if _, ok := seen[key]; ok || changed(key) {
+			return true
+		}
+		seen[key] = struct{}{}
+
+		if change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_WRITE {
+			written = append(written, key)
+		} else {
+			deleted = append(deleted, key)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if last.Compare(state.last) > 0 {
+		state.last = last
+	}
+
+	if state.snapshot != nil && len(written) == 0 && len(deleted) == 0 {
+		return state.snapshot, nil
+	}
+
+	// the snapshots handed out are never modified, so the changes read are added to a copy
+	snap := &snapshot{
+		written: make(map[string]struct{}),
+		deleted: make(map[string]*openfgav1.Tuple),
+	}
+	if state.snapshot != nil {
+		maps.Copy(snap.written, state.snapshot.written)
+		maps.Copy(snap.deleted, state.snapshot.deleted)
+	}
+	for _, key := range written {
+		snap.written[key] = struct{}{}
+	}
+
+	if len(deleted) > 0 {
+		missing := make(map[string]struct{}, len(deleted))
+		for _, key := range deleted {
+			missing[key] = struct{}{}
+		}
+
+		// the deletes don't hold the conditions of the tuples, so they are taken from their last writes at the
+		// revision, which is the ULID of a change itself
+		_, err = s.readChanges(ctx, store, nextULID(revision), true, func(change *openfgav1.TupleChange) bool {
+			key := tupleUtils.TupleKeyToString(change.GetTupleKey())
+			if _, ok := missing[key]; ok && change.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_WRITE {
+				snap.deleted[key] = &openfgav1.Tuple{Key: change.GetTupleKey(), Timestamp: change.GetTimestamp()}
+				delete(missing, key)
+			}
+			return len(missing) > 0
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: the changelog doesn't hold the writes of %d tuples deleted since", storage.ErrRevisionUnavailable, len(missing))
+		}
+	}
+
+	state.snapshot = snap
+	return snap, nil
+}
+
+// nextULID returns the lowest ULID ordering after the given one.
+func nextULID(id ulid.ULID) ulid.ULID {
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i]++
+		if id[i] != 0 {
+			break
+		}
+	}
+	return id
+}
+
+// readChanges calls fn with the changes of the store after the ULID, or before it in descending order, until it
+// returns false. It returns the ULID of the last change read, if any.
+func (s *SnapshotTupleReader) readChanges(
+	ctx context.Context,
+	store string,
+	from ulid.ULID,
+	desc bool,
+	fn func(change *openfgav1.TupleChange) bool,
+) (ulid.ULID, error) {
+	token, err := s.tokenSerializer.Serialize(from.String(), "")
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	var last ulid.ULID
+	for {
+		changes, continuationToken, err := s.OpenFGADatastore.ReadChanges(ctx, store, storage.ReadChangesFilter{}, storage.ReadChangesOptions{
+			Pagination: storage.NewPaginationOptions(snapshotChangesPageSize, string(token)),
+			SortDesc:   desc,
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			return last, nil
+		}
+		if errors.Is(err, storage.ErrContinuationTokenExpired) {
+			// the changes since the revision were pruned, so they can't be reverted
+			return ulid.ULID{}, fmt.Errorf("%w: the changelog was pruned past it", storage.ErrRevisionUnavailable)
+		}
+		if err != nil {
+			return ulid.ULID{}, err
+		}
+
+		lastID, _, err := s.tokenSerializer.Deserialize(string(continuationToken))
+		if err != nil {
+			return ulid.ULID{}, err
+		}
+		if last, err = ulid.Parse(lastID); err != nil {
+			return ulid.ULID{}, err
+		}
+
+		for _, change := range changes {
+			if !fn(change) {
+				return last, nil
+			}
+		}
+
+		if len(changes) < snapshotChangesPageSize {
+			return last, nil
+		}
+		token = continuationToken
+	}
+}
+
+// matchTupleKey reports whether the tuple key matches the filter of a Read.
+func matchTupleKey(filter, tk *openfgav1.TupleKey) bool {
+	if filter.GetObject() != "" {
+		filterType, filterID := tupleUtils.SplitObject(filter.GetObject())
+		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
+		if filterType != objectType || (filterID != "" && filterID != objectID) {
+			return false
+		}
+	}
+	if filter.GetRelation() != "" && filter.GetRelation() != tk.GetRelation() {
+		return false
+	}
+	if filter.GetUser() != "" && filter.GetUser() != tk.GetUser() {
+		return false
+	}
+	return true
+}
+
+// snapshotIterator iterates over the tuples of the underlying iterator which weren't changed since the revision, and
+// then over the tuples deleted since the revision which match the read.
+type snapshotIterator struct {
+	iter     storage.TupleIterator
+	snapshot *snapshot
+	match    func(t *openfgav1.Tuple) bool
+	// deleted holds the tuples deleted since the revision left to return, once the underlying iterator is done.
+	deleted []*openfgav1.Tuple
+	done    bool
+	once    sync.Once
+}
+
+var _ storage.TupleIterator = (*snapshotIterator)(nil)
+
+// Next see [storage.Iterator].Next.
+func (s *snapshotIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	t, err := s.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.done {
+		s.deleted = s.deleted[1:]
+		return t, nil
+	}
+	return s.iter.Next(ctx)
+}
+
+// Head see [storage.Iterator].Head.
+func (s *snapshotIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	for !s.done {
+		t, err := s.iter.Head(ctx)
+		if errors.Is(err, storage.ErrIteratorDone) {
+			s.done = true
+			for _, deleted := range s.snapshot.deleted {
+				if s.match(deleted) {
+					s.deleted = append(s.deleted, deleted)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the tuples deleted since the revision are returned as they were at it once the underlying iterator is
+		// done, even if written again since
+		if !s.snapshot.changed(t) {
+			return t, nil
+		}
+
+		if _, err := s.iter.Next(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if len(s.deleted) == 0 {
+		return nil, storage.ErrIteratorDone
+	}
+	return s.deleted[0], nil
+}
+
+// Stop see [storage.Iterator].Stop.
+func (s *snapshotIterator) Stop() {
+	s.once.Do(s.iter.Stop)
+}
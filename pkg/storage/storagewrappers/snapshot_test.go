@@ -0,0 +1,214 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func readAll(t *testing.T, iter storage.TupleIterator) []string {
+	t.Helper()
+	defer iter.Stop()
+
+	var keys []string
+	for {
+		tp, err := iter.Next(context.Background())
+		if err != nil {
+			require.ErrorIs(t, err, storage.ErrIteratorDone)
+			return keys
+		}
+		keys = append(keys, tuple.TupleKeyToString(tp.GetKey()))
+	}
+}
+
+func TestSnapshotTupleReader(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	snapshotReader := NewSnapshotTupleReader(ds, encoder.NewStringContinuationTokenSerializer())
+
+	// the revision is the ULID of the last change of the write, which is itself part of the store at the revision
+	storeID := ulid.Make().String()
+	var revision ulid.ULID
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+		tuple.NewTupleKeyWithCondition("document:3", "viewer", "user:anne", "in_region", nil),
+		tuple.NewTupleKey("document:4", "viewer", "folder:x#viewer"),
+	}, storage.WithCommittedRevision(&revision)))
+	require.NotZero(t, revision)
+
+	require.NoError(t, ds.Write(ctx, storeID,
+		[]*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:2", "viewer", "user:anne")),
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:3", "viewer", "user:anne")),
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:4", "viewer", "folder:x#viewer")),
+		},
+		[]*openfgav1.TupleKey{tuple.NewTupleKey("document:5", "viewer", "user:anne")},
+	))
+	// a tuple deleted and written again since the revision is read as it was at the revision
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+	}))
+
+	exactCtx := storage.ContextWithRevision(ctx, storage.Revision{ULID: revision, Mode: storage.RevisionExact})
+	atLeastAsFreshCtx := storage.ContextWithRevision(ctx, storage.Revision{ULID: revision, Mode: storage.RevisionAtLeastAsFresh})
+
+	t.Run("read", func(t *testing.T) {
+		filter := tuple.NewTupleKey("document:", "viewer", "user:anne")
+
+		iter, err := snapshotReader.Read(exactCtx, storeID, filter, storage.ReadOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@user:anne",
+			"document:2#viewer@user:anne",
+			"document:3#viewer@user:anne",
+		}, readAll(t, iter))
+
+		iter, err = snapshotReader.Read(atLeastAsFreshCtx, storeID, filter, storage.ReadOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@user:anne",
+			"document:3#viewer@user:anne",
+			"document:5#viewer@user:anne",
+		}, readAll(t, iter))
+	})
+
+	t.Run("read_page", func(t *testing.T) {
+		var keys []string
+		token := ""
+		for {
+			tuples, continuationToken, err := snapshotReader.ReadPage(exactCtx, storeID, nil, storage.ReadPageOptions{
+				Pagination: storage.NewPaginationOptions(2, token),
+			})
+			require.NoError(t, err)
+			for _, tp := range tuples {
+				keys = append(keys, tuple.TupleKeyToString(tp.GetKey()))
+			}
+			if len(continuationToken) == 0 {
+				break
+			}
+			token = string(continuationToken)
+		}
+
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@user:anne",
+			"document:2#viewer@user:anne",
+			"document:3#viewer@user:anne",
+			"document:4#viewer@folder:x#viewer",
+		}, keys)
+	})
+
+	t.Run("read_rewritten_tuple", func(t *testing.T) {
+		filter := tuple.NewTupleKey("document:3", "viewer", "user:anne")
+
+		iter, err := snapshotReader.Read(exactCtx, storeID, filter, storage.ReadOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		tp, err := iter.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "in_region", tp.GetKey().GetCondition().GetName())
+		_, err = iter.Next(ctx)
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+
+		tuples, _, err := snapshotReader.ReadPage(exactCtx, storeID, filter, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(2, ""),
+		})
+		require.NoError(t, err)
+		require.Len(t, tuples, 1)
+		require.Equal(t, "in_region", tuples[0].GetKey().GetCondition().GetName())
+	})
+
+	t.Run("read_user_tuple", func(t *testing.T) {
+		tp, err := snapshotReader.ReadUserTuple(exactCtx, storeID, tuple.NewTupleKey("document:3", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "in_region", tp.GetKey().GetCondition().GetName())
+
+		_, err = snapshotReader.ReadUserTuple(exactCtx, storeID, tuple.NewTupleKey("document:5", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		tp, err = snapshotReader.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:3", "viewer", "user:anne"), storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Nil(t, tp.GetKey().GetCondition())
+	})
+
+	t.Run("read_userset_tuples", func(t *testing.T) {
+		iter, err := snapshotReader.ReadUsersetTuples(exactCtx, storeID, storage.ReadUsersetTuplesFilter{
+			Object:   "document:4",
+			Relation: "viewer",
+		}, storage.ReadUsersetTuplesOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:4#viewer@folder:x#viewer"}, readAll(t, iter))
+	})
+
+	t.Run("read_starting_with_user", func(t *testing.T) {
+		iter, err := snapshotReader.ReadStartingWithUser(exactCtx, storeID, storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:anne"}},
+		}, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@user:anne",
+			"document:2#viewer@user:anne",
+			"document:3#viewer@user:anne",
+		}, readAll(t, iter))
+	})
+
+	t.Run("memoized_snapshot_is_brought_up_to_date", func(t *testing.T) {
+		ctx := storage.ContextWithRevision(ctx, storage.Revision{ULID: revision, Mode: storage.RevisionExact})
+		filter := tuple.NewTupleKey("document:", "viewer", "user:anne")
+
+		iter, err := snapshotReader.Read(ctx, storeID, filter, storage.ReadOptions{})
+		require.NoError(t, err)
+		before := readAll(t, iter)
+
+		require.NoError(t, ds.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "viewer", "user:anne")),
+			},
+			[]*openfgav1.TupleKey{tuple.NewTupleKey("document:6", "viewer", "user:anne")},
+		))
+
+		iter, err = snapshotReader.Read(ctx, storeID, filter, storage.ReadOptions{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, before, readAll(t, iter))
+	})
+}
+
+func TestSnapshotTupleReaderPrunedChangelog(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	snapshotReader := NewSnapshotTupleReader(ds, encoder.NewStringContinuationTokenSerializer())
+
+	storeID := ulid.Make().String()
+	var revision ulid.ULID
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+	}, storage.WithCommittedRevision(&revision)))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+	}))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+	}))
+
+	// the changes since the revision are pruned, so they can't be reverted
+	_, err := ds.(storage.ChangelogPruner).PruneChanges(ctx, storeID, time.Time{}, 1, 100)
+	require.NoError(t, err)
+
+	exactCtx := storage.ContextWithRevision(ctx, storage.Revision{ULID: revision, Mode: storage.RevisionExact})
+	_, err = snapshotReader.Read(exactCtx, storeID, tuple.NewTupleKey("document:", "viewer", "user:anne"), storage.ReadOptions{})
+	require.ErrorIs(t, err, storage.ErrRevisionUnavailable)
+	require.NotErrorIs(t, err, storage.ErrContinuationTokenExpired)
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxStoreLabels is the maximum number of labels of a store.
+	MaxStoreLabels = 64
+
+	// maxStoreLabelLength is the maximum length of the key and value of a store label.
+	maxStoreLabelLength = 63
+)
+
+// storeLabelPattern is the format of the keys and non-empty values of the store labels: alphanumerics, '-', '_',
+// '.' and '/', beginning and ending with an alphanumeric.
+var storeLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]*[a-zA-Z0-9])?$`)
+
+// ValidateStoreLabels checks that there are at most MaxStoreLabels labels, each with a key of 1 to 63 characters and
+// a value of up to 63 characters, made of alphanumerics, '-', '_', '.' and '/', beginning and ending with an
+// alphanumeric.
+func ValidateStoreLabels(labels map[string]string) error {
+	if len(labels) > MaxStoreLabels {
+		return fmt.Errorf("a store can't have more than %d labels", MaxStoreLabels)
+	}
+	for key, value := range labels {
+		if err := validateStoreLabelKey(key); err != nil {
+			return err
+		}
+		if err := validateStoreLabelValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateStoreLabelKey(key string) error {
+	if len(key) > maxStoreLabelLength || !storeLabelPattern.MatchString(key) {
+		return fmt.Errorf("invalid label key '%s'", key)
+	}
+	return nil
+}
+
+func validateStoreLabelValue(value string) error {
+	if value != "" && (len(value) > maxStoreLabelLength || !storeLabelPattern.MatchString(value)) {
+		return fmt.Errorf("invalid label value '%s'", value)
+	}
+	return nil
+}
+
+// ParseStoreLabels parses store labels formatted as 'key1=value1,key2=value2' (see FormatStoreLabels).
+func ParseStoreLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return labels, nil
+	}
+
+	for _, label := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(label), "=")
+		if !found {
+			return nil, fmt.Errorf("label '%s' isn't formatted as 'key=value'", label)
+		}
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("duplicate label key '%s'", key)
+		}
+		labels[key] = value
+	}
+
+	if err := ValidateStoreLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// FormatStoreLabels formats store labels as 'key1=value1,key2=value2', ordered by key.
+func FormatStoreLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(labels[key])
+	}
+	return sb.String()
+}
+
+// LabelOperator is the operator of a LabelRequirement.
+type LabelOperator int
+
+const (
+	// LabelEquals matches the stores with the label key set to the value: 'key=value'.
+	LabelEquals LabelOperator = iota
+	// LabelNotEquals matches the stores without the label key set to the value, including those without the
+	// label key: 'key!=value'.
+	LabelNotEquals
+	// LabelExists matches the stores with the label key, whatever its value: 'key'.
+	LabelExists
+	// LabelNotExists matches the stores without the label key: '!key'.
+	LabelNotExists
+)
+
+// LabelRequirement is a requirement on a label of the stores matched by a LabelSelector.
+type LabelRequirement struct {
+	Key      string
+	Operator LabelOperator
+	// Value is the value of the label for LabelEquals and LabelNotEquals.
+	Value string
+}
+
+// Matches reports whether the labels of a store meet the requirement.
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case LabelEquals:
+		return ok && value == r.Value
+	case LabelNotEquals:
+		return !ok || value != r.Value
+	case LabelExists:
+		return ok
+	case LabelNotExists:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// LabelSelector selects the stores whose labels meet all its requirements. An empty selector selects all the
+// stores.
+type LabelSelector []LabelRequirement
+
+// Matches reports whether the labels of a store meet all the requirements of the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelector parses a label selector made of comma separated requirements, each one of 'key=value',
+// 'key!=value', 'key' and '!key' (e.g. 'env=prod,tenant!=acme,!legacy').
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var selector LabelSelector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		var requirement LabelRequirement
+		if key, value, found := strings.Cut(part, "!="); found {
+			requirement = LabelRequirement{Key: key, Operator: LabelNotEquals, Value: value}
+		} else if key, value, found := strings.Cut(part, "="); found {
+			requirement = LabelRequirement{Key: key, Operator: LabelEquals, Value: value}
+		} else if key, found := strings.CutPrefix(part, "!"); found {
+			requirement = LabelRequirement{Key: key, Operator: LabelNotExists}
+		} else {
+			requirement = LabelRequirement{Key: part, Operator: LabelExists}
+		}
+
+		if err := validateStoreLabelKey(requirement.Key); err != nil {
+			return nil, fmt.Errorf("invalid label selector '%s': %w", s, err)
+		}
+		if err := validateStoreLabelValue(requirement.Value); err != nil {
+			return nil, fmt.Errorf("invalid label selector '%s': %w", s, err)
+		}
+		selector = append(selector, requirement)
+	}
+	return selector, nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStoreLabels(t *testing.T) {
+	labels, err := ParseStoreLabels(" env=prod, team=auth/core,empty=")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "prod", "team": "auth/core", "empty": ""}, labels)
+	require.Equal(t, "empty=,env=prod,team=auth/core", FormatStoreLabels(labels))
+
+	for name, s := range map[string]string{
+		"missing_value": "env",
+		"duplicate_key": "env=prod,env=dev",
+		"empty_key":     "=prod",
+		"invalid_key":   "-env=prod",
+		"invalid_value": "env=prod!",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseStoreLabels(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := ParseLabelSelector("env=prod,tenant!=acme,team,!legacy")
+	require.NoError(t, err)
+	require.Equal(t, LabelSelector{
+		{Key: "env", Operator: LabelEquals, Value: "prod"},
+		{Key: "tenant", Operator: LabelNotEquals, Value: "acme"},
+		{Key: "team", Operator: LabelExists},
+		{Key: "legacy", Operator: LabelNotExists},
+	}, selector)
+
+	require.True(t, selector.Matches(map[string]string{"env": "prod", "team": "auth"}))
+	require.True(t, selector.Matches(map[string]string{"env": "prod", "team": "auth", "tenant": "other"}))
+	require.False(t, selector.Matches(map[string]string{"env": "prod", "team": "auth", "tenant": "acme"}))
+	require.False(t, selector.Matches(map[string]string{"env": "prod", "team": "auth", "legacy": ""}))
+	require.False(t, selector.Matches(map[string]string{"env": "dev", "team": "auth"}))
+	require.False(t, selector.Matches(map[string]string{"env": "prod"}))
+
+	empty, err := ParseLabelSelector("")
+	require.NoError(t, err)
+	require.True(t, empty.Matches(nil))
+
+	_, err = ParseLabelSelector("env=prod,")
+	require.Error(t, err)
+}
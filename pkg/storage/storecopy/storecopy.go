@@ -0,0 +1,318 @@
+// Package storecopy contains the copy of a store, along with its authorization models, assertions, labels, tuples
+// and changelog, from a datastore to another one, which may be of another engine.
+package storecopy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// pageSize is the number of tuples, changes and authorization models read at once while copying a store.
+const pageSize = 100
+
+// Copier copies a store from a datastore, the source, to another one, the target. Each of its steps can be retried,
+// and resumes from the copy on the target.
+type Copier struct {
+	store  string
+	source storage.OpenFGADatastore
+	target storage.OpenFGADatastore
+
+	sourceRecords storage.RecordCopier
+	targetRecords storage.RecordCopier
+
+	checkpoint func(token string) error
+}
+
+// Option configures a Copier.
+type Option func(*Copier)
+
+// WithChangesCheckpoint calls fn with the ULID of the last change copied after every page of them, so that a copy
+// which failed can resume from it.
+func WithChangesCheckpoint(fn func(token string) error) Option {
+	return func(m *Copier) {
+		m.checkpoint = fn
+	}
+}
+
+// New returns a Copier of the store from the source to the target. Both must implement [storage.RecordCopier], so
+// that the tuples and changes are copied as they are stored, with their ULIDs, timestamps and expiry.
+func New(store string, source, target storage.OpenFGADatastore, opts ...Option) (*Copier, error) {
+	sourceRecords, ok := storage.As[storage.RecordCopier](source)
+	if !ok {
+		return nil, fmt.Errorf("the source datastore cannot copy its tuples and changes as they are stored: %w", errors.ErrUnsupported)
+	}
+	targetRecords, ok := storage.As[storage.RecordCopier](target)
+	if !ok {
+		return nil, fmt.Errorf("the target datastore cannot import tuples and changes as they are stored: %w", errors.ErrUnsupported)
+	}
+
+	m := &Copier{
+		store:         store,
+		source:        source,
+		target:        target,
+		sourceRecords: sourceRecords,
+		targetRecords: targetRecords,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// CreateStore creates the store on the target, with its labels, unless it was already by a previous copy.
+func (m *Copier) CreateStore(ctx context.Context, s *openfgav1.Store) error {
+	_, err := m.target.GetStore(ctx, m.store)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("get store: %w", err)
+	}
+
+	store := &openfgav1.Store{Id: s.GetId(), Name: s.GetName()}
+	sourceLabeler, sourceOK := storage.As[storage.StoreLabeler](m.source)
+	targetLabeler, targetOK := storage.As[storage.StoreLabeler](m.target)
+	if sourceOK && targetOK {
+		var labels map[string]string
+		labels, err = sourceLabeler.ReadStoreLabels(ctx, m.store)
+		if err == nil {
+			_, err = targetLabeler.CreateStoreWithLabels(ctx, store, labels)
+		}
+	} else {
+		_, err = m.target.CreateStore(ctx, store)
+	}
+
+	if errors.Is(err, storage.ErrCollision) {
+		return fmt.Errorf("a deleted copy of the store is left on the target, which must be purged first: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("create store: %w", err)
+	}
+	return nil
+}
+
+// CopyLabels copies the name and labels of the store to the target.
+func (m *Copier) CopyLabels(ctx context.Context) error {
+	sourceLabeler, sourceOK := storage.As[storage.StoreLabeler](m.source)
+	targetLabeler, targetOK := storage.As[storage.StoreLabeler](m.target)
+	if !sourceOK || !targetOK {
+		return nil
+	}
+
+	s, err := m.source.GetStore(ctx, m.store)
+	if err != nil {
+		return fmt.Errorf("get store: %w", err)
+	}
+	labels, err := sourceLabeler.ReadStoreLabels(ctx, m.store)
+	if err != nil {
+		return fmt.Errorf("read store labels: %w", err)
+	}
+	if _, err := targetLabeler.UpdateStore(ctx, m.store, s.GetName(), labels); err != nil {
+		return fmt.Errorf("update store: %w", err)
+	}
+	return nil
+}
+
+// CopyModels copies the authorization models the target lacks, oldest first so that the latest stays the same,
+// and the assertions of all the models.
+func (m *Copier) CopyModels(ctx context.Context) error {
+	var models []*openfgav1.AuthorizationModel
+	var token string
+	for {
+		page, next, err := m.source.ReadAuthorizationModels(ctx, m.store, storage.ReadAuthorizationModelsOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, token),
+		})
+		if err != nil {
+			return fmt.Errorf("read authorization models: %w", err)
+		}
+		models = append(models, page...)
+		if len(next) == 0 {
+			break
+		}
+		token = string(next)
+	}
+
+	for i := len(models) - 1; i >= 0; i-- {
+		model := models[i]
+
+		_, err := m.target.ReadAuthorizationModel(ctx, m.store, model.GetId())
+		if errors.Is(err, storage.ErrNotFound) {
+			err = m.target.WriteAuthorizationModel(ctx, m.store, model)
+		}
+		if err != nil {
+			return fmt.Errorf("copy authorization model '%s': %w", model.GetId(), err)
+		}
+
+		assertions, err := m.source.ReadAssertions(ctx, m.store, model.GetId())
+		if err != nil {
+			return fmt.Errorf("read assertions of authorization model '%s': %w", model.GetId(), err)
+		}
+		if len(assertions) == 0 {
+			continue
+		}
+		if err := m.target.WriteAssertions(ctx, m.store, model.GetId(), assertions); err != nil {
+			return fmt.Errorf("write assertions of authorization model '%s': %w", model.GetId(), err)
+		}
+	}
+	return nil
+}
+
+// CopyChanges copies the changes of the changelog of the store after the change with the ULID after, or all of them
+// if it is empty, to the target as they are stored, along with the current state on the source of the tuples they
+// changed. It returns the ULID of the last change copied, which is the same if there are none.
+func (m *Copier) CopyChanges(ctx context.Context, after string) (string, error) {
+	for {
+		changes, err := m.sourceRecords.ReadChangeRecords(ctx, m.store, after, pageSize)
+		if err != nil {
+			return "", fmt.Errorf("read changes: %w", err)
+		}
+		if len(changes) == 0 {
+			return after, nil
+		}
+
+		records := storage.RecordImport{Changes: changes}
+		seen := make(map[string]struct{}, len(changes))
+		for _, change := range changes {
+			tk := change.TupleKey
+			key := tuple.TupleKeyToString(tuple.NewTupleKey(tk.GetObject(), tk.GetRelation(), tk.GetUser()))
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			record, err := m.sourceRecords.ReadTupleRecord(ctx, m.store, tk)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				records.Deletes = append(records.Deletes, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+			case err != nil:
+				return "", fmt.Errorf("read tuple: %w", err)
+			default:
+				records.Tuples = append(records.Tuples, record)
+			}
+		}
+
+		if err := m.targetRecords.ImportRecords(ctx, m.store, records); err != nil {
+			return "", fmt.Errorf("import changes: %w", err)
+		}
+
+		after = changes[len(changes)-1].Ulid
+		if m.checkpoint != nil {
+			if err := m.checkpoint(after); err != nil {
+				return "", fmt.Errorf("checkpoint changes: %w", err)
+			}
+		}
+		if len(changes) < pageSize {
+			return after, nil
+		}
+	}
+}
+
+// ReconcileTuples copies to the target, as they are stored, the tuples of the store it lacks or which were written
+// again since, and deletes from it the tuples the store doesn't have. The tuples of both datastores are read in key
+// order and merged, the datastores only being asked for the tuples the target seems to have in excess: as engines
+// may order some keys differently, they are deleted only once the source confirms it doesn't have them.
+func (m *Copier) ReconcileTuples(ctx context.Context) error {
+	source := &recordStream{copier: m.sourceRecords, store: m.store}
+	target := &recordStream{copier: m.targetRecords, store: m.store}
+
+	var pending storage.RecordImport
+	for {
+		sourceRecord, err := source.peek(ctx)
+		if err != nil {
+			return err
+		}
+		targetRecord, err := target.peek(ctx)
+		if err != nil {
+			return err
+		}
+		if sourceRecord == nil && targetRecord == nil {
+			break
+		}
+
+		order := 0
+		switch {
+		case sourceRecord == nil:
+			order = 1
+		case targetRecord == nil:
+			order = -1
+		default:
+			order = storage.CompareTupleRecords(sourceRecord, targetRecord)
+		}
+
+		switch {
+		case order < 0:
+			pending.Tuples = append(pending.Tuples, sourceRecord)
+			source.next()
+		case order > 0:
+			tk := targetRecord.AsTuple().GetKey()
+			_, err := m.sourceRecords.ReadTupleRecord(ctx, m.store, tk)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				pending.Deletes = append(pending.Deletes, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+			case err != nil:
+				return fmt.Errorf("read tuple: %w", err)
+			}
+			target.next()
+		default:
+			if sourceRecord.Ulid != targetRecord.Ulid {
+				pending.Tuples = append(pending.Tuples, sourceRecord)
+			}
+			source.next()
+			target.next()
+		}
+
+		// the pages are read after the last tuple of the previous one, which the tuples imported or deleted before
+		// it don't change
+		if len(pending.Tuples)+len(pending.Deletes) >= pageSize {
+			if err := m.targetRecords.ImportRecords(ctx, m.store, pending); err != nil {
+				return fmt.Errorf("reconcile tuples: %w", err)
+			}
+			pending = storage.RecordImport{}
+		}
+	}
+
+	if len(pending.Tuples)+len(pending.Deletes) > 0 {
+		if err := m.targetRecords.ImportRecords(ctx, m.store, pending); err != nil {
+			return fmt.Errorf("reconcile tuples: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordStream reads the tuples of a store, as they are stored, one page at a time.
+type recordStream struct {
+	copier storage.RecordCopier
+	store  string
+
+	records []*storage.TupleRecord
+	token   string
+	done    bool
+}
+
+// peek returns the current tuple of the stream, reading the next page if needed, or nil once they were all read.
+func (r *recordStream) peek(ctx context.Context) (*storage.TupleRecord, error) {
+	for len(r.records) == 0 && !r.done {
+		records, next, err := r.copier.ReadTupleRecords(ctx, r.store, storage.NewPaginationOptions(pageSize, r.token))
+		if err != nil {
+			return nil, fmt.Errorf("read tuples: %w", err)
+		}
+		r.records = records
+		r.token = string(next)
+		r.done = len(next) == 0
+	}
+	if len(r.records) == 0 {
+		return nil, nil
+	}
+	return r.records[0], nil
+}
+
+// next moves the stream past its current tuple.
+func (r *recordStream) next() {
+	r.records = r.records[1:]
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// EventOutboxTest tests a datastore configured to record the changes in its outbox.
+func EventOutboxTest(t *testing.T, datastore storage.OpenFGADatastore, outbox storage.EventOutbox) {
+	ctx := context.Background()
+
+	// readAll returns the events of the outbox, and deletes them so that the next test starts from an empty one.
+	readAll := func(t *testing.T) []*storage.Event {
+		t.Helper()
+		events, err := outbox.ReadOutbox(ctx, 100)
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		require.NoError(t, outbox.DeleteOutboxEvents(ctx, ids))
+		return events
+	}
+	readAll(t)
+
+	tk1 := tuple.NewTupleKeyWithCondition("doc:readme", "viewer", "user:anne", "in_office", nil)
+	tk2 := tuple.NewTupleKey("doc:readme", "viewer", "user:bob")
+
+	t.Run("records_the_changes_in_order", func(t *testing.T) {
+		store, err := datastore.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "outbox"})
+		require.NoError(t, err)
+		storeID := store.GetId()
+
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type doc
+				relations
+					define viewer: [user]`)
+		require.NoError(t, datastore.WriteAuthorizationModel(ctx, storeID, model))
+
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1, tk2}))
+		require.NoError(t, datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk2),
+		}, []*openfgav1.TupleKey{tk1}, storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore)))
+
+		// the failed writes, and those changing nothing, aren't recorded
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk2),
+		}, nil)
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1}, storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore)))
+
+		require.NoError(t, datastore.DeleteStore(ctx, storeID))
+
+		events := readAll(t)
+		require.Len(t, events, 5)
+		for i, event := range events {
+			require.Equal(t, storeID, event.StoreID)
+			require.NotEmpty(t, event.ID)
+			require.False(t, event.Time.IsZero())
+			if i > 0 {
+				require.False(t, event.Time.Before(events[i-1].Time))
+			}
+		}
+
+		require.Equal(t, storage.EventStoreCreated, events[0].Type)
+		require.Equal(t, "outbox", events[0].StoreName)
+
+		require.Equal(t, storage.EventAuthorizationModelWritten, events[1].Type)
+		require.Equal(t, model.GetId(), events[1].AuthorizationModelID)
+
+		require.Equal(t, storage.EventTuplesWritten, events[2].Type)
+		require.ElementsMatch(t, []storage.EventTupleChange{
+			{Operation: storage.TupleOperationWrite, Object: "doc:readme", Relation: "viewer", User: "user:anne", ConditionName: "in_office"},
+			{Operation: storage.TupleOperationWrite, Object: "doc:readme", Relation: "viewer", User: "user:bob"},
+		}, events[2].TupleChanges)
+
+		require.Equal(t, storage.EventTuplesWritten, events[3].Type)
+		require.Equal(t, []storage.EventTupleChange{
+			{Operation: storage.TupleOperationDelete, Object: "doc:readme", Relation: "viewer", User: "user:bob"},
+		}, events[3].TupleChanges)
+
+		require.Equal(t, storage.EventStoreDeleted, events[4].Type)
+	})
+
+	t.Run("stores_created_with_labels", func(t *testing.T) {
+		labeler, ok := datastore.(storage.StoreLabeler)
+		if !ok {
+			t.Skip("the datastore doesn't implement storage.StoreLabeler")
+		}
+
+		store, err := labeler.CreateStoreWithLabels(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "labeled"}, map[string]string{"tier": "gold"})
+		require.NoError(t, err)
+
+		events := readAll(t)
+		require.Len(t, events, 1)
+		require.Equal(t, storage.EventStoreCreated, events[0].Type)
+		require.Equal(t, store.GetId(), events[0].StoreID)
+	})
+
+	t.Run("imported_tuples", func(t *testing.T) {
+		importer, ok := datastore.(storage.TupleImporter)
+		if !ok {
+			t.Skip("the datastore doesn't implement storage.TupleImporter")
+		}
+
+		storeID := ulid.Make().String()
+		require.NoError(t, importer.ImportTuples(ctx, storeID, []*openfgav1.TupleKey{tk1, tk2}))
+
+		events := readAll(t)
+		require.Len(t, events, 1)
+		require.Equal(t, storage.EventTuplesWritten, events[0].Type)
+		require.Len(t, events[0].TupleChanges, 2)
+	})
+
+	t.Run("expired_tuples", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		expiresAt := storage.WithExpiresAt(time.Now().Add(-time.Minute))
+
+		// the expired tuples deleted by a write to write them again are recorded along with it
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1}, expiresAt))
+		readAll(t)
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1}))
+
+		events := readAll(t)
+		require.Len(t, events, 1)
+		require.Equal(t, storage.EventTuplesWritten, events[0].Type)
+		require.Equal(t, []storage.EventTupleChange{
+			{Operation: storage.TupleOperationDelete, Object: "doc:readme", Relation: "viewer", User: "user:anne"},
+			{Operation: storage.TupleOperationWrite, Object: "doc:readme", Relation: "viewer", User: "user:anne", ConditionName: "in_office"},
+		}, events[0].TupleChanges)
+
+		reaper, ok := datastore.(storage.TupleReaper)
+		if !ok {
+			return
+		}
+
+		// and those deleted by the reaper on their own
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2}, expiresAt))
+		readAll(t)
+		for {
+			deleted, err := reaper.DeleteExpiredTuples(ctx, time.Now(), 100)
+			require.NoError(t, err)
+			if deleted < 100 {
+				break
+			}
+		}
+
+		events = nil
+		for page := readAll(t); len(page) > 0; page = readAll(t) {
+			for _, event := range page {
+				if event.StoreID == storeID {
+					events = append(events, event)
+				}
+			}
+		}
+		require.Len(t, events, 1)
+		require.Equal(t, storage.EventTuplesExpired, events[0].Type)
+		require.Equal(t, []storage.EventTupleChange{
+			{Operation: storage.TupleOperationDelete, Object: "doc:readme", Relation: "viewer", User: "user:bob"},
+		}, events[0].TupleChanges)
+	})
+
+	t.Run("pages_and_deletes_the_events", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		for _, tk := range []*openfgav1.TupleKey{tk1, tk2} {
+			require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+		}
+		require.NoError(t, datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk1),
+		}, nil))
+
+		first, err := outbox.ReadOutbox(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		require.Equal(t, "user:anne", first[0].TupleChanges[0].User)
+		require.Equal(t, "user:bob", first[1].TupleChanges[0].User)
+
+		// the IDs which aren't in the outbox are ignored
+		require.NoError(t, outbox.DeleteOutboxEvents(ctx, []string{first[0].ID, first[1].ID, ulid.Make().String()}))
+
+		events := readAll(t)
+		require.Len(t, events, 1)
+		require.Equal(t, storage.TupleOperationDelete, events[0].TupleChanges[0].Operation)
+	})
+}
@@ -29,28 +29,75 @@ var (
 )
 
 func RunAllTests(t *testing.T, ds storage.OpenFGADatastore, tokenSerializer encoder.ContinuationTokenSerializer) {
-	t.Run("TestDatastoreIsReady", func(t *testing.T) {
-		status, err := ds.IsReady(context.Background())
-		require.NoError(t, err)
-		require.True(t, status.IsReady)
-	})
-
-	// Tuples.
-	t.Run("TestTupleWriteAndRead", func(t *testing.T) { TupleWritingAndReadingTest(t, ds) })
-	t.Run("TestReadChanges", func(t *testing.T) { ReadChangesTest(t, ds, tokenSerializer) })
-	t.Run("TestReadStartingWithUser", func(t *testing.T) { ReadStartingWithUserTest(t, ds) })
-	t.Run("TestReadAndReadPages", func(t *testing.T) { ReadAndReadPageTest(t, ds) })
-
-	// Authorization models.
-	t.Run("TestWriteAndReadAuthorizationModel", func(t *testing.T) { WriteAndReadAuthorizationModelTest(t, ds) })
-	t.Run("TestReadAuthorizationModels", func(t *testing.T) { ReadAuthorizationModelsTest(t, ds) })
-	t.Run("TestFindLatestAuthorizationModel", func(t *testing.T) { FindLatestAuthorizationModelTest(t, ds) })
+	for _, scenario := range Scenarios(ds, tokenSerializer) {
+		t.Run(scenario.Name, scenario.Run)
+	}
+}
 
-	// Assertions.
-	t.Run("TestWriteAndReadAssertions", func(t *testing.T) { AssertionsTest(t, ds) })
+// Scenario is a test of a behavior of a datastore, which the datastores must all pass.
+type Scenario struct {
+	Name string
+	Run  func(t *testing.T)
+}
 
-	// Stores.
-	t.Run("TestStore", func(t *testing.T) { StoreTest(t, ds) })
+// Scenarios returns the scenarios of the datastore, including those of the optional interfaces it implements. The
+// tokenSerializer must be the one of the continuation tokens of the datastore. The scenarios write stores of their
+// own, with random IDs, to the datastore.
+func Scenarios(ds storage.OpenFGADatastore, tokenSerializer encoder.ContinuationTokenSerializer) []Scenario {
+	scenarios := []Scenario{
+		{"TestDatastoreIsReady", func(t *testing.T) {
+			status, err := ds.IsReady(context.Background())
+			require.NoError(t, err)
+			require.True(t, status.IsReady)
+		}},
+
+		// Tuples.
+		{"TestTupleWriteAndRead", func(t *testing.T) { TupleWritingAndReadingTest(t, ds) }},
+		{"TestTupleWriteOptions", func(t *testing.T) { TupleWriteOptionsTest(t, ds) }},
+		{"TestTupleExpiration", func(t *testing.T) { TupleExpirationTest(t, ds) }},
+	}
+	if importer, ok := ds.(storage.TupleImporter); ok {
+		scenarios = append(scenarios, Scenario{"TestImportTuples", func(t *testing.T) { ImportTuplesTest(t, ds, importer) }})
+	}
+	scenarios = append(scenarios, Scenario{"TestReadChanges", func(t *testing.T) { ReadChangesTest(t, ds, tokenSerializer) }})
+	if watcher, ok := ds.(storage.ChangelogWatcher); ok {
+		scenarios = append(scenarios, Scenario{"TestWatchChangelog", func(t *testing.T) { WatchChangelogTest(t, ds, watcher) }})
+	}
+	if pruner, ok := ds.(storage.ChangelogPruner); ok {
+		scenarios = append(scenarios, Scenario{"TestChangelogPruner", func(t *testing.T) { ChangelogPrunerTest(t, ds, pruner) }})
+	}
+	if counter, ok := ds.(storage.TupleCounter); ok {
+		scenarios = append(scenarios, Scenario{"TestTupleCounter", func(t *testing.T) { TupleCounterTest(t, ds, counter) }})
+	}
+	if copier, ok := ds.(storage.RecordCopier); ok {
+		scenarios = append(scenarios, Scenario{"TestRecordCopier", func(t *testing.T) { RecordCopierTest(t, ds, copier) }})
+	}
+	scenarios = append(scenarios,
+		Scenario{"TestReadTuplesBatch", func(t *testing.T) { ReadTuplesBatchTest(t, ds) }},
+		Scenario{"TestReadStartingWithUser", func(t *testing.T) { ReadStartingWithUserTest(t, ds) }},
+		Scenario{"TestReadAndReadPages", func(t *testing.T) { ReadAndReadPageTest(t, ds) }},
+
+		// Authorization models.
+		Scenario{"TestWriteAndReadAuthorizationModel", func(t *testing.T) { WriteAndReadAuthorizationModelTest(t, ds) }},
+		Scenario{"TestReadAuthorizationModels", func(t *testing.T) { ReadAuthorizationModelsTest(t, ds) }},
+		Scenario{"TestFindLatestAuthorizationModel", func(t *testing.T) { FindLatestAuthorizationModelTest(t, ds) }},
+
+		// Assertions.
+		Scenario{"TestWriteAndReadAssertions", func(t *testing.T) { AssertionsTest(t, ds) }},
+
+		// Stores.
+		Scenario{"TestStore", func(t *testing.T) { StoreTest(t, ds) }},
+	)
+	if undeleter, ok := ds.(storage.StoreUndeleter); ok {
+		scenarios = append(scenarios, Scenario{"TestStoreUndeleter", func(t *testing.T) { StoreUndeleterTest(t, ds, undeleter) }})
+	}
+	if purger, ok := ds.(storage.StorePurger); ok {
+		scenarios = append(scenarios, Scenario{"TestStorePurger", func(t *testing.T) { StorePurgerTest(t, ds, purger) }})
+	}
+	if labeler, ok := ds.(storage.StoreLabeler); ok {
+		scenarios = append(scenarios, Scenario{"TestStoreLabeler", func(t *testing.T) { StoreLabelerTest(t, ds, labeler) }})
+	}
+	return scenarios
 }
 
 // BootstrapFGAStore is a utility to write an FGA model and relationship tuples to a datastore.
@@ -12,6 +12,7 @@ import (
 
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
 )
 
 func StoreTest(t *testing.T, datastore storage.OpenFGADatastore) {
@@ -97,4 +98,284 @@ func StoreTest(t *testing.T, datastore storage.OpenFGADatastore) {
 			require.NotEqual(t, store.GetId(), s.GetId())
 		}
 	})
+
+	t.Run("list_stores_filters_by_name_prefix", func(t *testing.T) {
+		prefix := testutils.CreateRandomString(10)
+		var ids []string
+		for _, name := range []string{prefix + "-a", prefix + "-b", "x" + prefix} {
+			store := &openfgav1.Store{Id: ulid.Make().String(), Name: name}
+			_, err := datastore.CreateStore(ctx, store)
+			require.NoError(t, err)
+			ids = append(ids, store.GetId())
+		}
+
+		gotStores, _, err := datastore.ListStores(ctx, storage.ListStoresOptions{
+			NamePrefix: prefix,
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		})
+		require.NoError(t, err)
+		require.Len(t, gotStores, 2)
+		require.Equal(t, ids[0], gotStores[0].GetId())
+		require.Equal(t, ids[1], gotStores[1].GetId())
+	})
+}
+
+func StoreLabelerTest(t *testing.T, datastore storage.OpenFGADatastore, labeler storage.StoreLabeler) {
+	ctx := context.Background()
+
+	// the labels are unique to the test, so that the stores of the other tests don't match the selectors
+	tenant := testutils.CreateRandomString(10)
+	createStore := func(t *testing.T, labels map[string]string) *openfgav1.Store {
+		store, err := labeler.CreateStoreWithLabels(ctx, &openfgav1.Store{
+			Id:   ulid.Make().String(),
+			Name: testutils.CreateRandomString(10),
+		}, labels)
+		require.NoError(t, err)
+		return store
+	}
+	listStores := func(t *testing.T, selector string) []string {
+		labelSelector, err := storage.ParseLabelSelector(selector)
+		require.NoError(t, err)
+
+		gotStores, _, err := datastore.ListStores(ctx, storage.ListStoresOptions{
+			LabelSelector: labelSelector,
+			Pagination:    storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		})
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(gotStores))
+		for _, store := range gotStores {
+			ids = append(ids, store.GetId())
+		}
+		return ids
+	}
+
+	prod := createStore(t, map[string]string{"tenant": tenant, "env": "prod"})
+	dev := createStore(t, map[string]string{"tenant": tenant, "env": "dev", "legacy": ""})
+	unlabeled := createStore(t, nil)
+
+	t.Run("labels_are_read", func(t *testing.T) {
+		labels, err := labeler.ReadStoreLabels(ctx, prod.GetId())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"tenant": tenant, "env": "prod"}, labels)
+
+		labels, err = labeler.ReadStoreLabels(ctx, unlabeled.GetId())
+		require.NoError(t, err)
+		require.Empty(t, labels)
+	})
+
+	t.Run("list_stores_filters_by_label_selector", func(t *testing.T) {
+		require.Equal(t, []string{prod.GetId(), dev.GetId()}, listStores(t, "tenant="+tenant))
+		require.Equal(t, []string{prod.GetId()}, listStores(t, "tenant="+tenant+",env=prod"))
+		require.Equal(t, []string{dev.GetId()}, listStores(t, "tenant="+tenant+",env!=prod"))
+		require.Equal(t, []string{dev.GetId()}, listStores(t, "tenant="+tenant+",legacy"))
+		require.Equal(t, []string{prod.GetId()}, listStores(t, "tenant="+tenant+",!legacy"))
+		require.Empty(t, listStores(t, "tenant="+tenant+",env=staging"))
+	})
+
+	t.Run("update_store_replaces_the_labels", func(t *testing.T) {
+		store := createStore(t, map[string]string{"tenant": tenant, "env": "staging"})
+
+		updated, err := labeler.UpdateStore(ctx, store.GetId(), "", map[string]string{"env": "staging"})
+		require.NoError(t, err)
+		require.Equal(t, store.GetName(), updated.GetName())
+
+		labels, err := labeler.ReadStoreLabels(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "staging"}, labels)
+		require.NotContains(t, listStores(t, "tenant="+tenant), store.GetId())
+	})
+
+	t.Run("update_store_renames_the_store_and_keeps_the_labels", func(t *testing.T) {
+		store := createStore(t, map[string]string{"tenant": tenant, "env": "qa"})
+
+		updated, err := labeler.UpdateStore(ctx, store.GetId(), "renamed", nil)
+		require.NoError(t, err)
+		require.Equal(t, "renamed", updated.GetName())
+
+		gotStore, err := datastore.GetStore(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, "renamed", gotStore.GetName())
+
+		labels, err := labeler.ReadStoreLabels(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"tenant": tenant, "env": "qa"}, labels)
+	})
+
+	t.Run("updating_a_deleted_store_returns_not_found", func(t *testing.T) {
+		store := createStore(t, nil)
+		require.NoError(t, datastore.DeleteStore(ctx, store.GetId()))
+
+		_, err := labeler.UpdateStore(ctx, store.GetId(), "renamed", nil)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = labeler.ReadStoreLabels(ctx, store.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("updating_a_non-existent_store_returns_not_found", func(t *testing.T) {
+		_, err := labeler.UpdateStore(ctx, ulid.Make().String(), "renamed", nil)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func StoreUndeleterTest(t *testing.T, datastore storage.OpenFGADatastore, undeleter storage.StoreUndeleter) {
+	ctx := context.Background()
+
+	createStore := func(t *testing.T) *openfgav1.Store {
+		store, err := datastore.CreateStore(ctx, &openfgav1.Store{
+			Id:        ulid.Make().String(),
+			Name:      testutils.CreateRandomString(10),
+			CreatedAt: timestamppb.New(time.Now()),
+		})
+		require.NoError(t, err)
+		return store
+	}
+
+	t.Run("undeleted_store_is_restored", func(t *testing.T) {
+		store := createStore(t)
+		require.NoError(t, datastore.DeleteStore(ctx, store.GetId()))
+
+		restored, err := undeleter.UndeleteStore(ctx, store.GetId(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, store.GetId(), restored.GetId())
+		require.Equal(t, store.GetName(), restored.GetName())
+		require.Nil(t, restored.GetDeletedAt())
+
+		_, err = datastore.GetStore(ctx, store.GetId())
+		require.NoError(t, err)
+
+		gotStores, _, err := datastore.ListStores(ctx, storage.ListStoresOptions{
+			IDs:        []string{store.GetId()},
+			Pagination: storage.NewPaginationOptions(storage.DefaultPageSize, ""),
+		})
+		require.NoError(t, err)
+		require.Len(t, gotStores, 1)
+	})
+
+	t.Run("undeleting_a_store_which_is_not_deleted_returns_it", func(t *testing.T) {
+		store := createStore(t)
+
+		restored, err := undeleter.UndeleteStore(ctx, store.GetId(), time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, store.GetName(), restored.GetName())
+	})
+
+	t.Run("undeleting_a_store_deleted_before_the_grace_period_returns_not_found", func(t *testing.T) {
+		store := createStore(t)
+		require.NoError(t, datastore.DeleteStore(ctx, store.GetId()))
+
+		_, err := undeleter.UndeleteStore(ctx, store.GetId(), time.Now().Add(time.Hour))
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = datastore.GetStore(ctx, store.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("undeleting_a_non-existent_store_returns_not_found", func(t *testing.T) {
+		_, err := undeleter.UndeleteStore(ctx, ulid.Make().String(), time.Time{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func StorePurgerTest(t *testing.T, datastore storage.OpenFGADatastore, purger storage.StorePurger) {
+	ctx := context.Background()
+
+	// createDeletedStore creates a store with an authorization model, assertions and three tuples, then deletes it.
+	createDeletedStore := func(t *testing.T) string {
+		store, err := datastore.CreateStore(ctx, &openfgav1.Store{
+			Id:        ulid.Make().String(),
+			Name:      testutils.CreateRandomString(10),
+			CreatedAt: timestamppb.New(time.Now()),
+		})
+		require.NoError(t, err)
+
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]`)
+		require.NoError(t, datastore.WriteAuthorizationModel(ctx, store.GetId(), model))
+		require.NoError(t, datastore.WriteAssertions(ctx, store.GetId(), model.GetId(), []*openfgav1.Assertion{
+			{TupleKey: tuple.NewAssertionTupleKey("document:1", "viewer", "user:anne"), Expectation: true},
+		}))
+		require.NoError(t, datastore.Write(ctx, store.GetId(), nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+		}))
+
+		require.NoError(t, datastore.DeleteStore(ctx, store.GetId()))
+		return store.GetId()
+	}
+
+	t.Run("purges_the_deleted_store_in_batches", func(t *testing.T) {
+		storeID := createDeletedStore(t)
+
+		deletedStores, err := purger.ListDeletedStores(ctx, time.Now().Add(time.Hour), 1000)
+		require.NoError(t, err)
+		require.Contains(t, deletedStores, storeID)
+
+		batches, total := 0, 0
+		for {
+			deleted, err := purger.PurgeStore(ctx, storeID, time.Now().Add(time.Hour), 2)
+			require.NoError(t, err)
+			batches++
+			total += deleted
+			if deleted < 2 {
+				break
+			}
+		}
+		require.Greater(t, batches, 1)
+		require.GreaterOrEqual(t, total, 8)
+
+		iter, err := datastore.Read(ctx, storeID, nil, storage.ReadOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		_, err = iter.Next(ctx)
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+
+		require.Empty(t, readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, ""))
+
+		_, err = datastore.FindLatestAuthorizationModel(ctx, storeID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		deletedStores, err = purger.ListDeletedStores(ctx, time.Now().Add(time.Hour), 1000)
+		require.NoError(t, err)
+		require.NotContains(t, deletedStores, storeID)
+
+		// the store can be created again
+		_, err = datastore.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "recreated", CreatedAt: timestamppb.Now()})
+		require.NoError(t, err)
+	})
+
+	t.Run("stores_deleted_after_the_retention_are_not_purged", func(t *testing.T) {
+		storeID := createDeletedStore(t)
+
+		deletedStores, err := purger.ListDeletedStores(ctx, time.Now().Add(-time.Hour), 1000)
+		require.NoError(t, err)
+		require.NotContains(t, deletedStores, storeID)
+
+		deleted, err := purger.PurgeStore(ctx, storeID, time.Now().Add(-time.Hour), 2)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		_, err = datastore.FindLatestAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+	})
+
+	t.Run("stores_which_are_not_deleted_are_not_purged", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		_, err := datastore.CreateStore(ctx, &openfgav1.Store{Id: storeID, Name: "store", CreatedAt: timestamppb.Now()})
+		require.NoError(t, err)
+
+		deleted, err := purger.PurgeStore(ctx, storeID, time.Now().Add(time.Hour), 2)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		_, err = datastore.GetStore(ctx, storeID)
+		require.NoError(t, err)
+	})
 }
@@ -15,6 +15,7 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/protoadapt"
 	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -37,6 +38,233 @@ var (
 	}
 )
 
+func WatchChangelogTest(t *testing.T, datastore storage.OpenFGADatastore, watcher storage.ChangelogWatcher) {
+	storeID := ulid.Make().String()
+	otherStoreID := ulid.Make().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications, err := watcher.WatchChangelog(ctx, storeID)
+	require.NoError(t, err)
+
+	otherNotifications, err := watcher.WatchChangelog(ctx, otherStoreID)
+	require.NoError(t, err)
+
+	// the notifications may be delivered asynchronously, so changes are retried until one is notified
+	requireNotified := func(t *testing.T, change func() error) {
+		require.Eventually(t, func() bool {
+			require.NoError(t, change())
+
+			select {
+			case <-notifications:
+				return true
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 10*time.Second, time.Millisecond)
+	}
+
+	requireNotified(t, func() error {
+		return datastore.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:"+ulid.Make().String(), "viewer", "user:jon"),
+		})
+	})
+
+	if importer, ok := datastore.(storage.TupleImporter); ok {
+		requireNotified(t, func() error {
+			return importer.ImportTuples(context.Background(), storeID, []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:"+ulid.Make().String(), "viewer", "user:jon"),
+			})
+		})
+	}
+
+	if reaper, ok := datastore.(storage.TupleReaper); ok {
+		requireNotified(t, func() error {
+			expiresAt := time.Now().Add(-time.Second)
+			err := datastore.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:"+ulid.Make().String(), "viewer", "user:jon"),
+			}, storage.WithExpiresAt(expiresAt))
+			if err != nil {
+				return err
+			}
+			// the write itself is notified
+			<-notifications
+
+			_, err = reaper.DeleteExpiredTuples(context.Background(), time.Now(), 100)
+			return err
+		})
+	}
+
+	select {
+	case <-otherNotifications:
+		require.Fail(t, "the changes of another store were notified")
+	default:
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-notifications:
+			return !ok
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func ChangelogPrunerTest(t *testing.T, datastore storage.OpenFGADatastore, pruner storage.ChangelogPruner) {
+	ctx := context.Background()
+
+	// writeChanges writes then deletes tuples, one change at a time, and returns their tuple keys.
+	writeChanges := func(t *testing.T, storeID string, count int) []*openfgav1.TupleKey {
+		tks := make([]*openfgav1.TupleKey, 0, count)
+		for i := 0; i < count; i++ {
+			tk := tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne")
+			require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk}))
+			tks = append(tks, tk)
+		}
+		return tks
+	}
+
+	t.Run("prunes_the_changes_beyond_the_count_kept", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tks := writeChanges(t, storeID, 5)
+
+		deleted, err := pruner.PruneChanges(ctx, storeID, time.Time{}, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+
+		deleted, err = pruner.PruneChanges(ctx, storeID, time.Time{}, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 2)
+		require.Equal(t, tks[3].GetObject(), changes[0].GetTupleKey().GetObject())
+	})
+
+	t.Run("prunes_the_changes_older_than_the_age_kept", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, 3)
+		otherStoreID := ulid.Make().String()
+		writeChanges(t, otherStoreID, 1)
+
+		deleted, err := pruner.PruneChanges(ctx, storeID, time.Now().Add(-time.Hour), 0, 10)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		deleted, err = pruner.PruneChanges(ctx, storeID, time.Now().Add(time.Second), 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, deleted)
+
+		require.Empty(t, readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, ""))
+		require.Len(t, readChangesWithPageSize(t, datastore, otherStoreID, storage.DefaultPageSize, ""), 1)
+	})
+
+	t.Run("continuation_tokens_before_the_pruned_changes_expire", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeChanges(t, storeID, 4)
+
+		opts := storage.ReadChangesOptions{Pagination: storage.PaginationOptions{PageSize: 1}}
+		_, expiredToken, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+		require.NoError(t, err)
+
+		opts.Pagination.PageSize = 2
+		_, validToken, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+		require.NoError(t, err)
+
+		deleted, err := pruner.PruneChanges(ctx, storeID, time.Time{}, 2, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+
+		opts.Pagination.From = string(expiredToken)
+		_, _, err = datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+		require.ErrorIs(t, err, storage.ErrContinuationTokenExpired)
+
+		opts.Pagination.From = string(validToken)
+		changes, _, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, opts)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+	})
+
+	t.Run("compacts_the_writes_followed_by_a_delete", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tks := writeChanges(t, storeID, 3)
+		for _, tk := range tks[:2] {
+			require.NoError(t, datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk)}, nil))
+		}
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tks[0]}))
+
+		compacted, err := pruner.CompactChanges(ctx, storeID, time.Now().Add(time.Second), 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, compacted)
+
+		compacted, err = pruner.CompactChanges(ctx, storeID, time.Now().Add(time.Second), 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, compacted)
+
+		// the last write of the first tuple isn't followed by a delete, and the third tuple was never deleted
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 2)
+		for _, change := range changes {
+			require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, change.GetOperation())
+		}
+		require.Equal(t, tks[2].GetObject(), changes[0].GetTupleKey().GetObject())
+		require.Equal(t, tks[0].GetObject(), changes[1].GetTupleKey().GetObject())
+	})
+}
+
+func ImportTuplesTest(t *testing.T, datastore storage.OpenFGADatastore, importer storage.TupleImporter) {
+	ctx := context.Background()
+
+	// More tuples than fit in a single statement of the SQL datastores.
+	tks := make([]*openfgav1.TupleKey, 0, 2500)
+	for i := 0; i < cap(tks); i++ {
+		tk := tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne")
+		if i%2 == 0 {
+			tk = tuple.NewTupleKeyWithCondition(fmt.Sprintf("document:%d", i), "viewer", "group:eng#member", "condition",
+				testutils.MustNewStruct(t, map[string]interface{}{"param": i}))
+		}
+		tks = append(tks, tk)
+	}
+
+	t.Run("imports_the_tuples_and_their_changes", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		require.NoError(t, importer.ImportTuples(ctx, storeID, tks))
+
+		seenTuples := testutils.ConvertTuplesToTupleKeys(readWithPageSize(t, datastore, storeID, 1000, nil))
+		if diff := cmp.Diff(tks, seenTuples, cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		changes := readChangesWithPageSize(t, datastore, storeID, 1000, "")
+		require.Len(t, changes, len(tks))
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, changes[0].GetOperation())
+	})
+
+	t.Run("imports_nothing_if_a_tuple_already_exists", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		require.NoError(t, datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tks[len(tks)-1]}))
+
+		err := importer.ImportTuples(ctx, storeID, tks)
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+
+		require.Len(t, readWithPageSize(t, datastore, storeID, 1000, nil), 1)
+		require.Len(t, readChangesWithPageSize(t, datastore, storeID, 1000, ""), 1)
+	})
+
+	t.Run("fails_if_there_are_too_many_tuples", func(t *testing.T) {
+		tooMany := make([]*openfgav1.TupleKey, importer.MaxTuplesPerImport()+1)
+		for i := range tooMany {
+			tooMany[i] = tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne")
+		}
+
+		err := importer.ImportTuples(ctx, ulid.Make().String(), tooMany)
+		require.ErrorIs(t, err, storage.ErrExceededWriteBatchLimit)
+	})
+}
+
 func ReadChangesTest(t *testing.T, datastore storage.OpenFGADatastore, tokenSerializer encoder.ContinuationTokenSerializer) {
 	ctx := context.Background()
 
@@ -1342,6 +1570,522 @@ func TupleWritingAndReadingTest(t *testing.T, datastore storage.OpenFGADatastore
 	})
 }
 
+func TupleWriteOptionsTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	tk1 := tuple.NewTupleKey("doc:readme", "owner", "user:anne")
+	tk2 := tuple.NewTupleKey("doc:readme", "viewer", "user:bob")
+	tk3 := tuple.NewTupleKey("doc:readme", "viewer", "user:charlie")
+
+	requireTuples := func(t *testing.T, storeID string, expected ...*openfgav1.TupleKey) {
+		t.Helper()
+		seenTuples := testutils.ConvertTuplesToTupleKeys(readWithPageSize(t, datastore, storeID, storage.DefaultPageSize, nil))
+		if diff := cmp.Diff(expected, seenTuples, cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	t.Run("ignores_duplicate_writes", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1, tk2},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+		)
+		require.NoError(t, err)
+		requireTuples(t, storeID, tk1, tk2)
+
+		// The ignored write is not a change.
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 2)
+	})
+
+	t.Run("ignores_concurrent_duplicate_writes", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2})
+		require.NoError(t, err)
+
+		var writes errgroup.Group
+		for i := 0; i < 5; i++ {
+			writes.Go(func() error {
+				return datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1},
+					storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+				)
+			})
+		}
+		require.NoError(t, writes.Wait())
+		requireTuples(t, storeID, tk1, tk2)
+		require.Len(t, readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, ""), 2)
+	})
+
+	t.Run("ignores_duplicate_writes_with_the_same_condition", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tk := tuple.NewTupleKeyWithCondition("doc:readme", "viewer", "user:anne", "condition",
+			testutils.MustNewStruct(t, map[string]interface{}{"param1": "ok", "param2": 1}))
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+		)
+		require.NoError(t, err)
+
+		t.Run("but_not_with_a_different_condition", func(t *testing.T) {
+			for _, duplicate := range []*openfgav1.TupleKey{
+				tuple.NewTupleKey("doc:readme", "viewer", "user:anne"),
+				tuple.NewTupleKeyWithCondition("doc:readme", "viewer", "user:anne", "other", tk.GetCondition().GetContext()),
+				tuple.NewTupleKeyWithCondition("doc:readme", "viewer", "user:anne", "condition",
+					testutils.MustNewStruct(t, map[string]interface{}{"param1": "ko"})),
+			} {
+				err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{duplicate},
+					storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+				)
+				require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+				require.ErrorContains(t, err, "different condition")
+			}
+		})
+	})
+
+	t.Run("ignores_missing_deletes", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1, tk2})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk1),
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk3),
+			},
+			nil,
+			storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore),
+		)
+		require.NoError(t, err)
+		requireTuples(t, storeID, tk2)
+
+		// The ignored delete is not a change.
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 3)
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, changes[2].GetOperation())
+		require.Equal(t, tk1.GetUser(), changes[2].GetTupleKey().GetUser())
+	})
+
+	t.Run("ignores_everything", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk2)},
+			[]*openfgav1.TupleKey{tk1},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+			storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore),
+		)
+		require.NoError(t, err)
+		requireTuples(t, storeID, tk1)
+		require.Len(t, readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, ""), 1)
+	})
+
+	t.Run("applies_the_write_if_the_preconditions_hold", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		// The preconditions are checked before the deletes and writes.
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk1)},
+			[]*openfgav1.TupleKey{tk2},
+			storage.WithPreconditions(
+				storage.WritePrecondition{TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(tk1), Exists: true},
+				storage.WritePrecondition{TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(tk2), Exists: false},
+			),
+		)
+		require.NoError(t, err)
+		requireTuples(t, storeID, tk2)
+	})
+
+	t.Run("fails_and_introduces_no_changes_if_a_precondition_does_not_hold", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		tests := map[string]storage.WritePrecondition{
+			"exists":         {TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(tk3), Exists: true},
+			"does_not_exist": {TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(tk1), Exists: false},
+		}
+
+		for name, precondition := range tests {
+			t.Run(name, func(t *testing.T) {
+				err := datastore.Write(ctx, storeID,
+					[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk1)},
+					[]*openfgav1.TupleKey{tk2},
+					storage.WithPreconditions(precondition),
+				)
+				require.ErrorIs(t, err, storage.ErrPreconditionFailed)
+				require.EqualError(t, err, storage.PreconditionFailedError(precondition).Error())
+
+				requireTuples(t, storeID, tk1)
+				require.Len(t, readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, ""), 1)
+			})
+		}
+	})
+
+	t.Run("sets_the_committed_revision", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		var first ulid.ULID
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1, tk2}, storage.WithCommittedRevision(&first))
+		require.NoError(t, err)
+		require.NotZero(t, first)
+
+		// The ignored write records no change, so it has no revision.
+		var ignored ulid.ULID
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+			storage.WithCommittedRevision(&ignored),
+		)
+		require.NoError(t, err)
+		require.Zero(t, ignored)
+
+		var second ulid.ULID
+		err = datastore.Write(ctx, storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk1)},
+			[]*openfgav1.TupleKey{tk3},
+			storage.WithCommittedRevision(&second),
+		)
+		require.NoError(t, err)
+		require.Positive(t, second.Compare(first))
+	})
+
+	t.Run("concurrent_writes_with_preconditions_are_serialized", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		// Each write moves the tuple from a relation to the next one, so that at most one of the
+		// writes racing for the same relation succeeds.
+		relations := []string{"owner", "viewer"}
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:readme", relations[0], "user:anne")})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < cap(errs); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				from := tuple.NewTupleKey("doc:readme", relations[0], "user:anne")
+				errs <- datastore.Write(ctx, storeID,
+					[]*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(from)},
+					[]*openfgav1.TupleKey{tuple.NewTupleKey("doc:readme", relations[1], "user:anne")},
+					storage.WithPreconditions(storage.WritePrecondition{
+						TupleKey: tuple.TupleKeyToTupleKeyWithoutCondition(from),
+						Exists:   true,
+					}),
+				)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+			}
+		}
+		require.Equal(t, 1, succeeded)
+		requireTuples(t, storeID, tuple.NewTupleKey("doc:readme", relations[1], "user:anne"))
+	})
+}
+
+func TupleCounterTest(t *testing.T, datastore storage.OpenFGADatastore, counter storage.TupleCounter) {
+	ctx := context.Background()
+
+	tk1 := tuple.NewTupleKey("doc:readme", "owner", "user:anne")
+	tk2 := tuple.NewTupleKey("doc:readme", "viewer", "user:bob")
+	tk3 := tuple.NewTupleKey("doc:readme", "viewer", "user:charlie")
+
+	requireCount := func(t *testing.T, storeID string, expected int64) {
+		t.Helper()
+		count, err := counter.CountTuples(ctx, storeID)
+		require.NoError(t, err)
+		require.Equal(t, expected, count)
+	}
+
+	t.Run("counts_the_tuples_written_and_deleted", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		requireCount(t, storeID, 0)
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1, tk2})
+		require.NoError(t, err)
+		requireCount(t, storeID, 2)
+
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk1)}, []*openfgav1.TupleKey{tk3})
+		require.NoError(t, err)
+		requireCount(t, storeID, 2)
+
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk2),
+			tuple.TupleKeyToTupleKeyWithoutCondition(tk3),
+		}, nil)
+		require.NoError(t, err)
+		requireCount(t, storeID, 0)
+
+		// The other stores are not counted.
+		requireCount(t, ulid.Make().String(), 0)
+	})
+
+	t.Run("failed_and_ignored_writes_are_not_counted", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2, tk1})
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+		requireCount(t, storeID, 1)
+
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk3)}, []*openfgav1.TupleKey{tk1, tk2},
+			storage.WithOnDuplicateInsert(storage.OnDuplicateInsertIgnore),
+			storage.WithOnMissingDelete(storage.OnMissingDeleteIgnore),
+		)
+		require.NoError(t, err)
+		requireCount(t, storeID, 2)
+	})
+
+	t.Run("counts_the_concurrent_first_writes_of_a_store", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		var writes errgroup.Group
+		for i := 0; i < 10; i++ {
+			writes.Go(func() error {
+				return datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+					tuple.NewTupleKey(fmt.Sprintf("doc:%d", i), "viewer", "user:anne"),
+				})
+			})
+		}
+		require.NoError(t, writes.Wait())
+		requireCount(t, storeID, 10)
+	})
+
+	t.Run("expired_tuples_are_counted_until_deleted", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1}, storage.WithExpiresAt(time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+		requireCount(t, storeID, 1)
+
+		// The expired tuple is deleted before it is written again.
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1})
+		require.NoError(t, err)
+		requireCount(t, storeID, 1)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2}, storage.WithExpiresAt(time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+		requireCount(t, storeID, 2)
+
+		reaper, ok := datastore.(storage.TupleReaper)
+		if !ok {
+			return
+		}
+		for deleted := 1; deleted > 0; {
+			deleted, err = reaper.DeleteExpiredTuples(ctx, time.Now(), 100)
+			require.NoError(t, err)
+		}
+		requireCount(t, storeID, 1)
+	})
+
+	importer, ok := datastore.(storage.TupleImporter)
+	if !ok {
+		return
+	}
+
+	t.Run("counts_the_tuples_imported", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := importer.ImportTuples(ctx, storeID, []*openfgav1.TupleKey{tk1, tk2, tk3})
+		require.NoError(t, err)
+		requireCount(t, storeID, 3)
+
+		err = importer.ImportTuples(ctx, storeID, []*openfgav1.TupleKey{tuple.NewTupleKey("doc:other", "viewer", "user:anne"), tk1})
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+		requireCount(t, storeID, 3)
+	})
+}
+
+func TupleExpirationTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	expired := tuple.NewTupleKey("doc:readme", "viewer", "user:anne")
+	expiredUserset := tuple.NewTupleKey("doc:readme", "viewer", "group:eng#member")
+	alive := tuple.NewTupleKey("doc:readme", "viewer", "user:bob")
+
+	// writeExpired writes the tuples with an expiry in the past, which the write command wouldn't allow.
+	writeExpired := func(t *testing.T, storeID string, tks ...*openfgav1.TupleKey) {
+		t.Helper()
+		err := datastore.Write(ctx, storeID, nil, tks, storage.WithExpiresAt(time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+	}
+
+	t.Run("expired_tuples_are_not_read", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeExpired(t, storeID, expired, expiredUserset)
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{alive}, storage.WithExpiresAt(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+
+		iter, err := datastore.Read(ctx, storeID, tuple.NewTupleKey("doc:readme", "", ""), storage.ReadOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		if diff := cmp.Diff([]*openfgav1.TupleKey{alive}, iterateThroughAllTuples(t, iter), cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		tuples := readWithPageSize(t, datastore, storeID, storage.DefaultPageSize, nil)
+		require.Len(t, tuples, 1)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, expired, storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, alive, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+
+		iter, err = datastore.ReadUsersetTuples(ctx, storeID, storage.ReadUsersetTuplesFilter{
+			Object:   "doc:readme",
+			Relation: "viewer",
+		}, storage.ReadUsersetTuplesOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		require.Empty(t, iterateThroughAllTuples(t, iter))
+
+		iter, err = datastore.ReadStartingWithUser(ctx, storeID, storage.ReadStartingWithUserFilter{
+			ObjectType: "doc",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:anne"}, {Object: "user:bob"}},
+		}, storage.ReadStartingWithUserOptions{})
+		require.NoError(t, err)
+		defer iter.Stop()
+		if diff := cmp.Diff([]*openfgav1.TupleKey{alive}, iterateThroughAllTuples(t, iter), cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("expired_tuples_can_be_written_again_but_not_deleted", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		writeExpired(t, storeID, expired)
+
+		err := datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(expired)}, nil)
+		require.ErrorIs(t, err, storage.ErrInvalidWriteInput)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{expired})
+		require.NoError(t, err)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, expired, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+
+		// The expired tuple is deleted before it is written again.
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 3)
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, changes[1].GetOperation())
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, changes[2].GetOperation())
+	})
+
+	reaper, ok := datastore.(storage.TupleReaper)
+	if !ok {
+		return
+	}
+
+	t.Run("expired_tuples_are_deleted_by_the_reaper", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{alive}, storage.WithExpiresAt(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		writeExpired(t, storeID, expired, expiredUserset)
+
+		deleted, err := reaper.DeleteExpiredTuples(ctx, time.Now(), 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+
+		for deleted > 0 {
+			deleted, err = reaper.DeleteExpiredTuples(ctx, time.Now(), 100)
+			require.NoError(t, err)
+		}
+
+		changes := readChangesWithPageSize(t, datastore, storeID, storage.DefaultPageSize, "")
+		require.Len(t, changes, 5)
+		deletes := make([]*openfgav1.TupleKey, 0, 2)
+		for _, change := range changes[3:] {
+			require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, change.GetOperation())
+			deletes = append(deletes, change.GetTupleKey())
+		}
+		if diff := cmp.Diff([]*openfgav1.TupleKey{expired, expiredUserset}, deletes, cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		// The tuple which hasn't expired yet is kept.
+		tuples := readWithPageSize(t, datastore, storeID, storage.DefaultPageSize, nil)
+		require.Len(t, tuples, 1)
+	})
+}
+
+func ReadTuplesBatchTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	anne := tuple.NewTupleKeyWithCondition("doc:1", "viewer", "user:anne", "condition",
+		testutils.MustNewStruct(t, map[string]interface{}{"param1": "ok"}))
+	group := tuple.NewTupleKey("doc:1", "viewer", "group:eng#member")
+	wildcard := tuple.NewTupleKey("doc:1", "viewer", "user:*")
+	editor := tuple.NewTupleKey("doc:1", "editor", "user:anne")
+	bob := tuple.NewTupleKey("doc:2", "viewer", "user:bob")
+	otherGroup := tuple.NewTupleKey("doc:2", "viewer", "group:eng#member")
+	expired := tuple.NewTupleKey("doc:3", "viewer", "user:carl")
+
+	storeID := ulid.Make().String()
+	err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{anne, group, wildcard, editor, bob, otherGroup})
+	require.NoError(t, err)
+	err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{expired}, storage.WithExpiresAt(time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+
+	t.Run("reads_the_tuples_matching_the_lookups", func(t *testing.T) {
+		tuples, err := datastore.ReadTuplesBatch(ctx, storeID, []storage.TupleLookup{
+			{Object: "doc:1", Relation: "viewer", User: "user:anne"},
+			{Object: "doc:1", Relation: "viewer", User: "group:eng#member"},
+			{Object: "doc:1", Relation: "viewer"},
+			{Object: "doc:2", Relation: "viewer", User: "user:bob"},
+			{Object: "doc:2", Relation: "viewer", User: "user:missing"},
+			{Object: "doc:3", Relation: "viewer", User: "user:carl"},
+		}, storage.ReadTuplesBatchOptions{})
+		require.NoError(t, err)
+
+		got := make([]*openfgav1.TupleKey, 0, len(tuples))
+		for _, tp := range tuples {
+			got = append(got, tp.GetKey())
+		}
+		if diff := cmp.Diff([]*openfgav1.TupleKey{anne, group, wildcard, bob}, got, cmpSortTupleKeys...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no_lookups", func(t *testing.T) {
+		tuples, err := datastore.ReadTuplesBatch(ctx, storeID, nil, storage.ReadTuplesBatchOptions{})
+		require.NoError(t, err)
+		require.Empty(t, tuples)
+	})
+
+	t.Run("other_store", func(t *testing.T) {
+		tuples, err := datastore.ReadTuplesBatch(ctx, ulid.Make().String(), []storage.TupleLookup{
+			{Object: "doc:1", Relation: "viewer", User: "user:anne"},
+			{Object: "doc:1", Relation: "viewer"},
+		}, storage.ReadTuplesBatchOptions{})
+		require.NoError(t, err)
+		require.Empty(t, tuples)
+	})
+}
+
 func ReadStartingWithUserTest(t *testing.T, datastore storage.OpenFGADatastore) {
 	ctx := context.Background()
 
@@ -1870,3 +2614,199 @@ func writeTuplesConcurrently(t *testing.T, store string, datastore storage.OpenF
 
 	wg.Wait()
 }
+
+func RecordCopierTest(t *testing.T, datastore storage.OpenFGADatastore, copier storage.RecordCopier) {
+	ctx := context.Background()
+
+	tk1 := tuple.NewTupleKey("doc:readme", "owner", "user:anne")
+	tk2 := tuple.NewTupleKeyWithCondition("doc:readme", "viewer", "group:eng#member", "in_office", &structpb.Struct{
+		Fields: map[string]*structpb.Value{"office": structpb.NewStringValue("paris")},
+	})
+	tk3 := tuple.NewTupleKey("doc:readme", "viewer", "user:bob")
+
+	readAllRecords := func(t *testing.T, storeID string) map[string]*storage.TupleRecord {
+		t.Helper()
+		records := make(map[string]*storage.TupleRecord)
+		var token string
+		var last *storage.TupleRecord
+		for {
+			page, next, err := copier.ReadTupleRecords(ctx, storeID, storage.NewPaginationOptions(1, token))
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page), 1)
+			for _, record := range page {
+				if last != nil {
+					require.Positive(t, storage.CompareTupleRecords(record, last), "the tuples are read in key order")
+				}
+				last = record
+				records[tuple.TupleKeyToString(record.AsTuple().GetKey())] = record
+			}
+
+			token = string(next)
+			if token == "" {
+				return records
+			}
+		}
+	}
+
+	requireSameRecord := func(t *testing.T, expected, actual *storage.TupleRecord) {
+		t.Helper()
+		require.Equal(t, expected.Ulid, actual.Ulid)
+		if diff := cmp.Diff(expected.AsTuple().GetKey(), actual.AsTuple().GetKey(), cmpOpts...); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+		require.True(t, expected.InsertedAt.Equal(actual.InsertedAt), "inserted at %s, want %s", actual.InsertedAt, expected.InsertedAt)
+		require.Equal(t, expected.ExpiresAt == nil, actual.ExpiresAt == nil)
+		if expected.ExpiresAt != nil {
+			require.True(t, expected.ExpiresAt.Equal(*actual.ExpiresAt), "expires at %s, want %s", actual.ExpiresAt, expected.ExpiresAt)
+		}
+	}
+
+	// writeSource writes tk1 with an expiry, tk2, and tk3 deleted afterwards, so that the store has two tuples and
+	// four changes.
+	writeSource := func(t *testing.T) string {
+		t.Helper()
+		storeID := ulid.Make().String()
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk1}, storage.WithExpiresAt(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		err = datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk2, tk3})
+		require.NoError(t, err)
+		err = datastore.Write(ctx, storeID, []*openfgav1.TupleKeyWithoutCondition{tuple.TupleKeyToTupleKeyWithoutCondition(tk3)}, nil)
+		require.NoError(t, err)
+		return storeID
+	}
+
+	t.Run("reads_the_tuples_and_changes_as_stored", func(t *testing.T) {
+		storeID := writeSource(t)
+
+		records := readAllRecords(t, storeID)
+		require.Len(t, records, 2)
+		require.NotNil(t, records[tuple.TupleKeyToString(tk1)].ExpiresAt)
+		require.Nil(t, records[tuple.TupleKeyToString(tk2)].ExpiresAt)
+
+		record, err := copier.ReadTupleRecord(ctx, storeID, tk2)
+		require.NoError(t, err)
+		requireSameRecord(t, records[tuple.TupleKeyToString(tk2)], record)
+
+		_, err = copier.ReadTupleRecord(ctx, storeID, tk3)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		changes, err := copier.ReadChangeRecords(ctx, storeID, "", 0)
+		require.NoError(t, err)
+		require.Len(t, changes, 4)
+		require.Equal(t, tuple.TupleKeyToString(tk1), tuple.TupleKeyToString(changes[0].TupleKey))
+		require.Equal(t, openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, changes[3].Operation)
+		require.Equal(t, tuple.TupleKeyToString(tk3), tuple.TupleKeyToString(changes[3].TupleKey))
+
+		after, err := copier.ReadChangeRecords(ctx, storeID, changes[1].Ulid, 1)
+		require.NoError(t, err)
+		require.Len(t, after, 1)
+		require.Equal(t, changes[2].Ulid, after[0].Ulid)
+	})
+
+	// the ULIDs of the tuples are unique across the stores of some datastores, so the records imported are new ones,
+	// with timestamps as precise as the datastores store them
+	newRecord := func(tk *openfgav1.TupleKey, expiresAt *time.Time) *storage.TupleRecord {
+		objectType, objectID := tuple.SplitObject(tk.GetObject())
+		return &storage.TupleRecord{
+			ObjectType:       objectType,
+			ObjectID:         objectID,
+			Relation:         tk.GetRelation(),
+			User:             tk.GetUser(),
+			ConditionName:    tk.GetCondition().GetName(),
+			ConditionContext: tk.GetCondition().GetContext(),
+			Ulid:             ulid.Make().String(),
+			InsertedAt:       time.Now().Add(-time.Hour).UTC().Truncate(time.Second),
+			ExpiresAt:        expiresAt,
+		}
+	}
+	newChange := func(operation openfgav1.TupleOperation, tk *openfgav1.TupleKey, insertedAt time.Time) *storage.ChangeRecord {
+		return &storage.ChangeRecord{
+			Ulid:       ulid.MustNew(ulid.Timestamp(insertedAt), ulid.DefaultEntropy()).String(),
+			Operation:  operation,
+			TupleKey:   tk,
+			InsertedAt: insertedAt,
+		}
+	}
+
+	t.Run("imports_the_records_as_they_are", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+		records := []*storage.TupleRecord{newRecord(tk1, &expiresAt), newRecord(tk2, nil)}
+		insertedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		changes := []*storage.ChangeRecord{
+			newChange(openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, tk1, insertedAt),
+			newChange(openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, tk2, insertedAt.Add(time.Second)),
+			newChange(openfgav1.TupleOperation_TUPLE_OPERATION_WRITE, tk3, insertedAt.Add(2*time.Second)),
+			newChange(openfgav1.TupleOperation_TUPLE_OPERATION_DELETE, tuple.NewTupleKey(tk3.GetObject(), tk3.GetRelation(), tk3.GetUser()), insertedAt.Add(3*time.Second)),
+		}
+
+		err := copier.ImportRecords(ctx, storeID, storage.RecordImport{Changes: changes[:2], Tuples: records})
+		require.NoError(t, err)
+
+		// the changes imported already are skipped
+		err = copier.ImportRecords(ctx, storeID, storage.RecordImport{Changes: changes})
+		require.NoError(t, err)
+
+		imported := readAllRecords(t, storeID)
+		require.Len(t, imported, len(records))
+		for _, record := range records {
+			requireSameRecord(t, record, imported[tuple.TupleKeyToString(record.AsTuple().GetKey())])
+		}
+
+		importedChanges, err := copier.ReadChangeRecords(ctx, storeID, "", 0)
+		require.NoError(t, err)
+		require.Len(t, importedChanges, len(changes))
+		for i, change := range changes {
+			require.Equal(t, change.Ulid, importedChanges[i].Ulid)
+			require.Equal(t, change.Operation, importedChanges[i].Operation)
+			require.True(t, change.InsertedAt.Equal(importedChanges[i].InsertedAt), "inserted at %s, want %s", importedChanges[i].InsertedAt, change.InsertedAt)
+			if diff := cmp.Diff(change.TupleKey, importedChanges[i].TupleKey, cmpOpts...); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		}
+
+		// the tuples and changes imported are read as if they were written
+		_, err = datastore.ReadUserTuple(ctx, storeID, tk1, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		readChanges, _, err := datastore.ReadChanges(ctx, storeID, storage.ReadChangesFilter{}, storage.ReadChangesOptions{})
+		require.NoError(t, err)
+		require.Len(t, readChanges, len(changes))
+
+		if counter, ok := datastore.(storage.TupleCounter); ok {
+			count, err := counter.CountTuples(ctx, storeID)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), count)
+		}
+	})
+
+	t.Run("replaces_and_deletes_the_tuples", func(t *testing.T) {
+		storeID := writeSource(t)
+
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+		record := newRecord(tk2, &expiresAt)
+		err := copier.ImportRecords(ctx, storeID, storage.RecordImport{
+			Deletes: []*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk1),
+				tuple.TupleKeyToTupleKeyWithoutCondition(tk3), // the tuples missing are ignored
+			},
+			Tuples: []*storage.TupleRecord{record},
+		})
+		require.NoError(t, err)
+
+		records := readAllRecords(t, storeID)
+		require.Len(t, records, 1)
+		requireSameRecord(t, record, records[tuple.TupleKeyToString(tk2)])
+
+		// the import records no change of its own
+		changes, err := copier.ReadChangeRecords(ctx, storeID, "", 0)
+		require.NoError(t, err)
+		require.Len(t, changes, 4)
+
+		if counter, ok := datastore.(storage.TupleCounter); ok {
+			count, err := counter.CountTuples(ctx, storeID)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), count)
+		}
+	})
+}
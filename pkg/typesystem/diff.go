@@ -0,0 +1,182 @@
+package typesystem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// ModelDiff is how an authorization model differs from a previous one. Relations are formatted as
+// "objectType#relation", and type restrictions as in the DSL ("user", "user:*", "group#member",
+// "user with condition"). All the lists are sorted.
+type ModelDiff struct {
+	AddedTypes   []string `json:"added_types,omitempty"`
+	RemovedTypes []string `json:"removed_types,omitempty"`
+
+	AddedRelations   []string `json:"added_relations,omitempty"`
+	RemovedRelations []string `json:"removed_relations,omitempty"`
+	// ChangedRewrites holds the relations of both models whose rewrite changed.
+	ChangedRewrites []string `json:"changed_rewrites,omitempty"`
+	// NarrowedTypeRestrictions holds the relations of both models which no longer allow some of the
+	// user types they were directly related to.
+	NarrowedTypeRestrictions []NarrowedTypeRestriction `json:"narrowed_type_restrictions,omitempty"`
+
+	AddedConditions   []string `json:"added_conditions,omitempty"`
+	RemovedConditions []string `json:"removed_conditions,omitempty"`
+	// ChangedConditions holds the conditions of both models whose expression or parameters changed.
+	ChangedConditions []string `json:"changed_conditions,omitempty"`
+}
+
+// NarrowedTypeRestriction is a relation which no longer allows some of the user types it was directly related to.
+type NarrowedTypeRestriction struct {
+	Relation     string   `json:"relation"`
+	RemovedTypes []string `json:"removed_types"`
+}
+
+// IsEmpty reports whether the models are equivalent.
+func (d *ModelDiff) IsEmpty() bool {
+	return len(d.AddedTypes) == 0 && len(d.RemovedTypes) == 0 &&
+		len(d.AddedRelations) == 0 && len(d.RemovedRelations) == 0 &&
+		len(d.ChangedRewrites) == 0 && len(d.NarrowedTypeRestrictions) == 0 &&
+		len(d.AddedConditions) == 0 && len(d.RemovedConditions) == 0 && len(d.ChangedConditions) == 0
+}
+
+// IsBreaking reports whether the new model may invalidate existing tuples or change the outcome of queries:
+// anything removed, narrowed or changed is breaking, while additions are not.
+func (d *ModelDiff) IsBreaking() bool {
+	return len(d.RemovedTypes) > 0 || len(d.RemovedRelations) > 0 ||
+		len(d.ChangedRewrites) > 0 || len(d.NarrowedTypeRestrictions) > 0 ||
+		len(d.RemovedConditions) > 0 || len(d.ChangedConditions) > 0
+}
+
+// Diff compares the model of to with the previous model of from.
+func Diff(from, to *TypeSystem) *ModelDiff {
+	diff := &ModelDiff{}
+
+	for objectType := range to.typeDefinitions {
+		if _, ok := from.typeDefinitions[objectType]; !ok {
+			diff.AddedTypes = append(diff.AddedTypes, objectType)
+		}
+	}
+
+	for objectType, fromRelations := range from.relations {
+		if _, ok := to.typeDefinitions[objectType]; !ok {
+			diff.RemovedTypes = append(diff.RemovedTypes, objectType)
+		}
+
+		toRelations := to.relations[objectType]
+		for relationName, fromRelation := range fromRelations {
+			objectRelation := tuple.ToObjectRelationString(objectType, relationName)
+
+			toRelation, ok := toRelations[relationName]
+			if !ok {
+				diff.RemovedRelations = append(diff.RemovedRelations, objectRelation)
+				continue
+			}
+
+			if !proto.Equal(fromRelation.GetRewrite(), toRelation.GetRewrite()) {
+				diff.ChangedRewrites = append(diff.ChangedRewrites, objectRelation)
+			}
+
+			allowed := make(map[string]struct{}, len(toRelation.GetTypeInfo().GetDirectlyRelatedUserTypes()))
+			for _, rr := range toRelation.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				allowed[typeRestrictionString(rr)] = struct{}{}
+			}
+
+			var removedTypes []string
+			for _, rr := range fromRelation.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				if _, ok := allowed[typeRestrictionString(rr)]; !ok {
+					removedTypes = append(removedTypes, typeRestrictionString(rr))
+				}
+			}
+			if len(removedTypes) > 0 {
+				sort.Strings(removedTypes)
+				diff.NarrowedTypeRestrictions = append(diff.NarrowedTypeRestrictions, NarrowedTypeRestriction{
+					Relation:     objectRelation,
+					RemovedTypes: removedTypes,
+				})
+			}
+		}
+	}
+
+	for objectType, toRelations := range to.relations {
+		fromRelations := from.relations[objectType]
+		for relationName := range toRelations {
+			if _, ok := fromRelations[relationName]; !ok {
+				diff.AddedRelations = append(diff.AddedRelations, tuple.ToObjectRelationString(objectType, relationName))
+			}
+		}
+	}
+
+	for name, fromCondition := range from.conditions {
+		toCondition, ok := to.conditions[name]
+		if !ok {
+			diff.RemovedConditions = append(diff.RemovedConditions, name)
+			continue
+		}
+
+		if normalizeExpression(fromCondition.GetExpression()) != normalizeExpression(toCondition.GetExpression()) ||
+			!conditionParametersEqual(fromCondition.GetParameters(), toCondition.GetParameters()) {
+			diff.ChangedConditions = append(diff.ChangedConditions, name)
+		}
+	}
+
+	for name := range to.conditions {
+		if _, ok := from.conditions[name]; !ok {
+			diff.AddedConditions = append(diff.AddedConditions, name)
+		}
+	}
+
+	sort.Strings(diff.AddedTypes)
+	sort.Strings(diff.RemovedTypes)
+	sort.Strings(diff.AddedRelations)
+	sort.Strings(diff.RemovedRelations)
+	sort.Strings(diff.ChangedRewrites)
+	sort.Slice(diff.NarrowedTypeRestrictions, func(i, j int) bool {
+		return diff.NarrowedTypeRestrictions[i].Relation < diff.NarrowedTypeRestrictions[j].Relation
+	})
+	sort.Strings(diff.AddedConditions)
+	sort.Strings(diff.RemovedConditions)
+	sort.Strings(diff.ChangedConditions)
+
+	return diff
+}
+
+// typeRestrictionString returns the type restriction as written in the DSL: user, user:*, group#member, optionally
+// followed by "with condition".
+func typeRestrictionString(rr *openfgav1.RelationReference) string {
+	s := rr.GetType()
+	switch rr.GetRelationOrWildcard().(type) {
+	case *openfgav1.RelationReference_Relation:
+		s = tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation())
+	case *openfgav1.RelationReference_Wildcard:
+		s = tuple.TypedPublicWildcard(rr.GetType())
+	}
+
+	if rr.GetCondition() != "" {
+		s = fmt.Sprintf("%s with %s", s, rr.GetCondition())
+	}
+	return s
+}
+
+// normalizeExpression collapses the whitespace of a condition expression, which doesn't change its meaning.
+func normalizeExpression(expression string) string {
+	return strings.Join(strings.Fields(expression), " ")
+}
+
+func conditionParametersEqual(a, b map[string]*openfgav1.ConditionParamTypeRef) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, paramType := range a {
+		if !proto.Equal(paramType, b[name]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package typesystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestDiff(t *testing.T) {
+	from := `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user]
+
+		type team
+
+		type document
+			relations
+				define owner: [user]
+				define editor: [user, group#member, user with in_region]
+				define viewer: [user, user:*] or editor
+
+		condition in_region(region: string) {
+			region == "eu"
+		}
+
+		condition before(x: int) {
+			x < 10
+		}`
+
+	tests := map[string]struct {
+		to       string
+		expected *ModelDiff
+		breaking bool
+		empty    bool
+	}{
+		"same_model": {
+			to:       from,
+			expected: &ModelDiff{},
+			empty:    true,
+		},
+		"additions_only": {
+			to: `
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user]
+
+				type team
+
+				type folder
+					relations
+						define viewer: [user]
+
+				type document
+					relations
+						define owner: [user]
+						define editor: [user, group#member, user with in_region]
+						define viewer: [user, user:*] or editor
+						define commenter: [user]
+
+				condition in_region(region: string) {
+					region == "eu"
+				}
+
+				condition before(x: int) {
+					x < 10
+				}
+
+				condition after(x: int) {
+					x > 10
+				}`,
+			expected: &ModelDiff{
+				AddedTypes:      []string{"folder"},
+				AddedRelations:  []string{"document#commenter", "folder#viewer"},
+				AddedConditions: []string{"after"},
+			},
+		},
+		"breaking_changes": {
+			to: `
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user]
+
+				type document
+					relations
+						define editor: [user]
+						define viewer: [user, user:*] or editor or owner
+						define owner: [user]
+
+				condition before(x: int) {
+					x < 100
+				}`,
+			expected: &ModelDiff{
+				RemovedTypes:    []string{"team"},
+				ChangedRewrites: []string{"document#viewer"},
+				NarrowedTypeRestrictions: []NarrowedTypeRestriction{
+					{Relation: "document#editor", RemovedTypes: []string{"group#member", "user with in_region"}},
+				},
+				RemovedConditions: []string{"in_region"},
+				ChangedConditions: []string{"before"},
+			},
+			breaking: true,
+		},
+		"removed_relation": {
+			to: `
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user]
+
+				type team
+
+				type document
+					relations
+						define editor: [user, group#member, user with in_region]
+						define viewer: [user] or editor
+
+				condition in_region(region: string) {
+					region == "eu"
+				}
+
+				condition before(x: int) {
+					x < 10
+				}`,
+			expected: &ModelDiff{
+				RemovedRelations: []string{"document#owner"},
+				NarrowedTypeRestrictions: []NarrowedTypeRestriction{
+					{Relation: "document#viewer", RemovedTypes: []string{"user:*"}},
+				},
+			},
+			breaking: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fromTypesys, err := New(testutils.MustTransformDSLToProtoWithID(from))
+			require.NoError(t, err)
+			toTypesys, err := New(testutils.MustTransformDSLToProtoWithID(test.to))
+			require.NoError(t, err)
+
+			diff := Diff(fromTypesys, toTypesys)
+			require.Equal(t, test.expected, diff)
+			require.Equal(t, test.breaking, diff.IsBreaking())
+			require.Equal(t, test.empty, diff.IsEmpty())
+		})
+	}
+}